@@ -0,0 +1,269 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/kubernetes-sigs/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/altinity/clickhouse-operator/deploy/builder"
+	"github.com/altinity/clickhouse-operator/pkg/version"
+)
+
+// Possible values of RBAC scope
+const (
+	rbacCluster   = "cluster"
+	rbacNamespace = "namespace"
+)
+
+// Manifest sections, which can be rendered
+const (
+	sectionCRD            = "crd"
+	sectionRBAC           = "rbac"
+	sectionDeployment     = "deployment"
+	sectionServiceMetrics = "service-metrics"
+	sectionServiceWebhook = "service-webhook"
+)
+
+var allSections = []string{sectionCRD, sectionRBAC, sectionDeployment, sectionServiceMetrics, sectionServiceWebhook}
+
+// installOptions specifies how installation manifest is rendered
+type installOptions struct {
+	namespace             string
+	version               string
+	operatorImage         string
+	metricsExporterImage  string
+	imagePullPolicy       string
+	rbac                  string
+	watchNamespaces       string
+	credentialsSecretName string
+	username              string
+	password              string
+	verbosity             string
+	sections              string
+	output                string
+}
+
+// runInstall renders installation manifest out of Go types of the operator and CRD schemas built into the binary,
+// so the manifest is in sync with the code of the same version
+func runInstall(args []string) error {
+	opts := installOptions{}
+	flags := flag.NewFlagSet("install", flag.ContinueOnError)
+	flags.StringVar(&opts.namespace, "namespace", "kube-system", "Namespace to install the operator into")
+	flags.StringVar(&opts.version, "version", version.Version, "Operator version the manifest is labeled with and images are tagged with")
+	flags.StringVar(&opts.operatorImage, "operator-image", "", "Operator image. Defaults to altinity/clickhouse-operator:<version>")
+	flags.StringVar(&opts.metricsExporterImage, "metrics-exporter-image", "", "Metrics exporter image. Defaults to altinity/metrics-exporter:<version>")
+	flags.StringVar(&opts.imagePullPolicy, "image-pull-policy", "Always", "Pull policy of operator and metrics exporter images")
+	flags.StringVar(&opts.rbac, "rbac", rbacCluster, "RBAC scope: cluster - ClusterRole, namespace - Role within the operator's namespace")
+	flags.StringVar(&opts.watchNamespaces, "watch-namespaces", "", "Comma-separated namespaces watched by the operator. All namespaces are watched in case not specified")
+	flags.StringVar(&opts.credentialsSecretName, "credentials-secret", "clickhouse-operator", "Name of the Secret with credentials the operator connects to ClickHouse with")
+	flags.StringVar(&opts.username, "username", "clickhouse_operator", "Username the operator connects to ClickHouse with")
+	flags.StringVar(&opts.password, "password", "clickhouse_operator_password", "Password the operator connects to ClickHouse with")
+	flags.StringVar(&opts.verbosity, "verbosity", "1", "Verbosity of the operator's log")
+	flags.StringVar(&opts.sections, "sections", strings.Join(allSections, ","), "Comma-separated manifest sections to be rendered")
+	flags.StringVar(&opts.output, "output", "", "File to write the manifest into. Stdout in case not specified")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if opts.operatorImage == "" {
+		opts.operatorImage = "altinity/clickhouse-operator:" + opts.version
+	}
+	if opts.metricsExporterImage == "" {
+		opts.metricsExporterImage = "altinity/metrics-exporter:" + opts.version
+	}
+	if (opts.rbac != rbacCluster) && (opts.rbac != rbacNamespace) {
+		return fmt.Errorf("unknown RBAC scope: %s", opts.rbac)
+	}
+
+	var out io.Writer = os.Stdout
+	if opts.output != "" {
+		file, err := os.Create(opts.output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	return newInstallRenderer(opts, out).render()
+}
+
+// installRenderer renders installation manifest section by section, as deploy/builder scripts do
+type installRenderer struct {
+	opts     installOptions
+	out      io.Writer
+	rendered int
+}
+
+// newInstallRenderer creates new installation manifest renderer
+func newInstallRenderer(opts installOptions, out io.Writer) *installRenderer {
+	return &installRenderer{
+		opts: opts,
+		out:  out,
+	}
+}
+
+// render renders all requested sections of the manifest
+func (r *installRenderer) render() error {
+	sections := make(map[string]bool)
+	for _, section := range strings.Split(r.opts.sections, ",") {
+		section = strings.TrimSpace(section)
+		if !inArray(section, allSections) {
+			return fmt.Errorf("unknown section: %s", section)
+		}
+		sections[section] = true
+	}
+
+	var objects []runtime.Object
+	if sections[sectionCRD] {
+		crds, err := r.newCRDs()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, crds...)
+	}
+	if sections[sectionRBAC] {
+		objects = append(objects, r.newRBAC()...)
+	}
+	if sections[sectionDeployment] {
+		configMaps, err := r.newConfigMaps()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, configMaps...)
+		objects = append(objects, r.newSecret(), r.newDeployment())
+	}
+	if sections[sectionServiceMetrics] {
+		objects = append(objects, r.newServiceMetrics())
+	}
+	if sections[sectionServiceWebhook] {
+		objects = append(objects, r.newServiceWebhook())
+	}
+
+	for _, obj := range objects {
+		if err := r.write(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write writes object as a document of the manifest, documents are separated by YAML documents separator
+func (r *installRenderer) write(obj runtime.Object) error {
+	section, err := marshalManifest(obj)
+	if err != nil {
+		return err
+	}
+	r.rendered++
+	if r.rendered > 1 {
+		section = append([]byte("---\n"), section...)
+	}
+	_, err = r.out.Write(section)
+	return err
+}
+
+// marshalManifest marshals object into YAML document. Fields populated by the API server,
+// such as status and creation timestamp, are not a part of the manifest
+func marshalManifest(obj runtime.Object) ([]byte, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	content := make(map[string]interface{})
+	if err := json.Unmarshal(b, &content); err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(content, "spec", "template", "metadata", "creationTimestamp")
+	return yaml.Marshal(content)
+}
+
+// readTemplate reads template built into the binary
+func readTemplate(file string) (string, error) {
+	content, err := builder.FS.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("unable to read template %s: %v", file, err)
+	}
+	return string(content), nil
+}
+
+// vars makes variables operator config files are rendered with
+func (r *installRenderer) vars() map[string]string {
+	return map[string]string{
+		"NAMESPACE":                       r.opts.namespace,
+		"OPERATOR_VERSION":                r.opts.version,
+		"WATCH_NAMESPACES":                r.opts.watchNamespaces,
+		"CH_USERNAME_PLAIN":               "",
+		"CH_PASSWORD_PLAIN":               "",
+		"CH_CREDENTIALS_SECRET_NAMESPACE": "",
+		"CH_CREDENTIALS_SECRET_NAME":      r.opts.credentialsSecretName,
+		"VERBOSITY":                       r.opts.verbosity,
+	}
+}
+
+// varRegexp matches ${VAR} references in templates
+var varRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// substitute substitutes variables into the template, as envsubst does. Unknown variables are substituted as empty
+func substitute(template string, vars map[string]string) string {
+	return varRegexp.ReplaceAllStringFunc(template, func(ref string) string {
+		return vars[varRegexp.FindStringSubmatch(ref)[1]]
+	})
+}
+
+// configFileHeader makes header of the rendered operator config file, warning it is generated
+func configFileHeader(file string) string {
+	lines := []string{
+		"IMPORTANT",
+		"This file is auto-generated",
+		"Do not edit this file - all changes would be lost",
+		"Edit appropriate template in the following folder:",
+		"deploy/builder/" + builder.ConfigPath,
+		"IMPORTANT",
+	}
+	b := &bytes.Buffer{}
+	switch path.Ext(file) {
+	case ".xml":
+		for _, line := range lines {
+			b.WriteString("<!-- " + line + " -->\n")
+		}
+	case ".yaml":
+		for _, line := range lines {
+			b.WriteString("# " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// inArray checks whether the needle is in the haystack
+func inArray(needle string, haystack []string) bool {
+	for _, item := range haystack {
+		if item == needle {
+			return true
+		}
+	}
+	return false
+}
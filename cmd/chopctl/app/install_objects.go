@@ -0,0 +1,508 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/kubernetes-sigs/yaml"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/altinity/clickhouse-operator/deploy/builder"
+	apiChk "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse-keeper.altinity.com/v1"
+	apiChi "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// operatorName is a name of the operator's Deployment, ServiceAccount, Secret and app label
+const operatorName = "clickhouse-operator"
+
+// crdSpec specifies CRD of a resource of the operator
+type crdSpec struct {
+	// obj is an object of the resource, kind and names of the CRD are made of its Go type
+	obj runtime.Object
+	// group is an API group of the resource
+	group string
+	// short is a short name of the resource
+	short string
+	// template is a file of the CRD schema and printer columns
+	template string
+}
+
+// crdSpecs lists CRDs of all resources of the operator
+var crdSpecs = []crdSpec{
+	{&apiChi.ClickHouseInstallation{}, apiChi.SchemeGroupVersion.Group, "chi", "clickhouse-operator-install-yaml-template-01-section-crd-01-chi-chit.yaml"},
+	{&apiChi.ClickHouseInstallationTemplate{}, apiChi.SchemeGroupVersion.Group, "chit", "clickhouse-operator-install-yaml-template-01-section-crd-01-chi-chit.yaml"},
+	{&apiChi.ClickHouseOperatorConfiguration{}, apiChi.SchemeGroupVersion.Group, "chopconf", "clickhouse-operator-install-yaml-template-01-section-crd-02-chopconf.yaml"},
+	{&apiChk.ClickHouseKeeperInstallation{}, apiChk.SchemeGroupVersion.Group, "chk", "clickhouse-operator-install-yaml-template-01-section-crd-03-chk.yaml"},
+	{&apiChi.ClickHouseOperation{}, apiChi.SchemeGroupVersion.Group, "chiop", "clickhouse-operator-install-yaml-template-01-section-crd-04-operation.yaml"},
+}
+
+// operatorConfigFolder specifies folder of operator config files, mounted into operator's containers from ConfigMap
+type operatorConfigFolder struct {
+	// name is a prefix of names of the ConfigMap and the volume
+	name string
+	// dir is a folder within operator's config folder
+	dir string
+}
+
+// operatorConfigFolders lists folders of operator config files
+var operatorConfigFolders = []operatorConfigFolder{
+	{"etc-clickhouse-operator", ""},
+	{"etc-clickhouse-operator-confd", "conf.d"},
+	{"etc-clickhouse-operator-configd", "config.d"},
+	{"etc-clickhouse-operator-templatesd", "templates.d"},
+	{"etc-clickhouse-operator-usersd", "users.d"},
+}
+
+// configMapName returns name of the ConfigMap with config files of the folder
+func (f operatorConfigFolder) configMapName() string {
+	return f.name + "-files"
+}
+
+// volumeName returns name of the volume the folder is mounted from
+func (f operatorConfigFolder) volumeName() string {
+	return f.name + "-folder"
+}
+
+// mountPath returns path the folder is mounted at
+func (f operatorConfigFolder) mountPath() string {
+	return path.Join("/etc/clickhouse-operator", f.dir)
+}
+
+// newCRDs creates CRDs of all resources of the operator
+func (r *installRenderer) newCRDs() ([]runtime.Object, error) {
+	var crds []runtime.Object
+	for _, spec := range crdSpecs {
+		crd, err := r.newCRD(spec)
+		if err != nil {
+			return nil, err
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
+
+// newCRD creates CRD of the resource. Group, kind and names are made of the Go type of the resource,
+// schema and printer columns are taken from the CRD template built into the binary
+func (r *installRenderer) newCRD(spec crdSpec) (*apiextensions.CustomResourceDefinition, error) {
+	file := path.Join(builder.InstallBundlePath, spec.template)
+	content, err := readTemplate(file)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := reflect.TypeOf(spec.obj).Elem().Name()
+	singular := strings.ToLower(kind)
+	plural := singular + "s"
+
+	// Descriptions of the schema may refer to the kind
+	crd := &apiextensions.CustomResourceDefinition{}
+	if err := yaml.Unmarshal([]byte(substitute(content, map[string]string{"KIND": kind})), crd); err != nil {
+		return nil, fmt.Errorf("unable to parse CRD template %s: %v", file, err)
+	}
+	crd.TypeMeta = meta.TypeMeta{
+		APIVersion: apiextensions.SchemeGroupVersion.String(),
+		Kind:       "CustomResourceDefinition",
+	}
+	crd.ObjectMeta = meta.ObjectMeta{
+		Name: plural + "." + spec.group,
+		Labels: map[string]string{
+			spec.group + "/chop": r.opts.version,
+		},
+	}
+	crd.Spec.Group = spec.group
+	crd.Spec.Scope = apiextensions.NamespaceScoped
+	crd.Spec.Names = apiextensions.CustomResourceDefinitionNames{
+		Kind:       kind,
+		Singular:   singular,
+		Plural:     plural,
+		ShortNames: []string{spec.short},
+	}
+	return crd, nil
+}
+
+// Verbs of RBAC rules
+var (
+	verbsManage = []string{"get", "list", "patch", "update", "watch", "create", "delete"}
+	verbsWatch  = []string{"get", "list", "watch"}
+	verbsStatus = []string{"get", "update", "patch", "create", "delete"}
+)
+
+// operatorRules lists RBAC rules the operator needs
+var operatorRules = []rbac.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"configmaps", "services", "persistentvolumeclaims", "secrets"}, Verbs: verbsManage},
+	{APIGroups: []string{""}, Resources: []string{"endpoints"}, Verbs: verbsWatch},
+	{APIGroups: []string{""}, Resources: []string{"events"}, Verbs: []string{"create"}},
+	{APIGroups: []string{""}, Resources: []string{"persistentvolumes"}, Verbs: []string{"get", "list", "patch", "update", "watch"}},
+	{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "list", "patch", "update", "watch", "delete"}},
+	{APIGroups: []string{""}, Resources: []string{"pods/status"}, Verbs: []string{"patch", "update"}},
+	{APIGroups: []string{""}, Resources: []string{"pods/eviction"}, Verbs: []string{"create"}},
+	{APIGroups: []string{""}, Resources: []string{"nodes"}, Verbs: []string{"get", "list", "patch"}},
+	{APIGroups: []string{"apps"}, Resources: []string{"statefulsets"}, Verbs: verbsManage},
+	{APIGroups: []string{"apps"}, Resources: []string{"replicasets"}, Verbs: []string{"get", "patch", "update", "delete"}},
+	{APIGroups: []string{"apps"}, Resources: []string{"deployments"}, ResourceNames: []string{operatorName}, Verbs: []string{"get", "patch", "update", "delete"}},
+	{APIGroups: []string{"policy"}, Resources: []string{"poddisruptionbudgets"}, Verbs: verbsManage},
+	{APIGroups: []string{"apiextensions.k8s.io"}, Resources: []string{"customresourcedefinitions"}, Verbs: []string{"get", "list", "update"}},
+	{APIGroups: []string{"admissionregistration.k8s.io"}, Resources: []string{"validatingwebhookconfigurations"}, Verbs: []string{"get", "create", "update"}},
+	{APIGroups: []string{"authentication.k8s.io"}, Resources: []string{"tokenreviews"}, Verbs: []string{"create"}},
+	{APIGroups: []string{"authorization.k8s.io"}, Resources: []string{"subjectaccessreviews"}, Verbs: []string{"create"}},
+	{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"get", "create", "update"}},
+	{
+		APIGroups: []string{apiChi.SchemeGroupVersion.Group},
+		Resources: []string{"clickhouseinstallations"},
+		Verbs:     []string{"get", "list", "watch", "patch", "update", "delete"},
+	},
+	{
+		APIGroups: []string{apiChi.SchemeGroupVersion.Group},
+		Resources: []string{"clickhouseinstallationtemplates", "clickhouseoperatorconfigurations", "clickhouseoperations"},
+		Verbs:     verbsWatch,
+	},
+	{
+		APIGroups: []string{apiChi.SchemeGroupVersion.Group},
+		Resources: []string{
+			"clickhouseinstallations/finalizers",
+			"clickhouseinstallationtemplates/finalizers",
+			"clickhouseoperatorconfigurations/finalizers",
+			"clickhouseoperations/finalizers",
+		},
+		Verbs: []string{"update"},
+	},
+	{
+		APIGroups: []string{apiChi.SchemeGroupVersion.Group},
+		Resources: []string{
+			"clickhouseinstallations/status",
+			"clickhouseinstallationtemplates/status",
+			"clickhouseoperatorconfigurations/status",
+			"clickhouseoperations/status",
+		},
+		Verbs: verbsStatus,
+	},
+	{
+		APIGroups: []string{apiChk.SchemeGroupVersion.Group},
+		Resources: []string{"clickhousekeeperinstallations"},
+		Verbs:     []string{"get", "list", "watch", "patch", "update", "delete"},
+	},
+	{APIGroups: []string{apiChk.SchemeGroupVersion.Group}, Resources: []string{"clickhousekeeperinstallations/finalizers"}, Verbs: []string{"update"}},
+	{APIGroups: []string{apiChk.SchemeGroupVersion.Group}, Resources: []string{"clickhousekeeperinstallations/status"}, Verbs: verbsStatus},
+}
+
+// clusterScopedResources lists resources of operator rules, which are not namespaced.
+// Access to them can be granted by ClusterRole only
+var clusterScopedResources = []string{
+	"nodes",
+	"persistentvolumes",
+	"customresourcedefinitions",
+	"validatingwebhookconfigurations",
+	"tokenreviews",
+	"subjectaccessreviews",
+}
+
+// splitOperatorRules splits operator rules into rules of namespaced and cluster-scoped resources
+func splitOperatorRules() (namespaced, clusterScoped []rbac.PolicyRule) {
+	for _, rule := range operatorRules {
+		if util.InArray(rule.Resources[0], clusterScopedResources) {
+			clusterScoped = append(clusterScoped, rule)
+		} else {
+			namespaced = append(namespaced, rule)
+		}
+	}
+	return namespaced, clusterScoped
+}
+
+// newObjectMeta creates meta of a namespaced object of the operator
+func (r *installRenderer) newObjectMeta(name string, app bool) meta.ObjectMeta {
+	labels := map[string]string{
+		apiChi.SchemeGroupVersion.Group + "/chop": r.opts.version,
+	}
+	if app {
+		labels["app"] = operatorName
+	}
+	return meta.ObjectMeta{
+		Name:      name,
+		Namespace: r.opts.namespace,
+		Labels:    labels,
+	}
+}
+
+// newRBAC creates ServiceAccount along with either cluster-wide or namespaced role.
+// Namespaced role is accompanied by cluster-wide role granting access to cluster-scoped resources
+func (r *installRenderer) newRBAC() []runtime.Object {
+	serviceAccount := &core.ServiceAccount{
+		TypeMeta:   meta.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: r.newObjectMeta(operatorName, false),
+	}
+
+	roleMeta := r.newObjectMeta(operatorName, false)
+	roleKind, roleBindingKind := "Role", "RoleBinding"
+	if r.opts.rbac == rbacCluster {
+		// Cluster-wide role is not namespaced, so it is named after the namespace the operator is installed into
+		roleMeta.Name = operatorName + "-" + r.opts.namespace
+		roleMeta.Namespace = ""
+		roleKind, roleBindingKind = "ClusterRole", "ClusterRoleBinding"
+	}
+	roleTypeMeta := meta.TypeMeta{APIVersion: rbac.SchemeGroupVersion.String(), Kind: roleKind}
+	roleBindingTypeMeta := meta.TypeMeta{APIVersion: rbac.SchemeGroupVersion.String(), Kind: roleBindingKind}
+	roleRef := rbac.RoleRef{
+		APIGroup: rbac.GroupName,
+		Kind:     roleKind,
+		Name:     roleMeta.Name,
+	}
+	subjects := []rbac.Subject{
+		{
+			Kind:      rbac.ServiceAccountKind,
+			Name:      operatorName,
+			Namespace: r.opts.namespace,
+		},
+	}
+
+	if r.opts.rbac == rbacCluster {
+		return []runtime.Object{
+			serviceAccount,
+			&rbac.ClusterRole{TypeMeta: roleTypeMeta, ObjectMeta: roleMeta, Rules: operatorRules},
+			&rbac.ClusterRoleBinding{TypeMeta: roleBindingTypeMeta, ObjectMeta: roleMeta, RoleRef: roleRef, Subjects: subjects},
+		}
+	}
+
+	namespaced, clusterScoped := splitOperatorRules()
+	// Cluster-wide role is not namespaced, so it is named after the namespace the operator is installed into
+	clusterRoleMeta := r.newObjectMeta(operatorName+"-"+r.opts.namespace, false)
+	clusterRoleMeta.Namespace = ""
+	return []runtime.Object{
+		serviceAccount,
+		&rbac.Role{TypeMeta: roleTypeMeta, ObjectMeta: roleMeta, Rules: namespaced},
+		&rbac.RoleBinding{TypeMeta: roleBindingTypeMeta, ObjectMeta: roleMeta, RoleRef: roleRef, Subjects: subjects},
+		&rbac.ClusterRole{
+			TypeMeta:   meta.TypeMeta{APIVersion: rbac.SchemeGroupVersion.String(), Kind: "ClusterRole"},
+			ObjectMeta: clusterRoleMeta,
+			Rules:      clusterScoped,
+		},
+		&rbac.ClusterRoleBinding{
+			TypeMeta:   meta.TypeMeta{APIVersion: rbac.SchemeGroupVersion.String(), Kind: "ClusterRoleBinding"},
+			ObjectMeta: clusterRoleMeta,
+			RoleRef:    rbac.RoleRef{APIGroup: rbac.GroupName, Kind: "ClusterRole", Name: clusterRoleMeta.Name},
+			Subjects:   subjects,
+		},
+	}
+}
+
+// newConfigMaps creates ConfigMaps with operator config files of all config folders
+func (r *installRenderer) newConfigMaps() ([]runtime.Object, error) {
+	var configMaps []runtime.Object
+	for _, folder := range operatorConfigFolders {
+		configMap, err := r.newConfigMap(folder)
+		if err != nil {
+			return nil, err
+		}
+		configMaps = append(configMaps, configMap)
+	}
+	return configMaps, nil
+}
+
+// newConfigMap creates ConfigMap with operator config files of the config folder
+func (r *installRenderer) newConfigMap(folder operatorConfigFolder) (*core.ConfigMap, error) {
+	configMap := &core.ConfigMap{
+		TypeMeta:   meta.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: r.newObjectMeta(folder.configMapName(), true),
+	}
+
+	// Hidden files, such as .gitkeep, are not built into the binary, so folder may be missing as a whole
+	entries, err := fs.ReadDir(builder.FS, path.Join(builder.ConfigPath, folder.dir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := path.Join(builder.ConfigPath, folder.dir, entry.Name())
+		content, err := readTemplate(file)
+		if err != nil {
+			return nil, err
+		}
+		if configMap.Data == nil {
+			configMap.Data = make(map[string]string)
+		}
+		// Files are newline-terminated within ConfigMap, as deploy/builder scripts render them
+		content = configFileHeader(file) + substitute(content, r.vars())
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		configMap.Data[entry.Name()] = content
+	}
+	return configMap, nil
+}
+
+// newSecret creates Secret with credentials the operator connects to ClickHouse with
+func (r *installRenderer) newSecret() *core.Secret {
+	return &core.Secret{
+		TypeMeta:   meta.TypeMeta{APIVersion: "v1", Kind: "Secret"},
+		ObjectMeta: r.newObjectMeta(r.opts.credentialsSecretName, true),
+		Type:       core.SecretTypeOpaque,
+		StringData: map[string]string{
+			"username": r.opts.username,
+			"password": r.opts.password,
+		},
+	}
+}
+
+// newDeployment creates Deployment of the operator along with metrics exporter
+func (r *installRenderer) newDeployment() *apps.Deployment {
+	replicas := int32(1)
+	var volumes []core.Volume
+	var volumeMounts []core.VolumeMount
+	for _, folder := range operatorConfigFolders {
+		volumes = append(volumes, core.Volume{
+			Name: folder.volumeName(),
+			VolumeSource: core.VolumeSource{
+				ConfigMap: &core.ConfigMapVolumeSource{
+					LocalObjectReference: core.LocalObjectReference{Name: folder.configMapName()},
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, core.VolumeMount{
+			Name:      folder.volumeName(),
+			MountPath: folder.mountPath(),
+		})
+	}
+
+	return &apps.Deployment{
+		TypeMeta:   meta.TypeMeta{APIVersion: apps.SchemeGroupVersion.String(), Kind: "Deployment"},
+		ObjectMeta: r.newObjectMeta(operatorName, true),
+		Spec: apps.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &meta.LabelSelector{
+				MatchLabels: map[string]string{"app": operatorName},
+			},
+			Template: core.PodTemplateSpec{
+				ObjectMeta: meta.ObjectMeta{
+					Labels: map[string]string{"app": operatorName},
+					Annotations: map[string]string{
+						"prometheus.io/port":                 "8888",
+						"prometheus.io/scrape":               "true",
+						"clickhouse-operator-metrics/port":   "9999",
+						"clickhouse-operator-metrics/scrape": "true",
+					},
+				},
+				Spec: core.PodSpec{
+					ServiceAccountName: operatorName,
+					Volumes:            volumes,
+					Containers: []core.Container{
+						{
+							Name:            operatorName,
+							Image:           r.opts.operatorImage,
+							ImagePullPolicy: core.PullPolicy(r.opts.imagePullPolicy),
+							VolumeMounts:    volumeMounts,
+							Env:             newOperatorEnv(),
+							Ports: []core.ContainerPort{
+								{Name: "metrics", ContainerPort: 9999},
+								{Name: "webhook", ContainerPort: 9443},
+							},
+						},
+						{
+							Name:            "metrics-exporter",
+							Image:           r.opts.metricsExporterImage,
+							ImagePullPolicy: core.PullPolicy(r.opts.imagePullPolicy),
+							VolumeMounts:    volumeMounts,
+							Env:             newOperatorEnv(),
+							Ports: []core.ContainerPort{
+								{Name: "metrics", ContainerPort: 8888},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newOperatorEnv creates environment of operator's containers, exposing pod and operator container specifics
+func newOperatorEnv() []core.EnvVar {
+	var env []core.EnvVar
+	for _, field := range []struct {
+		name, path string
+	}{
+		{"OPERATOR_POD_NODE_NAME", "spec.nodeName"},
+		{"OPERATOR_POD_NAME", "metadata.name"},
+		{"OPERATOR_POD_NAMESPACE", "metadata.namespace"},
+		{"OPERATOR_POD_IP", "status.podIP"},
+		{"OPERATOR_POD_SERVICE_ACCOUNT", "spec.serviceAccountName"},
+	} {
+		env = append(env, core.EnvVar{
+			Name: field.name,
+			ValueFrom: &core.EnvVarSource{
+				FieldRef: &core.ObjectFieldSelector{FieldPath: field.path},
+			},
+		})
+	}
+	for _, resource := range []struct {
+		name, resource string
+	}{
+		{"OPERATOR_CONTAINER_CPU_REQUEST", "requests.cpu"},
+		{"OPERATOR_CONTAINER_CPU_LIMIT", "limits.cpu"},
+		{"OPERATOR_CONTAINER_MEM_REQUEST", "requests.memory"},
+		{"OPERATOR_CONTAINER_MEM_LIMIT", "limits.memory"},
+	} {
+		env = append(env, core.EnvVar{
+			Name: resource.name,
+			ValueFrom: &core.EnvVarSource{
+				ResourceFieldRef: &core.ResourceFieldSelector{
+					ContainerName: operatorName,
+					Resource:      resource.resource,
+				},
+			},
+		})
+	}
+	return env
+}
+
+// newServiceMetrics creates ClusterIP Service to provide monitoring metrics for Prometheus
+func (r *installRenderer) newServiceMetrics() *core.Service {
+	return &core.Service{
+		TypeMeta:   meta.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: r.newObjectMeta(operatorName+"-metrics", true),
+		Spec: core.ServiceSpec{
+			Ports: []core.ServicePort{
+				{Name: "clickhouse-metrics", Port: 8888},
+				{Name: "operator-metrics", Port: 9999},
+			},
+			Selector: map[string]string{"app": operatorName},
+		},
+	}
+}
+
+// newServiceWebhook creates ClusterIP Service to provide CRD conversion webhook for kube-apiserver
+func (r *installRenderer) newServiceWebhook() *core.Service {
+	return &core.Service{
+		TypeMeta:   meta.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: r.newObjectMeta(operatorName+"-webhook", true),
+		Spec: core.ServiceSpec{
+			Ports: []core.ServicePort{
+				{Name: "webhook", Port: 443, TargetPort: intstr.FromInt(9443)},
+			},
+			Selector: map[string]string{"app": operatorName},
+		},
+	}
+}
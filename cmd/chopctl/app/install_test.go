@@ -0,0 +1,185 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package app
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	rbac "k8s.io/api/rbac/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+func newTestInstallOptions(sections string) installOptions {
+	return installOptions{
+		namespace:             "kube-system",
+		version:               "0.23.3",
+		operatorImage:         "altinity/clickhouse-operator:0.23.3",
+		metricsExporterImage:  "altinity/metrics-exporter:0.23.3",
+		imagePullPolicy:       "Always",
+		rbac:                  rbacCluster,
+		credentialsSecretName: "clickhouse-operator",
+		username:              "clickhouse_operator",
+		password:              "clickhouse_operator_password",
+		verbosity:             "1",
+		sections:              sections,
+	}
+}
+
+func renderTestManifest(t *testing.T, opts installOptions) []string {
+	out := &bytes.Buffer{}
+	require.NoError(t, newInstallRenderer(opts, out).render())
+	return strings.Split(out.String(), "---\n")
+}
+
+func Test_Substitute(t *testing.T) {
+	vars := map[string]string{
+		"NAMESPACE": "kube-system",
+		"VERBOSITY": "1",
+	}
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"namespace: ${NAMESPACE}", "namespace: kube-system"},
+		{"${NAMESPACE}/${VERBOSITY}", "kube-system/1"},
+		{"unknown: ${UNKNOWN}", "unknown: "},
+		{"not a reference: $NAMESPACE ${1ST}", "not a reference: $NAMESPACE ${1ST}"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, substitute(tt.template, vars), tt.template)
+	}
+}
+
+func Test_ConfigFileHeader(t *testing.T) {
+	require.True(t, strings.HasPrefix(configFileHeader("templates-config/config.yaml"), "# IMPORTANT\n"))
+	require.True(t, strings.HasPrefix(configFileHeader("templates-config/users.d/01.xml"), "<!-- IMPORTANT -->\n"))
+	require.Empty(t, configFileHeader("templates-config/readme"))
+}
+
+func Test_Render_UnknownSection(t *testing.T) {
+	err := newInstallRenderer(newTestInstallOptions("crd,unknown"), &bytes.Buffer{}).render()
+	require.EqualError(t, err, "unknown section: unknown")
+}
+
+func Test_Render_RBAC(t *testing.T) {
+	opts := newTestInstallOptions(sectionRBAC)
+	opts.namespace = "clickhouse"
+
+	docs := renderTestManifest(t, opts)
+	require.Len(t, docs, 3)
+	require.Contains(t, docs[1], "kind: ClusterRole\n")
+	require.Contains(t, docs[1], "name: clickhouse-operator-clickhouse\n")
+	require.NotContains(t, docs[1], "namespace:")
+	require.Contains(t, docs[2], "kind: ClusterRoleBinding\n")
+
+	opts.rbac = rbacNamespace
+	docs = renderTestManifest(t, opts)
+	require.Len(t, docs, 5)
+	require.Contains(t, docs[1], "kind: Role\n")
+	require.Contains(t, docs[1], "namespace: clickhouse\n")
+	require.Contains(t, docs[2], "kind: RoleBinding\n")
+	// Access to cluster-scoped resources is granted by cluster-wide role
+	require.Contains(t, docs[3], "kind: ClusterRole\n")
+	require.Contains(t, docs[3], "name: clickhouse-operator-clickhouse\n")
+	require.NotContains(t, docs[3], "namespace:")
+	require.Contains(t, docs[4], "kind: ClusterRoleBinding\n")
+	require.Contains(t, docs[4], "namespace: clickhouse\n")
+}
+
+func Test_NewRBAC_ClusterScopedResources(t *testing.T) {
+	// Resources of the operator rules, which are not namespaced
+	clusterScoped := map[string]bool{
+		"nodes":                           true,
+		"persistentvolumes":               true,
+		"customresourcedefinitions":       true,
+		"validatingwebhookconfigurations": true,
+		"tokenreviews":                    true,
+		"subjectaccessreviews":            true,
+	}
+
+	opts := newTestInstallOptions(sectionRBAC)
+	opts.rbac = rbacNamespace
+	objects := newInstallRenderer(opts, &bytes.Buffer{}).newRBAC()
+	require.Len(t, objects, 5)
+	role := objects[1].(*rbac.Role)
+	clusterRole := objects[3].(*rbac.ClusterRole)
+
+	for _, rule := range role.Rules {
+		for _, resource := range rule.Resources {
+			require.False(t, clusterScoped[resource], "cluster-scoped %s is granted by Role", resource)
+		}
+	}
+	for _, rule := range clusterRole.Rules {
+		for _, resource := range rule.Resources {
+			require.True(t, clusterScoped[resource], "namespaced %s is granted by ClusterRole", resource)
+		}
+	}
+	// Every rule is granted exactly once
+	require.Len(t, append(role.Rules, clusterRole.Rules...), len(operatorRules))
+
+	// Resources are listed by one rule only
+	seen := map[string]bool{}
+	for _, rule := range operatorRules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				require.False(t, seen[group+"/"+resource], "%s/%s is listed twice", group, resource)
+				seen[group+"/"+resource] = true
+			}
+		}
+	}
+}
+
+func Test_Render_ConfigFiles(t *testing.T) {
+	opts := newTestInstallOptions(sectionDeployment)
+	opts.watchNamespaces = "dev,prod"
+
+	docs := renderTestManifest(t, opts)
+	// ConfigMaps of all config folders, Secret and Deployment
+	require.Len(t, docs, len(operatorConfigFolders)+2)
+	require.Contains(t, docs[0], "name: etc-clickhouse-operator-files\n")
+	require.Contains(t, docs[0], "# This file is auto-generated")
+	require.Contains(t, docs[0], "dev,prod")
+	require.NotContains(t, docs[0], "${")
+}
+
+// Test_Render_MatchesBundle checks manifest built of Go types is the same as the bundle built by deploy/builder,
+// objects of the bundle are decoded into Go types to be compared regardless of formatting and comments
+func Test_Render_MatchesBundle(t *testing.T) {
+	bundle, err := os.ReadFile("../../../deploy/operator/clickhouse-operator-install-bundle.yaml")
+	require.NoError(t, err)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiextensions.AddToScheme(scheme))
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+
+	docs := renderTestManifest(t, newTestInstallOptions(strings.Join(allSections, ",")))
+	bundleDocs := strings.SplitAfter(string(bundle), "\n---\n")
+	require.Len(t, docs, len(bundleDocs))
+	for i := range bundleDocs {
+		obj, _, err := decoder.Decode([]byte(strings.TrimSuffix(bundleDocs[i], "---\n")), nil, nil)
+		require.NoError(t, err)
+		want, err := marshalManifest(obj)
+		require.NoError(t, err)
+		require.Equal(t, string(want), docs[i])
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/altinity/clickhouse-operator/pkg/version"
+)
+
+// command specifies sub-command of chopctl
+type command struct {
+	// description is a one-line description of the command
+	description string
+	// run runs the command with its arguments
+	run func(args []string) error
+}
+
+// commands lists available sub-commands
+var commands = map[string]command{
+	"install": {
+		description: "Render installation manifest: CRDs, RBAC, operator configs and Deployment",
+		run:         runInstall,
+	},
+	"validate": {
+		description: "Validate CHI manifests with the operator's normalization and validation code",
+		run:         runValidate,
+	},
+	"version": {
+		description: "Display chopctl version and exit",
+		run: func([]string) error {
+			fmt.Printf("%s\n", version.Version)
+			return nil
+		},
+	},
+}
+
+// Run is an entry point of the application
+func Run() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command: %s\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// usage prints list of available sub-commands
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: chopctl <command> [flags]\n\nCommands:\n")
+	for _, name := range []string{"install", "validate", "version"} {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].description)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package app
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/altinity/clickhouse-operator/pkg/validation"
+)
+
+// runValidate validates CHI manifests with normalization and validation code of the operator.
+// Manifests are read from files specified as arguments, stdin in case "-" is specified.
+// Fails in case any CHI is invalid
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	configFilePath := flags.String("config", "", "Operator config file CHIs are normalized with. Operator defaults are used in case not specified")
+	quiet := flags.Bool("quiet", false, "Report invalid objects only")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("no manifest specified, usage: chopctl validate [flags] <manifest.yaml|-> ...")
+	}
+
+	// Operator code logs via glog, let it log to stderr, so stdout carries validation results only
+	_ = flag.Set("logtostderr", "true")
+	_ = flag.CommandLine.Parse(nil)
+
+	if err := validation.Init(*configFilePath); err != nil {
+		return fmt.Errorf("unable to init operator config: %v", err)
+	}
+
+	validator := validation.NewValidator(nil)
+	invalid := 0
+	for _, file := range flags.Args() {
+		results, err := validateFile(validator, file)
+		if err != nil {
+			return fmt.Errorf("unable to validate %s: %v", file, err)
+		}
+		for _, result := range results {
+			if !result.IsValid() {
+				invalid++
+			} else if *quiet {
+				continue
+			}
+			fmt.Printf("%s: %s", file, result)
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d invalid object(s) found", invalid)
+	}
+	return nil
+}
+
+// validateFile validates manifest of the file, stdin in case "-" is specified
+func validateFile(validator *validation.Validator, file string) ([]*validation.Result, error) {
+	var in io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+	return validator.ValidateManifest(in)
+}
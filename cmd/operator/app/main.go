@@ -74,13 +74,15 @@ func Run() {
 	initClickHouse(ctx)
 	initClickHouseReconcilerMetricsExporter(ctx)
 	initKeeper(ctx)
+	initWebhook(ctx)
+	initAPIServer(ctx)
 
 	var wg sync.WaitGroup
-	wg.Add(3)
+	wg.Add(4)
 
 	go func() {
 		defer wg.Done()
-		runClickHouse(ctx)
+		runControllers(ctx)
 	}()
 	go func() {
 		defer wg.Done()
@@ -88,7 +90,11 @@ func Run() {
 	}()
 	go func() {
 		defer wg.Done()
-		runKeeper(ctx)
+		runWebhook(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		runAPIServer(ctx)
 	}()
 
 	// Wait for completion
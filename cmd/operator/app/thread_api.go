@@ -0,0 +1,68 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"flag"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	"github.com/altinity/clickhouse-operator/pkg/apiserver"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+)
+
+// CLI parameter variables
+var (
+	// apiEP defines operator API end-point IP address. Empty value disables API
+	apiEP string
+	// apiTLSCertFile defines path to TLS certificate file API is served with
+	apiTLSCertFile string
+	// apiTLSKeyFile defines path to TLS key file API is served with
+	apiTLSKeyFile string
+)
+
+func init() {
+	flag.StringVar(&apiEP, "api-endpoint", "", "The operator API endpoint. Empty value disables API.")
+	flag.StringVar(&apiTLSCertFile, "api-tls-cert-file", "", "Path to TLS certificate file. API is served over HTTPS when both certificate and key are specified, otherwise API endpoint has to be a loopback address.")
+	flag.StringVar(&apiTLSKeyFile, "api-tls-key-file", "", "Path to TLS key file. API is served over HTTPS when both certificate and key are specified, otherwise API endpoint has to be a loopback address.")
+}
+
+var apiServer *apiserver.Server
+
+// initAPIServer is an entry point of the application
+func initAPIServer(ctx context.Context) {
+	if apiEP == "" {
+		log.V(1).F().Info("API disabled")
+		return
+	}
+
+	kubeClient, _, chopClient := chop.GetClientset(kubeConfigFile, masterURL)
+	apiServer = apiserver.NewServer(apiEP, apiTLSCertFile, apiTLSKeyFile, kubeClient, chopClient)
+}
+
+// runAPIServer is an entry point of the application
+func runAPIServer(ctx context.Context) {
+	if apiServer == nil {
+		return
+	}
+
+	log.S().P()
+	defer log.E().P()
+
+	log.V(1).F().Info("Starting API")
+	if err := apiServer.Run(ctx); err != nil {
+		log.V(1).F().Error("API failed err: %v", err)
+	}
+}
@@ -64,6 +64,10 @@ func initKeeper(ctx context.Context) {
 	if err != nil {
 		os.Exit(1)
 	}
+
+	// Disruptive operations over ClickHouse hosts wait for ensembles being reconfigured, as reported by CHK status.
+	// CHKs are read from API server directly, since manager's cache is not started yet
+	chiController.SetKeeperReader(manager.GetAPIReader())
 }
 
 func runKeeper(ctx context.Context) {
@@ -0,0 +1,90 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package app
+
+import (
+	"context"
+	"flag"
+	"os"
+	"sync"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	"github.com/altinity/clickhouse-operator/pkg/apis/deployment"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+)
+
+// defaultLeaderElectionLease specifies name of the lease operator instances elect the leader with
+const defaultLeaderElectionLease = "clickhouse-operator"
+
+// CLI parameter variables
+var (
+	// leaderElect specifies whether CHI and CHK controllers run on the elected leader instance only
+	leaderElect bool
+	// leaderElectionLease specifies name of the lease in the operator's namespace
+	leaderElectionLease string
+)
+
+func init() {
+	flag.BoolVar(&leaderElect, "leader-elect", true, "Run CHI and CHK controllers on the elected leader only, so instances of the operator being rolled out do not reconcile concurrently.")
+	flag.StringVar(&leaderElectionLease, "leader-election-lease", defaultLeaderElectionLease, "The lease name of leader election within the operator's namespace.")
+}
+
+// runControllers runs CHI and CHK controllers, on the elected leader in case leader election is enabled.
+// Webhooks are set by the leader as well. Leadership is released only after both controllers have shut down
+func runControllers(ctx context.Context) {
+	log.S().P()
+	defer log.E().P()
+
+	run := func(ctx context.Context) {
+		ensureWebhooks(ctx)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			runClickHouse(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			runKeeper(ctx)
+		}()
+		wg.Wait()
+	}
+
+	if !leaderElect {
+		run(ctx)
+		return
+	}
+
+	// Lease is kept in the operator's namespace, which is unknown in case the operator runs outside of k8s
+	namespace := chop.Config().Runtime.Namespace
+	if namespace == "" {
+		log.F().Warning("Operator's namespace is not specified, run without leader election")
+		run(ctx)
+		return
+	}
+
+	// Lease renewals are not delayed by API writes budget, which may be busy with reconcile
+	kubeClient := chop.GetLeaderElectionClientset(kubeConfigFile, masterURL)
+	identity, _ := chop.Get().ConfigManager.GetRuntimeParam(deployment.OPERATOR_POD_NAME)
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+	if err := controller.RunLeading(ctx, kubeClient, namespace, leaderElectionLease, identity, run); err != nil {
+		// Controllers may still be running, so the process is not safe to keep running
+		log.F().Error("Leader election of %s/%s failed. Err: %v", namespace, leaderElectionLease, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package app
+
+import (
+	"context"
+	"flag"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	"github.com/altinity/clickhouse-operator/pkg/apis/deployment"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	"github.com/altinity/clickhouse-operator/pkg/webhook"
+)
+
+// Webhook defaults
+const (
+	defaultWebhookEndpoint = ":9443"
+	defaultWebhookService  = "clickhouse-operator-webhook"
+)
+
+// CLI parameter variables
+var (
+	// webhookEP defines conversion and validating webhooks end-point IP address. Empty value disables webhooks
+	webhookEP string
+	// webhookService defines name of the Service kube-apiserver reaches webhooks by
+	webhookService string
+)
+
+func init() {
+	flag.StringVar(&webhookEP, "webhook-endpoint", defaultWebhookEndpoint, "The CRD conversion and CHI validating webhooks endpoint. Empty value disables webhooks.")
+	flag.StringVar(&webhookService, "webhook-service", defaultWebhookService, "The Service name of the webhooks.")
+}
+
+var webhookServer *webhook.Server
+
+// initWebhook is an entry point of the application
+func initWebhook(ctx context.Context) {
+	if webhookEP == "" {
+		log.V(1).F().Info("Webhooks disabled")
+		return
+	}
+
+	namespace, _ := chop.Get().ConfigManager.GetRuntimeParam(deployment.OPERATOR_POD_NAMESPACE)
+	kubeClient, extClient, _ := chop.GetClientset(kubeConfigFile, masterURL)
+	webhookServer = webhook.NewServer(webhookEP, webhookService, namespace, kubeClient, extClient)
+}
+
+// runWebhook is an entry point of the application
+func runWebhook(ctx context.Context) {
+	if webhookServer == nil {
+		return
+	}
+
+	log.S().P()
+	defer log.E().P()
+
+	log.V(1).F().Info("Starting webhooks")
+	if err := webhookServer.Run(ctx); err != nil {
+		log.V(1).F().Error("Webhooks failed err: %v", err)
+	}
+}
+
+// ensureWebhooks points CRDs and admission to webhooks. Is run by the elected leader
+func ensureWebhooks(ctx context.Context) {
+	if webhookServer == nil {
+		return
+	}
+
+	if err := webhookServer.EnsureConfigurations(ctx); err != nil {
+		log.V(1).F().Error("Unable to set webhooks err: %v", err)
+	}
+}
@@ -102,4 +102,5 @@ MANIFEST_PRINT_RBAC_CLUSTERED="no" \
 MANIFEST_PRINT_RBAC_NAMESPACED="no" \
 MANIFEST_PRINT_DEPLOYMENT="no" \
 MANIFEST_PRINT_SERVICE_METRICS="no" \
+MANIFEST_PRINT_SERVICE_WEBHOOK="no" \
 "${CUR_DIR}/cat-clickhouse-operator-install-yaml.sh" > "${MANIFEST_ROOT}/operator/parts/crd.yaml"
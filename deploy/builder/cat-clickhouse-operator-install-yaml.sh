@@ -86,6 +86,9 @@ MANIFEST_PRINT_DEPLOYMENT="${MANIFEST_PRINT_DEPLOYMENT:-"yes"}"
 # Render operator's Service Metrics
 MANIFEST_PRINT_SERVICE_METRICS="${MANIFEST_PRINT_SERVICE_METRICS:-"yes"}"
 
+# Render operator's Service Webhook
+MANIFEST_PRINT_SERVICE_WEBHOOK="${MANIFEST_PRINT_SERVICE_WEBHOOK:-"yes"}"
+
 ##################################
 ##
 ##     Render .yaml manifest
@@ -138,6 +141,14 @@ if [[ "${MANIFEST_PRINT_CRD}" == "yes" ]]; then
     cat "${TEMPLATES_DIR}/${SECTION_FILE_NAME}" | \
         OPERATOR_VERSION="${OPERATOR_VERSION}"    \
         envsubst
+
+    # Render Operation
+    SECTION_FILE_NAME="clickhouse-operator-install-yaml-template-01-section-crd-04-operation.yaml"
+    ensure_file "${TEMPLATES_DIR}" "${SECTION_FILE_NAME}" "${REPO_PATH_TEMPLATES_PATH}"
+    render_separator
+    cat "${TEMPLATES_DIR}/${SECTION_FILE_NAME}" | \
+        OPERATOR_VERSION="${OPERATOR_VERSION}"    \
+        envsubst
 fi
 
 # Render RBAC section for ClusterRole
@@ -376,3 +387,15 @@ if [[ "${MANIFEST_PRINT_SERVICE_METRICS}" == "yes" ]]; then
         OPERATOR_VERSION="${OPERATOR_VERSION}"    \
         envsubst
 fi
+
+# Render Service Webhook section
+if [[ "${MANIFEST_PRINT_SERVICE_WEBHOOK}" == "yes" ]]; then
+    SECTION_FILE_NAME="clickhouse-operator-install-yaml-template-05-section-service-webhook.yaml"
+    ensure_file "${TEMPLATES_DIR}" "${SECTION_FILE_NAME}" "${REPO_PATH_TEMPLATES_PATH}"
+    render_separator
+    cat "${TEMPLATES_DIR}/${SECTION_FILE_NAME}" | \
+        COMMENT="$(cut_namespace_for_kubectl "${OPERATOR_NAMESPACE}")" \
+        NAMESPACE="${OPERATOR_NAMESPACE}"         \
+        OPERATOR_VERSION="${OPERATOR_VERSION}"    \
+        envsubst
+fi
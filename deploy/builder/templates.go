@@ -0,0 +1,30 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+// Package builder provides CRD templates and operator config templates installation manifests are built with
+package builder
+
+import "embed"
+
+// Paths of templates within FS
+const (
+	// InstallBundlePath is a path of templates of installation manifest sections
+	InstallBundlePath = "templates-install-bundle"
+	// ConfigPath is a path of templates of operator config files
+	ConfigPath = "templates-config"
+)
+
+// FS contains templates of installation manifest sections and operator config files
+//
+//go:embed templates-install-bundle/*.yaml templates-config
+var FS embed.FS
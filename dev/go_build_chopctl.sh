@@ -0,0 +1,11 @@
+#!/bin/bash
+
+# Source configuration
+CUR_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" >/dev/null 2>&1 && pwd)"
+source "${CUR_DIR}/go_build_config.sh"
+
+# Build chopctl
+OUTPUT_BINARY="${CHOPCTL_BIN:-"${SRC_ROOT}/dev/bin/chopctl"}"
+MAIN_SRC_FILE="${SRC_ROOT}/cmd/chopctl/main.go"
+
+source "${CUR_DIR}/go_build_universal.sh"
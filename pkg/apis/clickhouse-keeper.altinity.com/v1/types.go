@@ -73,6 +73,14 @@ func (chk *ClickHouseKeeperInstallation) HasStatus() bool {
 	return chk.Status != nil
 }
 
+// IsReconfiguring checks whether ensemble of the CHK is being scaled or rolling-upgraded
+func (chk *ClickHouseKeeperInstallation) IsReconfiguring() bool {
+	if !chk.HasStatus() {
+		return false
+	}
+	return chk.Status.Status == StatusReconfiguring
+}
+
 // HasAncestor checks whether CHI has an ancestor
 func (chk *ClickHouseKeeperInstallation) HasAncestor() bool {
 	if !chk.HasStatus() {
@@ -153,8 +161,9 @@ func (chk *ClickHouseKeeperInstallation) MergeFrom(from *ClickHouseKeeperInstall
 
 // ChkSpec defines spec section of ClickHouseKeeper resource
 type ChkSpec struct {
-	Configuration *ChkConfiguration `json:"configuration,omitempty"          yaml:"configuration,omitempty"`
-	Templates     *ChkTemplates     `json:"templates,omitempty"              yaml:"templates,omitempty"`
+	Configuration *ChkConfiguration     `json:"configuration,omitempty"          yaml:"configuration,omitempty"`
+	Templates     *ChkTemplates         `json:"templates,omitempty"              yaml:"templates,omitempty"`
+	Validation    *apiChi.ChiValidation `json:"validation,omitempty"             yaml:"validation,omitempty"`
 }
 
 func (spec ChkSpec) GetConfiguration() *ChkConfiguration {
@@ -180,6 +189,7 @@ func (spec *ChkSpec) MergeFrom(from *ChkSpec, _type apiChi.MergeType) {
 
 	spec.Configuration = spec.Configuration.MergeFrom(from.Configuration, _type)
 	spec.Templates = spec.Templates.MergeFrom(from.Templates, _type)
+	spec.Validation = spec.Validation.MergeFrom(from.Validation, _type)
 }
 
 // ChkConfiguration defines configuration section of .spec
@@ -541,6 +551,16 @@ func (spec *ChkSpec) GetPrometheusPort() int {
 	return spec.GetPort("prometheus/port", -1)
 }
 
+// Possible values of CHK status
+const (
+	// StatusCompleted means ensemble has reached its desired state and all members are ready
+	StatusCompleted = "Completed"
+	// StatusInProgress means ensemble has reached its desired state, but not all members are ready yet
+	StatusInProgress = "In progress"
+	// StatusReconfiguring means ensemble is being scaled or rolling-upgraded one member at a time
+	StatusReconfiguring = "Reconfiguring"
+)
+
 // ChkStatus defines status section of ClickHouseKeeper resource
 type ChkStatus struct {
 	CHOpVersion string `json:"chop-version,omitempty"           yaml:"chop-version,omitempty"`
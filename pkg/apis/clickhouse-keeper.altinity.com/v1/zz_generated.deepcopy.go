@@ -108,6 +108,11 @@ func (in *ChkSpec) DeepCopyInto(out *ChkSpec) {
 		*out = new(ChkTemplates)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Validation != nil {
+		in, out := &in.Validation, &out.Validation
+		*out = new(clickhousealtinitycomv1.ChiValidation)
+		**out = **in
+	}
 	return
 }
 
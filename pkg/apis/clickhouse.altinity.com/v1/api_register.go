@@ -43,6 +43,8 @@ func init() {
 		&ClickHouseInstallationList{},
 		&ClickHouseInstallationTemplate{},
 		&ClickHouseInstallationTemplateList{},
+		&ClickHouseOperation{},
+		&ClickHouseOperationList{},
 		&ClickHouseOperatorConfiguration{},
 		&ClickHouseOperatorConfigurationList{},
 	)
@@ -23,4 +23,5 @@ const (
 	ClickHouseInstallationCRDResourceKind         = "ClickHouseInstallation"
 	ClickHouseInstallationTemplateCRDResourceKind = "ClickHouseInstallationTemplate"
 	ClickHouseOperatorCRDResourceKind             = "ClickHouseOperator"
+	ClickHouseOperationCRDResourceKind            = "ClickHouseOperation"
 )
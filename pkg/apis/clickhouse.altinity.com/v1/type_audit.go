@@ -0,0 +1,163 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import "strconv"
+
+// System log tables audit records are kept in
+const (
+	AuditQueryLog   = "query_log"
+	AuditSessionLog = "session_log"
+)
+
+// ChiAudit defines audit logging of queries and sessions, configured uniformly across all hosts.
+// Audit records are kept in system log tables, which are placed into dedicated database and storage and expired by TTL
+type ChiAudit struct {
+	// Enabled specifies whether queries are logged into query_log
+	Enabled *StringBool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// Sessions specifies whether logins and logouts are logged into session_log
+	Sessions *StringBool `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+	// Database specifies database audit log tables are kept in, "system" by default
+	Database string `json:"database,omitempty" yaml:"database,omitempty"`
+	// StoragePolicy specifies storage policy audit log tables are kept at, so audit records go to a dedicated disk
+	StoragePolicy string `json:"storagePolicy,omitempty" yaml:"storagePolicy,omitempty"`
+	// TTL specifies how long audit records are kept, as ClickHouse interval, such as "30 DAY"
+	TTL string `json:"ttl,omitempty" yaml:"ttl,omitempty"`
+	// FlushIntervalMilliseconds specifies how often audit records are flushed into tables
+	FlushIntervalMilliseconds int `json:"flushIntervalMilliseconds,omitempty" yaml:"flushIntervalMilliseconds,omitempty"`
+}
+
+// IsEnabled checks whether queries are logged
+func (a *ChiAudit) IsEnabled() bool {
+	if a == nil {
+		return false
+	}
+	return a.Enabled.Value()
+}
+
+// IsSessionsEnabled checks whether sessions are logged
+func (a *ChiAudit) IsSessionsEnabled() bool {
+	if a == nil {
+		return false
+	}
+	return a.Sessions.Value()
+}
+
+// GetLogs gets system log tables audit records are kept in
+func (a *ChiAudit) GetLogs() (logs []string) {
+	if a.IsEnabled() {
+		logs = append(logs, AuditQueryLog)
+	}
+	if a.IsSessionsEnabled() {
+		logs = append(logs, AuditSessionLog)
+	}
+	return logs
+}
+
+// GetServerSettings gets server settings of audit log tables
+func (a *ChiAudit) GetServerSettings() map[string]string {
+	settings := make(map[string]string)
+	for _, log := range a.GetLogs() {
+		// Presence of the section enables the log
+		settings[log+"/table"] = log
+		if a.Database != "" {
+			settings[log+"/database"] = a.Database
+		}
+		if a.StoragePolicy != "" {
+			settings[log+"/storage_policy"] = a.StoragePolicy
+		}
+		if a.TTL != "" {
+			settings[log+"/ttl"] = "event_date + INTERVAL " + a.TTL + " DELETE"
+		}
+		if a.FlushIntervalMilliseconds > 0 {
+			settings[log+"/flush_interval_milliseconds"] = strconv.Itoa(a.FlushIntervalMilliseconds)
+		}
+	}
+	return settings
+}
+
+// GetProfileSettings gets settings of the default profile.
+// Queries are logged from the start along with formatted query text and profile events, so records are complete
+func (a *ChiAudit) GetProfileSettings() map[string]string {
+	if !a.IsEnabled() {
+		return nil
+	}
+	return map[string]string{
+		"log_queries":           "1",
+		"log_queries_min_type":  "QUERY_START",
+		"log_formatted_queries": "1",
+		"log_profile_events":    "1",
+	}
+}
+
+// MergeFrom merges from specified audit
+func (a *ChiAudit) MergeFrom(from *ChiAudit, _type MergeType) *ChiAudit {
+	if from == nil {
+		return a
+	}
+
+	if a == nil {
+		a = new(ChiAudit)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if !a.Enabled.HasValue() {
+			a.Enabled = a.Enabled.MergeFrom(from.Enabled)
+		}
+		if !a.Sessions.HasValue() {
+			a.Sessions = a.Sessions.MergeFrom(from.Sessions)
+		}
+		if a.Database == "" {
+			a.Database = from.Database
+		}
+		if a.StoragePolicy == "" {
+			a.StoragePolicy = from.StoragePolicy
+		}
+		if a.TTL == "" {
+			a.TTL = from.TTL
+		}
+		if a.FlushIntervalMilliseconds == 0 {
+			a.FlushIntervalMilliseconds = from.FlushIntervalMilliseconds
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Enabled.HasValue() {
+			// Override by non-empty values only
+			a.Enabled = a.Enabled.MergeFrom(from.Enabled)
+		}
+		if from.Sessions.HasValue() {
+			// Override by non-empty values only
+			a.Sessions = a.Sessions.MergeFrom(from.Sessions)
+		}
+		if from.Database != "" {
+			// Override by non-empty values only
+			a.Database = from.Database
+		}
+		if from.StoragePolicy != "" {
+			// Override by non-empty values only
+			a.StoragePolicy = from.StoragePolicy
+		}
+		if from.TTL != "" {
+			// Override by non-empty values only
+			a.TTL = from.TTL
+		}
+		if from.FlushIntervalMilliseconds != 0 {
+			// Override by non-empty values only
+			a.FlushIntervalMilliseconds = from.FlushIntervalMilliseconds
+		}
+	}
+
+	return a
+}
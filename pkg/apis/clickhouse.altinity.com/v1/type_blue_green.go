@@ -0,0 +1,122 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// Possible blue/green generation colors
+const (
+	BlueGreenColorBlue  = "blue"
+	BlueGreenColorGreen = "green"
+)
+
+// defaultBlueGreenMaxReplicationDelay specifies default max replication delay (in seconds), allowed for the generation
+// being switched to
+const defaultBlueGreenMaxReplicationDelay = 60
+
+// ChiBlueGreen defines blue/green generation of the CHI.
+// Two CHIs (blue and green generations) share one stable common service.
+// The operator switches selector of the common service to the generation requested to be active
+// after the generation passes health and data-freshness checks.
+type ChiBlueGreen struct {
+	// Service specifies name of the common service shared by blue and green generations
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+	// Color specifies color of the generation
+	Color string `json:"color,omitempty" yaml:"color,omitempty"`
+	// Active specifies whether the generation is requested to serve the common service
+	Active *StringBool `json:"active,omitempty" yaml:"active,omitempty"`
+	// MaxReplicationDelay specifies max replication delay (in seconds) of the generation, allowed to switch to it
+	MaxReplicationDelay int `json:"maxReplicationDelay,omitempty" yaml:"maxReplicationDelay,omitempty"`
+}
+
+// NewChiBlueGreen creates new blue/green generation
+func NewChiBlueGreen() *ChiBlueGreen {
+	return new(ChiBlueGreen)
+}
+
+// GetService gets name of the common service
+func (bg *ChiBlueGreen) GetService() string {
+	if bg == nil {
+		return ""
+	}
+	return bg.Service
+}
+
+// HasService checks whether common service is specified
+func (bg *ChiBlueGreen) HasService() bool {
+	return bg.GetService() != ""
+}
+
+// GetColor gets color of the generation
+func (bg *ChiBlueGreen) GetColor() string {
+	if bg == nil {
+		return ""
+	}
+	return bg.Color
+}
+
+// IsActive checks whether the generation is requested to serve the common service
+func (bg *ChiBlueGreen) IsActive() bool {
+	if bg == nil {
+		return false
+	}
+	return bg.Active.Value()
+}
+
+// GetMaxReplicationDelay gets max replication delay (in seconds), allowed to switch to the generation
+func (bg *ChiBlueGreen) GetMaxReplicationDelay() int {
+	if (bg == nil) || (bg.MaxReplicationDelay <= 0) {
+		return defaultBlueGreenMaxReplicationDelay
+	}
+	return bg.MaxReplicationDelay
+}
+
+// MergeFrom merges from specified blue/green generation
+func (bg *ChiBlueGreen) MergeFrom(from *ChiBlueGreen, _type MergeType) *ChiBlueGreen {
+	if from == nil {
+		return bg
+	}
+
+	if bg == nil {
+		bg = NewChiBlueGreen()
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if bg.Service == "" {
+			bg.Service = from.Service
+		}
+		if bg.Color == "" {
+			bg.Color = from.Color
+		}
+		if bg.MaxReplicationDelay == 0 {
+			bg.MaxReplicationDelay = from.MaxReplicationDelay
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Service != "" {
+			// Override by non-empty values only
+			bg.Service = from.Service
+		}
+		if from.Color != "" {
+			// Override by non-empty values only
+			bg.Color = from.Color
+		}
+		if from.MaxReplicationDelay != 0 {
+			// Override by non-empty values only
+			bg.MaxReplicationDelay = from.MaxReplicationDelay
+		}
+	}
+	bg.Active = bg.Active.MergeFrom(from.Active)
+
+	return bg
+}
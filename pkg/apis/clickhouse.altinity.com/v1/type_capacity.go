@@ -0,0 +1,89 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// ChiCapacityMaintenance defines reporting of resources footprint of the CHI.
+// Requested resources of pods and PVCs along with resources used by ClickHouse are aggregated
+// over all hosts of the CHI into status and metrics on every maintenance run.
+type ChiCapacityMaintenance struct {
+	// Enabled specifies whether footprint of the CHI is reported
+	Enabled *StringBool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+}
+
+// IsEnabled checks whether footprint of the CHI is reported
+func (p *ChiCapacityMaintenance) IsEnabled() bool {
+	if p == nil {
+		return false
+	}
+	return p.Enabled.Value()
+}
+
+// MergeFrom merges from specified capacity maintenance policy
+func (p *ChiCapacityMaintenance) MergeFrom(from *ChiCapacityMaintenance, _type MergeType) *ChiCapacityMaintenance {
+	if from == nil {
+		return p
+	}
+
+	if p == nil {
+		p = new(ChiCapacityMaintenance)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if !p.Enabled.HasValue() {
+			p.Enabled = p.Enabled.MergeFrom(from.Enabled)
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Enabled.HasValue() {
+			// Override by non-empty values only
+			p.Enabled = p.Enabled.MergeFrom(from.Enabled)
+		}
+	}
+
+	return p
+}
+
+// ChiCapacityStatus defines resources footprint of the CHI, aggregated over all its pods and PVCs.
+// Quantities are specified in k8s notation
+type ChiCapacityStatus struct {
+	// Pods specifies number of pods of the CHI
+	Pods int `json:"pods,omitempty" yaml:"pods,omitempty"`
+	// PVCs specifies number of PVCs of the CHI
+	PVCs int `json:"pvcs,omitempty" yaml:"pvcs,omitempty"`
+	// CPURequests specifies CPU requested by containers of the pods
+	CPURequests string `json:"cpuRequests,omitempty" yaml:"cpuRequests,omitempty"`
+	// CPULimits specifies CPU limits of containers of the pods
+	CPULimits string `json:"cpuLimits,omitempty" yaml:"cpuLimits,omitempty"`
+	// MemoryRequests specifies memory requested by containers of the pods
+	MemoryRequests string `json:"memoryRequests,omitempty" yaml:"memoryRequests,omitempty"`
+	// MemoryLimits specifies memory limits of containers of the pods
+	MemoryLimits string `json:"memoryLimits,omitempty" yaml:"memoryLimits,omitempty"`
+	// MemoryUsed specifies resident memory of ClickHouse servers
+	MemoryUsed string `json:"memoryUsed,omitempty" yaml:"memoryUsed,omitempty"`
+	// StorageRequests specifies storage requested by PVCs
+	StorageRequests string `json:"storageRequests,omitempty" yaml:"storageRequests,omitempty"`
+	// StorageCapacity specifies storage provisioned to PVCs
+	StorageCapacity string `json:"storageCapacity,omitempty" yaml:"storageCapacity,omitempty"`
+	// StorageUsed specifies space used on disks of ClickHouse servers
+	StorageUsed string `json:"storageUsed,omitempty" yaml:"storageUsed,omitempty"`
+}
+
+// Equal checks whether capacity statuses are equal
+func (s *ChiCapacityStatus) Equal(b *ChiCapacityStatus) bool {
+	if (s == nil) || (b == nil) {
+		return s == b
+	}
+	return *s == *b
+}
@@ -28,11 +28,12 @@ import (
 )
 
 // FillStatus fills .Status
-func (chi *ClickHouseInstallation) FillStatus(endpoint string, pods, fqdns []string, ip string) {
+func (chi *ClickHouseInstallation) FillStatus(endpoint string, connection *ChiConnectionStatus, pods, fqdns []string, ip string) {
 	chi.EnsureStatus().Fill(&FillStatusParams{
 		CHOpIP:              ip,
 		ClustersCount:       chi.ClustersCount(),
 		ShardsCount:         chi.ShardsCount(),
+		ReplicasCount:       chi.ReplicasCount(),
 		HostsCount:          chi.HostsCount(),
 		TaskID:              chi.Spec.GetTaskID(),
 		HostsUpdatedCount:   0,
@@ -44,6 +45,7 @@ func (chi *ClickHouseInstallation) FillStatus(endpoint string, pods, fqdns []str
 		Pods:                pods,
 		FQDNs:               fqdns,
 		Endpoint:            endpoint,
+		Connection:          connection,
 		NormalizedCHI: chi.Copy(CopyCHIOptions{
 			SkipStatus:        true,
 			SkipManagedFields: true,
@@ -398,6 +400,9 @@ func (spec *ChiSpec) MergeFrom(from *ChiSpec, _type MergeType) {
 		if spec.NamespaceDomainPattern == "" {
 			spec.NamespaceDomainPattern = from.NamespaceDomainPattern
 		}
+		if spec.Preset == "" {
+			spec.Preset = from.Preset
+		}
 	case MergeTypeOverrideByNonEmptyValues:
 		if from.HasTaskID() {
 			spec.TaskID = from.TaskID
@@ -417,6 +422,10 @@ func (spec *ChiSpec) MergeFrom(from *ChiSpec, _type MergeType) {
 		if from.NamespaceDomainPattern != "" {
 			spec.NamespaceDomainPattern = from.NamespaceDomainPattern
 		}
+		if from.Preset != "" {
+			// Override by non-empty values only
+			spec.Preset = from.Preset
+		}
 	}
 
 	spec.Templating = spec.Templating.MergeFrom(from.Templating, _type)
@@ -424,6 +433,14 @@ func (spec *ChiSpec) MergeFrom(from *ChiSpec, _type MergeType) {
 	spec.Defaults = spec.Defaults.MergeFrom(from.Defaults, _type)
 	spec.Configuration = spec.Configuration.MergeFrom(from.Configuration, _type)
 	spec.Templates = spec.Templates.MergeFrom(from.Templates, _type)
+	spec.UpgradeVerification = spec.UpgradeVerification.MergeFrom(from.UpgradeVerification, _type)
+	spec.BlueGreen = spec.BlueGreen.MergeFrom(from.BlueGreen, _type)
+	spec.Maintenance = spec.Maintenance.MergeFrom(from.Maintenance, _type)
+	spec.HostMacros = spec.HostMacros.MergeFrom(from.HostMacros, _type)
+	spec.CrossRegion = spec.CrossRegion.MergeFrom(from.CrossRegion, _type)
+	spec.Validation = spec.Validation.MergeFrom(from.Validation, _type)
+	spec.Connection = spec.Connection.MergeFrom(from.Connection, _type)
+	spec.ServiceBinding = spec.ServiceBinding.MergeFrom(from.ServiceBinding, _type)
 	// TODO may be it would be wiser to make more intelligent merge
 	spec.UseTemplates = append(spec.UseTemplates, from.UseTemplates...)
 }
@@ -480,6 +497,18 @@ func (chi *ClickHouseInstallation) ShardsCount() int {
 	return count
 }
 
+// ReplicasCount counts max number of replicas among clusters
+func (chi *ClickHouseInstallation) ReplicasCount() int {
+	count := 0
+	chi.WalkClusters(func(cluster *Cluster) error {
+		if cluster.Layout != nil && cluster.Layout.ReplicasCount > count {
+			count = cluster.Layout.ReplicasCount
+		}
+		return nil
+	})
+	return count
+}
+
 // HostsCount counts hosts
 func (chi *ClickHouseInstallation) HostsCount() int {
 	count := 0
@@ -628,6 +657,19 @@ func (chi *ClickHouseInstallation) IsRollingUpdate() bool {
 	return chi.Spec.Restart == RestartRollingUpdate
 }
 
+// HasStandby checks whether at least one cluster of the CHI has standby hosts
+func (chi *ClickHouseInstallation) HasStandby() bool {
+	if (chi == nil) || (chi.Spec.Configuration == nil) {
+		return false
+	}
+	for _, cluster := range chi.Spec.Configuration.Clusters {
+		if cluster.Standby.GetReplicas() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // IsTroubleshoot checks whether CHI is in troubleshoot mode
 func (chi *ClickHouseInstallation) IsTroubleshoot() bool {
 	if chi == nil {
@@ -0,0 +1,105 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// Verification modes of client certificates
+const (
+	// ClientCertificatesVerificationModeRelaxed verifies certificates provided by clients,
+	// clients without certificate are still accepted and authenticated by password
+	ClientCertificatesVerificationModeRelaxed = "relaxed"
+	// ClientCertificatesVerificationModeStrict requires all clients to provide certificates
+	ClientCertificatesVerificationModeStrict = "strict"
+)
+
+// ChiClientCertificates defines users authenticated by X.509 client certificates instead of passwords.
+// Client certificates are verified against CA, specified by the Secret ref
+type ChiClientCertificates struct {
+	// CA specifies Secret key with CA certificate client certificates are verified against.
+	// Goes into server config as openSSL/server/caConfig
+	CA *SettingSource `json:"ca,omitempty" yaml:"ca,omitempty"`
+	// VerificationMode specifies how client certificates are verified. Goes into server config as openSSL/server/verificationMode
+	VerificationMode string `json:"verificationMode,omitempty" yaml:"verificationMode,omitempty"`
+	// Users specifies common names of client certificates per user. Goes into users as ssl_certificates/common_name
+	Users map[string][]string `json:"users,omitempty" yaml:"users,omitempty"`
+}
+
+// GetCA gets Secret ref of CA certificate
+func (c *ChiClientCertificates) GetCA() *SettingSource {
+	if c == nil {
+		return nil
+	}
+	return c.CA
+}
+
+// GetVerificationMode gets verification mode of client certificates
+func (c *ChiClientCertificates) GetVerificationMode() string {
+	if c == nil {
+		return ""
+	}
+	if c.VerificationMode == "" {
+		return ClientCertificatesVerificationModeRelaxed
+	}
+	return c.VerificationMode
+}
+
+// GetUsers gets common names of client certificates per user
+func (c *ChiClientCertificates) GetUsers() map[string][]string {
+	if c == nil {
+		return nil
+	}
+	return c.Users
+}
+
+// MergeFrom merges from specified client certificates
+func (c *ChiClientCertificates) MergeFrom(from *ChiClientCertificates, _type MergeType) *ChiClientCertificates {
+	if from == nil {
+		return c
+	}
+
+	if c == nil {
+		c = new(ChiClientCertificates)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if c.CA == nil {
+			c.CA = from.CA
+		}
+		if c.VerificationMode == "" {
+			c.VerificationMode = from.VerificationMode
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.CA != nil {
+			// Override by non-empty values only
+			c.CA = from.CA
+		}
+		if from.VerificationMode != "" {
+			// Override by non-empty values only
+			c.VerificationMode = from.VerificationMode
+		}
+	}
+
+	// Users are merged user by user, own common names of the user are preferred
+	for username, commonNames := range from.Users {
+		if c.Users == nil {
+			c.Users = make(map[string][]string)
+		}
+		if _, ok := c.Users[username]; !ok || (_type == MergeTypeOverrideByNonEmptyValues) {
+			c.Users[username] = commonNames
+		}
+	}
+
+	return c
+}
@@ -16,16 +16,20 @@ package v1
 
 // Cluster defines item of a clusters section of .configuration
 type Cluster struct {
-	Name         string              `json:"name,omitempty"         yaml:"name,omitempty"`
-	Zookeeper    *ChiZookeeperConfig `json:"zookeeper,omitempty"    yaml:"zookeeper,omitempty"`
-	Settings     *Settings           `json:"settings,omitempty"     yaml:"settings,omitempty"`
-	Files        *Settings           `json:"files,omitempty"        yaml:"files,omitempty"`
-	Templates    *ChiTemplateNames   `json:"templates,omitempty"    yaml:"templates,omitempty"`
-	SchemaPolicy *SchemaPolicy       `json:"schemaPolicy,omitempty" yaml:"schemaPolicy,omitempty"`
-	Insecure     *StringBool         `json:"insecure,omitempty"     yaml:"insecure,omitempty"`
-	Secure       *StringBool         `json:"secure,omitempty"       yaml:"secure,omitempty"`
-	Secret       *ClusterSecret      `json:"secret,omitempty"       yaml:"secret,omitempty"`
-	Layout       *ChiClusterLayout   `json:"layout,omitempty"       yaml:"layout,omitempty"`
+	Name                  string                    `json:"name,omitempty"                  yaml:"name,omitempty"`
+	Zookeeper             *ChiZookeeperConfig       `json:"zookeeper,omitempty"             yaml:"zookeeper,omitempty"`
+	Settings              *Settings                 `json:"settings,omitempty"              yaml:"settings,omitempty"`
+	Files                 *Settings                 `json:"files,omitempty"                 yaml:"files,omitempty"`
+	Templates             *ChiTemplateNames         `json:"templates,omitempty"             yaml:"templates,omitempty"`
+	SchemaPolicy          *SchemaPolicy             `json:"schemaPolicy,omitempty"          yaml:"schemaPolicy,omitempty"`
+	Insecure              *StringBool               `json:"insecure,omitempty"              yaml:"insecure,omitempty"`
+	Secure                *StringBool               `json:"secure,omitempty"                yaml:"secure,omitempty"`
+	Secret                *ClusterSecret            `json:"secret,omitempty"                yaml:"secret,omitempty"`
+	Layout                *ChiClusterLayout         `json:"layout,omitempty"                yaml:"layout,omitempty"`
+	ReplicatedDatabases   []ChiReplicatedDatabase   `json:"replicatedDatabases,omitempty"   yaml:"replicatedDatabases,omitempty"`
+	ReplicationThrottling *ChiReplicationThrottling `json:"replicationThrottling,omitempty" yaml:"replicationThrottling,omitempty"`
+	Standby               *ChiStandby               `json:"standby,omitempty"               yaml:"standby,omitempty"`
+	Metadata              *ChiScopeMetadata         `json:"metadata,omitempty"              yaml:"metadata,omitempty"`
 
 	// Internal data
 	Address ChiClusterAddress       `json:"-" yaml:"-"`
@@ -30,12 +30,22 @@ const (
 
 // Configuration defines configuration section of .spec
 type Configuration struct {
-	Zookeeper *ChiZookeeperConfig `json:"zookeeper,omitempty" yaml:"zookeeper,omitempty"`
-	Users     *Settings           `json:"users,omitempty"     yaml:"users,omitempty"`
-	Profiles  *Settings           `json:"profiles,omitempty"  yaml:"profiles,omitempty"`
-	Quotas    *Settings           `json:"quotas,omitempty"    yaml:"quotas,omitempty"`
-	Settings  *Settings           `json:"settings,omitempty"  yaml:"settings,omitempty"`
-	Files     *Settings           `json:"files,omitempty"     yaml:"files,omitempty"`
+	Zookeeper          *ChiZookeeperConfig    `json:"zookeeper,omitempty"          yaml:"zookeeper,omitempty"`
+	Users              *Settings              `json:"users,omitempty"              yaml:"users,omitempty"`
+	Profiles           *Settings              `json:"profiles,omitempty"           yaml:"profiles,omitempty"`
+	Quotas             *Settings              `json:"quotas,omitempty"             yaml:"quotas,omitempty"`
+	Settings           *Settings              `json:"settings,omitempty"           yaml:"settings,omitempty"`
+	Files              *Settings              `json:"files,omitempty"              yaml:"files,omitempty"`
+	Guards             *ChiGuards             `json:"guards,omitempty"             yaml:"guards,omitempty"`
+	ClientCertificates *ChiClientCertificates `json:"clientCertificates,omitempty" yaml:"clientCertificates,omitempty"`
+	Audit              *ChiAudit              `json:"audit,omitempty"              yaml:"audit,omitempty"`
+	Monitoring         *ChiMonitoring         `json:"monitoring,omitempty"         yaml:"monitoring,omitempty"`
+	// RemoteClusters specifies custom clusters written into remote_servers along with generated ones
+	RemoteClusters []ChiRemoteCluster `json:"remoteClusters,omitempty" yaml:"remoteClusters,omitempty"`
+	// UsersFrom specifies existing ConfigMaps and Secrets with users.d files maintained outside of the operator
+	UsersFrom []ChiUsersSource `json:"usersFrom,omitempty" yaml:"usersFrom,omitempty"`
+	// UserDefaults specifies default database, default roles and allowed hosts per user
+	UserDefaults map[string]*ChiUserDefaults `json:"userDefaults,omitempty" yaml:"userDefaults,omitempty"`
 	// TODO refactor into map[string]ChiCluster
 	Clusters []*Cluster `json:"clusters,omitempty"  yaml:"clusters,omitempty"`
 }
@@ -45,6 +55,30 @@ func NewConfiguration() *Configuration {
 	return new(Configuration)
 }
 
+// GetUsersFrom gets existing ConfigMaps and Secrets with users.d files
+func (configuration *Configuration) GetUsersFrom() []ChiUsersSource {
+	if configuration == nil {
+		return nil
+	}
+	return configuration.UsersFrom
+}
+
+// GetMonitoring gets monitoring schema specification
+func (configuration *Configuration) GetMonitoring() *ChiMonitoring {
+	if configuration == nil {
+		return nil
+	}
+	return configuration.Monitoring
+}
+
+// GetUserDefaults gets defaults per user
+func (configuration *Configuration) GetUserDefaults() map[string]*ChiUserDefaults {
+	if configuration == nil {
+		return nil
+	}
+	return configuration.UserDefaults
+}
+
 // MergeFrom merges from specified source
 func (configuration *Configuration) MergeFrom(from *Configuration, _type MergeType) *Configuration {
 	if from == nil {
@@ -61,6 +95,13 @@ func (configuration *Configuration) MergeFrom(from *Configuration, _type MergeTy
 	configuration.Quotas = configuration.Quotas.MergeFrom(from.Quotas)
 	configuration.Settings = configuration.Settings.MergeFrom(from.Settings)
 	configuration.Files = configuration.Files.MergeFrom(from.Files)
+	configuration.Guards = configuration.Guards.MergeFrom(from.Guards, _type)
+	configuration.ClientCertificates = configuration.ClientCertificates.MergeFrom(from.ClientCertificates, _type)
+	configuration.Audit = configuration.Audit.MergeFrom(from.Audit, _type)
+	configuration.Monitoring = configuration.Monitoring.MergeFrom(from.Monitoring, _type)
+	configuration.RemoteClusters = MergeRemoteClustersFrom(configuration.RemoteClusters, from.RemoteClusters, _type)
+	configuration.UsersFrom = MergeUsersSourcesFrom(configuration.UsersFrom, from.UsersFrom)
+	configuration.UserDefaults = MergeUserDefaultsFrom(configuration.UserDefaults, from.UserDefaults, _type)
 
 	// TODO merge clusters
 	// Copy Clusters for now
@@ -99,6 +99,13 @@ const (
 	// Used in case no other specified in config
 	DefaultReconcileSystemThreadsNumber = 1
 
+	// DefaultReconcileOperationsThreadsNumber specifies default number of controller threads running operations
+	// and restarts of hosts. Operations and restarts may block for long, so they do not share threads with system events
+	DefaultReconcileOperationsThreadsNumber = 1
+
+	// DefaultReconcileMaintenanceThreadsNumber specifies default number of controller threads running maintenance of CHIs
+	DefaultReconcileMaintenanceThreadsNumber = 1
+
 	// defaultTerminationGracePeriod specifies default value for TerminationGracePeriod
 	defaultTerminationGracePeriod = 30
 	// defaultRevisionHistoryLimit specifies default value for RevisionHistoryLimit
@@ -174,6 +181,47 @@ type OperatorConfigConfig struct {
 	Network struct {
 		HostRegexpTemplate string `json:"hostRegexpTemplate" yaml:"hostRegexpTemplate"`
 	} `json:"network" yaml:"network"`
+
+	// Overlays specifies config generated into CHIs selected by namespace and/or labels
+	Overlays []OperatorConfigOverlay `json:"overlays,omitempty" yaml:"overlays,omitempty"`
+}
+
+// OperatorConfigOverlay specifies config, which is merged into generated config of selected CHIs,
+// so policy is applied to, for example, all CHIs of a namespace without touching CHIs themselves
+type OperatorConfigOverlay struct {
+	// Name of the overlay, is used in names of generated config files
+	Name string `json:"name" yaml:"name"`
+	// Namespaces selects CHIs by namespace. Empty list selects CHIs of any namespace
+	Namespaces []string `json:"namespaces,omitempty" yaml:"namespaces,omitempty"`
+	// Labels selects CHIs having all of the labels. Empty map selects CHIs with any labels
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Settings are generated into config.d. Settings of the CHI itself take precedence
+	Settings map[string]string `json:"settings,omitempty" yaml:"settings,omitempty"`
+	// Files maps "file name->file content" of extra config files
+	Files OperatorConfigOverlayFiles `json:"files,omitempty" yaml:"files,omitempty"`
+}
+
+// OperatorConfigOverlayFiles specifies extra config files of an overlay
+type OperatorConfigOverlayFiles struct {
+	Common map[string]string `json:"common,omitempty" yaml:"common,omitempty"`
+	Host   map[string]string `json:"host,omitempty"   yaml:"host,omitempty"`
+	Users  map[string]string `json:"users,omitempty"  yaml:"users,omitempty"`
+}
+
+// Match checks whether the overlay selects the CHI
+func (o *OperatorConfigOverlay) Match(chi *ClickHouseInstallation) bool {
+	if chi == nil {
+		return false
+	}
+	if (len(o.Namespaces) > 0) && !util.InArray(chi.Namespace, o.Namespaces) {
+		return false
+	}
+	for name, value := range o.Labels {
+		if existing, ok := chi.Labels[name]; !ok || (existing != value) {
+			return false
+		}
+	}
+	return true
 }
 
 // OperatorConfigRestartPolicyRuleSet specifies set of rules
@@ -277,7 +325,79 @@ type OperatorConfigClickHouse struct {
 		Timeouts struct {
 			Collect time.Duration `json:"collect" yaml:"collect"`
 		} `json:"timeouts" yaml:"timeouts"`
+		// Tables specifies how many tables are reported by per-table metrics
+		Tables OperatorConfigClickHouseMetricsTables `json:"tables" yaml:"tables"`
 	} `json:"metrics" yaml:"metrics"`
+
+	// Image specifies default ClickHouse images used by ClickHouse containers with no image specified
+	Image OperatorConfigClickHouseImage `json:"image" yaml:"image"`
+}
+
+// OperatorConfigClickHouseMetricsTables specifies cardinality of per-table metrics.
+// Tables neither included nor within top-N largest tables of the host are aggregated into one 'other' table
+type OperatorConfigClickHouseMetricsTables struct {
+	// TopN specifies how many largest tables of the host are reported with own database and table labels.
+	// All tables are reported in case not specified
+	TopN int `json:"topN,omitempty" yaml:"topN,omitempty"`
+	// Include lists regexps of 'database.table' names of tables always reported with own labels
+	Include []string `json:"include,omitempty" yaml:"include,omitempty"`
+}
+
+// IsLimited checks whether number of reported tables is limited
+func (t *OperatorConfigClickHouseMetricsTables) IsLimited() bool {
+	if t == nil {
+		return false
+	}
+	return t.TopN > 0
+}
+
+// IsIncluded checks whether the table is always reported with own labels
+func (t *OperatorConfigClickHouseMetricsTables) IsIncluded(database, table string) bool {
+	if t == nil {
+		return false
+	}
+	return util.InArrayWithRegexp(database+"."+table, t.Include)
+}
+
+// OperatorConfigClickHouseImage specifies default ClickHouse images per node architecture
+type OperatorConfigClickHouseImage struct {
+	// Default specifies image used in case no image is listed for the architecture
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+	// Architectures lists images per node architecture, as reported by 'kubernetes.io/arch' node label.
+	// The first listed image is used by hosts not bound to any architecture
+	Architectures []OperatorConfigClickHouseArchitectureImage `json:"architectures,omitempty" yaml:"architectures,omitempty"`
+}
+
+// OperatorConfigClickHouseArchitectureImage specifies ClickHouse image for node architecture
+type OperatorConfigClickHouseArchitectureImage struct {
+	Architecture string `json:"architecture" yaml:"architecture"`
+	Image        string `json:"image"        yaml:"image"`
+}
+
+// Select selects image for the architecture.
+// Empty architecture means host is not bound to any architecture, in this case the first listed image is selected
+// along with all architectures it is listed for, so multi-arch images are allowed to run on all of them.
+// Nil architectures list means any architecture is fine for the image
+func (i *OperatorConfigClickHouseImage) Select(architecture string) (image string, architectures []string) {
+	if architecture != "" {
+		for _, entry := range i.Architectures {
+			if entry.Architecture == architecture {
+				return entry.Image, nil
+			}
+		}
+		return i.Default, nil
+	}
+
+	if len(i.Architectures) == 0 {
+		return i.Default, nil
+	}
+	image = i.Architectures[0].Image
+	for _, entry := range i.Architectures {
+		if entry.Image == image {
+			architectures = append(architectures, entry.Architecture)
+		}
+	}
+	return image, architectures
 }
 
 // OperatorConfigTemplate specifies template section
@@ -316,6 +436,9 @@ type OperatorConfigCHI struct {
 	Policy OperatorConfigCHIPolicy `json:"policy" yaml:"policy"`
 	// Path where to look for ClickHouseInstallation templates .yaml files
 	Path string `json:"path" yaml:"path"`
+	// Presets maps preset name to the list of templates the preset consists of.
+	// CHI selects preset via `spec.preset`
+	Presets map[string][]ChiUseTemplate `json:"presets,omitempty" yaml:"presets,omitempty"`
 
 	Runtime OperatorConfigCHIRuntime `json:"runtime,omitempty" yaml:"runtime,omitempty"`
 }
@@ -341,6 +464,11 @@ type OperatorConfigReconcile struct {
 
 		// DEPRECATED, is replaced with reconcileCHIsThreadsNumber
 		ThreadsNumber int `json:"threadsNumber" yaml:"threadsNumber"`
+
+		// APIWritesQPS and APIWritesBurst specify budget of Kubernetes API writes of the operator.
+		// Writes are not limited, except by client-side throttling of the API client, in case not specified
+		APIWritesQPS   int `json:"apiWritesQPS"   yaml:"apiWritesQPS"`
+		APIWritesBurst int `json:"apiWritesBurst" yaml:"apiWritesBurst"`
 	} `json:"runtime" yaml:"runtime"`
 
 	StatefulSet struct {
@@ -627,6 +755,22 @@ func (c *OperatorConfig) FindTemplate(use *ChiUseTemplate, fallbackNamespace str
 	return nil
 }
 
+// GetPresetTemplates gets list of templates the specified preset consists of
+func (c *OperatorConfig) GetPresetTemplates(preset string) ([]ChiUseTemplate, bool) {
+	templates, ok := c.Template.CHI.Presets[preset]
+	return templates, ok
+}
+
+// GetOverlays gets overlays selecting the CHI, in order of the config
+func (c *OperatorConfig) GetOverlays(chi *ClickHouseInstallation) (overlays []*OperatorConfigOverlay) {
+	for i := range c.ClickHouse.Config.Overlays {
+		if overlay := &c.ClickHouse.Config.Overlays[i]; overlay.Match(chi) {
+			overlays = append(overlays, overlay)
+		}
+	}
+	return overlays
+}
+
 // GetAutoTemplates gets all auto templates.
 // Auto templates are sorted alphabetically by tuple: namespace, name
 func (c *OperatorConfig) GetAutoTemplates() []*ClickHouseInstallation {
@@ -710,6 +854,15 @@ func (c *OperatorConfig) normalizeSectionClickHouseConfigurationFile() {
 	util.PreparePath(&c.ClickHouse.Config.File.Path.User, c.Runtime.ConfigFolderPath, UsersConfigDir)
 }
 
+func (c *OperatorConfig) normalizeSectionClickHouseConfigurationOverlays() {
+	// Overlays with no name specified are named by their position
+	for i := range c.ClickHouse.Config.Overlays {
+		if c.ClickHouse.Config.Overlays[i].Name == "" {
+			c.ClickHouse.Config.Overlays[i].Name = strconv.Itoa(i)
+		}
+	}
+}
+
 func (c *OperatorConfig) normalizeSectionTemplate() {
 	p := c.Template.CHI.Policy
 	switch {
@@ -857,11 +1010,23 @@ func (c *OperatorConfig) normalizeSectionReconcileRuntime() {
 	if c.Reconcile.Runtime.ReconcileShardsMaxConcurrencyPercent == 0 {
 		c.Reconcile.Runtime.ReconcileShardsMaxConcurrencyPercent = defaultReconcileShardsMaxConcurrencyPercent
 	}
+	if (c.Reconcile.Runtime.APIWritesQPS > 0) && (c.Reconcile.Runtime.APIWritesBurst <= 0) {
+		// Burst of at least one write is required for writes to be done at all
+		c.Reconcile.Runtime.APIWritesBurst = c.Reconcile.Runtime.APIWritesQPS
+	}
 
 	//reconcileWaitExclude: true
 	//reconcileWaitInclude: false
 }
 
+// GetAPIWritesBudget gets QPS and burst of Kubernetes API writes of the operator. Zero QPS means unlimited writes
+func (c *OperatorConfig) GetAPIWritesBudget() (float32, int) {
+	if c == nil {
+		return 0, 0
+	}
+	return float32(c.Reconcile.Runtime.APIWritesQPS), c.Reconcile.Runtime.APIWritesBurst
+}
+
 func (c *OperatorConfig) normalizeSectionLabel() {
 	//config.IncludeIntoPropagationAnnotations
 	//config.ExcludeFromPropagationAnnotations
@@ -890,6 +1055,7 @@ func (c *OperatorConfig) normalize() {
 
 	c.normalizeSectionClickHouseConfigurationFile()
 	c.normalizeSectionClickHouseConfigurationUserDefault()
+	c.normalizeSectionClickHouseConfigurationOverlays()
 	c.normalizeSectionClickHouseAccess()
 	c.normalizeSectionClickHouseMetrics()
 	c.normalizeSectionTemplate()
@@ -0,0 +1,51 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// ChiConfigDirs defines full paths of folders generated ClickHouse config files are mounted into.
+// Custom ClickHouse builds may look for config files in folders, which differ from the ones of the official image.
+// Empty path means the default one is used.
+type ChiConfigDirs struct {
+	// Common specifies folder of common config files, 'config.d' in the official image
+	Common string `json:"common,omitempty" yaml:"common,omitempty"`
+	// Users specifies folder of users config files, 'users.d' in the official image
+	Users string `json:"users,omitempty"  yaml:"users,omitempty"`
+	// Host specifies folder of host config files, 'conf.d' in the official image
+	Host string `json:"host,omitempty"   yaml:"host,omitempty"`
+}
+
+// GetCommon gets folder of common config files or the default one
+func (d *ChiConfigDirs) GetCommon(_default string) string {
+	if (d == nil) || (d.Common == "") {
+		return _default
+	}
+	return d.Common
+}
+
+// GetUsers gets folder of users config files or the default one
+func (d *ChiConfigDirs) GetUsers(_default string) string {
+	if (d == nil) || (d.Users == "") {
+		return _default
+	}
+	return d.Users
+}
+
+// GetHost gets folder of host config files or the default one
+func (d *ChiConfigDirs) GetHost(_default string) string {
+	if (d == nil) || (d.Host == "") {
+		return _default
+	}
+	return d.Host
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Kinds of objects connection details are published into
+const (
+	ConnectionPublishConfigMap = "ConfigMap"
+	ConnectionPublishSecret    = "Secret"
+)
+
+// ChiConnection defines how connection details of the CHI are published for applications
+type ChiConnection struct {
+	// CASecret specifies name of the Secret with CA certificate, clients verify secure endpoints with
+	CASecret string `json:"caSecret,omitempty" yaml:"caSecret,omitempty"`
+	// Publish specifies kind of object connection details are published into, ConfigMap or Secret.
+	// Connection details are reported in status only by default
+	Publish string `json:"publish,omitempty" yaml:"publish,omitempty"`
+	// Name specifies name of the published object. Defaults to chi-{chi}-connection
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// ChiConnectionStatus defines ready-to-use connection details of the CHI
+type ChiConnectionStatus struct {
+	Host             string `json:"host,omitempty"             yaml:"host,omitempty"`
+	HTTPPort         int32  `json:"httpPort,omitempty"         yaml:"httpPort,omitempty"`
+	HTTPSPort        int32  `json:"httpsPort,omitempty"        yaml:"httpsPort,omitempty"`
+	NativePort       int32  `json:"nativePort,omitempty"       yaml:"nativePort,omitempty"`
+	NativeSecurePort int32  `json:"nativeSecurePort,omitempty" yaml:"nativeSecurePort,omitempty"`
+	HTTPURL          string `json:"httpURL,omitempty"          yaml:"httpURL,omitempty"`
+	// Secure specifies whether secure endpoints are available
+	Secure   bool   `json:"secure,omitempty"   yaml:"secure,omitempty"`
+	CASecret string `json:"caSecret,omitempty" yaml:"caSecret,omitempty"`
+}
+
+// GetCASecret gets name of the Secret with CA certificate
+func (c *ChiConnection) GetCASecret() string {
+	if c == nil {
+		return ""
+	}
+	return c.CASecret
+}
+
+// GetPublish gets kind of object connection details are published into. Empty means not published
+func (c *ChiConnection) GetPublish() string {
+	if c == nil {
+		return ""
+	}
+	switch c.Publish {
+	case ConnectionPublishConfigMap, ConnectionPublishSecret:
+		return c.Publish
+	}
+	return ""
+}
+
+// GetName gets name of the published object
+func (c *ChiConnection) GetName() string {
+	if c == nil {
+		return ""
+	}
+	return c.Name
+}
+
+// MergeFrom merges from specified connection
+func (c *ChiConnection) MergeFrom(from *ChiConnection, _type MergeType) *ChiConnection {
+	if from == nil {
+		return c
+	}
+
+	if c == nil {
+		c = new(ChiConnection)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if c.CASecret == "" {
+			c.CASecret = from.CASecret
+		}
+		if c.Publish == "" {
+			c.Publish = from.Publish
+		}
+		if c.Name == "" {
+			c.Name = from.Name
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.CASecret != "" {
+			// Override by non-empty values only
+			c.CASecret = from.CASecret
+		}
+		if from.Publish != "" {
+			// Override by non-empty values only
+			c.Publish = from.Publish
+		}
+		if from.Name != "" {
+			// Override by non-empty values only
+			c.Name = from.Name
+		}
+	}
+
+	return c
+}
+
+// GetData gets connection details as environment variables, applications consume via envFrom
+func (s *ChiConnectionStatus) GetData() map[string]string {
+	if s == nil {
+		return nil
+	}
+	data := map[string]string{
+		"CLICKHOUSE_HOST":   s.Host,
+		"CLICKHOUSE_SECURE": strconv.FormatBool(s.Secure),
+	}
+	if s.HTTPPort > 0 {
+		data["CLICKHOUSE_HTTP_PORT"] = strconv.Itoa(int(s.HTTPPort))
+	}
+	if s.HTTPSPort > 0 {
+		data["CLICKHOUSE_HTTPS_PORT"] = strconv.Itoa(int(s.HTTPSPort))
+	}
+	if s.NativePort > 0 {
+		data["CLICKHOUSE_NATIVE_PORT"] = strconv.Itoa(int(s.NativePort))
+	}
+	if s.NativeSecurePort > 0 {
+		data["CLICKHOUSE_NATIVE_SECURE_PORT"] = strconv.Itoa(int(s.NativeSecurePort))
+	}
+	if s.HTTPURL != "" {
+		data["CLICKHOUSE_URL"] = s.HTTPURL
+	}
+	if s.CASecret != "" {
+		data["CLICKHOUSE_CA_SECRET"] = s.CASecret
+	}
+	return data
+}
+
+// BuildHTTPURL builds URL of the preferable HTTP endpoint, secure one is preferred
+func (s *ChiConnectionStatus) BuildHTTPURL() string {
+	switch {
+	case s == nil:
+		return ""
+	case s.HTTPSPort > 0:
+		return fmt.Sprintf("https://%s:%d", s.Host, s.HTTPSPort)
+	case s.HTTPPort > 0:
+		return fmt.Sprintf("http://%s:%d", s.Host, s.HTTPPort)
+	}
+	return ""
+}
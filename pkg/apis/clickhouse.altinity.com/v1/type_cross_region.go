@@ -0,0 +1,174 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// Roles of the CHI in cross-region replication topology
+const (
+	// CrossRegionRolePrimary specifies CHI accepting writes
+	CrossRegionRolePrimary = "Primary"
+	// CrossRegionRoleStandby specifies CHI replicating from the primary, with writes disabled till the CHI is promoted
+	CrossRegionRoleStandby = "Standby"
+)
+
+// ChiCrossRegion defines cross-region replication topology of the CHI.
+// CHIs in different regions (Kubernetes clusters) are declared as peers of each other, so the operator
+// generates macros for consistent ZooKeeper paths and replica names across regions
+// and cluster definitions spanning replicas of all regions.
+type ChiCrossRegion struct {
+	// Region specifies name of the region the CHI runs in
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// Installation specifies name of the installation shared by all regions, to be used in ZooKeeper paths.
+	// Defaults to CHI name
+	Installation string `json:"installation,omitempty" yaml:"installation,omitempty"`
+	// Peers specifies CHIs in other regions, which are replication targets
+	Peers []ChiCrossRegionPeer `json:"peers,omitempty" yaml:"peers,omitempty"`
+	// Role specifies role of the CHI. Standby CHI is turned into primary by "Promote" operation
+	Role string `json:"role,omitempty" yaml:"role,omitempty"`
+}
+
+// ChiCrossRegionPeer defines CHI in another region
+type ChiCrossRegionPeer struct {
+	// Region specifies name of the region the peer CHI runs in
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// HostPattern specifies pattern of host names of the peer CHI, reachable from this region.
+	// Pattern is expanded with macros of each host of this CHI, thus peer CHI is expected to have the same layout.
+	// Ex.: chi-events-{cluster}-{host}.us-east.example.com
+	HostPattern string `json:"hostPattern,omitempty" yaml:"hostPattern,omitempty"`
+	// Port specifies port of the peer hosts. Defaults to port of the host of this CHI
+	Port int32 `json:"port,omitempty" yaml:"port,omitempty"`
+	// Secure specifies whether peer hosts are connected via secure port
+	Secure *StringBool `json:"secure,omitempty" yaml:"secure,omitempty"`
+	// User specifies user to connect to the peer hosts with, in case cluster has no secret shared by all regions
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+	// Password specifies password of the user, in plaintext
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// NewChiCrossRegion creates new cross-region replication topology
+func NewChiCrossRegion() *ChiCrossRegion {
+	return new(ChiCrossRegion)
+}
+
+// IsEnabled checks whether cross-region replication topology is specified
+func (cr *ChiCrossRegion) IsEnabled() bool {
+	return cr.GetRegion() != ""
+}
+
+// GetRegion gets name of the region
+func (cr *ChiCrossRegion) GetRegion() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.Region
+}
+
+// GetInstallation gets name of the installation shared by all regions
+func (cr *ChiCrossRegion) GetInstallation() string {
+	if cr == nil {
+		return ""
+	}
+	return cr.Installation
+}
+
+// GetRole gets role of the CHI, primary by default
+func (cr *ChiCrossRegion) GetRole() string {
+	if (cr == nil) || (cr.Role == "") {
+		return CrossRegionRolePrimary
+	}
+	return cr.Role
+}
+
+// IsStandby checks whether the CHI is a standby, with writes disabled
+func (cr *ChiCrossRegion) IsStandby() bool {
+	return cr.IsEnabled() && (cr.GetRole() == CrossRegionRoleStandby)
+}
+
+// GetProfileSettings gets settings of the default profile.
+// Standby CHI accepts reads only, replication is not affected, since it does not go through queries
+func (cr *ChiCrossRegion) GetProfileSettings() map[string]string {
+	if !cr.IsStandby() {
+		return nil
+	}
+	return map[string]string{
+		"readonly": "2",
+	}
+}
+
+// GetPeers gets CHIs in other regions
+func (cr *ChiCrossRegion) GetPeers() []ChiCrossRegionPeer {
+	if cr == nil {
+		return nil
+	}
+	return cr.Peers
+}
+
+// HasPeers checks whether CHIs in other regions are specified
+func (cr *ChiCrossRegion) HasPeers() bool {
+	return cr.IsEnabled() && (len(cr.GetPeers()) > 0)
+}
+
+// IsSecure checks whether peer hosts are connected via secure port
+func (peer *ChiCrossRegionPeer) IsSecure() bool {
+	if peer == nil {
+		return false
+	}
+	return peer.Secure.IsTrue()
+}
+
+// MergeFrom merges from specified cross-region replication topology
+func (cr *ChiCrossRegion) MergeFrom(from *ChiCrossRegion, _type MergeType) *ChiCrossRegion {
+	if from == nil {
+		return cr
+	}
+
+	if cr == nil {
+		cr = NewChiCrossRegion()
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if cr.Region == "" {
+			cr.Region = from.Region
+		}
+		if cr.Installation == "" {
+			cr.Installation = from.Installation
+		}
+		if len(cr.Peers) == 0 {
+			cr.Peers = from.Peers
+		}
+		if cr.Role == "" {
+			cr.Role = from.Role
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Region != "" {
+			// Override by non-empty values only
+			cr.Region = from.Region
+		}
+		if from.Installation != "" {
+			// Override by non-empty values only
+			cr.Installation = from.Installation
+		}
+		if len(from.Peers) > 0 {
+			// Override by non-empty values only
+			cr.Peers = from.Peers
+		}
+		if from.Role != "" {
+			// Override by non-empty values only
+			cr.Role = from.Role
+		}
+	}
+
+	return cr
+}
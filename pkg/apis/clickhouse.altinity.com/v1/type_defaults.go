@@ -14,19 +14,150 @@
 
 package v1
 
+import "strings"
+
 // ChiDefaults defines defaults section of .spec
 type ChiDefaults struct {
 	ReplicasUseFQDN   *StringBool        `json:"replicasUseFQDN,omitempty"    yaml:"replicasUseFQDN,omitempty"`
 	DistributedDDL    *ChiDistributedDDL `json:"distributedDDL,omitempty"     yaml:"distributedDDL,omitempty"`
 	StorageManagement *StorageManagement `json:"storageManagement,omitempty"  yaml:"storageManagement,omitempty"`
 	Templates         *ChiTemplateNames  `json:"templates,omitempty"          yaml:"templates,omitempty"`
+	// ReplicasCount specifies replicas count of clusters, which have no replicas count specified explicitly.
+	// Target of the scale subresource
+	ReplicasCount int `json:"replicasCount,omitempty" yaml:"replicasCount,omitempty"`
+	// Scheduling specifies scheduling defaults applied to all pods of the CHI
+	Scheduling *ChiScheduling `json:"scheduling,omitempty" yaml:"scheduling,omitempty"`
+	// System specifies kernel parameters and process limits applied to all pods of the CHI
+	System *ChiSystemTuning `json:"system,omitempty" yaml:"system,omitempty"`
+	// Routing specifies how traffic is routed to hosts of the CHI
+	Routing *ChiRouting `json:"routing,omitempty" yaml:"routing,omitempty"`
+	// HostServices specifies how hosts are reachable: via Service per host or via single headless Service of the CHI
+	HostServices string `json:"hostServices,omitempty" yaml:"hostServices,omitempty"`
+	// HostDiscovery specifies how hosts address each other: by names of their Services or by DNS names of their pods
+	HostDiscovery string `json:"hostDiscovery,omitempty" yaml:"hostDiscovery,omitempty"`
+	// DistributeHostPorts specifies whether ports of hosts exposing ports on the node via hostPort are offset by index of the host,
+	// so hosts sharing a node do not clash over ports
+	DistributeHostPorts *StringBool `json:"distributeHostPorts,omitempty" yaml:"distributeHostPorts,omitempty"`
+	// ReadinessGate specifies whether pods are Ready only after the operator's deep health check of the host passed
+	ReadinessGate *StringBool `json:"readinessGate,omitempty" yaml:"readinessGate,omitempty"`
+	// Readiness specifies how readiness of ClickHouse is probed by the default readiness probe
+	Readiness *ChiReadiness `json:"readiness,omitempty" yaml:"readiness,omitempty"`
+	// MemoryManagement specifies container memory limit, memory ClickHouse is allowed to use and OOM score coherently
+	MemoryManagement *ChiMemoryManagement `json:"memoryManagement,omitempty" yaml:"memoryManagement,omitempty"`
 }
 
+// Possible values of how hosts are reachable
+const (
+	// HostServicesPerHost specifies each host has own Service
+	HostServicesPerHost = "PerHost"
+	// HostServicesHeadless specifies hosts are reachable via per-pod DNS records of single headless Service
+	HostServicesHeadless = "Headless"
+)
+
+// Possible values of how hosts address each other
+const (
+	// HostDiscoveryService specifies hosts are addressed by names of their Services
+	HostDiscoveryService = "Service"
+	// HostDiscoveryPodDNS specifies hosts are addressed by DNS names of their pods within governing Service
+	HostDiscoveryPodDNS = "PodDNS"
+)
+
 // NewChiDefaults creates new ChiDefaults object
 func NewChiDefaults() *ChiDefaults {
 	return new(ChiDefaults)
 }
 
+// GetReplicasCount gets replicas count of clusters, which have no replicas count specified explicitly
+func (defaults *ChiDefaults) GetReplicasCount() int {
+	if defaults == nil {
+		return 0
+	}
+	return defaults.ReplicasCount
+}
+
+// GetScheduling gets scheduling defaults applied to all pods of the CHI
+func (defaults *ChiDefaults) GetScheduling() *ChiScheduling {
+	if defaults == nil {
+		return nil
+	}
+	return defaults.Scheduling
+}
+
+// GetSystem gets kernel parameters and process limits applied to all pods of the CHI
+func (defaults *ChiDefaults) GetSystem() *ChiSystemTuning {
+	if defaults == nil {
+		return nil
+	}
+	return defaults.System
+}
+
+// GetRouting gets how traffic is routed to hosts of the CHI
+func (defaults *ChiDefaults) GetRouting() *ChiRouting {
+	if defaults == nil {
+		return nil
+	}
+	return defaults.Routing
+}
+
+// GetHostServices gets how hosts are reachable
+func (defaults *ChiDefaults) GetHostServices() string {
+	if defaults == nil {
+		return ""
+	}
+	return defaults.HostServices
+}
+
+// IsHeadlessHostServices checks whether hosts are reachable via single headless Service instead of Service per host
+func (defaults *ChiDefaults) IsHeadlessHostServices() bool {
+	return strings.EqualFold(defaults.GetHostServices(), HostServicesHeadless)
+}
+
+// GetHostDiscovery gets how hosts address each other
+func (defaults *ChiDefaults) GetHostDiscovery() string {
+	if defaults == nil {
+		return ""
+	}
+	return defaults.HostDiscovery
+}
+
+// IsPodDNSHostDiscovery checks whether hosts are addressed by DNS names of their pods.
+// Hosts without own Services are always addressed by DNS names of their pods
+func (defaults *ChiDefaults) IsPodDNSHostDiscovery() bool {
+	return strings.EqualFold(defaults.GetHostDiscovery(), HostDiscoveryPodDNS) || defaults.IsHeadlessHostServices()
+}
+
+// IsDistributeHostPorts checks whether ports of hosts exposing ports on the node via hostPort are offset by index of the host
+func (defaults *ChiDefaults) IsDistributeHostPorts() bool {
+	if defaults == nil {
+		return false
+	}
+	return defaults.DistributeHostPorts.Value()
+}
+
+// IsReadinessGateEnabled checks whether pods are Ready only after the operator's deep health check of the host passed
+func (defaults *ChiDefaults) IsReadinessGateEnabled() bool {
+	if defaults == nil {
+		return false
+	}
+	return defaults.ReadinessGate.Value()
+}
+
+// GetReadiness gets how readiness of ClickHouse is probed
+func (defaults *ChiDefaults) GetReadiness() *ChiReadiness {
+	if defaults == nil {
+		return nil
+	}
+	return defaults.Readiness
+}
+
+// GetMemoryManagement gets memory management of ClickHouse
+func (defaults *ChiDefaults) GetMemoryManagement() *ChiMemoryManagement {
+	if defaults == nil {
+		return nil
+	}
+	return defaults.MemoryManagement
+}
+
 // MergeFrom merges from specified object
 func (defaults *ChiDefaults) MergeFrom(from *ChiDefaults, _type MergeType) *ChiDefaults {
 	if from == nil {
@@ -42,16 +173,55 @@ func (defaults *ChiDefaults) MergeFrom(from *ChiDefaults, _type MergeType) *ChiD
 		if !from.ReplicasUseFQDN.HasValue() {
 			defaults.ReplicasUseFQDN = defaults.ReplicasUseFQDN.MergeFrom(from.ReplicasUseFQDN)
 		}
+		if defaults.ReplicasCount == 0 {
+			defaults.ReplicasCount = from.ReplicasCount
+		}
+		if defaults.HostServices == "" {
+			defaults.HostServices = from.HostServices
+		}
+		if defaults.HostDiscovery == "" {
+			defaults.HostDiscovery = from.HostDiscovery
+		}
+		if defaults.DistributeHostPorts == nil {
+			defaults.DistributeHostPorts = from.DistributeHostPorts
+		}
+		if defaults.ReadinessGate == nil {
+			defaults.ReadinessGate = from.ReadinessGate
+		}
 	case MergeTypeOverrideByNonEmptyValues:
 		if from.ReplicasUseFQDN.HasValue() {
 			// Override by non-empty values only
 			defaults.ReplicasUseFQDN = defaults.ReplicasUseFQDN.MergeFrom(from.ReplicasUseFQDN)
 		}
+		if from.ReplicasCount != 0 {
+			defaults.ReplicasCount = from.ReplicasCount
+		}
+		if from.HostServices != "" {
+			// Override by non-empty values only
+			defaults.HostServices = from.HostServices
+		}
+		if from.HostDiscovery != "" {
+			// Override by non-empty values only
+			defaults.HostDiscovery = from.HostDiscovery
+		}
+		if from.DistributeHostPorts != nil {
+			// Override by non-empty values only
+			defaults.DistributeHostPorts = from.DistributeHostPorts
+		}
+		if from.ReadinessGate != nil {
+			// Override by non-empty values only
+			defaults.ReadinessGate = from.ReadinessGate
+		}
 	}
 
 	defaults.DistributedDDL = defaults.DistributedDDL.MergeFrom(from.DistributedDDL, _type)
 	defaults.StorageManagement = defaults.StorageManagement.MergeFrom(from.StorageManagement, _type)
 	defaults.Templates = defaults.Templates.MergeFrom(from.Templates, _type)
+	defaults.Scheduling = defaults.Scheduling.MergeFrom(from.Scheduling, _type)
+	defaults.System = defaults.System.MergeFrom(from.System, _type)
+	defaults.Routing = defaults.Routing.MergeFrom(from.Routing, _type)
+	defaults.Readiness = defaults.Readiness.MergeFrom(from.Readiness, _type)
+	defaults.MemoryManagement = defaults.MemoryManagement.MergeFrom(from.MemoryManagement, _type)
 
 	return defaults
 }
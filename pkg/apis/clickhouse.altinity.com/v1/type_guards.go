@@ -0,0 +1,128 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// ChiGuards defines guards capping load, which queries and user sessions are allowed to put on ClickHouse hosts.
+// Zero value of any guard means the guard is not specified.
+type ChiGuards struct {
+	// MaxConcurrentQueries specifies max number of simultaneously processed queries. Goes into server config
+	MaxConcurrentQueries int `json:"maxConcurrentQueries,omitempty" yaml:"maxConcurrentQueries,omitempty"`
+	// MaxConcurrentInsertQueries specifies max number of simultaneously processed insert queries. Goes into server config
+	MaxConcurrentInsertQueries int `json:"maxConcurrentInsertQueries,omitempty" yaml:"maxConcurrentInsertQueries,omitempty"`
+	// MaxConcurrentSelectQueries specifies max number of simultaneously processed select queries. Goes into server config
+	MaxConcurrentSelectQueries int `json:"maxConcurrentSelectQueries,omitempty" yaml:"maxConcurrentSelectQueries,omitempty"`
+	// MaxConnections specifies max number of inbound connections. Goes into server config
+	MaxConnections int `json:"maxConnections,omitempty" yaml:"maxConnections,omitempty"`
+	// Users specifies per-user guards. Goes into profiles
+	Users map[string]ChiUserGuards `json:"users,omitempty" yaml:"users,omitempty"`
+}
+
+// ChiUserGuards defines per-user guards
+type ChiUserGuards struct {
+	// MaxSessionsForUser specifies max number of simultaneous sessions of the user
+	MaxSessionsForUser int `json:"maxSessionsForUser,omitempty" yaml:"maxSessionsForUser,omitempty"`
+	// MaxConcurrentQueriesForUser specifies max number of simultaneously processed queries of the user
+	MaxConcurrentQueriesForUser int `json:"maxConcurrentQueriesForUser,omitempty" yaml:"maxConcurrentQueriesForUser,omitempty"`
+}
+
+// NewChiGuards creates new guards
+func NewChiGuards() *ChiGuards {
+	return new(ChiGuards)
+}
+
+// GetServerSettings gets server-wide guards as a map of server settings names to values
+func (g *ChiGuards) GetServerSettings() map[string]int {
+	if g == nil {
+		return nil
+	}
+	return map[string]int{
+		"max_concurrent_queries":        g.MaxConcurrentQueries,
+		"max_concurrent_insert_queries": g.MaxConcurrentInsertQueries,
+		"max_concurrent_select_queries": g.MaxConcurrentSelectQueries,
+		"max_connections":               g.MaxConnections,
+	}
+}
+
+// GetUsers gets per-user guards
+func (g *ChiGuards) GetUsers() map[string]ChiUserGuards {
+	if g == nil {
+		return nil
+	}
+	return g.Users
+}
+
+// GetProfileSettings gets user guards as a map of profile settings names to values
+func (g ChiUserGuards) GetProfileSettings() map[string]int {
+	return map[string]int{
+		"max_sessions_for_user":           g.MaxSessionsForUser,
+		"max_concurrent_queries_for_user": g.MaxConcurrentQueriesForUser,
+	}
+}
+
+// MergeFrom merges from specified guards
+func (g *ChiGuards) MergeFrom(from *ChiGuards, _type MergeType) *ChiGuards {
+	if from == nil {
+		return g
+	}
+
+	if g == nil {
+		g = NewChiGuards()
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if g.MaxConcurrentQueries == 0 {
+			g.MaxConcurrentQueries = from.MaxConcurrentQueries
+		}
+		if g.MaxConcurrentInsertQueries == 0 {
+			g.MaxConcurrentInsertQueries = from.MaxConcurrentInsertQueries
+		}
+		if g.MaxConcurrentSelectQueries == 0 {
+			g.MaxConcurrentSelectQueries = from.MaxConcurrentSelectQueries
+		}
+		if g.MaxConnections == 0 {
+			g.MaxConnections = from.MaxConnections
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.MaxConcurrentQueries != 0 {
+			// Override by non-empty values only
+			g.MaxConcurrentQueries = from.MaxConcurrentQueries
+		}
+		if from.MaxConcurrentInsertQueries != 0 {
+			// Override by non-empty values only
+			g.MaxConcurrentInsertQueries = from.MaxConcurrentInsertQueries
+		}
+		if from.MaxConcurrentSelectQueries != 0 {
+			// Override by non-empty values only
+			g.MaxConcurrentSelectQueries = from.MaxConcurrentSelectQueries
+		}
+		if from.MaxConnections != 0 {
+			// Override by non-empty values only
+			g.MaxConnections = from.MaxConnections
+		}
+	}
+
+	// Per-user guards are merged user by user, own guards of the user are preferred
+	for username, guards := range from.Users {
+		if g.Users == nil {
+			g.Users = make(map[string]ChiUserGuards)
+		}
+		if _, ok := g.Users[username]; !ok || (_type == MergeTypeOverrideByNonEmptyValues) {
+			g.Users[username] = guards
+		}
+	}
+
+	return g
+}
@@ -35,6 +35,7 @@ type ChiHost struct {
 	Settings            *Settings         `json:"settings,omitempty"            yaml:"settings,omitempty"`
 	Files               *Settings         `json:"files,omitempty"               yaml:"files,omitempty"`
 	Templates           *ChiTemplateNames `json:"templates,omitempty"           yaml:"templates,omitempty"`
+	Metadata            *ChiScopeMetadata `json:"metadata,omitempty"            yaml:"metadata,omitempty"`
 
 	// Internal data
 	Address             ChiHostAddress              `json:"-" yaml:"-"`
@@ -46,6 +47,8 @@ type ChiHost struct {
 	// DesiredStatefulSet is a desired stateful set - reconcile target
 	DesiredStatefulSet *appsv1.StatefulSet     `json:"-" yaml:"-" testdiff:"ignore"`
 	CHI                *ClickHouseInstallation `json:"-" yaml:"-" testdiff:"ignore"`
+	// Macros are runtime macros of the host, fetched from Kubernetes metadata as specified by ChiHostMacros
+	Macros map[string]string `json:"-" yaml:"-" testdiff:"ignore"`
 }
 
 // GetReconcileAttributes is an ensurer getter
@@ -98,6 +101,17 @@ func (host *ChiHost) InheritTemplatesFrom(shard *ChiShard, replica *ChiReplica,
 	host.Templates.HandleDeprecatedFields()
 }
 
+// InheritMetadataFrom inherits labels and annotations from specified shard and replica
+func (host *ChiHost) InheritMetadataFrom(shard *ChiShard, replica *ChiReplica) {
+	if shard != nil {
+		host.Metadata = host.Metadata.MergeFrom(shard.Metadata)
+	}
+
+	if replica != nil {
+		host.Metadata = host.Metadata.MergeFrom(replica.Metadata)
+	}
+}
+
 func isUnassigned(port int32) bool {
 	return port == PortMayBeAssignedLaterOrLeftUnused
 }
@@ -178,9 +192,18 @@ func (host *ChiHost) GetSettings() *Settings {
 	return host.Settings
 }
 
-// GetZookeeper gets zookeeper
+// GetZookeeper gets zookeeper.
+// Shard-level zookeeper overrides replica-level one, which overrides cluster-level one
 func (host *ChiHost) GetZookeeper() *ChiZookeeperConfig {
+	if shard := host.GetShard(); (shard != nil) && !shard.Zookeeper.IsEmpty() {
+		return shard.Zookeeper
+	}
 	cluster := host.GetCluster()
+	if replica := host.Address.ReplicaIndex; replica < len(cluster.Layout.Replicas) {
+		if zk := cluster.GetReplica(replica).Zookeeper; !zk.IsEmpty() {
+			return zk
+		}
+	}
 	return cluster.Zookeeper
 }
 
@@ -0,0 +1,132 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"strings"
+
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// FaultDomainMacroName specifies name of the macro to hold fault-domain identifier of the host
+const FaultDomainMacroName = "fault_domain"
+
+// ChiHostMacros defines Kubernetes metadata of the host to be surfaced to ClickHouse as macros.
+// Macros are fetched from the node the host's pod is scheduled on and are refreshed when the pod is rescheduled,
+// so queries and storage policies can be zone-aware.
+type ChiHostMacros struct {
+	// NodeName specifies name of the macro to hold name of the node
+	NodeName string `json:"nodeName,omitempty" yaml:"nodeName,omitempty"`
+	// NodeLabels specifies macros to be filled from node labels, as macro name -> label name
+	NodeLabels map[string]string `json:"nodeLabels,omitempty" yaml:"nodeLabels,omitempty"`
+	// NodeAnnotations specifies macros to be filled from node annotations, as macro name -> annotation name
+	NodeAnnotations map[string]string `json:"nodeAnnotations,omitempty" yaml:"nodeAnnotations,omitempty"`
+	// FaultDomainLabels specifies node labels, values of which make up fault-domain identifier of the host,
+	// such as zone and rack. Identifier is surfaced as {fault_domain} macro
+	FaultDomainLabels []string `json:"faultDomainLabels,omitempty" yaml:"faultDomainLabels,omitempty"`
+}
+
+// NewChiHostMacros creates new host macros
+func NewChiHostMacros() *ChiHostMacros {
+	return new(ChiHostMacros)
+}
+
+// IsEnabled checks whether any macros are requested
+func (m *ChiHostMacros) IsEnabled() bool {
+	if m == nil {
+		return false
+	}
+	return (m.NodeName != "") || (len(m.NodeLabels) > 0) || (len(m.NodeAnnotations) > 0) || (len(m.FaultDomainLabels) > 0)
+}
+
+// GetNodeName gets name of the macro to hold name of the node
+func (m *ChiHostMacros) GetNodeName() string {
+	if m == nil {
+		return ""
+	}
+	return m.NodeName
+}
+
+// GetNodeLabels gets macros to be filled from node labels
+func (m *ChiHostMacros) GetNodeLabels() map[string]string {
+	if m == nil {
+		return nil
+	}
+	return m.NodeLabels
+}
+
+// GetNodeAnnotations gets macros to be filled from node annotations
+func (m *ChiHostMacros) GetNodeAnnotations() map[string]string {
+	if m == nil {
+		return nil
+	}
+	return m.NodeAnnotations
+}
+
+// GetFaultDomainLabels gets node labels, values of which make up fault-domain identifier of the host
+func (m *ChiHostMacros) GetFaultDomainLabels() []string {
+	if m == nil {
+		return nil
+	}
+	return m.FaultDomainLabels
+}
+
+// GetFaultDomain makes fault-domain identifier out of node labels. Labels missing on the node are skipped.
+// Empty identifier means the node has none of the labels
+func (m *ChiHostMacros) GetFaultDomain(nodeLabels map[string]string) string {
+	var parts []string
+	for _, label := range m.GetFaultDomainLabels() {
+		if value, ok := nodeLabels[label]; ok && (value != "") {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// MergeFrom merges from specified host macros
+func (m *ChiHostMacros) MergeFrom(from *ChiHostMacros, _type MergeType) *ChiHostMacros {
+	if from == nil {
+		return m
+	}
+
+	if m == nil {
+		m = NewChiHostMacros()
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if m.NodeName == "" {
+			m.NodeName = from.NodeName
+		}
+		m.NodeLabels = util.MergeStringMapsPreserve(m.NodeLabels, from.NodeLabels)
+		m.NodeAnnotations = util.MergeStringMapsPreserve(m.NodeAnnotations, from.NodeAnnotations)
+		if len(m.FaultDomainLabels) == 0 {
+			m.FaultDomainLabels = from.FaultDomainLabels
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.NodeName != "" {
+			// Override by non-empty values only
+			m.NodeName = from.NodeName
+		}
+		m.NodeLabels = util.MergeStringMapsOverwrite(m.NodeLabels, from.NodeLabels)
+		m.NodeAnnotations = util.MergeStringMapsOverwrite(m.NodeAnnotations, from.NodeAnnotations)
+		if len(from.FaultDomainLabels) > 0 {
+			// Override by non-empty values only
+			m.FaultDomainLabels = from.FaultDomainLabels
+		}
+	}
+
+	return m
+}
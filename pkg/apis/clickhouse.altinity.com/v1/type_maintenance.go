@@ -0,0 +1,630 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// Possible disk usage maintenance actions
+const (
+	// MaintenanceActionThrottle specifies to apply throttling settings to the host
+	MaintenanceActionThrottle = "throttle"
+	// MaintenanceActionAlert specifies to alert only, no settings are applied
+	MaintenanceActionAlert = "alert"
+)
+
+// ChiMaintenance defines maintenance policies the operator runs periodically over the CHI
+type ChiMaintenance struct {
+	DiskUsage   *ChiDiskUsageMaintenance   `json:"diskUsage,omitempty"   yaml:"diskUsage,omitempty"`
+	Mutations   *ChiMutationsMaintenance   `json:"mutations,omitempty"   yaml:"mutations,omitempty"`
+	Spot        *ChiSpotMaintenance        `json:"spot,omitempty"        yaml:"spot,omitempty"`
+	Drill       *ChiDrillMaintenance       `json:"drill,omitempty"       yaml:"drill,omitempty"`
+	RemoteWrite *ChiRemoteWriteMaintenance `json:"remoteWrite,omitempty" yaml:"remoteWrite,omitempty"`
+	Capacity    *ChiCapacityMaintenance    `json:"capacity,omitempty"    yaml:"capacity,omitempty"`
+}
+
+// ChiDiskUsageMaintenance defines free-disk based throttling policy.
+// When disk usage on a host crosses the threshold, throttling settings are applied to the host temporarily,
+// till disk usage drops below recovery threshold.
+type ChiDiskUsageMaintenance struct {
+	// Threshold specifies disk usage percent, crossing which the host is throttled
+	Threshold int `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	// RecoveryThreshold specifies disk usage percent, dropping below which the host is not throttled anymore.
+	// Defaults to Threshold
+	RecoveryThreshold int `json:"recoveryThreshold,omitempty" yaml:"recoveryThreshold,omitempty"`
+	// Action specifies what to do with the host crossing the threshold. Defaults to throttle
+	Action string `json:"action,omitempty" yaml:"action,omitempty"`
+	// Settings specifies merge/insert throttling server settings to be applied to the host
+	Settings *Settings `json:"settings,omitempty" yaml:"settings,omitempty"`
+}
+
+// ChiMutationsMaintenance defines watchdog policy for long-running mutations.
+// Mutations running longer than max duration are reported as stuck and optionally killed.
+type ChiMutationsMaintenance struct {
+	// MaxDuration specifies duration in seconds, running longer than which the mutation is considered stuck
+	MaxDuration int `json:"maxDuration,omitempty" yaml:"maxDuration,omitempty"`
+	// Kill specifies whether stuck mutations should be killed
+	Kill *StringBool `json:"kill,omitempty" yaml:"kill,omitempty"`
+}
+
+// ChiSpotMaintenance defines handling of hosts running on spot/preemptible nodes.
+// Hosts, nodes of which receive termination notice, are drained before the node disappears:
+// excluded from services and clusters, while other replicas of the shard sync parts of the host.
+// Hosts are included back as soon as they run on a node without termination notice.
+type ChiSpotMaintenance struct {
+	// Enabled specifies whether termination notices are watched for
+	Enabled *StringBool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// TerminationTaints specifies keys of taints nodes receive on termination notice.
+	// Defaults to taints of well-known termination handlers
+	TerminationTaints []string `json:"terminationTaints,omitempty" yaml:"terminationTaints,omitempty"`
+	// TerminationConditions specifies types of node conditions, being True on termination notice
+	TerminationConditions []string `json:"terminationConditions,omitempty" yaml:"terminationConditions,omitempty"`
+}
+
+// ChiDrillMaintenance defines game-day drills, deliberately restarting one replica per shard on a schedule.
+// Replicas are restarted one at a time via eviction, so PodDisruptionBudget is respected, and every restarted replica
+// has to recover in time, while the rest replicas of the shard keep serving. Results are reported in status.
+type ChiDrillMaintenance struct {
+	// Enabled specifies whether drills are run
+	Enabled *StringBool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	// Interval specifies interval in seconds between drills. Defaults to a week
+	Interval int `json:"interval,omitempty" yaml:"interval,omitempty"`
+	// Timeout specifies duration in seconds, restarted replica has to recover within. Defaults to 10 minutes
+	Timeout int `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// MaxReplicationDelay specifies max replication delay in seconds, recovered replica is allowed to have
+	MaxReplicationDelay int `json:"maxReplicationDelay,omitempty" yaml:"maxReplicationDelay,omitempty"`
+}
+
+// ChiRemoteWriteMaintenance defines push of SLIs computed by the operator via Prometheus remote-write,
+// for environments where in-cluster exporters can not be scraped.
+// SLIs are availability of shards and replication freshness of hosts, computed and pushed on every maintenance run.
+type ChiRemoteWriteMaintenance struct {
+	// URL specifies remote-write endpoint SLIs are pushed to
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// Headers specifies extra HTTP headers of remote-write requests
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// BearerTokenSecretKeyRef specifies key of the secret in CHI namespace, holding bearer token of remote-write requests
+	BearerTokenSecretKeyRef *core.SecretKeySelector `json:"bearerTokenSecretKeyRef,omitempty" yaml:"bearerTokenSecretKeyRef,omitempty"`
+	// Labels specifies extra labels of pushed series
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// Defaults of drills
+const (
+	defaultDrillInterval            = 7 * 24 * 60 * 60
+	defaultDrillTimeout             = 10 * 60
+	defaultDrillMaxReplicationDelay = 60
+)
+
+// DefaultSpotTerminationTaints specifies taints of well-known termination handlers
+var DefaultSpotTerminationTaints = []string{
+	"aws-node-termination-handler/spot-itn",
+	"aws-node-termination-handler/rebalance-recommendation",
+	"cloud.google.com/impending-node-termination",
+	"node.cloudprovider.kubernetes.io/shutdown",
+	"karpenter.sh/disruption",
+	"ToBeDeletedByClusterAutoscaler",
+}
+
+// GetDiskUsage gets disk usage maintenance policy
+func (m *ChiMaintenance) GetDiskUsage() *ChiDiskUsageMaintenance {
+	if m == nil {
+		return nil
+	}
+	return m.DiskUsage
+}
+
+// GetMutations gets mutations maintenance policy
+func (m *ChiMaintenance) GetMutations() *ChiMutationsMaintenance {
+	if m == nil {
+		return nil
+	}
+	return m.Mutations
+}
+
+// GetSpot gets spot nodes maintenance policy
+func (m *ChiMaintenance) GetSpot() *ChiSpotMaintenance {
+	if m == nil {
+		return nil
+	}
+	return m.Spot
+}
+
+// GetDrill gets drills maintenance policy
+func (m *ChiMaintenance) GetDrill() *ChiDrillMaintenance {
+	if m == nil {
+		return nil
+	}
+	return m.Drill
+}
+
+// GetRemoteWrite gets remote-write of SLIs maintenance policy
+func (m *ChiMaintenance) GetRemoteWrite() *ChiRemoteWriteMaintenance {
+	if m == nil {
+		return nil
+	}
+	return m.RemoteWrite
+}
+
+// GetCapacity gets capacity reporting maintenance policy
+func (m *ChiMaintenance) GetCapacity() *ChiCapacityMaintenance {
+	if m == nil {
+		return nil
+	}
+	return m.Capacity
+}
+
+// MergeFrom merges from specified maintenance
+func (m *ChiMaintenance) MergeFrom(from *ChiMaintenance, _type MergeType) *ChiMaintenance {
+	if from == nil {
+		return m
+	}
+
+	if m == nil {
+		m = new(ChiMaintenance)
+	}
+
+	m.DiskUsage = m.DiskUsage.MergeFrom(from.DiskUsage, _type)
+	m.Mutations = m.Mutations.MergeFrom(from.Mutations, _type)
+	m.Spot = m.Spot.MergeFrom(from.Spot, _type)
+	m.Drill = m.Drill.MergeFrom(from.Drill, _type)
+	m.RemoteWrite = m.RemoteWrite.MergeFrom(from.RemoteWrite, _type)
+	m.Capacity = m.Capacity.MergeFrom(from.Capacity, _type)
+
+	return m
+}
+
+// IsEnabled checks whether disk usage maintenance policy is enabled
+func (p *ChiDiskUsageMaintenance) IsEnabled() bool {
+	if p == nil {
+		return false
+	}
+	return p.Threshold > 0
+}
+
+// GetThreshold gets disk usage percent, crossing which the host is throttled
+func (p *ChiDiskUsageMaintenance) GetThreshold() int {
+	if p == nil {
+		return 0
+	}
+	return p.Threshold
+}
+
+// GetRecoveryThreshold gets disk usage percent, dropping below which the host is not throttled anymore
+func (p *ChiDiskUsageMaintenance) GetRecoveryThreshold() int {
+	if p == nil {
+		return 0
+	}
+	if (p.RecoveryThreshold <= 0) || (p.RecoveryThreshold > p.Threshold) {
+		return p.Threshold
+	}
+	return p.RecoveryThreshold
+}
+
+// IsAlert checks whether the policy alerts only instead of throttling
+func (p *ChiDiskUsageMaintenance) IsAlert() bool {
+	if p == nil {
+		return false
+	}
+	return p.Action == MaintenanceActionAlert
+}
+
+// GetSettings gets throttling settings
+func (p *ChiDiskUsageMaintenance) GetSettings() *Settings {
+	if p == nil {
+		return nil
+	}
+	return p.Settings
+}
+
+// MergeFrom merges from specified disk usage maintenance policy
+func (p *ChiDiskUsageMaintenance) MergeFrom(from *ChiDiskUsageMaintenance, _type MergeType) *ChiDiskUsageMaintenance {
+	if from == nil {
+		return p
+	}
+
+	if p == nil {
+		p = new(ChiDiskUsageMaintenance)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if p.Threshold == 0 {
+			p.Threshold = from.Threshold
+		}
+		if p.RecoveryThreshold == 0 {
+			p.RecoveryThreshold = from.RecoveryThreshold
+		}
+		if p.Action == "" {
+			p.Action = from.Action
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Threshold != 0 {
+			// Override by non-empty values only
+			p.Threshold = from.Threshold
+		}
+		if from.RecoveryThreshold != 0 {
+			// Override by non-empty values only
+			p.RecoveryThreshold = from.RecoveryThreshold
+		}
+		if from.Action != "" {
+			// Override by non-empty values only
+			p.Action = from.Action
+		}
+	}
+	p.Settings = p.Settings.MergeFrom(from.Settings)
+
+	return p
+}
+
+// IsEnabled checks whether mutations maintenance policy is enabled
+func (p *ChiMutationsMaintenance) IsEnabled() bool {
+	if p == nil {
+		return false
+	}
+	return p.MaxDuration > 0
+}
+
+// GetMaxDuration gets duration in seconds, running longer than which the mutation is considered stuck
+func (p *ChiMutationsMaintenance) GetMaxDuration() int {
+	if p == nil {
+		return 0
+	}
+	return p.MaxDuration
+}
+
+// IsKill checks whether stuck mutations should be killed
+func (p *ChiMutationsMaintenance) IsKill() bool {
+	if p == nil {
+		return false
+	}
+	return p.Kill.Value()
+}
+
+// MergeFrom merges from specified mutations maintenance policy
+func (p *ChiMutationsMaintenance) MergeFrom(from *ChiMutationsMaintenance, _type MergeType) *ChiMutationsMaintenance {
+	if from == nil {
+		return p
+	}
+
+	if p == nil {
+		p = new(ChiMutationsMaintenance)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if p.MaxDuration == 0 {
+			p.MaxDuration = from.MaxDuration
+		}
+		if p.Kill == nil {
+			p.Kill = from.Kill
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.MaxDuration != 0 {
+			// Override by non-empty values only
+			p.MaxDuration = from.MaxDuration
+		}
+		if from.Kill != nil {
+			// Override by non-empty values only
+			p.Kill = from.Kill
+		}
+	}
+
+	return p
+}
+
+// IsEnabled checks whether spot nodes maintenance policy is enabled
+func (p *ChiSpotMaintenance) IsEnabled() bool {
+	if p == nil {
+		return false
+	}
+	return p.Enabled.Value()
+}
+
+// GetTerminationTaints gets keys of taints nodes receive on termination notice
+func (p *ChiSpotMaintenance) GetTerminationTaints() []string {
+	if p == nil {
+		return nil
+	}
+	if len(p.TerminationTaints) == 0 {
+		return DefaultSpotTerminationTaints
+	}
+	return p.TerminationTaints
+}
+
+// GetTerminationConditions gets types of node conditions, being True on termination notice
+func (p *ChiSpotMaintenance) GetTerminationConditions() []string {
+	if p == nil {
+		return nil
+	}
+	return p.TerminationConditions
+}
+
+// FindTerminationNotice describes termination notice the node has received, if any.
+// Empty string means the node has no termination notice
+func (p *ChiSpotMaintenance) FindTerminationNotice(node *core.Node) string {
+	if (p == nil) || (node == nil) {
+		return ""
+	}
+	for _, key := range p.GetTerminationTaints() {
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == key {
+				return fmt.Sprintf("taint %s", key)
+			}
+		}
+	}
+	for _, _type := range p.GetTerminationConditions() {
+		for _, condition := range node.Status.Conditions {
+			if (string(condition.Type) == _type) && (condition.Status == core.ConditionTrue) {
+				return fmt.Sprintf("condition %s", _type)
+			}
+		}
+	}
+	return ""
+}
+
+// MergeFrom merges from specified spot nodes maintenance policy
+func (p *ChiSpotMaintenance) MergeFrom(from *ChiSpotMaintenance, _type MergeType) *ChiSpotMaintenance {
+	if from == nil {
+		return p
+	}
+
+	if p == nil {
+		p = new(ChiSpotMaintenance)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if p.Enabled == nil {
+			p.Enabled = from.Enabled
+		}
+		if len(p.TerminationTaints) == 0 {
+			p.TerminationTaints = from.TerminationTaints
+		}
+		if len(p.TerminationConditions) == 0 {
+			p.TerminationConditions = from.TerminationConditions
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Enabled != nil {
+			// Override by non-empty values only
+			p.Enabled = from.Enabled
+		}
+		if len(from.TerminationTaints) > 0 {
+			// Override by non-empty values only
+			p.TerminationTaints = from.TerminationTaints
+		}
+		if len(from.TerminationConditions) > 0 {
+			// Override by non-empty values only
+			p.TerminationConditions = from.TerminationConditions
+		}
+	}
+
+	return p
+}
+
+// IsEnabled checks whether drills are run
+func (p *ChiDrillMaintenance) IsEnabled() bool {
+	if p == nil {
+		return false
+	}
+	return p.Enabled.Value()
+}
+
+// GetInterval gets interval between drills
+func (p *ChiDrillMaintenance) GetInterval() time.Duration {
+	if (p == nil) || (p.Interval <= 0) {
+		return defaultDrillInterval * time.Second
+	}
+	return time.Duration(p.Interval) * time.Second
+}
+
+// GetTimeout gets duration, restarted replica has to recover within
+func (p *ChiDrillMaintenance) GetTimeout() time.Duration {
+	if (p == nil) || (p.Timeout <= 0) {
+		return defaultDrillTimeout * time.Second
+	}
+	return time.Duration(p.Timeout) * time.Second
+}
+
+// GetMaxReplicationDelay gets max replication delay in seconds, recovered replica is allowed to have
+func (p *ChiDrillMaintenance) GetMaxReplicationDelay() int {
+	if (p == nil) || (p.MaxReplicationDelay <= 0) {
+		return defaultDrillMaxReplicationDelay
+	}
+	return p.MaxReplicationDelay
+}
+
+// MergeFrom merges from specified drills maintenance policy
+func (p *ChiDrillMaintenance) MergeFrom(from *ChiDrillMaintenance, _type MergeType) *ChiDrillMaintenance {
+	if from == nil {
+		return p
+	}
+
+	if p == nil {
+		p = new(ChiDrillMaintenance)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if !p.Enabled.HasValue() {
+			p.Enabled = p.Enabled.MergeFrom(from.Enabled)
+		}
+		if p.Interval == 0 {
+			p.Interval = from.Interval
+		}
+		if p.Timeout == 0 {
+			p.Timeout = from.Timeout
+		}
+		if p.MaxReplicationDelay == 0 {
+			p.MaxReplicationDelay = from.MaxReplicationDelay
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Enabled.HasValue() {
+			// Override by non-empty values only
+			p.Enabled = p.Enabled.MergeFrom(from.Enabled)
+		}
+		if from.Interval != 0 {
+			// Override by non-empty values only
+			p.Interval = from.Interval
+		}
+		if from.Timeout != 0 {
+			// Override by non-empty values only
+			p.Timeout = from.Timeout
+		}
+		if from.MaxReplicationDelay != 0 {
+			// Override by non-empty values only
+			p.MaxReplicationDelay = from.MaxReplicationDelay
+		}
+	}
+
+	return p
+}
+
+// IsEnabled checks whether SLIs are pushed via remote-write
+func (p *ChiRemoteWriteMaintenance) IsEnabled() bool {
+	if p == nil {
+		return false
+	}
+	return p.URL != ""
+}
+
+// GetHeaders gets extra HTTP headers of remote-write requests
+func (p *ChiRemoteWriteMaintenance) GetHeaders() map[string]string {
+	if p == nil {
+		return nil
+	}
+	return p.Headers
+}
+
+// GetLabels gets extra labels of pushed series
+func (p *ChiRemoteWriteMaintenance) GetLabels() map[string]string {
+	if p == nil {
+		return nil
+	}
+	return p.Labels
+}
+
+// MergeFrom merges from specified remote-write maintenance policy
+func (p *ChiRemoteWriteMaintenance) MergeFrom(from *ChiRemoteWriteMaintenance, _type MergeType) *ChiRemoteWriteMaintenance {
+	if from == nil {
+		return p
+	}
+
+	if p == nil {
+		p = new(ChiRemoteWriteMaintenance)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if p.URL == "" {
+			p.URL = from.URL
+		}
+		if p.BearerTokenSecretKeyRef == nil {
+			p.BearerTokenSecretKeyRef = from.BearerTokenSecretKeyRef
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.URL != "" {
+			// Override by non-empty values only
+			p.URL = from.URL
+		}
+		if from.BearerTokenSecretKeyRef != nil {
+			// Override by non-empty values only
+			p.BearerTokenSecretKeyRef = from.BearerTokenSecretKeyRef
+		}
+	}
+	p.Headers = mergeRemoteWriteMap(p.Headers, from.Headers, _type)
+	p.Labels = mergeRemoteWriteMap(p.Labels, from.Labels, _type)
+
+	return p
+}
+
+// mergeRemoteWriteMap merges map values according to merge type
+func mergeRemoteWriteMap(to, from map[string]string, _type MergeType) map[string]string {
+	for key, value := range from {
+		if to == nil {
+			to = make(map[string]string)
+		}
+		if _, ok := to[key]; ok && (_type == MergeTypeFillEmptyValues) {
+			continue
+		}
+		to[key] = value
+	}
+	return to
+}
+
+// Possible drill statuses
+const (
+	DrillStatusInProgress = "InProgress"
+	DrillStatusPassed     = "Passed"
+	DrillStatusFailed     = "Failed"
+)
+
+// ChiDrillStatus defines status of the current or the last drill
+type ChiDrillStatus struct {
+	// Run specifies sequence number of the drill, replica to be restarted in each shard rotates with it
+	Run int `json:"run,omitempty" yaml:"run,omitempty"`
+	// Status specifies status of the drill
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+	// StartTime specifies when the drill was started
+	StartTime string `json:"startTime,omitempty" yaml:"startTime,omitempty"`
+	// FinishTime specifies when the drill was finished
+	FinishTime string `json:"finishTime,omitempty" yaml:"finishTime,omitempty"`
+	// Host specifies host being restarted now
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+	// HostRestartTime specifies when the host being restarted now was evicted
+	HostRestartTime string `json:"hostRestartTime,omitempty" yaml:"hostRestartTime,omitempty"`
+	// Hosts specifies per-host results of the drill
+	Hosts []string `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+	// Error specifies why the drill failed
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// IsInProgress checks whether the drill is in progress
+func (s *ChiDrillStatus) IsInProgress() bool {
+	if s == nil {
+		return false
+	}
+	return s.Status == DrillStatusInProgress
+}
+
+// GetRun gets sequence number of the drill
+func (s *ChiDrillStatus) GetRun() int {
+	if s == nil {
+		return 0
+	}
+	return s.Run
+}
+
+// GetStartTime gets when the drill was started
+func (s *ChiDrillStatus) GetStartTime() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	t, _ := time.Parse(time.RFC3339, s.StartTime)
+	return t
+}
+
+// GetHostRestartTime gets when the host being restarted now was evicted
+func (s *ChiDrillStatus) GetHostRestartTime() time.Time {
+	if s == nil {
+		return time.Time{}
+	}
+	t, _ := time.Parse(time.RFC3339, s.HostRestartTime)
+	return t
+}
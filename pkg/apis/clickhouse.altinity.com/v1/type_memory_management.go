@@ -0,0 +1,151 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// MemoryManagementRatioDefault specifies share of container memory limit ClickHouse is allowed to use by default
+const MemoryManagementRatioDefault = "0.9"
+
+// ChiMemoryManagement defines memory of ClickHouse coherently across all layers: container memory limit,
+// memory ClickHouse server is allowed to use and OOM score of ClickHouse process.
+// Container memory limit and request are applied unless pod templates specify them
+type ChiMemoryManagement struct {
+	// Limit specifies memory limit of ClickHouse container
+	Limit *resource.Quantity `json:"limit,omitempty"                          yaml:"limit,omitempty"`
+	// Request specifies memory request of ClickHouse container. Defaults to the limit, so memory is not overcommitted on the node
+	Request *resource.Quantity `json:"request,omitempty"                        yaml:"request,omitempty"`
+	// MaxServerMemoryUsageToRAMRatio specifies share of the limit ClickHouse server is allowed to use.
+	// Goes into server settings as max_server_memory_usage_to_ram_ratio and max_server_memory_usage
+	MaxServerMemoryUsageToRAMRatio string `json:"maxServerMemoryUsageToRAMRatio,omitempty" yaml:"maxServerMemoryUsageToRAMRatio,omitempty"`
+	// OOMScoreAdj specifies OOM score adjustment of ClickHouse process. Goes into server settings as oom_score.
+	// Negative values require SYS_RESOURCE capability, which is added to ClickHouse container
+	OOMScoreAdj *int `json:"oomScoreAdj,omitempty"                    yaml:"oomScoreAdj,omitempty"`
+}
+
+// GetLimit gets memory limit of ClickHouse container
+func (m *ChiMemoryManagement) GetLimit() *resource.Quantity {
+	if m == nil {
+		return nil
+	}
+	return m.Limit
+}
+
+// GetRequest gets memory request of ClickHouse container
+func (m *ChiMemoryManagement) GetRequest() *resource.Quantity {
+	if m == nil {
+		return nil
+	}
+	if m.Request == nil {
+		return m.Limit
+	}
+	return m.Request
+}
+
+// GetMaxServerMemoryUsageToRAMRatio gets share of the limit ClickHouse server is allowed to use
+func (m *ChiMemoryManagement) GetMaxServerMemoryUsageToRAMRatio() string {
+	if (m == nil) || (m.MaxServerMemoryUsageToRAMRatio == "") {
+		return MemoryManagementRatioDefault
+	}
+	return m.MaxServerMemoryUsageToRAMRatio
+}
+
+// GetMaxServerMemoryUsage gets memory ClickHouse server is allowed to use, in bytes, as share of the limit.
+// ClickHouse may not respect memory limit of the container and consider RAM of the node instead
+func (m *ChiMemoryManagement) GetMaxServerMemoryUsage() int64 {
+	limit := m.GetLimit()
+	if limit == nil {
+		return 0
+	}
+	ratio, err := strconv.ParseFloat(m.GetMaxServerMemoryUsageToRAMRatio(), 64)
+	if (err != nil) || (ratio <= 0) || (ratio > 1) {
+		return 0
+	}
+	return int64(float64(limit.Value()) * ratio)
+}
+
+// HasNegativeOOMScoreAdj checks whether OOM score of ClickHouse process is lowered
+func (m *ChiMemoryManagement) HasNegativeOOMScoreAdj() bool {
+	if (m == nil) || (m.OOMScoreAdj == nil) {
+		return false
+	}
+	return *m.OOMScoreAdj < 0
+}
+
+// GetServerSettings gets server settings memory management goes into
+func (m *ChiMemoryManagement) GetServerSettings() map[string]string {
+	settings := make(map[string]string)
+	if m == nil {
+		return settings
+	}
+	if usage := m.GetMaxServerMemoryUsage(); usage > 0 {
+		settings["max_server_memory_usage_to_ram_ratio"] = m.GetMaxServerMemoryUsageToRAMRatio()
+		settings["max_server_memory_usage"] = strconv.FormatInt(usage, 10)
+	}
+	if m.OOMScoreAdj != nil {
+		settings["oom_score"] = strconv.Itoa(*m.OOMScoreAdj)
+	}
+	return settings
+}
+
+// MergeFrom merges from specified memory management
+func (m *ChiMemoryManagement) MergeFrom(from *ChiMemoryManagement, _type MergeType) *ChiMemoryManagement {
+	if from == nil {
+		return m
+	}
+
+	if m == nil {
+		return from.DeepCopy()
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if m.Limit == nil {
+			m.Limit = from.Limit
+		}
+		if m.Request == nil {
+			m.Request = from.Request
+		}
+		if m.MaxServerMemoryUsageToRAMRatio == "" {
+			m.MaxServerMemoryUsageToRAMRatio = from.MaxServerMemoryUsageToRAMRatio
+		}
+		if m.OOMScoreAdj == nil {
+			m.OOMScoreAdj = from.OOMScoreAdj
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Limit != nil {
+			// Override by non-empty values only
+			m.Limit = from.Limit
+		}
+		if from.Request != nil {
+			// Override by non-empty values only
+			m.Request = from.Request
+		}
+		if from.MaxServerMemoryUsageToRAMRatio != "" {
+			// Override by non-empty values only
+			m.MaxServerMemoryUsageToRAMRatio = from.MaxServerMemoryUsageToRAMRatio
+		}
+		if from.OOMScoreAdj != nil {
+			// Override by non-empty values only
+			m.OOMScoreAdj = from.OOMScoreAdj
+		}
+	}
+
+	return m
+}
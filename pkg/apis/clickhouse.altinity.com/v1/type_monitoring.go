@@ -0,0 +1,75 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// MonitoringDatabaseDefault specifies database monitoring schema is created in by default
+const MonitoringDatabaseDefault = "monitoring"
+
+// ChiMonitoring defines monitoring schema, a set of views over system tables shipped dashboards are built on.
+// Views are created on each host of each cluster and are upgraded by the operator along with host reconcile
+type ChiMonitoring struct {
+	// Schema specifies whether monitoring schema is created
+	Schema *StringBool `json:"schema,omitempty"   yaml:"schema,omitempty"`
+	// Database specifies database monitoring schema is created in, "monitoring" by default
+	Database string `json:"database,omitempty" yaml:"database,omitempty"`
+}
+
+// IsSchemaEnabled checks whether monitoring schema is created
+func (m *ChiMonitoring) IsSchemaEnabled() bool {
+	if m == nil {
+		return false
+	}
+	return m.Schema.Value()
+}
+
+// GetDatabase gets database monitoring schema is created in
+func (m *ChiMonitoring) GetDatabase() string {
+	if (m == nil) || (m.Database == "") {
+		return MonitoringDatabaseDefault
+	}
+	return m.Database
+}
+
+// MergeFrom merges from specified monitoring
+func (m *ChiMonitoring) MergeFrom(from *ChiMonitoring, _type MergeType) *ChiMonitoring {
+	if from == nil {
+		return m
+	}
+
+	if m == nil {
+		m = new(ChiMonitoring)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if !m.Schema.HasValue() {
+			m.Schema = m.Schema.MergeFrom(from.Schema)
+		}
+		if m.Database == "" {
+			m.Database = from.Database
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Schema.HasValue() {
+			// Override by non-empty values only
+			m.Schema = m.Schema.MergeFrom(from.Schema)
+		}
+		if from.Database != "" {
+			// Override by non-empty values only
+			m.Database = from.Database
+		}
+	}
+
+	return m
+}
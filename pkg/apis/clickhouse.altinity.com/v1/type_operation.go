@@ -0,0 +1,298 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	core "k8s.io/api/core/v1"
+)
+
+// Possible types of maintenance operations
+const (
+	// OperationTypeDetachPartition detaches partition of the table
+	OperationTypeDetachPartition = "DetachPartition"
+	// OperationTypeAttachPartition attaches partition of the table from `detached` directory, from another table or from backup
+	OperationTypeAttachPartition = "AttachPartition"
+	// OperationTypeMovePartition moves partition of the table to another disk or volume
+	OperationTypeMovePartition = "MovePartition"
+	// OperationTypePromote promotes standby CHI of cross-region replication topology into primary
+	OperationTypePromote = "Promote"
+	// OperationTypeMigrateNodes migrates replicas off the nodes matching node selector or taint, one replica at a time
+	OperationTypeMigrateNodes = "MigrateNodes"
+	// OperationTypeRestartHost restarts single host safely: host is drained, restarted and included back once healthy
+	OperationTypeRestartHost = "RestartHost"
+)
+
+const (
+	// defaultPromoteMaxReplicationDelay specifies max replication delay (in seconds) of standby hosts, promotion is allowed with
+	defaultPromoteMaxReplicationDelay = 60
+	// defaultMigrateNodesHostTimeout specifies how long migrated or restarted replica is waited for to recover
+	defaultMigrateNodesHostTimeout = 30 * time.Minute
+)
+
+// Possible statuses of maintenance operations
+const (
+	OperationStatusInProgress = "InProgress"
+	OperationStatusCompleted  = "Completed"
+	OperationStatusFailed     = "Failed"
+)
+
+// OperationSpec defines spec section of ClickHouseOperation resource
+type OperationSpec struct {
+	// CHI specifies name of the ClickHouseInstallation in the namespace of the operation
+	CHI string `json:"chi,omitempty" yaml:"chi,omitempty"`
+	// Cluster specifies name of the cluster to run operation on. All clusters by default
+	Cluster string `json:"cluster,omitempty" yaml:"cluster,omitempty"`
+	// Type specifies type of the operation
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Database specifies database of the table
+	Database string `json:"database,omitempty" yaml:"database,omitempty"`
+	// Table specifies table to run operation on
+	Table string `json:"table,omitempty" yaml:"table,omitempty"`
+	// Partition specifies partition expression, as it is written in SQL. Ex.: '2024-01-01' or 202401
+	Partition string `json:"partition,omitempty" yaml:"partition,omitempty"`
+	// FromTable specifies table to attach partition from, instead of `detached` directory
+	FromTable string `json:"fromTable,omitempty" yaml:"fromTable,omitempty"`
+	// FromBackup specifies backup to attach partition from, instead of `detached` directory
+	FromBackup *OperationBackup `json:"fromBackup,omitempty" yaml:"fromBackup,omitempty"`
+	// Disk specifies disk to move partition to
+	Disk string `json:"disk,omitempty" yaml:"disk,omitempty"`
+	// Volume specifies volume to move partition to
+	Volume string `json:"volume,omitempty" yaml:"volume,omitempty"`
+	// MaxReplicationDelay specifies max replication delay (in seconds) of standby hosts, promotion is allowed with.
+	// In case of node migration or host restart specifies max replication delay of replicas, migration or restart proceeds with
+	MaxReplicationDelay int `json:"maxReplicationDelay,omitempty" yaml:"maxReplicationDelay,omitempty"`
+	// NodeSelector specifies labels of the nodes replicas are migrated off
+	NodeSelector map[string]string `json:"nodeSelector,omitempty" yaml:"nodeSelector,omitempty"`
+	// NodeTaint specifies key of the taint of the nodes replicas are migrated off
+	NodeTaint string `json:"nodeTaint,omitempty" yaml:"nodeTaint,omitempty"`
+	// HostTimeout specifies how long (in seconds) migrated or restarted replica is waited for to recover. 1800 by default
+	HostTimeout int `json:"hostTimeout,omitempty" yaml:"hostTimeout,omitempty"`
+	// Host specifies name of the host to be restarted, either name of the host or name of its pod
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+}
+
+// OperationBackup defines backup, made by ClickHouse BACKUP command, to restore partition from.
+// Backup has to be accessible by every host in scope of the operation. Restored parts of replicated tables
+// are deduplicated by ClickHouse, so restore on every replica is safe
+type OperationBackup struct {
+	// Disk specifies name of the disk configured as backup destination in ClickHouse
+	Disk string `json:"disk,omitempty" yaml:"disk,omitempty"`
+	// Path specifies path of the backup on the disk
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+}
+
+// OperationStatus defines status section of ClickHouseOperation resource
+type OperationStatus struct {
+	// Status specifies status of the operation
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+	// Error specifies error of the operation as a whole, if any
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// Hosts specifies per-host results of the operation
+	Hosts []OperationHostStatus `json:"hosts,omitempty" yaml:"hosts,omitempty"`
+}
+
+// OperationHostStatus defines result of the operation on a host
+type OperationHostStatus struct {
+	// Host specifies name of the host
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+	// Status specifies status of the operation on the host
+	Status string `json:"status,omitempty" yaml:"status,omitempty"`
+	// Error specifies error of the operation on the host, if any
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Validate checks whether the operation is specified completely
+func (spec *OperationSpec) Validate() error {
+	if spec.CHI == "" {
+		return fmt.Errorf("chi is not specified")
+	}
+	switch spec.Type {
+	case OperationTypePromote:
+		// Promotion is applied to the CHI as a whole
+		return nil
+	case OperationTypeMigrateNodes:
+		if (len(spec.NodeSelector) == 0) && (spec.NodeTaint == "") {
+			return fmt.Errorf("either node selector or node taint has to be specified to migrate replicas off")
+		}
+		return nil
+	case OperationTypeRestartHost:
+		if spec.Host == "" {
+			return fmt.Errorf("host has to be specified to be restarted")
+		}
+		return nil
+	}
+	if (spec.Database == "") || (spec.Table == "") || (spec.Partition == "") {
+		return fmt.Errorf("database, table and partition have to be specified")
+	}
+	if err := validatePartition(spec.Partition); err != nil {
+		return err
+	}
+	if (spec.FromBackup != nil) && (spec.Type != OperationTypeAttachPartition) {
+		return fmt.Errorf("backup can be specified for %s only", OperationTypeAttachPartition)
+	}
+	switch spec.Type {
+	case OperationTypeDetachPartition:
+	case OperationTypeAttachPartition:
+		if spec.FromBackup == nil {
+			break
+		}
+		if spec.FromTable != "" {
+			return fmt.Errorf("either table or backup can be specified to attach partition from")
+		}
+		if (spec.FromBackup.Disk == "") || (spec.FromBackup.Path == "") {
+			return fmt.Errorf("disk and path of the backup have to be specified")
+		}
+	case OperationTypeMovePartition:
+		if (spec.Disk == "") == (spec.Volume == "") {
+			return fmt.Errorf("either disk or volume has to be specified to move partition to")
+		}
+	default:
+		return fmt.Errorf("unknown operation type: %s", spec.Type)
+	}
+	return nil
+}
+
+// validatePartition checks partition expression consists of literals only, such as 202401, '2024-01-01',
+// tuple(1, 'a') or ID 'all', so it can be used in SQL as is
+func validatePartition(partition string) error {
+	invalid := fmt.Errorf("partition has to be specified as literal, got: %s", partition)
+	for rest := strings.TrimSpace(partition); rest != ""; rest = strings.TrimSpace(rest) {
+		r := rune(rest[0])
+		switch {
+		case strings.ContainsRune("(),", r):
+			rest = rest[1:]
+		case unicode.IsDigit(r):
+			rest = strings.TrimLeftFunc(rest, unicode.IsDigit)
+		case (r == '-') && (len(rest) > 1) && unicode.IsDigit(rune(rest[1])):
+			// Negative number, while "--" would start a comment
+			rest = strings.TrimLeftFunc(rest[1:], unicode.IsDigit)
+		case r == '\'':
+			// String literal, quotes within are escaped with backslash
+			end := 1
+			for ; (end < len(rest)) && (rest[end] != '\''); end++ {
+				if rest[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(rest) {
+				return invalid
+			}
+			rest = rest[end+1:]
+		default:
+			word := rest[:len(rest)-len(strings.TrimLeftFunc(rest, unicode.IsLetter))]
+			switch strings.ToLower(word) {
+			case "tuple", "id", "all":
+				rest = rest[len(word):]
+			default:
+				return invalid
+			}
+		}
+	}
+	return nil
+}
+
+// GetMaxReplicationDelay gets max replication delay (in seconds) of standby hosts, promotion is allowed with
+func (spec *OperationSpec) GetMaxReplicationDelay() int {
+	if spec.MaxReplicationDelay > 0 {
+		return spec.MaxReplicationDelay
+	}
+	return defaultPromoteMaxReplicationDelay
+}
+
+// GetHostTimeout gets how long migrated or restarted replica is waited for to recover
+func (spec *OperationSpec) GetHostTimeout() time.Duration {
+	if spec.HostTimeout > 0 {
+		return time.Duration(spec.HostTimeout) * time.Second
+	}
+	return defaultMigrateNodesHostTimeout
+}
+
+// MatchNode checks whether replicas are to be migrated off the node
+func (spec *OperationSpec) MatchNode(node *core.Node) bool {
+	if node == nil {
+		return false
+	}
+	if len(spec.NodeSelector) > 0 {
+		for key, value := range spec.NodeSelector {
+			if node.Labels[key] != value {
+				return false
+			}
+		}
+		return true
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == spec.NodeTaint {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished checks whether the operation is finished, either successfully or not
+func (op *ClickHouseOperation) IsFinished() bool {
+	if (op == nil) || (op.Status == nil) {
+		return false
+	}
+	switch op.Status.Status {
+	case OperationStatusCompleted, OperationStatusFailed:
+		return true
+	}
+	return false
+}
+
+// EnsureStatus ensures status of the operation
+func (op *ClickHouseOperation) EnsureStatus() *OperationStatus {
+	if op == nil {
+		return nil
+	}
+	if op.Status == nil {
+		op.Status = &OperationStatus{}
+	}
+	return op.Status
+}
+
+// PushHost appends result of the operation on the host
+func (s *OperationStatus) PushHost(host string, err error) {
+	if s == nil {
+		return
+	}
+	result := OperationHostStatus{
+		Host:   host,
+		Status: OperationStatusCompleted,
+	}
+	if err != nil {
+		result.Status = OperationStatusFailed
+		result.Error = err.Error()
+	}
+	s.Hosts = append(s.Hosts, result)
+}
+
+// HasFailedHosts checks whether the operation failed on at least one host
+func (s *OperationStatus) HasFailedHosts() bool {
+	if s == nil {
+		return false
+	}
+	for _, host := range s.Hosts {
+		if host.Status == OperationStatusFailed {
+			return true
+		}
+	}
+	return false
+}
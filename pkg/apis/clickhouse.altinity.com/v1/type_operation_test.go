@@ -0,0 +1,70 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_validatePartition(t *testing.T) {
+	for _, partition := range []string{
+		"202401",
+		"-1",
+		"'2024-01-01'",
+		`'it\'s'`,
+		"tuple(2024, 'a')",
+		"(2024, 'a')",
+		"ID 'all'",
+		"ALL",
+	} {
+		require.NoError(t, validatePartition(partition), partition)
+	}
+
+	for _, partition := range []string{
+		"202401; DROP TABLE events",
+		"202401 --",
+		"'2024",
+		`'it\'`,
+		"toYYYYMM(now())",
+		"202401 FROM other",
+	} {
+		require.Error(t, validatePartition(partition), partition)
+	}
+}
+
+func Test_OperationSpec_Validate_FromBackup(t *testing.T) {
+	spec := &OperationSpec{
+		CHI:        "chi",
+		Type:       OperationTypeAttachPartition,
+		Database:   "default",
+		Table:      "events",
+		Partition:  "202401",
+		FromBackup: &OperationBackup{Disk: "backups", Path: "events.zip"},
+	}
+	require.NoError(t, spec.Validate())
+
+	spec.FromTable = "events_old"
+	require.Error(t, spec.Validate())
+
+	spec.FromTable = ""
+	spec.FromBackup.Path = ""
+	require.Error(t, spec.Validate())
+
+	spec.FromBackup.Path = "events.zip"
+	spec.Type = OperationTypeDetachPartition
+	require.Error(t, spec.Validate())
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"strings"
+
+	core "k8s.io/api/core/v1"
+)
+
+// Possible values of how readiness of ClickHouse is probed
+const (
+	// ReadinessModePing specifies readiness is probed by /ping HTTP endpoint
+	ReadinessModePing = "Ping"
+	// ReadinessModeQuery specifies readiness is probed by an authenticated query run by clickhouse-client,
+	// since /ping responds OK even in case server is effectively unusable
+	ReadinessModeQuery = "Query"
+)
+
+// ChiReadiness defines how readiness of ClickHouse is probed
+type ChiReadiness struct {
+	// Mode specifies how readiness is probed. Ping is used by default
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	// User specifies user the query is run by. Defaults to `default` user
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+	// PasswordSecretKeyRef specifies Secret key the password of the user is kept in
+	PasswordSecretKeyRef *core.SecretKeySelector `json:"passwordSecretKeyRef,omitempty" yaml:"passwordSecretKeyRef,omitempty"`
+	// Databases specifies databases, which have to be attached in order for the host to be ready
+	Databases []string `json:"databases,omitempty" yaml:"databases,omitempty"`
+}
+
+// IsQuery checks whether readiness is probed by a query
+func (r *ChiReadiness) IsQuery() bool {
+	if r == nil {
+		return false
+	}
+	return strings.EqualFold(r.Mode, ReadinessModeQuery)
+}
+
+// GetUser gets user the query is run by
+func (r *ChiReadiness) GetUser() string {
+	if (r == nil) || (r.User == "") {
+		return "default"
+	}
+	return r.User
+}
+
+// GetPasswordSecretKeyRef gets Secret key the password of the user is kept in
+func (r *ChiReadiness) GetPasswordSecretKeyRef() *core.SecretKeySelector {
+	if r == nil {
+		return nil
+	}
+	return r.PasswordSecretKeyRef
+}
+
+// GetDatabases gets databases, which have to be attached in order for the host to be ready
+func (r *ChiReadiness) GetDatabases() []string {
+	if r == nil {
+		return nil
+	}
+	return r.Databases
+}
+
+// MergeFrom merges from specified readiness
+func (r *ChiReadiness) MergeFrom(from *ChiReadiness, _type MergeType) *ChiReadiness {
+	if from == nil {
+		return r
+	}
+
+	if r == nil {
+		r = new(ChiReadiness)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if r.Mode == "" {
+			r.Mode = from.Mode
+		}
+		if r.User == "" {
+			r.User = from.User
+		}
+		if r.PasswordSecretKeyRef == nil {
+			r.PasswordSecretKeyRef = from.PasswordSecretKeyRef
+		}
+		if len(r.Databases) == 0 {
+			r.Databases = from.Databases
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Mode != "" {
+			// Override by non-empty values only
+			r.Mode = from.Mode
+		}
+		if from.User != "" {
+			// Override by non-empty values only
+			r.User = from.User
+		}
+		if from.PasswordSecretKeyRef != nil {
+			// Override by non-empty values only
+			r.PasswordSecretKeyRef = from.PasswordSecretKeyRef
+		}
+		if len(from.Databases) > 0 {
+			// Override by non-empty values only
+			r.Databases = from.Databases
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,168 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"time"
+
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// ChiReconcileCheckpoint defines checkpoint of the reconcile in progress.
+// Checkpoint survives operator restart, so interrupted reconcile is resumed from the last completed host.
+// Staged rollout keeps its window in the checkpoint as well, so paused reconcile is resumed the same way
+type ChiReconcileCheckpoint struct {
+	Generation     int64    `json:"generation,omitempty"     yaml:"generation,omitempty"`
+	HostsCompleted []string `json:"hostsCompleted,omitempty" yaml:"hostsCompleted,omitempty"`
+	UpdatedAt      string   `json:"updatedAt,omitempty"      yaml:"updatedAt,omitempty"`
+	// WindowStartedAt specifies start of the current window of staged rollout
+	WindowStartedAt string `json:"windowStartedAt,omitempty" yaml:"windowStartedAt,omitempty"`
+	// WindowHosts specifies hosts modified within the current window of staged rollout
+	WindowHosts []string `json:"windowHosts,omitempty" yaml:"windowHosts,omitempty"`
+	// ResumeAt specifies time the reconcile paused by staged rollout is to be resumed at
+	ResumeAt string `json:"resumeAt,omitempty" yaml:"resumeAt,omitempty"`
+}
+
+// NewChiReconcileCheckpoint creates new checkpoint of the reconcile of specified CHI generation
+func NewChiReconcileCheckpoint(generation int64, now time.Time) *ChiReconcileCheckpoint {
+	return &ChiReconcileCheckpoint{
+		Generation: generation,
+		UpdatedAt:  now.UTC().Format(time.RFC3339),
+	}
+}
+
+// GetGeneration gets CHI generation reconciled
+func (c *ChiReconcileCheckpoint) GetGeneration() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.Generation
+}
+
+// GetHostsCompleted gets names of hosts reconciled
+func (c *ChiReconcileCheckpoint) GetHostsCompleted() []string {
+	if c == nil {
+		return nil
+	}
+	return c.HostsCompleted
+}
+
+// IsFor checks whether checkpoint is made by the reconcile of specified CHI generation
+func (c *ChiReconcileCheckpoint) IsFor(generation int64) bool {
+	if c == nil {
+		return false
+	}
+	return c.Generation == generation
+}
+
+// HasHostCompleted checks whether specified host is reconciled
+func (c *ChiReconcileCheckpoint) HasHostCompleted(host string) bool {
+	if c == nil {
+		return false
+	}
+	return util.InArray(host, c.HostsCompleted)
+}
+
+// hostCompleted marks host reconcile completed
+func (c *ChiReconcileCheckpoint) hostCompleted(host string, now time.Time) {
+	if c == nil {
+		return
+	}
+	if !util.InArray(host, c.HostsCompleted) {
+		c.HostsCompleted = append(c.HostsCompleted, host)
+	}
+	c.UpdatedAt = now.UTC().Format(time.RFC3339)
+}
+
+// IsPaused checks whether the reconcile is paused by staged rollout
+func (c *ChiReconcileCheckpoint) IsPaused() bool {
+	if c == nil {
+		return false
+	}
+	return c.ResumeAt != ""
+}
+
+// IsResumable checks whether the reconcile paused by staged rollout is due to be resumed
+func (c *ChiReconcileCheckpoint) IsResumable(now time.Time) bool {
+	if !c.IsPaused() {
+		return false
+	}
+	resumeAt, err := time.Parse(time.RFC3339, c.ResumeAt)
+	return (err != nil) || !now.Before(resumeAt)
+}
+
+// GetResumeAt gets time the reconcile paused by staged rollout is to be resumed at
+func (c *ChiReconcileCheckpoint) GetResumeAt() string {
+	if c == nil {
+		return ""
+	}
+	return c.ResumeAt
+}
+
+// getWindowEnd gets end of the current window of staged rollout. Zero time means no window is open
+func (c *ChiReconcileCheckpoint) getWindowEnd(window time.Duration) time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	start, err := time.Parse(time.RFC3339, c.WindowStartedAt)
+	if err != nil {
+		return time.Time{}
+	}
+	return start.Add(window)
+}
+
+// admitHost checks whether the host can be modified within the current window of staged rollout.
+// In case the window is over, a new one is started. Admitted host is counted in the window
+func (c *ChiReconcileCheckpoint) admitHost(host string, batch int, window time.Duration, now time.Time) bool {
+	if c == nil {
+		return true
+	}
+	if util.InArray(host, c.WindowHosts) {
+		return true
+	}
+	if !now.Before(c.getWindowEnd(window)) {
+		c.WindowStartedAt = now.UTC().Format(time.RFC3339)
+		c.WindowHosts = nil
+	}
+	if len(c.WindowHosts) >= batch {
+		return false
+	}
+	c.WindowHosts = append(c.WindowHosts, host)
+	c.UpdatedAt = now.UTC().Format(time.RFC3339)
+	return true
+}
+
+// pause pauses the reconcile till the end of the current window of staged rollout
+func (c *ChiReconcileCheckpoint) pause(window time.Duration, now time.Time) {
+	if c == nil {
+		return
+	}
+	resumeAt := c.getWindowEnd(window)
+	if resumeAt.Before(now) {
+		resumeAt = now
+	}
+	c.ResumeAt = resumeAt.UTC().Format(time.RFC3339)
+	c.UpdatedAt = now.UTC().Format(time.RFC3339)
+}
+
+// carryWindowFrom carries window of staged rollout over from the checkpoint of previous generation,
+// so changes made while rollout is in progress do not reset the window
+func (c *ChiReconcileCheckpoint) carryWindowFrom(from *ChiReconcileCheckpoint) {
+	if (c == nil) || (from == nil) {
+		return
+	}
+	c.WindowStartedAt = from.WindowStartedAt
+	c.WindowHosts = append([]string{}, from.WindowHosts...)
+}
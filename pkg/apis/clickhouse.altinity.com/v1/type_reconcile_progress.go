@@ -0,0 +1,126 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"time"
+
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// ChiReconcileProgress defines progress of the reconcile in progress
+type ChiReconcileProgress struct {
+	StartedAt           string   `json:"startedAt,omitempty"           yaml:"startedAt,omitempty"`
+	HostsTotal          int      `json:"hostsTotal,omitempty"          yaml:"hostsTotal,omitempty"`
+	HostsCompleted      int      `json:"hostsCompleted,omitempty"      yaml:"hostsCompleted,omitempty"`
+	HostsInProgress     []string `json:"hostsInProgress,omitempty"     yaml:"hostsInProgress,omitempty"`
+	EstimatedCompletion string   `json:"estimatedCompletion,omitempty" yaml:"estimatedCompletion,omitempty"`
+	APIWritesThrottled  string   `json:"apiWritesThrottled,omitempty"  yaml:"apiWritesThrottled,omitempty"`
+}
+
+// NewChiReconcileProgress creates new progress of the reconcile started at specified time
+func NewChiReconcileProgress(hostsTotal int, startedAt time.Time) *ChiReconcileProgress {
+	return &ChiReconcileProgress{
+		StartedAt:  startedAt.UTC().Format(time.RFC3339),
+		HostsTotal: hostsTotal,
+	}
+}
+
+// GetStartedAt gets reconcile start time
+func (p *ChiReconcileProgress) GetStartedAt() time.Time {
+	if p == nil {
+		return time.Time{}
+	}
+	startedAt, _ := time.Parse(time.RFC3339, p.StartedAt)
+	return startedAt
+}
+
+// GetHostsTotal gets number of hosts to be reconciled
+func (p *ChiReconcileProgress) GetHostsTotal() int {
+	if p == nil {
+		return 0
+	}
+	return p.HostsTotal
+}
+
+// GetHostsCompleted gets number of hosts reconciled
+func (p *ChiReconcileProgress) GetHostsCompleted() int {
+	if p == nil {
+		return 0
+	}
+	return p.HostsCompleted
+}
+
+// GetEstimatedCompletion gets estimated completion time in RFC3339 format, empty value means no estimation available
+func (p *ChiReconcileProgress) GetEstimatedCompletion() string {
+	if p == nil {
+		return ""
+	}
+	return p.EstimatedCompletion
+}
+
+// GetAPIWritesThrottled gets time API writes waited for the budget during the reconcile, empty value means no waits
+func (p *ChiReconcileProgress) GetAPIWritesThrottled() string {
+	if p == nil {
+		return ""
+	}
+	return p.APIWritesThrottled
+}
+
+// GetEstimatedDuration gets estimated time left till reconcile completion.
+// Estimation is based on average duration of hosts reconciled so far, zero means no estimation available
+func (p *ChiReconcileProgress) GetEstimatedDuration(now time.Time) time.Duration {
+	if (p == nil) || (p.HostsCompleted == 0) || (p.HostsCompleted >= p.HostsTotal) {
+		return 0
+	}
+	perHost := now.Sub(p.GetStartedAt()) / time.Duration(p.HostsCompleted)
+	return perHost * time.Duration(p.HostsTotal-p.HostsCompleted)
+}
+
+// hostStarted marks host reconcile started
+func (p *ChiReconcileProgress) hostStarted(host string) {
+	if p == nil {
+		return
+	}
+	if !util.InArray(host, p.HostsInProgress) {
+		p.HostsInProgress = append(p.HostsInProgress, host)
+	}
+}
+
+// hostCompleted marks host reconcile completed and re-estimates completion time
+func (p *ChiReconcileProgress) hostCompleted(host string, now time.Time) {
+	if p == nil {
+		return
+	}
+	p.HostsInProgress = util.RemoveFromArray(host, p.HostsInProgress)
+	p.HostsCompleted++
+	if estimated := p.GetEstimatedDuration(now); estimated > 0 {
+		p.EstimatedCompletion = now.Add(estimated).UTC().Format(time.RFC3339)
+	} else {
+		p.EstimatedCompletion = ""
+	}
+}
+
+// apiWritesThrottled sets time API writes waited for the budget, waits shorter than a second are not reported
+func (p *ChiReconcileProgress) apiWritesThrottled(throttled time.Duration) {
+	if p == nil {
+		return
+	}
+	if throttled < time.Second {
+		p.APIWritesThrottled = ""
+		return
+	}
+	p.APIWritesThrottled = throttled.Round(time.Second).String()
+}
@@ -0,0 +1,384 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"time"
+
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// ChiReconcilingStatefulSet defines StatefulSet reconcile tunables of a CHI, overriding operator's config
+type ChiReconcilingStatefulSet struct {
+	Update *ChiReconcilingStatefulSetUpdate `json:"update,omitempty" yaml:"update,omitempty"`
+}
+
+// ChiReconcilingStatefulSetUpdate defines StatefulSet update tunables of a CHI
+type ChiReconcilingStatefulSetUpdate struct {
+	// Timeout specifies how many seconds to wait for StatefulSet to become ready
+	Timeout int `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// PollInterval specifies how many seconds to wait between StatefulSet readiness checks
+	PollInterval int `json:"pollInterval,omitempty" yaml:"pollInterval,omitempty"`
+}
+
+// GetUpdate gets StatefulSet update tunables
+func (s *ChiReconcilingStatefulSet) GetUpdate() *ChiReconcilingStatefulSetUpdate {
+	if s == nil {
+		return nil
+	}
+	return s.Update
+}
+
+// GetTimeout gets timeout of StatefulSet to become ready. Zero means not specified
+func (u *ChiReconcilingStatefulSetUpdate) GetTimeout() time.Duration {
+	if u == nil {
+		return 0
+	}
+	return time.Duration(u.Timeout) * time.Second
+}
+
+// GetPollInterval gets interval between StatefulSet readiness checks. Zero means not specified
+func (u *ChiReconcilingStatefulSetUpdate) GetPollInterval() time.Duration {
+	if u == nil {
+		return 0
+	}
+	return time.Duration(u.PollInterval) * time.Second
+}
+
+// MergeFrom merges from specified object
+func (s *ChiReconcilingStatefulSet) MergeFrom(from *ChiReconcilingStatefulSet, _type MergeType) *ChiReconcilingStatefulSet {
+	if from == nil {
+		return s
+	}
+
+	if s == nil {
+		s = new(ChiReconcilingStatefulSet)
+	}
+
+	if from.Update == nil {
+		return s
+	}
+	if s.Update == nil {
+		s.Update = new(ChiReconcilingStatefulSetUpdate)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if s.Update.Timeout == 0 {
+			s.Update.Timeout = from.Update.Timeout
+		}
+		if s.Update.PollInterval == 0 {
+			s.Update.PollInterval = from.Update.PollInterval
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Update.Timeout != 0 {
+			// Override by non-empty values only
+			s.Update.Timeout = from.Update.Timeout
+		}
+		if from.Update.PollInterval != 0 {
+			// Override by non-empty values only
+			s.Update.PollInterval = from.Update.PollInterval
+		}
+	}
+
+	return s
+}
+
+// ChiReconcilingHost defines host reconcile tunables of a CHI, overriding operator's config
+type ChiReconcilingHost struct {
+	Wait *ChiReconcilingHostWait `json:"wait,omitempty" yaml:"wait,omitempty"`
+}
+
+// ChiReconcilingHostWait defines whether to wait for host to be excluded, to complete queries and to be included
+type ChiReconcilingHostWait struct {
+	Exclude *StringBool `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+	Queries *StringBool `json:"queries,omitempty" yaml:"queries,omitempty"`
+	Include *StringBool `json:"include,omitempty" yaml:"include,omitempty"`
+}
+
+// GetWait gets host wait tunables
+func (h *ChiReconcilingHost) GetWait() *ChiReconcilingHostWait {
+	if h == nil {
+		return nil
+	}
+	return h.Wait
+}
+
+// GetExclude gets whether to wait for host to be excluded from the cluster
+func (w *ChiReconcilingHostWait) GetExclude() *StringBool {
+	if w == nil {
+		return nil
+	}
+	return w.Exclude
+}
+
+// GetQueries gets whether to wait for host to complete running queries
+func (w *ChiReconcilingHostWait) GetQueries() *StringBool {
+	if w == nil {
+		return nil
+	}
+	return w.Queries
+}
+
+// GetInclude gets whether to wait for host to be included into the cluster
+func (w *ChiReconcilingHostWait) GetInclude() *StringBool {
+	if w == nil {
+		return nil
+	}
+	return w.Include
+}
+
+// MergeFrom merges from specified object
+func (h *ChiReconcilingHost) MergeFrom(from *ChiReconcilingHost, _type MergeType) *ChiReconcilingHost {
+	if from == nil {
+		return h
+	}
+
+	if h == nil {
+		h = new(ChiReconcilingHost)
+	}
+
+	if from.Wait == nil {
+		return h
+	}
+	if h.Wait == nil {
+		h.Wait = new(ChiReconcilingHostWait)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if !h.Wait.Exclude.HasValue() {
+			h.Wait.Exclude = h.Wait.Exclude.MergeFrom(from.Wait.Exclude)
+		}
+		if !h.Wait.Queries.HasValue() {
+			h.Wait.Queries = h.Wait.Queries.MergeFrom(from.Wait.Queries)
+		}
+		if !h.Wait.Include.HasValue() {
+			h.Wait.Include = h.Wait.Include.MergeFrom(from.Wait.Include)
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Wait.Exclude.HasValue() {
+			// Override by non-empty values only
+			h.Wait.Exclude = h.Wait.Exclude.MergeFrom(from.Wait.Exclude)
+		}
+		if from.Wait.Queries.HasValue() {
+			// Override by non-empty values only
+			h.Wait.Queries = h.Wait.Queries.MergeFrom(from.Wait.Queries)
+		}
+		if from.Wait.Include.HasValue() {
+			// Override by non-empty values only
+			h.Wait.Include = h.Wait.Include.MergeFrom(from.Wait.Include)
+		}
+	}
+
+	return h
+}
+
+// ChiReconcilingRuntime defines concurrency tunables of a CHI reconcile, overriding operator's config
+type ChiReconcilingRuntime struct {
+	ReconcileShardsThreadsNumber         int `json:"reconcileShardsThreadsNumber,omitempty"         yaml:"reconcileShardsThreadsNumber,omitempty"`
+	ReconcileShardsMaxConcurrencyPercent int `json:"reconcileShardsMaxConcurrencyPercent,omitempty" yaml:"reconcileShardsMaxConcurrencyPercent,omitempty"`
+}
+
+// GetReconcileShardsThreadsNumber gets number of threads to reconcile shards concurrently. Zero means not specified
+func (r *ChiReconcilingRuntime) GetReconcileShardsThreadsNumber() int {
+	if r == nil {
+		return 0
+	}
+	return r.ReconcileShardsThreadsNumber
+}
+
+// GetReconcileShardsMaxConcurrencyPercent gets max percent of shards to be reconciled concurrently. Zero means not specified
+func (r *ChiReconcilingRuntime) GetReconcileShardsMaxConcurrencyPercent() int {
+	if r == nil {
+		return 0
+	}
+	return r.ReconcileShardsMaxConcurrencyPercent
+}
+
+// MergeFrom merges from specified object
+func (r *ChiReconcilingRuntime) MergeFrom(from *ChiReconcilingRuntime, _type MergeType) *ChiReconcilingRuntime {
+	if from == nil {
+		return r
+	}
+
+	if r == nil {
+		r = new(ChiReconcilingRuntime)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if r.ReconcileShardsThreadsNumber == 0 {
+			r.ReconcileShardsThreadsNumber = from.ReconcileShardsThreadsNumber
+		}
+		if r.ReconcileShardsMaxConcurrencyPercent == 0 {
+			r.ReconcileShardsMaxConcurrencyPercent = from.ReconcileShardsMaxConcurrencyPercent
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.ReconcileShardsThreadsNumber != 0 {
+			// Override by non-empty values only
+			r.ReconcileShardsThreadsNumber = from.ReconcileShardsThreadsNumber
+		}
+		if from.ReconcileShardsMaxConcurrencyPercent != 0 {
+			// Override by non-empty values only
+			r.ReconcileShardsMaxConcurrencyPercent = from.ReconcileShardsMaxConcurrencyPercent
+		}
+	}
+
+	return r
+}
+
+// ChiReconcilingScope restricts reconcile pass to specified clusters and shards of a CHI.
+// Empty scope means the whole CHI is reconciled
+type ChiReconcilingScope struct {
+	// Clusters specifies names of clusters to be reconciled. Empty list means all clusters
+	Clusters []string `json:"clusters,omitempty" yaml:"clusters,omitempty"`
+	// Shards specifies names of shards to be reconciled within clusters in scope. Empty list means all shards
+	Shards []string `json:"shards,omitempty" yaml:"shards,omitempty"`
+}
+
+// IsEmpty checks whether scope restricts nothing
+func (s *ChiReconcilingScope) IsEmpty() bool {
+	if s == nil {
+		return true
+	}
+	return (len(s.Clusters) == 0) && (len(s.Shards) == 0)
+}
+
+// HasCluster checks whether cluster is in scope
+func (s *ChiReconcilingScope) HasCluster(cluster string) bool {
+	if s.IsEmpty() || (len(s.Clusters) == 0) {
+		return true
+	}
+	return util.InArray(cluster, s.Clusters)
+}
+
+// HasShard checks whether shard of the cluster is in scope
+func (s *ChiReconcilingScope) HasShard(cluster, shard string) bool {
+	if !s.HasCluster(cluster) {
+		return false
+	}
+	if s.IsEmpty() || (len(s.Shards) == 0) {
+		return true
+	}
+	return util.InArray(shard, s.Shards)
+}
+
+// MergeFrom merges from specified object
+func (s *ChiReconcilingScope) MergeFrom(from *ChiReconcilingScope, _type MergeType) *ChiReconcilingScope {
+	if from == nil {
+		return s
+	}
+
+	if s == nil {
+		s = new(ChiReconcilingScope)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if len(s.Clusters) == 0 {
+			s.Clusters = from.Clusters
+		}
+		if len(s.Shards) == 0 {
+			s.Shards = from.Shards
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if len(from.Clusters) > 0 {
+			// Override by non-empty values only
+			s.Clusters = from.Clusters
+		}
+		if len(from.Shards) > 0 {
+			// Override by non-empty values only
+			s.Shards = from.Shards
+		}
+	}
+
+	return s
+}
+
+// defaultRolloutWindow specifies default duration of the window of staged rollout
+const defaultRolloutWindow = time.Hour
+
+// ChiReconcilingRollout defines staged rollout of host changes of a CHI.
+// Hosts being modified - pod template, image, host-level settings and files - are reconciled
+// by batches of specified percent of hosts per time window, so a regression hits limited number of hosts.
+// Configuration shared by all hosts is not staged
+type ChiReconcilingRollout struct {
+	// Percent specifies max percent of hosts to be modified per window. Zero or 100 means no staging
+	Percent int `json:"percent,omitempty" yaml:"percent,omitempty"`
+	// Window specifies duration of the window in seconds. Defaults to 1 hour
+	Window int `json:"window,omitempty" yaml:"window,omitempty"`
+}
+
+// IsEnabled checks whether host changes are staged
+func (r *ChiReconcilingRollout) IsEnabled() bool {
+	if r == nil {
+		return false
+	}
+	return (r.Percent > 0) && (r.Percent < 100)
+}
+
+// GetWindow gets duration of the window
+func (r *ChiReconcilingRollout) GetWindow() time.Duration {
+	if (r == nil) || (r.Window <= 0) {
+		return defaultRolloutWindow
+	}
+	return time.Duration(r.Window) * time.Second
+}
+
+// GetBatchSize gets max number of hosts to be modified per window out of specified number of hosts.
+// At least one host is modified per window
+func (r *ChiReconcilingRollout) GetBatchSize(hostsCount int) int {
+	if !r.IsEnabled() {
+		return hostsCount
+	}
+	batch := (hostsCount*r.Percent + 99) / 100
+	if batch < 1 {
+		return 1
+	}
+	return batch
+}
+
+// MergeFrom merges from specified object
+func (r *ChiReconcilingRollout) MergeFrom(from *ChiReconcilingRollout, _type MergeType) *ChiReconcilingRollout {
+	if from == nil {
+		return r
+	}
+
+	if r == nil {
+		r = new(ChiReconcilingRollout)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if r.Percent == 0 {
+			r.Percent = from.Percent
+		}
+		if r.Window == 0 {
+			r.Window = from.Window
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Percent != 0 {
+			// Override by non-empty values only
+			r.Percent = from.Percent
+		}
+		if from.Window != 0 {
+			// Override by non-empty values only
+			r.Window = from.Window
+		}
+	}
+
+	return r
+}
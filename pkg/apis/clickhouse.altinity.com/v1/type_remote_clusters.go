@@ -0,0 +1,95 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// ChiRemoteCluster defines custom cluster to be written into remote_servers along with clusters generated by the operator.
+// Custom clusters may consist of arbitrary hosts, ex.: hosts of other installations, for federated queries
+type ChiRemoteCluster struct {
+	// Name specifies name of the cluster in remote_servers
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Secret specifies inter-server secret of the cluster, in plaintext
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	// Shards specifies shards of the cluster
+	Shards []ChiRemoteShard `json:"shards,omitempty" yaml:"shards,omitempty"`
+}
+
+// ChiRemoteShard defines shard of the custom cluster
+type ChiRemoteShard struct {
+	// InternalReplication specifies whether shard replicates data by itself. Defaults to true
+	InternalReplication *StringBool `json:"internalReplication,omitempty" yaml:"internalReplication,omitempty"`
+	// Weight specifies weight of the shard for data distribution
+	Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
+	// Replicas specifies replicas of the shard
+	Replicas []ChiRemoteReplica `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+}
+
+// ChiRemoteReplica defines replica of the custom cluster
+type ChiRemoteReplica struct {
+	// Host specifies host name or address of the replica
+	Host string `json:"host,omitempty" yaml:"host,omitempty"`
+	// Port specifies native protocol port of the replica. Defaults to 9000, or 9440 in case of secure connection
+	Port int32 `json:"port,omitempty" yaml:"port,omitempty"`
+	// Secure specifies whether replica is connected via secure port
+	Secure *StringBool `json:"secure,omitempty" yaml:"secure,omitempty"`
+	// User specifies user to connect to the replica with
+	User string `json:"user,omitempty" yaml:"user,omitempty"`
+	// Password specifies password of the user, in plaintext
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// HasWeight checks whether shard has weight specified
+func (shard *ChiRemoteShard) HasWeight() bool {
+	if shard == nil {
+		return false
+	}
+	return shard.Weight != nil
+}
+
+// GetWeight gets weight of the shard
+func (shard *ChiRemoteShard) GetWeight() int {
+	if shard.HasWeight() {
+		return *shard.Weight
+	}
+	return 0
+}
+
+// IsSecure checks whether replica is connected via secure port
+func (replica *ChiRemoteReplica) IsSecure() bool {
+	if replica == nil {
+		return false
+	}
+	return replica.Secure.Value()
+}
+
+// MergeRemoteClustersFrom merges custom clusters by name. Clusters of the same name are either kept or overridden,
+// depending on merge type. Clusters absent in the receiver are appended
+func MergeRemoteClustersFrom(to, from []ChiRemoteCluster, _type MergeType) []ChiRemoteCluster {
+	for i := range from {
+		found := false
+		for j := range to {
+			if to[j].Name != from[i].Name {
+				continue
+			}
+			found = true
+			if _type == MergeTypeOverrideByNonEmptyValues {
+				to[j] = from[i]
+			}
+		}
+		if !found {
+			to = append(to, from[i])
+		}
+	}
+	return to
+}
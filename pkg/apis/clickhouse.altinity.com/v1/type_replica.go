@@ -30,6 +30,11 @@ func (replica *ChiReplica) InheritTemplatesFrom(cluster *Cluster) {
 	replica.Templates.HandleDeprecatedFields()
 }
 
+// InheritMetadataFrom inherits labels and annotations from specified cluster
+func (replica *ChiReplica) InheritMetadataFrom(cluster *Cluster) {
+	replica.Metadata = replica.Metadata.MergeFrom(cluster.Metadata)
+}
+
 // GetServiceTemplate gets service template
 func (replica *ChiReplica) GetServiceTemplate() (*ChiServiceTemplate, bool) {
 	if !replica.Templates.HasReplicaServiceTemplate() {
@@ -0,0 +1,40 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// ChiReplicatedDatabase defines database to be created with Replicated database engine on all hosts of the cluster.
+// Shard and replica of the database are derived from {shard} and {replica} macros, maintained by the operator.
+type ChiReplicatedDatabase struct {
+	// Name specifies name of the database
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// ZookeeperPath specifies path of the database in ZooKeeper. May contain macros
+	ZookeeperPath string `json:"zookeeperPath,omitempty" yaml:"zookeeperPath,omitempty"`
+}
+
+// GetName gets name of the database
+func (db *ChiReplicatedDatabase) GetName() string {
+	if db == nil {
+		return ""
+	}
+	return db.Name
+}
+
+// GetZookeeperPath gets path of the database in ZooKeeper
+func (db *ChiReplicatedDatabase) GetZookeeperPath() string {
+	if db == nil {
+		return ""
+	}
+	return db.ZookeeperPath
+}
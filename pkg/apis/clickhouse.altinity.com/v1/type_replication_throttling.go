@@ -0,0 +1,51 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import "strconv"
+
+// ChiReplicationThrottling defines throughput of replication traffic of hosts of the cluster,
+// so recovery of replicas does not saturate network of serving hosts
+type ChiReplicationThrottling struct {
+	// FetchesBandwidth specifies max bandwidth of fetches of replicated parts, in bytes per second per host
+	FetchesBandwidth int64 `json:"fetchesBandwidth,omitempty" yaml:"fetchesBandwidth,omitempty"`
+	// SendsBandwidth specifies max bandwidth of sends of replicated parts, in bytes per second per host
+	SendsBandwidth int64 `json:"sendsBandwidth,omitempty" yaml:"sendsBandwidth,omitempty"`
+	// MaxFetches specifies max number of replicated parts fetched concurrently per host
+	MaxFetches int `json:"maxFetches,omitempty" yaml:"maxFetches,omitempty"`
+}
+
+// NewChiReplicationThrottling creates new replication throttling
+func NewChiReplicationThrottling() *ChiReplicationThrottling {
+	return new(ChiReplicationThrottling)
+}
+
+// GetServerSettings gets server settings of replication throttling
+func (t *ChiReplicationThrottling) GetServerSettings() map[string]string {
+	if t == nil {
+		return nil
+	}
+	settings := make(map[string]string)
+	if t.FetchesBandwidth > 0 {
+		settings["max_replicated_fetches_network_bandwidth_for_server"] = strconv.FormatInt(t.FetchesBandwidth, 10)
+	}
+	if t.SendsBandwidth > 0 {
+		settings["max_replicated_sends_network_bandwidth_for_server"] = strconv.FormatInt(t.SendsBandwidth, 10)
+	}
+	if t.MaxFetches > 0 {
+		settings["background_fetches_pool_size"] = strconv.Itoa(t.MaxFetches)
+	}
+	return settings
+}
@@ -0,0 +1,123 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import "strings"
+
+// Annotations of services enabling topology-aware routing.
+// Kubernetes 1.27+ recognizes topology mode, older versions recognize topology-aware hints
+const (
+	AnnotationTopologyMode       = "service.kubernetes.io/topology-mode"
+	AnnotationTopologyAwareHints = "service.kubernetes.io/topology-aware-hints"
+)
+
+// Possible values of how hosts are exposed to node-local agents
+const (
+	// NodeLocalService specifies hosts are exposed via Service with Local internal traffic policy,
+	// so clients reach the host co-located on their node only
+	NodeLocalService = "Service"
+	// NodeLocalHostPort specifies native and HTTP ports of hosts are exposed as host ports of their nodes
+	NodeLocalHostPort = "HostPort"
+)
+
+// ChiRouting defines how traffic is routed to hosts of the CHI
+type ChiRouting struct {
+	// TopologyAware specifies whether traffic should stay within the zone it originates from.
+	// Services are annotated for topology-aware routing and Distributed queries prefer local and nearest replicas
+	TopologyAware *StringBool `json:"topologyAware,omitempty" yaml:"topologyAware,omitempty"`
+	// NodeLocal specifies how hosts are exposed to node-local agents, such as log collectors,
+	// which have to query the co-located replica only. Hosts are not exposed in case not specified
+	NodeLocal string `json:"nodeLocal,omitempty" yaml:"nodeLocal,omitempty"`
+}
+
+// IsTopologyAware checks whether traffic should stay within the zone it originates from
+func (r *ChiRouting) IsTopologyAware() bool {
+	if r == nil {
+		return false
+	}
+	return r.TopologyAware.Value()
+}
+
+// IsNodeLocalService checks whether hosts are exposed via Service with Local internal traffic policy
+func (r *ChiRouting) IsNodeLocalService() bool {
+	if r == nil {
+		return false
+	}
+	return strings.EqualFold(r.NodeLocal, NodeLocalService)
+}
+
+// IsNodeLocalHostPort checks whether hosts are exposed via host ports of their nodes
+func (r *ChiRouting) IsNodeLocalHostPort() bool {
+	if r == nil {
+		return false
+	}
+	return strings.EqualFold(r.NodeLocal, NodeLocalHostPort)
+}
+
+// GetServiceAnnotations gets annotations to be set on services of the CHI
+func (r *ChiRouting) GetServiceAnnotations() map[string]string {
+	if !r.IsTopologyAware() {
+		return nil
+	}
+	return map[string]string{
+		AnnotationTopologyMode:       "Auto",
+		AnnotationTopologyAwareHints: "auto",
+	}
+}
+
+// GetProfileSettings gets settings of the default profile.
+// Replicas are named alike within the replica index, which is commonly spread over zones one-to-one,
+// so nearest hostname load balancing keeps Distributed queries within the zone
+func (r *ChiRouting) GetProfileSettings() map[string]string {
+	if !r.IsTopologyAware() {
+		return nil
+	}
+	return map[string]string{
+		"prefer_localhost_replica": "1",
+		"load_balancing":           "nearest_hostname",
+	}
+}
+
+// MergeFrom merges from specified routing
+func (r *ChiRouting) MergeFrom(from *ChiRouting, _type MergeType) *ChiRouting {
+	if from == nil {
+		return r
+	}
+
+	if r == nil {
+		r = new(ChiRouting)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if !r.TopologyAware.HasValue() {
+			r.TopologyAware = r.TopologyAware.MergeFrom(from.TopologyAware)
+		}
+		if r.NodeLocal == "" {
+			r.NodeLocal = from.NodeLocal
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.TopologyAware.HasValue() {
+			// Override by non-empty values only
+			r.TopologyAware = r.TopologyAware.MergeFrom(from.TopologyAware)
+		}
+		if from.NodeLocal != "" {
+			// Override by non-empty values only
+			r.NodeLocal = from.NodeLocal
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,126 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ChiScheduling defines scheduling defaults applied to all pods of the CHI.
+// Values specified explicitly by pod templates take precedence.
+type ChiScheduling struct {
+	// RuntimeClassName specifies RuntimeClass pods are run with, ex.: GPU-enabled runtime
+	RuntimeClassName string `json:"runtimeClassName,omitempty"  yaml:"runtimeClassName,omitempty"`
+	// ExtendedResources specifies extended resources, such as GPUs, requested and limited for ClickHouse container
+	ExtendedResources core.ResourceList `json:"extendedResources,omitempty" yaml:"extendedResources,omitempty"`
+	// DedicatedNodes specifies dedicated node pool pods are scheduled onto
+	DedicatedNodes *ChiDedicatedNodes `json:"dedicatedNodes,omitempty"    yaml:"dedicatedNodes,omitempty"`
+}
+
+// ChiDedicatedNodes defines dedicated node pool, nodes of which are labeled and tainted by the same key and value.
+// Pods tolerate the taint and are required to run on nodes having the label
+type ChiDedicatedNodes struct {
+	Key   string `json:"key,omitempty"    yaml:"key,omitempty"`
+	Value string `json:"value,omitempty"  yaml:"value,omitempty"`
+	// Effect specifies effect of the taint. Defaults to NoSchedule
+	Effect core.TaintEffect `json:"effect,omitempty" yaml:"effect,omitempty"`
+}
+
+// GetRuntimeClassName gets RuntimeClass pods are run with
+func (s *ChiScheduling) GetRuntimeClassName() string {
+	if s == nil {
+		return ""
+	}
+	return s.RuntimeClassName
+}
+
+// GetExtendedResources gets extended resources requested for ClickHouse container
+func (s *ChiScheduling) GetExtendedResources() core.ResourceList {
+	if s == nil {
+		return nil
+	}
+	return s.ExtendedResources
+}
+
+// GetDedicatedNodes gets dedicated node pool
+func (s *ChiScheduling) GetDedicatedNodes() *ChiDedicatedNodes {
+	if s == nil {
+		return nil
+	}
+	return s.DedicatedNodes
+}
+
+// MergeFrom merges from specified scheduling
+func (s *ChiScheduling) MergeFrom(from *ChiScheduling, _type MergeType) *ChiScheduling {
+	if from == nil {
+		return s
+	}
+
+	if s == nil {
+		s = new(ChiScheduling)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if s.RuntimeClassName == "" {
+			s.RuntimeClassName = from.RuntimeClassName
+		}
+		if s.DedicatedNodes == nil {
+			s.DedicatedNodes = from.DedicatedNodes.DeepCopy()
+		}
+		for name, quantity := range from.ExtendedResources {
+			if _, ok := s.ExtendedResources[name]; !ok {
+				s.setExtendedResource(name, quantity)
+			}
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.RuntimeClassName != "" {
+			s.RuntimeClassName = from.RuntimeClassName
+		}
+		if from.DedicatedNodes != nil {
+			s.DedicatedNodes = from.DedicatedNodes.DeepCopy()
+		}
+		for name, quantity := range from.ExtendedResources {
+			s.setExtendedResource(name, quantity)
+		}
+	}
+
+	return s
+}
+
+// setExtendedResource sets quantity of the extended resource
+func (s *ChiScheduling) setExtendedResource(name core.ResourceName, quantity resource.Quantity) {
+	if s.ExtendedResources == nil {
+		s.ExtendedResources = make(core.ResourceList)
+	}
+	s.ExtendedResources[name] = quantity.DeepCopy()
+}
+
+// IsValid checks whether dedicated node pool is specified
+func (n *ChiDedicatedNodes) IsValid() bool {
+	if n == nil {
+		return false
+	}
+	return n.Key != ""
+}
+
+// GetEffect gets effect of the taint
+func (n *ChiDedicatedNodes) GetEffect() core.TaintEffect {
+	if n.Effect == "" {
+		return core.TaintEffectNoSchedule
+	}
+	return n.Effect
+}
@@ -0,0 +1,91 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import "strings"
+
+// ChiScopeMetadata defines labels and annotations specified at cluster, shard, replica or host level.
+// Metadata is inherited down to the hosts and lands on the objects of the corresponding scope, such as pods and services
+type ChiScopeMetadata struct {
+	Labels      map[string]string `json:"labels,omitempty"      yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// GetLabels is a getter
+func (m *ChiScopeMetadata) GetLabels() map[string]string {
+	if m == nil {
+		return nil
+	}
+	return m.Labels
+}
+
+// GetAnnotations is a getter
+func (m *ChiScopeMetadata) GetAnnotations() map[string]string {
+	if m == nil {
+		return nil
+	}
+	return m.Annotations
+}
+
+// IsEmpty checks whether metadata has neither labels nor annotations
+func (m *ChiScopeMetadata) IsEmpty() bool {
+	return (len(m.GetLabels()) == 0) && (len(m.GetAnnotations()) == 0)
+}
+
+// MergeFrom merges from specified metadata. Values already specified are kept
+func (m *ChiScopeMetadata) MergeFrom(from *ChiScopeMetadata) *ChiScopeMetadata {
+	if from.IsEmpty() {
+		return m
+	}
+	if m == nil {
+		m = new(ChiScopeMetadata)
+	}
+	m.Labels = mergeMetadataValues(m.Labels, from.Labels)
+	m.Annotations = mergeMetadataValues(m.Annotations, from.Annotations)
+	return m
+}
+
+// DropPrefixed drops labels and annotations with keys having specified prefix
+func (m *ChiScopeMetadata) DropPrefixed(prefix string) (dropped []string) {
+	if m == nil {
+		return nil
+	}
+	for key := range m.Labels {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.Labels, key)
+			dropped = append(dropped, key)
+		}
+	}
+	for key := range m.Annotations {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.Annotations, key)
+			dropped = append(dropped, key)
+		}
+	}
+	return dropped
+}
+
+// mergeMetadataValues merges values from src into dst, keeping values already existing in dst
+func mergeMetadataValues(dst, src map[string]string) map[string]string {
+	for key, value := range src {
+		if dst == nil {
+			dst = make(map[string]string)
+		}
+		if _, ok := dst[key]; !ok {
+			dst[key] = value
+		}
+	}
+	return dst
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// Well-known entries of Service Binding (servicebinding.io) Secrets
+const (
+	ServiceBindingType     = "clickhouse"
+	ServiceBindingProvider = "altinity"
+)
+
+// ChiServiceBinding defines Service Binding (servicebinding.io) Secrets provisioned for users of the CHI,
+// so workloads are bound to ClickHouse by the Service Binding runtime without custom glue.
+// Passwords of the users are generated by the operator and kept in their binding Secrets.
+type ChiServiceBinding struct {
+	// Users specifies users binding Secrets are provisioned for.
+	// Secret of the first user is published in status.binding as the CHI is a Provisioned Service
+	Users []string `json:"users,omitempty" yaml:"users,omitempty"`
+}
+
+// ChiBindingStatus defines Provisioned Service status, referencing binding Secret of the CHI
+type ChiBindingStatus struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// GetUsers gets users binding Secrets are provisioned for
+func (b *ChiServiceBinding) GetUsers() []string {
+	if b == nil {
+		return nil
+	}
+	return b.Users
+}
+
+// HasUser checks whether binding Secret is provisioned for the user
+func (b *ChiServiceBinding) HasUser(username string) bool {
+	for _, user := range b.GetUsers() {
+		if user == username {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeFrom merges from specified service binding
+func (b *ChiServiceBinding) MergeFrom(from *ChiServiceBinding, _type MergeType) *ChiServiceBinding {
+	if from == nil {
+		return b
+	}
+
+	if b == nil {
+		b = new(ChiServiceBinding)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if len(b.Users) == 0 {
+			b.Users = from.Users
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if len(from.Users) > 0 {
+			// Override by non-empty values only
+			b.Users = from.Users
+		}
+	}
+
+	return b
+}
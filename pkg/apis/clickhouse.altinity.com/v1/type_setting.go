@@ -111,15 +111,22 @@ func (s *Setting) HasAttributes() bool {
 	return len(s.attributes) > 0
 }
 
-// Attributes returns string form of attributes - used to config tag creation
-func (s *Setting) Attributes() string {
+// WalkAttributes walks over attributes of the setting in order of names
+func (s *Setting) WalkAttributes(f func(name, value string)) {
 	if s == nil {
-		return ""
+		return
 	}
+	for _, name := range util.MapSortedKeys(s.attributes) {
+		f(name, s.attributes[name])
+	}
+}
+
+// Attributes returns string form of attributes
+func (s *Setting) Attributes() string {
 	a := ""
-	for name, value := range s.attributes {
+	s.WalkAttributes(func(name, value string) {
 		a += fmt.Sprintf(` %s="%s"`, name, value)
-	}
+	})
 	return a
 }
 
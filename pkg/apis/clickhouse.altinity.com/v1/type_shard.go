@@ -30,6 +30,11 @@ func (shard *ChiShard) InheritTemplatesFrom(cluster *Cluster) {
 	shard.Templates.HandleDeprecatedFields()
 }
 
+// InheritMetadataFrom inherits labels and annotations from specified cluster
+func (shard *ChiShard) InheritMetadataFrom(cluster *Cluster) {
+	shard.Metadata = shard.Metadata.MergeFrom(cluster.Metadata)
+}
+
 // GetServiceTemplate gets service template
 func (shard *ChiShard) GetServiceTemplate() (*ChiServiceTemplate, bool) {
 	if !shard.Templates.HasShardServiceTemplate() {
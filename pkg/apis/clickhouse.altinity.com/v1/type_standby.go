@@ -0,0 +1,84 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+const (
+	// defaultStandbyNodeLostTimeout specifies how long node of a host has to be not ready to be considered lost
+	defaultStandbyNodeLostTimeout = 5 * time.Minute
+)
+
+// ChiStandby defines pool of warm standby hosts of the cluster.
+// Standby hosts run with schema pre-created, but are not members of any shard and hold no data.
+// As soon as node of a host is lost, one of standby hosts is promoted into the host:
+// it is restarted with macros of the host, re-creates replicated tables and fetches data from other replicas
+type ChiStandby struct {
+	// Replicas specifies number of standby hosts of the cluster
+	Replicas int `json:"replicas,omitempty"        yaml:"replicas,omitempty"`
+	// NodeLostTimeout specifies how long, in seconds, node of a host has to be not ready, before the host is replaced by a standby host
+	NodeLostTimeout int `json:"nodeLostTimeout,omitempty" yaml:"nodeLostTimeout,omitempty"`
+}
+
+// GetReplicas gets number of standby hosts of the cluster
+func (s *ChiStandby) GetReplicas() int {
+	if s == nil {
+		return 0
+	}
+	return s.Replicas
+}
+
+// GetNodeLostTimeout gets how long node of a host has to be not ready to be considered lost
+func (s *ChiStandby) GetNodeLostTimeout() time.Duration {
+	if (s == nil) || (s.NodeLostTimeout <= 0) {
+		return defaultStandbyNodeLostTimeout
+	}
+	return time.Duration(s.NodeLostTimeout) * time.Second
+}
+
+// IsNodeLost checks whether the node is not ready for longer than node lost timeout
+func (s *ChiStandby) IsNodeLost(node *core.Node) bool {
+	if node == nil {
+		return false
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type != core.NodeReady {
+			continue
+		}
+		return (condition.Status != core.ConditionTrue) && (time.Since(condition.LastTransitionTime.Time) > s.GetNodeLostTimeout())
+	}
+	return false
+}
+
+// ChiStandbyPromotion defines standby host promoted into a host of the cluster.
+// Promoted standby host serves the host from then on, keeping its StatefulSet and volumes
+type ChiStandbyPromotion struct {
+	// Cluster specifies name of the cluster
+	Cluster string `json:"cluster"              yaml:"cluster"`
+	// Host specifies name of the host, node of which was lost
+	Host string `json:"host"                 yaml:"host"`
+	// Standby specifies index of the standby host promoted into the host
+	Standby int `json:"standby"              yaml:"standby"`
+	// Node specifies name of the lost node
+	Node string `json:"node,omitempty"       yaml:"node,omitempty"`
+	// PromotedAt specifies time of the promotion
+	PromotedAt string `json:"promotedAt,omitempty" yaml:"promotedAt,omitempty"`
+	// RetainedPVCs specifies PVCs of the lost host, which are kept by reclaim policy and have to be deleted manually
+	RetainedPVCs []string `json:"retainedPVCs,omitempty" yaml:"retainedPVCs,omitempty"`
+}
@@ -17,6 +17,7 @@ package v1
 import (
 	"sort"
 	"sync"
+	"time"
 
 	"github.com/altinity/clickhouse-operator/pkg/util"
 	"github.com/altinity/clickhouse-operator/pkg/version"
@@ -34,6 +35,9 @@ const (
 	StatusCompleted   = "Completed"
 	StatusAborted     = "Aborted"
 	StatusTerminating = "Terminating"
+	// StatusInterrupted means the reconcile is handed off by the operator shutting down.
+	// Checkpoint of the reconcile is kept, so the reconcile is resumed by the next operator instance
+	StatusInterrupted = "Interrupted"
 )
 
 // ChiStatus defines status section of ClickHouseInstallation resource.
@@ -43,49 +47,75 @@ const (
 // that application logic sticks to the synchronized getter/setters by auditing whether all explicit Go field-level
 // accesses are strictly within _this_ source file OR the generated deep copy source file.
 type ChiStatus struct {
-	CHOpVersion            string                  `json:"chop-version,omitempty"           yaml:"chop-version,omitempty"`
-	CHOpCommit             string                  `json:"chop-commit,omitempty"            yaml:"chop-commit,omitempty"`
-	CHOpDate               string                  `json:"chop-date,omitempty"              yaml:"chop-date,omitempty"`
-	CHOpIP                 string                  `json:"chop-ip,omitempty"                yaml:"chop-ip,omitempty"`
-	ClustersCount          int                     `json:"clusters,omitempty"               yaml:"clusters,omitempty"`
-	ShardsCount            int                     `json:"shards,omitempty"                 yaml:"shards,omitempty"`
-	ReplicasCount          int                     `json:"replicas,omitempty"               yaml:"replicas,omitempty"`
-	HostsCount             int                     `json:"hosts,omitempty"                  yaml:"hosts,omitempty"`
-	Status                 string                  `json:"status,omitempty"                 yaml:"status,omitempty"`
-	TaskID                 string                  `json:"taskID,omitempty"                 yaml:"taskID,omitempty"`
-	TaskIDsStarted         []string                `json:"taskIDsStarted,omitempty"         yaml:"taskIDsStarted,omitempty"`
-	TaskIDsCompleted       []string                `json:"taskIDsCompleted,omitempty"       yaml:"taskIDsCompleted,omitempty"`
-	Action                 string                  `json:"action,omitempty"                 yaml:"action,omitempty"`
-	Actions                []string                `json:"actions,omitempty"                yaml:"actions,omitempty"`
-	Error                  string                  `json:"error,omitempty"                  yaml:"error,omitempty"`
-	Errors                 []string                `json:"errors,omitempty"                 yaml:"errors,omitempty"`
-	HostsUpdatedCount      int                     `json:"hostsUpdated,omitempty"           yaml:"hostsUpdated,omitempty"`
-	HostsAddedCount        int                     `json:"hostsAdded,omitempty"             yaml:"hostsAdded,omitempty"`
-	HostsUnchangedCount    int                     `json:"hostsUnchanged,omitempty"         yaml:"hostsUnchanged,omitempty"`
-	HostsFailedCount       int                     `json:"hostsFailed,omitempty"            yaml:"hostsFailed,omitempty"`
-	HostsCompletedCount    int                     `json:"hostsCompleted,omitempty"         yaml:"hostsCompleted,omitempty"`
-	HostsDeletedCount      int                     `json:"hostsDeleted,omitempty"           yaml:"hostsDeleted,omitempty"`
-	HostsDeleteCount       int                     `json:"hostsDelete,omitempty"            yaml:"hostsDelete,omitempty"`
-	Pods                   []string                `json:"pods,omitempty"                   yaml:"pods,omitempty"`
-	PodIPs                 []string                `json:"pod-ips,omitempty"                yaml:"pod-ips,omitempty"`
-	FQDNs                  []string                `json:"fqdns,omitempty"                  yaml:"fqdns,omitempty"`
-	Endpoint               string                  `json:"endpoint,omitempty"               yaml:"endpoint,omitempty"`
-	NormalizedCHI          *ClickHouseInstallation `json:"normalized,omitempty"             yaml:"normalized,omitempty"`
-	NormalizedCHICompleted *ClickHouseInstallation `json:"normalizedCompleted,omitempty"    yaml:"normalizedCompleted,omitempty"`
-	HostsWithTablesCreated []string                `json:"hostsWithTablesCreated,omitempty" yaml:"hostsWithTablesCreated,omitempty"`
-	UsedTemplates          []*ChiUseTemplate       `json:"usedTemplates,omitempty"          yaml:"usedTemplates,omitempty"`
+	CHOpVersion            string                        `json:"chop-version,omitempty"           yaml:"chop-version,omitempty"`
+	CHOpCommit             string                        `json:"chop-commit,omitempty"            yaml:"chop-commit,omitempty"`
+	CHOpDate               string                        `json:"chop-date,omitempty"              yaml:"chop-date,omitempty"`
+	CHOpIP                 string                        `json:"chop-ip,omitempty"                yaml:"chop-ip,omitempty"`
+	ClustersCount          int                           `json:"clusters,omitempty"               yaml:"clusters,omitempty"`
+	ShardsCount            int                           `json:"shards,omitempty"                 yaml:"shards,omitempty"`
+	ReplicasCount          int                           `json:"replicas,omitempty"               yaml:"replicas,omitempty"`
+	HostsCount             int                           `json:"hosts,omitempty"                  yaml:"hosts,omitempty"`
+	Status                 string                        `json:"status,omitempty"                 yaml:"status,omitempty"`
+	TaskID                 string                        `json:"taskID,omitempty"                 yaml:"taskID,omitempty"`
+	TaskIDsStarted         []string                      `json:"taskIDsStarted,omitempty"         yaml:"taskIDsStarted,omitempty"`
+	TaskIDsCompleted       []string                      `json:"taskIDsCompleted,omitempty"       yaml:"taskIDsCompleted,omitempty"`
+	Action                 string                        `json:"action,omitempty"                 yaml:"action,omitempty"`
+	Actions                []string                      `json:"actions,omitempty"                yaml:"actions,omitempty"`
+	Error                  string                        `json:"error,omitempty"                  yaml:"error,omitempty"`
+	Errors                 []string                      `json:"errors,omitempty"                 yaml:"errors,omitempty"`
+	HostsUpdatedCount      int                           `json:"hostsUpdated,omitempty"           yaml:"hostsUpdated,omitempty"`
+	HostsAddedCount        int                           `json:"hostsAdded,omitempty"             yaml:"hostsAdded,omitempty"`
+	HostsUnchangedCount    int                           `json:"hostsUnchanged,omitempty"         yaml:"hostsUnchanged,omitempty"`
+	HostsFailedCount       int                           `json:"hostsFailed,omitempty"            yaml:"hostsFailed,omitempty"`
+	HostsCompletedCount    int                           `json:"hostsCompleted,omitempty"         yaml:"hostsCompleted,omitempty"`
+	HostsDeletedCount      int                           `json:"hostsDeleted,omitempty"           yaml:"hostsDeleted,omitempty"`
+	HostsDeleteCount       int                           `json:"hostsDelete,omitempty"            yaml:"hostsDelete,omitempty"`
+	Pods                   []string                      `json:"pods,omitempty"                   yaml:"pods,omitempty"`
+	PodIPs                 []string                      `json:"pod-ips,omitempty"                yaml:"pod-ips,omitempty"`
+	FQDNs                  []string                      `json:"fqdns,omitempty"                  yaml:"fqdns,omitempty"`
+	Endpoint               string                        `json:"endpoint,omitempty"               yaml:"endpoint,omitempty"`
+	Connection             *ChiConnectionStatus          `json:"connection,omitempty"             yaml:"connection,omitempty"`
+	Binding                *ChiBindingStatus             `json:"binding,omitempty"                yaml:"binding,omitempty"`
+	NormalizedCHI          *ClickHouseInstallation       `json:"normalized,omitempty"             yaml:"normalized,omitempty"`
+	NormalizedCHICompleted *ClickHouseInstallation       `json:"normalizedCompleted,omitempty"    yaml:"normalizedCompleted,omitempty"`
+	HostsWithTablesCreated []string                      `json:"hostsWithTablesCreated,omitempty" yaml:"hostsWithTablesCreated,omitempty"`
+	UsedTemplates          []*ChiUseTemplate             `json:"usedTemplates,omitempty"          yaml:"usedTemplates,omitempty"`
+	UpgradeVerification    *ChiUpgradeVerificationStatus `json:"upgradeVerification,omitempty"    yaml:"upgradeVerification,omitempty"`
+	DiskPressureHosts      []string                      `json:"diskPressureHosts,omitempty"      yaml:"diskPressureHosts,omitempty"`
+	StuckMutations         []string                      `json:"stuckMutations,omitempty"         yaml:"stuckMutations,omitempty"`
+	SpotTerminations       []string                      `json:"spotTerminations,omitempty"       yaml:"spotTerminations,omitempty"`
+	UnhealthyHosts         []string                      `json:"unhealthyHosts,omitempty"         yaml:"unhealthyHosts,omitempty"`
+	Drill                  *ChiDrillStatus               `json:"drill,omitempty"                  yaml:"drill,omitempty"`
+	Migrations             []string                      `json:"migrations,omitempty"             yaml:"migrations,omitempty"`
+	Rejections             []string                      `json:"rejections,omitempty"             yaml:"rejections,omitempty"`
+	UnmanagedObjects       []string                      `json:"unmanagedObjects,omitempty"       yaml:"unmanagedObjects,omitempty"`
+	MissingTemplates       []string                      `json:"missingTemplates,omitempty"       yaml:"missingTemplates,omitempty"`
+	Progress               *ChiReconcileProgress         `json:"progress,omitempty"               yaml:"progress,omitempty"`
+	Checkpoint             *ChiReconcileCheckpoint       `json:"checkpoint,omitempty"             yaml:"checkpoint,omitempty"`
+	Capacity               *ChiCapacityStatus            `json:"capacity,omitempty"               yaml:"capacity,omitempty"`
+	StandbyPromotions      []ChiStandbyPromotion         `json:"standbyPromotions,omitempty"      yaml:"standbyPromotions,omitempty"`
+	UsersFrom              *ChiUsersFromStatus           `json:"usersFrom,omitempty"              yaml:"usersFrom,omitempty"`
 
 	mu sync.RWMutex `json:"-" yaml:"-"`
 }
 
 // CopyCHIStatusOptions specifies what to copy in CHI status options
 type CopyCHIStatusOptions struct {
-	Actions           bool
-	Errors            bool
-	Normalized        bool
-	MainFields        bool
-	WholeStatus       bool
-	InheritableFields bool
+	Actions             bool
+	Errors              bool
+	Normalized          bool
+	MainFields          bool
+	WholeStatus         bool
+	InheritableFields   bool
+	UpgradeVerification bool
+	DiskPressureHosts   bool
+	StuckMutations      bool
+	SpotTerminations    bool
+	UnhealthyHosts      bool
+	Drill               bool
+	Capacity            bool
+	StandbyPromotions   bool
+	UsersFrom           bool
 }
 
 // FillStatusParams is a struct used to fill status params
@@ -93,6 +123,7 @@ type FillStatusParams struct {
 	CHOpIP              string
 	ClustersCount       int
 	ShardsCount         int
+	ReplicasCount       int
 	HostsCount          int
 	TaskID              string
 	HostsUpdatedCount   int
@@ -104,6 +135,7 @@ type FillStatusParams struct {
 	Pods                []string
 	FQDNs               []string
 	Endpoint            string
+	Connection          *ChiConnectionStatus
 	NormalizedCHI       *ClickHouseInstallation
 }
 
@@ -121,6 +153,7 @@ func (s *ChiStatus) Fill(params *FillStatusParams) {
 		s.CHOpIP = params.CHOpIP
 		s.ClustersCount = params.ClustersCount
 		s.ShardsCount = params.ShardsCount
+		s.ReplicasCount = params.ReplicasCount
 		s.HostsCount = params.HostsCount
 		s.TaskID = params.TaskID
 		s.HostsUpdatedCount = params.HostsUpdatedCount
@@ -132,6 +165,7 @@ func (s *ChiStatus) Fill(params *FillStatusParams) {
 		s.Pods = params.Pods
 		s.FQDNs = params.FQDNs
 		s.Endpoint = params.Endpoint
+		s.Connection = params.Connection
 		s.NormalizedCHI = params.NormalizedCHI
 	})
 }
@@ -164,6 +198,16 @@ func (s *ChiStatus) PushHostTablesCreated(host string) {
 	})
 }
 
+// PushUnmanagedObject pushes child object excluded from management by the operator to the list of unmanaged objects
+func (s *ChiStatus) PushUnmanagedObject(object string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		if util.InArray(object, s.UnmanagedObjects) {
+			return
+		}
+		s.UnmanagedObjects = append(s.UnmanagedObjects, object)
+	})
+}
+
 // SyncHostTablesCreated syncs list of hosts with tables created with actual list of hosts
 func (s *ChiStatus) SyncHostTablesCreated() {
 	doWithWriteLock(s, func(s *ChiStatus) {
@@ -272,6 +316,97 @@ func (s *ChiStatus) HostCompleted() {
 	})
 }
 
+// ProgressStart starts tracking progress of the reconcile of specified number of hosts
+func (s *ChiStatus) ProgressStart(hostsTotal int) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Progress = NewChiReconcileProgress(hostsTotal, time.Now())
+	})
+}
+
+// ProgressHostStarted marks host reconcile started in progress of the reconcile
+func (s *ChiStatus) ProgressHostStarted(host string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Progress.hostStarted(host)
+	})
+}
+
+// ProgressHostCompleted marks host reconcile completed in progress of the reconcile
+func (s *ChiStatus) ProgressHostCompleted(host string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Progress.hostCompleted(host, time.Now())
+	})
+}
+
+// ProgressAPIWritesThrottled sets time API writes waited for the budget during the reconcile
+func (s *ChiStatus) ProgressAPIWritesThrottled(throttled time.Duration) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Progress.apiWritesThrottled(throttled)
+	})
+}
+
+// CheckpointStart starts checkpointing the reconcile of specified CHI generation.
+// Checkpoint made by the interrupted reconcile of the same generation is kept, so the reconcile is resumed.
+// Returns whether the reconcile is resumed
+func (s *ChiStatus) CheckpointStart(generation int64) bool {
+	resumed := false
+	doWithWriteLock(s, func(s *ChiStatus) {
+		if s == nil {
+			return
+		}
+		if s.Checkpoint.IsFor(generation) && (len(s.Checkpoint.HostsCompleted) > 0) {
+			s.Checkpoint.ResumeAt = ""
+			resumed = true
+			return
+		}
+		checkpoint := NewChiReconcileCheckpoint(generation, time.Now())
+		checkpoint.carryWindowFrom(s.Checkpoint)
+		s.Checkpoint = checkpoint
+	})
+	return resumed
+}
+
+// CheckpointAdmitHost checks whether the host can be modified within the current window of staged rollout,
+// having specified max number of hosts modified per window of specified duration
+func (s *ChiStatus) CheckpointAdmitHost(host string, batch int, window time.Duration) bool {
+	admitted := true
+	doWithWriteLock(s, func(s *ChiStatus) {
+		admitted = s.Checkpoint.admitHost(host, batch, window, time.Now())
+	})
+	return admitted
+}
+
+// CheckpointPause pauses the reconcile till the end of the current window of staged rollout
+func (s *ChiStatus) CheckpointPause(window time.Duration) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Checkpoint.pause(window, time.Now())
+	})
+}
+
+// CheckpointHostCompleted marks host reconcile completed in checkpoint of the reconcile
+func (s *ChiStatus) CheckpointHostCompleted(host string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Checkpoint.hostCompleted(host, time.Now())
+	})
+}
+
+// GetCheckpoint gets checkpoint of the reconcile
+func (s *ChiStatus) GetCheckpoint() *ChiReconcileCheckpoint {
+	var res *ChiReconcileCheckpoint
+	doWithReadLock(s, func(s *ChiStatus) {
+		res = s.Checkpoint.DeepCopy()
+	})
+	return res
+}
+
+// GetProgress gets progress of the reconcile
+func (s *ChiStatus) GetProgress() *ChiReconcileProgress {
+	var res *ChiReconcileProgress
+	doWithReadLock(s, func(s *ChiStatus) {
+		res = s.Progress.DeepCopy()
+	})
+	return res
+}
+
 // ReconcileStart marks reconcile start
 func (s *ChiStatus) ReconcileStart(deleteHostsCount int) {
 	doWithWriteLock(s, func(s *ChiStatus) {
@@ -285,6 +420,7 @@ func (s *ChiStatus) ReconcileStart(deleteHostsCount int) {
 		s.HostsCompletedCount = 0
 		s.HostsDeletedCount = 0
 		s.HostsDeleteCount = deleteHostsCount
+		s.UnmanagedObjects = nil
 		pushTaskIDStartedNoSync(s)
 	})
 }
@@ -297,6 +433,8 @@ func (s *ChiStatus) ReconcileComplete() {
 		}
 		s.Status = StatusCompleted
 		s.Action = ""
+		s.Progress = nil
+		s.Checkpoint = nil
 		pushTaskIDCompletedNoSync(s)
 	})
 }
@@ -309,10 +447,25 @@ func (s *ChiStatus) ReconcileAbort() {
 		}
 		s.Status = StatusAborted
 		s.Action = ""
+		s.Progress = nil
+		s.Checkpoint = nil
 		pushTaskIDCompletedNoSync(s)
 	})
 }
 
+// ReconcileInterrupt marks reconcile interrupted by the operator shutdown.
+// Checkpoint is kept, so the reconcile is resumed from the last completed host
+func (s *ChiStatus) ReconcileInterrupt() {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		if s == nil {
+			return
+		}
+		s.Status = StatusInterrupted
+		s.Action = ""
+		s.Progress = nil
+	})
+}
+
 // DeleteStart marks deletion start
 func (s *ChiStatus) DeleteStart() {
 	doWithWriteLock(s, func(s *ChiStatus) {
@@ -344,6 +497,16 @@ func (s *ChiStatus) CopyFrom(f *ChiStatus, opts CopyCHIStatusOptions) {
 				s.Actions = from.Actions
 				s.Errors = from.Errors
 				s.HostsWithTablesCreated = from.HostsWithTablesCreated
+				s.UpgradeVerification = from.UpgradeVerification
+				s.DiskPressureHosts = from.DiskPressureHosts
+				s.StuckMutations = from.StuckMutations
+				s.SpotTerminations = from.SpotTerminations
+				s.UnhealthyHosts = from.UnhealthyHosts
+				s.Drill = from.Drill.DeepCopy()
+				s.Capacity = from.Capacity.DeepCopy()
+				s.Checkpoint = from.Checkpoint.DeepCopy()
+				s.StandbyPromotions = copyStandbyPromotions(from.StandbyPromotions)
+				s.UsersFrom = from.UsersFrom.DeepCopy()
 			}
 
 			if opts.Actions {
@@ -392,13 +555,54 @@ func (s *ChiStatus) CopyFrom(f *ChiStatus, opts CopyCHIStatusOptions) {
 				s.PodIPs = from.PodIPs
 				s.FQDNs = from.FQDNs
 				s.Endpoint = from.Endpoint
+				s.Connection = from.Connection.DeepCopy()
+				s.Binding = from.Binding.DeepCopy()
 				s.NormalizedCHI = from.NormalizedCHI
+				s.Migrations = from.Migrations
+				s.Rejections = from.Rejections
+				s.UnmanagedObjects = from.UnmanagedObjects
+				s.MissingTemplates = from.MissingTemplates
+				s.Progress = from.Progress.DeepCopy()
+				s.Checkpoint = from.Checkpoint.DeepCopy()
 			}
 
 			if opts.Normalized {
 				s.NormalizedCHI = from.NormalizedCHI
 			}
 
+			if opts.UpgradeVerification {
+				s.UpgradeVerification = from.UpgradeVerification
+			}
+
+			if opts.DiskPressureHosts {
+				s.DiskPressureHosts = from.DiskPressureHosts
+			}
+
+			if opts.StuckMutations {
+				s.StuckMutations = from.StuckMutations
+			}
+
+			if opts.SpotTerminations {
+				s.SpotTerminations = from.SpotTerminations
+			}
+
+			if opts.UnhealthyHosts {
+				s.UnhealthyHosts = from.UnhealthyHosts
+			}
+
+			if opts.Drill {
+				s.Drill = from.Drill.DeepCopy()
+			}
+
+			if opts.Capacity {
+				s.Capacity = from.Capacity.DeepCopy()
+			}
+
+			if opts.StandbyPromotions {
+				s.StandbyPromotions = copyStandbyPromotions(from.StandbyPromotions)
+				s.UsersFrom = from.UsersFrom.DeepCopy()
+			}
+
 			if opts.WholeStatus {
 				s.CHOpVersion = from.CHOpVersion
 				s.CHOpCommit = from.CHOpCommit
@@ -426,8 +630,25 @@ func (s *ChiStatus) CopyFrom(f *ChiStatus, opts CopyCHIStatusOptions) {
 				s.PodIPs = from.PodIPs
 				s.FQDNs = from.FQDNs
 				s.Endpoint = from.Endpoint
+				s.Connection = from.Connection.DeepCopy()
+				s.Binding = from.Binding.DeepCopy()
 				s.NormalizedCHI = from.NormalizedCHI
 				s.NormalizedCHICompleted = from.NormalizedCHICompleted
+				s.UpgradeVerification = from.UpgradeVerification
+				s.DiskPressureHosts = from.DiskPressureHosts
+				s.StuckMutations = from.StuckMutations
+				s.SpotTerminations = from.SpotTerminations
+				s.UnhealthyHosts = from.UnhealthyHosts
+				s.Drill = from.Drill.DeepCopy()
+				s.Migrations = from.Migrations
+				s.Rejections = from.Rejections
+				s.UnmanagedObjects = from.UnmanagedObjects
+				s.MissingTemplates = from.MissingTemplates
+				s.Progress = from.Progress.DeepCopy()
+				s.Checkpoint = from.Checkpoint.DeepCopy()
+				s.Capacity = from.Capacity.DeepCopy()
+				s.StandbyPromotions = copyStandbyPromotions(from.StandbyPromotions)
+				s.UsersFrom = from.UsersFrom.DeepCopy()
 			}
 		})
 	})
@@ -636,6 +857,31 @@ func (s *ChiStatus) GetEndpoint() string {
 	})
 }
 
+// GetConnection gets connection details of the CHI
+func (s *ChiStatus) GetConnection() *ChiConnectionStatus {
+	var res *ChiConnectionStatus
+	doWithReadLock(s, func(s *ChiStatus) {
+		res = s.Connection.DeepCopy()
+	})
+	return res
+}
+
+// GetBinding gets Provisioned Service binding of the CHI
+func (s *ChiStatus) GetBinding() *ChiBindingStatus {
+	var res *ChiBindingStatus
+	doWithReadLock(s, func(s *ChiStatus) {
+		res = s.Binding.DeepCopy()
+	})
+	return res
+}
+
+// SetBinding sets Provisioned Service binding of the CHI
+func (s *ChiStatus) SetBinding(binding *ChiBindingStatus) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Binding = binding
+	})
+}
+
 // GetNormalizedCHI gets target CHI
 func (s *ChiStatus) GetNormalizedCHI() *ClickHouseInstallation {
 	return getInstallationWithReadLock(s, func(s *ChiStatus) *ClickHouseInstallation {
@@ -657,8 +903,224 @@ func (s *ChiStatus) GetHostsWithTablesCreated() []string {
 	})
 }
 
+// GetUpgradeVerification gets upgrade verification status
+func (s *ChiStatus) GetUpgradeVerification() *ChiUpgradeVerificationStatus {
+	var res *ChiUpgradeVerificationStatus
+	doWithReadLock(s, func(s *ChiStatus) {
+		res = s.UpgradeVerification
+	})
+	return res
+}
+
+// SetUpgradeVerification sets upgrade verification status
+func (s *ChiStatus) SetUpgradeVerification(status *ChiUpgradeVerificationStatus) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.UpgradeVerification = status
+	})
+}
+
+// GetDiskPressureHosts gets names of hosts with disk usage over the threshold of disk usage maintenance
+func (s *ChiStatus) GetDiskPressureHosts() []string {
+	return getStringArrWithReadLock(s, func(s *ChiStatus) []string {
+		return s.DiskPressureHosts
+	})
+}
+
+// SetDiskPressureHosts sets names of hosts with disk usage over the threshold of disk usage maintenance
+func (s *ChiStatus) SetDiskPressureHosts(hosts []string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.DiskPressureHosts = hosts
+	})
+}
+
+// GetStuckMutations gets mutations running longer than max duration of mutations maintenance
+func (s *ChiStatus) GetStuckMutations() []string {
+	return getStringArrWithReadLock(s, func(s *ChiStatus) []string {
+		return s.StuckMutations
+	})
+}
+
+// SetStuckMutations sets mutations running longer than max duration of mutations maintenance
+func (s *ChiStatus) SetStuckMutations(mutations []string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.StuckMutations = mutations
+	})
+}
+
+// GetUsersFrom gets status of users sources tracked by the operator
+func (s *ChiStatus) GetUsersFrom() *ChiUsersFromStatus {
+	var res *ChiUsersFromStatus
+	doWithReadLock(s, func(s *ChiStatus) {
+		res = s.UsersFrom.DeepCopy()
+	})
+	return res
+}
+
+// SetUsersFrom sets status of users sources tracked by the operator
+func (s *ChiStatus) SetUsersFrom(usersFrom *ChiUsersFromStatus) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.UsersFrom = usersFrom
+	})
+}
+
+// GetDrill gets status of the current or the last drill
+func (s *ChiStatus) GetDrill() *ChiDrillStatus {
+	var res *ChiDrillStatus
+	doWithReadLock(s, func(s *ChiStatus) {
+		res = s.Drill.DeepCopy()
+	})
+	return res
+}
+
+// SetDrill sets status of the current or the last drill
+func (s *ChiStatus) SetDrill(drill *ChiDrillStatus) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Drill = drill
+	})
+}
+
+// GetCapacity gets resources footprint of the CHI
+func (s *ChiStatus) GetCapacity() *ChiCapacityStatus {
+	var res *ChiCapacityStatus
+	doWithReadLock(s, func(s *ChiStatus) {
+		res = s.Capacity.DeepCopy()
+	})
+	return res
+}
+
+// SetCapacity sets resources footprint of the CHI
+func (s *ChiStatus) SetCapacity(capacity *ChiCapacityStatus) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Capacity = capacity
+	})
+}
+
+// GetSpotTerminations gets hosts drained due to termination notice of their spot nodes
+func (s *ChiStatus) GetSpotTerminations() []string {
+	return getStringArrWithReadLock(s, func(s *ChiStatus) []string {
+		return s.SpotTerminations
+	})
+}
+
+// SetSpotTerminations sets hosts drained due to termination notice of their spot nodes
+func (s *ChiStatus) SetSpotTerminations(terminations []string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.SpotTerminations = terminations
+	})
+}
+
+// GetUnhealthyHosts gets hosts, pods of which are failing
+func (s *ChiStatus) GetUnhealthyHosts() []string {
+	return getStringArrWithReadLock(s, func(s *ChiStatus) []string {
+		return s.UnhealthyHosts
+	})
+}
+
+// SetUnhealthyHosts sets hosts, pods of which are failing
+func (s *ChiStatus) SetUnhealthyHosts(hosts []string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.UnhealthyHosts = hosts
+	})
+}
+
+// GetStandbyPromotions gets standby hosts promoted into hosts of clusters
+func (s *ChiStatus) GetStandbyPromotions() []ChiStandbyPromotion {
+	var res []ChiStandbyPromotion
+	doWithReadLock(s, func(s *ChiStatus) {
+		res = copyStandbyPromotions(s.StandbyPromotions)
+	})
+	return res
+}
+
+// FindStandbyPromotion finds standby host promoted into the host of the cluster
+func (s *ChiStatus) FindStandbyPromotion(cluster, host string) (*ChiStandbyPromotion, bool) {
+	var res *ChiStandbyPromotion
+	doWithReadLock(s, func(s *ChiStatus) {
+		for i := range s.StandbyPromotions {
+			if (s.StandbyPromotions[i].Cluster == cluster) && (s.StandbyPromotions[i].Host == host) {
+				promotion := s.StandbyPromotions[i]
+				res = &promotion
+			}
+		}
+	})
+	return res, res != nil
+}
+
+// PushStandbyPromotion pushes standby host promoted into the host, replacing previous promotion into the same host
+func (s *ChiStatus) PushStandbyPromotion(promotion ChiStandbyPromotion) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		promotions := []ChiStandbyPromotion{promotion}
+		for _, p := range s.StandbyPromotions {
+			if (p.Cluster != promotion.Cluster) || (p.Host != promotion.Host) {
+				promotions = append(promotions, p)
+			}
+		}
+		s.StandbyPromotions = promotions
+	})
+}
+
+// GetMigrations gets deprecated fields of the spec migrated into the current layout
+func (s *ChiStatus) GetMigrations() []string {
+	return getStringArrWithReadLock(s, func(s *ChiStatus) []string {
+		return s.Migrations
+	})
+}
+
+// GetRejections gets invalid entries of the spec rejected by the normalizer
+func (s *ChiStatus) GetRejections() []string {
+	return getStringArrWithReadLock(s, func(s *ChiStatus) []string {
+		return s.Rejections
+	})
+}
+
+// GetUnmanagedObjects gets child objects excluded from management by the operator
+func (s *ChiStatus) GetUnmanagedObjects() []string {
+	return getStringArrWithReadLock(s, func(s *ChiStatus) []string {
+		return s.UnmanagedObjects
+	})
+}
+
+// GetMissingTemplates gets templates referenced by hosts, but not found
+func (s *ChiStatus) GetMissingTemplates() []string {
+	return getStringArrWithReadLock(s, func(s *ChiStatus) []string {
+		return s.MissingTemplates
+	})
+}
+
+// SetMissingTemplates sets templates referenced by hosts, but not found
+func (s *ChiStatus) SetMissingTemplates(missing []string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.MissingTemplates = missing
+	})
+}
+
+// SetMigrations sets deprecated fields of the spec migrated into the current layout
+func (s *ChiStatus) SetMigrations(migrations []string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Migrations = migrations
+	})
+}
+
+// SetRejections sets invalid entries of the spec rejected by the normalizer
+func (s *ChiStatus) SetRejections(rejections []string) {
+	doWithWriteLock(s, func(s *ChiStatus) {
+		s.Rejections = rejections
+	})
+}
+
 // Begin helpers
 
+func copyStandbyPromotions(promotions []ChiStandbyPromotion) []ChiStandbyPromotion {
+	if promotions == nil {
+		return nil
+	}
+	res := make([]ChiStandbyPromotion, len(promotions))
+	for i := range promotions {
+		promotions[i].DeepCopyInto(&res[i])
+	}
+	return res
+}
+
 func doWithWriteLock(s *ChiStatus, f func(s *ChiStatus)) {
 	if s == nil {
 		return
@@ -12,6 +12,7 @@ var fillParamsA = &FillStatusParams{
 	CHOpIP:              "1.2.3.4",
 	ClustersCount:       1,
 	ShardsCount:         2,
+	ReplicasCount:       2,
 	HostsCount:          3,
 	TaskID:              "task-a",
 	HostsUpdatedCount:   4,
@@ -30,6 +31,7 @@ var fillParamsB = &FillStatusParams{
 	CHOpIP:              "5.6.7.8",
 	ClustersCount:       10,
 	ShardsCount:         20,
+	ReplicasCount:       20,
 	HostsCount:          30,
 	TaskID:              "task-b",
 	HostsUpdatedCount:   40,
@@ -74,6 +76,70 @@ var copyTestStatusFrom = &ChiStatus{
 	NormalizedCHI:          normalizedChiA,
 	NormalizedCHICompleted: normalizedChiA,
 	HostsWithTablesCreated: []string{"host-a-1", "host-a-2"},
+	Connection: &ChiConnectionStatus{
+		Host:       "endpt-a",
+		HTTPPort:   8123,
+		NativePort: 9000,
+		HTTPURL:    "http://endpt-a:8123",
+	},
+	Binding: &ChiBindingStatus{
+		Name: "chi-a-binding-user-a",
+	},
+	UpgradeVerification: &ChiUpgradeVerificationStatus{
+		Image:   "image-a",
+		Sandbox: "sandbox-a",
+		Status:  UpgradeVerificationStatusPassed,
+	},
+	Drill: &ChiDrillStatus{
+		Run:       1,
+		Status:    DrillStatusPassed,
+		StartTime: "2024-01-01T00:00:00Z",
+		Hosts:     []string{"host-a-1: recovered in 30s"},
+	},
+	DiskPressureHosts: []string{"host-a-1"},
+	StuckMutations:    []string{"host-a-1: db.table:0000000001"},
+	SpotTerminations:  []string{"host-a-2: node node-a taint karpenter.sh/disruption"},
+	UnhealthyHosts:    []string{"host-a-3: container clickhouse CrashLoopBackOff"},
+	Migrations:        []string{"spec.templates.podTemplates[0].distribution: OnePerHost -> spec.templates.podTemplates[0].podDistribution[0].type: ClickHouseAntiAffinity"},
+	UnmanagedObjects:  []string{"Service ns-a/clickhouse-chi-a: spec.ports"},
+	MissingTemplates:  []string{"podTemplate pod-a referenced by replica 0 of shard 0 of cluster cluster-a is not found"},
+	Progress: &ChiReconcileProgress{
+		StartedAt:           "2024-01-01T00:00:00Z",
+		HostsTotal:          4,
+		HostsCompleted:      1,
+		HostsInProgress:     []string{"host-a-2"},
+		EstimatedCompletion: "2024-01-01T00:30:00Z",
+	},
+	Checkpoint: &ChiReconcileCheckpoint{
+		Generation:      3,
+		HostsCompleted:  []string{"host-a-1"},
+		UpdatedAt:       "2024-01-01T00:10:00Z",
+		WindowStartedAt: "2024-01-01T00:00:00Z",
+		WindowHosts:     []string{"host-a-1"},
+		ResumeAt:        "2024-01-01T01:00:00Z",
+	},
+	Capacity: &ChiCapacityStatus{
+		Pods:            2,
+		PVCs:            2,
+		CPURequests:     "2",
+		MemoryRequests:  "8Gi",
+		StorageRequests: "200Gi",
+		StorageUsed:     "12Gi",
+	},
+	StandbyPromotions: []ChiStandbyPromotion{
+		{
+			Cluster:    "cluster-a",
+			Host:       "host-a-4",
+			Standby:    0,
+			Node:       "node-b",
+			PromotedAt: "2024-01-01T00:20:00Z",
+		},
+	},
+	UsersFrom: &ChiUsersFromStatus{
+		Versions:   []string{"ConfigMap/users-extra:12345"},
+		ChangedAt:  "2024-01-01T00:30:00Z",
+		ReloadedAt: "2024-01-01T00:31:00Z",
+	},
 }
 
 // NB: These tests mostly exist to exercise synchronization and detect regressions related to them via the
@@ -141,6 +207,7 @@ func Test_ChiStatus_BasicOperations_SingleStatus_ConcurrencyTest(t *testing.T) {
 				require.Equal(tt, expectedParams.CHOpIP, s.CHOpIP)
 				require.Equal(tt, expectedParams.ClustersCount, s.ClustersCount)
 				require.Equal(tt, expectedParams.ShardsCount, s.ShardsCount)
+				require.Equal(tt, expectedParams.ReplicasCount, s.ReplicasCount)
 				require.Equal(tt, expectedParams.HostsCount, s.HostsCount)
 				require.Equal(tt, expectedParams.TaskID, s.TaskID)
 				require.Equal(tt, expectedParams.HostsUpdatedCount, s.HostsUpdatedCount)
@@ -191,6 +258,8 @@ func Test_ChiStatus_BasicOperations_SingleStatus_ConcurrencyTest(t *testing.T) {
 				require.Equal(tt, copyTestStatusFrom.GetCHOpVersion(), s.GetCHOpVersion())
 				require.Equal(tt, copyTestStatusFrom.GetClustersCount(), s.GetClustersCount())
 				require.Equal(tt, copyTestStatusFrom.GetEndpoint(), s.GetEndpoint())
+				require.Equal(tt, copyTestStatusFrom.GetConnection(), s.GetConnection())
+				require.Equal(tt, copyTestStatusFrom.GetBinding(), s.GetBinding())
 				require.Equal(tt, copyTestStatusFrom.GetError(), s.GetError())
 				require.Equal(tt, copyTestStatusFrom.GetErrors(), s.GetErrors())
 				require.Equal(tt, copyTestStatusFrom.GetErrors(), s.GetErrors())
@@ -214,6 +283,20 @@ func Test_ChiStatus_BasicOperations_SingleStatus_ConcurrencyTest(t *testing.T) {
 				require.Equal(tt, copyTestStatusFrom.GetTaskID(), s.GetTaskID())
 				require.Equal(tt, copyTestStatusFrom.GetTaskIDsCompleted(), s.GetTaskIDsCompleted())
 				require.Equal(tt, copyTestStatusFrom.GetTaskIDsStarted(), s.GetTaskIDsStarted())
+				require.Equal(tt, copyTestStatusFrom.GetUpgradeVerification(), s.GetUpgradeVerification())
+				require.Equal(tt, copyTestStatusFrom.GetDiskPressureHosts(), s.GetDiskPressureHosts())
+				require.Equal(tt, copyTestStatusFrom.GetStuckMutations(), s.GetStuckMutations())
+				require.Equal(tt, copyTestStatusFrom.GetSpotTerminations(), s.GetSpotTerminations())
+				require.Equal(tt, copyTestStatusFrom.GetUnhealthyHosts(), s.GetUnhealthyHosts())
+				require.Equal(tt, copyTestStatusFrom.GetDrill(), s.GetDrill())
+				require.Equal(tt, copyTestStatusFrom.GetMigrations(), s.GetMigrations())
+				require.Equal(tt, copyTestStatusFrom.GetUnmanagedObjects(), s.GetUnmanagedObjects())
+				require.Equal(tt, copyTestStatusFrom.GetMissingTemplates(), s.GetMissingTemplates())
+				require.Equal(tt, copyTestStatusFrom.GetProgress(), s.GetProgress())
+				require.Equal(tt, copyTestStatusFrom.GetCheckpoint(), s.GetCheckpoint())
+				require.Equal(tt, copyTestStatusFrom.GetCapacity(), s.GetCapacity())
+				require.Equal(tt, copyTestStatusFrom.GetStandbyPromotions(), s.GetStandbyPromotions())
+				require.Equal(tt, copyTestStatusFrom.GetUsersFrom(), s.GetUsersFrom())
 			},
 		},
 	} {
@@ -0,0 +1,224 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"strings"
+
+	core "k8s.io/api/core/v1"
+)
+
+// DefaultEntrypoint specifies entrypoint of the official ClickHouse image
+const DefaultEntrypoint = "/entrypoint.sh"
+
+// ChiSystemTuning defines kernel parameters and process limits ClickHouse pods run with.
+// Values specified explicitly by pod templates take precedence.
+type ChiSystemTuning struct {
+	// Sysctls specifies namespaced kernel parameters set via pod's security context, ex.: net.core.somaxconn
+	Sysctls map[string]string `json:"sysctls,omitempty" yaml:"sysctls,omitempty"`
+	// Ulimits specifies process limits set for ClickHouse process
+	Ulimits *ChiUlimits `json:"ulimits,omitempty" yaml:"ulimits,omitempty"`
+	// Memory specifies huge pages and memory locking of ClickHouse
+	Memory *ChiMemoryTuning `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
+
+// ChiMemoryTuning defines memory tuning, generated into both pod resources and ClickHouse server settings
+type ChiMemoryTuning struct {
+	// HugePages specifies huge pages per page size requested for ClickHouse container, ex.: hugepages-2Mi: 1Gi.
+	// Enables 'remap_executable' server setting
+	HugePages core.ResourceList `json:"hugePages,omitempty"       yaml:"hugePages,omitempty"`
+	// MLockExecutable specifies to lock ClickHouse executable in memory.
+	// Enables 'mlock_executable' server setting and adds IPC_LOCK capability to ClickHouse container
+	MLockExecutable *StringBool `json:"mlockExecutable,omitempty" yaml:"mlockExecutable,omitempty"`
+}
+
+// ChiUlimits defines process limits set by wrapping entrypoint of ClickHouse container.
+// Limits can be raised up to the hard limits of the container runtime only
+type ChiUlimits struct {
+	// NoFile specifies max number of open files
+	NoFile int64 `json:"nofile,omitempty"     yaml:"nofile,omitempty"`
+	// NProc specifies max number of processes
+	NProc int64 `json:"nproc,omitempty"      yaml:"nproc,omitempty"`
+	// Entrypoint specifies entrypoint of the image to be wrapped. Defaults to entrypoint of the official image
+	Entrypoint string `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
+}
+
+// GetSysctls gets kernel parameters
+func (t *ChiSystemTuning) GetSysctls() map[string]string {
+	if t == nil {
+		return nil
+	}
+	return t.Sysctls
+}
+
+// GetUlimits gets process limits
+func (t *ChiSystemTuning) GetUlimits() *ChiUlimits {
+	if t == nil {
+		return nil
+	}
+	return t.Ulimits
+}
+
+// GetMemory gets memory tuning
+func (t *ChiSystemTuning) GetMemory() *ChiMemoryTuning {
+	if t == nil {
+		return nil
+	}
+	return t.Memory
+}
+
+// MergeFrom merges from specified system tuning
+func (t *ChiSystemTuning) MergeFrom(from *ChiSystemTuning, _type MergeType) *ChiSystemTuning {
+	if from == nil {
+		return t
+	}
+
+	if t == nil {
+		t = new(ChiSystemTuning)
+	}
+
+	for name, value := range from.Sysctls {
+		if t.Sysctls == nil {
+			t.Sysctls = make(map[string]string)
+		}
+		if _, ok := t.Sysctls[name]; ok && (_type == MergeTypeFillEmptyValues) {
+			continue
+		}
+		t.Sysctls[name] = value
+	}
+	t.Ulimits = t.Ulimits.MergeFrom(from.Ulimits, _type)
+	t.Memory = t.Memory.MergeFrom(from.Memory, _type)
+
+	return t
+}
+
+// IsEmpty checks whether no limits are specified
+func (u *ChiUlimits) IsEmpty() bool {
+	if u == nil {
+		return true
+	}
+	return (u.NoFile <= 0) && (u.NProc <= 0)
+}
+
+// GetEntrypoint gets entrypoint of the image to be wrapped
+func (u *ChiUlimits) GetEntrypoint() string {
+	if (u == nil) || (u.Entrypoint == "") {
+		return DefaultEntrypoint
+	}
+	return u.Entrypoint
+}
+
+// MergeFrom merges from specified limits
+func (u *ChiUlimits) MergeFrom(from *ChiUlimits, _type MergeType) *ChiUlimits {
+	if from == nil {
+		return u
+	}
+
+	if u == nil {
+		u = new(ChiUlimits)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if u.NoFile == 0 {
+			u.NoFile = from.NoFile
+		}
+		if u.NProc == 0 {
+			u.NProc = from.NProc
+		}
+		if u.Entrypoint == "" {
+			u.Entrypoint = from.Entrypoint
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.NoFile != 0 {
+			u.NoFile = from.NoFile
+		}
+		if from.NProc != 0 {
+			u.NProc = from.NProc
+		}
+		if from.Entrypoint != "" {
+			u.Entrypoint = from.Entrypoint
+		}
+	}
+
+	return u
+}
+
+// GetHugePages gets huge pages per page size. Resources, which are not huge pages, are skipped
+func (m *ChiMemoryTuning) GetHugePages() core.ResourceList {
+	if m == nil {
+		return nil
+	}
+	hugePages := make(core.ResourceList)
+	for name, quantity := range m.HugePages {
+		if strings.HasPrefix(string(name), core.ResourceHugePagesPrefix) {
+			hugePages[name] = quantity
+		}
+	}
+	return hugePages
+}
+
+// IsMLockExecutable checks whether ClickHouse executable is to be locked in memory
+func (m *ChiMemoryTuning) IsMLockExecutable() bool {
+	if m == nil {
+		return false
+	}
+	return m.MLockExecutable.Value()
+}
+
+// GetServerSettings gets server settings memory tuning goes into
+func (m *ChiMemoryTuning) GetServerSettings() map[string]string {
+	settings := make(map[string]string)
+	if len(m.GetHugePages()) > 0 {
+		settings["remap_executable"] = "1"
+	}
+	if m.IsMLockExecutable() {
+		settings["mlock_executable"] = "1"
+	}
+	return settings
+}
+
+// MergeFrom merges from specified memory tuning
+func (m *ChiMemoryTuning) MergeFrom(from *ChiMemoryTuning, _type MergeType) *ChiMemoryTuning {
+	if from == nil {
+		return m
+	}
+
+	if m == nil {
+		m = new(ChiMemoryTuning)
+	}
+
+	for name, quantity := range from.HugePages {
+		if m.HugePages == nil {
+			m.HugePages = make(core.ResourceList)
+		}
+		if _, ok := m.HugePages[name]; ok && (_type == MergeTypeFillEmptyValues) {
+			continue
+		}
+		m.HugePages[name] = quantity.DeepCopy()
+	}
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if !m.MLockExecutable.HasValue() {
+			m.MLockExecutable = m.MLockExecutable.MergeFrom(from.MLockExecutable)
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.MLockExecutable.HasValue() {
+			m.MLockExecutable = m.MLockExecutable.MergeFrom(from.MLockExecutable)
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// Possible upgrade verification statuses
+const (
+	UpgradeVerificationStatusInProgress = "InProgress"
+	UpgradeVerificationStatusPassed     = "Passed"
+	UpgradeVerificationStatusFailed     = "Failed"
+)
+
+// ChiUpgradeVerification defines upgrade verification sandbox.
+// Before the target image is rolled out over the CHI, the operator spawns a small ephemeral copy of the CHI
+// with the target image, restores schema-only snapshot into it and runs verification queries.
+// Real upgrade to the target image is gated on the result of the verification.
+type ChiUpgradeVerification struct {
+	// Image specifies target ClickHouse image to be verified
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+	// Queries specifies set of queries to be run over the sandbox. Verification fails in case any query fails
+	Queries []string `json:"queries,omitempty" yaml:"queries,omitempty"`
+}
+
+// NewChiUpgradeVerification creates new upgrade verification
+func NewChiUpgradeVerification() *ChiUpgradeVerification {
+	return new(ChiUpgradeVerification)
+}
+
+// GetImage gets target image
+func (v *ChiUpgradeVerification) GetImage() string {
+	if v == nil {
+		return ""
+	}
+	return v.Image
+}
+
+// HasImage checks whether target image is specified
+func (v *ChiUpgradeVerification) HasImage() bool {
+	return v.GetImage() != ""
+}
+
+// GetQueries gets verification queries
+func (v *ChiUpgradeVerification) GetQueries() []string {
+	if v == nil {
+		return nil
+	}
+	return v.Queries
+}
+
+// MergeFrom merges from specified upgrade verification
+func (v *ChiUpgradeVerification) MergeFrom(from *ChiUpgradeVerification, _type MergeType) *ChiUpgradeVerification {
+	if from == nil {
+		return v
+	}
+
+	if v == nil {
+		v = NewChiUpgradeVerification()
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if v.Image == "" {
+			v.Image = from.Image
+		}
+		if len(v.Queries) == 0 {
+			v.Queries = from.Queries
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Image != "" {
+			// Override by non-empty values only
+			v.Image = from.Image
+		}
+		if len(from.Queries) > 0 {
+			// Override by non-empty values only
+			v.Queries = from.Queries
+		}
+	}
+
+	return v
+}
+
+// ChiUpgradeVerificationStatus defines status of the upgrade verification
+type ChiUpgradeVerificationStatus struct {
+	Image   string `json:"image,omitempty"   yaml:"image,omitempty"`
+	Sandbox string `json:"sandbox,omitempty" yaml:"sandbox,omitempty"`
+	Status  string `json:"status,omitempty"  yaml:"status,omitempty"`
+	Error   string `json:"error,omitempty"   yaml:"error,omitempty"`
+}
+
+// GetImage gets target image being verified
+func (s *ChiUpgradeVerificationStatus) GetImage() string {
+	if s == nil {
+		return ""
+	}
+	return s.Image
+}
+
+// GetSandbox gets name of the sandbox CHI
+func (s *ChiUpgradeVerificationStatus) GetSandbox() string {
+	if s == nil {
+		return ""
+	}
+	return s.Sandbox
+}
+
+// GetStatus gets verification status
+func (s *ChiUpgradeVerificationStatus) GetStatus() string {
+	if s == nil {
+		return ""
+	}
+	return s.Status
+}
+
+// GetError gets verification error
+func (s *ChiUpgradeVerificationStatus) GetError() string {
+	if s == nil {
+		return ""
+	}
+	return s.Error
+}
+
+// IsPassed checks whether verification of the specified image has passed
+func (s *ChiUpgradeVerificationStatus) IsPassed(image string) bool {
+	return (s.GetImage() == image) && (s.GetStatus() == UpgradeVerificationStatusPassed)
+}
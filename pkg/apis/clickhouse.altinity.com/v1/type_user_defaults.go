@@ -0,0 +1,135 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// ChiUserDefaults defines defaults of a user, which are generated into users.xml of the user,
+// so no raw XML overrides are required for them
+type ChiUserDefaults struct {
+	// DefaultDatabase specifies database the user is connected to by default. Goes into users as default_database
+	DefaultDatabase string `json:"defaultDatabase,omitempty" yaml:"defaultDatabase,omitempty"`
+	// DefaultRoles specifies roles granted to the user, which are default roles of the user.
+	// Goes into users as grants/query
+	DefaultRoles []string `json:"defaultRoles,omitempty"    yaml:"defaultRoles,omitempty"`
+	// Access specifies hosts the user is allowed to connect from. Goes into users as networks
+	Access *ChiUserAccess `json:"access,omitempty"          yaml:"access,omitempty"`
+}
+
+// ChiUserAccess defines hosts the user is allowed to connect from
+type ChiUserAccess struct {
+	// IPs specifies IP addresses and subnets. Goes into users as networks/ip
+	IPs []string `json:"ips,omitempty"         yaml:"ips,omitempty"`
+	// Hosts specifies host names. Goes into users as networks/host
+	Hosts []string `json:"hosts,omitempty"       yaml:"hosts,omitempty"`
+	// HostRegexps specifies regexps of host names. Goes into users as networks/host_regexp
+	HostRegexps []string `json:"hostRegexps,omitempty" yaml:"hostRegexps,omitempty"`
+}
+
+// GetDefaultDatabase gets default database of the user
+func (d *ChiUserDefaults) GetDefaultDatabase() string {
+	if d == nil {
+		return ""
+	}
+	return d.DefaultDatabase
+}
+
+// GetDefaultRoles gets default roles of the user
+func (d *ChiUserDefaults) GetDefaultRoles() []string {
+	if d == nil {
+		return nil
+	}
+	return d.DefaultRoles
+}
+
+// GetAccess gets hosts the user is allowed to connect from
+func (d *ChiUserDefaults) GetAccess() *ChiUserAccess {
+	if d == nil {
+		return nil
+	}
+	return d.Access
+}
+
+// GetIPs gets IP addresses and subnets the user is allowed to connect from
+func (a *ChiUserAccess) GetIPs() []string {
+	if a == nil {
+		return nil
+	}
+	return a.IPs
+}
+
+// GetHosts gets host names the user is allowed to connect from
+func (a *ChiUserAccess) GetHosts() []string {
+	if a == nil {
+		return nil
+	}
+	return a.Hosts
+}
+
+// GetHostRegexps gets regexps of host names the user is allowed to connect from
+func (a *ChiUserAccess) GetHostRegexps() []string {
+	if a == nil {
+		return nil
+	}
+	return a.HostRegexps
+}
+
+// MergeFrom merges from specified user defaults
+func (d *ChiUserDefaults) MergeFrom(from *ChiUserDefaults, _type MergeType) *ChiUserDefaults {
+	if from == nil {
+		return d
+	}
+
+	if d == nil {
+		return from.DeepCopy()
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if d.DefaultDatabase == "" {
+			d.DefaultDatabase = from.DefaultDatabase
+		}
+		if len(d.DefaultRoles) == 0 {
+			d.DefaultRoles = from.DefaultRoles
+		}
+		if d.Access == nil {
+			d.Access = from.Access
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.DefaultDatabase != "" {
+			// Override by non-empty values only
+			d.DefaultDatabase = from.DefaultDatabase
+		}
+		if len(from.DefaultRoles) > 0 {
+			// Override by non-empty values only
+			d.DefaultRoles = from.DefaultRoles
+		}
+		if from.Access != nil {
+			// Override by non-empty values only
+			d.Access = from.Access
+		}
+	}
+
+	return d
+}
+
+// MergeUserDefaultsFrom merges user defaults user by user
+func MergeUserDefaultsFrom(to, from map[string]*ChiUserDefaults, _type MergeType) map[string]*ChiUserDefaults {
+	for username, defaults := range from {
+		if to == nil {
+			to = make(map[string]*ChiUserDefaults)
+		}
+		to[username] = to[username].MergeFrom(defaults, _type)
+	}
+	return to
+}
@@ -0,0 +1,117 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"time"
+
+	core "k8s.io/api/core/v1"
+)
+
+// Kinds of users sources
+const (
+	UsersSourceKindConfigMap = "ConfigMap"
+	UsersSourceKindSecret    = "Secret"
+)
+
+// ChiUsersSource defines existing ConfigMap or Secret with users.d files maintained outside of the operator.
+// Each key of the object is mounted as a file into users.d folder along with users generated by the operator,
+// so keys have to be unique across all sources
+type ChiUsersSource struct {
+	// ConfigMapRef specifies ConfigMap with users.d files
+	ConfigMapRef *core.LocalObjectReference `json:"configMapRef,omitempty" yaml:"configMapRef,omitempty"`
+	// SecretRef specifies Secret with users.d files
+	SecretRef *core.LocalObjectReference `json:"secretRef,omitempty"    yaml:"secretRef,omitempty"`
+}
+
+// GetKind gets kind of the object referenced by the source, if any
+func (s ChiUsersSource) GetKind() string {
+	switch {
+	case s.ConfigMapRef != nil:
+		return UsersSourceKindConfigMap
+	case s.SecretRef != nil:
+		return UsersSourceKindSecret
+	}
+	return ""
+}
+
+// GetName gets name of the object referenced by the source, if any
+func (s ChiUsersSource) GetName() string {
+	switch {
+	case s.ConfigMapRef != nil:
+		return s.ConfigMapRef.Name
+	case s.SecretRef != nil:
+		return s.SecretRef.Name
+	}
+	return ""
+}
+
+// String returns string representation of the source as kind/name
+func (s ChiUsersSource) String() string {
+	return s.GetKind() + "/" + s.GetName()
+}
+
+// MergeUsersSourcesFrom merges users sources, sources not specified yet are appended
+func MergeUsersSourcesFrom(to, from []ChiUsersSource) []ChiUsersSource {
+	for _, source := range from {
+		found := false
+		for _, existing := range to {
+			if existing.String() == source.String() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			to = append(to, *source.DeepCopy())
+		}
+	}
+	return to
+}
+
+// ChiUsersFromStatus defines status of users sources tracked by the operator.
+// ClickHouse reloads users config as soon as kubelet propagates changes of the sources into the pods,
+// so the reload is requested after the propagation delay
+type ChiUsersFromStatus struct {
+	// Versions specifies resource versions of the sources as kind/name:resourceVersion
+	Versions []string `json:"versions,omitempty"   yaml:"versions,omitempty"`
+	// ChangedAt specifies when change of the sources was detected
+	ChangedAt string `json:"changedAt,omitempty"  yaml:"changedAt,omitempty"`
+	// ReloadedAt specifies when users config was reloaded on the hosts last time
+	ReloadedAt string `json:"reloadedAt,omitempty" yaml:"reloadedAt,omitempty"`
+}
+
+// GetVersions gets resource versions of the sources
+func (s *ChiUsersFromStatus) GetVersions() []string {
+	if s == nil {
+		return nil
+	}
+	return s.Versions
+}
+
+// IsReloadDue checks whether users config is to be reloaded, since the sources changed
+// longer than the delay ago and were not reloaded after that
+func (s *ChiUsersFromStatus) IsReloadDue(now time.Time, delay time.Duration) bool {
+	if (s == nil) || (s.ChangedAt == "") {
+		return false
+	}
+	changedAt, err := time.Parse(time.RFC3339, s.ChangedAt)
+	if err != nil {
+		return false
+	}
+	if reloadedAt, err := time.Parse(time.RFC3339, s.ReloadedAt); (err == nil) && !reloadedAt.Before(changedAt) {
+		return false
+	}
+	return !now.Before(changedAt.Add(delay))
+}
@@ -0,0 +1,113 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+// Possible layout validation actions
+const (
+	// ValidationActionWarn specifies to report violations and proceed with reconcile
+	ValidationActionWarn = "warn"
+	// ValidationActionDeny specifies to report violations and refuse to reconcile
+	ValidationActionDeny = "deny"
+)
+
+// Possible layout validation profiles
+const (
+	// ValidationProfileDevelopment specifies development profile, single-replica shards are fine
+	ValidationProfileDevelopment = "development"
+	// ValidationProfileProduction specifies production profile, each shard is expected to be replicated
+	ValidationProfileProduction = "production"
+)
+
+// Possible template references validation modes
+const (
+	// ValidationTemplatesLenient specifies to report unknown template references and fall back to default templates
+	ValidationTemplatesLenient = "lenient"
+	// ValidationTemplatesStrict specifies to reject unknown template references
+	ValidationTemplatesStrict = "strict"
+)
+
+// ChiValidation defines semantic validation of the layout against anti-patterns,
+// such as even-sized keeper ensembles or single-replica shards in production
+type ChiValidation struct {
+	// Profile specifies set of rules to be checked. Defaults to development
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	// Action specifies what to do with violations found. Defaults to warn
+	Action string `json:"action,omitempty" yaml:"action,omitempty"`
+	// Templates specifies how to treat references to unknown templates. Defaults to lenient
+	Templates string `json:"templates,omitempty" yaml:"templates,omitempty"`
+}
+
+// IsProduction checks whether production profile is specified
+func (v *ChiValidation) IsProduction() bool {
+	if v == nil {
+		return false
+	}
+	return v.Profile == ValidationProfileProduction
+}
+
+// IsDeny checks whether reconcile should be refused in case of violations
+func (v *ChiValidation) IsDeny() bool {
+	if v == nil {
+		return false
+	}
+	return v.Action == ValidationActionDeny
+}
+
+// IsStrictTemplates checks whether references to unknown templates should be rejected
+func (v *ChiValidation) IsStrictTemplates() bool {
+	if v == nil {
+		return false
+	}
+	return v.Templates == ValidationTemplatesStrict
+}
+
+// MergeFrom merges from specified validation
+func (v *ChiValidation) MergeFrom(from *ChiValidation, _type MergeType) *ChiValidation {
+	if from == nil {
+		return v
+	}
+
+	if v == nil {
+		v = new(ChiValidation)
+	}
+
+	switch _type {
+	case MergeTypeFillEmptyValues:
+		if v.Profile == "" {
+			v.Profile = from.Profile
+		}
+		if v.Action == "" {
+			v.Action = from.Action
+		}
+		if v.Templates == "" {
+			v.Templates = from.Templates
+		}
+	case MergeTypeOverrideByNonEmptyValues:
+		if from.Profile != "" {
+			// Override by non-empty values only
+			v.Profile = from.Profile
+		}
+		if from.Action != "" {
+			// Override by non-empty values only
+			v.Action = from.Action
+		}
+		if from.Templates != "" {
+			// Override by non-empty values only
+			v.Templates = from.Templates
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,54 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ChiValidation(t *testing.T) {
+	var unset *ChiValidation
+	require.False(t, unset.IsProduction())
+	require.False(t, unset.IsDeny())
+
+	validation := &ChiValidation{Profile: ValidationProfileProduction, Action: ValidationActionDeny}
+	require.True(t, validation.IsProduction())
+	require.True(t, validation.IsDeny())
+
+	validation = &ChiValidation{Profile: ValidationProfileDevelopment, Action: ValidationActionWarn}
+	require.False(t, validation.IsProduction())
+	require.False(t, validation.IsDeny())
+}
+
+func Test_ChiValidation_MergeFrom(t *testing.T) {
+	from := &ChiValidation{Profile: ValidationProfileProduction, Action: ValidationActionDeny}
+
+	// Validation of templates is taken in case none is specified
+	var unset *ChiValidation
+	require.Equal(t, from, unset.MergeFrom(from, MergeTypeFillEmptyValues))
+	require.Nil(t, unset.MergeFrom(nil, MergeTypeFillEmptyValues))
+
+	// Specified values are kept on fill
+	validation := &ChiValidation{Action: ValidationActionWarn}
+	require.Equal(t, &ChiValidation{Profile: ValidationProfileProduction, Action: ValidationActionWarn},
+		validation.MergeFrom(from, MergeTypeFillEmptyValues))
+
+	// Specified values are overridden by non-empty values only
+	validation = &ChiValidation{Profile: ValidationProfileDevelopment, Action: ValidationActionWarn}
+	require.Equal(t, &ChiValidation{Profile: ValidationProfileDevelopment, Action: ValidationActionDeny},
+		validation.MergeFrom(&ChiValidation{Action: ValidationActionDeny}, MergeTypeOverrideByNonEmptyValues))
+}
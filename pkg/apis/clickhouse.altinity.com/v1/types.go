@@ -74,19 +74,39 @@ type ClickHouseOperatorConfiguration struct {
 	Status          string         `json:"status" yaml:"status"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClickHouseOperation defines maintenance operation to be executed by the operator over hosts of ClickHouseInstallation
+type ClickHouseOperation struct {
+	meta.TypeMeta   `json:",inline"            yaml:",inline"`
+	meta.ObjectMeta `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Spec            OperationSpec    `json:"spec"             yaml:"spec"`
+	Status          *OperationStatus `json:"status,omitempty" yaml:"status,omitempty"`
+}
+
 // ChiSpec defines spec section of ClickHouseInstallation resource
 type ChiSpec struct {
-	TaskID                 *string          `json:"taskID,omitempty"                 yaml:"taskID,omitempty"`
-	Stop                   *StringBool      `json:"stop,omitempty"                   yaml:"stop,omitempty"`
-	Restart                string           `json:"restart,omitempty"                yaml:"restart,omitempty"`
-	Troubleshoot           *StringBool      `json:"troubleshoot,omitempty"           yaml:"troubleshoot,omitempty"`
-	NamespaceDomainPattern string           `json:"namespaceDomainPattern,omitempty" yaml:"namespaceDomainPattern,omitempty"`
-	Templating             *ChiTemplating   `json:"templating,omitempty"             yaml:"templating,omitempty"`
-	Reconciling            *ChiReconciling  `json:"reconciling,omitempty"            yaml:"reconciling,omitempty"`
-	Defaults               *ChiDefaults     `json:"defaults,omitempty"               yaml:"defaults,omitempty"`
-	Configuration          *Configuration   `json:"configuration,omitempty"          yaml:"configuration,omitempty"`
-	Templates              *ChiTemplates    `json:"templates,omitempty"              yaml:"templates,omitempty"`
-	UseTemplates           []ChiUseTemplate `json:"useTemplates,omitempty"           yaml:"useTemplates,omitempty"`
+	TaskID                 *string                 `json:"taskID,omitempty"                 yaml:"taskID,omitempty"`
+	Stop                   *StringBool             `json:"stop,omitempty"                   yaml:"stop,omitempty"`
+	Restart                string                  `json:"restart,omitempty"                yaml:"restart,omitempty"`
+	Troubleshoot           *StringBool             `json:"troubleshoot,omitempty"           yaml:"troubleshoot,omitempty"`
+	NamespaceDomainPattern string                  `json:"namespaceDomainPattern,omitempty" yaml:"namespaceDomainPattern,omitempty"`
+	Templating             *ChiTemplating          `json:"templating,omitempty"             yaml:"templating,omitempty"`
+	Reconciling            *ChiReconciling         `json:"reconciling,omitempty"            yaml:"reconciling,omitempty"`
+	Defaults               *ChiDefaults            `json:"defaults,omitempty"               yaml:"defaults,omitempty"`
+	Configuration          *Configuration          `json:"configuration,omitempty"          yaml:"configuration,omitempty"`
+	Templates              *ChiTemplates           `json:"templates,omitempty"              yaml:"templates,omitempty"`
+	UseTemplates           []ChiUseTemplate        `json:"useTemplates,omitempty"           yaml:"useTemplates,omitempty"`
+	Preset                 string                  `json:"preset,omitempty"                 yaml:"preset,omitempty"`
+	UpgradeVerification    *ChiUpgradeVerification `json:"upgradeVerification,omitempty"    yaml:"upgradeVerification,omitempty"`
+	BlueGreen              *ChiBlueGreen           `json:"blueGreen,omitempty"              yaml:"blueGreen,omitempty"`
+	Maintenance            *ChiMaintenance         `json:"maintenance,omitempty"            yaml:"maintenance,omitempty"`
+	HostMacros             *ChiHostMacros          `json:"hostMacros,omitempty"             yaml:"hostMacros,omitempty"`
+	CrossRegion            *ChiCrossRegion         `json:"crossRegion,omitempty"            yaml:"crossRegion,omitempty"`
+	Validation             *ChiValidation          `json:"validation,omitempty"             yaml:"validation,omitempty"`
+	Connection             *ChiConnection          `json:"connection,omitempty"             yaml:"connection,omitempty"`
+	ServiceBinding         *ChiServiceBinding      `json:"serviceBinding,omitempty"         yaml:"serviceBinding,omitempty"`
 }
 
 // ChiUseTemplate defines UseTemplate section of ClickHouseInstallation resource
@@ -437,6 +457,16 @@ type ChiReconciling struct {
 	ConfigMapPropagationTimeout int `json:"configMapPropagationTimeout,omitempty" yaml:"configMapPropagationTimeout,omitempty"`
 	// Cleanup specifies cleanup behavior
 	Cleanup *ChiCleanup `json:"cleanup,omitempty" yaml:"cleanup,omitempty"`
+	// StatefulSet specifies StatefulSet reconcile timeouts, overriding operator's config
+	StatefulSet *ChiReconcilingStatefulSet `json:"statefulSet,omitempty" yaml:"statefulSet,omitempty"`
+	// Host specifies host reconcile wait behavior, overriding operator's config
+	Host *ChiReconcilingHost `json:"host,omitempty" yaml:"host,omitempty"`
+	// Runtime specifies reconcile concurrency, overriding operator's config
+	Runtime *ChiReconcilingRuntime `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	// Scope restricts reconcile pass to specified clusters and shards
+	Scope *ChiReconcilingScope `json:"scope,omitempty" yaml:"scope,omitempty"`
+	// Rollout specifies staged rollout of host changes
+	Rollout *ChiReconcilingRollout `json:"rollout,omitempty" yaml:"rollout,omitempty"`
 }
 
 // NewChiReconciling creates new reconciling
@@ -474,6 +504,11 @@ func (t *ChiReconciling) MergeFrom(from *ChiReconciling, _type MergeType) *ChiRe
 	}
 
 	t.Cleanup = t.Cleanup.MergeFrom(from.Cleanup, _type)
+	t.StatefulSet = t.StatefulSet.MergeFrom(from.StatefulSet, _type)
+	t.Host = t.Host.MergeFrom(from.Host, _type)
+	t.Runtime = t.Runtime.MergeFrom(from.Runtime, _type)
+	t.Scope = t.Scope.MergeFrom(from.Scope, _type)
+	t.Rollout = t.Rollout.MergeFrom(from.Rollout, _type)
 
 	return t
 }
@@ -554,6 +589,46 @@ func (t *ChiReconciling) GetCleanup() *ChiCleanup {
 	return t.Cleanup
 }
 
+// GetStatefulSet gets StatefulSet reconcile tunables
+func (t *ChiReconciling) GetStatefulSet() *ChiReconcilingStatefulSet {
+	if t == nil {
+		return nil
+	}
+	return t.StatefulSet
+}
+
+// GetHost gets host reconcile tunables
+func (t *ChiReconciling) GetHost() *ChiReconcilingHost {
+	if t == nil {
+		return nil
+	}
+	return t.Host
+}
+
+// GetRuntime gets reconcile concurrency tunables
+func (t *ChiReconciling) GetRuntime() *ChiReconcilingRuntime {
+	if t == nil {
+		return nil
+	}
+	return t.Runtime
+}
+
+// GetScope gets reconcile scope
+func (t *ChiReconciling) GetScope() *ChiReconcilingScope {
+	if t == nil {
+		return nil
+	}
+	return t.Scope
+}
+
+// GetRollout gets staged rollout of host changes
+func (t *ChiReconciling) GetRollout() *ChiReconcilingRollout {
+	if t == nil {
+		return nil
+	}
+	return t.Rollout
+}
+
 // ChiTemplateNames defines references to .spec.templates to be used on current level of cluster
 type ChiTemplateNames struct {
 	HostTemplate            string `json:"hostTemplate,omitempty"            yaml:"hostTemplate,omitempty"`
@@ -580,6 +655,9 @@ type ChiShard struct {
 	Files               *Settings         `json:"files,omitempty"               yaml:"files,omitempty"`
 	Templates           *ChiTemplateNames `json:"templates,omitempty"           yaml:"templates,omitempty"`
 	ReplicasCount       int               `json:"replicasCount,omitempty"       yaml:"replicasCount,omitempty"`
+	Metadata            *ChiScopeMetadata `json:"metadata,omitempty"            yaml:"metadata,omitempty"`
+	// Zookeeper specifies coordination domain of the shard, overrides cluster-level zookeeper
+	Zookeeper *ChiZookeeperConfig `json:"zookeeper,omitempty" yaml:"zookeeper,omitempty"`
 	// TODO refactor into map[string]ChiHost
 	Hosts []*ChiHost `json:"replicas,omitempty" yaml:"replicas,omitempty"`
 
@@ -600,6 +678,10 @@ type ChiReplica struct {
 	Files       *Settings         `json:"files,omitempty"       yaml:"files,omitempty"`
 	Templates   *ChiTemplateNames `json:"templates,omitempty"   yaml:"templates,omitempty"`
 	ShardsCount int               `json:"shardsCount,omitempty" yaml:"shardsCount,omitempty"`
+	Metadata    *ChiScopeMetadata `json:"metadata,omitempty"    yaml:"metadata,omitempty"`
+	// Zookeeper specifies coordination domain of the replica, overrides cluster-level zookeeper.
+	// Replicas of a shard are required to use the same coordination domain
+	Zookeeper *ChiZookeeperConfig `json:"zookeeper,omitempty" yaml:"zookeeper,omitempty"`
 	// TODO refactor into map[string]ChiHost
 	Hosts []*ChiHost `json:"shards,omitempty" yaml:"shards,omitempty"`
 
@@ -669,8 +751,13 @@ type ChiPodTemplate struct {
 	GenerateName    string               `json:"generateName,omitempty"    yaml:"generateName,omitempty"`
 	Zone            ChiPodTemplateZone   `json:"zone,omitempty"            yaml:"zone,omitempty"`
 	PodDistribution []ChiPodDistribution `json:"podDistribution,omitempty" yaml:"podDistribution,omitempty"`
+	ConfigDirs      *ChiConfigDirs       `json:"configDirs,omitempty"      yaml:"configDirs,omitempty"`
 	ObjectMeta      meta.ObjectMeta      `json:"metadata,omitempty"        yaml:"metadata,omitempty"`
 	Spec            core.PodSpec         `json:"spec,omitempty"            yaml:"spec,omitempty"`
+
+	// Distribution is deprecated in favor of PodDistribution
+	// !!! DEPRECATED !!!
+	Distribution string `json:"distribution,omitempty" yaml:"distribution,omitempty"`
 }
 
 // ChiPodTemplateZone defines pod template zone
@@ -720,6 +807,15 @@ type ClickHouseInstallationTemplateList struct {
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// ClickHouseOperationList defines list of maintenance operations
+type ClickHouseOperationList struct {
+	meta.TypeMeta `json:",inline"  yaml:",inline"`
+	meta.ListMeta `json:"metadata" yaml:"metadata"`
+	Items         []ClickHouseOperation `json:"items" yaml:"items"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 // ClickHouseOperatorConfigurationList defines CHI operator config list
 type ClickHouseOperatorConfigurationList struct {
 	meta.TypeMeta `json:",inline"  yaml:",inline"`
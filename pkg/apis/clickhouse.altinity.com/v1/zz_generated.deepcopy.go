@@ -65,6 +65,106 @@ func (in *CHVersion) DeepCopy() *CHVersion {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiAudit) DeepCopyInto(out *ChiAudit) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(StringBool)
+		**out = **in
+	}
+	if in.Sessions != nil {
+		in, out := &in.Sessions, &out.Sessions
+		*out = new(StringBool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiAudit.
+func (in *ChiAudit) DeepCopy() *ChiAudit {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiAudit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiBindingStatus) DeepCopyInto(out *ChiBindingStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiBindingStatus.
+func (in *ChiBindingStatus) DeepCopy() *ChiBindingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiBindingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiBlueGreen) DeepCopyInto(out *ChiBlueGreen) {
+	*out = *in
+	if in.Active != nil {
+		in, out := &in.Active, &out.Active
+		*out = new(StringBool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiBlueGreen.
+func (in *ChiBlueGreen) DeepCopy() *ChiBlueGreen {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiBlueGreen)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiCapacityMaintenance) DeepCopyInto(out *ChiCapacityMaintenance) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(StringBool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiCapacityMaintenance.
+func (in *ChiCapacityMaintenance) DeepCopy() *ChiCapacityMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiCapacityMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiCapacityStatus) DeepCopyInto(out *ChiCapacityStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiCapacityStatus.
+func (in *ChiCapacityStatus) DeepCopy() *ChiCapacityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiCapacityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChiCleanup) DeepCopyInto(out *ChiCleanup) {
 	*out = *in
@@ -91,6 +191,42 @@ func (in *ChiCleanup) DeepCopy() *ChiCleanup {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiClientCertificates) DeepCopyInto(out *ChiClientCertificates) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(SettingSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiClientCertificates.
+func (in *ChiClientCertificates) DeepCopy() *ChiClientCertificates {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiClientCertificates)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChiClusterAddress) DeepCopyInto(out *ChiClusterAddress) {
 	*out = *in
@@ -143,175 +279,451 @@ func (in *ChiClusterLayout) DeepCopy() *ChiClusterLayout {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiDefaults) DeepCopyInto(out *ChiDefaults) {
+func (in *ChiConfigDirs) DeepCopyInto(out *ChiConfigDirs) {
 	*out = *in
-	if in.ReplicasUseFQDN != nil {
-		in, out := &in.ReplicasUseFQDN, &out.ReplicasUseFQDN
-		*out = new(StringBool)
-		**out = **in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiConfigDirs.
+func (in *ChiConfigDirs) DeepCopy() *ChiConfigDirs {
+	if in == nil {
+		return nil
 	}
-	if in.DistributedDDL != nil {
-		in, out := &in.DistributedDDL, &out.DistributedDDL
-		*out = new(ChiDistributedDDL)
-		**out = **in
+	out := new(ChiConfigDirs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiConnection) DeepCopyInto(out *ChiConnection) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiConnection.
+func (in *ChiConnection) DeepCopy() *ChiConnection {
+	if in == nil {
+		return nil
 	}
-	if in.StorageManagement != nil {
-		in, out := &in.StorageManagement, &out.StorageManagement
-		*out = new(StorageManagement)
-		**out = **in
+	out := new(ChiConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiConnectionStatus) DeepCopyInto(out *ChiConnectionStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiConnectionStatus.
+func (in *ChiConnectionStatus) DeepCopy() *ChiConnectionStatus {
+	if in == nil {
+		return nil
 	}
-	if in.Templates != nil {
-		in, out := &in.Templates, &out.Templates
-		*out = new(ChiTemplateNames)
+	out := new(ChiConnectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiCrossRegion) DeepCopyInto(out *ChiCrossRegion) {
+	*out = *in
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]ChiCrossRegionPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiCrossRegion.
+func (in *ChiCrossRegion) DeepCopy() *ChiCrossRegion {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiCrossRegion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiCrossRegionPeer) DeepCopyInto(out *ChiCrossRegionPeer) {
+	*out = *in
+	if in.Secure != nil {
+		in, out := &in.Secure, &out.Secure
+		*out = new(StringBool)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiDefaults.
-func (in *ChiDefaults) DeepCopy() *ChiDefaults {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiCrossRegionPeer.
+func (in *ChiCrossRegionPeer) DeepCopy() *ChiCrossRegionPeer {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiDefaults)
+	out := new(ChiCrossRegionPeer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiDistributedDDL) DeepCopyInto(out *ChiDistributedDDL) {
+func (in *ChiDedicatedNodes) DeepCopyInto(out *ChiDedicatedNodes) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiDistributedDDL.
-func (in *ChiDistributedDDL) DeepCopy() *ChiDistributedDDL {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiDedicatedNodes.
+func (in *ChiDedicatedNodes) DeepCopy() *ChiDedicatedNodes {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiDistributedDDL)
+	out := new(ChiDedicatedNodes)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiHost) DeepCopyInto(out *ChiHost) {
+func (in *ChiDefaults) DeepCopyInto(out *ChiDefaults) {
 	*out = *in
-	if in.Insecure != nil {
-		in, out := &in.Insecure, &out.Insecure
+	if in.ReplicasUseFQDN != nil {
+		in, out := &in.ReplicasUseFQDN, &out.ReplicasUseFQDN
 		*out = new(StringBool)
 		**out = **in
 	}
-	if in.Secure != nil {
-		in, out := &in.Secure, &out.Secure
-		*out = new(StringBool)
+	if in.DistributedDDL != nil {
+		in, out := &in.DistributedDDL, &out.DistributedDDL
+		*out = new(ChiDistributedDDL)
 		**out = **in
 	}
-	if in.Settings != nil {
-		in, out := &in.Settings, &out.Settings
-		*out = new(Settings)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = new(Settings)
-		(*in).DeepCopyInto(*out)
+	if in.StorageManagement != nil {
+		in, out := &in.StorageManagement, &out.StorageManagement
+		*out = new(StorageManagement)
+		**out = **in
 	}
 	if in.Templates != nil {
 		in, out := &in.Templates, &out.Templates
 		*out = new(ChiTemplateNames)
 		**out = **in
 	}
-	out.Address = in.Address
-	out.Config = in.Config
-	if in.Version != nil {
-		in, out := &in.Version, &out.Version
-		*out = new(CHVersion)
-		**out = **in
+	if in.Scheduling != nil {
+		in, out := &in.Scheduling, &out.Scheduling
+		*out = new(ChiScheduling)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.reconcileAttributes != nil {
-		in, out := &in.reconcileAttributes, &out.reconcileAttributes
-		*out = new(ChiHostReconcileAttributes)
-		**out = **in
+	if in.System != nil {
+		in, out := &in.System, &out.System
+		*out = new(ChiSystemTuning)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.CurStatefulSet != nil {
-		in, out := &in.CurStatefulSet, &out.CurStatefulSet
-		*out = new(appsv1.StatefulSet)
+	if in.Routing != nil {
+		in, out := &in.Routing, &out.Routing
+		*out = new(ChiRouting)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.DesiredStatefulSet != nil {
-		in, out := &in.DesiredStatefulSet, &out.DesiredStatefulSet
-		*out = new(appsv1.StatefulSet)
+	if in.DistributeHostPorts != nil {
+		in, out := &in.DistributeHostPorts, &out.DistributeHostPorts
+		*out = new(StringBool)
+		**out = **in
+	}
+	if in.ReadinessGate != nil {
+		in, out := &in.ReadinessGate, &out.ReadinessGate
+		*out = new(StringBool)
+		**out = **in
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(ChiReadiness)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.CHI != nil {
-		in, out := &in.CHI, &out.CHI
-		*out = new(ClickHouseInstallation)
+	if in.MemoryManagement != nil {
+		in, out := &in.MemoryManagement, &out.MemoryManagement
+		*out = new(ChiMemoryManagement)
 		(*in).DeepCopyInto(*out)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiHost.
-func (in *ChiHost) DeepCopy() *ChiHost {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiDefaults.
+func (in *ChiDefaults) DeepCopy() *ChiDefaults {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiHost)
+	out := new(ChiDefaults)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiHostAddress) DeepCopyInto(out *ChiHostAddress) {
+func (in *ChiDiskUsageMaintenance) DeepCopyInto(out *ChiDiskUsageMaintenance) {
 	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(Settings)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiHostAddress.
-func (in *ChiHostAddress) DeepCopy() *ChiHostAddress {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiDiskUsageMaintenance.
+func (in *ChiDiskUsageMaintenance) DeepCopy() *ChiDiskUsageMaintenance {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiHostAddress)
+	out := new(ChiDiskUsageMaintenance)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiHostConfig) DeepCopyInto(out *ChiHostConfig) {
+func (in *ChiDistributedDDL) DeepCopyInto(out *ChiDistributedDDL) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiHostConfig.
-func (in *ChiHostConfig) DeepCopy() *ChiHostConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiDistributedDDL.
+func (in *ChiDistributedDDL) DeepCopy() *ChiDistributedDDL {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiHostConfig)
+	out := new(ChiDistributedDDL)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiHostReconcileAttributes) DeepCopyInto(out *ChiHostReconcileAttributes) {
+func (in *ChiDrillMaintenance) DeepCopyInto(out *ChiDrillMaintenance) {
 	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(StringBool)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiHostReconcileAttributes.
-func (in *ChiHostReconcileAttributes) DeepCopy() *ChiHostReconcileAttributes {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiDrillMaintenance.
+func (in *ChiDrillMaintenance) DeepCopy() *ChiDrillMaintenance {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiHostReconcileAttributes)
+	out := new(ChiDrillMaintenance)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiHostReconcileAttributesCounters) DeepCopyInto(out *ChiHostReconcileAttributesCounters) {
+func (in *ChiDrillStatus) DeepCopyInto(out *ChiDrillStatus) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiDrillStatus.
+func (in *ChiDrillStatus) DeepCopy() *ChiDrillStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiDrillStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiGuards) DeepCopyInto(out *ChiGuards) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make(map[string]ChiUserGuards, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiGuards.
+func (in *ChiGuards) DeepCopy() *ChiGuards {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiGuards)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiHost) DeepCopyInto(out *ChiHost) {
+	*out = *in
+	if in.Insecure != nil {
+		in, out := &in.Insecure, &out.Insecure
+		*out = new(StringBool)
+		**out = **in
+	}
+	if in.Secure != nil {
+		in, out := &in.Secure, &out.Secure
+		*out = new(StringBool)
+		**out = **in
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(Settings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = new(Settings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = new(ChiTemplateNames)
+		**out = **in
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(ChiScopeMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Address = in.Address
+	out.Config = in.Config
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(CHVersion)
+		**out = **in
+	}
+	if in.reconcileAttributes != nil {
+		in, out := &in.reconcileAttributes, &out.reconcileAttributes
+		*out = new(ChiHostReconcileAttributes)
+		**out = **in
+	}
+	if in.CurStatefulSet != nil {
+		in, out := &in.CurStatefulSet, &out.CurStatefulSet
+		*out = new(appsv1.StatefulSet)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DesiredStatefulSet != nil {
+		in, out := &in.DesiredStatefulSet, &out.DesiredStatefulSet
+		*out = new(appsv1.StatefulSet)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CHI != nil {
+		in, out := &in.CHI, &out.CHI
+		*out = new(ClickHouseInstallation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Macros != nil {
+		in, out := &in.Macros, &out.Macros
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiHost.
+func (in *ChiHost) DeepCopy() *ChiHost {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiHostAddress) DeepCopyInto(out *ChiHostAddress) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiHostAddress.
+func (in *ChiHostAddress) DeepCopy() *ChiHostAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiHostAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiHostConfig) DeepCopyInto(out *ChiHostConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiHostConfig.
+func (in *ChiHostConfig) DeepCopy() *ChiHostConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiHostConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiHostMacros) DeepCopyInto(out *ChiHostMacros) {
+	*out = *in
+	if in.NodeLabels != nil {
+		in, out := &in.NodeLabels, &out.NodeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.NodeAnnotations != nil {
+		in, out := &in.NodeAnnotations, &out.NodeAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.FaultDomainLabels != nil {
+		in, out := &in.FaultDomainLabels, &out.FaultDomainLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiHostMacros.
+func (in *ChiHostMacros) DeepCopy() *ChiHostMacros {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiHostMacros)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiHostReconcileAttributes) DeepCopyInto(out *ChiHostReconcileAttributes) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiHostReconcileAttributes.
+func (in *ChiHostReconcileAttributes) DeepCopy() *ChiHostReconcileAttributes {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiHostReconcileAttributes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiHostReconcileAttributesCounters) DeepCopyInto(out *ChiHostReconcileAttributesCounters) {
 	*out = *in
 	if in.status != nil {
 		in, out := &in.status, &out.status
@@ -356,197 +768,848 @@ func (in *ChiHostTemplate) DeepCopy() *ChiHostTemplate {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiObjectsCleanup) DeepCopyInto(out *ChiObjectsCleanup) {
+func (in *ChiMaintenance) DeepCopyInto(out *ChiMaintenance) {
+	*out = *in
+	if in.DiskUsage != nil {
+		in, out := &in.DiskUsage, &out.DiskUsage
+		*out = new(ChiDiskUsageMaintenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mutations != nil {
+		in, out := &in.Mutations, &out.Mutations
+		*out = new(ChiMutationsMaintenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Spot != nil {
+		in, out := &in.Spot, &out.Spot
+		*out = new(ChiSpotMaintenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Drill != nil {
+		in, out := &in.Drill, &out.Drill
+		*out = new(ChiDrillMaintenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteWrite != nil {
+		in, out := &in.RemoteWrite, &out.RemoteWrite
+		*out = new(ChiRemoteWriteMaintenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(ChiCapacityMaintenance)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiMaintenance.
+func (in *ChiMaintenance) DeepCopy() *ChiMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiMemoryManagement) DeepCopyInto(out *ChiMemoryManagement) {
+	*out = *in
+	if in.Limit != nil {
+		in, out := &in.Limit, &out.Limit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.OOMScoreAdj != nil {
+		in, out := &in.OOMScoreAdj, &out.OOMScoreAdj
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiMemoryManagement.
+func (in *ChiMemoryManagement) DeepCopy() *ChiMemoryManagement {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiMemoryManagement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiMemoryTuning) DeepCopyInto(out *ChiMemoryTuning) {
 	*out = *in
+	if in.HugePages != nil {
+		in, out := &in.HugePages, &out.HugePages
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.MLockExecutable != nil {
+		in, out := &in.MLockExecutable, &out.MLockExecutable
+		*out = new(StringBool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiMemoryTuning.
+func (in *ChiMemoryTuning) DeepCopy() *ChiMemoryTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiMemoryTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiMonitoring) DeepCopyInto(out *ChiMonitoring) {
+	*out = *in
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(StringBool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiMonitoring.
+func (in *ChiMonitoring) DeepCopy() *ChiMonitoring {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiMonitoring)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiMutationsMaintenance) DeepCopyInto(out *ChiMutationsMaintenance) {
+	*out = *in
+	if in.Kill != nil {
+		in, out := &in.Kill, &out.Kill
+		*out = new(StringBool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiMutationsMaintenance.
+func (in *ChiMutationsMaintenance) DeepCopy() *ChiMutationsMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiMutationsMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiObjectsCleanup) DeepCopyInto(out *ChiObjectsCleanup) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiObjectsCleanup.
+func (in *ChiObjectsCleanup) DeepCopy() *ChiObjectsCleanup {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiObjectsCleanup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiPodDistribution) DeepCopyInto(out *ChiPodDistribution) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiPodDistribution.
+func (in *ChiPodDistribution) DeepCopy() *ChiPodDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiPodDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiPodTemplate) DeepCopyInto(out *ChiPodTemplate) {
+	*out = *in
+	in.Zone.DeepCopyInto(&out.Zone)
+	if in.PodDistribution != nil {
+		in, out := &in.PodDistribution, &out.PodDistribution
+		*out = make([]ChiPodDistribution, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigDirs != nil {
+		in, out := &in.ConfigDirs, &out.ConfigDirs
+		*out = new(ChiConfigDirs)
+		**out = **in
+	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiPodTemplate.
+func (in *ChiPodTemplate) DeepCopy() *ChiPodTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiPodTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiPodTemplateZone) DeepCopyInto(out *ChiPodTemplateZone) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiPodTemplateZone.
+func (in *ChiPodTemplateZone) DeepCopy() *ChiPodTemplateZone {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiPodTemplateZone)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiPortDistribution) DeepCopyInto(out *ChiPortDistribution) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiPortDistribution.
+func (in *ChiPortDistribution) DeepCopy() *ChiPortDistribution {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiPortDistribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReadiness) DeepCopyInto(out *ChiReadiness) {
+	*out = *in
+	if in.PasswordSecretKeyRef != nil {
+		in, out := &in.PasswordSecretKeyRef, &out.PasswordSecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReadiness.
+func (in *ChiReadiness) DeepCopy() *ChiReadiness {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReadiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconcileCheckpoint) DeepCopyInto(out *ChiReconcileCheckpoint) {
+	*out = *in
+	if in.HostsCompleted != nil {
+		in, out := &in.HostsCompleted, &out.HostsCompleted
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WindowHosts != nil {
+		in, out := &in.WindowHosts, &out.WindowHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconcileCheckpoint.
+func (in *ChiReconcileCheckpoint) DeepCopy() *ChiReconcileCheckpoint {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconcileCheckpoint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconcileProgress) DeepCopyInto(out *ChiReconcileProgress) {
+	*out = *in
+	if in.HostsInProgress != nil {
+		in, out := &in.HostsInProgress, &out.HostsInProgress
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconcileProgress.
+func (in *ChiReconcileProgress) DeepCopy() *ChiReconcileProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconcileProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconciling) DeepCopyInto(out *ChiReconciling) {
+	*out = *in
+	if in.Cleanup != nil {
+		in, out := &in.Cleanup, &out.Cleanup
+		*out = new(ChiCleanup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StatefulSet != nil {
+		in, out := &in.StatefulSet, &out.StatefulSet
+		*out = new(ChiReconcilingStatefulSet)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Host != nil {
+		in, out := &in.Host, &out.Host
+		*out = new(ChiReconcilingHost)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Runtime != nil {
+		in, out := &in.Runtime, &out.Runtime
+		*out = new(ChiReconcilingRuntime)
+		**out = **in
+	}
+	if in.Scope != nil {
+		in, out := &in.Scope, &out.Scope
+		*out = new(ChiReconcilingScope)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(ChiReconcilingRollout)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconciling.
+func (in *ChiReconciling) DeepCopy() *ChiReconciling {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconciling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconcilingHost) DeepCopyInto(out *ChiReconcilingHost) {
+	*out = *in
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(ChiReconcilingHostWait)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconcilingHost.
+func (in *ChiReconcilingHost) DeepCopy() *ChiReconcilingHost {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconcilingHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconcilingHostWait) DeepCopyInto(out *ChiReconcilingHostWait) {
+	*out = *in
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = new(StringBool)
+		**out = **in
+	}
+	if in.Queries != nil {
+		in, out := &in.Queries, &out.Queries
+		*out = new(StringBool)
+		**out = **in
+	}
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = new(StringBool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconcilingHostWait.
+func (in *ChiReconcilingHostWait) DeepCopy() *ChiReconcilingHostWait {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconcilingHostWait)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconcilingRollout) DeepCopyInto(out *ChiReconcilingRollout) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconcilingRollout.
+func (in *ChiReconcilingRollout) DeepCopy() *ChiReconcilingRollout {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconcilingRollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconcilingRuntime) DeepCopyInto(out *ChiReconcilingRuntime) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconcilingRuntime.
+func (in *ChiReconcilingRuntime) DeepCopy() *ChiReconcilingRuntime {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconcilingRuntime)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconcilingScope) DeepCopyInto(out *ChiReconcilingScope) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconcilingScope.
+func (in *ChiReconcilingScope) DeepCopy() *ChiReconcilingScope {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconcilingScope)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconcilingStatefulSet) DeepCopyInto(out *ChiReconcilingStatefulSet) {
+	*out = *in
+	if in.Update != nil {
+		in, out := &in.Update, &out.Update
+		*out = new(ChiReconcilingStatefulSetUpdate)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconcilingStatefulSet.
+func (in *ChiReconcilingStatefulSet) DeepCopy() *ChiReconcilingStatefulSet {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconcilingStatefulSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReconcilingStatefulSetUpdate) DeepCopyInto(out *ChiReconcilingStatefulSetUpdate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconcilingStatefulSetUpdate.
+func (in *ChiReconcilingStatefulSetUpdate) DeepCopy() *ChiReconcilingStatefulSetUpdate {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiReconcilingStatefulSetUpdate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiRemoteWriteMaintenance) DeepCopyInto(out *ChiRemoteWriteMaintenance) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.BearerTokenSecretKeyRef != nil {
+		in, out := &in.BearerTokenSecretKeyRef, &out.BearerTokenSecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiRemoteWriteMaintenance.
+func (in *ChiRemoteWriteMaintenance) DeepCopy() *ChiRemoteWriteMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiRemoteWriteMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiRemoteCluster) DeepCopyInto(out *ChiRemoteCluster) {
+	*out = *in
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = make([]ChiRemoteShard, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiRemoteCluster.
+func (in *ChiRemoteCluster) DeepCopy() *ChiRemoteCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiRemoteCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiRemoteReplica) DeepCopyInto(out *ChiRemoteReplica) {
+	*out = *in
+	if in.Secure != nil {
+		in, out := &in.Secure, &out.Secure
+		*out = new(StringBool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiRemoteReplica.
+func (in *ChiRemoteReplica) DeepCopy() *ChiRemoteReplica {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiRemoteReplica)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiRemoteShard) DeepCopyInto(out *ChiRemoteShard) {
+	*out = *in
+	if in.InternalReplication != nil {
+		in, out := &in.InternalReplication, &out.InternalReplication
+		*out = new(StringBool)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]ChiRemoteReplica, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiRemoteShard.
+func (in *ChiRemoteShard) DeepCopy() *ChiRemoteShard {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiRemoteShard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiReplica) DeepCopyInto(out *ChiReplica) {
+	*out = *in
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = new(Settings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = new(Settings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = new(ChiTemplateNames)
+		**out = **in
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(ChiScopeMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Zookeeper != nil {
+		in, out := &in.Zookeeper, &out.Zookeeper
+		*out = new(ChiZookeeperConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]*ChiHost, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ChiHost)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	out.Address = in.Address
+	if in.CHI != nil {
+		in, out := &in.CHI, &out.CHI
+		*out = new(ClickHouseInstallation)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiObjectsCleanup.
-func (in *ChiObjectsCleanup) DeepCopy() *ChiObjectsCleanup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReplica.
+func (in *ChiReplica) DeepCopy() *ChiReplica {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiObjectsCleanup)
+	out := new(ChiReplica)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiPodDistribution) DeepCopyInto(out *ChiPodDistribution) {
+func (in *ChiReplicaAddress) DeepCopyInto(out *ChiReplicaAddress) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiPodDistribution.
-func (in *ChiPodDistribution) DeepCopy() *ChiPodDistribution {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReplicaAddress.
+func (in *ChiReplicaAddress) DeepCopy() *ChiReplicaAddress {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiPodDistribution)
+	out := new(ChiReplicaAddress)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiPodTemplate) DeepCopyInto(out *ChiPodTemplate) {
+func (in *ChiReplicatedDatabase) DeepCopyInto(out *ChiReplicatedDatabase) {
 	*out = *in
-	in.Zone.DeepCopyInto(&out.Zone)
-	if in.PodDistribution != nil {
-		in, out := &in.PodDistribution, &out.PodDistribution
-		*out = make([]ChiPodDistribution, len(*in))
-		copy(*out, *in)
-	}
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiPodTemplate.
-func (in *ChiPodTemplate) DeepCopy() *ChiPodTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReplicatedDatabase.
+func (in *ChiReplicatedDatabase) DeepCopy() *ChiReplicatedDatabase {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiPodTemplate)
+	out := new(ChiReplicatedDatabase)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiPodTemplateZone) DeepCopyInto(out *ChiPodTemplateZone) {
+func (in *ChiReplicationThrottling) DeepCopyInto(out *ChiReplicationThrottling) {
 	*out = *in
-	if in.Values != nil {
-		in, out := &in.Values, &out.Values
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiPodTemplateZone.
-func (in *ChiPodTemplateZone) DeepCopy() *ChiPodTemplateZone {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReplicationThrottling.
+func (in *ChiReplicationThrottling) DeepCopy() *ChiReplicationThrottling {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiPodTemplateZone)
+	out := new(ChiReplicationThrottling)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiPortDistribution) DeepCopyInto(out *ChiPortDistribution) {
+func (in *ChiRouting) DeepCopyInto(out *ChiRouting) {
 	*out = *in
+	if in.TopologyAware != nil {
+		in, out := &in.TopologyAware, &out.TopologyAware
+		*out = new(StringBool)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiPortDistribution.
-func (in *ChiPortDistribution) DeepCopy() *ChiPortDistribution {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiRouting.
+func (in *ChiRouting) DeepCopy() *ChiRouting {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiPortDistribution)
+	out := new(ChiRouting)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiReconciling) DeepCopyInto(out *ChiReconciling) {
+func (in *ChiScheduling) DeepCopyInto(out *ChiScheduling) {
 	*out = *in
-	if in.Cleanup != nil {
-		in, out := &in.Cleanup, &out.Cleanup
-		*out = new(ChiCleanup)
-		(*in).DeepCopyInto(*out)
+	if in.ExtendedResources != nil {
+		in, out := &in.ExtendedResources, &out.ExtendedResources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.DedicatedNodes != nil {
+		in, out := &in.DedicatedNodes, &out.DedicatedNodes
+		*out = new(ChiDedicatedNodes)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReconciling.
-func (in *ChiReconciling) DeepCopy() *ChiReconciling {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiScheduling.
+func (in *ChiScheduling) DeepCopy() *ChiScheduling {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiReconciling)
+	out := new(ChiScheduling)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiReplica) DeepCopyInto(out *ChiReplica) {
+func (in *ChiServiceTemplate) DeepCopyInto(out *ChiServiceTemplate) {
 	*out = *in
-	if in.Settings != nil {
-		in, out := &in.Settings, &out.Settings
-		*out = new(Settings)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = new(Settings)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Templates != nil {
-		in, out := &in.Templates, &out.Templates
-		*out = new(ChiTemplateNames)
-		**out = **in
-	}
-	if in.Hosts != nil {
-		in, out := &in.Hosts, &out.Hosts
-		*out = make([]*ChiHost, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(ChiHost)
-				(*in).DeepCopyInto(*out)
-			}
-		}
-	}
-	out.Address = in.Address
-	if in.CHI != nil {
-		in, out := &in.CHI, &out.CHI
-		*out = new(ClickHouseInstallation)
-		(*in).DeepCopyInto(*out)
-	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReplica.
-func (in *ChiReplica) DeepCopy() *ChiReplica {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiServiceTemplate.
+func (in *ChiServiceTemplate) DeepCopy() *ChiServiceTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiReplica)
+	out := new(ChiServiceTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiReplicaAddress) DeepCopyInto(out *ChiReplicaAddress) {
+func (in *ChiScopeMetadata) DeepCopyInto(out *ChiScopeMetadata) {
 	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiReplicaAddress.
-func (in *ChiReplicaAddress) DeepCopy() *ChiReplicaAddress {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiScopeMetadata.
+func (in *ChiScopeMetadata) DeepCopy() *ChiScopeMetadata {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiReplicaAddress)
+	out := new(ChiScopeMetadata)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ChiServiceTemplate) DeepCopyInto(out *ChiServiceTemplate) {
+func (in *ChiServiceBinding) DeepCopyInto(out *ChiServiceBinding) {
 	*out = *in
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiServiceTemplate.
-func (in *ChiServiceTemplate) DeepCopy() *ChiServiceTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiServiceBinding.
+func (in *ChiServiceBinding) DeepCopy() *ChiServiceBinding {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiServiceTemplate)
+	out := new(ChiServiceBinding)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -579,6 +1642,16 @@ func (in *ChiShard) DeepCopyInto(out *ChiShard) {
 		*out = new(ChiTemplateNames)
 		**out = **in
 	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(ChiScopeMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Zookeeper != nil {
+		in, out := &in.Zookeeper, &out.Zookeeper
+		*out = new(ChiZookeeperConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Hosts != nil {
 		in, out := &in.Hosts, &out.Hosts
 		*out = make([]*ChiHost, len(*in))
@@ -673,6 +1746,46 @@ func (in *ChiSpec) DeepCopyInto(out *ChiSpec) {
 		*out = make([]ChiUseTemplate, len(*in))
 		copy(*out, *in)
 	}
+	if in.UpgradeVerification != nil {
+		in, out := &in.UpgradeVerification, &out.UpgradeVerification
+		*out = new(ChiUpgradeVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(ChiBlueGreen)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(ChiMaintenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostMacros != nil {
+		in, out := &in.HostMacros, &out.HostMacros
+		*out = new(ChiHostMacros)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CrossRegion != nil {
+		in, out := &in.CrossRegion, &out.CrossRegion
+		*out = new(ChiCrossRegion)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Validation != nil {
+		in, out := &in.Validation, &out.Validation
+		*out = new(ChiValidation)
+		**out = **in
+	}
+	if in.Connection != nil {
+		in, out := &in.Connection, &out.Connection
+		*out = new(ChiConnection)
+		**out = **in
+	}
+	if in.ServiceBinding != nil {
+		in, out := &in.ServiceBinding, &out.ServiceBinding
+		*out = new(ChiServiceBinding)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -686,6 +1799,74 @@ func (in *ChiSpec) DeepCopy() *ChiSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiSpotMaintenance) DeepCopyInto(out *ChiSpotMaintenance) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(StringBool)
+		**out = **in
+	}
+	if in.TerminationTaints != nil {
+		in, out := &in.TerminationTaints, &out.TerminationTaints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TerminationConditions != nil {
+		in, out := &in.TerminationConditions, &out.TerminationConditions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiSpotMaintenance.
+func (in *ChiSpotMaintenance) DeepCopy() *ChiSpotMaintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiSpotMaintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiStandby) DeepCopyInto(out *ChiStandby) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiStandby.
+func (in *ChiStandby) DeepCopy() *ChiStandby {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiStandby)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiStandbyPromotion) DeepCopyInto(out *ChiStandbyPromotion) {
+	*out = *in
+	if in.RetainedPVCs != nil {
+		in, out := &in.RetainedPVCs, &out.RetainedPVCs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiStandbyPromotion.
+func (in *ChiStandbyPromotion) DeepCopy() *ChiStandbyPromotion {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiStandbyPromotion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChiStatus) DeepCopyInto(out *ChiStatus) {
 	*out = *in
@@ -694,62 +1875,149 @@ func (in *ChiStatus) DeepCopyInto(out *ChiStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.TaskIDsCompleted != nil {
-		in, out := &in.TaskIDsCompleted, &out.TaskIDsCompleted
+	if in.TaskIDsCompleted != nil {
+		in, out := &in.TaskIDsCompleted, &out.TaskIDsCompleted
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PodIPs != nil {
+		in, out := &in.PodIPs, &out.PodIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FQDNs != nil {
+		in, out := &in.FQDNs, &out.FQDNs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Connection != nil {
+		in, out := &in.Connection, &out.Connection
+		*out = new(ChiConnectionStatus)
+		**out = **in
+	}
+	if in.Binding != nil {
+		in, out := &in.Binding, &out.Binding
+		*out = new(ChiBindingStatus)
+		**out = **in
+	}
+	if in.NormalizedCHI != nil {
+		in, out := &in.NormalizedCHI, &out.NormalizedCHI
+		*out = new(ClickHouseInstallation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NormalizedCHICompleted != nil {
+		in, out := &in.NormalizedCHICompleted, &out.NormalizedCHICompleted
+		*out = new(ClickHouseInstallation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostsWithTablesCreated != nil {
+		in, out := &in.HostsWithTablesCreated, &out.HostsWithTablesCreated
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsedTemplates != nil {
+		in, out := &in.UsedTemplates, &out.UsedTemplates
+		*out = make([]*ChiUseTemplate, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ChiUseTemplate)
+				**out = **in
+			}
+		}
+	}
+	if in.UpgradeVerification != nil {
+		in, out := &in.UpgradeVerification, &out.UpgradeVerification
+		*out = new(ChiUpgradeVerificationStatus)
+		**out = **in
+	}
+	if in.DiskPressureHosts != nil {
+		in, out := &in.DiskPressureHosts, &out.DiskPressureHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StuckMutations != nil {
+		in, out := &in.StuckMutations, &out.StuckMutations
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Actions != nil {
-		in, out := &in.Actions, &out.Actions
+	if in.SpotTerminations != nil {
+		in, out := &in.SpotTerminations, &out.SpotTerminations
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Errors != nil {
-		in, out := &in.Errors, &out.Errors
+	if in.UnhealthyHosts != nil {
+		in, out := &in.UnhealthyHosts, &out.UnhealthyHosts
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Pods != nil {
-		in, out := &in.Pods, &out.Pods
+	if in.Drill != nil {
+		in, out := &in.Drill, &out.Drill
+		*out = new(ChiDrillStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.PodIPs != nil {
-		in, out := &in.PodIPs, &out.PodIPs
+	if in.Rejections != nil {
+		in, out := &in.Rejections, &out.Rejections
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.FQDNs != nil {
-		in, out := &in.FQDNs, &out.FQDNs
+	if in.UnmanagedObjects != nil {
+		in, out := &in.UnmanagedObjects, &out.UnmanagedObjects
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.NormalizedCHI != nil {
-		in, out := &in.NormalizedCHI, &out.NormalizedCHI
-		*out = new(ClickHouseInstallation)
+	if in.MissingTemplates != nil {
+		in, out := &in.MissingTemplates, &out.MissingTemplates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(ChiReconcileProgress)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.NormalizedCHICompleted != nil {
-		in, out := &in.NormalizedCHICompleted, &out.NormalizedCHICompleted
-		*out = new(ClickHouseInstallation)
+	if in.Checkpoint != nil {
+		in, out := &in.Checkpoint, &out.Checkpoint
+		*out = new(ChiReconcileCheckpoint)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.HostsWithTablesCreated != nil {
-		in, out := &in.HostsWithTablesCreated, &out.HostsWithTablesCreated
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(ChiCapacityStatus)
+		**out = **in
 	}
-	if in.UsedTemplates != nil {
-		in, out := &in.UsedTemplates, &out.UsedTemplates
-		*out = make([]*ChiUseTemplate, len(*in))
+	if in.StandbyPromotions != nil {
+		in, out := &in.StandbyPromotions, &out.StandbyPromotions
+		*out = make([]ChiStandbyPromotion, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(ChiUseTemplate)
-				**out = **in
-			}
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.UsersFrom != nil {
+		in, out := &in.UsersFrom, &out.UsersFrom
+		*out = new(ChiUsersFromStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	out.mu = in.mu
 	return
 }
@@ -764,6 +2032,39 @@ func (in *ChiStatus) DeepCopy() *ChiStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiSystemTuning) DeepCopyInto(out *ChiSystemTuning) {
+	*out = *in
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Ulimits != nil {
+		in, out := &in.Ulimits, &out.Ulimits
+		*out = new(ChiUlimits)
+		**out = **in
+	}
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		*out = new(ChiMemoryTuning)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiSystemTuning.
+func (in *ChiSystemTuning) DeepCopy() *ChiSystemTuning {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiSystemTuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChiTemplateNames) DeepCopyInto(out *ChiTemplateNames) {
 	*out = *in
@@ -867,6 +2168,59 @@ func (in *ChiTemplating) DeepCopy() *ChiTemplating {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiUlimits) DeepCopyInto(out *ChiUlimits) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiUlimits.
+func (in *ChiUlimits) DeepCopy() *ChiUlimits {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiUlimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiUpgradeVerification) DeepCopyInto(out *ChiUpgradeVerification) {
+	*out = *in
+	if in.Queries != nil {
+		in, out := &in.Queries, &out.Queries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiUpgradeVerification.
+func (in *ChiUpgradeVerification) DeepCopy() *ChiUpgradeVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiUpgradeVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiUpgradeVerificationStatus) DeepCopyInto(out *ChiUpgradeVerificationStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiUpgradeVerificationStatus.
+func (in *ChiUpgradeVerificationStatus) DeepCopy() *ChiUpgradeVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiUpgradeVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChiUseTemplate) DeepCopyInto(out *ChiUseTemplate) {
 	*out = *in
@@ -883,6 +2237,38 @@ func (in *ChiUseTemplate) DeepCopy() *ChiUseTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiUserGuards) DeepCopyInto(out *ChiUserGuards) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiUserGuards.
+func (in *ChiUserGuards) DeepCopy() *ChiUserGuards {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiUserGuards)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiValidation) DeepCopyInto(out *ChiValidation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiValidation.
+func (in *ChiValidation) DeepCopy() *ChiValidation {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiValidation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChiVolumeClaimTemplate) DeepCopyInto(out *ChiVolumeClaimTemplate) {
 	*out = *in
@@ -902,6 +2288,110 @@ func (in *ChiVolumeClaimTemplate) DeepCopy() *ChiVolumeClaimTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiUserAccess) DeepCopyInto(out *ChiUserAccess) {
+	*out = *in
+	if in.IPs != nil {
+		in, out := &in.IPs, &out.IPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HostRegexps != nil {
+		in, out := &in.HostRegexps, &out.HostRegexps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiUserAccess.
+func (in *ChiUserAccess) DeepCopy() *ChiUserAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiUserAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiUserDefaults) DeepCopyInto(out *ChiUserDefaults) {
+	*out = *in
+	if in.DefaultRoles != nil {
+		in, out := &in.DefaultRoles, &out.DefaultRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Access != nil {
+		in, out := &in.Access, &out.Access
+		*out = new(ChiUserAccess)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiUserDefaults.
+func (in *ChiUserDefaults) DeepCopy() *ChiUserDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiUserDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiUsersFromStatus) DeepCopyInto(out *ChiUsersFromStatus) {
+	*out = *in
+	if in.Versions != nil {
+		in, out := &in.Versions, &out.Versions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiUsersFromStatus.
+func (in *ChiUsersFromStatus) DeepCopy() *ChiUsersFromStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiUsersFromStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChiUsersSource) DeepCopyInto(out *ChiUsersSource) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChiUsersSource.
+func (in *ChiUsersSource) DeepCopy() *ChiUsersSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ChiUsersSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChiZookeeperConfig) DeepCopyInto(out *ChiZookeeperConfig) {
 	*out = *in
@@ -941,13 +2431,80 @@ func (in *ChiZookeeperNode) DeepCopy() *ChiZookeeperNode {
 	if in == nil {
 		return nil
 	}
-	out := new(ChiZookeeperNode)
+	out := new(ChiZookeeperNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseInstallation) DeepCopyInto(out *ClickHouseInstallation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(ChiStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Attributes.DeepCopyInto(&out.Attributes)
+	out.statusCreatorMutex = in.statusCreatorMutex
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseInstallation.
+func (in *ClickHouseInstallation) DeepCopy() *ClickHouseInstallation {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseInstallation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClickHouseInstallation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClickHouseInstallationList) DeepCopyInto(out *ClickHouseInstallationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClickHouseInstallation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseInstallationList.
+func (in *ClickHouseInstallationList) DeepCopy() *ClickHouseInstallationList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClickHouseInstallationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClickHouseInstallationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClickHouseInstallation) DeepCopyInto(out *ClickHouseInstallation) {
+func (in *ClickHouseInstallationTemplate) DeepCopyInto(out *ClickHouseInstallationTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -962,18 +2519,18 @@ func (in *ClickHouseInstallation) DeepCopyInto(out *ClickHouseInstallation) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseInstallation.
-func (in *ClickHouseInstallation) DeepCopy() *ClickHouseInstallation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseInstallationTemplate.
+func (in *ClickHouseInstallationTemplate) DeepCopy() *ClickHouseInstallationTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(ClickHouseInstallation)
+	out := new(ClickHouseInstallationTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClickHouseInstallation) DeepCopyObject() runtime.Object {
+func (in *ClickHouseInstallationTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -981,13 +2538,13 @@ func (in *ClickHouseInstallation) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClickHouseInstallationList) DeepCopyInto(out *ClickHouseInstallationList) {
+func (in *ClickHouseInstallationTemplateList) DeepCopyInto(out *ClickHouseInstallationTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClickHouseInstallation, len(*in))
+		*out = make([]ClickHouseInstallationTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -995,18 +2552,18 @@ func (in *ClickHouseInstallationList) DeepCopyInto(out *ClickHouseInstallationLi
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseInstallationList.
-func (in *ClickHouseInstallationList) DeepCopy() *ClickHouseInstallationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseInstallationTemplateList.
+func (in *ClickHouseInstallationTemplateList) DeepCopy() *ClickHouseInstallationTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClickHouseInstallationList)
+	out := new(ClickHouseInstallationTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClickHouseInstallationList) DeepCopyObject() runtime.Object {
+func (in *ClickHouseInstallationTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1014,33 +2571,31 @@ func (in *ClickHouseInstallationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClickHouseInstallationTemplate) DeepCopyInto(out *ClickHouseInstallationTemplate) {
+func (in *ClickHouseOperation) DeepCopyInto(out *ClickHouseOperation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 	if in.Status != nil {
 		in, out := &in.Status, &out.Status
-		*out = new(ChiStatus)
+		*out = new(OperationStatus)
 		(*in).DeepCopyInto(*out)
 	}
-	in.Attributes.DeepCopyInto(&out.Attributes)
-	out.statusCreatorMutex = in.statusCreatorMutex
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseInstallationTemplate.
-func (in *ClickHouseInstallationTemplate) DeepCopy() *ClickHouseInstallationTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseOperation.
+func (in *ClickHouseOperation) DeepCopy() *ClickHouseOperation {
 	if in == nil {
 		return nil
 	}
-	out := new(ClickHouseInstallationTemplate)
+	out := new(ClickHouseOperation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClickHouseInstallationTemplate) DeepCopyObject() runtime.Object {
+func (in *ClickHouseOperation) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1048,13 +2603,13 @@ func (in *ClickHouseInstallationTemplate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClickHouseInstallationTemplateList) DeepCopyInto(out *ClickHouseInstallationTemplateList) {
+func (in *ClickHouseOperationList) DeepCopyInto(out *ClickHouseOperationList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ClickHouseInstallationTemplate, len(*in))
+		*out = make([]ClickHouseOperation, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1062,18 +2617,18 @@ func (in *ClickHouseInstallationTemplateList) DeepCopyInto(out *ClickHouseInstal
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseInstallationTemplateList.
-func (in *ClickHouseInstallationTemplateList) DeepCopy() *ClickHouseInstallationTemplateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClickHouseOperationList.
+func (in *ClickHouseOperationList) DeepCopy() *ClickHouseOperationList {
 	if in == nil {
 		return nil
 	}
-	out := new(ClickHouseInstallationTemplateList)
+	out := new(ClickHouseOperationList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClickHouseInstallationTemplateList) DeepCopyObject() runtime.Object {
+func (in *ClickHouseOperationList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1188,6 +2743,26 @@ func (in *Cluster) DeepCopyInto(out *Cluster) {
 		*out = new(ChiClusterLayout)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ReplicatedDatabases != nil {
+		in, out := &in.ReplicatedDatabases, &out.ReplicatedDatabases
+		*out = make([]ChiReplicatedDatabase, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReplicationThrottling != nil {
+		in, out := &in.ReplicationThrottling, &out.ReplicationThrottling
+		*out = new(ChiReplicationThrottling)
+		**out = **in
+	}
+	if in.Standby != nil {
+		in, out := &in.Standby, &out.Standby
+		*out = new(ChiStandby)
+		**out = **in
+	}
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(ChiScopeMetadata)
+		(*in).DeepCopyInto(*out)
+	}
 	out.Address = in.Address
 	if in.CHI != nil {
 		in, out := &in.CHI, &out.CHI
@@ -1319,6 +2894,55 @@ func (in *Configuration) DeepCopyInto(out *Configuration) {
 		*out = new(Settings)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Guards != nil {
+		in, out := &in.Guards, &out.Guards
+		*out = new(ChiGuards)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertificates != nil {
+		in, out := &in.ClientCertificates, &out.ClientCertificates
+		*out = new(ChiClientCertificates)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(ChiAudit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(ChiMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteClusters != nil {
+		in, out := &in.RemoteClusters, &out.RemoteClusters
+		*out = make([]ChiRemoteCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UsersFrom != nil {
+		in, out := &in.UsersFrom, &out.UsersFrom
+		*out = make([]ChiUsersSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UserDefaults != nil {
+		in, out := &in.UserDefaults, &out.UserDefaults
+		*out = make(map[string]*ChiUserDefaults, len(*in))
+		for key, val := range *in {
+			var outVal *ChiUserDefaults
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(ChiUserDefaults)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	if in.Clusters != nil {
 		in, out := &in.Clusters, &out.Clusters
 		*out = make([]*Cluster, len(*in))
@@ -1441,6 +3065,11 @@ func (in *FillStatusParams) DeepCopyInto(out *FillStatusParams) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Connection != nil {
+		in, out := &in.Connection, &out.Connection
+		*out = new(ChiConnectionStatus)
+		**out = **in
+	}
 	if in.NormalizedCHI != nil {
 		in, out := &in.NormalizedCHI, &out.NormalizedCHI
 		*out = new(ClickHouseInstallation)
@@ -1565,6 +3194,87 @@ func (in *ObjectAddress) DeepCopy() *ObjectAddress {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationHostStatus) DeepCopyInto(out *OperationHostStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationHostStatus.
+func (in *OperationHostStatus) DeepCopy() *OperationHostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationHostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationBackup) DeepCopyInto(out *OperationBackup) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationBackup.
+func (in *OperationBackup) DeepCopy() *OperationBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationSpec) DeepCopyInto(out *OperationSpec) {
+	*out = *in
+	if in.FromBackup != nil {
+		in, out := &in.FromBackup, &out.FromBackup
+		*out = new(OperationBackup)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationSpec.
+func (in *OperationSpec) DeepCopy() *OperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationStatus) DeepCopyInto(out *OperationStatus) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]OperationHostStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStatus.
+func (in *OperationStatus) DeepCopy() *OperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperatorConfig) DeepCopyInto(out *OperatorConfig) {
 	*out = *in
@@ -1650,6 +3360,21 @@ func (in *OperatorConfigAnnotation) DeepCopy() *OperatorConfigAnnotation {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperatorConfigCHI) DeepCopyInto(out *OperatorConfigCHI) {
 	*out = *in
+	if in.Presets != nil {
+		in, out := &in.Presets, &out.Presets
+		*out = make(map[string][]ChiUseTemplate, len(*in))
+		for key, val := range *in {
+			var outVal []ChiUseTemplate
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]ChiUseTemplate, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 	in.Runtime.DeepCopyInto(&out.Runtime)
 	return
 }
@@ -1711,6 +3436,8 @@ func (in *OperatorConfigClickHouse) DeepCopyInto(out *OperatorConfigClickHouse)
 	in.ConfigRestartPolicy.DeepCopyInto(&out.ConfigRestartPolicy)
 	out.Access = in.Access
 	out.Metrics = in.Metrics
+	in.Metrics.Tables.DeepCopyInto(&out.Metrics.Tables)
+	in.Image.DeepCopyInto(&out.Image)
 	return
 }
 
@@ -1724,12 +3451,77 @@ func (in *OperatorConfigClickHouse) DeepCopy() *OperatorConfigClickHouse {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorConfigClickHouseArchitectureImage) DeepCopyInto(out *OperatorConfigClickHouseArchitectureImage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorConfigClickHouseArchitectureImage.
+func (in *OperatorConfigClickHouseArchitectureImage) DeepCopy() *OperatorConfigClickHouseArchitectureImage {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorConfigClickHouseArchitectureImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorConfigClickHouseMetricsTables) DeepCopyInto(out *OperatorConfigClickHouseMetricsTables) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorConfigClickHouseMetricsTables.
+func (in *OperatorConfigClickHouseMetricsTables) DeepCopy() *OperatorConfigClickHouseMetricsTables {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorConfigClickHouseMetricsTables)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorConfigClickHouseImage) DeepCopyInto(out *OperatorConfigClickHouseImage) {
+	*out = *in
+	if in.Architectures != nil {
+		in, out := &in.Architectures, &out.Architectures
+		*out = make([]OperatorConfigClickHouseArchitectureImage, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorConfigClickHouseImage.
+func (in *OperatorConfigClickHouseImage) DeepCopy() *OperatorConfigClickHouseImage {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorConfigClickHouseImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperatorConfigConfig) DeepCopyInto(out *OperatorConfigConfig) {
 	*out = *in
 	in.File.DeepCopyInto(&out.File)
 	in.User.DeepCopyInto(&out.User)
 	out.Network = in.Network
+	if in.Overlays != nil {
+		in, out := &in.Overlays, &out.Overlays
+		*out = make([]OperatorConfigOverlay, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1846,6 +3638,79 @@ func (in *OperatorConfigLabel) DeepCopy() *OperatorConfigLabel {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorConfigOverlay) DeepCopyInto(out *OperatorConfigOverlay) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Files.DeepCopyInto(&out.Files)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorConfigOverlay.
+func (in *OperatorConfigOverlay) DeepCopy() *OperatorConfigOverlay {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorConfigOverlay)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorConfigOverlayFiles) DeepCopyInto(out *OperatorConfigOverlayFiles) {
+	*out = *in
+	if in.Common != nil {
+		in, out := &in.Common, &out.Common
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Host != nil {
+		in, out := &in.Host, &out.Host
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorConfigOverlayFiles.
+func (in *OperatorConfigOverlayFiles) DeepCopy() *OperatorConfigOverlayFiles {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorConfigOverlayFiles)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperatorConfigReconcile) DeepCopyInto(out *OperatorConfigReconcile) {
 	*out = *in
@@ -59,4 +59,7 @@ const (
 const (
 	PortDistributionUnspecified       = "Unspecified"
 	PortDistributionClusterScopeIndex = "ClusterScopeIndex"
+	// Ports are offset by index of the host within the whole ClickHouseInstallation,
+	// so hosts of different clusters sharing a node do not clash
+	PortDistributionCHIScopeIndex = "CHIScopeIndex"
 )
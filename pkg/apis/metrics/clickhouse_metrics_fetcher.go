@@ -103,8 +103,10 @@ const (
 		SELECT
 			database,
 			table,
-			count()          AS mutations,
-			sum(parts_to_do) AS parts_to_do
+			count()                            AS mutations,
+			sum(parts_to_do)                   AS parts_to_do,
+			toUInt64(max(now() - create_time)) AS longest_running,
+			countIf(latest_fail_reason != '')  AS failed
 		FROM system.mutations
 		WHERE is_done = 0
 		GROUP BY database, table
@@ -209,9 +211,9 @@ func (f *ClickHouseMetricsFetcher) getClickHouseQueryMutations(ctx context.Conte
 		ctx,
 		queryMutationsSQL,
 		func(rows *sql.Rows, data *Table) error {
-			var database, table, mutations, partsToDo string
-			if err := rows.Scan(&database, &table, &mutations, &partsToDo); err == nil {
-				*data = append(*data, []string{database, table, mutations, partsToDo})
+			var database, table, mutations, partsToDo, longestRunning, failed string
+			if err := rows.Scan(&database, &table, &mutations, &partsToDo, &longestRunning, &failed); err == nil {
+				*data = append(*data, []string{database, table, mutations, partsToDo, longestRunning, failed})
 			}
 			return nil
 		},
@@ -24,6 +24,7 @@ import (
 	// log "k8s.io/klog"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/altinity/clickhouse-operator/pkg/chop"
 	"github.com/altinity/clickhouse-operator/pkg/util"
 )
 
@@ -87,6 +88,7 @@ func (w *CHIPrometheusWriter) WriteMetrics(data [][]string) {
 // Expected data structure: database, table, partitions, parts, bytes, uncompressed_bytes, rows
 // TODO add namespace handling. It is just skipped for now
 func (w *CHIPrometheusWriter) WriteTableSizes(data [][]string) {
+	data = w.limitTables(data, tablesLayout{database: 0, table: 1, rank: 5, sum: []int{3, 4, 5, 6, 7, 8, 9}})
 	for _, metric := range data {
 		if len(metric) < 2 {
 			continue
@@ -147,6 +149,7 @@ func (w *CHIPrometheusWriter) WriteSystemParts(data [][]string) {
 
 // WriteSystemReplicas writes system replicas
 func (w *CHIPrometheusWriter) WriteSystemReplicas(data [][]string) {
+	data = w.limitTables(data, tablesLayout{database: 0, table: 1, rank: 2, max: []int{2}})
 	for _, metric := range data {
 		labelNames := []string{"database", "table"}
 		labelValues := []string{metric[0], metric[1]}
@@ -159,6 +162,7 @@ func (w *CHIPrometheusWriter) WriteSystemReplicas(data [][]string) {
 
 // WriteMutations writes mutations
 func (w *CHIPrometheusWriter) WriteMutations(data [][]string) {
+	data = w.limitTables(data, tablesLayout{database: 0, table: 1, rank: 2, sum: []int{2, 3, 5}, max: []int{4}})
 	for _, metric := range data {
 		labelNames := []string{"database", "table"}
 		labelValues := []string{metric[0], metric[1]}
@@ -170,6 +174,14 @@ func (w *CHIPrometheusWriter) WriteMutations(data [][]string) {
 			"table_mutations_parts_to_do", "Number of data parts that need to be mutated for the mutation to finish",
 			prometheus.GaugeValue, metric[3],
 			labelNames, labelValues)
+		w.writeSingleMetricToPrometheus(
+			"table_mutations_longest_running_seconds", "Duration in seconds of the longest running mutation for the table",
+			prometheus.GaugeValue, metric[4],
+			labelNames, labelValues)
+		w.writeSingleMetricToPrometheus(
+			"table_mutations_failed", "Number of active mutations for the table, which failed at least once",
+			prometheus.GaugeValue, metric[5],
+			labelNames, labelValues)
 	}
 }
 
@@ -191,6 +203,7 @@ func (w *CHIPrometheusWriter) WriteSystemDisks(data [][]string) {
 
 // WriteDetachedParts writes detached parts
 func (w *CHIPrometheusWriter) WriteDetachedParts(data [][]string) {
+	data = w.limitTables(data, tablesLayout{database: 1, table: 2, rank: 0, sum: []int{0}})
 	for _, metric := range data {
 		labelNames := []string{"database", "table", "disk", "reason"}
 		labelValues := []string{metric[1], metric[2], metric[3], metric[4]}
@@ -221,6 +234,11 @@ func (w *CHIPrometheusWriter) WriteOKFetch(fetchType string) {
 		labelNames, labelValues)
 }
 
+// limitTables limits number of tables reported by per-table metrics as specified by the operator config
+func (w *CHIPrometheusWriter) limitTables(data [][]string, layout tablesLayout) [][]string {
+	return limitTables(data, &chop.Config().ClickHouse.Metrics.Tables, layout)
+}
+
 func (w *CHIPrometheusWriter) getCHILabels() (labels []string) {
 	for label := range w.chi.Labels {
 		labels = append(labels, label)
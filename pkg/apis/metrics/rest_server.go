@@ -41,6 +41,7 @@ func StartMetricsREST(
 
 	http.Handle(metricsPath, promhttp.Handler())
 	http.Handle(chiListPath, exporter)
+	http.Handle(TargetsPath, exporter)
 
 	go http.ListenAndServe(metricsAddress, nil)
 	if metricsAddress != chiListAddress {
@@ -52,6 +53,15 @@ func StartMetricsREST(
 
 // ServeHTTP is an interface method to serve HTTP requests
 func (e *Exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == TargetsPath {
+		if r.Method != "GET" {
+			_, _ = fmt.Fprintf(w, "Sorry, only GET method is supported.")
+			return
+		}
+		e.getTargets(w, r)
+		return
+	}
+
 	if r.URL.Path != "/chi" {
 		http.Error(w, "404 not found.", http.StatusNotFound)
 		return
@@ -0,0 +1,155 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+)
+
+// otherTable is database and table name tables aggregated into are reported with
+const otherTable = "_other"
+
+// tablesLayout specifies columns of per-table data rows
+type tablesLayout struct {
+	// database and table specify columns with database and table names
+	database, table int
+	// rank specifies column tables are ranked by, the largest tables are reported with own labels
+	rank int
+	// sum and max specify value columns aggregated by sum and by max respectively.
+	// All the rest columns are labels
+	sum, max []int
+}
+
+// width returns number of columns the row is expected to have at least
+func (l tablesLayout) width() int {
+	width := 0
+	for _, column := range append(append([]int{l.database, l.table, l.rank}, l.sum...), l.max...) {
+		if column+1 > width {
+			width = column + 1
+		}
+	}
+	return width
+}
+
+// isValue checks whether the column is a value column
+func (l tablesLayout) isValue(column int) bool {
+	for _, c := range append(append([]int{}, l.sum...), l.max...) {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// limitTables limits number of tables reported by per-table data. Tables neither included nor within top-N
+// ranked tables are aggregated into one 'other' table, so cardinality of per-table metrics is capped
+func limitTables(data [][]string, tables *api.OperatorConfigClickHouseMetricsTables, layout tablesLayout) [][]string {
+	if !tables.IsLimited() {
+		return data
+	}
+
+	// Rank tables
+	type tableName struct {
+		database, table string
+	}
+	ranks := make(map[tableName]float64)
+	var names []tableName
+	for _, row := range data {
+		if len(row) < layout.width() {
+			continue
+		}
+		name := tableName{row[layout.database], row[layout.table]}
+		if _, ok := ranks[name]; !ok {
+			names = append(names, name)
+		}
+		rank, _ := strconv.ParseFloat(row[layout.rank], 64)
+		ranks[name] += rank
+	}
+
+	// Select tables reported with own labels
+	reported := make(map[tableName]bool)
+	var candidates []tableName
+	for _, name := range names {
+		if tables.IsIncluded(name.database, name.table) {
+			reported[name] = true
+		} else {
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) <= tables.TopN {
+		return data
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return ranks[candidates[i]] > ranks[candidates[j]]
+	})
+	for _, name := range candidates[:tables.TopN] {
+		reported[name] = true
+	}
+
+	// Aggregate the rest tables, rows of the 'other' table are distinguished by the rest labels
+	var result [][]string
+	aggregated := make(map[string][]string)
+	for _, row := range data {
+		if len(row) < layout.width() {
+			result = append(result, row)
+			continue
+		}
+		if reported[tableName{row[layout.database], row[layout.table]}] {
+			result = append(result, row)
+			continue
+		}
+
+		var key []string
+		for column := range row {
+			switch {
+			case (column == layout.database) || (column == layout.table):
+				key = append(key, otherTable)
+			case !layout.isValue(column):
+				key = append(key, row[column])
+			}
+		}
+		other, ok := aggregated[strings.Join(key, "\x00")]
+		if !ok {
+			other = append([]string{}, row...)
+			other[layout.database], other[layout.table] = otherTable, otherTable
+			aggregated[strings.Join(key, "\x00")] = other
+			result = append(result, other)
+			continue
+		}
+		for _, column := range layout.sum {
+			other[column] = aggregateValues(other[column], row[column], func(a, b float64) float64 { return a + b })
+		}
+		for _, column := range layout.max {
+			other[column] = aggregateValues(other[column], row[column], func(a, b float64) float64 {
+				if a > b {
+					return a
+				}
+				return b
+			})
+		}
+	}
+	return result
+}
+
+// aggregateValues aggregates two numeric values
+func aggregateValues(a, b string, aggregate func(a, b float64) float64) string {
+	x, _ := strconv.ParseFloat(a, 64)
+	y, _ := strconv.ParseFloat(b, 64)
+	return strconv.FormatFloat(aggregate(x, y), 'f', -1, 64)
+}
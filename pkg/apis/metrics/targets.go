@@ -0,0 +1,106 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+const (
+	// TargetsPath specifies path scrape targets are served at
+	TargetsPath = "/targets"
+	// defaultTargetsPort specifies port targets are listed with by default, which is ClickHouse Prometheus endpoint port
+	defaultTargetsPort = 9363
+)
+
+// TargetGroup is a group of scrape targets, as expected by Prometheus file_sd and http_sd discovery
+type TargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// getTargets serves HTTP request to get scrape targets of watched hosts.
+// Port targets are listed with is specified by 'port' query parameter, which is either port number
+// or one of host's ports: 'tcp', 'tls', 'http', 'https'
+func (e *Exporter) getTargets(w http.ResponseWriter, r *http.Request) {
+	port := r.URL.Query().Get("port")
+	groups, err := e.buildTargets(port)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(groups)
+}
+
+// buildTargets builds target group per watched host, labeled with the host's location within CHI
+func (e *Exporter) buildTargets(port string) ([]TargetGroup, error) {
+	if port != "" {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			switch port {
+			case "tcp", "tls", "http", "https":
+			default:
+				return nil, fmt.Errorf("unknown port: %s", port)
+			}
+		}
+	}
+
+	groups := make([]TargetGroup, 0)
+	for _, chi := range e.getWatchedCHIs() {
+		chi.walkHosts(func(chi *WatchedCHI, cluster *WatchedCluster, host *WatchedHost) {
+			targetPort := host.getPort(port)
+			if targetPort == 0 {
+				return
+			}
+			groups = append(groups, TargetGroup{
+				Targets: []string{fmt.Sprintf("%s:%d", host.Hostname, targetPort)},
+				Labels: map[string]string{
+					"namespace": chi.Namespace,
+					"chi":       chi.Name,
+					"cluster":   cluster.Name,
+					"shard":     host.Shard,
+					"replica":   host.Replica,
+					"hostname":  host.Hostname,
+				},
+			})
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Targets[0] < groups[j].Targets[0]
+	})
+	return groups, nil
+}
+
+// getPort gets port of the host by name. Explicitly specified number is used as is
+func (host *WatchedHost) getPort(port string) int32 {
+	switch port {
+	case "":
+		return defaultTargetsPort
+	case "tcp":
+		return host.TCPPort
+	case "tls":
+		return host.TLSPort
+	case "http":
+		return host.HTTPPort
+	case "https":
+		return host.HTTPSPort
+	}
+	number, _ := strconv.ParseUint(port, 10, 16)
+	return int32(number)
+}
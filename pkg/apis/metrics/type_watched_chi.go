@@ -37,6 +37,8 @@ type WatchedCluster struct {
 // WatchedHost specifies watched host
 type WatchedHost struct {
 	Name      string `json:"name,omitempty"      yaml:"name,omitempty"`
+	Shard     string `json:"shard,omitempty"     yaml:"shard,omitempty"`
+	Replica   string `json:"replica,omitempty"   yaml:"replica,omitempty"`
 	Hostname  string `json:"hostname,omitempty"  yaml:"hostname,omitempty"`
 	TCPPort   int32  `json:"tcpPort,omitempty"   yaml:"tcpPort,omitempty"`
 	TLSPort   int32  `json:"tlsPort,omitempty"   yaml:"tlsPort,omitempty"`
@@ -119,6 +121,8 @@ func (host *WatchedHost) readFrom(h *v1.ChiHost) {
 		return
 	}
 	host.Name = h.Name
+	host.Shard = h.Address.ShardName
+	host.Replica = h.Address.ReplicaName
 	host.Hostname = h.Address.FQDN
 	host.TCPPort = h.TCPPort
 	host.TLSPort = h.TLSPort
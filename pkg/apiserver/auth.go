@@ -0,0 +1,92 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	authentication "k8s.io/api/authentication/v1"
+	authorization "k8s.io/api/authorization/v1"
+	kube "k8s.io/client-go/kubernetes"
+
+	clickhouse_altinity_com "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+)
+
+// authenticator authenticates and authorizes API requests against kube-apiserver,
+// so API clients use their regular Kubernetes credentials and RBAC permissions
+type authenticator struct {
+	kubeClient kube.Interface
+}
+
+// newAuthenticator creates new authenticator
+func newAuthenticator(kubeClient kube.Interface) *authenticator {
+	return &authenticator{
+		kubeClient: kubeClient,
+	}
+}
+
+// authenticate authenticates bearer token of the request.
+// Returns user info of the authenticated user, nil in case request is not authenticated
+func (a *authenticator) authenticate(ctx context.Context, r *http.Request) *authentication.UserInfo {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || (token == "") {
+		return nil
+	}
+
+	review, err := a.kubeClient.AuthenticationV1().TokenReviews().Create(ctx, &authentication.TokenReview{
+		Spec: authentication.TokenReviewSpec{
+			Token: token,
+		},
+	}, controller.NewCreateOptions())
+	if (err != nil) || !review.Status.Authenticated {
+		return nil
+	}
+	return &review.Status.User
+}
+
+// authorize checks whether the user is allowed to perform verb over resource of the operator's API group in the namespace.
+// Empty namespace means all namespaces
+func (a *authenticator) authorize(ctx context.Context, user *authentication.UserInfo, verb, resource, namespace string) bool {
+	if user == nil {
+		return false
+	}
+
+	extra := make(map[string]authorization.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorization.ExtraValue(value)
+	}
+
+	review, err := a.kubeClient.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorization.SubjectAccessReview{
+		Spec: authorization.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorization.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      verb,
+				Group:     clickhouse_altinity_com.APIGroupName,
+				Resource:  resource,
+			},
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+		},
+	}, controller.NewCreateOptions())
+	if err != nil {
+		return false
+	}
+	return review.Status.Allowed
+}
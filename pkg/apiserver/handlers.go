@@ -0,0 +1,356 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	authentication "k8s.io/api/authentication/v1"
+	core "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+const (
+	// resourceCHIs specifies resource access to which is checked for all API requests
+	resourceCHIs = "clickhouseinstallations"
+	// resourceOperations specifies resource access to which is checked for operations API requests
+	resourceOperations = "clickhouseoperations"
+)
+
+// handleCHIs serves
+//
+//	/api/v1/chis
+//	/api/v1/chis/{namespace}/{name}
+//	/api/v1/chis/{namespace}/{name}/hosts
+//	/api/v1/chis/{namespace}/{name}/objects
+//	/api/v1/chis/{namespace}/{name}/topology
+func (s *Server) handleCHIs(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, CHIsPath), "/"), "/")
+	switch {
+	case (len(parts) == 1) && (parts[0] == ""):
+		s.listCHIs(w, r)
+	case len(parts) == 2:
+		s.getCHI(w, r, parts[0], parts[1])
+	case (len(parts) == 3) && (parts[2] == "hosts"):
+		s.getHosts(w, r, parts[0], parts[1])
+	case (len(parts) == 3) && (parts[2] == "objects"):
+		s.getObjects(w, r, parts[0], parts[1])
+	case (len(parts) == 3) && (parts[2] == "topology"):
+		s.getTopology(w, r, parts[0], parts[1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// listCHIs writes all CHIs the user is allowed to list
+func (s *Server) listCHIs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	chis, err := s.chopClient.ClickhouseV1().ClickHouseInstallations("").List(ctx, controller.NewListOptions())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	result := []CHI{}
+	access := s.newAccessCache(ctx, r, "list", resourceCHIs)
+	for i := range chis.Items {
+		chi := &chis.Items[i]
+		if !chop.Config().IsWatchedNamespace(chi.Namespace) || !access.allowed(chi.Namespace) {
+			continue
+		}
+		result = append(result, newCHI(chi))
+	}
+	writeJSON(w, result)
+}
+
+// getCHI writes the CHI
+func (s *Server) getCHI(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	chi, ok := s.fetchCHI(w, r, namespace, name)
+	if !ok {
+		return
+	}
+	writeJSON(w, newCHI(chi))
+}
+
+// getHosts writes health of all hosts of the CHI
+func (s *Server) getHosts(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	chi, ok := s.fetchCHI(w, r, namespace, name)
+	if !ok {
+		return
+	}
+
+	status := chi.GetStatus()
+	normalized, err := model.NewNormalizer(s.kubeClient).CreateTemplatedCHI(chi, model.NewNormalizerOptions())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var inProgress []string
+	if progress := status.GetProgress(); progress != nil {
+		inProgress = progress.HostsInProgress
+	}
+	diskPressure := status.GetDiskPressureHosts()
+
+	result := []Host{}
+	normalized.WalkHosts(func(host *api.ChiHost) error {
+		h := Host{
+			Name:         host.GetName(),
+			Cluster:      host.Address.ClusterName,
+			Shard:        host.Address.ShardName,
+			Replica:      host.Address.ReplicaName,
+			FQDN:         model.CreateFQDN(host),
+			Pod:          model.CreatePodName(host),
+			InProgress:   util.InArray(host.GetName(), inProgress),
+			DiskPressure: util.InArray(host.GetName(), diskPressure),
+		}
+		if pod, err := s.kubeClient.CoreV1().Pods(namespace).Get(r.Context(), h.Pod, controller.NewGetOptions()); err == nil {
+			h.PodPhase = string(pod.Status.Phase)
+			h.Ready = isPodReady(pod)
+		}
+		result = append(result, h)
+		return nil
+	})
+	writeJSON(w, result)
+}
+
+// getObjects writes all objects owned by the CHI, be them created by the operator directly or by k8s on behalf of it.
+// Objects are found by labels of the CHI, so objects with labels altered by users are not listed
+func (s *Server) getObjects(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	chi, ok := s.fetchCHI(w, r, namespace, name)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	opts := controller.NewListOptions(model.NewLabeler(chi).GetSelectorCHIScope())
+	result := []Object{}
+	add := func(kind string, objMeta *meta.ObjectMeta) {
+		result = append(result, newObject(kind, objMeta))
+	}
+
+	statefulSets, err := s.kubeClient.AppsV1().StatefulSets(namespace).List(ctx, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for i := range statefulSets.Items {
+		add("StatefulSet", &statefulSets.Items[i].ObjectMeta)
+	}
+
+	pods, err := s.kubeClient.CoreV1().Pods(namespace).List(ctx, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for i := range pods.Items {
+		add("Pod", &pods.Items[i].ObjectMeta)
+	}
+
+	services, err := s.kubeClient.CoreV1().Services(namespace).List(ctx, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for i := range services.Items {
+		add("Service", &services.Items[i].ObjectMeta)
+	}
+
+	configMaps, err := s.kubeClient.CoreV1().ConfigMaps(namespace).List(ctx, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for i := range configMaps.Items {
+		add("ConfigMap", &configMaps.Items[i].ObjectMeta)
+	}
+
+	secrets, err := s.kubeClient.CoreV1().Secrets(namespace).List(ctx, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for i := range secrets.Items {
+		add("Secret", &secrets.Items[i].ObjectMeta)
+	}
+
+	pvcs, err := s.kubeClient.CoreV1().PersistentVolumeClaims(namespace).List(ctx, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for i := range pvcs.Items {
+		add("PersistentVolumeClaim", &pvcs.Items[i].ObjectMeta)
+	}
+
+	pdbs, err := s.kubeClient.PolicyV1().PodDisruptionBudgets(namespace).List(ctx, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	for i := range pdbs.Items {
+		add("PodDisruptionBudget", &pdbs.Items[i].ObjectMeta)
+	}
+
+	writeJSON(w, result)
+}
+
+// handleOperations serves
+//
+//	/api/v1/operations
+//
+// Pending operations are unfinished ClickHouseOperations and reconciles of CHIs in progress
+func (s *Server) handleOperations(w http.ResponseWriter, r *http.Request) {
+	if strings.Trim(strings.TrimPrefix(r.URL.Path, OperationsPath), "/") != "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	ctx := r.Context()
+	result := []Operation{}
+
+	ops, err := s.chopClient.ClickhouseV1().ClickHouseOperations("").List(ctx, controller.NewListOptions())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	opsAccess := s.newAccessCache(ctx, r, "list", resourceOperations)
+	for i := range ops.Items {
+		op := &ops.Items[i]
+		if op.IsFinished() || !chop.Config().IsWatchedNamespace(op.Namespace) || !opsAccess.allowed(op.Namespace) {
+			continue
+		}
+		operation := Operation{
+			Namespace: op.Namespace,
+			Name:      op.Name,
+			CHI:       op.Spec.CHI,
+			Type:      op.Spec.Type,
+		}
+		if op.Status != nil {
+			operation.Status = op.Status.Status
+		}
+		result = append(result, operation)
+	}
+
+	chis, err := s.chopClient.ClickhouseV1().ClickHouseInstallations("").List(ctx, controller.NewListOptions())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	chisAccess := s.newAccessCache(ctx, r, "list", resourceCHIs)
+	for i := range chis.Items {
+		chi := &chis.Items[i]
+		if (chi.GetStatus().GetStatus() != api.StatusInProgress) ||
+			!chop.Config().IsWatchedNamespace(chi.Namespace) ||
+			!chisAccess.allowed(chi.Namespace) {
+			continue
+		}
+		result = append(result, Operation{
+			Namespace: chi.Namespace,
+			Name:      chi.Name,
+			CHI:       chi.Name,
+			Type:      OperationTypeReconcile,
+			Status:    chi.GetStatus().GetStatus(),
+		})
+	}
+
+	writeJSON(w, result)
+}
+
+// fetchCHI fetches the CHI the user is allowed to get.
+// Writes an error and returns false in case the CHI can not be provided
+func (s *Server) fetchCHI(w http.ResponseWriter, r *http.Request, namespace, name string) (*api.ClickHouseInstallation, bool) {
+	ctx := r.Context()
+	if !chop.Config().IsWatchedNamespace(namespace) || !s.newAccessCache(ctx, r, "get", resourceCHIs).allowed(namespace) {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	chi, err := s.chopClient.ClickhouseV1().ClickHouseInstallations(namespace).Get(ctx, name, controller.NewGetOptions())
+	if err != nil {
+		writeError(w, err)
+		return nil, false
+	}
+	return chi, true
+}
+
+// accessCache caches authorization decisions per namespace within one request
+type accessCache struct {
+	ctx      context.Context
+	auth     *authenticator
+	user     *authentication.UserInfo
+	verb     string
+	resource string
+	cache    map[string]bool
+}
+
+// newAccessCache creates new access cache
+func (s *Server) newAccessCache(ctx context.Context, r *http.Request, verb, resource string) *accessCache {
+	return &accessCache{
+		ctx:      ctx,
+		auth:     s.auth,
+		user:     userFromContext(r.Context()),
+		verb:     verb,
+		resource: resource,
+		cache:    make(map[string]bool),
+	}
+}
+
+// allowed checks whether the user of the request is allowed to access resources in the namespace
+func (c *accessCache) allowed(namespace string) bool {
+	if allowed, ok := c.cache[namespace]; ok {
+		return allowed
+	}
+	allowed := c.auth.authorize(c.ctx, c.user, c.verb, c.resource, namespace)
+	c.cache[namespace] = allowed
+	return allowed
+}
+
+// isPodReady checks whether the pod has Ready condition set
+func isPodReady(pod *core.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == core.PodReady {
+			return condition.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
+
+// writeJSON writes value as JSON response
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.V(1).F().Error("unable to write response err: %v", err)
+	}
+}
+
+// writeError writes error response, translating kube-apiserver errors into HTTP status codes
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if apiErrors.IsNotFound(err) {
+		code = http.StatusNotFound
+	}
+	http.Error(w, err.Error(), code)
+}
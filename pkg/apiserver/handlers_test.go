@@ -0,0 +1,107 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+)
+
+func newTestCHI(namespace, name, status string) *api.ClickHouseInstallation {
+	chi := &api.ClickHouseInstallation{
+		ObjectMeta: meta.ObjectMeta{Namespace: namespace, Name: name},
+	}
+	chi.EnsureStatus().Status = status
+	return chi
+}
+
+func Test_ListCHIs(t *testing.T) {
+	s := newTestServer(t,
+		newTestCHI("a", "first", api.StatusCompleted),
+		newTestCHI("a", "second", api.StatusCompleted),
+		newTestCHI("b", "third", api.StatusCompleted),
+	)
+
+	w := serve(s, s.handleCHIs, CHIsPath, "alice-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	var chis []CHI
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chis))
+	require.Len(t, chis, 2)
+	for _, chi := range chis {
+		require.Equal(t, "a", chi.Namespace)
+	}
+
+	w = serve(s, s.handleCHIs, CHIsPath, "bob-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chis))
+	require.Empty(t, chis)
+}
+
+func Test_GetCHI(t *testing.T) {
+	s := newTestServer(t,
+		newTestCHI("a", "first", api.StatusCompleted),
+		newTestCHI("b", "third", api.StatusCompleted),
+	)
+
+	w := serve(s, s.handleCHIs, CHIsPath+"/a/first", "alice-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	var chi CHI
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chi))
+	require.Equal(t, "first", chi.Name)
+	require.Equal(t, api.StatusCompleted, chi.Status)
+
+	// Not allowed CHIs are indistinguishable from missing ones
+	require.Equal(t, http.StatusNotFound, serve(s, s.handleCHIs, CHIsPath+"/b/third", "alice-token").Code)
+	require.Equal(t, http.StatusNotFound, serve(s, s.handleCHIs, CHIsPath+"/a/missing", "alice-token").Code)
+	require.Equal(t, http.StatusNotFound, serve(s, s.handleCHIs, CHIsPath+"/a/first", "bob-token").Code)
+	require.Equal(t, http.StatusNotFound, serve(s, s.handleCHIs, CHIsPath+"/a/first/unknown", "alice-token").Code)
+}
+
+func Test_HandleOperations(t *testing.T) {
+	newOperation := func(namespace, name, status string) *api.ClickHouseOperation {
+		op := &api.ClickHouseOperation{
+			ObjectMeta: meta.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       api.OperationSpec{CHI: "first", Type: api.OperationTypeDetachPartition},
+		}
+		op.EnsureStatus().Status = status
+		return op
+	}
+	s := newTestServer(t,
+		newTestCHI("a", "first", api.StatusInProgress),
+		newTestCHI("a", "second", api.StatusCompleted),
+		newTestCHI("b", "third", api.StatusInProgress),
+		newOperation("a", "pending", api.OperationStatusInProgress),
+		newOperation("a", "done", api.OperationStatusCompleted),
+		newOperation("b", "other", api.OperationStatusInProgress),
+	)
+
+	w := serve(s, s.handleOperations, OperationsPath, "alice-token")
+	require.Equal(t, http.StatusOK, w.Code)
+	var operations []Operation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &operations))
+	require.ElementsMatch(t, []Operation{
+		{Namespace: "a", Name: "pending", CHI: "first", Type: api.OperationTypeDetachPartition, Status: api.OperationStatusInProgress},
+		{Namespace: "a", Name: "first", CHI: "first", Type: OperationTypeReconcile, Status: api.StatusInProgress},
+	}, operations)
+
+	require.Equal(t, http.StatusNotFound, serve(s, s.handleOperations, OperationsPath+"/pending", "alice-token").Code)
+}
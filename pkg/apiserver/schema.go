@@ -0,0 +1,225 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/version"
+)
+
+// Schema is a JSON schema node
+type Schema map[string]interface{}
+
+var (
+	quantityType    = reflect.TypeOf(resource.Quantity{})
+	intOrStringType = reflect.TypeOf(intstr.IntOrString{})
+	timeType        = reflect.TypeOf(meta.Time{})
+	marshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// handleSchema serves
+//
+//	/api/v1/schema/chi
+//
+// JSON schema of the CHI of the running operator version, with defaults applied by the operator
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	if strings.Trim(strings.TrimPrefix(r.URL.Path, SchemaPath), "/") != "chi" {
+		http.NotFound(w, r)
+		return
+	}
+
+	defaults, err := model.NewNormalizer(s.kubeClient).CreateTemplatedCHI(nil, model.NewNormalizerOptions())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, NewCHISchema(defaults))
+}
+
+// NewCHISchema builds JSON schema of the CHI out of the API types.
+// Scalar values of the specified CHI are published as defaults
+func NewCHISchema(defaults *api.ClickHouseInstallation) Schema {
+	spec := newSchemaBuilder().build(reflect.TypeOf(api.ChiSpec{}))
+	if defaults != nil {
+		if values, err := toUnstructured(defaults.Spec); err == nil {
+			applyDefaults(spec, values)
+		}
+	}
+
+	return Schema{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"$id":         "clickhouseinstallation-" + version.Version + ".json",
+		"title":       api.ClickHouseInstallationCRDResourceKind,
+		"description": "ClickHouseInstallation of clickhouse-operator " + version.Version,
+		"type":        "object",
+		"properties": Schema{
+			"apiVersion": Schema{"type": "string", "enum": []string{api.SchemeGroupVersion.String()}},
+			"kind":       Schema{"type": "string", "enum": []string{api.ClickHouseInstallationCRDResourceKind}},
+			"metadata":   Schema{"type": "object"},
+			"spec":       spec,
+		},
+		"required": []string{"apiVersion", "kind"},
+	}
+}
+
+// schemaBuilder builds JSON schema by reflection over Go types
+type schemaBuilder struct {
+	// inProgress specifies types being built, used to break recursive types
+	inProgress map[reflect.Type]bool
+}
+
+// newSchemaBuilder creates new schema builder
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		inProgress: make(map[reflect.Type]bool),
+	}
+}
+
+// build builds schema of the type
+func (b *schemaBuilder) build(t reflect.Type) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case quantityType, intOrStringType:
+		return Schema{"x-kubernetes-int-or-string": true, "anyOf": []Schema{{"type": "integer"}, {"type": "string"}}}
+	case timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	}
+	if t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType) {
+		// Custom-marshaled types, such as Settings, have no structure known in advance
+		return Schema{"x-kubernetes-preserve-unknown-fields": true}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "format": "byte"}
+		}
+		return Schema{"type": "array", "items": b.build(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": b.build(t.Elem())}
+	case reflect.Struct:
+		return b.buildStruct(t)
+	default:
+		return Schema{}
+	}
+}
+
+// buildStruct builds schema of the struct type
+func (b *schemaBuilder) buildStruct(t reflect.Type) Schema {
+	if b.inProgress[t] {
+		return Schema{"type": "object", "x-kubernetes-preserve-unknown-fields": true}
+	}
+	b.inProgress[t] = true
+	defer delete(b.inProgress, t)
+
+	properties := Schema{}
+	b.buildFields(t, properties)
+	return Schema{"type": "object", "properties": properties}
+}
+
+// buildFields builds schemas of fields of the struct type into properties
+func (b *schemaBuilder) buildFields(t reflect.Type, properties Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, inline, skip := jsonFieldName(field)
+		switch {
+		case skip:
+			continue
+		case inline:
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.buildFields(ft, properties)
+			}
+		default:
+			properties[name] = b.build(field.Type)
+		}
+	}
+}
+
+// jsonFieldName gets name of the field as serialized into JSON
+func jsonFieldName(field reflect.StructField) (name string, inline, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" || !field.IsExported() {
+		return "", false, true
+	}
+	name = strings.Split(tag, ",")[0]
+	if name == "" {
+		if field.Anonymous {
+			return "", true, false
+		}
+		name = field.Name
+	}
+	return name, false, false
+}
+
+// toUnstructured converts value into generic JSON representation
+func toUnstructured(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
+
+// applyDefaults sets scalar values as defaults of the corresponding properties of the schema.
+// Arrays are not descended into, since defaults of list items depend on the item
+func applyDefaults(schema Schema, values map[string]interface{}) {
+	properties, ok := schema["properties"].(Schema)
+	if !ok {
+		return
+	}
+	for name, value := range values {
+		property, ok := properties[name].(Schema)
+		if !ok {
+			continue
+		}
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			applyDefaults(property, typed)
+		case []interface{}:
+		default:
+			if _, ok := property["type"]; ok {
+				property["default"] = typed
+			}
+		}
+	}
+}
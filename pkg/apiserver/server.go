@@ -0,0 +1,167 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	authentication "k8s.io/api/authentication/v1"
+	kube "k8s.io/client-go/kubernetes"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	chopClientSet "github.com/altinity/clickhouse-operator/pkg/client/clientset/versioned"
+)
+
+const (
+	// CHIsPath specifies path CHIs inventory is served at
+	CHIsPath = "/api/v1/chis"
+	// OperationsPath specifies path pending operations are served at
+	OperationsPath = "/api/v1/operations"
+	// SchemaPath specifies path JSON schemas of the custom resources are served at
+	SchemaPath = "/api/v1/schema"
+	// shutdownTimeout specifies how long to wait for in-flight requests on shutdown
+	shutdownTimeout = 5 * time.Second
+	// readHeaderTimeout limits time to read request headers
+	readHeaderTimeout = 10 * time.Second
+)
+
+// Server serves read-only API over objects managed by the operator.
+// Requests are authenticated by bearer tokens and authorized by RBAC of kube-apiserver
+type Server struct {
+	endpoint   string
+	tlsCert    string
+	tlsKey     string
+	kubeClient kube.Interface
+	chopClient chopClientSet.Interface
+	auth       *authenticator
+}
+
+// NewServer creates new API server.
+// In case TLS certificate and key files are specified API is served over HTTPS.
+// Otherwise API is served over plain HTTP, which is allowed on loopback address only, since bearer tokens are accepted
+func NewServer(endpoint, tlsCert, tlsKey string, kubeClient kube.Interface, chopClient chopClientSet.Interface) *Server {
+	return &Server{
+		endpoint:   endpoint,
+		tlsCert:    tlsCert,
+		tlsKey:     tlsKey,
+		kubeClient: kubeClient,
+		chopClient: chopClient,
+		auth:       newAuthenticator(kubeClient),
+	}
+}
+
+// Run serves API till context is done
+func (s *Server) Run(ctx context.Context) error {
+	if err := s.checkEndpoint(); err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(CHIsPath, s.handleCHIs)
+	mux.HandleFunc(CHIsPath+"/", s.handleCHIs)
+	mux.HandleFunc(OperationsPath, s.handleOperations)
+	mux.HandleFunc(SchemaPath+"/", s.handleSchema)
+	server := &http.Server{
+		Addr:              s.endpoint,
+		Handler:           s.authenticated(mux),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		if s.isTLS() {
+			log.V(1).F().Info("serving API over HTTPS at %s", s.endpoint)
+			errs <- server.ListenAndServeTLS(s.tlsCert, s.tlsKey)
+		} else {
+			log.V(1).F().Info("serving API over HTTP at %s", s.endpoint)
+			errs <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// isTLS checks whether API is served over HTTPS
+func (s *Server) isTLS() bool {
+	return (s.tlsCert != "") && (s.tlsKey != "")
+}
+
+// checkEndpoint checks whether API can be served at the endpoint.
+// Bearer tokens must not be sent in clear text over the network, so plain HTTP is served on loopback address only
+func (s *Server) checkEndpoint() error {
+	if (s.tlsCert == "") != (s.tlsKey == "") {
+		return fmt.Errorf("both TLS certificate and key files have to be specified")
+	}
+	if s.isTLS() {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(s.endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid API endpoint %s err: %v", s.endpoint, err)
+	}
+	if !isLoopback(host) {
+		return fmt.Errorf("API endpoint %s is not a loopback address, TLS certificate and key files have to be specified to serve it", s.endpoint)
+	}
+	return nil
+}
+
+// isLoopback checks whether host is a loopback address
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return (ip != nil) && ip.IsLoopback()
+}
+
+// userKey is a context key authenticated user is stored by
+type userKey struct{}
+
+// authenticated wraps handler with authentication. Only GET requests are served
+func (s *Server) authenticated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		user := s.auth.authenticate(r.Context(), r)
+		if user == nil {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, user)))
+	})
+}
+
+// userFromContext gets authenticated user from the context
+func userFromContext(ctx context.Context) *authentication.UserInfo {
+	user, _ := ctx.Value(userKey{}).(*authentication.UserInfo)
+	return user
+}
@@ -0,0 +1,185 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	authentication "k8s.io/api/authentication/v1"
+	authorization "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+	kubeTesting "k8s.io/client-go/testing"
+
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	chopFake "github.com/altinity/clickhouse-operator/pkg/client/clientset/versioned/fake"
+)
+
+// testTokens maps bearer tokens known to the fake kube-apiserver to users
+var testTokens = map[string]string{
+	"alice-token": "alice",
+	"bob-token":   "bob",
+}
+
+// testAccess maps users to namespaces they are allowed to access
+var testAccess = map[string]string{
+	"alice": "a",
+}
+
+// newTestServer creates server against fake kube-apiserver, which authenticates testTokens
+// and authorizes access according to testAccess
+func newTestServer(t *testing.T, chopObjects ...runtime.Object) *Server {
+	require.NoError(t, chop.NewOffline(""))
+
+	kubeClient := kubeFake.NewSimpleClientset()
+	kubeClient.PrependReactor("create", "tokenreviews", func(action kubeTesting.Action) (bool, runtime.Object, error) {
+		review := action.(kubeTesting.CreateAction).GetObject().(*authentication.TokenReview).DeepCopy()
+		if review.Spec.Token == "broken-token" {
+			return true, nil, fmt.Errorf("kube-apiserver is unavailable")
+		}
+		if user, ok := testTokens[review.Spec.Token]; ok {
+			review.Status.Authenticated = true
+			review.Status.User = authentication.UserInfo{Username: user}
+		}
+		return true, review, nil
+	})
+	kubeClient.PrependReactor("create", "subjectaccessreviews", func(action kubeTesting.Action) (bool, runtime.Object, error) {
+		review := action.(kubeTesting.CreateAction).GetObject().(*authorization.SubjectAccessReview).DeepCopy()
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = (attributes.Group == "clickhouse.altinity.com") &&
+			(attributes.Namespace != "") &&
+			(testAccess[review.Spec.User] == attributes.Namespace)
+		return true, review, nil
+	})
+
+	return NewServer("127.0.0.1:0", "", "", kubeClient, chopFake.NewSimpleClientset(chopObjects...))
+}
+
+// serve serves GET request of the path with the token through the authenticated handler
+func serve(s *Server, handler http.HandlerFunc, path, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.authenticated(handler).ServeHTTP(w, r)
+	return w
+}
+
+func Test_CheckEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		tlsCert  string
+		tlsKey   string
+		ok       bool
+	}{
+		{endpoint: "127.0.0.1:9444", ok: true},
+		{endpoint: "[::1]:9444", ok: true},
+		{endpoint: "localhost:9444", ok: true},
+		{endpoint: ":9444"},
+		{endpoint: "0.0.0.0:9444"},
+		{endpoint: "10.0.0.1:9444"},
+		{endpoint: "9444"},
+		{endpoint: ":9444", tlsCert: "tls.crt", tlsKey: "tls.key", ok: true},
+		{endpoint: "127.0.0.1:9444", tlsCert: "tls.crt"},
+		{endpoint: "127.0.0.1:9444", tlsKey: "tls.key"},
+	}
+	for _, tt := range tests {
+		s := NewServer(tt.endpoint, tt.tlsCert, tt.tlsKey, nil, nil)
+		err := s.checkEndpoint()
+		if tt.ok {
+			require.NoError(t, err, tt.endpoint)
+		} else {
+			require.Error(t, err, tt.endpoint)
+		}
+	}
+}
+
+func Test_Run_RefusesPlainHTTP(t *testing.T) {
+	s := NewServer(":0", "", "", nil, nil)
+	require.Error(t, s.Run(context.Background()))
+}
+
+func Test_Run_Loopback(t *testing.T) {
+	s := newTestServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("API is not shut down")
+	}
+}
+
+func Test_Authenticated(t *testing.T) {
+	s := newTestServer(t)
+	var user *authentication.UserInfo
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		user = userFromContext(r.Context())
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		code  int
+		user  string
+	}{
+		{name: "no token", code: http.StatusUnauthorized},
+		{name: "unknown token", token: "eve-token", code: http.StatusUnauthorized},
+		{name: "token review failed", token: "broken-token", code: http.StatusUnauthorized},
+		{name: "known token", token: "alice-token", code: http.StatusOK, user: "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user = nil
+			w := serve(s, handler, CHIsPath, tt.token)
+			require.Equal(t, tt.code, w.Code)
+			if tt.user == "" {
+				require.Nil(t, user)
+			} else {
+				require.NotNil(t, user)
+				require.Equal(t, tt.user, user.Username)
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodPost, CHIsPath, nil)
+	r.Header.Set("Authorization", "Bearer alice-token")
+	w := httptest.NewRecorder()
+	s.authenticated(http.HandlerFunc(handler)).ServeHTTP(w, r)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func Test_Authorize(t *testing.T) {
+	s := newTestServer(t)
+	ctx := context.Background()
+	alice := &authentication.UserInfo{Username: "alice"}
+	bob := &authentication.UserInfo{Username: "bob"}
+
+	require.True(t, s.auth.authorize(ctx, alice, "get", resourceCHIs, "a"))
+	require.False(t, s.auth.authorize(ctx, alice, "get", resourceCHIs, "b"))
+	require.False(t, s.auth.authorize(ctx, bob, "get", resourceCHIs, "a"))
+	require.False(t, s.auth.authorize(ctx, nil, "get", resourceCHIs, "a"))
+}
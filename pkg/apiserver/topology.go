@@ -0,0 +1,311 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// TopologySchemaVersion specifies version of the topology document schema.
+// It is bumped on incompatible changes only, new fields may be added within the same version
+const TopologySchemaVersion = "v1"
+
+// Health of topology nodes
+const (
+	// HealthHealthy means all hosts are ready and have no issues reported
+	HealthHealthy = "Healthy"
+	// HealthReconciling means hosts are being reconciled by the operator right now
+	HealthReconciling = "Reconciling"
+	// HealthDegraded means some hosts have issues, but every shard has a serving replica
+	HealthDegraded = "Degraded"
+	// HealthUnhealthy means host is not serving, or shard has no serving replica
+	HealthUnhealthy = "Unhealthy"
+)
+
+// Topology describes replication topology of the CHI, in a shape suitable for rendering dashboards:
+// clusters contain shards, shards contain replicas, each node has its health aggregated from nodes below
+type Topology struct {
+	SchemaVersion string            `json:"schemaVersion"`
+	GeneratedAt   string            `json:"generatedAt"`
+	Namespace     string            `json:"namespace"`
+	Name          string            `json:"name"`
+	Status        string            `json:"status,omitempty"`
+	Endpoint      string            `json:"endpoint,omitempty"`
+	Health        string            `json:"health"`
+	Clusters      []TopologyCluster `json:"clusters"`
+}
+
+// TopologyCluster describes cluster of the CHI
+type TopologyCluster struct {
+	Name   string          `json:"name"`
+	Health string          `json:"health"`
+	Shards []TopologyShard `json:"shards"`
+}
+
+// TopologyShard describes shard of the cluster
+type TopologyShard struct {
+	Name     string            `json:"name"`
+	Weight   *int              `json:"weight,omitempty"`
+	Health   string            `json:"health"`
+	Replicas []TopologyReplica `json:"replicas"`
+}
+
+// TopologyReplica describes replica of the shard, served by a host
+type TopologyReplica struct {
+	Name      string            `json:"name"`
+	Host      string            `json:"host"`
+	Health    string            `json:"health"`
+	Issues    []string          `json:"issues,omitempty"`
+	Ready     bool              `json:"ready"`
+	Pod       string            `json:"pod"`
+	PodPhase  string            `json:"podPhase,omitempty"`
+	Node      string            `json:"node,omitempty"`
+	Image     string            `json:"image,omitempty"`
+	Version   string            `json:"version,omitempty"`
+	FQDN      string            `json:"fqdn"`
+	Endpoints map[string]string `json:"endpoints"`
+}
+
+// Issues of replicas
+const (
+	issuePodNotReady   = "PodNotReady"
+	issueUnhealthy     = "Unhealthy"
+	issueDiskPressure  = "DiskPressure"
+	issueSpotDrained   = "SpotTerminationDrained"
+	issueInReconcile   = "InReconcile"
+	issueStuckMutation = "StuckMutation"
+)
+
+// getTopology writes replication topology of the CHI
+func (s *Server) getTopology(w http.ResponseWriter, r *http.Request, namespace, name string) {
+	chi, ok := s.fetchCHI(w, r, namespace, name)
+	if !ok {
+		return
+	}
+
+	normalized, err := model.NewNormalizer(s.kubeClient).CreateTemplatedCHI(chi, model.NewNormalizerOptions())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	opts := controller.NewListOptions(model.NewLabeler(chi).GetSelectorCHIScope())
+	pods, err := s.kubeClient.CoreV1().Pods(namespace).List(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	podsByName := make(map[string]*core.Pod)
+	for i := range pods.Items {
+		podsByName[pods.Items[i].Name] = &pods.Items[i]
+	}
+
+	writeJSON(w, NewTopology(normalized, chi.GetStatus(), podsByName, time.Now()))
+}
+
+// NewTopology builds topology document of the normalized CHI.
+// Health of hosts is derived from pods, by name, and from issues reported in status of the CHI
+func NewTopology(chi *api.ClickHouseInstallation, status *api.ChiStatus, pods map[string]*core.Pod, now time.Time) Topology {
+	topology := Topology{
+		SchemaVersion: TopologySchemaVersion,
+		GeneratedAt:   now.UTC().Format(time.RFC3339),
+		Namespace:     chi.Namespace,
+		Name:          chi.Name,
+		Status:        status.GetStatus(),
+		Endpoint:      status.GetEndpoint(),
+		Clusters:      []TopologyCluster{},
+	}
+
+	issues := newTopologyIssues(status)
+	var clustersHealth []string
+	chi.WalkClusters(func(cluster *api.Cluster) error {
+		c := TopologyCluster{
+			Name:   cluster.Name,
+			Shards: []TopologyShard{},
+		}
+		var shardsHealth []string
+		cluster.WalkShards(func(_ int, shard *api.ChiShard) error {
+			sh := TopologyShard{
+				Name:     shard.Name,
+				Weight:   shard.Weight,
+				Replicas: []TopologyReplica{},
+			}
+			var replicasHealth []string
+			shard.WalkHosts(func(host *api.ChiHost) error {
+				replica := newTopologyReplica(host, pods[model.CreatePodName(host)], issues)
+				replicasHealth = append(replicasHealth, replica.Health)
+				sh.Replicas = append(sh.Replicas, replica)
+				return nil
+			})
+			sh.Health = aggregateShardHealth(replicasHealth)
+			shardsHealth = append(shardsHealth, sh.Health)
+			c.Shards = append(c.Shards, sh)
+			return nil
+		})
+		c.Health = aggregateHealth(shardsHealth)
+		clustersHealth = append(clustersHealth, c.Health)
+		topology.Clusters = append(topology.Clusters, c)
+		return nil
+	})
+	topology.Health = aggregateHealth(clustersHealth)
+
+	return topology
+}
+
+// topologyIssues specifies hosts having issues reported in status of the CHI, by issue
+type topologyIssues map[string][]string
+
+// newTopologyIssues collects hosts having issues out of status of the CHI
+func newTopologyIssues(status *api.ChiStatus) topologyIssues {
+	issues := topologyIssues{
+		issueUnhealthy:     reportedHosts(status.GetUnhealthyHosts()),
+		issueDiskPressure:  status.GetDiskPressureHosts(),
+		issueSpotDrained:   reportedHosts(status.GetSpotTerminations()),
+		issueStuckMutation: reportedHosts(status.GetStuckMutations()),
+	}
+	if progress := status.GetProgress(); progress != nil {
+		issues[issueInReconcile] = progress.HostsInProgress
+	}
+	return issues
+}
+
+// reportedHosts gets hosts out of status entries, which are reported as "host: details"
+func reportedHosts(entries []string) (hosts []string) {
+	for _, entry := range entries {
+		if host, _, found := strings.Cut(entry, ": "); found {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// has checks whether the host has the issue
+func (i topologyIssues) has(issue, host string) bool {
+	return util.InArray(host, i[issue])
+}
+
+// newTopologyReplica builds replica description out of the host and its pod, if any
+func newTopologyReplica(host *api.ChiHost, pod *core.Pod, issues topologyIssues) TopologyReplica {
+	fqdn := model.CreateFQDN(host)
+	replica := TopologyReplica{
+		Name:      host.Address.ReplicaName,
+		Host:      host.GetName(),
+		Pod:       model.CreatePodName(host),
+		FQDN:      fqdn,
+		Endpoints: newTopologyEndpoints(host, fqdn),
+	}
+	if pod != nil {
+		replica.Ready = isPodReady(pod)
+		replica.PodPhase = string(pod.Status.Phase)
+		replica.Node = pod.Spec.NodeName
+		replica.Image = model.GetPodClickHouseImage(pod)
+		replica.Version = imageTag(replica.Image)
+	}
+
+	if !replica.Ready {
+		replica.Issues = append(replica.Issues, issuePodNotReady)
+	}
+	for _, issue := range []string{issueUnhealthy, issueSpotDrained, issueDiskPressure, issueStuckMutation, issueInReconcile} {
+		if issues.has(issue, replica.Host) {
+			replica.Issues = append(replica.Issues, issue)
+		}
+	}
+
+	switch {
+	case issues.has(issueInReconcile, replica.Host):
+		replica.Health = HealthReconciling
+	case !replica.Ready || issues.has(issueUnhealthy, replica.Host) || issues.has(issueSpotDrained, replica.Host):
+		replica.Health = HealthUnhealthy
+	case len(replica.Issues) > 0:
+		replica.Health = HealthDegraded
+	default:
+		replica.Health = HealthHealthy
+	}
+	return replica
+}
+
+// newTopologyEndpoints builds endpoints of the host by protocol, ports not assigned are skipped
+func newTopologyEndpoints(host *api.ChiHost, fqdn string) map[string]string {
+	endpoints := make(map[string]string)
+	for protocol, port := range map[string]int32{
+		"tcp":             host.TCPPort,
+		"tls":             host.TLSPort,
+		"http":            host.HTTPPort,
+		"https":           host.HTTPSPort,
+		"interserverHTTP": host.InterserverHTTPPort,
+	} {
+		if !api.IsPortUnassigned(port) {
+			endpoints[protocol] = fmt.Sprintf("%s:%d", fqdn, port)
+		}
+	}
+	return endpoints
+}
+
+// imageTag gets tag of the image, which is the version of ClickHouse for official images
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); (i >= 0) && !strings.Contains(image[i:], "/") {
+		return image[i+1:]
+	}
+	return ""
+}
+
+// aggregateShardHealth aggregates health of replicas into health of the shard.
+// Shard is unhealthy only in case none of its replicas is able to serve, otherwise it is degraded at most
+func aggregateShardHealth(replicas []string) string {
+	var health []string
+	serving := 0
+	for _, replica := range replicas {
+		if replica == HealthUnhealthy {
+			replica = HealthDegraded
+		} else {
+			serving++
+		}
+		health = append(health, replica)
+	}
+	if (len(replicas) > 0) && (serving == 0) {
+		return HealthUnhealthy
+	}
+	return aggregateHealth(health)
+}
+
+// aggregateHealth aggregates health of nodes into health of the parent node.
+// Parent is unhealthy in case any of the nodes is, degraded in case any of the nodes is not healthy
+func aggregateHealth(nodes []string) string {
+	result := HealthHealthy
+	for _, health := range nodes {
+		switch health {
+		case HealthUnhealthy:
+			return HealthUnhealthy
+		case HealthDegraded:
+			result = HealthDegraded
+		case HealthReconciling:
+			if result == HealthHealthy {
+				result = HealthReconciling
+			}
+		}
+	}
+	return result
+}
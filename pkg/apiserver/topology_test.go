@@ -0,0 +1,233 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/yaml"
+	"github.com/stretchr/testify/require"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+const topologyTestManifest = `
+metadata:
+  namespace: test
+  name: topo
+spec:
+  configuration:
+    clusters:
+      - name: main
+        layout:
+          shardsCount: 2
+          replicasCount: 2
+`
+
+// newTopologyTestCHI unmarshals and normalizes the CHI
+func newTopologyTestCHI(t *testing.T) *api.ClickHouseInstallation {
+	require.NoError(t, chop.NewOffline(""))
+	chi := &api.ClickHouseInstallation{}
+	require.NoError(t, yaml.Unmarshal([]byte(topologyTestManifest), chi))
+	normalized, err := model.NewNormalizer(kubeFake.NewSimpleClientset()).CreateTemplatedCHI(chi, model.NewNormalizerOptions())
+	require.NoError(t, err)
+	return normalized
+}
+
+// newTopologyTestPods creates pods of hosts of the CHI, ready unless listed as not ready
+func newTopologyTestPods(chi *api.ClickHouseInstallation, notReady ...string) map[string]*core.Pod {
+	pods := make(map[string]*core.Pod)
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		ready := core.ConditionTrue
+		for _, name := range notReady {
+			if name == host.GetName() {
+				ready = core.ConditionFalse
+			}
+		}
+		name := model.CreatePodName(host)
+		pods[name] = &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: chi.Namespace, Name: name},
+			Spec: core.PodSpec{
+				NodeName:   "node-" + host.GetName(),
+				Containers: []core.Container{{Name: "clickhouse", Image: "clickhouse/clickhouse-server:23.8"}},
+			},
+			Status: core.PodStatus{
+				Phase:      core.PodRunning,
+				Conditions: []core.PodCondition{{Type: core.PodReady, Status: ready}},
+			},
+		}
+		return nil
+	})
+	return pods
+}
+
+func Test_NewTopology(t *testing.T) {
+	chi := newTopologyTestCHI(t)
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	tests := []struct {
+		name     string
+		status   *api.ChiStatus
+		notReady []string
+		noPods   bool
+		health   string
+		shards   []string
+		replicas map[string]string
+		issues   map[string][]string
+	}{
+		{
+			name:   "healthy",
+			health: HealthHealthy,
+			shards: []string{HealthHealthy, HealthHealthy},
+		},
+		{
+			name:     "no pods",
+			noPods:   true,
+			health:   HealthUnhealthy,
+			shards:   []string{HealthUnhealthy, HealthUnhealthy},
+			replicas: map[string]string{"0-0": HealthUnhealthy, "1-1": HealthUnhealthy},
+			issues:   map[string][]string{"0-0": {issuePodNotReady}},
+		},
+		{
+			name:     "replica not ready",
+			notReady: []string{"0-1"},
+			health:   HealthDegraded,
+			shards:   []string{HealthDegraded, HealthHealthy},
+			replicas: map[string]string{"0-0": HealthHealthy, "0-1": HealthUnhealthy},
+			issues:   map[string][]string{"0-0": nil, "0-1": {issuePodNotReady}},
+		},
+		{
+			name:     "disk pressure",
+			status:   &api.ChiStatus{DiskPressureHosts: []string{"1-0"}},
+			health:   HealthDegraded,
+			shards:   []string{HealthHealthy, HealthDegraded},
+			replicas: map[string]string{"1-0": HealthDegraded},
+			issues:   map[string][]string{"1-0": {issueDiskPressure}},
+		},
+		{
+			name:     "stuck mutation",
+			status:   &api.ChiStatus{StuckMutations: []string{"1-1: db.table mutation_3.txt"}},
+			health:   HealthDegraded,
+			shards:   []string{HealthHealthy, HealthDegraded},
+			replicas: map[string]string{"1-1": HealthDegraded},
+			issues:   map[string][]string{"1-1": {issueStuckMutation}},
+		},
+		{
+			name: "shard not serving",
+			status: &api.ChiStatus{
+				UnhealthyHosts:   []string{"0-0: pod is failing"},
+				SpotTerminations: []string{"0-1: node node-0-1 terminating"},
+			},
+			health:   HealthUnhealthy,
+			shards:   []string{HealthUnhealthy, HealthHealthy},
+			replicas: map[string]string{"0-0": HealthUnhealthy, "0-1": HealthUnhealthy},
+			issues:   map[string][]string{"0-0": {issueUnhealthy}, "0-1": {issueSpotDrained}},
+		},
+		{
+			name:     "in reconcile",
+			status:   &api.ChiStatus{Progress: &api.ChiReconcileProgress{HostsInProgress: []string{"1-1"}}},
+			notReady: []string{"1-1"},
+			health:   HealthReconciling,
+			shards:   []string{HealthHealthy, HealthReconciling},
+			replicas: map[string]string{"1-1": HealthReconciling},
+			issues:   map[string][]string{"1-1": {issuePodNotReady, issueInReconcile}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pods := newTopologyTestPods(chi, tt.notReady...)
+			if tt.noPods {
+				pods = nil
+			}
+			topology := NewTopology(chi, tt.status, pods, now)
+
+			require.Equal(t, TopologySchemaVersion, topology.SchemaVersion)
+			require.Equal(t, "2023-10-01T10:00:00Z", topology.GeneratedAt)
+			require.Equal(t, tt.health, topology.Health)
+			require.Len(t, topology.Clusters, 1)
+			cluster := topology.Clusters[0]
+			require.Equal(t, "main", cluster.Name)
+			require.Equal(t, tt.health, cluster.Health)
+
+			var shards []string
+			replicas := make(map[string]TopologyReplica)
+			for _, shard := range cluster.Shards {
+				shards = append(shards, shard.Health)
+				require.Len(t, shard.Replicas, 2)
+				for _, replica := range shard.Replicas {
+					replicas[replica.Host] = replica
+				}
+			}
+			require.Equal(t, tt.shards, shards)
+			for host, health := range tt.replicas {
+				require.Equal(t, health, replicas[host].Health, host)
+			}
+			for host, issues := range tt.issues {
+				require.Equal(t, issues, replicas[host].Issues, host)
+			}
+		})
+	}
+}
+
+func Test_NewTopology_Replica(t *testing.T) {
+	chi := newTopologyTestCHI(t)
+	status := &api.ChiStatus{Status: api.StatusCompleted, Endpoint: "clickhouse-topo.test.svc.cluster.local"}
+	topology := NewTopology(chi, status, newTopologyTestPods(chi), time.Now())
+
+	require.Equal(t, "test", topology.Namespace)
+	require.Equal(t, "topo", topology.Name)
+	require.Equal(t, api.StatusCompleted, topology.Status)
+	require.Equal(t, "clickhouse-topo.test.svc.cluster.local", topology.Endpoint)
+
+	replica := topology.Clusters[0].Shards[1].Replicas[0]
+	fqdn := "chi-topo-main-1-0.test.svc.cluster.local"
+	require.Equal(t, TopologyReplica{
+		Name:     "0",
+		Host:     "1-0",
+		Health:   HealthHealthy,
+		Ready:    true,
+		Pod:      "chi-topo-main-1-0-0",
+		PodPhase: string(core.PodRunning),
+		Node:     "node-1-0",
+		Image:    "clickhouse/clickhouse-server:23.8",
+		Version:  "23.8",
+		FQDN:     fqdn,
+		Endpoints: map[string]string{
+			"tcp":             fqdn + ":9000",
+			"http":            fqdn + ":8123",
+			"interserverHTTP": fqdn + ":9009",
+		},
+	}, replica)
+}
+
+func Test_ImageTag(t *testing.T) {
+	tests := map[string]string{
+		"clickhouse/clickhouse-server:23.8":            "23.8",
+		"clickhouse/clickhouse-server":                 "",
+		"registry:5000/clickhouse/clickhouse-server":   "",
+		"registry:5000/clickhouse-server:23.8.2.7":     "23.8.2.7",
+		"clickhouse/clickhouse-server:23.8@sha256:abc": "23.8",
+	}
+	for image, tag := range tests {
+		require.Equal(t, tag, imageTag(image), image)
+	}
+}
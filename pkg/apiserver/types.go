@@ -0,0 +1,102 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiserver
+
+import (
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// CHI describes ClickHouseInstallation managed by the operator
+type CHI struct {
+	Namespace string                    `json:"namespace"`
+	Name      string                    `json:"name"`
+	Status    string                    `json:"status,omitempty"`
+	TaskID    string                    `json:"taskID,omitempty"`
+	Clusters  int                       `json:"clusters"`
+	Shards    int                       `json:"shards"`
+	Hosts     int                       `json:"hosts"`
+	Endpoint  string                    `json:"endpoint,omitempty"`
+	Errors    []string                  `json:"errors,omitempty"`
+	Progress  *api.ChiReconcileProgress `json:"progress,omitempty"`
+}
+
+// newCHI creates CHI description out of the CHI
+func newCHI(chi *api.ClickHouseInstallation) CHI {
+	status := chi.GetStatus()
+	return CHI{
+		Namespace: chi.Namespace,
+		Name:      chi.Name,
+		Status:    status.GetStatus(),
+		TaskID:    status.GetTaskID(),
+		Clusters:  status.GetClustersCount(),
+		Shards:    status.GetShardsCount(),
+		Hosts:     status.GetHostsCount(),
+		Endpoint:  status.GetEndpoint(),
+		Errors:    status.GetErrors(),
+		Progress:  status.GetProgress(),
+	}
+}
+
+// Host describes health of a host of the CHI
+type Host struct {
+	Name         string `json:"name"`
+	Cluster      string `json:"cluster"`
+	Shard        string `json:"shard"`
+	Replica      string `json:"replica"`
+	FQDN         string `json:"fqdn"`
+	Pod          string `json:"pod"`
+	PodPhase     string `json:"podPhase,omitempty"`
+	Ready        bool   `json:"ready"`
+	InProgress   bool   `json:"inProgress"`
+	DiskPressure bool   `json:"diskPressure"`
+}
+
+// Object describes object owned by the CHI
+type Object struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	UID  string `json:"uid"`
+	// Hash specifies version of the object as generated by the operator, empty for objects created by k8s
+	Hash string `json:"hash,omitempty"`
+	// Unmanaged specifies whether the object is excluded from management by users
+	Unmanaged bool `json:"unmanaged,omitempty"`
+}
+
+// newObject creates object description out of object meta
+func newObject(kind string, objMeta *meta.ObjectMeta) Object {
+	hash, _ := model.GetObjectVersion(*objMeta)
+	return Object{
+		Kind:      kind,
+		Name:      objMeta.Name,
+		UID:       string(objMeta.UID),
+		Hash:      hash,
+		Unmanaged: model.IsUnmanaged(objMeta),
+	}
+}
+
+// Operation describes operation pending on the CHI
+type Operation struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	CHI       string `json:"chi"`
+	Type      string `json:"type"`
+	Status    string `json:"status,omitempty"`
+}
+
+// OperationTypeReconcile specifies reconcile of the CHI in progress
+const OperationTypeReconcile = "Reconcile"
@@ -0,0 +1,108 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chop
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"k8s.io/client-go/util/flowcontrol"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+)
+
+// apiWritesWaitReportThreshold specifies how long API write has to wait for the budget in order to be reported
+const apiWritesWaitReportThreshold = time.Second
+
+// apiWritesBudget limits rate of Kubernetes API writes, so large installations are created in batches
+// within the budget instead of tripping client-side throttling of the API client, which is shared with reads
+type apiWritesBudget struct {
+	mutex   sync.RWMutex
+	limiter flowcontrol.RateLimiter
+	// waited accumulates time API writes waited for the budget, in nanoseconds
+	waited int64
+}
+
+// apiBudget is the budget of API writes of the operator, unlimited unless configured
+var apiBudget = &apiWritesBudget{}
+
+// set sets rate of API writes. Zero QPS means unlimited writes
+func (b *apiWritesBudget) set(qps float32, burst int) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if qps <= 0 {
+		b.limiter = nil
+		return
+	}
+	b.limiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+}
+
+// wait waits for the budget to allow the request
+func (b *apiWritesBudget) wait(req *http.Request) error {
+	b.mutex.RLock()
+	limiter := b.limiter
+	b.mutex.RUnlock()
+	if limiter == nil {
+		return nil
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(req.Context()); err != nil {
+		return err
+	}
+	waited := time.Since(start)
+	atomic.AddInt64(&b.waited, int64(waited))
+	if waited >= apiWritesWaitReportThreshold {
+		log.V(1).Info("API write %s %s waited %s for API writes budget", req.Method, req.URL.Path, waited)
+	}
+	return nil
+}
+
+// apiWritesRoundTripper delays API writes to fit into the budget
+type apiWritesRoundTripper struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (rt *apiWritesRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		if err := apiBudget.wait(req); err != nil {
+			return nil, err
+		}
+	}
+	return rt.next.RoundTrip(req)
+}
+
+// wrapAPIWrites wraps transport of API clients, so API writes are limited by the budget
+func wrapAPIWrites(next http.RoundTripper) http.RoundTripper {
+	return &apiWritesRoundTripper{next: next}
+}
+
+// SetupAPIWritesBudget sets up budget of Kubernetes API writes as specified by the operator config
+func (c *CHOp) SetupAPIWritesBudget() {
+	qps, burst := c.Config().GetAPIWritesBudget()
+	apiBudget.set(qps, burst)
+	if qps > 0 {
+		log.V(1).Info("API writes budget applied. QPS: %v burst: %d", qps, burst)
+	}
+}
+
+// APIWritesWaited returns total time API writes waited for the budget since the operator start
+func APIWritesWaited() time.Duration {
+	return time.Duration(atomic.LoadInt64(&apiBudget.waited))
+}
@@ -411,6 +411,12 @@ func (cm *ConfigManager) fetchSecretCredentials() {
 		}
 	}
 
+	// We need to have kube client available in order to fetch the secret
+	if cm.kubeClient == nil {
+		cm.config.ClickHouse.Access.Secret.Runtime.Error = fmt.Sprintf("No Kubernetes access to fetch secret '%s'", name)
+		return
+	}
+
 	log.V(1).Info("Going to search for username/password in the secret '%s/%s'", namespace, name)
 
 	// Sanity check
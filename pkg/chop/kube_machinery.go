@@ -101,6 +101,9 @@ func GetClientset(kubeConfigFile, masterURL string) (
 		kubeConfig.Burst = int(parsedBurst)
 	}
 
+	// API writes are limited by the budget specified by CHOP config
+	kubeConfig.Wrap(wrapAPIWrites)
+
 	kubeClientset, err := kube.NewForConfig(kubeConfig)
 	if err != nil {
 		log.F().Fatal("Unable to initialize kubernetes API clientset: %s", err.Error())
@@ -119,6 +122,28 @@ func GetClientset(kubeConfigFile, masterURL string) (
 	return kubeClientset, apiextensionsClientset, chopClientset
 }
 
+// GetLeaderElectionClientset gets k8s API client to elect the leader with.
+// Lease renewals have to complete within the renew deadline regardless of the operator's load,
+// so the client is neither limited by API writes budget nor shares client-side rate limiter with other clients
+func GetLeaderElectionClientset(kubeConfigFile, masterURL string) *kube.Clientset {
+	kubeConfig, err := getKubeConfig(kubeConfigFile, masterURL)
+	if err != nil {
+		log.F().Fatal("Unable to build kubeconf: %s", err.Error())
+		os.Exit(1)
+	}
+
+	kubeClientset, err := newLeaderElectionClientset(kubeConfig)
+	if err != nil {
+		log.F().Fatal("Unable to initialize kubernetes API clientset of leader election: %s", err.Error())
+	}
+	return kubeClientset
+}
+
+// newLeaderElectionClientset creates k8s API client of leader election out of unwrapped config
+func newLeaderElectionClientset(kubeConfig *kuberest.Config) (*kube.Clientset, error) {
+	return kube.NewForConfig(kuberest.CopyConfig(kubeConfig))
+}
+
 var chop *CHOp
 
 // New creates chop instance
@@ -131,6 +156,18 @@ func New(kubeClient *kube.Clientset, chopClient *chopclientset.Clientset, initCH
 		os.Exit(1)
 	}
 	chop.SetupLog()
+	chop.SetupAPIWritesBudget()
+}
+
+// NewOffline creates chop instance, which has no access to Kubernetes API.
+// Such an instance is used to normalize and validate CHIs outside of Kubernetes, say in CI pipelines
+func NewOffline(initCHOpConfigFilePath string) error {
+	c := NewCHOp(version.Version, version.GitSHA, version.BuiltAt, nil, nil, initCHOpConfigFilePath)
+	if err := c.Init(); err != nil {
+		return err
+	}
+	chop = c
+	return nil
 }
 
 // Get gets global CHOp
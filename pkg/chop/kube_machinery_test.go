@@ -0,0 +1,66 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chop
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	coordination "k8s.io/api/coordination/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+	kuberest "k8s.io/client-go/rest"
+)
+
+func Test_LeaderElectionClientset_NotLimitedByAPIWritesBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind": "Lease", "apiVersion": "coordination.k8s.io/v1", "metadata": {"name": "clickhouse-operator"}}`))
+	}))
+	defer server.Close()
+
+	// Budget allows one write, the next one is to wait for ages
+	apiBudget.set(0.001, 1)
+	defer apiBudget.set(0, 0)
+
+	kubeConfig := &kuberest.Config{Host: server.URL}
+	leaderElectionClient, err := newLeaderElectionClientset(kubeConfig)
+	require.NoError(t, err)
+	kubeConfig.Wrap(wrapAPIWrites)
+	kubeClient, err := kube.NewForConfig(kubeConfig)
+	require.NoError(t, err)
+
+	renew := func(client kube.Interface) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		lease := &coordination.Lease{ObjectMeta: meta.ObjectMeta{Name: "clickhouse-operator"}}
+		_, err := client.CoordinationV1().Leases("test").Update(ctx, lease, meta.UpdateOptions{})
+		return err
+	}
+
+	// Budget is used up by writes of the operator
+	require.NoError(t, renew(kubeClient))
+	require.Error(t, renew(kubeClient))
+
+	// Lease renewals go through regardless of the budget
+	for i := 0; i < 3; i++ {
+		require.NoError(t, renew(leaderElectionClient))
+	}
+}
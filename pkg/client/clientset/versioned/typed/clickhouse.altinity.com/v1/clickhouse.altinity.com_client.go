@@ -30,6 +30,7 @@ type ClickhouseV1Interface interface {
 	RESTClient() rest.Interface
 	ClickHouseInstallationsGetter
 	ClickHouseInstallationTemplatesGetter
+	ClickHouseOperationsGetter
 	ClickHouseOperatorConfigurationsGetter
 }
 
@@ -46,6 +47,10 @@ func (c *ClickhouseV1Client) ClickHouseInstallationTemplates(namespace string) C
 	return newClickHouseInstallationTemplates(c, namespace)
 }
 
+func (c *ClickhouseV1Client) ClickHouseOperations(namespace string) ClickHouseOperationInterface {
+	return newClickHouseOperations(c, namespace)
+}
+
 func (c *ClickhouseV1Client) ClickHouseOperatorConfigurations(namespace string) ClickHouseOperatorConfigurationInterface {
 	return newClickHouseOperatorConfigurations(c, namespace)
 }
@@ -36,6 +36,10 @@ func (c *FakeClickhouseV1) ClickHouseInstallationTemplates(namespace string) v1.
 	return &FakeClickHouseInstallationTemplates{c, namespace}
 }
 
+func (c *FakeClickhouseV1) ClickHouseOperations(namespace string) v1.ClickHouseOperationInterface {
+	return &FakeClickHouseOperations{c, namespace}
+}
+
 func (c *FakeClickhouseV1) ClickHouseOperatorConfigurations(namespace string) v1.ClickHouseOperatorConfigurationInterface {
 	return &FakeClickHouseOperatorConfigurations{c, namespace}
 }
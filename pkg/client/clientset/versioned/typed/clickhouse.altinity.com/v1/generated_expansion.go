@@ -22,4 +22,6 @@ type ClickHouseInstallationExpansion interface{}
 
 type ClickHouseInstallationTemplateExpansion interface{}
 
+type ClickHouseOperationExpansion interface{}
+
 type ClickHouseOperatorConfigurationExpansion interface{}
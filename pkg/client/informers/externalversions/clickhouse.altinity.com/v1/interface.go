@@ -28,6 +28,8 @@ type Interface interface {
 	ClickHouseInstallations() ClickHouseInstallationInformer
 	// ClickHouseInstallationTemplates returns a ClickHouseInstallationTemplateInformer.
 	ClickHouseInstallationTemplates() ClickHouseInstallationTemplateInformer
+	// ClickHouseOperations returns a ClickHouseOperationInformer.
+	ClickHouseOperations() ClickHouseOperationInformer
 	// ClickHouseOperatorConfigurations returns a ClickHouseOperatorConfigurationInformer.
 	ClickHouseOperatorConfigurations() ClickHouseOperatorConfigurationInformer
 }
@@ -53,6 +55,11 @@ func (v *version) ClickHouseInstallationTemplates() ClickHouseInstallationTempla
 	return &clickHouseInstallationTemplateInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ClickHouseOperations returns a ClickHouseOperationInformer.
+func (v *version) ClickHouseOperations() ClickHouseOperationInformer {
+	return &clickHouseOperationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ClickHouseOperatorConfigurations returns a ClickHouseOperatorConfigurationInformer.
 func (v *version) ClickHouseOperatorConfigurations() ClickHouseOperatorConfigurationInformer {
 	return &clickHouseOperatorConfigurationInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
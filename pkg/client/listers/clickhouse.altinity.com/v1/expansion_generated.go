@@ -34,6 +34,14 @@ type ClickHouseInstallationTemplateListerExpansion interface{}
 // ClickHouseInstallationTemplateNamespaceLister.
 type ClickHouseInstallationTemplateNamespaceListerExpansion interface{}
 
+// ClickHouseOperationListerExpansion allows custom methods to be added to
+// ClickHouseOperationLister.
+type ClickHouseOperationListerExpansion interface{}
+
+// ClickHouseOperationNamespaceListerExpansion allows custom methods to be added to
+// ClickHouseOperationNamespaceLister.
+type ClickHouseOperationNamespaceListerExpansion interface{}
+
 // ClickHouseOperatorConfigurationListerExpansion allows custom methods to be added to
 // ClickHouseOperatorConfigurationLister.
 type ClickHouseOperatorConfigurationListerExpansion interface{}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/sanity-io/litter"
@@ -27,6 +28,7 @@ import (
 	core "k8s.io/api/core/v1"
 	apiExtensions "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sLabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	utilRuntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -36,6 +38,7 @@ import (
 	typedCore "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/altinity/queue"
 
@@ -98,6 +101,7 @@ func NewController(
 		podLister:               kubeInformerFactory.Core().V1().Pods().Lister(),
 		podListerSynced:         kubeInformerFactory.Core().V1().Pods().Informer().HasSynced,
 		recorder:                recorder,
+		shutdown:                make(chan struct{}),
 	}
 	controller.initQueues()
 	controller.addEventHandlers(chopInformerFactory, kubeInformerFactory)
@@ -105,9 +109,27 @@ func NewController(
 	return controller
 }
 
+// Controller queues are laid out as system queues, followed by operations queues, followed by maintenance queues,
+// followed by CHI reconcile queues. Operations queues run operations and restarts of hosts
+
+// operationsQueuesIndex specifies index of the first queue operations are run in
+func operationsQueuesIndex() int {
+	return api.DefaultReconcileSystemThreadsNumber
+}
+
+// maintenanceQueuesIndex specifies index of the first queue CHIs are maintained in
+func maintenanceQueuesIndex() int {
+	return operationsQueuesIndex() + api.DefaultReconcileOperationsThreadsNumber
+}
+
+// chiQueuesIndex specifies index of the first queue CHIs are reconciled in
+func chiQueuesIndex() int {
+	return maintenanceQueuesIndex() + api.DefaultReconcileMaintenanceThreadsNumber
+}
+
 // initQueues
 func (c *Controller) initQueues() {
-	queuesNum := chop.Config().Reconcile.Runtime.ReconcileCHIsThreadsNumber + api.DefaultReconcileSystemThreadsNumber
+	queuesNum := chiQueuesIndex() + chop.Config().Reconcile.Runtime.ReconcileCHIsThreadsNumber
 	for i := 0; i < queuesNum; i++ {
 		c.queues = append(
 			c.queues,
@@ -131,6 +153,7 @@ func (c *Controller) addEventHandlersCHI(
 			}
 			log.V(3).M(chi).Info("chiInformer.AddFunc")
 			c.enqueueObject(NewReconcileCHI(reconcileAdd, nil, chi))
+			c.enqueueRestartHostRequest(chi)
 		},
 		UpdateFunc: func(old, new interface{}) {
 			oldChi := old.(*api.ClickHouseInstallation)
@@ -140,6 +163,7 @@ func (c *Controller) addEventHandlersCHI(
 			}
 			log.V(3).M(newChi).Info("chiInformer.UpdateFunc")
 			c.enqueueObject(NewReconcileCHI(reconcileUpdate, oldChi, newChi))
+			c.enqueueRestartHostRequest(newChi)
 		},
 		DeleteFunc: func(obj interface{}) {
 			chi := obj.(*api.ClickHouseInstallation)
@@ -152,6 +176,15 @@ func (c *Controller) addEventHandlersCHI(
 	})
 }
 
+// enqueueRestartHostRequest enqueues restart of a host, in case it is requested by annotation of the CHI.
+// Annotation does not change the spec, so it is not handled by reconcile
+func (c *Controller) enqueueRestartHostRequest(chi *api.ClickHouseInstallation) {
+	if chi.GetAnnotations()[model.AnnotationRestartHost] == "" {
+		return
+	}
+	c.enqueueObject(NewRequestRestartHost(chi.DeepCopy()))
+}
+
 func (c *Controller) addEventHandlersCHIT(
 	chopInformerFactory chopInformers.SharedInformerFactory,
 ) {
@@ -184,6 +217,35 @@ func (c *Controller) addEventHandlersCHIT(
 	})
 }
 
+func (c *Controller) addEventHandlersOperation(
+	chopInformerFactory chopInformers.SharedInformerFactory,
+) {
+	chopInformerFactory.Clickhouse().V1().ClickHouseOperations().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			op := obj.(*api.ClickHouseOperation)
+			if !chop.Config().IsWatchedNamespace(op.Namespace) {
+				return
+			}
+			log.V(3).M(op).Info("operationInformer.AddFunc")
+			if !op.IsFinished() {
+				// Operation is either new or was interrupted
+				c.enqueueObject(NewRunOperation(op.DeepCopy()))
+			}
+		},
+		UpdateFunc: func(old, new interface{}) {
+			newOp := new.(*api.ClickHouseOperation)
+			if !chop.Config().IsWatchedNamespace(newOp.Namespace) {
+				return
+			}
+			log.V(3).M(newOp).Info("operationInformer.UpdateFunc")
+			if newOp.Status == nil {
+				// Status is updated by the operator only, operation without status is requested to run again
+				c.enqueueObject(NewRunOperation(newOp.DeepCopy()))
+			}
+		},
+	})
+}
+
 func (c *Controller) addEventHandlersChopConfig(
 	chopInformerFactory chopInformers.SharedInformerFactory,
 ) {
@@ -446,6 +508,7 @@ func (c *Controller) addEventHandlers(
 	c.addEventHandlersCHI(chopInformerFactory)
 	c.addEventHandlersCHIT(chopInformerFactory)
 	c.addEventHandlersChopConfig(chopInformerFactory)
+	c.addEventHandlersOperation(chopInformerFactory)
 	c.addEventHandlersService(kubeInformerFactory)
 	c.addEventHandlersEndpoint(kubeInformerFactory)
 	c.addEventHandlersConfigMap(kubeInformerFactory)
@@ -458,6 +521,11 @@ func (c *Controller) isTrackedObject(objectMeta *meta.ObjectMeta) bool {
 	return chop.Config().IsWatchedNamespace(objectMeta.Namespace) && model.IsCHOPGeneratedObject(objectMeta)
 }
 
+// SetKeeperReader sets reader of CHKs, status of which pauses disruptive operations over hosts using their ensembles
+func (c *Controller) SetKeeperReader(reader client.Reader) {
+	c.keeperReader = reader
+}
+
 // Run syncs caches, starts workers
 func (c *Controller) Run(ctx context.Context) {
 	defer utilRuntime.HandleCrash()
@@ -499,21 +567,31 @@ func (c *Controller) Run(ctx context.Context) {
 	//
 	// Start threads
 	//
+	var workers sync.WaitGroup
 	workersNum := len(c.queues)
 	log.V(1).F().Info("ClickHouseInstallation controller: starting workers number: %d", workersNum)
 	for i := 0; i < workersNum; i++ {
 		log.V(1).F().Info("ClickHouseInstallation controller: starting worker %d out of %d", i+1, workersNum)
 		sys := false
-		if i < api.DefaultReconcileSystemThreadsNumber {
+		if i < chiQueuesIndex() {
 			sys = true
 		}
 		worker := c.newWorker(c.queues[i], sys)
-		go wait.Until(worker.run, runWorkerPeriod, ctx.Done())
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			wait.Until(worker.run, runWorkerPeriod, ctx.Done())
+		}()
 	}
 	defer log.V(1).F().Info("ClickHouseInstallation controller: shutting down workers")
 
+	// Start periodical maintenance
+	go wait.Until(c.enqueueMaintenance, maintenancePeriod, ctx.Done())
+
 	log.V(1).F().Info("ClickHouseInstallation controller: workers started")
 	<-ctx.Done()
+
+	c.shutDown(&workers)
 }
 
 func prepareCHIAdd(command *ReconcileCHI) bool {
@@ -579,8 +657,8 @@ func (c *Controller) enqueueObject(obj queue.PriorityQueueItem) {
 	enqueue := false
 	switch command := obj.(type) {
 	case *ReconcileCHI:
-		variants := len(c.queues) - api.DefaultReconcileSystemThreadsNumber
-		index = api.DefaultReconcileSystemThreadsNumber + util.HashIntoIntTopped(handle, variants)
+		variants := len(c.queues) - chiQueuesIndex()
+		index = chiQueuesIndex() + util.HashIntoIntTopped(handle, variants)
 		switch command.cmd {
 		case reconcileAdd:
 			enqueue = prepareCHIAdd(command)
@@ -596,6 +674,14 @@ func (c *Controller) enqueueObject(obj queue.PriorityQueueItem) {
 		variants := api.DefaultReconcileSystemThreadsNumber
 		index = util.HashIntoIntTopped(handle, variants)
 		enqueue = true
+	case *MaintainCHI:
+		variants := api.DefaultReconcileMaintenanceThreadsNumber
+		index = maintenanceQueuesIndex() + util.HashIntoIntTopped(handle, variants)
+		enqueue = true
+	case *RunOperation, *RequestRestartHost:
+		variants := api.DefaultReconcileOperationsThreadsNumber
+		index = operationsQueuesIndex() + util.HashIntoIntTopped(handle, variants)
+		enqueue = true
 	}
 	if enqueue {
 		//c.queues[index].AddRateLimited(obj)
@@ -603,6 +689,37 @@ func (c *Controller) enqueueObject(obj queue.PriorityQueueItem) {
 	}
 }
 
+// enqueueMaintenance enqueues maintenance of all CHIs having maintenance policies to run
+func (c *Controller) enqueueMaintenance() {
+	list, err := c.chiLister.List(k8sLabels.Everything())
+	if err != nil {
+		log.V(1).F().Error("FAIL list CHI err: %v", err)
+		return
+	}
+	for _, chi := range list {
+		switch {
+		case
+			chi.Spec.Maintenance.GetDiskUsage().IsEnabled(),
+			chi.Spec.Maintenance.GetMutations().IsEnabled(),
+			chi.Spec.Maintenance.GetSpot().IsEnabled(),
+			chi.Spec.Maintenance.GetDrill().IsEnabled(),
+			chi.Spec.Maintenance.GetRemoteWrite().IsEnabled(),
+			chi.Spec.Maintenance.GetCapacity().IsEnabled(),
+			chi.Spec.Defaults.IsReadinessGateEnabled(),
+			chi.HasStandby(),
+			len(chi.Spec.Configuration.GetUsersFrom()) > 0,
+			chi.Status.GetUsersFrom() != nil,
+			len(chi.Status.GetDiskPressureHosts()) > 0,
+			len(chi.Status.GetStuckMutations()) > 0,
+			len(chi.Status.GetSpotTerminations()) > 0,
+			chi.Status.GetCapacity() != nil,
+			chi.Status.GetCheckpoint().IsPaused(),
+			chi.Status.GetDrill().IsInProgress():
+			c.enqueueObject(NewMaintainCHI(chi.DeepCopy()))
+		}
+	}
+}
+
 // updateWatch
 func (c *Controller) updateWatch(chi *api.ClickHouseInstallation) {
 	watched := metrics.NewWatchedCHI(chi)
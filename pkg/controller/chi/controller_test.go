@@ -0,0 +1,104 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	core "k8s.io/api/core/v1"
+	extFake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeInformers "k8s.io/client-go/informers"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/altinity/queue"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	chopFake "github.com/altinity/clickhouse-operator/pkg/client/clientset/versioned/fake"
+	chopInformers "github.com/altinity/clickhouse-operator/pkg/client/informers/externalversions"
+)
+
+// testController is a controller running against fake clients, with informers started and synced
+type testController struct {
+	*Controller
+	kubeClient          *kubeFake.Clientset
+	chopClient          *chopFake.Clientset
+	kubeInformerFactory kubeInformers.SharedInformerFactory
+}
+
+// newTestController creates controller with fake clients serving specified objects
+func newTestController(t *testing.T, kubeObjects []runtime.Object, chopObjects []runtime.Object) *testController {
+	require.NoError(t, chop.NewOffline(""))
+
+	kubeClient := kubeFake.NewSimpleClientset(kubeObjects...)
+	chopClient := chopFake.NewSimpleClientset(chopObjects...)
+	kubeInformerFactory := kubeInformers.NewSharedInformerFactory(kubeClient, 0)
+	chopInformerFactory := chopInformers.NewSharedInformerFactory(chopClient, 0)
+	c := NewController(chopClient, extFake.NewSimpleClientset(), kubeClient, chopInformerFactory, kubeInformerFactory)
+
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	kubeInformerFactory.Start(stop)
+	chopInformerFactory.Start(stop)
+	kubeInformerFactory.WaitForCacheSync(stop)
+	chopInformerFactory.WaitForCacheSync(stop)
+
+	return &testController{
+		Controller:          c,
+		kubeClient:          kubeClient,
+		chopClient:          chopClient,
+		kubeInformerFactory: kubeInformerFactory,
+	}
+}
+
+// newTestWorker creates worker of the controller
+func (c *testController) newTestWorker() *worker {
+	return c.newWorker(queue.New(), true)
+}
+
+func Test_EnqueueObject_Queues(t *testing.T) {
+	objectMeta := meta.ObjectMeta{Namespace: "test", Name: "events"}
+	pod := &core.Pod{ObjectMeta: objectMeta}
+	op := &api.ClickHouseOperation{ObjectMeta: objectMeta}
+	chi := &api.ClickHouseInstallation{ObjectMeta: objectMeta}
+
+	tests := []struct {
+		name  string
+		item  queue.PriorityQueueItem
+		index int
+	}{
+		{"pod", NewReconcilePod(reconcileUpdate, pod, pod), 0},
+		{"operation", NewRunOperation(op), operationsQueuesIndex()},
+		{"restart host", NewRequestRestartHost(chi), operationsQueuesIndex()},
+		{"maintenance", NewMaintainCHI(chi), maintenanceQueuesIndex()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(t, nil, nil)
+			c.enqueueObject(tt.item)
+			for i := range c.queues {
+				expected := 0
+				if i == tt.index {
+					expected = 1
+				}
+				require.Equal(t, expected, c.queues[i].Len(), "queue %d", i)
+			}
+		})
+	}
+}
@@ -315,7 +315,7 @@ func (c *Controller) deleteServiceIfExists(ctx context.Context, namespace, name
 	}
 
 	// Check specified service exists
-	_, err := c.kubeClient.CoreV1().Services(namespace).Get(ctx, name, controller.NewGetOptions())
+	_, err := c.getServiceByName(namespace, name)
 
 	if err != nil {
 		// No such a service, nothing to delete
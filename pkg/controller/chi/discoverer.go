@@ -18,6 +18,7 @@ import (
 	"context"
 
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sLabels "k8s.io/apimachinery/pkg/labels"
 
 	log "github.com/altinity/clickhouse-operator/pkg/announcer"
 	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
@@ -46,6 +47,23 @@ func (c *Controller) discovery(ctx context.Context, chi *api.ClickHouseInstallat
 }
 
 func (c *Controller) discoveryStatefulSets(ctx context.Context, r *model.Registry, chi *api.ClickHouseInstallation, opts meta.ListOptions) {
+	if c.statefulSetListerSynced() {
+		selector, err := k8sLabels.Parse(opts.LabelSelector)
+		if err != nil {
+			log.M(chi).F().Error("FAIL parse selector err: %v", err)
+			return
+		}
+		objects, err := c.statefulSetLister.StatefulSets(chi.Namespace).List(selector)
+		if err != nil {
+			log.M(chi).F().Error("FAIL list StatefulSet err: %v", err)
+			return
+		}
+		for _, obj := range objects {
+			r.RegisterStatefulSet(obj.ObjectMeta)
+		}
+		return
+	}
+
 	list, err := c.kubeClient.AppsV1().StatefulSets(chi.Namespace).List(ctx, opts)
 	if err != nil {
 		log.M(chi).F().Error("FAIL list StatefulSet err: %v", err)
@@ -61,6 +79,23 @@ func (c *Controller) discoveryStatefulSets(ctx context.Context, r *model.Registr
 }
 
 func (c *Controller) discoveryConfigMaps(ctx context.Context, r *model.Registry, chi *api.ClickHouseInstallation, opts meta.ListOptions) {
+	if c.configMapListerSynced() {
+		selector, err := k8sLabels.Parse(opts.LabelSelector)
+		if err != nil {
+			log.M(chi).F().Error("FAIL parse selector err: %v", err)
+			return
+		}
+		objects, err := c.configMapLister.ConfigMaps(chi.Namespace).List(selector)
+		if err != nil {
+			log.M(chi).F().Error("FAIL list ConfigMap err: %v", err)
+			return
+		}
+		for _, obj := range objects {
+			r.RegisterConfigMap(obj.ObjectMeta)
+		}
+		return
+	}
+
 	list, err := c.kubeClient.CoreV1().ConfigMaps(chi.Namespace).List(ctx, opts)
 	if err != nil {
 		log.M(chi).F().Error("FAIL list ConfigMap err: %v", err)
@@ -76,6 +111,23 @@ func (c *Controller) discoveryConfigMaps(ctx context.Context, r *model.Registry,
 }
 
 func (c *Controller) discoveryServices(ctx context.Context, r *model.Registry, chi *api.ClickHouseInstallation, opts meta.ListOptions) {
+	if c.serviceListerSynced() {
+		selector, err := k8sLabels.Parse(opts.LabelSelector)
+		if err != nil {
+			log.M(chi).F().Error("FAIL parse selector err: %v", err)
+			return
+		}
+		objects, err := c.serviceLister.Services(chi.Namespace).List(selector)
+		if err != nil {
+			log.M(chi).F().Error("FAIL list Service err: %v", err)
+			return
+		}
+		for _, obj := range objects {
+			r.RegisterService(obj.ObjectMeta)
+		}
+		return
+	}
+
 	list, err := c.kubeClient.CoreV1().Services(chi.Namespace).List(ctx, opts)
 	if err != nil {
 		log.M(chi).F().Error("FAIL list Service err: %v", err)
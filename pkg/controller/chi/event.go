@@ -43,23 +43,64 @@ const (
 
 const (
 	// Short, machine understandable string that gives the reason for the transition into the object's current status
-	eventReasonReconcileStarted       = "ReconcileStarted"
-	eventReasonReconcileInProgress    = "ReconcileInProgress"
-	eventReasonReconcileCompleted     = "ReconcileCompleted"
-	eventReasonReconcileFailed        = "ReconcileFailed"
-	eventReasonCreateStarted          = "CreateStarted"
-	eventReasonCreateInProgress       = "CreateInProgress"
-	eventReasonCreateCompleted        = "CreateCompleted"
-	eventReasonCreateFailed           = "CreateFailed"
-	eventReasonUpdateStarted          = "UpdateStarted"
-	eventReasonUpdateInProgress       = "UpdateInProgress"
-	eventReasonUpdateCompleted        = "UpdateCompleted"
-	eventReasonUpdateFailed           = "UpdateFailed"
-	eventReasonDeleteStarted          = "DeleteStarted"
-	eventReasonDeleteInProgress       = "DeleteInProgress"
-	eventReasonDeleteCompleted        = "DeleteCompleted"
-	eventReasonDeleteFailed           = "DeleteFailed"
-	eventReasonProgressHostsCompleted = "ProgressHostsCompleted"
+	eventReasonReconcileStarted           = "ReconcileStarted"
+	eventReasonReconcileInProgress        = "ReconcileInProgress"
+	eventReasonReconcileCompleted         = "ReconcileCompleted"
+	eventReasonReconcileFailed            = "ReconcileFailed"
+	eventReasonReconcileResumed           = "ReconcileResumed"
+	eventReasonReconcileInterrupted       = "ReconcileInterrupted"
+	eventReasonRolloutPaused              = "RolloutPaused"
+	eventReasonRolloutResumed             = "RolloutResumed"
+	eventReasonCreateStarted              = "CreateStarted"
+	eventReasonCreateInProgress           = "CreateInProgress"
+	eventReasonCreateCompleted            = "CreateCompleted"
+	eventReasonCreateFailed               = "CreateFailed"
+	eventReasonUpdateStarted              = "UpdateStarted"
+	eventReasonUpdateInProgress           = "UpdateInProgress"
+	eventReasonUpdateCompleted            = "UpdateCompleted"
+	eventReasonUpdateFailed               = "UpdateFailed"
+	eventReasonDeleteStarted              = "DeleteStarted"
+	eventReasonDeleteInProgress           = "DeleteInProgress"
+	eventReasonDeleteCompleted            = "DeleteCompleted"
+	eventReasonDeleteFailed               = "DeleteFailed"
+	eventReasonProgressHostsCompleted     = "ProgressHostsCompleted"
+	eventReasonUpgradeVerificationStarted = "UpgradeVerificationStarted"
+	eventReasonUpgradeVerificationPending = "UpgradeVerificationPending"
+	eventReasonUpgradeVerificationPassed  = "UpgradeVerificationPassed"
+	eventReasonUpgradeVerificationFailed  = "UpgradeVerificationFailed"
+	eventReasonBlueGreenSwitchPending     = "BlueGreenSwitchPending"
+	eventReasonBlueGreenSwitchCompleted   = "BlueGreenSwitchCompleted"
+	eventReasonBlueGreenSwitchFailed      = "BlueGreenSwitchFailed"
+	eventReasonDiskPressureDetected       = "DiskPressureDetected"
+	eventReasonDiskPressureResolved       = "DiskPressureResolved"
+	eventReasonOperationRequested         = "OperationRequested"
+	eventReasonOperationCompleted         = "OperationCompleted"
+	eventReasonOperationFailed            = "OperationFailed"
+	eventReasonPromoted                   = "Promoted"
+	eventReasonMutationStuck              = "MutationStuck"
+	eventReasonMutationKilled             = "MutationKilled"
+	eventReasonSpotTerminationNotice      = "SpotTerminationNotice"
+	eventReasonSpotHostRecovered          = "SpotHostRecovered"
+	eventReasonDrillStarted               = "DrillStarted"
+	eventReasonDrillPassed                = "DrillPassed"
+	eventReasonDrillFailed                = "DrillFailed"
+	eventReasonValidationFailed           = "ValidationFailed"
+	eventReasonDeprecatedFieldsMigrated   = "DeprecatedFieldsMigrated"
+	eventReasonUnmanagedObjectSkipped     = "UnmanagedObjectSkipped"
+	eventReasonTemplateNotFound           = "TemplateNotFound"
+	eventReasonNameCollision              = "NameCollision"
+	eventReasonDataLossRecovered          = "DataLossRecovered"
+	eventReasonFaultDomainRecommendation  = "FaultDomainRecommendation"
+	eventReasonHostUnhealthy              = "HostUnhealthy"
+	eventReasonHostRecovered              = "HostRecovered"
+	eventReasonStandbyPromotionStarted    = "StandbyPromotionStarted"
+	eventReasonStandbyPromoted            = "StandbyPromoted"
+	eventReasonStandbyPromotionFailed     = "StandbyPromotionFailed"
+	eventReasonStandbyUnavailable         = "StandbyUnavailable"
+	eventReasonUsersFromChanged           = "UsersFromChanged"
+	eventReasonUsersFromReloaded          = "UsersFromReloaded"
+	eventReasonUsersFromUnavailable       = "UsersFromUnavailable"
+	eventReasonUsersFromCollision         = "UsersFromCollision"
 )
 
 // EventInfo emits event Info
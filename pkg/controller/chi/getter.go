@@ -41,7 +41,15 @@ func (c *Controller) getConfigMap(objMeta *meta.ObjectMeta, byNameOnly bool) (*c
 
 	if (obj != nil) && (err == nil) {
 		// Object found by name
-		return obj, nil
+		return obj.DeepCopy(), nil
+	}
+
+	if apiErrors.IsNotFound(err) {
+		// Cache may lag behind objects which have just been created
+		obj, err = c.kubeClient.CoreV1().ConfigMaps(objMeta.Namespace).Get(controller.NewContext(), objMeta.Name, controller.NewGetOptions())
+		if err == nil {
+			return obj, nil
+		}
 	}
 
 	if !apiErrors.IsNotFound(err) {
@@ -72,7 +80,7 @@ func (c *Controller) getConfigMap(objMeta *meta.ObjectMeta, byNameOnly bool) (*c
 
 	if len(objects) == 1 {
 		// Exactly one object found by labels
-		return objects[0], nil
+		return objects[0].DeepCopy(), nil
 	}
 
 	// Too much objects found by labels
@@ -92,8 +100,24 @@ func (c *Controller) getService(obj interface{}) (*core.Service, error) {
 		name = model.CreateStatefulSetServiceName(typedObj)
 		namespace = typedObj.Address.Namespace
 	}
-	return c.serviceLister.Services(namespace).Get(name)
-	//return c.kubeClient.CoreV1().Services(namespace).Get(newTask(), name, newGetOptions())
+	return c.getServiceByName(namespace, name)
+}
+
+// getServiceByName gets Service by namespaced name from the informer cache.
+// API server is asked in case cache is not synced yet or does not have the object,
+// since cache may lag behind objects which have just been created
+func (c *Controller) getServiceByName(namespace, name string) (*core.Service, error) {
+	if c.serviceListerSynced() {
+		obj, err := c.serviceLister.Services(namespace).Get(name)
+		if err == nil {
+			// Cached object is shared, so it must not be modified
+			return obj.DeepCopy(), nil
+		}
+		if !apiErrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return c.kubeClient.CoreV1().Services(namespace).Get(controller.NewContext(), name, controller.NewGetOptions())
 }
 
 // getStatefulSet gets StatefulSet. Accepted types:
@@ -125,7 +149,15 @@ func (c *Controller) getStatefulSetByMeta(meta *meta.ObjectMeta, byNameOnly bool
 
 	if (obj != nil) && (err == nil) {
 		// Object found by name
-		return obj, nil
+		return obj.DeepCopy(), nil
+	}
+
+	if apiErrors.IsNotFound(err) {
+		// Cache may lag behind objects which have just been created
+		obj, err = c.kubeClient.AppsV1().StatefulSets(meta.Namespace).Get(controller.NewContext(), meta.Name, controller.NewGetOptions())
+		if err == nil {
+			return obj, nil
+		}
 	}
 
 	if !apiErrors.IsNotFound(err) {
@@ -154,7 +186,7 @@ func (c *Controller) getStatefulSetByMeta(meta *meta.ObjectMeta, byNameOnly bool
 
 	if len(objects) == 1 {
 		// Exactly one object found by labels
-		return objects[0], nil
+		return objects[0].DeepCopy(), nil
 	}
 
 	// Too much objects found by labels
@@ -167,6 +199,31 @@ func (c *Controller) getStatefulSetByHost(host *api.ChiHost) (*apps.StatefulSet,
 	name := model.CreateStatefulSetName(host)
 	namespace := host.Address.Namespace
 
+	return c.getStatefulSetByName(namespace, name)
+}
+
+// getStatefulSetByName gets StatefulSet by namespaced name from the informer cache.
+// API server is asked in case cache is not synced yet or does not have the object
+func (c *Controller) getStatefulSetByName(namespace, name string) (*apps.StatefulSet, error) {
+	if c.statefulSetListerSynced() {
+		obj, err := c.statefulSetLister.StatefulSets(namespace).Get(name)
+		if err == nil {
+			// Cached object is shared, so it must not be modified
+			return obj.DeepCopy(), nil
+		}
+		if !apiErrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return c.kubeClient.AppsV1().StatefulSets(namespace).Get(controller.NewContext(), name, controller.NewGetOptions())
+}
+
+// fetchStatefulSet gets StatefulSet of the host from API server, bypassing the informer cache.
+// Pollers and wait paths have to see the latest state, since cached object may lag behind and pass the checks,
+// such as generation observed, which the latest object does not pass yet
+func (c *Controller) fetchStatefulSet(host *api.ChiHost) (*apps.StatefulSet, error) {
+	name := model.CreateStatefulSetName(host)
+	namespace := host.Address.Namespace
 	return c.kubeClient.AppsV1().StatefulSets(namespace).Get(controller.NewContext(), name, controller.NewGetOptions())
 }
 
@@ -188,6 +245,30 @@ func (c *Controller) getPod(obj interface{}) (*core.Pod, error) {
 		name = model.CreatePodName(obj)
 		namespace = typedObj.Address.Namespace
 	}
+	return c.getPodByName(namespace, name)
+}
+
+// getPodByName gets Pod by namespaced name from the informer cache.
+// API server is asked in case cache is not synced yet or does not have the object
+func (c *Controller) getPodByName(namespace, name string) (*core.Pod, error) {
+	if c.podListerSynced() {
+		obj, err := c.podLister.Pods(namespace).Get(name)
+		if err == nil {
+			// Cached object is shared, so it must not be modified
+			return obj.DeepCopy(), nil
+		}
+		if !apiErrors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+	return c.kubeClient.CoreV1().Pods(namespace).Get(controller.NewContext(), name, controller.NewGetOptions())
+}
+
+// fetchPod gets pod of the host from API server, bypassing the informer cache.
+// Pollers and wait paths have to see the latest state of the pod, such as its readiness or node
+func (c *Controller) fetchPod(host *api.ChiHost) (*core.Pod, error) {
+	name := model.CreatePodName(host)
+	namespace := host.Address.Namespace
 	return c.kubeClient.CoreV1().Pods(namespace).Get(controller.NewContext(), name, controller.NewGetOptions())
 }
 
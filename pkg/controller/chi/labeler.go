@@ -23,6 +23,7 @@ import (
 	apps "k8s.io/api/apps/v1"
 	core "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	log "github.com/altinity/clickhouse-operator/pkg/announcer"
 	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
@@ -255,7 +256,7 @@ func (c *Controller) appendLabelReadyOnPod(ctx context.Context, host *api.ChiHos
 		return nil
 	}
 
-	pod, err := c.getPod(host)
+	pod, err := c.fetchPod(host)
 	if err != nil {
 		log.M(host).F().Error("FAIL get pod for host %s err:%v", host.Address.NamespaceNameString(), err)
 		return err
@@ -283,7 +284,7 @@ func (c *Controller) deleteLabelReadyPod(ctx context.Context, host *api.ChiHost)
 	if host == nil {
 		return nil
 	}
-	pod, err := c.getPod(host)
+	pod, err := c.fetchPod(host)
 	if apiErrors.IsNotFound(err) {
 		// Pod may be missing in case, say, StatefulSet has 0 pods because CHI is stopped
 		// This is not an error, after all
@@ -359,3 +360,70 @@ func (c *Controller) deleteAnnotationReadyService(ctx context.Context, host *api
 
 	return nil
 }
+
+// setPodConditionReady sets readiness gate condition on the pod of the specified host
+func (c *Controller) setPodConditionReady(ctx context.Context, host *api.ChiHost, ready bool, message string) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+
+	pod, err := c.fetchPod(host)
+	if apiErrors.IsNotFound(err) {
+		// No pod - no condition
+		return nil
+	}
+	if err != nil {
+		log.V(1).M(host).F().Info("FAIL get pod for host '%s' err: %v", host.Address.NamespaceNameString(), err)
+		return err
+	}
+
+	status := core.ConditionFalse
+	if ready {
+		status = core.ConditionTrue
+	}
+	condition := core.PodCondition{
+		Type:               core.PodConditionType(model.PodConditionReady),
+		Status:             status,
+		LastTransitionTime: meta.Now(),
+		Message:            message,
+	}
+
+	found := false
+	for i := range pod.Status.Conditions {
+		existing := &pod.Status.Conditions[i]
+		if existing.Type != condition.Type {
+			continue
+		}
+		if existing.Status == condition.Status && existing.Message == condition.Message {
+			// Nothing to update
+			return nil
+		}
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		found = true
+	}
+	if !found {
+		pod.Status.Conditions = append(pod.Status.Conditions, condition)
+	}
+
+	_, err = c.kubeClient.CoreV1().Pods(pod.Namespace).UpdateStatus(ctx, pod, controller.NewUpdateOptions())
+	if err != nil {
+		log.M(host).F().Error("FAIL setting readiness gate condition for host %s err:%v", host.Address.NamespaceNameString(), err)
+		return err
+	}
+
+	return nil
+}
+
+// isPodConditionReady checks whether readiness gate condition of the pod is set
+func isPodConditionReady(pod *core.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == core.PodConditionType(model.PodConditionReady) {
+			return condition.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
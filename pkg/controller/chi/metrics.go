@@ -16,9 +16,14 @@ package chi
 
 import (
 	"context"
+	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	otelApi "go.opentelemetry.io/otel/metric"
+	"k8s.io/apimachinery/pkg/api/resource"
 
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
 	"github.com/altinity/clickhouse-operator/pkg/metrics"
 )
 
@@ -44,6 +49,14 @@ type Metrics struct {
 	// HostReconcilesTimings is a histogram of durations of successfully completed host reconciles
 	HostReconcilesTimings otelApi.Float64Histogram
 
+	// CHIReconcileProgress is a ratio (gauge) of hosts completed in CHI reconciles in progress
+	CHIReconcileProgress otelApi.Float64ObservableGauge
+	// CHIReconcileETA is an estimated time (gauge) left till completion of CHI reconciles in progress
+	CHIReconcileETA otelApi.Float64ObservableGauge
+
+	// CHICapacity is a set of gauges of resources footprint of CHIs having capacity reporting enabled
+	CHICapacity []otelApi.Float64ObservableGauge
+
 	PodAddEvents    otelApi.Int64Counter
 	PodUpdateEvents otelApi.Int64Counter
 	PodDeleteEvents otelApi.Int64Counter
@@ -95,6 +108,49 @@ func createMetrics() *Metrics {
 		otelApi.WithUnit("s"),
 	)
 
+	CHIReconcileProgress, _ := metrics.Meter().Float64ObservableGauge(
+		"clickhouse_operator_chi_reconcile_progress",
+		otelApi.WithDescription("ratio of hosts completed in CHI reconcile in progress"),
+		otelApi.WithUnit("1"),
+		otelApi.WithFloat64Callback(observeReconcileProgress(func(p *api.ChiReconcileProgress) float64 {
+			if p.GetHostsTotal() == 0 {
+				return 0
+			}
+			return float64(p.GetHostsCompleted()) / float64(p.GetHostsTotal())
+		})),
+	)
+	CHIReconcileETA, _ := metrics.Meter().Float64ObservableGauge(
+		"clickhouse_operator_chi_reconcile_eta",
+		otelApi.WithDescription("estimated time left till completion of CHI reconcile in progress"),
+		otelApi.WithUnit("s"),
+		otelApi.WithFloat64Callback(observeReconcileProgress(func(p *api.ChiReconcileProgress) float64 {
+			return p.GetEstimatedDuration(time.Now()).Seconds()
+		})),
+	)
+
+	var CHICapacity []otelApi.Float64ObservableGauge
+	for _, g := range []struct {
+		name, description, unit string
+		value                   func(*api.ChiCapacityStatus) string
+	}{
+		{"cpu_requests", "CPU requested by containers of CHI pods", "{cpu}", func(c *api.ChiCapacityStatus) string { return c.CPURequests }},
+		{"cpu_limits", "CPU limits of containers of CHI pods", "{cpu}", func(c *api.ChiCapacityStatus) string { return c.CPULimits }},
+		{"memory_requests", "memory requested by containers of CHI pods", "By", func(c *api.ChiCapacityStatus) string { return c.MemoryRequests }},
+		{"memory_limits", "memory limits of containers of CHI pods", "By", func(c *api.ChiCapacityStatus) string { return c.MemoryLimits }},
+		{"memory_used", "resident memory of ClickHouse servers of CHI", "By", func(c *api.ChiCapacityStatus) string { return c.MemoryUsed }},
+		{"storage_requests", "storage requested by CHI PVCs", "By", func(c *api.ChiCapacityStatus) string { return c.StorageRequests }},
+		{"storage_capacity", "storage provisioned to CHI PVCs", "By", func(c *api.ChiCapacityStatus) string { return c.StorageCapacity }},
+		{"storage_used", "space used on disks of ClickHouse servers of CHI", "By", func(c *api.ChiCapacityStatus) string { return c.StorageUsed }},
+	} {
+		gauge, _ := metrics.Meter().Float64ObservableGauge(
+			"clickhouse_operator_chi_capacity_"+g.name,
+			otelApi.WithDescription(g.description),
+			otelApi.WithUnit(g.unit),
+			otelApi.WithFloat64Callback(observeCapacity(g.value)),
+		)
+		CHICapacity = append(CHICapacity, gauge)
+	}
+
 	PodAddEvents, _ := metrics.Meter().Int64Counter(
 		"clickhouse_operator_pod_add_events",
 		otelApi.WithDescription("number PodAdd events"),
@@ -122,6 +178,11 @@ func createMetrics() *Metrics {
 		HostReconcilesErrors:    HostReconcilesErrors,
 		HostReconcilesTimings:   HostReconcilesTimings,
 
+		CHIReconcileProgress: CHIReconcileProgress,
+		CHIReconcileETA:      CHIReconcileETA,
+
+		CHICapacity: CHICapacity,
+
 		PodAddEvents:    PodAddEvents,
 		PodUpdateEvents: PodUpdateEvents,
 		PodDeleteEvents: PodDeleteEvents,
@@ -161,6 +222,76 @@ func metricsHostReconcilesTimings(ctx context.Context, seconds float64) {
 	ensureMetrics().HostReconcilesTimings.Record(ctx, seconds)
 }
 
+// reconcileProgresses keeps progress of CHI reconciles in progress, reported by observable gauges
+var reconcileProgresses sync.Map
+
+// observeReconcileProgress creates callback observing value of each CHI reconcile in progress
+func observeReconcileProgress(value func(*api.ChiReconcileProgress) float64) otelApi.Float64Callback {
+	return func(_ context.Context, o otelApi.Float64Observer) error {
+		reconcileProgresses.Range(func(key, progress any) bool {
+			address := key.(api.ObjectAddress)
+			o.Observe(
+				value(progress.(*api.ChiReconcileProgress)),
+				otelApi.WithAttributes(
+					attribute.String("namespace", address.Namespace),
+					attribute.String("chi", address.Name),
+				),
+			)
+			return true
+		})
+		return nil
+	}
+}
+
+func metricsCHIReconcileProgress(chi *api.ClickHouseInstallation) {
+	ensureMetrics()
+	address := api.ObjectAddress{Namespace: chi.Namespace, Name: chi.Name}
+	if progress := chi.EnsureStatus().GetProgress(); progress != nil {
+		reconcileProgresses.Store(address, progress)
+	} else {
+		reconcileProgresses.Delete(address)
+	}
+}
+
+func metricsCHIReconcileProgressCompleted(chi *api.ClickHouseInstallation) {
+	reconcileProgresses.Delete(api.ObjectAddress{Namespace: chi.Namespace, Name: chi.Name})
+}
+
+// capacities keeps resources footprint of CHIs, reported by observable gauges
+var capacities sync.Map
+
+// observeCapacity creates callback observing quantity of resources footprint of each CHI
+func observeCapacity(value func(*api.ChiCapacityStatus) string) otelApi.Float64Callback {
+	return func(_ context.Context, o otelApi.Float64Observer) error {
+		capacities.Range(func(key, capacity any) bool {
+			quantity, err := resource.ParseQuantity(value(capacity.(*api.ChiCapacityStatus)))
+			if err != nil {
+				return true
+			}
+			address := key.(api.ObjectAddress)
+			o.Observe(
+				quantity.AsApproximateFloat64(),
+				otelApi.WithAttributes(
+					attribute.String("namespace", address.Namespace),
+					attribute.String("chi", address.Name),
+				),
+			)
+			return true
+		})
+		return nil
+	}
+}
+
+func metricsCHICapacity(chi *api.ClickHouseInstallation, capacity *api.ChiCapacityStatus) {
+	ensureMetrics()
+	address := api.ObjectAddress{Namespace: chi.Namespace, Name: chi.Name}
+	if capacity != nil {
+		capacities.Store(address, capacity)
+	} else {
+		capacities.Delete(address)
+	}
+}
+
 func metricsPodAdd(ctx context.Context) {
 	ensureMetrics().PodAddEvents.Add(ctx, 1)
 }
@@ -37,7 +37,7 @@ func (c *Controller) walkContainers(host *api.ChiHost, f func(container *v1.Cont
 
 // walkContainerStatuses walks with specified func over all statuses of the specified host
 func (c *Controller) walkContainerStatuses(host *api.ChiHost, f func(status *v1.ContainerStatus)) {
-	pod, err := c.getPod(host)
+	pod, err := c.fetchPod(host)
 	if err != nil {
 		log.M(host).F().Error("FAIL get pod for host %s err:%v", host.Address.NamespaceNameString(), err)
 		return
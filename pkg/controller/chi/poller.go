@@ -20,6 +20,7 @@ import (
 	"time"
 
 	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 
 	log "github.com/altinity/clickhouse-operator/pkg/announcer"
@@ -40,6 +41,7 @@ func (c *Controller) waitHostNotReady(ctx context.Context, host *api.ChiHost) er
 		// to return any errors
 		controller.NewPollerOptions().
 			FromConfig(chop.Config()).
+			FromCHI(host.GetCHI()).
 			SetGetErrorTimeout(0),
 		func(_ context.Context, sts *apps.StatefulSet) bool {
 			return model.IsStatefulSetNotReady(sts)
@@ -85,6 +87,11 @@ func (c *Controller) waitHostReady(ctx context.Context, host *api.ChiHost) error
 		func(_ctx context.Context, sts *apps.StatefulSet) bool {
 			_ = c.deleteLabelReadyPod(_ctx, host)
 			_ = c.deleteAnnotationReadyService(_ctx, host)
+			if host.GetCHI().Spec.Defaults.IsReadinessGateEnabled() && c.isHostPodContainersReady(host) {
+				// Pod is not Ready till readiness gate is set by the deep health check of the host,
+				// which is run after the host is ready from k8s point of view
+				return true
+			}
 			return model.IsStatefulSetReady(sts)
 		},
 		func(_ctx context.Context) {
@@ -96,12 +103,26 @@ func (c *Controller) waitHostReady(ctx context.Context, host *api.ChiHost) error
 	return err
 }
 
+// isHostPodContainersReady checks whether all containers of the host's pod are ready
+func (c *Controller) isHostPodContainersReady(host *api.ChiHost) bool {
+	pod, err := c.fetchPod(host)
+	if err != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == core.ContainersReady {
+			return condition.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
+
 // waitHostDeleted polls host's StatefulSet until it is not available
 func (c *Controller) waitHostDeleted(host *api.ChiHost) {
 	for {
 		// TODO
 		// Probably there would be better way to wait until k8s reported StatefulSet deleted
-		if _, err := c.getStatefulSet(host); err == nil {
+		if _, err := c.fetchStatefulSet(host); err == nil {
 			log.V(2).Info("cache NOT yet synced")
 			time.Sleep(15 * time.Second)
 		} else {
@@ -123,7 +144,7 @@ func (c *Controller) pollHost(
 		return nil
 	}
 
-	opts = opts.Ensure().FromConfig(chop.Config())
+	opts = opts.Ensure().FromConfig(chop.Config()).FromCHI(host.GetCHI())
 	namespace := host.Address.Namespace
 	name := host.Address.HostName
 
@@ -154,7 +175,7 @@ func (c *Controller) pollHostStatefulSet(
 	}
 
 	if opts == nil {
-		opts = controller.NewPollerOptions().FromConfig(chop.Config())
+		opts = controller.NewPollerOptions().FromConfig(chop.Config()).FromCHI(host.GetCHI())
 	}
 
 	namespace := host.Address.Namespace
@@ -166,7 +187,7 @@ func (c *Controller) pollHostStatefulSet(
 		opts,
 		&controller.PollerFunctions{
 			Get: func(_ctx context.Context) (any, error) {
-				return c.getStatefulSet(host)
+				return c.fetchStatefulSet(host)
 			},
 			IsDone: func(_ctx context.Context, a any) bool {
 				return isDoneFn(_ctx, a.(*apps.StatefulSet))
@@ -0,0 +1,132 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/yaml"
+	"github.com/stretchr/testify/require"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// newPollerTestHost creates host of the CHI, StatefulSet of which is polled once a second for a second
+func newPollerTestHost(t *testing.T, w *worker) *api.ChiHost {
+	chi := &api.ClickHouseInstallation{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+metadata:
+  namespace: test
+  name: poller
+spec:
+  reconciling:
+    statefulSet:
+      update:
+        timeout: 1
+        pollInterval: 1
+`), chi))
+	return w.normalize(chi).FirstHost()
+}
+
+// newPollerTestStatefulSet creates StatefulSet of the host of the specified generation,
+// which is observed and ready in case observed generation is the same
+func newPollerTestStatefulSet(host *api.ChiHost, generation, observedGeneration int64) *apps.StatefulSet {
+	replicas := int32(1)
+	sts := &apps.StatefulSet{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace:  host.Address.Namespace,
+			Name:       model.CreateStatefulSetName(host),
+			Generation: generation,
+		},
+		Spec: apps.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+		Status: apps.StatefulSetStatus{
+			ObservedGeneration: observedGeneration,
+		},
+	}
+	if generation == observedGeneration {
+		sts.Status.ReadyReplicas = replicas
+		sts.Status.CurrentReplicas = replicas
+		sts.Status.UpdatedReplicas = replicas
+	}
+	return sts
+}
+
+func Test_WaitHostReady_StaleCache(t *testing.T) {
+	c := newTestController(t, nil, nil)
+	w := c.newTestWorker()
+	host := newPollerTestHost(t, w)
+
+	// API server has StatefulSet updated, but not rolled out yet
+	_, err := c.kubeClient.AppsV1().StatefulSets("test").Create(context.Background(), newPollerTestStatefulSet(host, 2, 1), controller.NewCreateOptions())
+	require.NoError(t, err)
+
+	// Cache lags behind and has previous generation, which is rolled out completely
+	require.Eventually(t, func() bool {
+		_, err := c.statefulSetLister.StatefulSets("test").Get(model.CreateStatefulSetName(host))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	indexer := c.kubeInformerFactory.Apps().V1().StatefulSets().Informer().GetIndexer()
+	require.NoError(t, indexer.Update(newPollerTestStatefulSet(host, 1, 1)))
+	cached, err := c.getStatefulSetByHost(host)
+	require.NoError(t, err)
+	require.True(t, model.IsStatefulSetGeneration(cached, cached.Generation))
+
+	require.Error(t, c.waitHostReady(context.Background(), host), "stale cache must not pass readiness")
+
+	// Rollout is completed
+	_, err = c.kubeClient.AppsV1().StatefulSets("test").Update(context.Background(), newPollerTestStatefulSet(host, 2, 2), controller.NewUpdateOptions())
+	require.NoError(t, err)
+	require.NoError(t, c.waitHostReady(context.Background(), host))
+}
+
+func Test_IsHostPodContainersReady_StaleCache(t *testing.T) {
+	c := newTestController(t, nil, nil)
+	w := c.newTestWorker()
+	host := newPollerTestHost(t, w)
+
+	newPod := func(ready core.ConditionStatus) *core.Pod {
+		return &core.Pod{
+			ObjectMeta: meta.ObjectMeta{Namespace: "test", Name: model.CreatePodName(host)},
+			Status: core.PodStatus{
+				Conditions: []core.PodCondition{{Type: core.ContainersReady, Status: ready}},
+			},
+		}
+	}
+
+	// API server has pod with containers not ready, while cache still has them ready
+	_, err := c.kubeClient.CoreV1().Pods("test").Create(context.Background(), newPod(core.ConditionFalse), controller.NewCreateOptions())
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, err := c.podLister.Pods("test").Get(model.CreatePodName(host))
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	indexer := c.kubeInformerFactory.Core().V1().Pods().Informer().GetIndexer()
+	require.NoError(t, indexer.Update(newPod(core.ConditionTrue)))
+	cached, err := c.getPod(host)
+	require.NoError(t, err)
+	require.Equal(t, core.ConditionTrue, cached.Status.Conditions[0].Status)
+
+	require.False(t, c.isHostPodContainersReady(host))
+}
@@ -45,6 +45,8 @@ const (
 	priorityReconcileChopConfig int = 3
 	priorityReconcileEndpoints  int = 15
 	priorityDropDNS             int = 7
+	priorityMaintainCHI         int = 20
+	priorityRunOperation        int = 20
 )
 
 // ReconcileCHI specifies reconcile request queue item
@@ -252,3 +254,81 @@ func NewReconcilePod(cmd string, old, new *core.Pod) *ReconcilePod {
 		new: new,
 	}
 }
+
+// MaintainCHI specifies CHI maintenance queue item
+type MaintainCHI struct {
+	PriorityQueueItem
+	chi *api.ClickHouseInstallation
+}
+
+var _ queue.PriorityQueueItem = &MaintainCHI{}
+
+// Handle returns handle of the queue item
+func (r MaintainCHI) Handle() queue.T {
+	if r.chi != nil {
+		return "MaintainCHI" + ":" + r.chi.Namespace + "/" + r.chi.Name
+	}
+	return ""
+}
+
+// NewMaintainCHI creates new CHI maintenance queue item
+func NewMaintainCHI(chi *api.ClickHouseInstallation) *MaintainCHI {
+	return &MaintainCHI{
+		PriorityQueueItem: PriorityQueueItem{
+			priority: priorityMaintainCHI,
+		},
+		chi: chi,
+	}
+}
+
+// RunOperation specifies maintenance operation queue item
+type RunOperation struct {
+	PriorityQueueItem
+	op *api.ClickHouseOperation
+}
+
+var _ queue.PriorityQueueItem = &RunOperation{}
+
+// Handle returns handle of the queue item
+func (r RunOperation) Handle() queue.T {
+	if r.op != nil {
+		return "RunOperation" + ":" + r.op.Namespace + "/" + r.op.Name
+	}
+	return ""
+}
+
+// NewRunOperation creates new maintenance operation queue item
+func NewRunOperation(op *api.ClickHouseOperation) *RunOperation {
+	return &RunOperation{
+		PriorityQueueItem: PriorityQueueItem{
+			priority: priorityRunOperation,
+		},
+		op: op,
+	}
+}
+
+// RequestRestartHost specifies queue item of restart of a host requested by annotation of the CHI
+type RequestRestartHost struct {
+	PriorityQueueItem
+	chi *api.ClickHouseInstallation
+}
+
+var _ queue.PriorityQueueItem = &RequestRestartHost{}
+
+// Handle returns handle of the queue item
+func (r RequestRestartHost) Handle() queue.T {
+	if r.chi != nil {
+		return "RequestRestartHost" + ":" + r.chi.Namespace + "/" + r.chi.Name
+	}
+	return ""
+}
+
+// NewRequestRestartHost creates new queue item of restart of a host requested by annotation of the CHI
+func NewRequestRestartHost(chi *api.ClickHouseInstallation) *RequestRestartHost {
+	return &RequestRestartHost{
+		PriorityQueueItem: PriorityQueueItem{
+			priority: priorityRunOperation,
+		},
+		chi: chi,
+	}
+}
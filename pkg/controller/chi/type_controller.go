@@ -15,6 +15,7 @@
 package chi
 
 import (
+	"sync"
 	"time"
 
 	kube "k8s.io/client-go/kubernetes"
@@ -24,6 +25,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	//"k8s.io/client-go/util/workqueue"
 	apiExtensions "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/altinity/queue"
 
@@ -72,11 +74,23 @@ type Controller struct {
 	queues []queue.PriorityQueue
 	// not used explicitly
 	recorder record.EventRecorder
+
+	// shutdown is closed as soon as the operator is shutting down. No new hosts are reconciled since then
+	shutdown chan struct{}
+	// inFlight specifies CHIs being reconciled right now, by namespace/name
+	inFlight sync.Map
+
+	// keeperReader reads CHKs, so disruptive operations wait for operator-managed ensembles being reconfigured.
+	// Nil in case keeper controller is not running
+	keeperReader client.Reader
 }
 
 const (
-	componentName   = "clickhouse-operator"
-	runWorkerPeriod = time.Second
+	componentName     = "clickhouse-operator"
+	runWorkerPeriod   = time.Second
+	maintenancePeriod = time.Minute
+	// shutdownReportPeriod specifies how often reconciles in flight are reported, while waited for on shutdown
+	shutdownReportPeriod = 20 * time.Second
 )
 
 const (
@@ -25,8 +25,7 @@ import (
 
 func (c *Controller) walkPVCs(host *api.ChiHost, f func(pvc *core.PersistentVolumeClaim)) {
 	namespace := host.Address.Namespace
-	name := model.CreatePodName(host)
-	pod, err := c.kubeClient.CoreV1().Pods(namespace).Get(controller.NewContext(), name, controller.NewGetOptions())
+	pod, err := c.getPod(host)
 	if err != nil {
 		log.M(host).F().Error("FAIL get pod for host %s/%s err:%v", namespace, host.GetName(), err)
 		return
@@ -0,0 +1,158 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	k8sLabels "k8s.io/apimachinery/pkg/labels"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// reconcileBlueGreen reconciles common service shared by blue/green generations of the CHI.
+// Common service is switched to the CHI in case the CHI is requested to be active
+// and passes health and data-freshness checks.
+func (w *worker) reconcileBlueGreen(ctx context.Context, chi *api.ClickHouseInstallation) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	if !chi.Spec.BlueGreen.HasService() || chi.IsStopped() {
+		// Nothing to switch to
+		return
+	}
+
+	service := w.task.creator.CreateServiceBlueGreen()
+	if service == nil {
+		return
+	}
+
+	curService, _ := w.c.getService(service)
+	switch {
+	case model.IsServiceSelectingCHI(curService, chi):
+		// The CHI is active generation already, just keep common service up to date
+		_ = w.reconcileService(ctx, chi, service)
+		return
+	case !chi.Spec.BlueGreen.IsActive():
+		// The CHI is standby generation
+		return
+	}
+
+	if peers := w.c.getActiveBlueGreenPeers(chi); len(peers) > 0 {
+		// Generations would take the common service over from each other on every reconcile
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonBlueGreenSwitchFailed).
+			WithStatusAction(chi).
+			M(chi).F().
+			Error("Switch common service %s/%s to %s generation %s is refused, generations %v are requested to be active as well. Exactly one generation has to be active",
+				service.Namespace, service.Name, chi.Spec.BlueGreen.GetColor(), chi.Name, peers)
+		return
+	}
+
+	w.a.V(1).
+		WithEvent(chi, eventActionReconcile, eventReasonBlueGreenSwitchPending).
+		M(chi).F().
+		Info("Switch common service %s/%s to %s generation %s is pending checks",
+			service.Namespace, service.Name, chi.Spec.BlueGreen.GetColor(), chi.Name)
+
+	if err := w.checkBlueGreen(ctx, chi); err != nil {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonBlueGreenSwitchFailed).
+			WithStatusAction(chi).
+			M(chi).F().
+			Error("FAILED to switch common service %s/%s to %s generation %s err: %v",
+				service.Namespace, service.Name, chi.Spec.BlueGreen.GetColor(), chi.Name, err)
+		return
+	}
+
+	if err := w.reconcileService(ctx, chi, service); err != nil {
+		return
+	}
+
+	w.a.V(1).
+		WithEvent(chi, eventActionReconcile, eventReasonBlueGreenSwitchCompleted).
+		WithStatusAction(chi).
+		M(chi).F().
+		Info("Switched common service %s/%s to %s generation %s",
+			service.Namespace, service.Name, chi.Spec.BlueGreen.GetColor(), chi.Name)
+}
+
+// getActiveBlueGreenPeers gets names of other generations sharing common service with the CHI,
+// which are requested to be active as well
+func (c *Controller) getActiveBlueGreenPeers(chi *api.ClickHouseInstallation) []string {
+	chis, err := c.chiLister.ClickHouseInstallations(chi.Namespace).List(k8sLabels.Everything())
+	if err != nil {
+		return nil
+	}
+	return findActiveBlueGreenPeers(chi, chis)
+}
+
+// findActiveBlueGreenPeers finds names of CHIs, other than the specified one, sharing common service with it
+// and requested to be active
+func findActiveBlueGreenPeers(chi *api.ClickHouseInstallation, chis []*api.ClickHouseInstallation) []string {
+	var peers []string
+	for _, peer := range chis {
+		if (peer.Name == chi.Name) || (peer.Namespace != chi.Namespace) || peer.IsStopped() {
+			continue
+		}
+		if (peer.Spec.BlueGreen.GetService() == chi.Spec.BlueGreen.GetService()) && peer.Spec.BlueGreen.IsActive() {
+			peers = append(peers, peer.Name)
+		}
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// checkBlueGreen checks whether all hosts of the CHI are healthy and have their data fresh enough
+// for the CHI to become active generation
+func (w *worker) checkBlueGreen(ctx context.Context, chi *api.ClickHouseInstallation) error {
+	maxDelay := chi.Spec.BlueGreen.GetMaxReplicationDelay()
+
+	var err error
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		if err != nil {
+			return nil
+		}
+		var delay int
+		var e error
+		pollErr := w.c.pollHost(ctx, host, nil, func(_ctx context.Context, host *api.ChiHost) bool {
+			// Host is healthy in case it is able to respond, data is fresh in case replicas are not lagging much
+			delay, e = w.ensureClusterSchemer(host).HostMaxReplicationDelay(_ctx, host)
+			if e != nil {
+				w.a.V(1).M(host).F().Info("Host %s is not healthy yet err: %v", host.GetName(), e)
+				return false
+			}
+			if delay > maxDelay {
+				w.a.V(1).M(host).F().Info("Host %s replication delay %d sec exceeds %d sec", host.GetName(), delay, maxDelay)
+				return false
+			}
+			return true
+		})
+		switch {
+		case e != nil:
+			err = fmt.Errorf("host %s is not healthy: %v", host.GetName(), e)
+		case pollErr != nil:
+			err = fmt.Errorf("host %s replication delay %d sec exceeds %d sec: %v", host.GetName(), delay, maxDelay, pollErr)
+		}
+		return nil
+	})
+
+	return err
+}
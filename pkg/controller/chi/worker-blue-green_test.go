@@ -0,0 +1,93 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+)
+
+func newBlueGreenTestCHI(name, service, color string, active bool) *api.ClickHouseInstallation {
+	return &api.ClickHouseInstallation{
+		ObjectMeta: meta.ObjectMeta{Namespace: "test", Name: name},
+		Spec: api.ChiSpec{
+			BlueGreen: &api.ChiBlueGreen{
+				Service: service,
+				Color:   color,
+				Active:  api.NewStringBool(active),
+			},
+		},
+	}
+}
+
+func Test_FindActiveBlueGreenPeers(t *testing.T) {
+	blue := newBlueGreenTestCHI("blue", "events", api.BlueGreenColorBlue, true)
+	stopped := newBlueGreenTestCHI("stopped", "events", api.BlueGreenColorGreen, true)
+	stopped.Spec.Stop = api.NewStringBool(true)
+	other := newBlueGreenTestCHI("other", "events", api.BlueGreenColorGreen, true)
+	other.Namespace = "other"
+
+	tests := []struct {
+		name  string
+		chis  []*api.ClickHouseInstallation
+		peers []string
+	}{
+		{
+			name: "standby generation",
+			chis: []*api.ClickHouseInstallation{blue, newBlueGreenTestCHI("green", "events", api.BlueGreenColorGreen, false)},
+		},
+		{
+			name:  "both generations active",
+			chis:  []*api.ClickHouseInstallation{blue, newBlueGreenTestCHI("green", "events", api.BlueGreenColorGreen, true)},
+			peers: []string{"green"},
+		},
+		{
+			name: "other common service",
+			chis: []*api.ClickHouseInstallation{blue, newBlueGreenTestCHI("green", "logs", api.BlueGreenColorGreen, true)},
+		},
+		{
+			name: "stopped and other namespace",
+			chis: []*api.ClickHouseInstallation{blue, stopped, other},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.peers, findActiveBlueGreenPeers(blue, tt.chis))
+		})
+	}
+}
+
+func Test_ReconcileBlueGreen_BothGenerationsActive(t *testing.T) {
+	blue := newBlueGreenTestCHI("blue", "events", api.BlueGreenColorBlue, true)
+	green := newBlueGreenTestCHI("green", "events", api.BlueGreenColorGreen, true)
+	c := newTestController(t, nil, []runtime.Object{blue, green})
+	w := c.newTestWorker()
+
+	chi := w.normalize(green)
+	w.newTask(chi)
+	w.reconcileBlueGreen(context.Background(), chi)
+
+	// Common service is not switched while both generations are requested to be active
+	_, err := c.kubeClient.CoreV1().Services("test").Get(context.Background(), "events", controller.NewGetOptions())
+	require.Error(t, err)
+}
@@ -0,0 +1,79 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// startCheckpoint starts checkpointing the reconcile of the CHI.
+// In case the reconcile of the same generation has been interrupted, say by operator restart,
+// hosts completed by the interrupted reconcile are not reconciled again
+func (w *worker) startCheckpoint(chi *api.ClickHouseInstallation) {
+	if !chi.EnsureStatus().CheckpointStart(chi.GetGeneration()) {
+		w.task.checkpoint = nil
+		return
+	}
+
+	w.task.checkpoint = chi.EnsureStatus().GetCheckpoint()
+	w.a.V(1).
+		WithEvent(chi, eventActionReconcile, eventReasonReconcileResumed).
+		WithStatusAction(chi).
+		M(chi).F().
+		Info("reconcile resumed from checkpoint of generation %d, hosts completed already: %s",
+			w.task.checkpoint.GetGeneration(), strings.Join(w.task.checkpoint.GetHostsCompleted(), ","))
+}
+
+// isHostCheckpointed checks whether the host is completed by the interrupted reconcile being resumed
+func (w *worker) isHostCheckpointed(host *api.ChiHost) bool {
+	return w.task.checkpoint.HasHostCompleted(host.GetName())
+}
+
+// resumeHost skips reconcile of the host completed by the interrupted reconcile.
+// Objects of the host are registered as reconciled, so they are not purged as unknown ones
+func (w *worker) resumeHost(ctx context.Context, host *api.ChiHost) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	w.a.V(1).M(host).F().Info("Host %s is completed by the interrupted reconcile, skip it", host.GetName())
+
+	if host.IsFirst() {
+		_ = w.reconcileCHIServiceFinal(ctx, host.CHI)
+	}
+
+	w.prepareDesiredStatefulSet(host, false)
+	w.task.registryReconciled.RegisterStatefulSet(host.DesiredStatefulSet.ObjectMeta)
+	w.task.registryReconciled.RegisterConfigMap(w.task.creator.CreateConfigMapHost(host).ObjectMeta)
+	if service := w.task.creator.CreateServiceHost(host); service != nil {
+		w.task.registryReconciled.RegisterService(service.ObjectMeta)
+	}
+	w.c.walkDiscoveredPVCs(host, func(pvc *core.PersistentVolumeClaim) {
+		w.task.registryReconciled.RegisterPVC(pvc.ObjectMeta)
+	})
+	host.GetReconcileAttributes().UnsetAdd()
+
+	host.CHI.EnsureStatus().HostCompleted()
+	host.CHI.EnsureStatus().ProgressHostCompleted(host.GetName())
+	metricsCHIReconcileProgress(host.CHI)
+}
@@ -27,6 +27,7 @@ import (
 	core "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	log "github.com/altinity/clickhouse-operator/pkg/announcer"
 	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
@@ -91,12 +92,44 @@ func (w *worker) reconcileCHI(ctx context.Context, old, new *api.ClickHouseInsta
 		return nil
 	}
 
+	if !w.reconcileUpgradeVerification(ctx, new) {
+		w.a.M(new).F().Info("Upgrade verification has not passed - hold reconcile")
+		return nil
+	}
+
+	w.reportMigrations(new)
+	w.reportRejections(new)
+	w.reportUnknownGuardUsers(new)
+	w.reportEscapedValues(new)
+
+	if !w.validateTemplates(new) {
+		w.a.M(new).F().Info("Templates validation has not passed - deny reconcile")
+		return nil
+	}
+
+	if !w.validateLayout(ctx, new) {
+		w.a.M(new).F().Info("Layout validation has not passed - deny reconcile")
+		return nil
+	}
+
+	if !w.validateNames(ctx, new) {
+		w.a.M(new).F().Info("Names validation has not passed - deny reconcile")
+		return nil
+	}
+
+	if !w.validateUsersFrom(ctx, new) {
+		w.a.M(new).F().Info("Users sources validation has not passed - deny reconcile")
+		return nil
+	}
+
 	if util.IsContextDone(ctx) {
 		log.V(2).Info("task is done")
 		return nil
 	}
 
 	w.newTask(new)
+	w.c.startInFlight(new)
+	defer w.c.completeInFlight(new)
 	w.markReconcileStart(ctx, new, actionPlan)
 	w.excludeStoppedCHIFromMonitoring(new)
 	w.walkHosts(ctx, new, actionPlan)
@@ -107,22 +140,45 @@ func (w *worker) reconcileCHI(ctx context.Context, old, new *api.ClickHouseInsta
 			M(new).F().
 			Error("FAILED to reconcile CHI err: %v", err)
 		w.markReconcileCompletedUnsuccessfully(ctx, new, err)
+		w.completeUpgradeVerification(ctx, new, err)
 	} else {
 		// Post-process added items
 		if util.IsContextDone(ctx) {
 			log.V(2).Info("task is done")
 			return nil
 		}
-		w.a.V(1).
-			WithEvent(new, eventActionReconcile, eventReasonReconcileInProgress).
-			WithStatusAction(new).
-			M(new).F().
-			Info("remove items scheduled for deletion")
-		w.clean(ctx, new)
-		w.dropReplicas(ctx, new, actionPlan)
+		if w.c.isShuttingDown() {
+			// Rest of hosts are reconciled by the next operator instance, the reconcile is not completed yet
+			w.handOffReconcile(ctx, new)
+			return nil
+		}
+		if w.isRolloutPaused(new) {
+			// Rest of hosts are reconciled within the next windows, the reconcile is not completed yet
+			w.pauseRollout(ctx, new)
+			return nil
+		}
+		if new.GetReconciling().GetScope().IsEmpty() {
+			w.a.V(1).
+				WithEvent(new, eventActionReconcile, eventReasonReconcileInProgress).
+				WithStatusAction(new).
+				M(new).F().
+				Info("remove items scheduled for deletion")
+			w.clean(ctx, new)
+			w.dropReplicas(ctx, new, actionPlan)
+		} else {
+			// Objects out of scope are not reconciled and thus can not be told from obsolete ones
+			w.a.V(1).
+				WithEvent(new, eventActionReconcile, eventReasonReconcileInProgress).
+				WithStatusAction(new).
+				M(new).F().
+				Info("reconcile scope is specified, skip removal of items scheduled for deletion till full reconcile")
+		}
 		w.addCHIToMonitoring(new)
+		w.reportFaultDomains(new)
 		w.waitForIPAddresses(ctx, new)
 		w.finalizeReconcileAndMarkCompleted(ctx, new)
+		w.completeUpgradeVerification(ctx, new, nil)
+		w.reconcileBlueGreen(ctx, new)
 
 		metricsCHIReconcilesCompleted(ctx)
 		metricsCHIReconcilesTimings(ctx, time.Now().Sub(startTime).Seconds())
@@ -190,7 +246,48 @@ func (w *worker) reconcileCHIAuxObjectsPreliminary(ctx context.Context, chi *api
 	if err := w.reconcileCHIConfigMapUsers(ctx, chi); err != nil {
 		w.a.F().Error("failed to reconcile config map users. err: %v", err)
 	}
+	// Service Binding Secrets have to exist before pods refer to them
+	if err := w.reconcileCHIServiceBinding(ctx, chi); err != nil {
+		w.a.F().Error("failed to reconcile service binding secrets. err: %v", err)
+	}
+	// Headless Service has to exist before pods, so they get their DNS records
+	if err := w.reconcileCHIServiceHeadless(ctx, chi); err != nil {
+		w.a.F().Error("failed to reconcile headless service. err: %v", err)
+	}
+	if err := w.reconcileCHIServiceNodeLocal(ctx, chi); err != nil {
+		w.a.F().Error("failed to reconcile node-local service. err: %v", err)
+	}
+
+	return nil
+}
+
+// reconcileCHIServiceHeadless reconciles headless Service governing StatefulSets of all hosts, if any
+func (w *worker) reconcileCHIServiceHeadless(ctx context.Context, chi *api.ClickHouseInstallation) error {
+	service := w.task.creator.CreateServiceHeadless()
+	if service == nil {
+		// Hosts have own Services
+		return nil
+	}
+	if err := w.reconcileService(ctx, chi, service); err != nil {
+		w.task.registryFailed.RegisterService(service.ObjectMeta)
+		return err
+	}
+	w.task.registryReconciled.RegisterService(service.ObjectMeta)
+	return nil
+}
 
+// reconcileCHIServiceNodeLocal reconciles Service exposing hosts to node-local agents, if any
+func (w *worker) reconcileCHIServiceNodeLocal(ctx context.Context, chi *api.ClickHouseInstallation) error {
+	service := w.task.creator.CreateServiceNodeLocal()
+	if service == nil {
+		// Hosts are not exposed to node-local agents via Service
+		return nil
+	}
+	if err := w.reconcileService(ctx, chi, service); err != nil {
+		w.task.registryFailed.RegisterService(service.ObjectMeta)
+		return err
+	}
+	w.task.registryReconciled.RegisterService(service.ObjectMeta)
 	return nil
 }
 
@@ -234,7 +331,67 @@ func (w *worker) reconcileCHIAuxObjectsFinal(ctx context.Context, chi *api.Click
 	defer w.a.V(2).M(chi).E().P()
 
 	// CHI ConfigMaps with update
-	return w.reconcileCHIConfigMapCommon(ctx, chi, nil)
+	err := w.reconcileCHIConfigMapCommon(ctx, chi, nil)
+
+	// Standby hosts pre-create schema from hosts being in place
+	w.reconcileStandby(ctx, chi)
+
+	// Connection details are published as soon as the CHI is reachable
+	if err := w.reconcileCHIConnection(ctx, chi); err != nil {
+		w.a.F().Error("failed to reconcile connection details. err: %v", err)
+	}
+
+	return err
+}
+
+// reconcileCHIConnection reconciles object connection details of the CHI are published into
+func (w *worker) reconcileCHIConnection(ctx context.Context, chi *api.ClickHouseInstallation) error {
+	switch chi.Spec.Connection.GetPublish() {
+	case api.ConnectionPublishConfigMap:
+		configMap := w.task.creator.CreateConfigMapConnection()
+		err := w.reconcileConfigMap(ctx, chi, configMap)
+		if err == nil {
+			w.task.registryReconciled.RegisterConfigMap(configMap.ObjectMeta)
+		} else {
+			w.task.registryFailed.RegisterConfigMap(configMap.ObjectMeta)
+		}
+		return err
+	case api.ConnectionPublishSecret:
+		secret := w.task.creator.CreateSecretConnection()
+		err := w.reconcileSecretData(ctx, chi, secret)
+		if err == nil {
+			w.task.registryReconciled.RegisterSecret(secret.ObjectMeta)
+		} else {
+			w.task.registryFailed.RegisterSecret(secret.ObjectMeta)
+		}
+		return err
+	}
+	return nil
+}
+
+// reconcileCHIServiceBinding reconciles Service Binding Secrets of the CHI users.
+// Passwords of existing Secrets are kept, so users do not lose access on reconcile
+func (w *worker) reconcileCHIServiceBinding(ctx context.Context, chi *api.ClickHouseInstallation) (err error) {
+	for _, username := range chi.Spec.ServiceBinding.GetUsers() {
+		if util.IsContextDone(ctx) {
+			log.V(2).Info("task is done")
+			return nil
+		}
+
+		secret := w.task.creator.CreateSecretBinding(username)
+		if curSecret, e := w.c.getSecret(secret); e == nil {
+			if password, ok := curSecret.Data[model.BindingSecretPasswordKey]; ok {
+				secret.StringData[model.BindingSecretPasswordKey] = string(password)
+			}
+		}
+		if e := w.reconcileSecretData(ctx, chi, secret); e == nil {
+			w.task.registryReconciled.RegisterSecret(secret.ObjectMeta)
+		} else {
+			w.task.registryFailed.RegisterSecret(secret.ObjectMeta)
+			err = e
+		}
+	}
+	return err
 }
 
 // reconcileCHIConfigMapCommon reconciles all CHI's common ConfigMap
@@ -287,6 +444,9 @@ func (w *worker) reconcileHostConfigMap(ctx context.Context, host *api.ChiHost)
 		return nil
 	}
 
+	// Macros of a host are fetched from Kubernetes metadata
+	w.fetchHostMacros(ctx, host)
+
 	// ConfigMap for a host
 	configMap := w.task.creator.CreateConfigMapHost(host)
 	err := w.reconcileConfigMap(ctx, host.CHI, configMap)
@@ -429,7 +589,7 @@ func (w *worker) reconcileHostStatefulSet(ctx context.Context, host *api.ChiHost
 			WithStatusAction(host.CHI).
 			WithStatusError(host.CHI).
 			M(host).F().
-			Error("FAILED to reconcile StatefulSet for host ", host.GetName())
+			Error("FAILED to reconcile StatefulSet for host: %s", host.GetName())
 	}
 
 	return err
@@ -467,6 +627,11 @@ func (w *worker) reconcileCluster(ctx context.Context, cluster *api.Cluster) err
 	w.a.V(2).M(cluster).S().P()
 	defer w.a.V(2).M(cluster).E().P()
 
+	if !cluster.GetCHI().GetReconciling().GetScope().HasCluster(cluster.Name) {
+		w.a.V(1).M(cluster).F().Info("cluster %s is out of reconcile scope, skip it", cluster.Name)
+		return nil
+	}
+
 	// Add ChkCluster's Service
 	if service := w.task.creator.CreateServiceCluster(cluster); service != nil {
 		if err := w.reconcileService(ctx, cluster.CHI, service); err == nil {
@@ -501,6 +666,16 @@ func (w *worker) reconcileCluster(ctx context.Context, cluster *api.Cluster) err
 func (w *worker) getReconcileShardsWorkersNum(shards []*api.ChiShard, opts *ReconcileShardsAndHostsOptions) int {
 	availableWorkers := float64(chop.Config().Reconcile.Runtime.ReconcileShardsThreadsNumber)
 	maxConcurrencyPercent := float64(chop.Config().Reconcile.Runtime.ReconcileShardsMaxConcurrencyPercent)
+	// CHI may override operator's settings
+	if len(shards) > 0 {
+		runtime := shards[0].GetCHI().GetReconciling().GetRuntime()
+		if threads := runtime.GetReconcileShardsThreadsNumber(); threads > 0 {
+			availableWorkers = float64(threads)
+		}
+		if percent := runtime.GetReconcileShardsMaxConcurrencyPercent(); percent > 0 {
+			maxConcurrencyPercent = float64(percent)
+		}
+	}
 	_100Percent := float64(100)
 	shardsNum := float64(len(shards))
 
@@ -531,6 +706,8 @@ func (o *ReconcileShardsAndHostsOptions) FullFanOut() bool {
 
 // reconcileShardsAndHosts reconciles shards and hosts of each shard
 func (w *worker) reconcileShardsAndHosts(ctx context.Context, shards []*api.ChiShard) error {
+	shards = w.filterShardsInScope(shards)
+
 	// Sanity check - CHI has to have shard(s)
 	if len(shards) == 0 {
 		return nil
@@ -604,6 +781,19 @@ func (w *worker) reconcileShardsAndHosts(ctx context.Context, shards []*api.ChiS
 	return nil
 }
 
+// filterShardsInScope filters shards within reconcile scope of the CHI
+func (w *worker) filterShardsInScope(shards []*api.ChiShard) []*api.ChiShard {
+	var filtered []*api.ChiShard
+	for _, shard := range shards {
+		if shard.GetCHI().GetReconciling().GetScope().HasShard(shard.Address.ClusterName, shard.Name) {
+			filtered = append(filtered, shard)
+		} else {
+			w.a.V(1).M(shard).F().Info("shard %s of cluster %s is out of reconcile scope, skip it", shard.Name, shard.Address.ClusterName)
+		}
+	}
+	return filtered
+}
+
 func (w *worker) reconcileShardWithHosts(ctx context.Context, shard *api.ChiShard) error {
 	if err := w.reconcileShard(ctx, shard); err != nil {
 		return err
@@ -654,12 +844,35 @@ func (w *worker) reconcileHost(ctx context.Context, host *api.ChiHost) error {
 		return nil
 	}
 
+	if w.isHostCheckpointed(host) {
+		w.resumeHost(ctx, host)
+		return nil
+	}
+
+	if w.c.isShuttingDown() {
+		w.handOffHost(ctx, host)
+		return nil
+	}
+
+	if !w.admitHostToRollout(host) {
+		w.holdHost(ctx, host)
+		return nil
+	}
+
 	w.a.V(2).M(host).S().P()
 	defer w.a.V(2).M(host).E().P()
 
 	metricsHostReconcilesStarted(ctx)
 	startTime := time.Now()
 
+	host.CHI.EnsureStatus().ProgressHostStarted(host.GetName())
+	metricsCHIReconcileProgress(host.CHI)
+	_ = w.c.updateCHIObjectStatus(ctx, host.CHI, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			MainFields: true,
+		},
+	})
+
 	if host.IsFirst() {
 		w.reconcileCHIServicePreliminary(ctx, host.CHI)
 		defer w.reconcileCHIServiceFinal(ctx, host.CHI)
@@ -683,6 +896,9 @@ func (w *worker) reconcileHost(ctx context.Context, host *api.ChiHost) error {
 	// Create artifacts
 	w.prepareHostStatefulSetWithStatus(ctx, host, false)
 
+	// Host may be restarted, do not interfere with keeper scaling or upgrade
+	w.waitKeeperReconfigured(ctx, host)
+
 	if err := w.excludeHost(ctx, host); err != nil {
 		metricsHostReconcilesErrors(ctx)
 		w.a.V(1).
@@ -746,7 +962,25 @@ func (w *worker) reconcileHost(ctx context.Context, host *api.ChiHost) error {
 			M(host).F().
 			Warning("Check host for ClickHouse availability before migrating tables. Host: %s Failed to get ClickHouse version: %s", host.GetName(), version)
 	}
-	_ = w.migrateTables(ctx, host, migrateTableOpts)
+	if !migrateTableOpts.ForceMigrate() && w.isHostDataLost(ctx, host) {
+		// Data volume is lost or recreated empty while PVC looks intact, restore schema of the host
+		migrateTableOpts = &migrateTableOptions{
+			forceMigrate: true,
+			dropReplica:  true,
+		}
+		w.a.V(1).
+			M(host).F().
+			Info("Empty data volume detected for host %s. Will do force migrate", host.GetName())
+	}
+	if err := w.migrateTables(ctx, host, migrateTableOpts); (err == nil) && migrateTableOpts.ForceMigrate() && !host.IsStopped() {
+		w.a.V(1).
+			WithEvent(host.GetCHI(), eventActionReconcile, eventReasonDataLossRecovered).
+			WithStatusAction(host.GetCHI()).
+			M(host).F().
+			Info("Schema restored after data loss on host %s", host.GetName())
+	}
+	_ = w.createReplicatedDatabases(ctx, host)
+	_ = w.createMonitoringSchema(ctx, host)
 
 	if err := w.includeHost(ctx, host); err != nil {
 		metricsHostReconcilesErrors(ctx)
@@ -776,6 +1010,10 @@ func (w *worker) reconcileHost(ctx context.Context, host *api.ChiHost) error {
 	hostsCompleted := 0
 	hostsCount := 0
 	host.CHI.EnsureStatus().HostCompleted()
+	host.CHI.EnsureStatus().ProgressHostCompleted(host.GetName())
+	host.CHI.EnsureStatus().CheckpointHostCompleted(host.GetName())
+	host.CHI.EnsureStatus().ProgressAPIWritesThrottled(chop.APIWritesWaited() - w.task.apiWritesWaited)
+	metricsCHIReconcileProgress(host.CHI)
 	if host.CHI != nil && host.CHI.Status != nil {
 		hostsCompleted = host.CHI.Status.GetHostsCompletedCount()
 		hostsCount = host.CHI.Status.GetHostsCount()
@@ -784,7 +1022,10 @@ func (w *worker) reconcileHost(ctx context.Context, host *api.ChiHost) error {
 		WithEvent(host.CHI, eventActionProgress, eventReasonProgressHostsCompleted).
 		WithStatusAction(host.CHI).
 		M(host).F().
-		Info("[now: %s] %s: %d of %d", now, eventReasonProgressHostsCompleted, hostsCompleted, hostsCount)
+		Info("[now: %s] %s: %d of %d, estimated completion: %s, API writes throttled: %s",
+			now, eventReasonProgressHostsCompleted, hostsCompleted, hostsCount,
+			host.CHI.EnsureStatus().GetProgress().GetEstimatedCompletion(),
+			host.CHI.EnsureStatus().GetProgress().GetAPIWritesThrottled())
 
 	_ = w.c.updateCHIObjectStatus(ctx, host.CHI, UpdateCHIStatusOptions{
 		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
@@ -802,6 +1043,8 @@ func (w *worker) reconcileHost(ctx context.Context, host *api.ChiHost) error {
 func (w *worker) reconcilePDB(ctx context.Context, cluster *api.Cluster, pdb *policy.PodDisruptionBudget) error {
 	cur, err := w.c.kubeClient.PolicyV1().PodDisruptionBudgets(pdb.Namespace).Get(ctx, pdb.Name, controller.NewGetOptions())
 	switch {
+	case (err == nil) && model.IsUnmanaged(&cur.ObjectMeta):
+		w.reportUnmanaged(cluster.GetCHI(), "PodDisruptionBudget", &cur.ObjectMeta, nil)
 	case err == nil:
 		pdb.ResourceVersion = cur.ResourceVersion
 		_, err := w.c.kubeClient.PolicyV1().PodDisruptionBudgets(pdb.Namespace).Update(ctx, pdb, controller.NewUpdateOptions())
@@ -844,8 +1087,17 @@ func (w *worker) reconcileConfigMap(
 	// Check whether this object already exists in k8s
 	curConfigMap, err := w.c.getConfigMap(&configMap.ObjectMeta, true)
 
+	if (curConfigMap != nil) && model.IsUnmanaged(&curConfigMap.ObjectMeta) {
+		// ConfigMap is excluded from management - leave it as it is
+		w.reportUnmanaged(chi, "ConfigMap", &curConfigMap.ObjectMeta, nil)
+		return nil
+	}
+
 	if curConfigMap != nil {
 		// We have ConfigMap - try to update it
+		if preserved := model.PreserveUnmanagedConfigMapFields(configMap, curConfigMap); len(preserved) > 0 {
+			w.reportUnmanaged(chi, "ConfigMap", &curConfigMap.ObjectMeta, preserved)
+		}
 		err = w.updateConfigMap(ctx, chi, configMap)
 	}
 
@@ -865,6 +1117,16 @@ func (w *worker) reconcileConfigMap(
 	return err
 }
 
+// reportUnmanaged reports child object (or some of its fields) excluded from management by the operator
+func (w *worker) reportUnmanaged(chi *api.ClickHouseInstallation, kind string, objMeta *meta.ObjectMeta, fields []string) {
+	description := model.DescribeUnmanaged(kind, objMeta, fields)
+	chi.EnsureStatus().PushUnmanagedObject(description)
+	w.a.V(1).WithEvent(chi, eventActionReconcile, eventReasonUnmanagedObjectSkipped).
+		WithStatusAction(chi).
+		M(chi).F().
+		Info("Skip unmanaged %s", description)
+}
+
 // hasService checks whether specified service exists
 func (w *worker) hasService(ctx context.Context, chi *api.ClickHouseInstallation, service *core.Service) bool {
 	// Check whether this object already exists
@@ -885,6 +1147,12 @@ func (w *worker) reconcileService(ctx context.Context, chi *api.ClickHouseInstal
 	// Check whether this object already exists
 	curService, err := w.c.getService(service)
 
+	if (curService != nil) && model.IsUnmanaged(&curService.ObjectMeta) {
+		// Service is excluded from management - leave it as it is
+		w.reportUnmanaged(chi, "Service", &curService.ObjectMeta, nil)
+		return nil
+	}
+
 	if curService != nil {
 		// We have the Service - try to update it
 		err = w.updateService(ctx, chi, curService, service)
@@ -937,6 +1205,40 @@ func (w *worker) reconcileSecret(ctx context.Context, chi *api.ClickHouseInstall
 	return err
 }
 
+// reconcileSecretData reconciles core.Secret, data of which is generated and kept up-to-date by the operator
+func (w *worker) reconcileSecretData(ctx context.Context, chi *api.ClickHouseInstallation, secret *core.Secret) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+
+	w.a.V(2).M(chi).S().Info(secret.Name)
+	defer w.a.V(2).M(chi).E().Info(secret.Name)
+
+	// Check whether this object already exists
+	curSecret, err := w.c.getSecret(secret)
+	if err == nil {
+		// We have Secret - try to update it
+		secret.ResourceVersion = curSecret.ResourceVersion
+		err = w.updateSecret(ctx, chi, secret)
+	}
+
+	if apiErrors.IsNotFound(err) {
+		// Secret not found - even during Update process - try to create it
+		err = w.createSecret(ctx, chi, secret)
+	}
+
+	if err != nil {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonReconcileFailed).
+			WithStatusAction(chi).
+			WithStatusError(chi).
+			M(chi).F().
+			Error("FAILED to reconcile Secret: %s CHI: %s ", secret.Name, chi.Name)
+	}
+
+	return err
+}
+
 func (w *worker) dumpStatefulSetDiff(host *api.ChiHost, cur, new *apps.StatefulSet) {
 	if cur == nil {
 		w.a.V(1).M(host).Info("Cur StatefulSet is not available, nothing to compare to")
@@ -1162,7 +1464,7 @@ func (w *worker) reconcilePVCFromVolumeMount(
 		w.task.registryReconciled.RegisterPVC(pvcReconciled.ObjectMeta)
 	default:
 		w.task.registryFailed.RegisterPVC(pvc.ObjectMeta)
-		w.a.M(host).F().Error("Unable to reconcile PVC err: %v", pvc.Namespace, pvc.Name, err)
+		w.a.M(host).F().Error("Unable to reconcile PVC %s/%s err: %v", pvc.Namespace, pvc.Name, err)
 	}
 
 	// It still may return data loss errors
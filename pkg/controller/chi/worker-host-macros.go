@@ -0,0 +1,121 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+
+	core "k8s.io/api/core/v1"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// fetchHostMacros fetches runtime macros of the host from Kubernetes metadata of the node the host's pod is scheduled on
+func (w *worker) fetchHostMacros(ctx context.Context, host *api.ChiHost) {
+	if !host.GetCHI().Spec.HostMacros.IsEnabled() {
+		// No macros requested
+		return
+	}
+
+	pod, err := w.c.getPod(host)
+	if err != nil {
+		// Pod is not created yet, macros would be fetched as soon as pod is scheduled
+		w.a.V(2).M(host).F().Info("unable to get pod of host %s err: %v", host.GetName(), err)
+		return
+	}
+
+	w.fillHostMacros(ctx, host, pod)
+}
+
+// fillHostMacros fills runtime macros of the host from Kubernetes metadata of the node the pod is scheduled on
+func (w *worker) fillHostMacros(ctx context.Context, host *api.ChiHost, pod *core.Pod) {
+	if pod.Spec.NodeName == "" {
+		// Pod is not scheduled yet
+		return
+	}
+
+	node, err := w.c.kubeClient.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, controller.NewGetOptions())
+	if err != nil {
+		w.a.V(1).M(host).F().Warning("unable to get node %s of host %s err: %v", pod.Spec.NodeName, host.GetName(), err)
+		return
+	}
+
+	spec := host.GetCHI().Spec.HostMacros
+	macros := make(map[string]string)
+	if name := spec.GetNodeName(); name != "" {
+		macros[name] = node.Name
+	}
+	for name, label := range spec.GetNodeLabels() {
+		if value, ok := node.Labels[label]; ok {
+			macros[name] = value
+		}
+	}
+	for name, annotation := range spec.GetNodeAnnotations() {
+		if value, ok := node.Annotations[annotation]; ok {
+			macros[name] = value
+		}
+	}
+	for name := range macros {
+		if err := model.ValidateHostMacroName(name); err != nil {
+			w.a.V(1).M(host).F().Warning("skip macro %s: %v", name, err)
+			delete(macros, name)
+		}
+	}
+
+	if faultDomain := spec.GetFaultDomain(node.Labels); faultDomain != "" {
+		macros[api.FaultDomainMacroName] = faultDomain
+	}
+
+	host.Macros = macros
+}
+
+// refreshHostMacros refreshes config of the host in case its pod is scheduled to another node
+func (w *worker) refreshHostMacros(ctx context.Context, old, new *core.Pod) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	if (old == nil) || (new == nil) || (new.Spec.NodeName == "") || (old.Spec.NodeName == new.Spec.NodeName) {
+		// Pod is not (re)scheduled
+		return
+	}
+
+	chi, err := w.createCHIFromObjectMeta(&new.ObjectMeta, false, model.NewNormalizerOptions())
+	if err != nil {
+		w.a.V(1).M(new).F().Info("unable to find CHI of pod %s/%s err: %v", new.Namespace, new.Name, err)
+		return
+	}
+	if !chi.Spec.HostMacros.IsEnabled() {
+		// No macros requested
+		return
+	}
+
+	w.newTask(chi)
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		if model.CreatePodName(host) != new.Name {
+			return nil
+		}
+		w.a.V(1).M(host).F().Info("Pod of host %s scheduled to node %s. Refresh macros", host.GetName(), new.Spec.NodeName)
+		if err := w.reconcileHostConfigMap(ctx, host); err != nil {
+			w.a.M(host).F().Error("FAILED to reconcile config of host %s err: %v", host.GetName(), err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	chkController "github.com/altinity/clickhouse-operator/pkg/controller/chk"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+const (
+	// waitKeeperReconfiguredTimeout specifies how long to wait for keeper ensemble reconfiguration to complete
+	waitKeeperReconfiguredTimeout = 30 * time.Minute
+	// waitKeeperReconfiguredPeriod specifies how often to check whether keeper ensemble reconfiguration is completed
+	waitKeeperReconfiguredPeriod = 15 * time.Second
+)
+
+// waitKeeperReconfigured waits for the operator-managed keeper ensemble used by the host to complete scaling or
+// rolling upgrade, so disruptive operations, such as host restart or partition moves, do not risk the quorum.
+// Proceeds anyway after the timeout.
+func (w *worker) waitKeeperReconfigured(ctx context.Context, host *api.ChiHost) {
+	if w.c.keeperReader == nil {
+		return
+	}
+
+	start := time.Now()
+	for {
+		reconfiguring, err := chkController.IsReconfiguring(
+			ctx,
+			w.c.keeperReader,
+			host.Address.Namespace,
+			host.GetZookeeper(),
+			client.InNamespace(chop.Config().GetInformerNamespace()),
+		)
+		if err != nil {
+			w.a.V(1).M(host).F().Warning("Unable to check keeper of host %s, proceed anyway. err: %v", host.GetName(), err)
+			return
+		}
+		if !reconfiguring {
+			return
+		}
+		if time.Since(start) >= waitKeeperReconfiguredTimeout {
+			w.a.V(1).M(host).F().Warning("Keeper of host %s is still being reconfigured, proceed anyway", host.GetName())
+			return
+		}
+		w.a.V(1).M(host).F().Info("Keeper of host %s is being reconfigured, wait", host.GetName())
+		if util.IsContextDone(ctx) {
+			log.V(2).Info("task is done")
+			return
+		}
+		time.Sleep(waitKeeperReconfiguredPeriod)
+	}
+}
@@ -0,0 +1,775 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	"github.com/altinity/clickhouse-operator/pkg/metrics"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// processMaintainCHI runs maintenance policies over the CHI
+func (w *worker) processMaintainCHI(ctx context.Context, cmd *MaintainCHI) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+	if w.c.isShuttingDown() {
+		// Maintenance may start disruptive operations, such as drills, they are left to the next operator instance
+		return nil
+	}
+
+	// Policies share the normalized CHI of the maintenance round
+	normalized := w.normalize(cmd.chi)
+	w.maintainDiskUsage(ctx, cmd.chi, normalized)
+	w.maintainMutations(ctx, cmd.chi, normalized)
+	w.maintainSpot(ctx, cmd.chi, normalized)
+	w.maintainStandby(ctx, cmd.chi, normalized)
+	w.maintainRollout(ctx, cmd.chi)
+	w.maintainUsersFrom(ctx, cmd.chi, normalized)
+	w.maintainDrill(ctx, cmd.chi, normalized)
+	w.maintainReadinessGates(ctx, cmd.chi, normalized)
+	w.maintainRemoteWrite(ctx, cmd.chi, normalized)
+	w.maintainCapacity(ctx, cmd.chi, normalized)
+	return nil
+}
+
+// maintainDiskUsage checks disk usage of the CHI hosts.
+// Hosts crossing the threshold are put under disk pressure, which applies throttling settings to them,
+// unless the policy alerts only. Hosts are released from disk pressure as soon as space recovers.
+func (w *worker) maintainDiskUsage(ctx context.Context, chi, normalized *api.ClickHouseInstallation) {
+	policy := chi.Spec.Maintenance.GetDiskUsage()
+	pressured := chi.EnsureStatus().GetDiskPressureHosts()
+
+	if !policy.IsEnabled() {
+		// Policy is removed, throttling settings are removed by reconcile, just forget about pressured hosts
+		if len(pressured) > 0 {
+			w.updateDiskPressureHosts(ctx, chi, nil, nil)
+		}
+		return
+	}
+
+	var hosts []string
+	var changed []string
+	normalized.WalkHosts(func(host *api.ChiHost) error {
+		name := host.GetName()
+		isPressured := util.InArray(name, pressured)
+
+		usage, err := w.ensureClusterSchemer(host).HostDiskUsage(ctx, host)
+		if err != nil {
+			// Unable to check, keep host as it is
+			w.a.V(1).M(host).F().Warning("unable to check disk usage of host %s err: %v", name, err)
+			if isPressured {
+				hosts = append(hosts, name)
+			}
+			return nil
+		}
+
+		switch {
+		case !isPressured && (usage >= policy.GetThreshold()):
+			hosts = append(hosts, name)
+			changed = append(changed, name)
+			w.a.WithEvent(chi, eventActionReconcile, eventReasonDiskPressureDetected).
+				M(host).F().
+				Warning("Disk usage %d%% of host %s crossed threshold %d%%. Alert only: %t",
+					usage, name, policy.GetThreshold(), policy.IsAlert())
+		case isPressured && (usage < policy.GetRecoveryThreshold()):
+			changed = append(changed, name)
+			w.a.V(1).
+				WithEvent(chi, eventActionReconcile, eventReasonDiskPressureResolved).
+				M(host).F().
+				Info("Disk usage %d%% of host %s dropped below recovery threshold %d%%",
+					usage, name, policy.GetRecoveryThreshold())
+		case isPressured:
+			hosts = append(hosts, name)
+		}
+		return nil
+	})
+
+	if len(changed) > 0 {
+		w.updateDiskPressureHosts(ctx, chi, hosts, changed)
+	}
+}
+
+// updateDiskPressureHosts updates list of hosts under disk pressure and
+// reconciles config of the changed hosts in order to apply or remove throttling settings
+func (w *worker) updateDiskPressureHosts(ctx context.Context, chi *api.ClickHouseInstallation, hosts, changed []string) {
+	chi.EnsureStatus().SetDiskPressureHosts(hosts)
+	if err := w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			DiskPressureHosts: true,
+		},
+	}); err != nil {
+		return
+	}
+
+	if (len(changed) == 0) || chi.Spec.Maintenance.GetDiskUsage().IsAlert() {
+		// No settings to apply
+		return
+	}
+
+	// Normalized CHI has throttling settings applied to the hosts under disk pressure
+	normalized := w.normalize(chi)
+	w.newTask(normalized)
+	normalized.WalkHosts(func(host *api.ChiHost) error {
+		if util.InArray(host.GetName(), changed) {
+			if err := w.reconcileHostConfigMap(ctx, host); err != nil {
+				w.a.M(host).F().Error("FAILED to reconcile config of host %s err: %v", host.GetName(), err)
+			}
+		}
+		return nil
+	})
+}
+
+// maintainMutations checks mutations of the CHI hosts.
+// Mutations running longer than max duration are reported as stuck and killed, in case the policy says so.
+func (w *worker) maintainMutations(ctx context.Context, chi, normalized *api.ClickHouseInstallation) {
+	policy := chi.Spec.Maintenance.GetMutations()
+	known := chi.EnsureStatus().GetStuckMutations()
+
+	if !policy.IsEnabled() {
+		// Policy is removed, just forget about stuck mutations
+		if len(known) > 0 {
+			w.updateStuckMutations(ctx, chi, nil)
+		}
+		return
+	}
+
+	var stuck []string
+	normalized.WalkHosts(func(host *api.ChiHost) error {
+		schemer := w.ensureClusterSchemer(host)
+		names, sqls, err := schemer.HostStuckMutations(ctx, host, policy.GetMaxDuration())
+		if err != nil {
+			// Unable to check, keep mutations of the host as they are
+			w.a.V(1).M(host).F().Warning("unable to check mutations of host %s err: %v", host.GetName(), err)
+			for _, mutation := range known {
+				if strings.HasPrefix(mutation, host.GetName()+": ") {
+					stuck = append(stuck, mutation)
+				}
+			}
+			return nil
+		}
+
+		for i := range names {
+			mutation := host.GetName() + ": " + names[i]
+			if policy.IsKill() {
+				if err := schemer.HostKillMutations(ctx, host, []string{sqls[i]}); err == nil {
+					w.a.WithEvent(chi, eventActionReconcile, eventReasonMutationKilled).
+						M(host).F().
+						Warning("Mutation %s is running longer than %ds, killed", mutation, policy.GetMaxDuration())
+					continue
+				}
+				w.a.M(host).F().Error("FAILED to kill mutation %s err: %v", mutation, err)
+			}
+			stuck = append(stuck, mutation)
+			if !util.InArray(mutation, known) {
+				w.a.WithEvent(chi, eventActionReconcile, eventReasonMutationStuck).
+					M(host).F().
+					Warning("Mutation %s is running longer than %ds", mutation, policy.GetMaxDuration())
+			}
+		}
+		return nil
+	})
+
+	if !util.ArraysEqual(stuck, known) {
+		w.updateStuckMutations(ctx, chi, stuck)
+	}
+}
+
+// updateStuckMutations updates list of stuck mutations
+func (w *worker) updateStuckMutations(ctx context.Context, chi *api.ClickHouseInstallation, mutations []string) {
+	chi.EnsureStatus().SetStuckMutations(mutations)
+	_ = w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			StuckMutations: true,
+		},
+	})
+}
+
+// maintainSpot checks nodes of the CHI hosts for termination notices.
+// Hosts, nodes of which are about to be terminated, are drained: excluded from the service and clusters,
+// while other replicas of the shard sync parts of the host. Drained hosts are included back
+// as soon as they run on a node without termination notice.
+func (w *worker) maintainSpot(ctx context.Context, chi, normalized *api.ClickHouseInstallation) {
+	policy := chi.Spec.Maintenance.GetSpot()
+	known := chi.EnsureStatus().GetSpotTerminations()
+
+	if !policy.IsEnabled() {
+		// Policy is removed, include drained hosts back
+		if len(known) > 0 {
+			w.updateSpotTerminations(ctx, chi, normalized, nil, nil, w.findSpotTerminatedHosts(normalized, known))
+		}
+		return
+	}
+
+	var terminations []string
+	var drained, recovered []*api.ChiHost
+	normalized.WalkHosts(func(host *api.ChiHost) error {
+		name := host.GetName()
+		termination := findSpotTermination(known, host)
+
+		notice, node, err := w.findSpotTerminationNotice(ctx, policy, host)
+		if err != nil {
+			// Unable to check, keep host as it is
+			w.a.V(1).M(host).F().Warning("unable to check node of host %s err: %v", name, err)
+			if termination != "" {
+				terminations = append(terminations, termination)
+			}
+			return nil
+		}
+
+		switch {
+		case (termination == "") && (notice != ""):
+			terminations = append(terminations, fmt.Sprintf("%s: node %s %s", name, node, notice))
+			drained = append(drained, host)
+			w.a.WithEvent(chi, eventActionReconcile, eventReasonSpotTerminationNotice).
+				M(host).F().
+				Warning("Node %s of host %s received termination notice: %s. Draining host", node, name, notice)
+		case (termination != "") && (notice == ""):
+			recovered = append(recovered, host)
+			w.a.V(1).
+				WithEvent(chi, eventActionReconcile, eventReasonSpotHostRecovered).
+				M(host).F().
+				Info("Host %s runs on node %s without termination notice. Including host back", name, node)
+		case termination != "":
+			terminations = append(terminations, termination)
+		}
+		return nil
+	})
+
+	if (len(drained) > 0) || (len(recovered) > 0) {
+		w.updateSpotTerminations(ctx, chi, normalized, terminations, drained, recovered)
+	}
+}
+
+// findSpotTerminationNotice describes termination notice of the node the host runs on along with the node name.
+// Empty notice means the host runs on a node without termination notice or is not scheduled yet
+func (w *worker) findSpotTerminationNotice(
+	ctx context.Context,
+	policy *api.ChiSpotMaintenance,
+	host *api.ChiHost,
+) (notice, nodeName string, err error) {
+	pod, err := w.c.fetchPod(host)
+	if err != nil {
+		return "", "", err
+	}
+	if pod.Spec.NodeName == "" {
+		// Pod is not scheduled yet
+		return "", "", nil
+	}
+	node, err := w.c.kubeClient.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, controller.NewGetOptions())
+	if err != nil {
+		return "", pod.Spec.NodeName, err
+	}
+	return policy.FindTerminationNotice(node), node.Name, nil
+}
+
+// findSpotTermination finds recorded termination of the host
+func findSpotTermination(terminations []string, host *api.ChiHost) string {
+	for _, termination := range terminations {
+		if strings.HasPrefix(termination, host.GetName()+": ") {
+			return termination
+		}
+	}
+	return ""
+}
+
+// findSpotTerminatedHosts finds hosts having terminations recorded
+func (w *worker) findSpotTerminatedHosts(chi *api.ClickHouseInstallation, terminations []string) (hosts []*api.ChiHost) {
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		if findSpotTermination(terminations, host) != "" {
+			hosts = append(hosts, host)
+		}
+		return nil
+	})
+	return hosts
+}
+
+// updateSpotTerminations records terminations in status and hands off drained hosts.
+// Drained hosts are excluded from the service and clusters, other replicas of their shards sync parts.
+// Recovered hosts are included back into the service and clusters
+func (w *worker) updateSpotTerminations(
+	ctx context.Context,
+	chi *api.ClickHouseInstallation,
+	normalized *api.ClickHouseInstallation,
+	terminations []string,
+	drained []*api.ChiHost,
+	recovered []*api.ChiHost,
+) {
+	chi.EnsureStatus().SetSpotTerminations(terminations)
+	if err := w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			SpotTerminations: true,
+		},
+	}); err != nil {
+		return
+	}
+
+	// Stop inserts into drained hosts
+	for _, host := range drained {
+		_ = w.excludeHostFromService(ctx, host)
+	}
+
+	// Remote servers exclude all hosts still having terminations recorded
+	w.newTask(normalized)
+	_ = w.reconcileCHIConfigMapCommon(ctx, normalized, w.options(w.findSpotTerminatedHosts(normalized, terminations)...))
+
+	for _, host := range drained {
+		w.handOffSpotHost(ctx, host)
+	}
+	for _, host := range recovered {
+		_ = w.includeHostIntoService(ctx, host)
+	}
+}
+
+// handOffSpotHost makes other replicas of the shard fetch parts of the host being drained
+func (w *worker) handOffSpotHost(ctx context.Context, host *api.ChiHost) {
+	host.GetShard().WalkHosts(func(replica *api.ChiHost) error {
+		if replica.GetName() == host.GetName() {
+			return nil
+		}
+		if err := w.ensureClusterSchemer(replica).HostSyncTables(ctx, replica); err != nil {
+			w.a.V(1).M(replica).F().Warning("unable to sync replica %s with host %s err: %v", replica.GetName(), host.GetName(), err)
+		}
+		return nil
+	})
+}
+
+// maintainDrill runs game-day drills over the CHI, making one step per maintenance round.
+// One replica per shard is restarted at a time, the next replica is restarted only after the previous one recovers.
+// Drill fails as soon as any restarted replica does not recover in time or the rest replicas of its shard are not available.
+func (w *worker) maintainDrill(ctx context.Context, chi, normalized *api.ClickHouseInstallation) {
+	policy := chi.Spec.Maintenance.GetDrill()
+	drill := chi.EnsureStatus().GetDrill()
+
+	if !policy.IsEnabled() {
+		if drill.IsInProgress() {
+			w.finishDrill(ctx, chi, drill, fmt.Errorf("drills are disabled"))
+		}
+		return
+	}
+
+	if !drill.IsInProgress() {
+		if !drill.GetStartTime().IsZero() && (time.Since(drill.GetStartTime()) < policy.GetInterval()) {
+			// Not scheduled yet
+			return
+		}
+		if !isDrillAllowed(chi) {
+			// Drill is postponed till the CHI is healthy
+			return
+		}
+		drill = &api.ChiDrillStatus{
+			Run:       drill.GetRun() + 1,
+			Status:    api.DrillStatusInProgress,
+			StartTime: time.Now().Format(time.RFC3339),
+		}
+		w.a.V(1).
+			WithEvent(chi, eventActionReconcile, eventReasonDrillStarted).
+			M(chi).F().
+			Info("Drill %d started", drill.Run)
+	}
+
+	targets := findDrillTargets(normalized, drill.Run)
+	if len(targets) == 0 {
+		w.finishDrill(ctx, chi, drill, fmt.Errorf("no shards with multiple replicas"))
+		return
+	}
+
+	if drill.Host != "" {
+		host := findDrillHost(targets, drill.Host)
+		if host == nil {
+			w.finishDrill(ctx, chi, drill, fmt.Errorf("host %s is not found", drill.Host))
+			return
+		}
+		restarted := time.Since(drill.GetHostRestartTime()).Round(time.Second)
+		recovered, err := w.checkHostRecovered(ctx, host, drill.GetHostRestartTime(), policy.GetMaxReplicationDelay())
+		if (err == nil) && !recovered && (restarted > policy.GetTimeout()) {
+			err = fmt.Errorf("not recovered within %s", policy.GetTimeout())
+		}
+		switch {
+		case err != nil:
+			drill.Hosts = append(drill.Hosts, fmt.Sprintf("%s: failed: %v", host.GetName(), err))
+			w.finishDrill(ctx, chi, drill, fmt.Errorf("host %s failed drill: %v", host.GetName(), err))
+			return
+		case !recovered:
+			// Wait for the host to recover
+			w.updateDrill(ctx, chi, drill)
+			return
+		}
+		w.a.V(1).M(host).F().Info("Drill %d host %s recovered in %s", drill.Run, host.GetName(), restarted)
+		drill.Hosts = append(drill.Hosts, fmt.Sprintf("%s: recovered in %s", host.GetName(), restarted))
+		drill.Host = ""
+		drill.HostRestartTime = ""
+	}
+
+	if len(drill.Hosts) >= len(targets) {
+		w.finishDrill(ctx, chi, drill, nil)
+		return
+	}
+
+	host := targets[len(drill.Hosts)]
+	if err := w.evictHost(ctx, host); err != nil {
+		drill.Hosts = append(drill.Hosts, fmt.Sprintf("%s: failed: %v", host.GetName(), err))
+		w.finishDrill(ctx, chi, drill, fmt.Errorf("unable to restart host %s: %v", host.GetName(), err))
+		return
+	}
+	w.a.V(1).M(host).F().Info("Drill %d restarted host %s", drill.Run, host.GetName())
+	drill.Host = host.GetName()
+	drill.HostRestartTime = time.Now().Format(time.RFC3339)
+	w.updateDrill(ctx, chi, drill)
+}
+
+// maintainReadinessGates re-checks hosts having readiness gate condition of their pods not set,
+// which is the case for pods restarted outside of reconcile
+func (w *worker) maintainReadinessGates(ctx context.Context, chi, normalized *api.ClickHouseInstallation) {
+	if !chi.Spec.Defaults.IsReadinessGateEnabled() || (chi.Status.GetStatus() != api.StatusCompleted) {
+		// Readiness gates of hosts being reconciled are managed by reconcile
+		return
+	}
+
+	normalized.WalkHosts(func(host *api.ChiHost) error {
+		pod, err := w.c.getPod(host)
+		if err != nil {
+			return nil
+		}
+		if isPodConditionReady(pod) || !w.c.isHostPodContainersReady(host) {
+			return nil
+		}
+		if err := w.ensureClusterSchemer(host).HostDeepCheck(ctx, host); err != nil {
+			w.a.V(1).M(host).F().Warning("host %s failed health check err: %v", host.GetName(), err)
+			_ = w.c.setPodConditionReady(ctx, host, false, fmt.Sprintf("host failed health check: %v", err))
+			return nil
+		}
+		w.a.V(1).M(host).F().Info("host %s passed health check", host.GetName())
+		_ = w.c.setPodConditionReady(ctx, host, true, "host passed health check")
+		return nil
+	})
+}
+
+// isDrillAllowed checks whether the CHI is healthy enough for the drill to be started
+func isDrillAllowed(chi *api.ClickHouseInstallation) bool {
+	switch {
+	case chi.IsStopped():
+		return false
+	case chi.Status.GetStatus() != api.StatusCompleted:
+		// Reconcile is in progress or failed
+		return false
+	case len(chi.Status.GetSpotTerminations()) > 0:
+		return false
+	case len(chi.Status.GetDiskPressureHosts()) > 0:
+		return false
+	}
+	return true
+}
+
+// findDrillTargets finds hosts to be restarted by the drill: one replica per shard having multiple replicas.
+// Replica rotates with the drill run, so all replicas get restarted over consequent drills
+func findDrillTargets(chi *api.ClickHouseInstallation, run int) (hosts []*api.ChiHost) {
+	chi.WalkShards(func(shard *api.ChiShard) error {
+		if len(shard.Hosts) > 1 {
+			hosts = append(hosts, shard.Hosts[run%len(shard.Hosts)])
+		}
+		return nil
+	})
+	return hosts
+}
+
+// findDrillHost finds host by name
+func findDrillHost(hosts []*api.ChiHost, name string) *api.ChiHost {
+	for _, host := range hosts {
+		if host.GetName() == name {
+			return host
+		}
+	}
+	return nil
+}
+
+// evictHost restarts the host by eviction of its pod, so PodDisruptionBudget is respected
+func (w *worker) evictHost(ctx context.Context, host *api.ChiHost) error {
+	eviction := &policy.Eviction{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      model.CreatePodName(host),
+			Namespace: host.Address.Namespace,
+		},
+	}
+	err := w.c.kubeClient.PolicyV1().Evictions(host.Address.Namespace).Evict(ctx, eviction)
+	if apiErrors.IsTooManyRequests(err) {
+		return fmt.Errorf("eviction is blocked by PodDisruptionBudget")
+	}
+	return err
+}
+
+// checkHostRecovered checks whether restarted host has recovered: new pod is ready and replication has caught up.
+// Rest replicas of the shard have to be available all the time
+func (w *worker) checkHostRecovered(ctx context.Context, host *api.ChiHost, restartTime time.Time, maxReplicationDelay int) (bool, error) {
+	var err error
+	host.GetShard().WalkHosts(func(replica *api.ChiHost) error {
+		if (err == nil) && (replica.GetName() != host.GetName()) {
+			if _, e := w.ensureClusterSchemer(replica).HostActiveQueriesNum(ctx, replica); e != nil {
+				err = fmt.Errorf("replica %s of the shard is not available: %v", replica.GetName(), e)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	pod, e := w.c.fetchPod(host)
+	if (e != nil) || pod.CreationTimestamp.Time.Before(restartTime) || !isPodReady(pod) {
+		// Pod is not restarted yet
+		return false, nil
+	}
+	schemer := w.ensureClusterSchemer(host)
+	if delay, e := schemer.HostMaxReplicationDelay(ctx, host); (e != nil) || (delay > maxReplicationDelay) {
+		return false, nil
+	}
+	if readonly, e := schemer.HostReadonlyReplicasNum(ctx, host); (e != nil) || (readonly > 0) {
+		return false, nil
+	}
+	return true, nil
+}
+
+// isPodReady checks whether the pod has Ready condition set
+func isPodReady(pod *core.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == core.PodReady {
+			return condition.Status == core.ConditionTrue
+		}
+	}
+	return false
+}
+
+// finishDrill records result of the drill
+func (w *worker) finishDrill(ctx context.Context, chi *api.ClickHouseInstallation, drill *api.ChiDrillStatus, err error) {
+	drill.Host = ""
+	drill.HostRestartTime = ""
+	drill.FinishTime = time.Now().Format(time.RFC3339)
+	if err == nil {
+		drill.Status = api.DrillStatusPassed
+		w.a.V(1).
+			WithEvent(chi, eventActionReconcile, eventReasonDrillPassed).
+			M(chi).F().
+			Info("Drill %d passed, restarted hosts: %d", drill.Run, len(drill.Hosts))
+	} else {
+		drill.Status = api.DrillStatusFailed
+		drill.Error = err.Error()
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonDrillFailed).
+			M(chi).F().
+			Warning("Drill %d failed err: %v", drill.Run, err)
+	}
+	w.updateDrill(ctx, chi, drill)
+}
+
+// updateDrill updates status of the drill
+func (w *worker) updateDrill(ctx context.Context, chi *api.ClickHouseInstallation, drill *api.ChiDrillStatus) {
+	chi.EnsureStatus().SetDrill(drill)
+	_ = w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			Drill: true,
+		},
+	})
+}
+
+// Names of SLI series pushed via remote-write
+const (
+	sliHostUp                    = "clickhouse_operator_sli_host_up"
+	sliHostReplicationDelay      = "clickhouse_operator_sli_host_replication_delay_seconds"
+	sliShardAvailability         = "clickhouse_operator_sli_shard_availability"
+	sliShardReplicationFreshness = "clickhouse_operator_sli_shard_replication_delay_seconds"
+)
+
+// maintainRemoteWrite computes SLIs of the CHI and pushes them via Prometheus remote-write:
+// availability of shards as ratio of replicas serving queries and replication freshness as max replication delay
+func (w *worker) maintainRemoteWrite(ctx context.Context, chi, normalized *api.ClickHouseInstallation) {
+	policy := chi.Spec.Maintenance.GetRemoteWrite()
+	if !policy.IsEnabled() || chi.IsStopped() {
+		return
+	}
+
+	headers, err := w.getRemoteWriteHeaders(ctx, chi, policy)
+	if err != nil {
+		w.a.V(1).M(chi).F().Warning("unable to get remote-write headers err: %v", err)
+		return
+	}
+
+	var samples []metrics.RemoteWriteSample
+	normalized.WalkShards(func(shard *api.ChiShard) error {
+		up, delay := 0, 0
+		shard.WalkHosts(func(host *api.ChiHost) error {
+			labels := newSLILabels(chi, policy, host.Address.ClusterName, host.Address.ShardName)
+			labels["host"] = host.GetName()
+
+			hostUp, hostDelay := 0, 0
+			schemer := w.ensureClusterSchemer(host)
+			if _, err := schemer.HostActiveQueriesNum(ctx, host); err == nil {
+				hostUp = 1
+				up++
+				if hostDelay, err = schemer.HostMaxReplicationDelay(ctx, host); err == nil {
+					samples = append(samples, metrics.RemoteWriteSample{Name: sliHostReplicationDelay, Labels: labels, Value: float64(hostDelay)})
+					if hostDelay > delay {
+						delay = hostDelay
+					}
+				}
+			}
+			samples = append(samples, metrics.RemoteWriteSample{Name: sliHostUp, Labels: labels, Value: float64(hostUp)})
+			return nil
+		})
+
+		labels := newSLILabels(chi, policy, shard.Address.ClusterName, shard.Name)
+		availability := 0.0
+		if len(shard.Hosts) > 0 {
+			availability = float64(up) / float64(len(shard.Hosts))
+		}
+		samples = append(samples, metrics.RemoteWriteSample{Name: sliShardAvailability, Labels: labels, Value: availability})
+		if up > 0 {
+			samples = append(samples, metrics.RemoteWriteSample{Name: sliShardReplicationFreshness, Labels: labels, Value: float64(delay)})
+		}
+		return nil
+	})
+
+	if err := metrics.PushRemoteWrite(ctx, policy.URL, headers, samples); err != nil {
+		w.a.V(1).M(chi).F().Warning("unable to push SLIs to %s err: %v", policy.URL, err)
+		return
+	}
+	w.a.V(2).M(chi).F().Info("pushed %d SLI samples to %s", len(samples), policy.URL)
+}
+
+// getRemoteWriteHeaders gets HTTP headers of remote-write requests, including bearer token from the secret
+func (w *worker) getRemoteWriteHeaders(
+	ctx context.Context,
+	chi *api.ClickHouseInstallation,
+	policy *api.ChiRemoteWriteMaintenance,
+) (map[string]string, error) {
+	headers := make(map[string]string)
+	for name, value := range policy.GetHeaders() {
+		headers[name] = value
+	}
+
+	ref := policy.BearerTokenSecretKeyRef
+	if ref == nil {
+		return headers, nil
+	}
+	secret, err := w.c.kubeClient.CoreV1().Secrets(chi.Namespace).Get(ctx, ref.Name, controller.NewGetOptions())
+	if err != nil {
+		return nil, err
+	}
+	token, ok := secret.Data[ref.Key]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in secret %s/%s", ref.Key, chi.Namespace, ref.Name)
+	}
+	headers["Authorization"] = "Bearer " + strings.TrimSpace(string(token))
+	return headers, nil
+}
+
+// newSLILabels creates labels of SLI series of the shard
+func newSLILabels(chi *api.ClickHouseInstallation, policy *api.ChiRemoteWriteMaintenance, cluster, shard string) map[string]string {
+	labels := map[string]string{}
+	for name, value := range policy.GetLabels() {
+		labels[name] = value
+	}
+	labels["namespace"] = chi.Namespace
+	labels["chi"] = chi.Name
+	labels["cluster"] = cluster
+	labels["shard"] = shard
+	return labels
+}
+
+// maintainCapacity aggregates resources footprint of the CHI into status and metrics
+func (w *worker) maintainCapacity(ctx context.Context, chi, normalized *api.ClickHouseInstallation) {
+	var capacity *api.ChiCapacityStatus
+	if chi.Spec.Maintenance.GetCapacity().IsEnabled() {
+		capacity = w.collectCapacity(ctx, normalized)
+	}
+	metricsCHICapacity(chi, capacity)
+
+	if capacity.Equal(chi.EnsureStatus().GetCapacity()) {
+		return
+	}
+	chi.EnsureStatus().SetCapacity(capacity)
+	_ = w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			Capacity: true,
+		},
+	})
+}
+
+// collectCapacity sums up resources requested by pods and PVCs of the CHI along with resources used by ClickHouse
+func (w *worker) collectCapacity(ctx context.Context, chi *api.ClickHouseInstallation) *api.ChiCapacityStatus {
+	capacity := &api.ChiCapacityStatus{}
+	var cpuRequests, cpuLimits, memoryRequests, memoryLimits, storageRequests, storageCapacity resource.Quantity
+	var memoryUsed, storageUsed int64
+
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		pod, err := w.c.getPod(host)
+		if err != nil {
+			return nil
+		}
+		capacity.Pods++
+		for i := range pod.Spec.Containers {
+			resources := &pod.Spec.Containers[i].Resources
+			cpuRequests.Add(resources.Requests[core.ResourceCPU])
+			cpuLimits.Add(resources.Limits[core.ResourceCPU])
+			memoryRequests.Add(resources.Requests[core.ResourceMemory])
+			memoryLimits.Add(resources.Limits[core.ResourceMemory])
+		}
+
+		w.c.walkDiscoveredPVCs(host, func(pvc *core.PersistentVolumeClaim) {
+			capacity.PVCs++
+			storageRequests.Add(pvc.Spec.Resources.Requests[core.ResourceStorage])
+			storageCapacity.Add(pvc.Status.Capacity[core.ResourceStorage])
+		})
+
+		schemer := w.ensureClusterSchemer(host)
+		if used, err := schemer.HostMemoryUsed(ctx, host); err == nil {
+			memoryUsed += int64(used)
+		}
+		if used, err := schemer.HostStorageUsed(ctx, host); err == nil {
+			storageUsed += int64(used)
+		}
+		return nil
+	})
+
+	capacity.CPURequests = cpuRequests.String()
+	capacity.CPULimits = cpuLimits.String()
+	capacity.MemoryRequests = memoryRequests.String()
+	capacity.MemoryLimits = memoryLimits.String()
+	capacity.StorageRequests = storageRequests.String()
+	capacity.StorageCapacity = storageCapacity.String()
+	// Used resources fluctuate all the time, round them to mebibytes in order not to update status on every run
+	capacity.MemoryUsed = resource.NewQuantity(roundToMebibytes(memoryUsed), resource.BinarySI).String()
+	capacity.StorageUsed = resource.NewQuantity(roundToMebibytes(storageUsed), resource.BinarySI).String()
+	return capacity
+}
+
+// roundToMebibytes rounds number of bytes to mebibytes
+func roundToMebibytes(bytes int64) int64 {
+	const mebibyte = 1024 * 1024
+	return (bytes + mebibyte/2) / mebibyte * mebibyte
+}
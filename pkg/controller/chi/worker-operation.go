@@ -0,0 +1,448 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// migrateNodesPollInterval specifies how often migrated replica is checked for recovery
+const migrateNodesPollInterval = 10 * time.Second
+
+// processRunOperation runs maintenance operation over hosts of the CHI and reports per-host results in status
+func (w *worker) processRunOperation(ctx context.Context, cmd *RunOperation) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+
+	op := cmd.op
+	op.Status = &api.OperationStatus{
+		Status: api.OperationStatusInProgress,
+	}
+
+	if err := op.Spec.Validate(); err != nil {
+		return w.finishOperation(ctx, op, err)
+	}
+
+	chi, err := w.c.chiLister.ClickHouseInstallations(op.Namespace).Get(op.Spec.CHI)
+	if err != nil {
+		return w.finishOperation(ctx, op, fmt.Errorf("unable to find CHI %s/%s err: %v", op.Namespace, op.Spec.CHI, err))
+	}
+	if op = w.updateOperationStatus(ctx, op); op == nil {
+		return nil
+	}
+
+	w.a.V(1).M(op).F().Info("Run operation %s over CHI %s/%s", op.Spec.Type, chi.Namespace, chi.Name)
+	switch op.Spec.Type {
+	case api.OperationTypePromote:
+		err = w.promote(ctx, op, chi)
+	case api.OperationTypeMigrateNodes:
+		err = w.migrateNodes(ctx, op, chi)
+	case api.OperationTypeRestartHost:
+		err = w.restartHostOperation(ctx, op, chi)
+	default:
+		err = w.runOperationOnHosts(ctx, op, chi)
+	}
+
+	if err == nil {
+		w.a.V(1).
+			WithEvent(chi, eventActionReconcile, eventReasonOperationCompleted).
+			M(chi).F().
+			Info("Operation %s/%s %s completed", op.Namespace, op.Name, op.Spec.Type)
+	} else {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonOperationFailed).
+			M(chi).F().
+			Error("Operation %s/%s %s failed err: %v", op.Namespace, op.Name, op.Spec.Type, err)
+	}
+
+	return w.finishOperation(ctx, op, err)
+}
+
+// runOperationOnHosts runs SQL operation on every host in scope of the operation
+func (w *worker) runOperationOnHosts(ctx context.Context, op *api.ClickHouseOperation, chi *api.ClickHouseInstallation) error {
+	w.normalize(chi.DeepCopy()).WalkHosts(func(host *api.ChiHost) error {
+		if (op.Spec.Cluster != "") && (op.Spec.Cluster != host.Address.ClusterName) {
+			// Host is out of scope of the operation
+			return nil
+		}
+		w.waitKeeperReconfigured(ctx, host)
+		err := w.ensureClusterSchemer(host).HostRunOperation(ctx, host, &op.Spec)
+		if err != nil {
+			w.a.V(1).M(op).F().Warning("FAILED to run operation %s on host %s err: %v", op.Spec.Type, host.GetName(), err)
+		}
+		op.Status.PushHost(host.GetName(), err)
+		return nil
+	})
+
+	if op.Status.HasFailedHosts() {
+		return fmt.Errorf("operation failed on some hosts")
+	}
+	if len(op.Status.Hosts) == 0 {
+		return fmt.Errorf("no hosts to run operation on")
+	}
+	return nil
+}
+
+// promote turns standby CHI of cross-region replication topology into primary.
+// Every host is checked to have replication caught up and no read-only replicas before writes are enabled.
+// Role is switched in the CHI spec, so regular reconcile enables writes and updates published endpoints
+func (w *worker) promote(ctx context.Context, op *api.ClickHouseOperation, chi *api.ClickHouseInstallation) error {
+	if !chi.Spec.CrossRegion.IsStandby() {
+		return fmt.Errorf("CHI %s/%s is not a cross-region standby", chi.Namespace, chi.Name)
+	}
+
+	w.normalize(chi.DeepCopy()).WalkHosts(func(host *api.ChiHost) error {
+		err := w.checkPromotion(ctx, host, op.Spec.GetMaxReplicationDelay())
+		if err != nil {
+			w.a.V(1).M(op).F().Warning("Host %s is not ready to be promoted err: %v", host.GetName(), err)
+		}
+		op.Status.PushHost(host.GetName(), err)
+		return nil
+	})
+	if op.Status.HasFailedHosts() {
+		return fmt.Errorf("pre-promotion checks failed on some hosts")
+	}
+
+	cur, err := w.c.chopClient.ClickhouseV1().ClickHouseInstallations(chi.Namespace).Get(ctx, chi.Name, controller.NewGetOptions())
+	if err != nil {
+		return fmt.Errorf("unable to get CHI %s/%s err: %v", chi.Namespace, chi.Name, err)
+	}
+	cur.Spec.CrossRegion.Role = api.CrossRegionRolePrimary
+	if _, err := w.c.chopClient.ClickhouseV1().ClickHouseInstallations(cur.Namespace).Update(ctx, cur, controller.NewUpdateOptions()); err != nil {
+		return fmt.Errorf("unable to promote CHI %s/%s err: %v", chi.Namespace, chi.Name, err)
+	}
+
+	w.a.V(1).
+		WithEvent(chi, eventActionReconcile, eventReasonPromoted).
+		M(chi).F().
+		Info("CHI %s/%s promoted to %s", chi.Namespace, chi.Name, api.CrossRegionRolePrimary)
+	return nil
+}
+
+// checkPromotion checks whether host has caught up with the primary and is able to accept writes
+func (w *worker) checkPromotion(ctx context.Context, host *api.ChiHost, maxReplicationDelay int) error {
+	schemer := w.ensureClusterSchemer(host)
+	delay, err := schemer.HostMaxReplicationDelay(ctx, host)
+	if err != nil {
+		return err
+	}
+	if delay > maxReplicationDelay {
+		return fmt.Errorf("replication delay %ds exceeds %ds", delay, maxReplicationDelay)
+	}
+	readonly, err := schemer.HostReadonlyReplicasNum(ctx, host)
+	if err != nil {
+		return err
+	}
+	if readonly > 0 {
+		return fmt.Errorf("%d replicated tables are read-only", readonly)
+	}
+	return nil
+}
+
+// migrateNodes migrates replicas off the nodes matching the operation, for planned node pool replacement.
+// Matching nodes are cordoned, so evicted replicas are rescheduled onto other nodes. Nodes are left cordoned.
+// Replicas are migrated one at a time, shard after shard: replica is evicted only when the rest replicas of its shard
+// are healthy, and the next replica is evicted only after the previous one recovers on another node
+func (w *worker) migrateNodes(ctx context.Context, op *api.ClickHouseOperation, chi *api.ClickHouseInstallation) error {
+	nodes, err := w.cordonNodes(ctx, &op.Spec)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("no nodes match node selector or node taint")
+	}
+
+	w.normalize(chi.DeepCopy()).WalkHosts(func(host *api.ChiHost) error {
+		if (op.Spec.Cluster != "") && (op.Spec.Cluster != host.Address.ClusterName) {
+			// Host is out of scope of the operation
+			return nil
+		}
+		if op.Status.HasFailedHosts() {
+			// Migration stops at the first failure, so no more replicas are taken down
+			return nil
+		}
+		pod, err := w.c.fetchPod(host)
+		if (err != nil) || !util.InArray(pod.Spec.NodeName, nodes) {
+			// Host does not run on a node being migrated off
+			return nil
+		}
+
+		w.a.V(1).M(op).F().Info("Migrate host %s off node %s", host.GetName(), pod.Spec.NodeName)
+		err = w.migrateHost(ctx, host, &op.Spec)
+		if err != nil {
+			w.a.V(1).M(op).F().Warning("FAILED to migrate host %s off node %s err: %v", host.GetName(), pod.Spec.NodeName, err)
+		}
+		op.Status.PushHost(host.GetName(), err)
+		// Report progress, migration of every host may take long
+		if updated := w.updateOperationStatus(ctx, op); updated != nil {
+			op.ResourceVersion = updated.ResourceVersion
+		}
+		return nil
+	})
+
+	if op.Status.HasFailedHosts() {
+		return fmt.Errorf("migration failed on some hosts")
+	}
+	return nil
+}
+
+// cordonNodes marks nodes matching the operation unschedulable. Returns names of the matching nodes
+func (w *worker) cordonNodes(ctx context.Context, spec *api.OperationSpec) ([]string, error) {
+	list, err := w.c.kubeClient.CoreV1().Nodes().List(ctx, controller.NewListOptions())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes err: %v", err)
+	}
+
+	var names []string
+	for i := range list.Items {
+		node := &list.Items[i]
+		if !spec.MatchNode(node) {
+			continue
+		}
+		names = append(names, node.Name)
+		if node.Spec.Unschedulable {
+			continue
+		}
+		payload := []byte(`{"spec":{"unschedulable":true}}`)
+		if _, err := w.c.kubeClient.CoreV1().Nodes().Patch(ctx, node.Name, types.StrategicMergePatchType, payload, controller.NewPatchOptions()); err != nil {
+			return nil, fmt.Errorf("unable to cordon node %s err: %v", node.Name, err)
+		}
+		w.a.V(1).F().Info("Node %s cordoned", node.Name)
+	}
+	return names, nil
+}
+
+// migrateHost evicts the host once the rest replicas of its shard are healthy and waits for it to recover on another node
+func (w *worker) migrateHost(ctx context.Context, host *api.ChiHost, spec *api.OperationSpec) error {
+	if err := w.checkShardReplicas(ctx, host, spec.GetMaxReplicationDelay()); err != nil {
+		return err
+	}
+
+	evictTime := time.Now()
+	if err := w.evictHost(ctx, host); err != nil {
+		return err
+	}
+	return w.waitHostRecovered(ctx, host, evictTime, spec)
+}
+
+// checkShardReplicas checks whether the rest replicas of the shard of the host are healthy,
+// so the host can be taken down without making the shard unavailable
+func (w *worker) checkShardReplicas(ctx context.Context, host *api.ChiHost, maxReplicationDelay int) error {
+	var err error
+	host.GetShard().WalkHosts(func(replica *api.ChiHost) error {
+		if (err == nil) && (replica.GetName() != host.GetName()) {
+			if e := w.checkPromotion(ctx, replica, maxReplicationDelay); e != nil {
+				err = fmt.Errorf("replica %s of the shard is not healthy: %v", replica.GetName(), e)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// waitHostRecovered waits for the host restarted at the specified time to recover within host timeout of the operation
+func (w *worker) waitHostRecovered(ctx context.Context, host *api.ChiHost, restartTime time.Time, spec *api.OperationSpec) error {
+	for {
+		recovered, err := w.checkHostRecovered(ctx, host, restartTime, spec.GetMaxReplicationDelay())
+		switch {
+		case err != nil:
+			return err
+		case recovered:
+			return nil
+		case time.Since(restartTime) > spec.GetHostTimeout():
+			return fmt.Errorf("not recovered within %s", spec.GetHostTimeout())
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(migrateNodesPollInterval):
+		}
+	}
+}
+
+// restartHostOperation restarts the host specified by the operation.
+// Host is restarted only while the CHI is not being reconciled, so the restart does not interfere with reconcile
+func (w *worker) restartHostOperation(ctx context.Context, op *api.ClickHouseOperation, chi *api.ClickHouseInstallation) error {
+	if status := chi.GetStatus().GetStatus(); status != api.StatusCompleted {
+		return fmt.Errorf("CHI %s/%s is not completed, status: %s", chi.Namespace, chi.Name, status)
+	}
+
+	var host *api.ChiHost
+	w.normalize(chi.DeepCopy()).WalkHosts(func(h *api.ChiHost) error {
+		if (h.GetName() == op.Spec.Host) || (model.CreatePodName(h) == op.Spec.Host) {
+			host = h
+		}
+		return nil
+	})
+	if host == nil {
+		return fmt.Errorf("host %s is not found in CHI %s/%s", op.Spec.Host, chi.Namespace, chi.Name)
+	}
+
+	err := w.restartHost(ctx, host, &op.Spec)
+	if err != nil {
+		w.a.V(1).M(op).F().Warning("FAILED to restart host %s err: %v", host.GetName(), err)
+	}
+	op.Status.PushHost(host.GetName(), err)
+	return err
+}
+
+// restartHost restarts the host safely. Host is excluded from Services and ClickHouse clusters, running queries are
+// waited for to complete, pod of the host is evicted and the host is included back once it recovers and passes health check.
+// Host is restarted only when the rest replicas of its shard are healthy, so the shard stays available all the time.
+// Host is included back in case restart fails as well, so it does not stay out of service till the next reconcile
+func (w *worker) restartHost(ctx context.Context, host *api.ChiHost, spec *api.OperationSpec) (err error) {
+	switch {
+	case host.IsStopped():
+		return fmt.Errorf("host is stopped")
+	case host.GetShard().HostsCount() == 1:
+		return fmt.Errorf("host is the only replica of its shard, restart would make the shard unavailable")
+	}
+	if err := w.checkShardReplicas(ctx, host, spec.GetMaxReplicationDelay()); err != nil {
+		return err
+	}
+
+	w.newTask(host.GetCHI())
+
+	w.a.V(1).M(host).F().Info("Restart host %s: exclude from services and clusters", host.GetName())
+	_ = w.excludeHostFromService(ctx, host)
+	w.excludeHostFromClickHouseCluster(ctx, host)
+	defer func() {
+		if err == nil {
+			return
+		}
+		// Reconcile does not include hosts it has not changed, so the host is included back right away.
+		// Host not recovered yet gets no traffic via Services anyway, since its pod is not ready
+		includeCtx := ctx
+		if util.IsContextDone(ctx) {
+			// Host must not stay out of service due to the operator shutting down
+			includeCtx = controller.NewContext()
+		}
+		w.a.V(1).M(host).F().Warning("Restart host %s failed, include into services and clusters err: %v", host.GetName(), err)
+		_ = w.includeHost(includeCtx, host)
+	}()
+
+	if err := w.waitHostNoActiveQueries(ctx, host); err != nil {
+		// Running queries are not interrupted, host is brought back as is
+		return fmt.Errorf("running queries have not completed: %v", err)
+	}
+
+	w.a.V(1).M(host).F().Info("Restart host %s: evict pod %s", host.GetName(), model.CreatePodName(host))
+	restartTime := time.Now()
+	if err := w.evictHost(ctx, host); err != nil {
+		return err
+	}
+	if err := w.waitHostRecovered(ctx, host, restartTime, spec); err != nil {
+		return err
+	}
+	if err := w.ensureClusterSchemer(host).HostDeepCheck(ctx, host); err != nil {
+		return fmt.Errorf("host failed health check: %v", err)
+	}
+
+	w.a.V(1).M(host).F().Info("Restart host %s: include into services and clusters", host.GetName())
+	return w.includeHost(ctx, host)
+}
+
+// processRequestRestartHost turns restart-host annotation of the CHI into RestartHost operation,
+// so the host is restarted safely and result of the restart is reported in status of the operation.
+// Annotation is removed from the CHI as soon as the operation is created
+func (w *worker) processRequestRestartHost(ctx context.Context, cmd *RequestRestartHost) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+
+	// Annotation may be handled already, in case the request was queued more than once
+	chis := w.c.chopClient.ClickhouseV1().ClickHouseInstallations(cmd.chi.Namespace)
+	chi, err := chis.Get(ctx, cmd.chi.Name, controller.NewGetOptions())
+	if err != nil {
+		w.a.M(cmd.chi).F().Error("FAILED to get CHI to request restart of host err: %v", err)
+		return nil
+	}
+	host := chi.GetAnnotations()[model.AnnotationRestartHost]
+	if host == "" {
+		return nil
+	}
+
+	op := &api.ClickHouseOperation{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace:    chi.Namespace,
+			GenerateName: chi.Name + "-restart-",
+			OwnerReferences: []meta.OwnerReference{
+				{
+					APIVersion: api.SchemeGroupVersion.String(),
+					Kind:       api.ClickHouseInstallationCRDResourceKind,
+					Name:       chi.Name,
+					UID:        chi.UID,
+				},
+			},
+		},
+		Spec: api.OperationSpec{
+			CHI:  chi.Name,
+			Type: api.OperationTypeRestartHost,
+			Host: host,
+		},
+	}
+	op, err = w.c.chopClient.ClickhouseV1().ClickHouseOperations(chi.Namespace).Create(ctx, op, controller.NewCreateOptions())
+	if err != nil {
+		// Annotation is kept, so restart is requested again on the next update of the CHI
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonOperationFailed).
+			M(chi).F().
+			Error("FAILED to request restart of host %s err: %v", host, err)
+		return nil
+	}
+
+	w.a.V(1).WithEvent(chi, eventActionReconcile, eventReasonOperationRequested).
+		M(chi).F().
+		Info("Restart of host %s is requested by annotation, operation %s/%s", host, op.Namespace, op.Name)
+
+	delete(chi.Annotations, model.AnnotationRestartHost)
+	if _, err := chis.Update(ctx, chi, controller.NewUpdateOptions()); err != nil {
+		w.a.M(chi).F().Error("FAILED to remove annotation %s err: %v", model.AnnotationRestartHost, err)
+	}
+	return nil
+}
+
+// finishOperation sets final status of the operation
+func (w *worker) finishOperation(ctx context.Context, op *api.ClickHouseOperation, err error) error {
+	op.Status.Status = api.OperationStatusCompleted
+	if err != nil {
+		op.Status.Status = api.OperationStatusFailed
+		op.Status.Error = err.Error()
+	}
+	w.updateOperationStatus(ctx, op)
+	return nil
+}
+
+// updateOperationStatus updates status of the operation. Returns updated operation or nil on failure
+func (w *worker) updateOperationStatus(ctx context.Context, op *api.ClickHouseOperation) *api.ClickHouseOperation {
+	updated, err := w.c.chopClient.ClickhouseV1().ClickHouseOperations(op.Namespace).UpdateStatus(ctx, op, controller.NewUpdateOptions())
+	if err != nil {
+		w.a.M(op).F().Error("FAILED to update status of operation %s/%s err: %v", op.Namespace, op.Name, err)
+		return nil
+	}
+	return updated
+}
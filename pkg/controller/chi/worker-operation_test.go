@@ -0,0 +1,112 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kubernetes-sigs/yaml"
+	"github.com/stretchr/testify/require"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubeTesting "k8s.io/client-go/testing"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+func Test_ProcessRequestRestartHost(t *testing.T) {
+	chi := &api.ClickHouseInstallation{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace: "test",
+			Name:      "events",
+			UID:       "uid-1",
+			Annotations: map[string]string{
+				model.AnnotationRestartHost: "chi-events-main-0-1",
+				"team":                      "analytics",
+			},
+		},
+	}
+	c := newTestController(t, nil, []runtime.Object{chi})
+	// Fake clientset does not generate names
+	generated := 0
+	c.chopClient.PrependReactor("create", "clickhouseoperations", func(action kubeTesting.Action) (bool, runtime.Object, error) {
+		op := action.(kubeTesting.CreateAction).GetObject().(*api.ClickHouseOperation)
+		if op.Name == "" {
+			generated++
+			op.Name = fmt.Sprintf("%s%d", op.GenerateName, generated)
+		}
+		return false, nil, nil
+	})
+	w := c.newTestWorker()
+	ctx := context.Background()
+
+	// Request may be queued more than once, restart is requested once
+	require.NoError(t, w.processRequestRestartHost(ctx, NewRequestRestartHost(chi)))
+	require.NoError(t, w.processRequestRestartHost(ctx, NewRequestRestartHost(chi)))
+
+	ops, err := c.chopClient.ClickhouseV1().ClickHouseOperations("test").List(ctx, controller.NewListOptions())
+	require.NoError(t, err)
+	require.Len(t, ops.Items, 1)
+	op := ops.Items[0]
+	require.Equal(t, "events-restart-1", op.Name)
+	require.Equal(t, api.OperationSpec{
+		CHI:  "events",
+		Type: api.OperationTypeRestartHost,
+		Host: "chi-events-main-0-1",
+	}, op.Spec)
+	require.Len(t, op.OwnerReferences, 1)
+	require.Equal(t, chi.UID, op.OwnerReferences[0].UID)
+
+	cur, err := c.chopClient.ClickhouseV1().ClickHouseInstallations("test").Get(ctx, "events", controller.NewGetOptions())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"team": "analytics"}, cur.Annotations)
+}
+
+func Test_RestartHost_Refused(t *testing.T) {
+	c := newTestController(t, nil, nil)
+	w := c.newTestWorker()
+
+	newHost := func(manifest string) *api.ChiHost {
+		chi := &api.ClickHouseInstallation{}
+		require.NoError(t, yaml.Unmarshal([]byte(manifest), chi))
+		return w.normalize(chi).FirstHost()
+	}
+
+	single := newHost(`
+metadata:
+  namespace: test
+  name: single
+`)
+	require.ErrorContains(t, w.restartHost(context.Background(), single, &api.OperationSpec{}), "only replica")
+
+	stopped := newHost(`
+metadata:
+  namespace: test
+  name: stopped
+spec:
+  stop: "yes"
+  configuration:
+    clusters:
+      - name: main
+        layout:
+          replicasCount: 2
+`)
+	require.ErrorContains(t, w.restartHost(context.Background(), stopped, &api.OperationSpec{}), "stopped")
+}
@@ -0,0 +1,160 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// podFailingWaitingReasons lists reasons of waiting containers, which are not going to start by themselves
+var podFailingWaitingReasons = []string{
+	"CrashLoopBackOff",
+	"ImagePullBackOff",
+	"ErrImagePull",
+	"CreateContainerConfigError",
+	"CreateContainerError",
+	"RunContainerError",
+}
+
+// podFailureReason explains why the pod is failing. Empty reason means pod is not failing
+func podFailureReason(pod *core.Pod) string {
+	if (pod == nil) || (pod.DeletionTimestamp != nil) {
+		// Pod being deleted is not a failure
+		return ""
+	}
+	if pod.Status.Phase == core.PodFailed {
+		return fmt.Sprintf("pod failed %s", pod.Status.Reason)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		switch {
+		case (status.State.Waiting != nil) && util.InArray(status.State.Waiting.Reason, podFailingWaitingReasons):
+			return fmt.Sprintf("container %s %s", status.Name, status.State.Waiting.Reason)
+		case (status.State.Terminated != nil) && (status.State.Terminated.ExitCode != 0):
+			return fmt.Sprintf("container %s terminated with exit code %d", status.Name, status.State.Terminated.ExitCode)
+		}
+	}
+	return ""
+}
+
+// checkPodHealth is a fast path for pod failures. As soon as pod of the host starts failing or recovers,
+// health of the host is re-checked and the host is reported in CHI status, instead of waiting for full reconcile of the CHI
+func (w *worker) checkPodHealth(ctx context.Context, old, new *core.Pod) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	if (old == nil) || (new == nil) {
+		return
+	}
+
+	reason := podFailureReason(new)
+	if (reason == "") && isPodReady(old) && !isPodReady(new) && (new.DeletionTimestamp == nil) {
+		// Running pod lost readiness
+		reason = "pod is not ready"
+	}
+	failed := (reason != "") && (reason != podFailureReason(old))
+	recovered := isPodReady(new) && !isPodReady(old)
+	if !failed && !recovered {
+		// No transition
+		return
+	}
+
+	chi, err := w.createCHIFromObjectMeta(&new.ObjectMeta, false, model.NewNormalizerOptions())
+	if err != nil {
+		w.a.V(1).M(new).F().Info("unable to find CHI of pod %s/%s err: %v", new.Namespace, new.Name, err)
+		return
+	}
+	var host *api.ChiHost
+	hosts := make(map[string]bool)
+	chi.WalkHosts(func(h *api.ChiHost) error {
+		hosts[h.GetName()] = true
+		if model.CreatePodName(h) == new.Name {
+			host = h
+		}
+		return nil
+	})
+	if host == nil {
+		return
+	}
+
+	// Hosts listed as unhealthy, except the host and hosts no longer in the CHI
+	name := host.GetName()
+	listed := false
+	var unhealthy []string
+	for _, entry := range chi.EnsureStatus().GetUnhealthyHosts() {
+		entryHost := strings.SplitN(entry, ":", 2)[0]
+		switch {
+		case entryHost == name:
+			listed = true
+		case hosts[entryHost]:
+			unhealthy = append(unhealthy, entry)
+		}
+	}
+
+	w.newTask(chi)
+	switch {
+	case failed:
+		// ClickHouse may still be serving, in case pod fails due to another container
+		if err := w.ensureClusterSchemer(host).HostDeepCheck(ctx, host); err != nil {
+			reason += ", ClickHouse is not available"
+		} else {
+			reason += ", ClickHouse is available"
+		}
+		unhealthy = append(unhealthy, fmt.Sprintf("%s: %s", name, reason))
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonHostUnhealthy).
+			M(host).F().
+			Warning("Host %s is unhealthy: %s", name, reason)
+	case recovered && listed:
+		w.a.V(1).
+			WithEvent(chi, eventActionReconcile, eventReasonHostRecovered).
+			M(host).F().
+			Info("Host %s recovered", name)
+		// Pod may have got another IP, so users restricted by IPs of the CHI hosts are to be refreshed
+		w.refreshUsersIPs(ctx, chi)
+	default:
+		// Host recovered, but it was not reported as unhealthy, as happens on regular start
+		return
+	}
+
+	chi.EnsureStatus().SetUnhealthyHosts(unhealthy)
+	_ = w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		TolerateAbsence: true,
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			UnhealthyHosts: true,
+		},
+	})
+}
+
+// refreshUsersIPs reconciles users config of the CHI with the current IPs of the CHI pods
+func (w *worker) refreshUsersIPs(ctx context.Context, chi *api.ClickHouseInstallation) {
+	opts := model.NewNormalizerOptions()
+	opts.DefaultUserAdditionalIPs = w.c.getPodsIPs(chi)
+	normalized, err := w.createCHIFromObjectMeta(&chi.ObjectMeta, false, opts)
+	if err != nil {
+		w.a.M(chi).F().Error("unable to normalize CHI %s/%s err: %v", chi.Namespace, chi.Name, err)
+		return
+	}
+	w.reconcileCHIConfigMapUsers(ctx, normalized)
+}
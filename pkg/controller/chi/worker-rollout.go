@@ -0,0 +1,108 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"time"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// admitHostToRollout checks whether the host is allowed to be reconciled within the current window of staged rollout.
+// Hosts being modified are counted against the window, the rest of hosts are always admitted
+func (w *worker) admitHostToRollout(host *api.ChiHost) bool {
+	rollout := host.GetCHI().GetReconciling().GetRollout()
+	if !rollout.IsEnabled() || !host.GetReconcileAttributes().IsModify() {
+		return true
+	}
+	batch := rollout.GetBatchSize(host.GetCHI().HostsCount())
+	return host.GetCHI().EnsureStatus().CheckpointAdmitHost(host.GetName(), batch, rollout.GetWindow())
+}
+
+// holdHost skips reconcile of the host not admitted to the current window of staged rollout.
+// Host is left as is till the next window
+func (w *worker) holdHost(ctx context.Context, host *api.ChiHost) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	w.a.V(1).M(host).F().Info("Host %s is held till the next window of staged rollout, skip it", host.GetName())
+
+	if host.IsFirst() {
+		_ = w.reconcileCHIServiceFinal(ctx, host.CHI)
+	}
+}
+
+// isRolloutPaused checks whether the reconcile has hosts held by staged rollout
+func (w *worker) isRolloutPaused(chi *api.ClickHouseInstallation) bool {
+	if !chi.GetReconciling().GetRollout().IsEnabled() {
+		return false
+	}
+	checkpoint := chi.EnsureStatus().GetCheckpoint()
+	held := false
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		if host.GetReconcileAttributes().IsModify() && !checkpoint.HasHostCompleted(host.GetName()) {
+			held = true
+		}
+		return nil
+	})
+	return held
+}
+
+// pauseRollout pauses the reconcile till the next window of staged rollout.
+// Reconcile is not completed, so items scheduled for deletion are kept and the reconcile is resumed by maintenance
+func (w *worker) pauseRollout(ctx context.Context, chi *api.ClickHouseInstallation) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	chi.EnsureStatus().CheckpointPause(chi.GetReconciling().GetRollout().GetWindow())
+	_ = w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			MainFields: true,
+		},
+	})
+
+	checkpoint := chi.EnsureStatus().GetCheckpoint()
+	w.a.V(1).
+		WithEvent(chi, eventActionReconcile, eventReasonRolloutPaused).
+		WithStatusAction(chi).
+		M(chi).F().
+		Info("staged rollout paused, hosts completed: %d of %d, to be resumed at: %s",
+			len(checkpoint.GetHostsCompleted()), chi.HostsCount(), checkpoint.GetResumeAt())
+}
+
+// maintainRollout resumes the reconcile paused by staged rollout as soon as the next window starts
+func (w *worker) maintainRollout(ctx context.Context, chi *api.ClickHouseInstallation) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	if !chi.EnsureStatus().GetCheckpoint().IsResumable(time.Now()) {
+		return
+	}
+
+	w.a.V(1).
+		WithEvent(chi, eventActionReconcile, eventReasonRolloutResumed).
+		M(chi).F().
+		Info("staged rollout resumed")
+	w.c.enqueueObject(NewReconcileCHI(reconcileAdd, nil, chi))
+}
@@ -0,0 +1,119 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// isShuttingDown checks whether the operator is shutting down
+func (c *Controller) isShuttingDown() bool {
+	select {
+	case <-c.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// shutDown shuts the controller down gracefully.
+// Queues are closed, so no new items are processed, and reconciles in flight do not start new hosts.
+// Host being reconciled is completed, after that the worker itself marks the reconcile interrupted and
+// hands it off to the next operator instance via checkpoint. Returns as soon as all workers have stopped,
+// reconciles in flight are reported periodically meanwhile
+func (c *Controller) shutDown(workers *sync.WaitGroup) {
+	log.V(1).F().Info("ClickHouseInstallation controller: shutdown requested, hand off reconciles in flight")
+	close(c.shutdown)
+	for i := range c.queues {
+		c.queues[i].Close()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	for {
+		select {
+		case <-done:
+			log.V(1).F().Info("ClickHouseInstallation controller: all workers completed")
+			return
+		case <-time.After(shutdownReportPeriod):
+			c.inFlight.Range(func(key, _ any) bool {
+				log.V(1).F().Warning("ClickHouseInstallation controller: reconcile of %s is completing its host", key)
+				return true
+			})
+		}
+	}
+}
+
+// startInFlight registers the CHI as being reconciled
+func (c *Controller) startInFlight(chi *api.ClickHouseInstallation) {
+	c.inFlight.Store(util.NamespaceNameString(chi.ObjectMeta), chi)
+}
+
+// completeInFlight unregisters the CHI as being reconciled
+func (c *Controller) completeInFlight(chi *api.ClickHouseInstallation) {
+	c.inFlight.Delete(util.NamespaceNameString(chi.ObjectMeta))
+}
+
+// interruptReconcile marks the reconcile of the CHI interrupted by the operator shutdown.
+// Checkpoint of the reconcile is kept, so the next operator instance resumes the reconcile
+func (c *Controller) interruptReconcile(ctx context.Context, chi *api.ClickHouseInstallation) {
+	chi.EnsureStatus().ReconcileInterrupt()
+	metricsCHIReconcileProgressCompleted(chi)
+	_ = c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			MainFields: true,
+		},
+	})
+
+	checkpoint := chi.EnsureStatus().GetCheckpoint()
+	NewAnnouncer().WithController(c).
+		WithEvent(chi, eventActionReconcile, eventReasonReconcileInterrupted).
+		WithStatusAction(chi).
+		M(chi).F().
+		Warning("reconcile interrupted by operator shutdown, hosts completed: %d of %d, to be resumed by the next operator instance",
+			len(checkpoint.GetHostsCompleted()), chi.HostsCount())
+}
+
+// handOffHost skips reconcile of the host, since the operator is shutting down.
+// Host is left as is and is reconciled by the next operator instance
+func (w *worker) handOffHost(ctx context.Context, host *api.ChiHost) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	w.a.V(1).M(host).F().Info("Operator is shutting down, host %s is handed off to the next operator instance", host.GetName())
+}
+
+// handOffReconcile hands off the reconcile to the next operator instance, since the operator is shutting down.
+// Reconcile is not completed, so items scheduled for deletion are kept and the reconcile is resumed from checkpoint
+func (w *worker) handOffReconcile(ctx context.Context, chi *api.ClickHouseInstallation) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	w.c.interruptReconcile(ctx, chi)
+}
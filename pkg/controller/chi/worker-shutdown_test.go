@@ -0,0 +1,95 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+)
+
+func Test_ShutDown_WaitsForWorkers(t *testing.T) {
+	c := newTestController(t, nil, nil)
+
+	// Worker completes its host after shutdown is requested
+	hostCompleted := make(chan struct{})
+	var workers sync.WaitGroup
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		<-c.shutdown
+		<-hostCompleted
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		c.shutDown(&workers)
+		close(stopped)
+	}()
+
+	require.Eventually(t, c.isShuttingDown, time.Second, 10*time.Millisecond)
+	for i := range c.queues {
+		require.True(t, c.queues[i].Closed())
+	}
+	select {
+	case <-stopped:
+		require.Fail(t, "shutdown completed while worker is still running")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(hostCompleted)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		require.Fail(t, "shutdown not completed after worker has stopped")
+	}
+}
+
+func Test_HandOffReconcile(t *testing.T) {
+	chi := &api.ClickHouseInstallation{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace:  "test",
+			Name:       "events",
+			Generation: 2,
+		},
+		Status: &api.ChiStatus{
+			Status: api.StatusInProgress,
+			Checkpoint: &api.ChiReconcileCheckpoint{
+				Generation:     2,
+				HostsCompleted: []string{"chi-events-main-0-0"},
+			},
+		},
+	}
+	c := newTestController(t, nil, []runtime.Object{chi})
+	w := c.newTestWorker()
+	ctx := context.Background()
+
+	w.handOffReconcile(ctx, w.normalize(chi.DeepCopy()))
+
+	cur, err := c.chopClient.ClickhouseV1().ClickHouseInstallations("test").Get(ctx, "events", controller.NewGetOptions())
+	require.NoError(t, err)
+	require.Equal(t, api.StatusInterrupted, cur.Status.Status)
+	// Checkpoint is kept, so the next operator instance resumes the reconcile
+	require.Equal(t, []string{"chi-events-main-0-0"}, cur.Status.GetCheckpoint().GetHostsCompleted())
+}
@@ -0,0 +1,307 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// reconcileStandby reconciles standby hosts of all clusters of the CHI.
+// Standby hosts are reconciled after all hosts of the CHI, so schema is pre-created from hosts being in place
+func (w *worker) reconcileStandby(ctx context.Context, chi *api.ClickHouseInstallation) {
+	chi.WalkClusters(func(cluster *api.Cluster) error {
+		for _, host := range model.CreateStandbyHosts(cluster) {
+			if util.IsContextDone(ctx) {
+				log.V(2).Info("task is done")
+				return nil
+			}
+			if err := w.reconcileStandbyHost(ctx, host); err != nil {
+				w.a.V(1).M(host).F().Warning("unable to reconcile standby host %s of cluster %s err: %v", host.GetName(), cluster.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// reconcileStandbyHost reconciles ConfigMap, StatefulSet and Service of the standby host and pre-creates schema on it.
+// Standby host is never included into services and clusters, it is not counted in hosts of the CHI either
+func (w *worker) reconcileStandbyHost(ctx context.Context, host *api.ChiHost) error {
+	if err := w.reconcileHostConfigMap(ctx, host); err != nil {
+		return err
+	}
+
+	w.prepareHostStatefulSetWithStatus(ctx, host, false)
+	if err := w.reconcileStatefulSet(ctx, host, false); err != nil {
+		w.task.registryFailed.RegisterStatefulSet(host.DesiredStatefulSet.ObjectMeta)
+		return err
+	}
+	w.task.registryReconciled.RegisterStatefulSet(host.DesiredStatefulSet.ObjectMeta)
+	_ = w.reconcilePVCs(ctx, host, api.DesiredStatefulSet)
+
+	if err := w.reconcileHostService(ctx, host); err != nil {
+		return err
+	}
+
+	if host.IsStopped() {
+		return nil
+	}
+	return w.ensureClusterSchemer(host).HostCreateStandbyTables(ctx, host)
+}
+
+// maintainStandby checks nodes of hosts of clusters having standby hosts.
+// In case node of a host is lost, a ready standby host is promoted into the host.
+// One host is replaced per maintenance round
+func (w *worker) maintainStandby(ctx context.Context, chi, normalized *api.ClickHouseInstallation) {
+	if !chi.HasStandby() {
+		return
+	}
+
+	var lost *api.ChiHost
+	var lostNode string
+	normalized.WalkClusters(func(cluster *api.Cluster) error {
+		if (lost != nil) || (cluster.Standby.GetReplicas() == 0) {
+			return nil
+		}
+		cluster.WalkHosts(func(host *api.ChiHost) error {
+			if lost != nil {
+				return nil
+			}
+			node, isLost, err := w.findLostNode(ctx, cluster.Standby, host)
+			if err != nil {
+				w.a.V(1).M(host).F().Warning("unable to check node of host %s err: %v", host.GetName(), err)
+				return nil
+			}
+			if isLost {
+				lost, lostNode = host, node
+			}
+			return nil
+		})
+		return nil
+	})
+	if lost == nil {
+		return
+	}
+
+	standby := w.findReadyStandbyHost(lost.GetCluster())
+	if standby == nil {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonStandbyUnavailable).
+			M(lost).F().
+			Warning("Node %s of host %s is lost, no ready standby host is available in cluster %s", lostNode, lost.GetName(), lost.Address.ClusterName)
+		return
+	}
+
+	w.promoteStandbyHost(ctx, chi, lost, lostNode, standby)
+}
+
+// findLostNode checks whether node of the host is lost - either removed or not ready longer than node lost timeout
+func (w *worker) findLostNode(ctx context.Context, standby *api.ChiStandby, host *api.ChiHost) (nodeName string, lost bool, err error) {
+	pod, err := w.c.fetchPod(host)
+	if err != nil {
+		return "", false, err
+	}
+	if pod.Spec.NodeName == "" {
+		// Pod is not scheduled yet
+		return "", false, nil
+	}
+	node, err := w.c.kubeClient.CoreV1().Nodes().Get(ctx, pod.Spec.NodeName, controller.NewGetOptions())
+	if apiErrors.IsNotFound(err) {
+		// Node is removed from k8s cluster
+		return pod.Spec.NodeName, true, nil
+	}
+	if err != nil {
+		return pod.Spec.NodeName, false, err
+	}
+	return node.Name, standby.IsNodeLost(node), nil
+}
+
+// findReadyStandbyHost finds standby host of the cluster, pod of which is ready
+func (w *worker) findReadyStandbyHost(cluster *api.Cluster) *api.ChiHost {
+	for _, host := range model.CreateStandbyHosts(cluster) {
+		if pod, err := w.c.fetchPod(host); (err == nil) && isPodReady(pod) {
+			return host
+		}
+	}
+	return nil
+}
+
+// promoteStandbyHost promotes standby host into the host, node of which is lost.
+// StatefulSet of the lost host is deleted, StatefulSet of the standby host is re-created with macros of the host,
+// so the standby pod becomes a replica of the host, re-creates replicated tables and fetches data from other replicas
+func (w *worker) promoteStandbyHost(
+	ctx context.Context,
+	chi *api.ClickHouseInstallation,
+	lost *api.ChiHost,
+	lostNode string,
+	standby *api.ChiHost,
+) {
+	name := lost.GetName()
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonStandbyPromotionStarted).
+		M(lost).F().
+		Warning("Node %s of host %s is lost. Promoting standby host %s", lostNode, name, standby.GetName())
+
+	// Pod of the lost host may come back along with the node, it must not serve the host anymore.
+	// Pod on the lost node is not able to terminate gracefully, so StatefulSet is deleted right away
+	statefulSetName := model.CreateStatefulSetName(lost)
+	err := w.c.kubeClient.AppsV1().StatefulSets(lost.Address.Namespace).Delete(ctx, statefulSetName, controller.NewDeleteOptions())
+	if (err != nil) && !apiErrors.IsNotFound(err) {
+		w.failStandbyPromotion(chi, lost, fmt.Errorf("unable to delete StatefulSet %s err: %v", statefulSetName, err))
+		return
+	}
+
+	// Replica of the lost host is dropped on other replicas of the shard, so it is re-created by the promoted host
+	w.dropLostReplica(ctx, lost)
+
+	// Volumes of the lost host are not used anymore, the promoted host keeps volumes of the standby host.
+	// PVCs are deleted before volumes of the standby host are labeled as volumes of the host
+	retained := w.deleteLostHostPVCs(ctx, lost)
+
+	// Selector of StatefulSet is immutable, so StatefulSet of the standby host is to be re-created.
+	// Volumes of the standby host are kept
+	if err := w.c.deleteStatefulSet(ctx, standby); err != nil && !apiErrors.IsNotFound(err) {
+		w.failStandbyPromotion(chi, lost, fmt.Errorf("unable to delete StatefulSet of standby host %s err: %v", standby.GetName(), err))
+		return
+	}
+
+	// From now on the host is served by the standby host
+	chi.EnsureStatus().PushStandbyPromotion(api.ChiStandbyPromotion{
+		Cluster:      lost.Address.ClusterName,
+		Host:         name,
+		Standby:      standby.Address.ReplicaIndex,
+		Node:         lostNode,
+		PromotedAt:   time.Now().Format(time.RFC3339),
+		RetainedPVCs: retained,
+	})
+	if err := w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			StandbyPromotions: true,
+		},
+	}); err != nil {
+		w.failStandbyPromotion(chi, lost, fmt.Errorf("unable to record promotion err: %v", err))
+		return
+	}
+
+	normalized := w.normalize(chi)
+	host := normalized.FindHost(lost.Address.ClusterName, lost.Address.ShardName, name)
+	if host == nil {
+		w.failStandbyPromotion(chi, lost, fmt.Errorf("host is not found"))
+		return
+	}
+	if err := w.reconcilePromotedHost(ctx, host); err != nil {
+		w.failStandbyPromotion(chi, lost, err)
+		return
+	}
+
+	// Pool is replenished with a new standby host
+	w.reconcileStandby(ctx, normalized)
+
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonStandbyPromoted).
+		M(host).F().
+		Info("Standby host %s is promoted into host %s, StatefulSet %s", standby.GetName(), name, host.Address.StatefulSet)
+	if len(retained) > 0 {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonStandbyPromoted).
+			M(host).F().
+			Warning("PVCs of the lost host %s are retained by reclaim policy, delete them manually: %s", name, strings.Join(retained, ", "))
+	}
+}
+
+// deleteLostHostPVCs deletes PVCs of the lost host, which are allowed to be deleted by reclaim policy.
+// Returns names of PVCs retained
+func (w *worker) deleteLostHostPVCs(ctx context.Context, lost *api.ChiHost) (retained []string) {
+	namespace := lost.Address.Namespace
+	w.c.walkDiscoveredPVCs(lost, func(pvc *core.PersistentVolumeClaim) {
+		if !model.LostHostCanDeletePVC(lost, pvc.Name) {
+			retained = append(retained, pvc.Name)
+			return
+		}
+		err := w.c.kubeClient.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, pvc.Name, controller.NewDeleteOptions())
+		if (err != nil) && !apiErrors.IsNotFound(err) {
+			w.a.V(1).M(lost).F().Warning("unable to delete PVC %s/%s of the lost host err: %v", namespace, pvc.Name, err)
+			retained = append(retained, pvc.Name)
+			return
+		}
+		w.a.V(1).M(lost).F().Info("PVC %s/%s of the lost host is deleted", namespace, pvc.Name)
+	})
+	sort.Strings(retained)
+	return retained
+}
+
+// reconcilePromotedHost reconciles the host served by the promoted standby host and re-creates its replicated tables
+func (w *worker) reconcilePromotedHost(ctx context.Context, host *api.ChiHost) error {
+	w.newTask(host.GetCHI())
+
+	// FQDN of the host may be changed along with the pod
+	if err := w.reconcileCHIConfigMapCommon(ctx, host.GetCHI(), nil); err != nil {
+		return err
+	}
+	if err := w.reconcileHostConfigMap(ctx, host); err != nil {
+		return err
+	}
+	w.prepareHostStatefulSetWithStatus(ctx, host, false)
+	if err := w.reconcileStatefulSet(ctx, host, false); err != nil {
+		return err
+	}
+	// Volumes of the standby host are labeled as volumes of the host
+	_ = w.reconcilePVCs(ctx, host, api.DesiredStatefulSet)
+	if err := w.reconcileHostService(ctx, host); err != nil {
+		return err
+	}
+
+	if _, err := w.pollHostForClickHouseVersion(ctx, host); err != nil {
+		return err
+	}
+	if err := w.migrateTables(ctx, host, &migrateTableOptions{forceMigrate: true}); err != nil {
+		return err
+	}
+	_ = w.createReplicatedDatabases(ctx, host)
+	_ = w.createMonitoringSchema(ctx, host)
+	return w.includeHost(ctx, host)
+}
+
+// dropLostReplica drops replica of the lost host on the first available replica of the shard
+func (w *worker) dropLostReplica(ctx context.Context, lost *api.ChiHost) {
+	dropped := false
+	lost.GetShard().WalkHosts(func(replica *api.ChiHost) error {
+		if dropped || (replica.GetName() == lost.GetName()) {
+			return nil
+		}
+		if err := w.ensureClusterSchemer(replica).HostDropReplica(ctx, replica, lost); err != nil {
+			w.a.V(1).M(replica).F().Warning("unable to drop replica %s on host %s err: %v", lost.GetName(), replica.GetName(), err)
+			return nil
+		}
+		dropped = true
+		return nil
+	})
+}
+
+// failStandbyPromotion reports failed promotion of a standby host
+func (w *worker) failStandbyPromotion(chi *api.ClickHouseInstallation, host *api.ChiHost, err error) {
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonStandbyPromotionFailed).
+		WithStatusError(chi).
+		M(host).F().
+		Error("FAILED to promote standby host into host %s err: %v", host.GetName(), err)
+}
@@ -0,0 +1,237 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-sigs/yaml"
+	"github.com/stretchr/testify/require"
+
+	core "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// newStandbyTestCHI creates CHI with one standby host per cluster and two volume claim templates,
+// one of which is retained
+func newStandbyTestCHI(t *testing.T) *api.ClickHouseInstallation {
+	chi := &api.ClickHouseInstallation{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+metadata:
+  namespace: test
+  name: standby
+spec:
+  defaults:
+    templates:
+      dataVolumeClaimTemplate: data
+      logVolumeClaimTemplate: logs
+  configuration:
+    clusters:
+      - name: main
+        standby:
+          replicas: 1
+          nodeLostTimeout: 60
+        layout:
+          replicasCount: 2
+  templates:
+    volumeClaimTemplates:
+      - name: data
+        spec:
+          accessModes: [ReadWriteOnce]
+      - name: logs
+        reclaimPolicy: Retain
+        spec:
+          accessModes: [ReadWriteOnce]
+`), chi))
+	return chi
+}
+
+func newStandbyTestPod(host *api.ChiHost, nodeName string, ready bool) *core.Pod {
+	status := core.ConditionFalse
+	if ready {
+		status = core.ConditionTrue
+	}
+	return &core.Pod{
+		ObjectMeta: meta.ObjectMeta{Namespace: host.Address.Namespace, Name: model.CreatePodName(host)},
+		Spec:       core.PodSpec{NodeName: nodeName},
+		Status: core.PodStatus{
+			Conditions: []core.PodCondition{{Type: core.PodReady, Status: status}},
+		},
+	}
+}
+
+func newStandbyTestNode(name string, ready bool, since time.Duration) *core.Node {
+	status := core.ConditionFalse
+	if ready {
+		status = core.ConditionTrue
+	}
+	return &core.Node{
+		ObjectMeta: meta.ObjectMeta{Name: name},
+		Status: core.NodeStatus{
+			Conditions: []core.NodeCondition{{
+				Type:               core.NodeReady,
+				Status:             status,
+				LastTransitionTime: meta.NewTime(time.Now().Add(-since)),
+			}},
+		},
+	}
+}
+
+func Test_FindLostNode(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodeName string
+		node     *core.Node
+		lostNode string
+		lost     bool
+	}{
+		{
+			name: "pod is not scheduled",
+		},
+		{
+			name:     "node is ready",
+			nodeName: "node-1",
+			node:     newStandbyTestNode("node-1", true, time.Hour),
+			lostNode: "node-1",
+		},
+		{
+			name:     "node is not ready shorter than timeout",
+			nodeName: "node-1",
+			node:     newStandbyTestNode("node-1", false, 30*time.Second),
+			lostNode: "node-1",
+		},
+		{
+			name:     "node is not ready longer than timeout",
+			nodeName: "node-1",
+			node:     newStandbyTestNode("node-1", false, 2*time.Minute),
+			lostNode: "node-1",
+			lost:     true,
+		},
+		{
+			name:     "node is removed",
+			nodeName: "node-1",
+			lostNode: "node-1",
+			lost:     true,
+		},
+	}
+
+	host := newTestController(t, nil, nil).newTestWorker().normalize(newStandbyTestCHI(t)).FirstHost()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{newStandbyTestPod(host, tt.nodeName, true)}
+			if tt.node != nil {
+				objects = append(objects, tt.node)
+			}
+			w := newTestController(t, objects, nil).newTestWorker()
+
+			nodeName, lost, err := w.findLostNode(context.Background(), host.GetCluster().Standby, host)
+			require.NoError(t, err)
+			require.Equal(t, tt.lostNode, nodeName)
+			require.Equal(t, tt.lost, lost)
+		})
+	}
+}
+
+func Test_FindLostNode_NoPod(t *testing.T) {
+	c := newTestController(t, nil, nil)
+	w := c.newTestWorker()
+	host := w.normalize(newStandbyTestCHI(t)).FirstHost()
+
+	_, lost, err := w.findLostNode(context.Background(), host.GetCluster().Standby, host)
+	require.Error(t, err)
+	require.False(t, lost)
+}
+
+func Test_FindReadyStandbyHost(t *testing.T) {
+	c := newTestController(t, nil, nil)
+	w := c.newTestWorker()
+	cluster := w.normalize(newStandbyTestCHI(t)).FirstHost().GetCluster()
+	standby := model.CreateStandbyHosts(cluster)
+	require.Len(t, standby, 1)
+
+	require.Nil(t, w.findReadyStandbyHost(cluster), "no pod of standby host")
+
+	c = newTestController(t, []runtime.Object{newStandbyTestPod(standby[0], "node-2", false)}, nil)
+	w = c.newTestWorker()
+	require.Nil(t, w.findReadyStandbyHost(cluster), "pod of standby host is not ready")
+
+	c = newTestController(t, []runtime.Object{newStandbyTestPod(standby[0], "node-2", true)}, nil)
+	w = c.newTestWorker()
+	found := w.findReadyStandbyHost(cluster)
+	require.NotNil(t, found)
+	require.Equal(t, standby[0].GetName(), found.GetName())
+}
+
+func Test_DeleteLostHostPVCs(t *testing.T) {
+	c := newTestController(t, nil, nil)
+	w := c.newTestWorker()
+	normalized := w.normalize(newStandbyTestCHI(t))
+	lost := normalized.FirstHost()
+	var other *api.ChiHost
+	normalized.WalkHosts(func(host *api.ChiHost) error {
+		if host != lost {
+			other = host
+		}
+		return nil
+	})
+	require.NotNil(t, other)
+
+	newPVC := func(host *api.ChiHost, name string) *core.PersistentVolumeClaim {
+		return &core.PersistentVolumeClaim{
+			ObjectMeta: meta.ObjectMeta{
+				Namespace: host.Address.Namespace,
+				Name:      name,
+				Labels:    model.GetSelectorHostScope(host),
+			},
+		}
+	}
+	var data, logs, otherData string
+	normalized.WalkVolumeClaimTemplates(func(template *api.ChiVolumeClaimTemplate) {
+		switch template.Name {
+		case "data":
+			data = model.CreatePVCNameByVolumeClaimTemplate(lost, template)
+			otherData = model.CreatePVCNameByVolumeClaimTemplate(other, template)
+		case "logs":
+			logs = model.CreatePVCNameByVolumeClaimTemplate(lost, template)
+		}
+	})
+
+	c = newTestController(t, []runtime.Object{
+		newPVC(lost, data),
+		newPVC(lost, logs),
+		newPVC(lost, "unknown"),
+		newPVC(other, otherData),
+	}, nil)
+	w = c.newTestWorker()
+
+	retained := w.deleteLostHostPVCs(context.Background(), lost)
+	require.Equal(t, []string{logs, "unknown"}, retained)
+
+	pvcs := c.kubeClient.CoreV1().PersistentVolumeClaims(lost.Address.Namespace)
+	_, err := pvcs.Get(context.Background(), data, controller.NewGetOptions())
+	require.True(t, apiErrors.IsNotFound(err), "PVC of the lost host is deleted")
+	for _, name := range []string{logs, "unknown", otherData} {
+		_, err := pvcs.Get(context.Background(), name, controller.NewGetOptions())
+		require.NoError(t, err, name)
+	}
+}
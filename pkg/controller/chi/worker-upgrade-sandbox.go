@@ -0,0 +1,256 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/model/clickhouse"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// reconcileUpgradeVerification ensures upgrade verification of the CHI is started for the target image.
+// Returns false in case reconcile of the CHI has to be held until upgrade verification passes.
+func (w *worker) reconcileUpgradeVerification(ctx context.Context, chi *api.ClickHouseInstallation) bool {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return false
+	}
+
+	if model.IsUpgradeSandbox(chi) || !chi.Spec.UpgradeVerification.HasImage() {
+		// Nothing to verify
+		return true
+	}
+
+	image := chi.Spec.UpgradeVerification.GetImage()
+	if chi.EnsureStatus().GetUpgradeVerification().GetImage() != image {
+		// Target image is new, start verification
+		w.startUpgradeVerification(ctx, chi)
+	}
+
+	if !model.IsImageInUse(chi, image) {
+		// Upgrade to the target image is not requested yet, nothing to gate
+		return true
+	}
+
+	status := chi.EnsureStatus().GetUpgradeVerification()
+	if status.IsPassed(image) {
+		return true
+	}
+
+	w.a.V(1).
+		WithEvent(chi, eventActionReconcile, eventReasonUpgradeVerificationPending).
+		WithStatusAction(chi).
+		M(chi).F().
+		Warning("Upgrade to image %s is held until upgrade verification passes. Verification status: %s %s",
+			image, status.GetStatus(), status.GetError())
+	return false
+}
+
+// startUpgradeVerification creates or updates upgrade sandbox CHI for the target image
+func (w *worker) startUpgradeVerification(ctx context.Context, chi *api.ClickHouseInstallation) {
+	sandbox := model.CreateUpgradeSandboxCHI(chi)
+	status := &api.ChiUpgradeVerificationStatus{
+		Image:   chi.Spec.UpgradeVerification.GetImage(),
+		Sandbox: sandbox.Name,
+		Status:  api.UpgradeVerificationStatusInProgress,
+	}
+
+	if err := w.c.createOrUpdateUpgradeSandbox(ctx, sandbox); err == nil {
+		w.a.V(1).
+			WithEvent(chi, eventActionReconcile, eventReasonUpgradeVerificationStarted).
+			M(chi).F().
+			Info("Started upgrade verification of image %s in sandbox %s/%s", status.Image, sandbox.Namespace, sandbox.Name)
+	} else {
+		status.Status = api.UpgradeVerificationStatusFailed
+		status.Error = err.Error()
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonUpgradeVerificationFailed).
+			M(chi).F().
+			Error("FAILED to create upgrade sandbox %s/%s err: %v", sandbox.Namespace, sandbox.Name, err)
+	}
+
+	chi.EnsureStatus().SetUpgradeVerification(status)
+	_ = w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			UpgradeVerification: true,
+		},
+	})
+}
+
+// completeUpgradeVerification verifies reconciled upgrade sandbox and reports result to the CHI being verified.
+// Sandbox is deleted afterwards and reconcile of the CHI being verified is resumed.
+func (w *worker) completeUpgradeVerification(ctx context.Context, sandbox *api.ClickHouseInstallation, reconcileErr error) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	name, ok := model.GetUpgradeSandboxParentName(sandbox)
+	if !ok {
+		// Regular CHI
+		return
+	}
+
+	chi, err := w.c.GetCHIByObjectMeta(&meta.ObjectMeta{Namespace: sandbox.Namespace, Name: name}, true)
+	if err != nil {
+		w.a.M(sandbox).F().Error("unable to get CHI %s/%s verified by upgrade sandbox err: %v", sandbox.Namespace, name, err)
+		return
+	}
+
+	status := chi.EnsureStatus().GetUpgradeVerification()
+	switch {
+	case status.GetSandbox() != sandbox.Name:
+		return
+	case status.GetStatus() != api.UpgradeVerificationStatusInProgress:
+		return
+	case !model.IsImageInUse(sandbox, status.GetImage()):
+		// Sandbox is reconciled for outdated image, wait for the next reconcile
+		return
+	}
+
+	err = reconcileErr
+	if err == nil {
+		err = w.verifyUpgradeSandbox(ctx, w.normalize(chi), sandbox)
+	}
+
+	result := &api.ChiUpgradeVerificationStatus{
+		Image:   status.GetImage(),
+		Sandbox: sandbox.Name,
+		Status:  api.UpgradeVerificationStatusPassed,
+	}
+	if err == nil {
+		w.a.V(1).
+			WithEvent(chi, eventActionReconcile, eventReasonUpgradeVerificationPassed).
+			M(chi).F().
+			Info("Upgrade verification of image %s passed", result.Image)
+	} else {
+		result.Status = api.UpgradeVerificationStatusFailed
+		result.Error = err.Error()
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonUpgradeVerificationFailed).
+			M(chi).F().
+			Error("Upgrade verification of image %s FAILED err: %v", result.Image, err)
+	}
+
+	chi.EnsureStatus().SetUpgradeVerification(result)
+	_ = w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			UpgradeVerification: true,
+		},
+	})
+
+	// Sandbox is ephemeral
+	if err := w.c.deleteUpgradeSandbox(ctx, sandbox); err != nil {
+		w.a.M(sandbox).F().Error("FAILED to delete upgrade sandbox %s/%s err: %v", sandbox.Namespace, sandbox.Name, err)
+	}
+
+	// Resume reconcile of the CHI, which may be held by the verification
+	w.c.enqueueObject(NewReconcileCHI(reconcileAdd, nil, chi))
+}
+
+// verifyUpgradeSandbox restores schema-only snapshot of the CHI into the sandbox and runs verification queries
+func (w *worker) verifyUpgradeSandbox(ctx context.Context, chi, sandbox *api.ClickHouseInstallation) error {
+	// Each cluster of the sandbox receives schema of the first host of the same cluster of the CHI
+	var err error
+	sandbox.WalkClusters(func(cluster *api.Cluster) error {
+		if err != nil {
+			return nil
+		}
+		sourceCluster := chi.FindCluster(cluster.Name)
+		if sourceCluster == nil {
+			return nil
+		}
+		source := sourceCluster.FirstHost()
+		target := cluster.FirstHost()
+		if (source == nil) || (target == nil) {
+			return nil
+		}
+		_, sqls, e := w.ensureClusterSchemer(source).HostSchemaSnapshot(ctx, source)
+		if e != nil {
+			err = fmt.Errorf("unable to fetch schema snapshot of host %s: %v", source.GetName(), e)
+			return nil
+		}
+		if e := w.ensureClusterSchemer(target).HostRestoreSchemaSnapshot(ctx, target, sqls); e != nil {
+			err = fmt.Errorf("unable to restore schema snapshot on host %s: %v", target.GetName(), e)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	host := sandbox.FirstHost()
+	queries := chi.Spec.UpgradeVerification.GetQueries()
+	if (host == nil) || (len(queries) == 0) {
+		return nil
+	}
+
+	// Verification queries are run on the first host of the sandbox
+	w.a.V(1).M(sandbox).F().Info("Run verification queries on host %s: %v", host.GetName(), queries)
+	if e := w.ensureClusterSchemer(host).ExecHost(ctx, host, queries, clickhouse.NewQueryOptions().SetRetry(false)); e != nil {
+		return fmt.Errorf("verification query failed: %v", e)
+	}
+
+	return nil
+}
+
+// createOrUpdateUpgradeSandbox creates upgrade sandbox CHI or updates spec of the existing one.
+// Existing CHI is updated only in case it is a sandbox of the same CHI
+func (c *Controller) createOrUpdateUpgradeSandbox(ctx context.Context, sandbox *api.ClickHouseInstallation) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+
+	cur, err := c.chopClient.ClickhouseV1().ClickHouseInstallations(sandbox.Namespace).Get(ctx, sandbox.Name, controller.NewGetOptions())
+	if apiErrors.IsNotFound(err) {
+		_, err = c.chopClient.ClickhouseV1().ClickHouseInstallations(sandbox.Namespace).Create(ctx, sandbox, controller.NewCreateOptions())
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	expected, _ := model.GetUpgradeSandboxParentName(sandbox)
+	if parent, ok := model.GetUpgradeSandboxParentName(cur); !ok || (parent != expected) {
+		// Never take over CHI which is not a sandbox of the same CHI
+		return fmt.Errorf("CHI %s/%s exists and is not an upgrade sandbox of CHI %s", cur.Namespace, cur.Name, expected)
+	}
+
+	cur.Labels = util.MergeStringMapsOverwrite(cur.Labels, sandbox.Labels)
+	cur.Spec = sandbox.Spec
+	_, err = c.chopClient.ClickhouseV1().ClickHouseInstallations(sandbox.Namespace).Update(ctx, cur, controller.NewUpdateOptions())
+	return err
+}
+
+// deleteUpgradeSandbox deletes upgrade sandbox CHI
+func (c *Controller) deleteUpgradeSandbox(ctx context.Context, sandbox *api.ClickHouseInstallation) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+
+	err := c.chopClient.ClickhouseV1().ClickHouseInstallations(sandbox.Namespace).Delete(ctx, sandbox.Name, controller.NewDeleteOptions())
+	if apiErrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,60 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+func Test_CreateOrUpdateUpgradeSandbox(t *testing.T) {
+	chi := &api.ClickHouseInstallation{
+		ObjectMeta: meta.ObjectMeta{Namespace: "test", Name: "events", UID: "uid-1"},
+		Spec: api.ChiSpec{
+			UpgradeVerification: &api.ChiUpgradeVerification{Image: "clickhouse/clickhouse-server:24.3"},
+		},
+	}
+	c := newTestController(t, nil, nil)
+	sandbox := model.CreateUpgradeSandboxCHI(c.newTestWorker().normalize(chi))
+
+	// Foreign CHI of the same name is never taken over
+	foreign := &api.ClickHouseInstallation{
+		ObjectMeta: meta.ObjectMeta{Namespace: "test", Name: sandbox.Name},
+	}
+	c = newTestController(t, nil, []runtime.Object{foreign})
+	require.Error(t, c.createOrUpdateUpgradeSandbox(context.Background(), sandbox))
+	cur, err := c.chopClient.ClickhouseV1().ClickHouseInstallations("test").Get(context.Background(), sandbox.Name, controller.NewGetOptions())
+	require.NoError(t, err)
+	require.False(t, model.IsUpgradeSandbox(cur))
+
+	// Sandbox is created and then updated
+	c = newTestController(t, nil, nil)
+	require.NoError(t, c.createOrUpdateUpgradeSandbox(context.Background(), sandbox))
+	require.NoError(t, c.createOrUpdateUpgradeSandbox(context.Background(), sandbox))
+	cur, err = c.chopClient.ClickhouseV1().ClickHouseInstallations("test").Get(context.Background(), sandbox.Name, controller.NewGetOptions())
+	require.NoError(t, err)
+	parent, ok := model.GetUpgradeSandboxParentName(cur)
+	require.True(t, ok)
+	require.Equal(t, "events", parent)
+}
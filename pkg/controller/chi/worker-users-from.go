@@ -0,0 +1,149 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// usersFromReloadDelay specifies how long to wait after change of users sources before reloading users config,
+// so kubelet is able to propagate the change into the pods. Kubelet syncs projected volumes once a minute by default
+const usersFromReloadDelay = time.Minute
+
+// maintainUsersFrom tracks resource versions of ConfigMaps and Secrets users.d files are taken from.
+// As soon as any of them changes, users config is reloaded on all hosts of the CHI after the propagation delay
+func (w *worker) maintainUsersFrom(ctx context.Context, chi, normalized *api.ClickHouseInstallation) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return
+	}
+
+	sources := chi.Spec.Configuration.GetUsersFrom()
+	status := chi.EnsureStatus().GetUsersFrom()
+
+	if len(sources) == 0 {
+		// Sources are removed, just forget about them
+		if status != nil {
+			w.updateUsersFrom(ctx, chi, nil)
+		}
+		return
+	}
+
+	versions, err := w.getUsersFromVersions(ctx, chi, sources)
+	if err != nil {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonUsersFromUnavailable).
+			M(chi).F().
+			Warning("unable to get users sources err: %v", err)
+		return
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	switch {
+	case status == nil:
+		// Sources are tracked from now on, pods have them mounted already
+		w.updateUsersFrom(ctx, chi, &api.ChiUsersFromStatus{
+			Versions:   versions,
+			ChangedAt:  now,
+			ReloadedAt: now,
+		})
+	case !util.ArraysEqual(versions, status.GetVersions()):
+		w.a.V(1).
+			WithEvent(chi, eventActionReconcile, eventReasonUsersFromChanged).
+			M(chi).F().
+			Info("users sources changed: %v, users config is to be reloaded in %s", versions, usersFromReloadDelay)
+		// Sources changed outside of reconcile may introduce colliding files, kubelet stops updating the volume then
+		if collisions := model.FindUsersSourceCollisions(normalized, model.NewUsersSourceFiles(ctx, w.c.kubeClient, chi.Namespace)); len(collisions) > 0 {
+			w.a.WithEvent(chi, eventActionReconcile, eventReasonUsersFromCollision).
+				M(chi).F().
+				Warning("Files of users sources collide: %s", strings.Join(collisions, "; "))
+		}
+		status.Versions = versions
+		status.ChangedAt = now
+		w.updateUsersFrom(ctx, chi, status)
+	case status.IsReloadDue(time.Now(), usersFromReloadDelay):
+		if w.reloadUsersFrom(ctx, chi, normalized) {
+			status.ReloadedAt = now
+			w.updateUsersFrom(ctx, chi, status)
+		}
+	}
+}
+
+// getUsersFromVersions gets resource versions of users sources as kind/name:resourceVersion
+func (w *worker) getUsersFromVersions(ctx context.Context, chi *api.ClickHouseInstallation, sources []api.ChiUsersSource) ([]string, error) {
+	var versions []string
+	for _, source := range sources {
+		var resourceVersion string
+		switch {
+		case source.ConfigMapRef != nil:
+			configMap, err := w.c.kubeClient.CoreV1().ConfigMaps(chi.Namespace).Get(ctx, source.GetName(), controller.NewGetOptions())
+			if err != nil {
+				return nil, fmt.Errorf("unable to get %s err: %v", source, err)
+			}
+			resourceVersion = configMap.ResourceVersion
+		case source.SecretRef != nil:
+			secret, err := w.c.kubeClient.CoreV1().Secrets(chi.Namespace).Get(ctx, source.GetName(), controller.NewGetOptions())
+			if err != nil {
+				return nil, fmt.Errorf("unable to get %s err: %v", source, err)
+			}
+			resourceVersion = secret.ResourceVersion
+		default:
+			continue
+		}
+		versions = append(versions, source.String()+":"+resourceVersion)
+	}
+	return versions, nil
+}
+
+// reloadUsersFrom reloads users config on all hosts of the CHI.
+// Returns whether all hosts reloaded config, hosts failed to are retried by the next maintenance round
+func (w *worker) reloadUsersFrom(ctx context.Context, chi, normalized *api.ClickHouseInstallation) bool {
+	reloaded := true
+	normalized.WalkHosts(func(host *api.ChiHost) error {
+		if host.IsStopped() {
+			return nil
+		}
+		if err := w.ensureClusterSchemer(host).HostReloadConfig(ctx, host); err != nil {
+			w.a.V(1).M(host).F().Warning("unable to reload config of host %s err: %v", host.GetName(), err)
+			reloaded = false
+		}
+		return nil
+	})
+	if reloaded {
+		w.a.V(1).
+			WithEvent(chi, eventActionReconcile, eventReasonUsersFromReloaded).
+			M(chi).F().
+			Info("users config reloaded on all hosts")
+	}
+	return reloaded
+}
+
+// updateUsersFrom updates status of users sources
+func (w *worker) updateUsersFrom(ctx context.Context, chi *api.ClickHouseInstallation, usersFrom *api.ChiUsersFromStatus) {
+	chi.EnsureStatus().SetUsersFrom(usersFrom)
+	_ = w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
+		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
+			UsersFrom: true,
+		},
+	})
+}
@@ -0,0 +1,217 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// validateLayout validates layout of the normalized CHI against anti-patterns.
+// Returns false in case violations are found and validation policy denies reconcile,
+// as well as in case replicas of a shard use different keeper ensembles or custom clusters clash with generated ones,
+// which is never admitted
+func (w *worker) validateLayout(ctx context.Context, chi *api.ClickHouseInstallation) bool {
+	var nodes []core.Node
+	if list, err := w.c.kubeClient.CoreV1().Nodes().List(ctx, controller.NewListOptions()); err == nil {
+		nodes = list.Items
+	} else {
+		// Unable to check anti-affinity, check the rest of the rules
+		w.a.V(1).M(chi).F().Warning("unable to list nodes to validate layout err: %v", err)
+	}
+
+	if mismatches := model.FindZookeeperMismatches(chi); len(mismatches) > 0 {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonValidationFailed).
+			WithStatusError(chi).
+			M(chi).F().
+			Error("Keeper ensembles mismatch, reconcile denied: %s", strings.Join(mismatches, "; "))
+		return false
+	}
+
+	if clashes := model.FindRemoteClusterClashes(chi); len(clashes) > 0 {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonValidationFailed).
+			WithStatusError(chi).
+			M(chi).F().
+			Error("Custom clusters clash, reconcile denied: %s", strings.Join(clashes, "; "))
+		return false
+	}
+
+	if clashes := model.FindNodePortClashes(chi); len(clashes) > 0 {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonValidationFailed).
+			WithStatusAction(chi).
+			M(chi).F().
+			Warning("Ports of node network clash, each of these hosts requires node of its own: %s", strings.Join(clashes, "; "))
+	}
+
+	violations := model.ValidateLayout(chi, nodes)
+	if len(violations) == 0 {
+		return true
+	}
+
+	if chi.Spec.Validation.IsDeny() {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonValidationFailed).
+			WithStatusError(chi).
+			M(chi).F().
+			Error("Layout validation failed, reconcile denied: %s", strings.Join(violations, "; "))
+		return false
+	}
+
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonValidationFailed).
+		WithStatusAction(chi).
+		M(chi).F().
+		Warning("Layout validation failed: %s", strings.Join(violations, "; "))
+	return true
+}
+
+// validateNames checks names of objects generated for the CHI do not collide with each other
+// and with objects of another CHI or objects created not by the operator, which would be overwritten otherwise.
+// Returns false in case collisions are found
+func (w *worker) validateNames(ctx context.Context, chi *api.ClickHouseInstallation) bool {
+	lookup, err := model.NewObjectLookup(ctx, w.c.kubeClient, chi.Namespace)
+	if err != nil {
+		// Unable to check existing objects, check generated names only
+		w.a.V(1).M(chi).F().Warning("unable to list objects to validate names err: %v", err)
+	}
+
+	collisions := model.FindNameCollisions(chi, lookup)
+	if len(collisions) == 0 {
+		return true
+	}
+
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonNameCollision).
+		WithStatusError(chi).
+		M(chi).F().
+		Error("Names of objects collide, reconcile denied: %s", strings.Join(collisions, "; "))
+	return false
+}
+
+// validateUsersFrom checks files of users sources do not collide with each other and with users config files
+// generated by the operator, which would prevent projected users.d volume from being mounted.
+// Returns false in case collisions are found
+func (w *worker) validateUsersFrom(ctx context.Context, chi *api.ClickHouseInstallation) bool {
+	collisions := model.FindUsersSourceCollisions(chi, model.NewUsersSourceFiles(ctx, w.c.kubeClient, chi.Namespace))
+	if len(collisions) == 0 {
+		return true
+	}
+
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonUsersFromCollision).
+		WithStatusError(chi).
+		M(chi).F().
+		Error("Files of users sources collide, reconcile denied: %s", strings.Join(collisions, "; "))
+	return false
+}
+
+// validateTemplates reports templates referenced by hosts of the CHI, but not found,
+// so hosts fall back to default templates.
+// Returns false in case missing templates are found and strict templates validation is requested
+func (w *worker) validateTemplates(chi *api.ClickHouseInstallation) bool {
+	missing := model.FindMissingTemplates(chi)
+	chi.EnsureStatus().SetMissingTemplates(missing)
+	if len(missing) == 0 {
+		return true
+	}
+
+	if chi.Spec.Validation.IsStrictTemplates() {
+		w.a.WithEvent(chi, eventActionReconcile, eventReasonTemplateNotFound).
+			WithStatusError(chi).
+			M(chi).F().
+			Error("Referenced templates not found, reconcile denied: %s", strings.Join(missing, "; "))
+		return false
+	}
+
+	w.a.V(1).WithEvent(chi, eventActionReconcile, eventReasonTemplateNotFound).
+		WithStatusAction(chi).
+		M(chi).F().
+		Warning("Referenced templates not found, defaults are used instead: %s", strings.Join(missing, "; "))
+	return true
+}
+
+// reportMigrations reports deprecated fields of the CHI, which were migrated into the current layout,
+// so the manifest can be updated accordingly
+func (w *worker) reportMigrations(chi *api.ClickHouseInstallation) {
+	migrations := chi.EnsureStatus().GetMigrations()
+	if len(migrations) == 0 {
+		return
+	}
+
+	w.a.V(1).WithEvent(chi, eventActionReconcile, eventReasonDeprecatedFieldsMigrated).
+		WithStatusAction(chi).
+		M(chi).F().
+		Warning("Deprecated fields migrated, please update the manifest: %s", strings.Join(migrations, "; "))
+}
+
+// reportRejections reports invalid entries of the spec, which were rejected by the normalizer and are not applied
+func (w *worker) reportRejections(chi *api.ClickHouseInstallation) {
+	rejections := chi.EnsureStatus().GetRejections()
+	if len(rejections) == 0 {
+		return
+	}
+
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonValidationFailed).
+		WithStatusError(chi).
+		M(chi).F().
+		Error("Invalid entries rejected and not applied: %s", strings.Join(rejections, "; "))
+}
+
+// reportUnknownGuardUsers reports per-user guards of users not specified in the CHI, which are not applied
+func (w *worker) reportUnknownGuardUsers(chi *api.ClickHouseInstallation) {
+	unknown := model.FindUnknownGuardUsers(chi)
+	if len(unknown) == 0 {
+		return
+	}
+
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonValidationFailed).
+		WithStatusAction(chi).
+		M(chi).F().
+		Warning("Guards of unknown users are not applied: %s", strings.Join(unknown, ", "))
+}
+
+// reportEscapedValues reports settings, values of which are XML-escaped in the manifest already.
+// Such values are escaped by the operator once more and reach ClickHouse with entities literally
+func (w *worker) reportEscapedValues(chi *api.ClickHouseInstallation) {
+	escaped := model.FindEscapedValues(chi)
+	if len(escaped) == 0 {
+		return
+	}
+
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonValidationFailed).
+		WithStatusAction(chi).
+		M(chi).F().
+		Warning("Values are XML-escaped already and are escaped twice, replace entities with plain chars: %s",
+			strings.Join(escaped, ", "))
+}
+
+// reportFaultDomains reports placement of reconciled hosts, which does not tolerate loss of a fault domain,
+// along with recommendations on replica and keeper placement
+func (w *worker) reportFaultDomains(chi *api.ClickHouseInstallation) {
+	if len(chi.Spec.HostMacros.GetFaultDomainLabels()) == 0 {
+		// Fault domains are not known
+		return
+	}
+	recommendations := model.FindFaultDomainRecommendations(chi)
+	if len(recommendations) == 0 {
+		return
+	}
+	w.a.WithEvent(chi, eventActionReconcile, eventReasonFaultDomainRecommendation).
+		WithStatusAction(chi).
+		M(chi).F().
+		Warning("Placement does not tolerate loss of a fault domain: %s", strings.Join(recommendations, "; "))
+}
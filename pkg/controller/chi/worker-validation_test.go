@@ -0,0 +1,90 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kubernetes-sigs/yaml"
+	"github.com/stretchr/testify/require"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+)
+
+// newLayoutTestCHI creates CHI with replicated shard, replicas of which have to be on different nodes
+func newLayoutTestCHI(t *testing.T, action string) *api.ClickHouseInstallation {
+	chi := &api.ClickHouseInstallation{}
+	require.NoError(t, yaml.Unmarshal([]byte(fmt.Sprintf(`
+metadata:
+  namespace: test
+  name: layout
+spec:
+  validation:
+    action: %s
+  defaults:
+    templates:
+      podTemplate: pod
+  configuration:
+    clusters:
+      - name: main
+        layout:
+          replicasCount: 2
+  templates:
+    podTemplates:
+      - name: pod
+        podDistribution:
+          - type: ShardAntiAffinity
+`, action)), chi))
+	return chi
+}
+
+func Test_ValidateLayout(t *testing.T) {
+	node := func(name string) runtime.Object {
+		return &core.Node{ObjectMeta: meta.ObjectMeta{Name: name}}
+	}
+	violation := "shard 0 of cluster main requires ShardAntiAffinity over 2 nodes, but only 1 nodes are available"
+
+	tests := []struct {
+		name   string
+		action string
+		nodes  []runtime.Object
+		ok     bool
+		status string
+		err    string
+	}{
+		{name: "satisfied", action: api.ValidationActionDeny, nodes: []runtime.Object{node("a"), node("b")}, ok: true},
+		{name: "warn", action: api.ValidationActionWarn, nodes: []runtime.Object{node("a")}, ok: true,
+			status: "Layout validation failed: " + violation},
+		{name: "deny", action: api.ValidationActionDeny, nodes: []runtime.Object{node("a")},
+			err: "Layout validation failed, reconcile denied: " + violation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(t, tt.nodes, nil)
+			w := c.newTestWorker()
+			chi := w.normalize(newLayoutTestCHI(t, tt.action))
+
+			require.Equal(t, tt.ok, w.validateLayout(context.Background(), chi))
+			require.Equal(t, tt.status, chi.EnsureStatus().GetAction())
+			require.Equal(t, tt.err, chi.EnsureStatus().GetError())
+		})
+	}
+}
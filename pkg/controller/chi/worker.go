@@ -62,6 +62,10 @@ type task struct {
 	registryFailed     *model.Registry
 	cmUpdate           time.Time
 	start              time.Time
+	// apiWritesWaited is time API writes of the operator waited for the budget by the task start
+	apiWritesWaited time.Duration
+	// checkpoint is a checkpoint of the interrupted reconcile being resumed by the task
+	checkpoint *api.ChiReconcileCheckpoint
 }
 
 // newTask creates new context
@@ -72,6 +76,7 @@ func newTask(creator *model.Creator) task {
 		registryFailed:     model.NewRegistry(),
 		cmUpdate:           time.Time{},
 		start:              time.Now(),
+		apiWritesWaited:    chop.APIWritesWaited(),
 	}
 }
 
@@ -136,7 +141,7 @@ func (w *worker) shouldForceRestartHost(host *api.ChiHost) bool {
 
 	podIsCrushed := false
 	// pod.Status.ContainerStatuses[0].State.Waiting.Reason
-	if pod, err := w.c.getPod(host); err == nil {
+	if pod, err := w.c.fetchPod(host); err == nil {
 		if len(pod.Status.ContainerStatuses) > 0 {
 			if pod.Status.ContainerStatuses[0].State.Waiting != nil {
 				if pod.Status.ContainerStatuses[0].State.Waiting.Reason == "CrashLoopBackOff" {
@@ -262,9 +267,10 @@ func (w *worker) processReconcilePod(ctx context.Context, cmd *ReconcilePod) err
 		metricsPodAdd(ctx)
 		return nil
 	case reconcileUpdate:
-		//ignore
 		//w.a.V(1).M(cmd.new).F().Info("Update Pod. %s/%s", cmd.new.Namespace, cmd.new.Name)
 		//metricsPodUpdate(ctx)
+		w.refreshHostMacros(ctx, cmd.old, cmd.new)
+		w.checkPodHealth(ctx, cmd.old, cmd.new)
 		return nil
 	case reconcileDelete:
 		w.a.V(1).M(cmd.old).F().Info("Delete Pod. %s/%s", cmd.old.Namespace, cmd.old.Name)
@@ -311,6 +317,12 @@ func (w *worker) processItem(ctx context.Context, item interface{}) error {
 		return w.processReconcilePod(ctx, cmd)
 	case *DropDns:
 		return w.processDropDns(ctx, cmd)
+	case *MaintainCHI:
+		return w.processMaintainCHI(ctx, cmd)
+	case *RunOperation:
+		return w.processRunOperation(ctx, cmd)
+	case *RequestRestartHost:
+		return w.processRequestRestartHost(ctx, cmd)
 	}
 
 	// Unknown item type, don't know what to do with it
@@ -491,6 +503,12 @@ func (w *worker) isCleanRestart(chi *api.ClickHouseInstallation) bool {
 
 	log.V(1).Info("Operator just started. May be clean restart")
 
+	if chi.Status.GetStatus() == api.StatusInterrupted {
+		// Reconcile has been handed off by the previous operator instance, it is to be resumed
+		log.V(1).Info("CHI %s reconcile was interrupted by operator shutdown. Not a clean restart", chi.Name)
+		return false
+	}
+
 	// Migration support
 	// Do we have have previously completed CHI?
 	// In case no - this means that CHI has either not completed or we are migrating from
@@ -643,6 +661,9 @@ func (w *worker) markReconcileStart(ctx context.Context, chi *api.ClickHouseInst
 
 	// Write desired normalized CHI with initialized .Status, so it would be possible to monitor progress
 	chi.EnsureStatus().ReconcileStart(ap.GetRemovedHostsNum())
+	chi.EnsureStatus().ProgressStart(w.countHostsInScope(chi))
+	w.startCheckpoint(chi)
+	metricsCHIReconcileProgress(chi)
 	_ = w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
 		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
 			MainFields: true,
@@ -658,6 +679,19 @@ func (w *worker) markReconcileStart(ctx context.Context, chi *api.ClickHouseInst
 	w.a.V(2).M(chi).F().Info("action plan\n%s\n", ap.String())
 }
 
+// countHostsInScope counts hosts to be reconciled within reconcile scope of the CHI
+func (w *worker) countHostsInScope(chi *api.ClickHouseInstallation) int {
+	count := 0
+	scope := chi.GetReconciling().GetScope()
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		if scope.HasShard(host.Address.ClusterName, host.Address.ShardName) {
+			count++
+		}
+		return nil
+	})
+	return count
+}
+
 func (w *worker) finalizeReconcileAndMarkCompleted(ctx context.Context, _chi *api.ClickHouseInstallation) {
 	if util.IsContextDone(ctx) {
 		log.V(2).Info("task is done")
@@ -676,6 +710,7 @@ func (w *worker) finalizeReconcileAndMarkCompleted(ctx context.Context, _chi *ap
 			chi.SetAncestor(chi.GetTarget())
 			chi.SetTarget(nil)
 			chi.EnsureStatus().ReconcileComplete()
+			metricsCHIReconcileProgressCompleted(chi)
 			// TODO unify with update endpoints
 			w.newTask(chi)
 			w.reconcileCHIConfigMapUsers(ctx, chi)
@@ -711,6 +746,7 @@ func (w *worker) markReconcileCompletedUnsuccessfully(ctx context.Context, chi *
 	case errors.Is(err, errCRUDAbort):
 		chi.EnsureStatus().ReconcileAbort()
 	}
+	metricsCHIReconcileProgressCompleted(chi)
 	w.c.updateCHIObjectStatus(ctx, chi, UpdateCHIStatusOptions{
 		CopyCHIStatusOptions: api.CopyCHIStatusOptions{
 			MainFields: true,
@@ -938,6 +974,53 @@ func (w *worker) migrateTables(ctx context.Context, host *api.ChiHost, opts ...*
 	return err
 }
 
+// createReplicatedDatabases creates databases with Replicated engine, specified in the cluster, on the host
+func (w *worker) createReplicatedDatabases(ctx context.Context, host *api.ChiHost) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+
+	if host.IsStopped() || (len(host.GetCluster().ReplicatedDatabases) == 0) {
+		// Nothing to create
+		return nil
+	}
+
+	err := w.ensureClusterSchemer(host).HostCreateReplicatedDatabases(ctx, host)
+	if err != nil {
+		w.a.V(1).
+			WithEvent(host.GetCHI(), eventActionCreate, eventReasonCreateFailed).
+			WithStatusAction(host.GetCHI()).
+			M(host).F().
+			Error("ERROR create replicated databases on shard/host:%d/%d cluster:%s err:%v", host.Address.ShardIndex, host.Address.ReplicaIndex, host.Address.ClusterName, err)
+	}
+	return err
+}
+
+// createMonitoringSchema creates or upgrades monitoring schema on the host, in case it is enabled
+func (w *worker) createMonitoringSchema(ctx context.Context, host *api.ChiHost) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+
+	monitoring := host.GetCHI().Spec.Configuration.GetMonitoring()
+	if host.IsStopped() || !monitoring.IsSchemaEnabled() {
+		// Nothing to create
+		return nil
+	}
+
+	err := w.ensureClusterSchemer(host).HostCreateMonitoringSchema(ctx, host, monitoring.GetDatabase())
+	if err != nil {
+		w.a.V(1).
+			WithEvent(host.GetCHI(), eventActionCreate, eventReasonCreateFailed).
+			WithStatusAction(host.GetCHI()).
+			M(host).F().
+			Error("ERROR create monitoring schema on shard/host:%d/%d cluster:%s err:%v", host.Address.ShardIndex, host.Address.ReplicaIndex, host.Address.ClusterName, err)
+	}
+	return err
+}
+
 // shouldMigrateTables
 func (w *worker) shouldMigrateTables(host *api.ChiHost, opts ...*migrateTableOptions) bool {
 	o := NewMigrateTableOptionsArr(opts...).First()
@@ -965,6 +1048,20 @@ func (w *worker) shouldMigrateTables(host *api.ChiHost, opts ...*migrateTableOpt
 	return true
 }
 
+// isHostDataLost checks whether the host, listed as having tables created, has lost all of them,
+// as happens in case data volume is lost or recreated empty
+func (w *worker) isHostDataLost(ctx context.Context, host *api.ChiHost) bool {
+	if host.IsStopped() || !model.HostHasTablesCreated(host) {
+		return false
+	}
+	lost, err := w.ensureClusterSchemer(host).HostLostTablesNum(ctx, host)
+	if err != nil {
+		w.a.V(1).M(host).F().Warning("unable to check host %s for lost tables err: %v", host.GetName(), err)
+		return false
+	}
+	return lost > 0
+}
+
 // shouldDropTables
 func (w *worker) shouldDropReplica(host *api.ChiHost, opts ...*migrateTableOptions) bool {
 	o := NewMigrateTableOptionsArr(opts...).First()
@@ -1057,6 +1154,9 @@ func (w *worker) excludeHostFromService(ctx context.Context, host *api.ChiHost)
 
 	_ = w.c.deleteLabelReadyPod(ctx, host)
 	_ = w.c.deleteAnnotationReadyService(ctx, host)
+	if host.GetCHI().Spec.Defaults.IsReadinessGateEnabled() {
+		_ = w.c.setPodConditionReady(ctx, host, false, "host is excluded from service")
+	}
 	return nil
 }
 
@@ -1067,11 +1167,35 @@ func (w *worker) includeHostIntoService(ctx context.Context, host *api.ChiHost)
 		return nil
 	}
 
+	w.setHostReadinessGate(ctx, host)
 	_ = w.c.appendLabelReadyOnPod(ctx, host)
 	_ = w.c.appendAnnotationReadyOnService(ctx, host)
 	return nil
 }
 
+// setHostReadinessGate waits for the host to pass deep health check and sets readiness gate condition of its pod
+func (w *worker) setHostReadinessGate(ctx context.Context, host *api.ChiHost) {
+	if !host.GetCHI().Spec.Defaults.IsReadinessGateEnabled() {
+		return
+	}
+
+	var checkErr error
+	err := w.c.pollHost(ctx, host, nil, func(_ctx context.Context, host *api.ChiHost) bool {
+		checkErr = w.ensureClusterSchemer(host).HostDeepCheck(_ctx, host)
+		return checkErr == nil
+	})
+	if err == nil && checkErr == nil {
+		_ = w.c.setPodConditionReady(ctx, host, true, "host passed health check")
+		return
+	}
+
+	if checkErr == nil {
+		checkErr = err
+	}
+	w.a.V(1).M(host).F().Warning("host %s failed health check err: %v", host.GetName(), checkErr)
+	_ = w.c.setPodConditionReady(ctx, host, false, fmt.Sprintf("host failed health check: %v", checkErr))
+}
+
 // excludeHostFromClickHouseCluster excludes host from ClickHouse configuration
 func (w *worker) excludeHostFromClickHouseCluster(ctx context.Context, host *api.ChiHost) {
 	if util.IsContextDone(ctx) {
@@ -1164,6 +1288,13 @@ func (w *worker) shouldWaitExcludeHost(host *api.ChiHost) bool {
 		return false
 	}
 
+	if wait := host.GetCHI().GetReconciling().GetHost().GetWait().GetExclude(); wait.HasValue() {
+		w.a.V(1).
+			M(host).F().
+			Info("CHI's 'reconciling.host.wait.exclude' setting is used. host %d shard %d cluster %s", host.Address.ReplicaIndex, host.Address.ShardIndex, host.Address.ClusterName)
+		return wait.Value()
+	}
+
 	w.a.V(1).
 		M(host).F().
 		Info("fallback to operator's settings. host %d shard %d cluster %s", host.Address.ReplicaIndex, host.Address.ShardIndex, host.Address.ClusterName)
@@ -1178,6 +1309,12 @@ func (w *worker) shouldWaitQueries(host *api.ChiHost) bool {
 			M(host).F().
 			Info("No need to wait for queries to complete, host is a new one. Host/shard/cluster %d/%d/%s", host.Address.ReplicaIndex, host.Address.ShardIndex, host.Address.ClusterName)
 		return false
+	case host.GetCHI().GetReconciling().GetHost().GetWait().GetQueries().HasValue():
+		wait := host.GetCHI().GetReconciling().GetHost().GetWait().GetQueries().Value()
+		w.a.V(1).
+			M(host).F().
+			Info("Will wait for queries to complete: %t according to CHI 'reconciling.host.wait.queries' setting. Host/shard/cluster %d/%d/%s", wait, host.Address.ReplicaIndex, host.Address.ShardIndex, host.Address.ClusterName)
+		return wait
 	case chop.Config().Reconcile.Host.Wait.Queries.Value():
 		w.a.V(1).
 			M(host).F().
@@ -1214,6 +1351,9 @@ func (w *worker) shouldWaitIncludeHost(host *api.ChiHost) bool {
 	case host.GetCHI().GetReconciling().IsReconcilingPolicyNoWait():
 		// Check CHI settings - explicitly requested to not wait
 		return false
+	case host.GetCHI().GetReconciling().GetHost().GetWait().GetInclude().HasValue():
+		// Check CHI settings - explicitly specified wait behavior
+		return host.GetCHI().GetReconciling().GetHost().GetWait().GetInclude().Value()
 	}
 
 	// Fallback to operator's settings
@@ -1323,14 +1463,19 @@ func (w *worker) updateService(
 		return nil
 	}
 
-	if curService.Spec.Type != targetService.Spec.Type {
-		return fmt.Errorf("just recreate the service in case of service type change")
-	}
-
 	// Updating a Service is a complicated business
 
 	newService := targetService.DeepCopy()
 
+	// Fields excluded from management are migrated as they are
+	if preserved := model.PreserveUnmanagedServiceFields(newService, curService); len(preserved) > 0 {
+		w.reportUnmanaged(chi, "Service", &curService.ObjectMeta, preserved)
+	}
+
+	if curService.Spec.Type != newService.Spec.Type {
+		return fmt.Errorf("just recreate the service in case of service type change")
+	}
+
 	// spec.resourceVersion is required in order to update an object
 	newService.ResourceVersion = curService.ResourceVersion
 
@@ -1475,6 +1620,31 @@ func (w *worker) createSecret(ctx context.Context, chi *api.ClickHouseInstallati
 	return err
 }
 
+// updateSecret
+func (w *worker) updateSecret(ctx context.Context, chi *api.ClickHouseInstallation, secret *core.Secret) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("task is done")
+		return nil
+	}
+
+	_, err := w.c.kubeClient.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, controller.NewUpdateOptions())
+	if err == nil {
+		w.a.V(1).
+			WithEvent(chi, eventActionUpdate, eventReasonUpdateCompleted).
+			WithStatusAction(chi).
+			M(chi).F().
+			Info("Update Secret %s/%s", secret.Namespace, secret.Name)
+	} else {
+		w.a.WithEvent(chi, eventActionUpdate, eventReasonUpdateFailed).
+			WithStatusAction(chi).
+			WithStatusError(chi).
+			M(chi).F().
+			Error("Update Secret %s/%s failed with error %v", secret.Namespace, secret.Name, err)
+	}
+
+	return err
+}
+
 // getStatefulSetStatus gets StatefulSet status
 func (w *worker) getStatefulSetStatus(host *api.ChiHost) api.ObjectStatus {
 	meta := host.DesiredStatefulSet.ObjectMeta
@@ -0,0 +1,199 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chk
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	apiChk "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse-keeper.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chk"
+)
+
+const (
+	// annotationGeneration specifies generation of the CHK the StatefulSet's pod template is rolled out for
+	annotationGeneration = "clickhouse-keeper.altinity.com/generation"
+
+	// fourLetterWordTimeout is a timeout of four letter word command sent to an ensemble member
+	fourLetterWordTimeout = 5 * time.Second
+)
+
+// reconcileEnsemble reconciles ConfigMap and StatefulSet of the ensemble.
+// Ensemble is scaled one member at a time and pod template changes are rolled out one pod at a time,
+// each step being taken only with all members ready and quorum in place.
+// Returns true in case ensemble reached its desired state.
+func (r *ChkReconciler) reconcileEnsemble(chk *apiChk.ClickHouseKeeperInstallation) (bool, error) {
+	cur := &apps.StatefulSet{}
+	if err := r.Get(context.TODO(), getNamespacedName(chk), cur); err != nil {
+		if !apiErrors.IsNotFound(err) {
+			return false, err
+		}
+		// Brand new ensemble, nothing to be careful about
+		replicas := model.GetReplicasCount(chk)
+		if err := r.reconcileConfigMap(chk, replicas); err != nil {
+			return false, err
+		}
+		return true, r.reconcileStatefulSet(chk, replicas)
+	}
+
+	desired := int32(model.GetReplicasCount(chk))
+	current := getStatefulSetReplicas(cur)
+	generation := strconv.FormatInt(chk.GetGeneration(), 10)
+
+	switch {
+	case current != desired:
+		// Scale ensemble by one member
+		if !r.isQuorumHealthy(chk, cur) {
+			return false, nil
+		}
+		step := current + 1
+		if desired < current {
+			step = current - 1
+		}
+		log.V(1).M(chk).F().Info("Scale ensemble %s/%s from %d to %d members", chk.Namespace, chk.Name, current, step)
+		if err := r.reconcileConfigMap(chk, int(step)); err != nil {
+			return false, err
+		}
+		cur.Spec.Replicas = &step
+		return false, r.Update(context.TODO(), cur)
+
+	case cur.GetAnnotations()[annotationGeneration] != generation:
+		// Start rolling upgrade from the member with the highest ordinal
+		if !r.isQuorumHealthy(chk, cur) {
+			return false, nil
+		}
+		log.V(1).M(chk).F().Info("Start rolling upgrade of ensemble %s/%s", chk.Namespace, chk.Name)
+		if err := r.reconcileConfigMap(chk, int(desired)); err != nil {
+			return false, err
+		}
+		return false, r.reconcileStatefulSet(chk, int(desired))
+
+	case getStatefulSetPartition(cur) > 0:
+		// Proceed with rolling upgrade as soon as the last upgraded member has joined the quorum
+		partition := getStatefulSetPartition(cur)
+		if (cur.Status.ObservedGeneration != cur.Generation) ||
+			(cur.Status.UpdatedReplicas < current-partition) ||
+			!r.isQuorumHealthy(chk, cur) {
+			return false, nil
+		}
+		partition--
+		log.V(1).M(chk).F().Info("Upgrade member %d of ensemble %s/%s", partition, chk.Namespace, chk.Name)
+		cur.Spec.UpdateStrategy.RollingUpdate.Partition = &partition
+		return false, r.Update(context.TODO(), cur)
+
+	case (cur.Status.ObservedGeneration != cur.Generation) || (cur.Status.UpdatedReplicas < current):
+		// Last member is being upgraded
+		return false, nil
+	}
+
+	return r.isQuorumHealthy(chk, cur), nil
+}
+
+// getStatefulSetReplicas gets number of replicas of the StatefulSet
+func getStatefulSetReplicas(sts *apps.StatefulSet) int32 {
+	if sts.Spec.Replicas == nil {
+		return 1
+	}
+	return *sts.Spec.Replicas
+}
+
+// getStatefulSetPartition gets partition of the StatefulSet rolling update
+func getStatefulSetPartition(sts *apps.StatefulSet) int32 {
+	if (sts.Spec.UpdateStrategy.RollingUpdate == nil) || (sts.Spec.UpdateStrategy.RollingUpdate.Partition == nil) {
+		return 0
+	}
+	return *sts.Spec.UpdateStrategy.RollingUpdate.Partition
+}
+
+// isQuorumHealthy checks whether all members of the ensemble are ready and
+// there is a leader with all the rest of the members synced as followers
+func (r *ChkReconciler) isQuorumHealthy(chk *apiChk.ClickHouseKeeperInstallation, sts *apps.StatefulSet) bool {
+	replicas := int(getStatefulSetReplicas(sts))
+
+	ready, err := r.getReadyPods(chk)
+	if err != nil {
+		log.V(1).M(chk).F().Warning("unable to get ready members of ensemble %s/%s err: %v", chk.Namespace, chk.Name, err)
+		return false
+	}
+	if len(ready) < replicas {
+		log.V(1).M(chk).F().Info("Ensemble %s/%s has %d of %d members ready", chk.Namespace, chk.Name, len(ready), replicas)
+		return false
+	}
+
+	leaders := 0
+	for i := 0; i < replicas; i++ {
+		mntr, err := r.sendFourLetterWord(model.GetPodFQDN(chk, i), chk.Spec.GetClientPort(), "mntr")
+		if err != nil {
+			log.V(1).M(chk).F().Warning("unable to check member %d of ensemble %s/%s err: %v", i, chk.Namespace, chk.Name, err)
+			return false
+		}
+		switch mntr["zk_server_state"] {
+		case "standalone":
+			return replicas == 1
+		case "leader":
+			leaders++
+			if synced, _ := strconv.Atoi(mntr["zk_synced_followers"]); synced < replicas-1 {
+				log.V(1).M(chk).F().Info("Ensemble %s/%s has %d of %d followers synced", chk.Namespace, chk.Name, synced, replicas-1)
+				return false
+			}
+		case "follower":
+		default:
+			log.V(1).M(chk).F().Info("Member %d of ensemble %s/%s is not in quorum", i, chk.Namespace, chk.Name)
+			return false
+		}
+	}
+
+	return leaders == 1
+}
+
+// sendFourLetterWord sends four letter word command to the ensemble member and returns its key-value response
+func (r *ChkReconciler) sendFourLetterWord(host string, port int, cmd string) (map[string]string, error) {
+	if r.fourLetterWord != nil {
+		return r.fourLetterWord(host, port, cmd)
+	}
+	return fourLetterWord(host, port, cmd)
+}
+
+// fourLetterWord sends four letter word command over the network and returns its key-value response
+func fourLetterWord(host string, port int, cmd string) (map[string]string, error) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), fourLetterWordTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(fourLetterWordTimeout))
+	if _, err := fmt.Fprint(conn, cmd); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) == 2 {
+			res[fields[0]] = fields[1]
+		}
+	}
+	return res, scanner.Err()
+}
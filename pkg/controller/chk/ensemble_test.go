@@ -0,0 +1,257 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chk
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/kubernetes-sigs/yaml"
+	"github.com/stretchr/testify/require"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiMachinery "k8s.io/apimachinery/pkg/runtime"
+	clientGoScheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiChk "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse-keeper.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chk"
+)
+
+// newTestCHK creates CHK of the ensemble of specified size
+func newTestCHK(t *testing.T, replicas int, generation int64) *apiChk.ClickHouseKeeperInstallation {
+	chk := &apiChk.ClickHouseKeeperInstallation{}
+	require.NoError(t, yaml.Unmarshal([]byte(fmt.Sprintf(`
+apiVersion: clickhouse-keeper.altinity.com/v1
+kind: ClickHouseKeeperInstallation
+metadata:
+  name: keeper
+  namespace: test
+  uid: keeper-uid
+spec:
+  configuration:
+    clusters:
+      - name: keeper
+        layout:
+          replicasCount: %d
+`, replicas)), chk))
+	chk.Generation = generation
+	return chk
+}
+
+// newTestStatefulSet creates StatefulSet of the ensemble rolled out for the generation of the CHK
+func newTestStatefulSet(chk *apiChk.ClickHouseKeeperInstallation, replicas int32, generation int64) *apps.StatefulSet {
+	return &apps.StatefulSet{
+		ObjectMeta: meta.ObjectMeta{
+			Name:       chk.Name,
+			Namespace:  chk.Namespace,
+			Generation: 1,
+			Annotations: map[string]string{
+				annotationGeneration: strconv.FormatInt(generation, 10),
+			},
+		},
+		Spec: apps.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+		Status: apps.StatefulSetStatus{
+			ObservedGeneration: 1,
+			UpdatedReplicas:    replicas,
+		},
+	}
+}
+
+// newTestPods creates pods of the ensemble members, ready or not
+func newTestPods(chk *apiChk.ClickHouseKeeperInstallation, ready ...bool) []client.Object {
+	var pods []client.Object
+	for i, isReady := range ready {
+		pods = append(pods, &core.Pod{
+			ObjectMeta: meta.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", chk.Name, i),
+				Namespace: chk.Namespace,
+				Labels:    model.GetPodLabels(chk),
+			},
+			Status: core.PodStatus{
+				ContainerStatuses: []core.ContainerStatus{{Ready: isReady}},
+			},
+		})
+	}
+	return pods
+}
+
+// healthyQuorum makes four letter word responses of the ensemble of specified size with the first member being a leader
+func healthyQuorum(chk *apiChk.ClickHouseKeeperInstallation, replicas int) map[string]map[string]string {
+	res := make(map[string]map[string]string)
+	for i := 0; i < replicas; i++ {
+		res[model.GetPodFQDN(chk, i)] = map[string]string{"zk_server_state": "follower"}
+	}
+	res[model.GetPodFQDN(chk, 0)] = map[string]string{
+		"zk_server_state":     "leader",
+		"zk_synced_followers": strconv.Itoa(replicas - 1),
+	}
+	if replicas == 1 {
+		res[model.GetPodFQDN(chk, 0)] = map[string]string{"zk_server_state": "standalone"}
+	}
+	return res
+}
+
+func newTestReconciler(t *testing.T, mntr map[string]map[string]string, objs ...client.Object) *ChkReconciler {
+	scheme := apiMachinery.NewScheme()
+	require.NoError(t, clientGoScheme.AddToScheme(scheme))
+	require.NoError(t, apiChk.AddToScheme(scheme))
+	return &ChkReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build(),
+		Scheme: scheme,
+		fourLetterWord: func(host string, port int, cmd string) (map[string]string, error) {
+			res, ok := mntr[host]
+			if !ok {
+				return nil, fmt.Errorf("%s is unreachable", host)
+			}
+			return res, nil
+		},
+	}
+}
+
+func getTestStatefulSet(t *testing.T, r *ChkReconciler, chk *apiChk.ClickHouseKeeperInstallation) *apps.StatefulSet {
+	sts := &apps.StatefulSet{}
+	require.NoError(t, r.Get(context.TODO(), getNamespacedName(chk), sts))
+	return sts
+}
+
+func Test_ReconcileEnsemble_New(t *testing.T) {
+	chk := newTestCHK(t, 3, 1)
+	r := newTestReconciler(t, nil, chk)
+
+	completed, err := r.reconcileEnsemble(chk)
+	require.NoError(t, err)
+	require.True(t, completed)
+
+	sts := getTestStatefulSet(t, r, chk)
+	require.Equal(t, int32(3), *sts.Spec.Replicas)
+	require.Equal(t, "1", sts.GetAnnotations()[annotationGeneration])
+	require.NoError(t, r.Get(context.TODO(), getNamespacedName(chk), &core.ConfigMap{}))
+}
+
+func Test_ReconcileEnsemble_Scale(t *testing.T) {
+	tests := []struct {
+		name    string
+		current int32
+		desired int
+		ready   []bool
+		want    int32
+	}{
+		{"scale up by one member", 3, 5, []bool{true, true, true}, 4},
+		{"scale down by one member", 5, 3, []bool{true, true, true, true, true}, 4},
+		{"wait for members to be ready", 3, 5, []bool{true, false, true}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chk := newTestCHK(t, tt.desired, 2)
+			objs := append(newTestPods(chk, tt.ready...), chk, newTestStatefulSet(chk, tt.current, 2))
+			r := newTestReconciler(t, healthyQuorum(chk, int(tt.current)), objs...)
+
+			completed, err := r.reconcileEnsemble(chk)
+			require.NoError(t, err)
+			require.False(t, completed)
+			require.Equal(t, tt.want, *getTestStatefulSet(t, r, chk).Spec.Replicas)
+		})
+	}
+}
+
+func Test_ReconcileEnsemble_RollingUpgrade(t *testing.T) {
+	chk := newTestCHK(t, 3, 3)
+	sts := newTestStatefulSet(chk, 3, 2)
+	r := newTestReconciler(t, healthyQuorum(chk, 3), append(newTestPods(chk, true, true, true), chk, sts)...)
+
+	// Rolling upgrade starts from the member with the highest ordinal
+	completed, err := r.reconcileEnsemble(chk)
+	require.NoError(t, err)
+	require.False(t, completed)
+	sts = getTestStatefulSet(t, r, chk)
+	require.Equal(t, "3", sts.GetAnnotations()[annotationGeneration])
+	require.Equal(t, int32(2), getStatefulSetPartition(sts))
+
+	// Next member is not upgraded until the StatefulSet controller has rolled out the previous one
+	sts.Status.ObservedGeneration = sts.Generation
+	sts.Status.UpdatedReplicas = 0
+	require.NoError(t, r.Status().Update(context.TODO(), sts))
+	completed, err = r.reconcileEnsemble(chk)
+	require.NoError(t, err)
+	require.False(t, completed)
+	require.Equal(t, int32(2), getStatefulSetPartition(getTestStatefulSet(t, r, chk)))
+
+	// Upgraded member has joined the quorum, proceed with the next one
+	sts = getTestStatefulSet(t, r, chk)
+	sts.Status.UpdatedReplicas = 1
+	require.NoError(t, r.Status().Update(context.TODO(), sts))
+	completed, err = r.reconcileEnsemble(chk)
+	require.NoError(t, err)
+	require.False(t, completed)
+	require.Equal(t, int32(1), getStatefulSetPartition(getTestStatefulSet(t, r, chk)))
+}
+
+func Test_ReconcileEnsemble_Completed(t *testing.T) {
+	chk := newTestCHK(t, 3, 2)
+	objs := append(newTestPods(chk, true, true, true), chk, newTestStatefulSet(chk, 3, 2))
+
+	r := newTestReconciler(t, healthyQuorum(chk, 3), objs...)
+	completed, err := r.reconcileEnsemble(chk)
+	require.NoError(t, err)
+	require.True(t, completed)
+
+	// The last upgraded member is not in quorum yet
+	r = newTestReconciler(t, healthyQuorum(chk, 2), objs...)
+	completed, err = r.reconcileEnsemble(chk)
+	require.NoError(t, err)
+	require.False(t, completed)
+}
+
+func Test_IsQuorumHealthy(t *testing.T) {
+	chk := newTestCHK(t, 3, 1)
+	leader := func(synced int) map[string]string {
+		return map[string]string{"zk_server_state": "leader", "zk_synced_followers": strconv.Itoa(synced)}
+	}
+	follower := map[string]string{"zk_server_state": "follower"}
+	tests := []struct {
+		name     string
+		replicas int32
+		ready    []bool
+		states   []map[string]string
+		want     bool
+	}{
+		{"standalone member", 1, []bool{true}, []map[string]string{{"zk_server_state": "standalone"}}, true},
+		{"leader with synced followers", 3, []bool{true, true, true}, []map[string]string{follower, leader(2), follower}, true},
+		{"member not ready", 3, []bool{true, true, false}, []map[string]string{follower, leader(2), follower}, false},
+		{"follower not synced", 3, []bool{true, true, true}, []map[string]string{follower, leader(1), follower}, false},
+		{"no leader", 3, []bool{true, true, true}, []map[string]string{follower, follower, follower}, false},
+		{"two leaders", 3, []bool{true, true, true}, []map[string]string{leader(2), leader(2), follower}, false},
+		{"member out of quorum", 3, []bool{true, true, true}, []map[string]string{follower, leader(2), {}}, false},
+		{"member unreachable", 3, []bool{true, true, true}, []map[string]string{follower, leader(2)}, false},
+		{"standalone member of ensemble", 3, []bool{true, true, true}, []map[string]string{{"zk_server_state": "standalone"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mntr := make(map[string]map[string]string)
+			for i, state := range tt.states {
+				mntr[model.GetPodFQDN(chk, i)] = state
+			}
+			r := newTestReconciler(t, mntr, newTestPods(chk, tt.ready...)...)
+			require.Equal(t, tt.want, r.isQuorumHealthy(chk, newTestStatefulSet(chk, tt.replicas, 1)))
+		})
+	}
+}
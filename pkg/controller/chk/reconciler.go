@@ -17,6 +17,8 @@ package chk
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	apps "k8s.io/api/apps/v1"
@@ -44,6 +46,9 @@ const ReconcileTime = 30 * time.Second
 type ChkReconciler struct {
 	client.Client
 	Scheme *apiMachinery.Scheme
+
+	// fourLetterWord sends four letter word command to an ensemble member, over the network in case not specified
+	fourLetterWord func(host string, port int, cmd string) (map[string]string, error)
 }
 
 type reconcileFunc func(cluster *apiChk.ClickHouseKeeperInstallation) error
@@ -89,10 +94,17 @@ func (r *ChkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, nil
 	}
 
+	if violations := model.ValidateLayout(new); len(violations) > 0 {
+		if new.Spec.Validation.IsDeny() {
+			log.V(1).M(new).F().Error("Layout validation failed, reconcile denied. CHK: %s/%s %s", new.Namespace, new.Name, strings.Join(violations, "; "))
+			return ctrl.Result{}, nil
+		}
+		log.V(1).M(new).F().Warning("Layout validation failed. CHK: %s/%s %s", new.Namespace, new.Name, strings.Join(violations, "; "))
+	}
+
+	completed := true
 	if old.GetGeneration() != new.GetGeneration() {
 		for _, f := range []reconcileFunc{
-			r.reconcileConfigMap,
-			r.reconcileStatefulSet,
 			r.reconcileClientService,
 			r.reconcileHeadlessService,
 			r.reconcilePodDisruptionBudget,
@@ -102,6 +114,12 @@ func (r *ChkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 				return reconcile.Result{}, err
 			}
 		}
+
+		var err error
+		if completed, err = r.reconcileEnsemble(new); err != nil {
+			log.V(1).Error("Error during reconcile. f: %s err: %s", getFunctionName(r.reconcileEnsemble), err)
+			return reconcile.Result{}, err
+		}
 	}
 
 	// Fetch the ClickHouseKeeper instance
@@ -117,19 +135,24 @@ func (r *ChkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	if err := r.reconcileClusterStatus(new); err != nil {
+	if err := r.reconcileClusterStatus(new, completed); err != nil {
 		log.V(1).Error("Error during reconcile status. f: %s err: %s", getFunctionName(r.reconcileClusterStatus), err)
 		return reconcile.Result{}, err
 	}
 
+	if !completed {
+		// Ensemble is being reconfigured step by step, come back for the next step
+		return ctrl.Result{RequeueAfter: ReconcileTime}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
-func (r *ChkReconciler) reconcileConfigMap(chk *apiChk.ClickHouseKeeperInstallation) error {
+func (r *ChkReconciler) reconcileConfigMap(chk *apiChk.ClickHouseKeeperInstallation, replicas int) error {
 	return r.reconcile(
 		chk,
 		&core.ConfigMap{},
-		model.CreateConfigMap(chk),
+		model.CreateConfigMap(chk, replicas),
 		"ConfigMap",
 		func(curObject, newObject client.Object) error {
 			cur, ok1 := curObject.(*core.ConfigMap)
@@ -144,11 +167,21 @@ func (r *ChkReconciler) reconcileConfigMap(chk *apiChk.ClickHouseKeeperInstallat
 	)
 }
 
-func (r *ChkReconciler) reconcileStatefulSet(chk *apiChk.ClickHouseKeeperInstallation) error {
+// reconcileStatefulSet reconciles StatefulSet with specified number of replicas.
+// Pod template of the existing StatefulSet is rolled out starting with the pod with the highest ordinal only,
+// the rest of the pods are rolled out by the ensemble reconcile step by step.
+func (r *ChkReconciler) reconcileStatefulSet(chk *apiChk.ClickHouseKeeperInstallation, replicas int) error {
+	sts := model.CreateStatefulSet(chk)
+	_replicas := int32(replicas)
+	sts.Spec.Replicas = &_replicas
+	sts.SetAnnotations(map[string]string{
+		annotationGeneration: strconv.FormatInt(chk.GetGeneration(), 10),
+	})
+
 	return r.reconcile(
 		chk,
 		&apps.StatefulSet{},
-		model.CreateStatefulSet(chk),
+		sts,
 		"StatefulSet",
 		func(curObject, newObject client.Object) error {
 			cur, ok1 := curObject.(*apps.StatefulSet)
@@ -157,9 +190,14 @@ func (r *ChkReconciler) reconcileStatefulSet(chk *apiChk.ClickHouseKeeperInstall
 				return fmt.Errorf("unable to cast")
 			}
 			markPodRestartedNow(new)
+			partition := *new.Spec.Replicas - 1
 			cur.Spec.Replicas = new.Spec.Replicas
 			cur.Spec.Template = new.Spec.Template
 			cur.Spec.UpdateStrategy = new.Spec.UpdateStrategy
+			cur.Spec.UpdateStrategy.RollingUpdate = &apps.RollingUpdateStatefulSetStrategy{
+				Partition: &partition,
+			}
+			cur.SetAnnotations(util.MergeStringMapsOverwrite(cur.GetAnnotations(), new.GetAnnotations()))
 			return nil
 		},
 	)
@@ -250,7 +288,10 @@ func (r *ChkReconciler) reconcile(
 	return nil
 }
 
-func (r *ChkReconciler) reconcileClusterStatus(chk *apiChk.ClickHouseKeeperInstallation) (err error) {
+// reconcileClusterStatus reconciles status of the CHK.
+// CHK is remembered as completed only in case ensemble has reached its desired state,
+// otherwise the next reconcile continues ensemble reconfiguration.
+func (r *ChkReconciler) reconcileClusterStatus(chk *apiChk.ClickHouseKeeperInstallation, completed bool) (err error) {
 	readyMembers, err := r.getReadyPods(chk)
 	if err != nil {
 		return err
@@ -281,16 +322,21 @@ func (r *ChkReconciler) reconcileClusterStatus(chk *apiChk.ClickHouseKeeperInsta
 
 		log.V(2).Info("ReadyReplicas: " + fmt.Sprintf("%v", cur.Status.ReadyReplicas))
 
-		if len(readyMembers) == model.GetReplicasCount(chk) {
-			cur.Status.Status = "Completed"
-		} else {
-			cur.Status.Status = "In progress"
+		switch {
+		case !completed:
+			cur.Status.Status = apiChk.StatusReconfiguring
+		case len(readyMembers) == model.GetReplicasCount(chk):
+			cur.Status.Status = apiChk.StatusCompleted
+		default:
+			cur.Status.Status = apiChk.StatusInProgress
 		}
 
-		cur.Status.NormalizedCHK = nil
-		cur.Status.NormalizedCHKCompleted = chk.DeepCopy()
-		cur.Status.NormalizedCHKCompleted.ObjectMeta.ManagedFields = nil
-		cur.Status.NormalizedCHKCompleted.Status = nil
+		if completed {
+			cur.Status.NormalizedCHK = nil
+			cur.Status.NormalizedCHKCompleted = chk.DeepCopy()
+			cur.Status.NormalizedCHKCompleted.ObjectMeta.ManagedFields = nil
+			cur.Status.NormalizedCHKCompleted.Status = nil
+		}
 
 		if err := r.Status().Update(context.TODO(), cur); err != nil {
 			log.V(1).Error("err: %s", err.Error())
@@ -0,0 +1,74 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chk
+
+import (
+	"context"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiChk "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse-keeper.altinity.com/v1"
+	apiChi "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+)
+
+// IsReconfiguring checks whether any of the zookeeper nodes, as specified for ClickHouse in the namespace,
+// is served by an operator-managed ensemble being scaled or rolling-upgraded, as reported by status of its CHK.
+// CHKs are listed with specified list options
+func IsReconfiguring(
+	ctx context.Context,
+	reader client.Reader,
+	namespace string,
+	zookeeper *apiChi.ChiZookeeperConfig,
+	opts ...client.ListOption,
+) (bool, error) {
+	if zookeeper.IsEmpty() {
+		return false, nil
+	}
+
+	list := &apiChk.ClickHouseKeeperInstallationList{}
+	if err := reader.List(ctx, list, opts...); err != nil {
+		return false, err
+	}
+	for i := range list.Items {
+		chk := &list.Items[i]
+		if !chk.IsReconfiguring() {
+			continue
+		}
+		for _, node := range zookeeper.Nodes {
+			if serves(chk, namespace, node.Host) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// serves checks whether host, as specified in the namespace, points to either client service or member of the ensemble
+func serves(chk *apiChk.ClickHouseKeeperInstallation, namespace, host string) bool {
+	switch {
+	case host == chk.Name:
+		// Client service in the same namespace
+		return namespace == chk.Namespace
+	case strings.HasPrefix(host, chk.Name+"."+chk.Namespace):
+		// Client service FQDN
+		return true
+	case strings.HasPrefix(host, chk.Name+"-") && strings.Contains(host, "."+chk.Name+"-headless"):
+		// Member FQDN
+		return strings.Contains(host, "."+chk.Name+"-headless."+chk.Namespace) ||
+			(strings.HasSuffix(host, "."+chk.Name+"-headless") && (namespace == chk.Namespace))
+	}
+	return false
+}
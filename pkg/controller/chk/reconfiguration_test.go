@@ -0,0 +1,67 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiChk "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse-keeper.altinity.com/v1"
+	apiChi "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+)
+
+func Test_IsReconfiguring(t *testing.T) {
+	zookeeper := func(hosts ...string) *apiChi.ChiZookeeperConfig {
+		config := &apiChi.ChiZookeeperConfig{}
+		for _, host := range hosts {
+			config.Nodes = append(config.Nodes, apiChi.ChiZookeeperNode{Host: host, Port: 2181})
+		}
+		return config
+	}
+	tests := []struct {
+		name      string
+		status    string
+		namespace string
+		zookeeper *apiChi.ChiZookeeperConfig
+		want      bool
+	}{
+		{"client service", apiChk.StatusReconfiguring, "test", zookeeper("keeper"), true},
+		{"client service of another namespace", apiChk.StatusReconfiguring, "other", zookeeper("keeper"), false},
+		{"client service FQDN", apiChk.StatusReconfiguring, "other", zookeeper("keeper.test.svc.cluster.local"), true},
+		{"member FQDN", apiChk.StatusReconfiguring, "other", zookeeper("zk", "keeper-1.keeper-headless.test.svc.cluster.local"), true},
+		{"another ensemble", apiChk.StatusReconfiguring, "test", zookeeper("zookeeper"), false},
+		{"completed ensemble", apiChk.StatusCompleted, "test", zookeeper("keeper"), false},
+		{"no zookeeper", apiChk.StatusReconfiguring, "test", zookeeper(), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chk := newTestCHK(t, 3, 1)
+			chk.Status = &apiChk.ChkStatus{Status: tt.status}
+			r := newTestReconciler(t, nil, chk)
+
+			reconfiguring, err := IsReconfiguring(context.TODO(), r, tt.namespace, tt.zookeeper)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, reconfiguring)
+
+			// CHKs beyond the listed namespace are not taken into account
+			reconfiguring, err = IsReconfiguring(context.TODO(), r, tt.namespace, tt.zookeeper, client.InNamespace("other"))
+			require.NoError(t, err)
+			require.False(t, reconfiguring)
+		})
+	}
+}
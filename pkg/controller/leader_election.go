@@ -0,0 +1,131 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+)
+
+// Leader election lease timings
+const (
+	leaderElectionLeaseDuration = 15 * time.Second
+	leaderElectionRenewDeadline = 10 * time.Second
+	leaderElectionRetryPeriod   = 2 * time.Second
+)
+
+// ErrLeadershipLost specifies error of the leadership being lost while the leader is still running
+var ErrLeadershipLost = fmt.Errorf("leadership lost")
+
+// RunLeading runs the function as soon as the operator instance becomes the leader, as recorded by the lease.
+// Function is expected to return as soon as the context is done. Leadership is released explicitly only after
+// the function has returned, so the next operator instance does not take over while the function is still
+// shutting down. Returns ErrLeadershipLost in case leadership is lost before the context is done,
+// the function may still be running then.
+func RunLeading(
+	ctx context.Context,
+	kubeClient kube.Interface,
+	namespace string,
+	lease string,
+	identity string,
+	run func(ctx context.Context),
+) error {
+	// Leader election runs with its own context, which is cancelled as soon as leadership is to be released
+	leaderCtx, release := context.WithCancel(context.Background())
+	defer release()
+
+	var mu sync.Mutex
+	started := false
+	completed := false
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: meta.ObjectMeta{
+				Name:      lease,
+				Namespace: namespace,
+			},
+			Client: kubeClient.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: identity,
+			},
+		},
+		ReleaseOnCancel: true,
+		LeaseDuration:   leaderElectionLeaseDuration,
+		RenewDeadline:   leaderElectionRenewDeadline,
+		RetryPeriod:     leaderElectionRetryPeriod,
+		Name:            lease,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				mu.Lock()
+				if ctx.Err() != nil {
+					// Shutdown is requested as leadership has been acquired, nothing to run
+					mu.Unlock()
+					release()
+					return
+				}
+				started = true
+				mu.Unlock()
+
+				log.V(1).F().Info("%s became the leader of %s/%s", identity, namespace, lease)
+				run(ctx)
+
+				mu.Lock()
+				completed = true
+				mu.Unlock()
+				log.V(1).F().Info("%s releases leadership of %s/%s", identity, namespace, lease)
+				release()
+			},
+			OnStoppedLeading: func() {
+				log.V(1).F().Info("%s is not the leader of %s/%s", identity, namespace, lease)
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Leadership is not awaited any longer in case shutdown is requested before leadership is acquired
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-leaderCtx.Done():
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if !started {
+			release()
+		}
+	}()
+
+	log.V(1).F().Info("%s waits for leadership of %s/%s", identity, namespace, lease)
+	elector.Run(leaderCtx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if started && !completed {
+		return ErrLeadershipLost
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	coordination "k8s.io/api/coordination/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func getTestLease(t *testing.T, kubeClient *fake.Clientset) *coordination.Lease {
+	lease, err := kubeClient.CoordinationV1().Leases("test").Get(context.TODO(), "clickhouse-operator", meta.GetOptions{})
+	require.NoError(t, err)
+	return lease
+}
+
+func Test_RunLeading(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stopping := make(chan struct{})
+	stopped := make(chan struct{})
+	result := make(chan error)
+	go func() {
+		result <- RunLeading(ctx, kubeClient, "test", "clickhouse-operator", "operator-1", func(ctx context.Context) {
+			<-ctx.Done()
+			// Leadership is kept while the function is shutting down
+			close(stopping)
+			<-stopped
+		})
+	}()
+
+	require.Eventually(t, func() bool {
+		lease, err := kubeClient.CoordinationV1().Leases("test").Get(context.TODO(), "clickhouse-operator", meta.GetOptions{})
+		return (err == nil) && (*lease.Spec.HolderIdentity == "operator-1")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopping
+	require.Equal(t, "operator-1", *getTestLease(t, kubeClient).Spec.HolderIdentity)
+
+	// Leadership is released explicitly as soon as the function has returned
+	close(stopped)
+	require.NoError(t, <-result)
+	require.Empty(t, *getTestLease(t, kubeClient).Spec.HolderIdentity)
+}
+
+func Test_RunLeading_NotLeader(t *testing.T) {
+	holder := "operator-2"
+	duration := int32(60)
+	now := meta.NewMicroTime(time.Now())
+	kubeClient := fake.NewSimpleClientset(&coordination.Lease{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      "clickhouse-operator",
+			Namespace: "test",
+		},
+		Spec: coordination.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &duration,
+			AcquireTime:          &now,
+			RenewTime:            &now,
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// Shutdown requested while another operator instance is the leader, function is not run
+	err := RunLeading(ctx, kubeClient, "test", "clickhouse-operator", "operator-1", func(ctx context.Context) {
+		t.Error("function is run without leadership")
+	})
+	require.NoError(t, err)
+	require.Equal(t, "operator-2", *getTestLease(t, kubeClient).Spec.HolderIdentity)
+}
@@ -65,6 +65,21 @@ func (o *PollerOptions) FromConfig(config *api.OperatorConfig) *PollerOptions {
 	return o
 }
 
+// FromCHI overrides poll options with reconcile tunables of the CHI, in case specified
+func (o *PollerOptions) FromCHI(chi *api.ClickHouseInstallation) *PollerOptions {
+	if o == nil {
+		return nil
+	}
+	update := chi.GetReconciling().GetStatefulSet().GetUpdate()
+	if timeout := update.GetTimeout(); timeout > 0 {
+		o.Timeout = timeout
+	}
+	if interval := update.GetPollInterval(); interval > 0 {
+		o.MainInterval = interval
+	}
+	return o
+}
+
 // SetCreateTimeout sets create timeout
 func (o *PollerOptions) SetGetErrorTimeout(timeout time.Duration) *PollerOptions {
 	if o == nil {
@@ -0,0 +1,177 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// pingQuery specifies query the ClickHouse driver pings server with
+const pingQuery = "select 1"
+
+// FakeResult is a result of a query served by the fake ClickHouse
+type FakeResult struct {
+	// Names specifies names of the columns
+	Names []string
+	// Types specifies ClickHouse types of the columns
+	Types []string
+	// Rows specifies rows of values of the columns
+	Rows [][]string
+}
+
+// NewFakeScalar creates result of a single value of the specified ClickHouse type
+func NewFakeScalar(value, _type string) *FakeResult {
+	return &FakeResult{
+		Names: []string{"result"},
+		Types: []string{_type},
+		Rows:  [][]string{{value}},
+	}
+}
+
+// NewFakeEmptyResult creates result without rows
+func NewFakeEmptyResult() *FakeResult {
+	return &FakeResult{
+		Names: []string{"result"},
+		Types: []string{"String"},
+	}
+}
+
+// tsv formats result as TabSeparatedWithNamesAndTypes, requested by the operator
+func (r *FakeResult) tsv() string {
+	b := &strings.Builder{}
+	b.WriteString(strings.Join(r.Names, "\t") + "\n")
+	b.WriteString(strings.Join(r.Types, "\t") + "\n")
+	for _, row := range r.Rows {
+		b.WriteString(strings.Join(row, "\t") + "\n")
+	}
+	return b.String()
+}
+
+// fakeResponse is a response to queries matching the pattern
+type fakeResponse struct {
+	pattern *regexp.Regexp
+	result  *FakeResult
+	err     string
+}
+
+// FakeClickHouse is a mock ClickHouse HTTP endpoint all hosts of CHIs are served by.
+// Queries are answered by responses registered for matching patterns, last registered response wins.
+// Queries not matching any pattern are answered by empty result
+type FakeClickHouse struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	responses []*fakeResponse
+	queries   []string
+}
+
+// NewFakeClickHouse starts fake ClickHouse, answering ping and version queries by default
+func NewFakeClickHouse() *FakeClickHouse {
+	f := &FakeClickHouse{}
+	f.Respond(`^SELECT 1$`, NewFakeScalar("1", "UInt8"))
+	f.Respond(`^SELECT version\(\)$`, NewFakeScalar("23.8.1.1", "String"))
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+// Respond registers result the queries matching the pattern are answered by
+func (f *FakeClickHouse) Respond(pattern string, result *FakeResult) *FakeClickHouse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses = append(f.responses, &fakeResponse{
+		pattern: regexp.MustCompile(pattern),
+		result:  result,
+	})
+	return f
+}
+
+// Fail registers error the queries matching the pattern are failed with
+func (f *FakeClickHouse) Fail(pattern, message string) *FakeClickHouse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses = append(f.responses, &fakeResponse{
+		pattern: regexp.MustCompile(pattern),
+		err:     message,
+	})
+	return f
+}
+
+// Queries lists queries received so far
+func (f *FakeClickHouse) Queries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.queries...)
+}
+
+// Address gets host and port the fake ClickHouse listens on
+func (f *FakeClickHouse) Address() (string, int) {
+	host, port, _ := net.SplitHostPort(f.server.Listener.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return host, p
+}
+
+// Close stops the fake ClickHouse
+func (f *FakeClickHouse) Close() {
+	f.server.Close()
+}
+
+// serve answers query of the request
+func (f *FakeClickHouse) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := strings.TrimSpace(string(body))
+	if query == "" {
+		query = strings.TrimSpace(r.URL.Query().Get("query"))
+	}
+
+	if query == pingQuery {
+		_, _ = fmt.Fprintln(w, "1")
+		return
+	}
+
+	response := f.find(query)
+	switch {
+	case response == nil:
+		_, _ = io.WriteString(w, NewFakeEmptyResult().tsv())
+	case response.err != "":
+		http.Error(w, response.err, http.StatusInternalServerError)
+	default:
+		_, _ = io.WriteString(w, response.result.tsv())
+	}
+}
+
+// find records the query and finds response to it
+func (f *FakeClickHouse) find(query string) *fakeResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, query)
+	for i := len(f.responses) - 1; i >= 0; i-- {
+		if f.responses[i].pattern.MatchString(query) {
+			return f.responses[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/altinity/clickhouse-operator/pkg/model/clickhouse"
+)
+
+func Test_FakeClickHouse(t *testing.T) {
+	fake := NewFakeClickHouse()
+	defer fake.Close()
+
+	host, port := fake.Address()
+	clickhouse.SetEndpointResolver(func(string, int) (string, int) {
+		return host, port
+	})
+	defer clickhouse.SetEndpointResolver(nil)
+
+	cluster := clickhouse.NewCluster().SetHosts([]string{"chi-test-cluster-0-0"})
+	cluster.ClusterConnectionParams = clickhouse.NewClusterConnectionParams("http", "", "", "", 8123)
+	ctx := context.Background()
+
+	// Defaults
+	query, err := cluster.QueryAny(ctx, "SELECT version()")
+	require.NoError(t, err)
+	version, err := query.String()
+	require.NoError(t, err)
+	require.Equal(t, "23.8.1.1", version)
+
+	// Last registered response wins
+	fake.Respond(`system\.replicas`, NewFakeScalar("3", "UInt64"))
+	fake.Respond(`system\.replicas`, NewFakeScalar("5", "UInt64"))
+	query, err = cluster.QueryAny(ctx, "SELECT count() FROM system.replicas")
+	require.NoError(t, err)
+	num, err := query.Int()
+	require.NoError(t, err)
+	require.Equal(t, 5, num)
+
+	// Failures
+	fake.Fail(`system\.disks`, "Code: 999. DB::Exception: fake failure")
+	_, err = cluster.QueryAny(ctx, "SELECT sum(total_space) FROM system.disks")
+	require.Error(t, err)
+
+	require.Contains(t, fake.Queries(), "SELECT count() FROM system.replicas")
+}
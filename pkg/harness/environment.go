@@ -0,0 +1,186 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apiExtensions "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	kubeInformers "k8s.io/client-go/informers"
+	kube "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	chopClientSet "github.com/altinity/clickhouse-operator/pkg/client/clientset/versioned"
+	chopInformers "github.com/altinity/clickhouse-operator/pkg/client/informers/externalversions"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	"github.com/altinity/clickhouse-operator/pkg/controller/chi"
+	"github.com/altinity/clickhouse-operator/pkg/model/clickhouse"
+)
+
+const (
+	// informerResyncPeriod specifies resync period of informers of the operator under test
+	informerResyncPeriod = 30 * time.Second
+	// pollInterval specifies how often conditions are polled for
+	pollInterval = time.Second
+)
+
+// Options specifies how the environment is set up
+type Options struct {
+	// CRDPaths specifies files and folders CRDs are installed from, typically deploy/operator/parts
+	CRDPaths []string
+	// ChopConfigFile specifies operator config file. Operator defaults are used unless specified
+	ChopConfigFile string
+	// SimulateReadiness specifies whether StatefulSets of CHIs are to be reported ready along with their pods,
+	// since there is neither controller-manager nor kubelet running StatefulSets within the environment
+	SimulateReadiness bool
+}
+
+// Environment is an operator running against local API server, started by envtest, with hosts served by fake ClickHouse.
+// API server binaries are looked up by envtest, typically via KUBEBUILDER_ASSETS env var
+type Environment struct {
+	// Config specifies config of the API server
+	Config *rest.Config
+	// KubeClient is a client of the API server
+	KubeClient kube.Interface
+	// ExtClient is a client of API extensions of the API server
+	ExtClient apiExtensions.Interface
+	// ChopClient is a client of operator's resources of the API server
+	ChopClient chopClientSet.Interface
+	// ClickHouse is the fake ClickHouse all hosts are served by
+	ClickHouse *FakeClickHouse
+
+	testEnv *envtest.Environment
+	cancel  context.CancelFunc
+}
+
+// Start starts API server, fake ClickHouse and the operator
+func Start(opts Options) (*Environment, error) {
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     opts.CRDPaths,
+		ErrorIfCRDPathMissing: len(opts.CRDPaths) > 0,
+	}
+	config, err := testEnv.Start()
+	if err != nil {
+		return nil, fmt.Errorf("unable to start API server: %v", err)
+	}
+
+	e := &Environment{
+		Config:     config,
+		ClickHouse: NewFakeClickHouse(),
+		testEnv:    testEnv,
+	}
+	if err := e.run(opts); err != nil {
+		_ = e.Stop()
+		return nil, err
+	}
+	return e, nil
+}
+
+// run starts the operator
+func (e *Environment) run(opts Options) error {
+	kubeClient, err := kube.NewForConfig(e.Config)
+	if err != nil {
+		return err
+	}
+	extClient, err := apiExtensions.NewForConfig(e.Config)
+	if err != nil {
+		return err
+	}
+	chopClient, err := chopClientSet.NewForConfig(e.Config)
+	if err != nil {
+		return err
+	}
+	e.KubeClient, e.ExtClient, e.ChopClient = kubeClient, extClient, chopClient
+
+	// All hosts are served by the fake ClickHouse
+	host, port := e.ClickHouse.Address()
+	clickhouse.SetEndpointResolver(func(string, int) (string, int) {
+		return host, port
+	})
+
+	chop.New(kubeClient, chopClient, opts.ChopConfigFile)
+
+	kubeInformerFactory := kubeInformers.NewSharedInformerFactoryWithOptions(
+		kubeClient,
+		informerResyncPeriod,
+		kubeInformers.WithNamespace(chop.Config().GetInformerNamespace()),
+	)
+	chopInformerFactory := chopInformers.NewSharedInformerFactoryWithOptions(
+		chopClient,
+		informerResyncPeriod,
+		chopInformers.WithNamespace(chop.Config().GetInformerNamespace()),
+	)
+	chiController := chi.NewController(chopClient, extClient, kubeClient, chopInformerFactory, kubeInformerFactory)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	kubeInformerFactory.Start(ctx.Done())
+	chopInformerFactory.Start(ctx.Done())
+	go chiController.Run(ctx)
+	if opts.SimulateReadiness {
+		go e.simulateReadiness(ctx)
+	}
+	return nil
+}
+
+// Stop stops the operator, fake ClickHouse and API server
+func (e *Environment) Stop() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	clickhouse.SetEndpointResolver(nil)
+	e.ClickHouse.Close()
+	return e.testEnv.Stop()
+}
+
+// CreateCHI creates CHI
+func (e *Environment) CreateCHI(ctx context.Context, chi *api.ClickHouseInstallation) error {
+	_, err := e.ChopClient.ClickhouseV1().ClickHouseInstallations(chi.Namespace).Create(ctx, chi, controller.NewCreateOptions())
+	return err
+}
+
+// WaitCHI waits for the CHI to satisfy the condition till context is done
+func (e *Environment) WaitCHI(
+	ctx context.Context,
+	namespace, name string,
+	condition func(*api.ClickHouseInstallation) bool,
+) (*api.ClickHouseInstallation, error) {
+	for {
+		chi, err := e.ChopClient.ClickhouseV1().ClickHouseInstallations(namespace).Get(ctx, name, controller.NewGetOptions())
+		if (err == nil) && condition(chi) {
+			return chi, nil
+		}
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = ctx.Err()
+			}
+			return chi, fmt.Errorf("CHI %s/%s did not satisfy the condition: %v", namespace, name, err)
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// WaitCHICompleted waits for the CHI reconcile to be completed
+func (e *Environment) WaitCHICompleted(ctx context.Context, namespace, name string) (*api.ClickHouseInstallation, error) {
+	return e.WaitCHI(ctx, namespace, name, func(chi *api.ClickHouseInstallation) bool {
+		return chi.Status.GetStatus() == api.StatusCompleted
+	})
+}
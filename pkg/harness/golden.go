@@ -0,0 +1,49 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// UpdateGoldenEnv specifies env var, which makes golden files to be re-written with actual content
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// GoldenPath gets path of the golden file by its name. Golden files are kept in testdata of the package under test
+func GoldenPath(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// AssertGolden compares actual content, say generated config file, with snapshot of it kept in the golden file.
+// In case UPDATE_GOLDEN env var is set, golden file is re-written with actual content instead,
+// so intended changes of generated content are reviewed as a diff of golden files
+func AssertGolden(t testing.TB, name string, actual string) {
+	t.Helper()
+
+	path := GoldenPath(name)
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(actual), 0644))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "golden file %s is not readable, run tests with %s=1 to create it", path, UpdateGoldenEnv)
+	require.Equal(t, string(expected), actual, "content differs from golden file %s, run tests with %s=1 to update it", path, UpdateGoldenEnv)
+}
@@ -0,0 +1,155 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package harness
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// simulateReadiness reports StatefulSets of CHIs ready along with their pods till context is done
+func (e *Environment) simulateReadiness(ctx context.Context) {
+	for {
+		if err := e.SimulateReadiness(ctx); err != nil {
+			log.V(1).F().Warning("unable to simulate readiness err: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// SimulateReadiness does what controller-manager and kubelet would do for StatefulSets of CHIs:
+// creates their pods, reports pods running and StatefulSets ready with the current generation
+func (e *Environment) SimulateReadiness(ctx context.Context) error {
+	list, err := e.KubeClient.AppsV1().StatefulSets("").List(ctx, controller.NewListOptions(map[string]string{
+		model.LabelAppName: model.LabelAppValue,
+	}))
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		statefulSet := &list.Items[i]
+		if err := e.simulatePod(ctx, statefulSet); err != nil {
+			return err
+		}
+		if err := e.simulateStatefulSetStatus(ctx, statefulSet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// simulatePod creates the only pod of the StatefulSet, or deletes it in case StatefulSet is scaled down to zero
+func (e *Environment) simulatePod(ctx context.Context, statefulSet *apps.StatefulSet) error {
+	pods := e.KubeClient.CoreV1().Pods(statefulSet.Namespace)
+	name := fmt.Sprintf("%s-0", statefulSet.Name)
+
+	if getReplicas(statefulSet) == 0 {
+		err := pods.Delete(ctx, name, controller.NewDeleteOptions())
+		if apiErrors.IsNotFound(err) {
+			err = nil
+		}
+		return err
+	}
+
+	pod, err := pods.Get(ctx, name, controller.NewGetOptions())
+	if apiErrors.IsNotFound(err) {
+		template := statefulSet.Spec.Template.DeepCopy()
+		pod = &core.Pod{
+			ObjectMeta: template.ObjectMeta,
+			Spec:       template.Spec,
+		}
+		pod.Name = name
+		pod.Namespace = statefulSet.Namespace
+		pod.Spec.Hostname = name
+		pod.Spec.Subdomain = statefulSet.Spec.ServiceName
+		pod, err = pods.Create(ctx, pod, controller.NewCreateOptions())
+	}
+	if err != nil {
+		return err
+	}
+	if pod.Status.Phase == core.PodRunning {
+		return nil
+	}
+
+	now := meta.Now()
+	pod.Status.Phase = core.PodRunning
+	pod.Status.StartTime = &now
+	pod.Status.PodIP = "127.0.0.1"
+	for _, _type := range []core.PodConditionType{core.PodScheduled, core.PodInitialized, core.ContainersReady, core.PodReady} {
+		pod.Status.Conditions = append(pod.Status.Conditions, core.PodCondition{
+			Type:               _type,
+			Status:             core.ConditionTrue,
+			LastTransitionTime: now,
+		})
+	}
+	for _, container := range pod.Spec.Containers {
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, core.ContainerStatus{
+			Name:    container.Name,
+			Image:   container.Image,
+			Ready:   true,
+			Started: &[]bool{true}[0],
+			State: core.ContainerState{
+				Running: &core.ContainerStateRunning{StartedAt: now},
+			},
+		})
+	}
+	_, err = pods.UpdateStatus(ctx, pod, controller.NewUpdateOptions())
+	return err
+}
+
+// simulateStatefulSetStatus reports all replicas of the StatefulSet ready with the current generation
+func (e *Environment) simulateStatefulSetStatus(ctx context.Context, statefulSet *apps.StatefulSet) error {
+	replicas := getReplicas(statefulSet)
+	revision := fmt.Sprintf("%s-%d", statefulSet.Name, statefulSet.Generation)
+	status := apps.StatefulSetStatus{
+		ObservedGeneration: statefulSet.Generation,
+		Replicas:           replicas,
+		ReadyReplicas:      replicas,
+		CurrentReplicas:    replicas,
+		UpdatedReplicas:    replicas,
+		AvailableReplicas:  replicas,
+		CurrentRevision:    revision,
+		UpdateRevision:     revision,
+	}
+	if equality.Semantic.DeepEqual(statefulSet.Status, status) {
+		return nil
+	}
+	statefulSet.Status = status
+	_, err := e.KubeClient.AppsV1().StatefulSets(statefulSet.Namespace).UpdateStatus(ctx, statefulSet, controller.NewUpdateOptions())
+	return err
+}
+
+// getReplicas gets number of replicas of the StatefulSet
+func getReplicas(statefulSet *apps.StatefulSet) int32 {
+	if statefulSet.Spec.Replicas == nil {
+		return 1
+	}
+	return *statefulSet.Spec.Replicas
+}
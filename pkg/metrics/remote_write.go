@@ -0,0 +1,150 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// remoteWriteTimeout specifies how long to wait for remote-write endpoint to accept samples
+	remoteWriteTimeout = 30 * time.Second
+	// snappyMaxLiteral specifies max length of a literal element of snappy block format
+	snappyMaxLiteral = 1 << 16
+)
+
+// Protobuf wire types of fields of remote-write messages
+const (
+	wireTypeVarint  = 0
+	wireTypeFixed64 = 1
+	wireTypeBytes   = 2
+)
+
+// RemoteWriteSample is a sample of a time series pushed via Prometheus remote-write
+type RemoteWriteSample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// PushRemoteWrite pushes samples to Prometheus remote-write endpoint
+func PushRemoteWrite(ctx context.Context, url string, headers map[string]string, samples []RemoteWriteSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	body := encodeSnappy(encodeWriteRequest(samples, time.Now()))
+
+	ctx, cancel := context.WithTimeout(ctx, remoteWriteTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("User-Agent", "clickhouse-operator")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("remote-write endpoint responded %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+// encodeWriteRequest encodes samples as prometheus.WriteRequest protobuf message
+func encodeWriteRequest(samples []RemoteWriteSample, now time.Time) []byte {
+	var request []byte
+	for _, sample := range samples {
+		var series []byte
+
+		// Labels are to be sorted by name, __name__ goes first
+		series = appendLabel(series, "__name__", sample.Name)
+		names := make([]string, 0, len(sample.Labels))
+		for name := range sample.Labels {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			series = appendLabel(series, name, sample.Labels[name])
+		}
+
+		// prometheus.Sample message
+		var s []byte
+		s = appendTag(s, 1, wireTypeFixed64)
+		s = binary.LittleEndian.AppendUint64(s, math.Float64bits(sample.Value))
+		s = appendTag(s, 2, wireTypeVarint)
+		s = binary.AppendUvarint(s, uint64(now.UnixMilli()))
+		series = appendBytes(series, 2, s)
+
+		request = appendBytes(request, 1, series)
+	}
+	return request
+}
+
+// appendLabel appends prometheus.Label protobuf message to the TimeSeries message
+func appendLabel(series []byte, name, value string) []byte {
+	var label []byte
+	label = appendBytes(label, 1, []byte(name))
+	label = appendBytes(label, 2, []byte(value))
+	return appendBytes(series, 1, label)
+}
+
+// appendTag appends protobuf tag of the field of specified number and wire type
+func appendTag(b []byte, field int, wireType int) []byte {
+	return binary.AppendUvarint(b, uint64(field<<3|wireType))
+}
+
+// appendBytes appends length-delimited protobuf field, such as string or embedded message
+func appendBytes(b []byte, field int, value []byte) []byte {
+	b = appendTag(b, field, wireTypeBytes)
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+// encodeSnappy encodes data in snappy block format, expected by remote-write endpoints.
+// Data is stored as literals only, which is valid snappy, while payloads are small enough to skip compression
+func encodeSnappy(data []byte) []byte {
+	out := binary.AppendUvarint(nil, uint64(len(data)))
+	for len(data) > 0 {
+		n := len(data)
+		if n > snappyMaxLiteral {
+			n = snappyMaxLiteral
+		}
+		// Literal of length n, length-1 stored in 2 extra bytes (tag 61)
+		out = append(out, 61<<2, byte(n-1), byte((n-1)>>8))
+		out = append(out, data[:n]...)
+		data = data[n:]
+	}
+	return out
+}
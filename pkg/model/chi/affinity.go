@@ -849,6 +849,29 @@ func prepareAffinity(podTemplate *api.ChiPodTemplate, host *api.ChiHost) {
 	}
 }
 
+// appendRequiredNodeSelectorRequirement appends requirement to all required node selector terms of the pod.
+// Terms are ORed, so the requirement has to be present in each of them
+func appendRequiredNodeSelectorRequirement(podSpec *v1.PodSpec, requirement v1.NodeSelectorRequirement) {
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &v1.Affinity{}
+	}
+	if podSpec.Affinity.NodeAffinity == nil {
+		podSpec.Affinity.NodeAffinity = &v1.NodeAffinity{}
+	}
+	nodeAffinity := podSpec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &v1.NodeSelector{}
+	}
+	nodeSelector := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(nodeSelector.NodeSelectorTerms) == 0 {
+		nodeSelector.NodeSelectorTerms = []v1.NodeSelectorTerm{{}}
+	}
+	for i := range nodeSelector.NodeSelectorTerms {
+		term := &nodeSelector.NodeSelectorTerms[i]
+		term.MatchExpressions = append(term.MatchExpressions, requirement)
+	}
+}
+
 // processNodeSelector
 func processNodeSelector(nodeSelector *v1.NodeSelector, host *api.ChiHost) {
 	if nodeSelector == nil {
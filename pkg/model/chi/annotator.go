@@ -17,11 +17,21 @@ package chi
 import (
 	core "k8s.io/api/core/v1"
 
+	clickhouse_altinity_com "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com"
 	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
 	"github.com/altinity/clickhouse-operator/pkg/chop"
 	"github.com/altinity/clickhouse-operator/pkg/util"
 )
 
+// AnnotationRestartHost specifies annotation of the CHI naming host to be restarted safely.
+// Annotation is a request to the operator, it is not propagated to objects of the CHI
+const AnnotationRestartHost = clickhouse_altinity_com.APIGroupName + "/" + "restart-host"
+
+// annotationsRequests specifies annotations of the CHI, which are requests to the operator
+var annotationsRequests = []string{
+	AnnotationRestartHost,
+}
+
 // Annotator is an entity which can annotate CHI artifacts
 type Annotator struct {
 	chi *api.ClickHouseInstallation
@@ -62,7 +72,7 @@ func (a *Annotator) getConfigMapHost(host *api.ChiHost) map[string]string {
 func (a *Annotator) getServiceCHI(chi *api.ClickHouseInstallation) map[string]string {
 	return util.MergeStringMapsOverwrite(
 		a.getCHIScope(),
-		nil,
+		a.getServiceRouting(),
 	)
 }
 
@@ -70,7 +80,7 @@ func (a *Annotator) getServiceCHI(chi *api.ClickHouseInstallation) map[string]st
 func (a *Annotator) getServiceCluster(cluster *api.Cluster) map[string]string {
 	return util.MergeStringMapsOverwrite(
 		a.getClusterScope(cluster),
-		nil,
+		a.getServiceRouting(),
 	)
 }
 
@@ -78,7 +88,7 @@ func (a *Annotator) getServiceCluster(cluster *api.Cluster) map[string]string {
 func (a *Annotator) getServiceShard(shard *api.ChiShard) map[string]string {
 	return util.MergeStringMapsOverwrite(
 		a.getShardScope(shard),
-		nil,
+		a.getServiceRouting(),
 	)
 }
 
@@ -86,10 +96,15 @@ func (a *Annotator) getServiceShard(shard *api.ChiShard) map[string]string {
 func (a *Annotator) getServiceHost(host *api.ChiHost) map[string]string {
 	return util.MergeStringMapsOverwrite(
 		a.getHostScope(host),
-		nil,
+		a.getServiceRouting(),
 	)
 }
 
+// getServiceRouting gets routing annotations of services
+func (a *Annotator) getServiceRouting() map[string]string {
+	return a.chi.Spec.Defaults.GetRouting().GetServiceAnnotations()
+}
+
 // getCHIScope gets annotations for CHI-scoped object
 func (a *Annotator) getCHIScope() map[string]string {
 	// Combine generated annotations and CHI-provided annotations
@@ -98,19 +113,20 @@ func (a *Annotator) getCHIScope() map[string]string {
 
 // getClusterScope gets annotations for Cluster-scoped object
 func (a *Annotator) getClusterScope(cluster *api.Cluster) map[string]string {
-	// Combine generated annotations and CHI-provided annotations
-	return a.filterOutPredefined(a.appendCHIProvidedTo(nil))
+	// Combine generated annotations, CHI-provided annotations and cluster-provided annotations
+	return a.filterOutPredefined(util.MergeStringMapsOverwrite(a.appendCHIProvidedTo(nil), cluster.Metadata.GetAnnotations()))
 }
 
 // getShardScope gets annotations for Shard-scoped object
 func (a *Annotator) getShardScope(shard *api.ChiShard) map[string]string {
-	// Combine generated annotations and CHI-provided annotations
-	return a.filterOutPredefined(a.appendCHIProvidedTo(nil))
+	// Combine generated annotations, CHI-provided annotations and shard-provided annotations
+	return a.filterOutPredefined(util.MergeStringMapsOverwrite(a.appendCHIProvidedTo(nil), shard.Metadata.GetAnnotations()))
 }
 
 // getHostScope gets annotations for Host-scoped object
 func (a *Annotator) getHostScope(host *api.ChiHost) map[string]string {
-	return a.filterOutPredefined(a.appendCHIProvidedTo(nil))
+	// Host-provided annotations are inherited from cluster, shard and replica
+	return a.filterOutPredefined(util.MergeStringMapsOverwrite(a.appendCHIProvidedTo(nil), host.Metadata.GetAnnotations()))
 }
 
 // filterOutPredefined filters out predefined values
@@ -121,6 +137,7 @@ func (a *Annotator) filterOutPredefined(m map[string]string) map[string]string {
 // appendCHIProvidedTo appends CHI-provided annotations to specified annotations
 func (a *Annotator) appendCHIProvidedTo(dst map[string]string) map[string]string {
 	source := util.CopyMapFilter(a.chi.Annotations, chop.Config().Annotation.Include, chop.Config().Annotation.Exclude)
+	source = util.CopyMapExclude(source, annotationsRequests...)
 	return util.MergeStringMapsOverwrite(dst, source)
 }
 
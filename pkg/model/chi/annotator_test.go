@@ -0,0 +1,36 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+func Test_AnnotationRestartHostIsNotPropagated(t *testing.T) {
+	chi := newTestCHI(t, `
+metadata:
+  name: annotated
+  annotations:
+    clickhouse.altinity.com/restart-host: chi-annotated-main-0-0
+    team: analytics
+`)
+	statefulSet := model.NewStatefulSetGenerator(chi).CreateStatefulSet(chi.FirstHost(), false)
+	require.Equal(t, "analytics", statefulSet.Annotations["team"])
+	require.NotContains(t, statefulSet.Annotations, model.AnnotationRestartHost)
+}
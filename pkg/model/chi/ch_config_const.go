@@ -23,6 +23,7 @@ const (
 const (
 	configMacros        = "macros"
 	configHostnamePorts = "hostname-ports"
+	configOverlay       = "overlay"
 	configProfiles      = "profiles"
 	configQuotas        = "quotas"
 	configRemoteServers = "remote_servers"
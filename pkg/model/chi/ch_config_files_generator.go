@@ -86,6 +86,12 @@ func (c *ClickHouseConfigFilesGenerator) CreateConfigFilesGroupCommon(options *C
 	util.MergeStringMapsOverwrite(commonConfigSections, c.chConfigGenerator.GetSectionFromFiles(api.SectionCommon, true, nil))
 	// Extra user-specified config files
 	util.MergeStringMapsOverwrite(commonConfigSections, c.chopConfig.ClickHouse.Config.File.Runtime.CommonConfigFiles)
+	// Config of overlays selecting the CHI.
+	// Overlay settings files are named to be read by ClickHouse before CHI's own settings, so CHI settings take precedence
+	for _, overlay := range c.getOverlays() {
+		util.IncludeNonEmpty(commonConfigSections, createConfigSectionFilename(configOverlay+"-"+overlay.Name), c.chConfigGenerator.GetOverlaySettings(overlay))
+		util.MergeStringMapsOverwrite(commonConfigSections, overlay.Files.Common)
+	}
 
 	return commonConfigSections
 }
@@ -104,6 +110,10 @@ func (c *ClickHouseConfigFilesGenerator) CreateConfigFilesGroupUsers() map[strin
 	util.MergeStringMapsOverwrite(commonUsersConfigSections, c.chConfigGenerator.GetSectionFromFiles(api.SectionUsers, false, nil))
 	// Extra user-specified config files
 	util.MergeStringMapsOverwrite(commonUsersConfigSections, c.chopConfig.ClickHouse.Config.File.Runtime.UsersConfigFiles)
+	// Config of overlays selecting the CHI
+	for _, overlay := range c.getOverlays() {
+		util.MergeStringMapsOverwrite(commonUsersConfigSections, overlay.Files.Users)
+	}
 
 	return commonUsersConfigSections
 }
@@ -119,10 +129,19 @@ func (c *ClickHouseConfigFilesGenerator) CreateConfigFilesGroupHost(host *api.Ch
 	util.MergeStringMapsOverwrite(hostConfigSections, c.chConfigGenerator.GetSectionFromFiles(api.SectionHost, true, host))
 	// Extra user-specified config files
 	util.MergeStringMapsOverwrite(hostConfigSections, c.chopConfig.ClickHouse.Config.File.Runtime.HostConfigFiles)
+	// Config of overlays selecting the CHI
+	for _, overlay := range c.getOverlays() {
+		util.MergeStringMapsOverwrite(hostConfigSections, overlay.Files.Host)
+	}
 
 	return hostConfigSections
 }
 
+// getOverlays gets operator config overlays selecting the CHI
+func (c *ClickHouseConfigFilesGenerator) getOverlays() []*api.OperatorConfigOverlay {
+	return c.chopConfig.GetOverlays(c.chConfigGenerator.chi)
+}
+
 // createConfigSectionFilename creates filename of a configuration file.
 // filename depends on a section which it will contain
 func createConfigSectionFilename(section string) string {
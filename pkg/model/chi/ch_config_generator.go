@@ -35,6 +35,40 @@ const (
 	AllShardsOneReplicaClusterName = "all-sharded"
 )
 
+const (
+	// CrossRegionClusterNameSuffix specifies suffix of auto-generated cluster, which lays over replicas of all regions
+	CrossRegionClusterNameSuffix = "-cross-region"
+
+	// Macros of cross-region replication topology, to be used in ZooKeeper paths and replica names
+	macrosCrossRegionRegion       = "region"
+	macrosCrossRegionInstallation = "cross_region_installation"
+	macrosCrossRegionReplica      = "cross_region_replica"
+)
+
+// operatorMacroNames specifies names of macros generated by the operator for each host
+var operatorMacroNames = []string{
+	"installation",
+	AllShardsOneReplicaClusterName + "-shard",
+	"cluster",
+	"shard",
+	"replica",
+	macrosCrossRegionRegion,
+	macrosCrossRegionInstallation,
+	macrosCrossRegionReplica,
+}
+
+// ValidateHostMacroName checks whether the name can be used as name of a macro fetched from Kubernetes metadata.
+// Macro name is used as XML tag name and has not to clash with macros managed by the operator
+func ValidateHostMacroName(name string) error {
+	switch {
+	case !xml.IsValidName(name):
+		return fmt.Errorf("invalid name")
+	case util.InArray(name, operatorMacroNames) || (name == api.FaultDomainMacroName):
+		return fmt.Errorf("name is reserved by the operator")
+	}
+	return nil
+}
+
 // ClickHouseConfigGenerator generates ClickHouse configuration files content for specified CHI
 // ClickHouse configuration files content is an XML ATM, so config generator provides set of Get*() functions
 // which produces XML which are parts of ClickHouse configuration and can/should be used as ClickHouse config files.
@@ -76,6 +110,11 @@ func (c *ClickHouseConfigGenerator) GetSettings(host *api.ChiHost) string {
 	return c.generateXMLConfig(host.Settings, "")
 }
 
+// GetOverlaySettings creates data for settings of the operator config overlay
+func (c *ClickHouseConfigGenerator) GetOverlaySettings(overlay *api.OperatorConfigOverlay) string {
+	return c.generateXMLConfig(api.NewSettings().SetScalarsFromMap(overlay.Settings).Normalize(), "")
+}
+
 // GetSectionFromFiles creates data for custom common config files
 func (c *ClickHouseConfigGenerator) GetSectionFromFiles(section api.SettingsSection, includeUnspecified bool, host *api.ChiHost) map[string]string {
 	var files *api.Settings
@@ -117,7 +156,7 @@ func (c *ClickHouseConfigGenerator) GetHostZookeeper(host *api.ChiHost) string {
 		//		<secure>%d</secure>
 		// </node>
 		util.Iline(b, 8, "<node>")
-		util.Iline(b, 8, "    <host>%s</host>", node.Host)
+		util.Iline(b, 8, "    <host>%s</host>", xml.Escape(node.Host))
 		util.Iline(b, 8, "    <port>%d</port>", node.Port)
 		if node.Secure.HasValue() {
 			util.Iline(b, 8, "    <secure>%d</secure>", c.getSecure(node))
@@ -137,12 +176,12 @@ func (c *ClickHouseConfigGenerator) GetHostZookeeper(host *api.ChiHost) string {
 
 	// Append root
 	if len(zk.Root) > 0 {
-		util.Iline(b, 8, "<root>%s</root>", zk.Root)
+		util.Iline(b, 8, "<root>%s</root>", xml.Escape(zk.Root))
 	}
 
 	// Append identity
 	if len(zk.Identity) > 0 {
-		util.Iline(b, 8, "<identity>%s</identity>", zk.Identity)
+		util.Iline(b, 8, "<identity>%s</identity>", xml.Escape(zk.Identity))
 	}
 
 	// </zookeeper>
@@ -152,9 +191,9 @@ func (c *ClickHouseConfigGenerator) GetHostZookeeper(host *api.ChiHost) string {
 	//      <path>/x/y/chi.name/z</path>
 	//      <profile>X</profile>
 	util.Iline(b, 4, "<distributed_ddl>")
-	util.Iline(b, 4, "    <path>%s</path>", c.getDistributedDDLPath())
+	util.Iline(b, 4, "    <path>%s</path>", xml.Escape(c.getDistributedDDLPath()))
 	if c.chi.Spec.Defaults.DistributedDDL.HasProfile() {
-		util.Iline(b, 4, "    <profile>%s</profile>", c.chi.Spec.Defaults.DistributedDDL.GetProfile())
+		util.Iline(b, 4, "    <profile>%s</profile>", xml.Escape(c.chi.Spec.Defaults.DistributedDDL.GetProfile()))
 	}
 	//		</distributed_ddl>
 	// </yandex>
@@ -315,7 +354,7 @@ func (c *ClickHouseConfigGenerator) getRemoteServersReplica(host *api.ChiHost, b
 		port = host.TCPPort
 	}
 	util.Iline(b, 16, "<replica>")
-	util.Iline(b, 16, "    <host>%s</host>", c.getRemoteServersReplicaHostname(host))
+	util.Iline(b, 16, "    <host>%s</host>", xml.Escape(c.getRemoteServersReplicaHostname(host)))
 	util.Iline(b, 16, "    <port>%d</port>", port)
 	util.Iline(b, 16, "    <secure>%d</secure>", c.getSecure(host))
 	util.Iline(b, 16, "</replica>")
@@ -346,14 +385,7 @@ func (c *ClickHouseConfigGenerator) GetRemoteServers(options *RemoteServersGener
 		util.Iline(b, 8, "<%s>", cluster.Name)
 
 		// <secret>VALUE</secret>
-		switch cluster.Secret.Source() {
-		case api.ClusterSecretSourcePlaintext:
-			// Secret value is explicitly specified
-			util.Iline(b, 12, "<secret>%s</secret>", cluster.Secret.Value)
-		case api.ClusterSecretSourceSecretRef, api.ClusterSecretSourceAuto:
-			// Use secret via ENV var from secret
-			util.Iline(b, 12, `<secret from_env="%s" />`, internodeClusterSecretEnvName)
-		}
+		c.getRemoteServersClusterSecret(cluster, b)
 
 		// Build each shard XML
 		cluster.WalkShards(func(index int, shard *api.ChiShard) error {
@@ -390,6 +422,18 @@ func (c *ClickHouseConfigGenerator) GetRemoteServers(options *RemoteServersGener
 		return nil
 	})
 
+	// Cross-region clusters
+	if c.chi.Spec.CrossRegion.HasPeers() {
+		util.Iline(b, 8, "<!-- Cross-region clusters -->")
+		c.getRemoteServersCrossRegion(b, options)
+	}
+
+	// Custom clusters
+	if len(c.chi.Spec.Configuration.RemoteClusters) > 0 {
+		util.Iline(b, 8, "<!-- Custom clusters -->")
+		c.getRemoteServersCustom(b)
+	}
+
 	// Auto-generated clusters
 
 	if c.CHIHostsNum(options) < 1 {
@@ -448,6 +492,173 @@ func (c *ClickHouseConfigGenerator) GetRemoteServers(options *RemoteServersGener
 	return b.String()
 }
 
+// getRemoteServersCrossRegion writes clusters, which lay over replicas of this and all peer regions.
+// Peer replicas are expected to mirror layout of this CHI
+func (c *ClickHouseConfigGenerator) getRemoteServersCrossRegion(b *bytes.Buffer, options *RemoteServersGeneratorOptions) {
+	c.chi.WalkClusters(func(cluster *api.Cluster) error {
+		if c.ClusterHostsNum(cluster, options) < 1 {
+			// Skip empty cluster
+			return nil
+		}
+		// <my_cluster_name-cross-region>
+		clusterName := cluster.Name + CrossRegionClusterNameSuffix
+		util.Iline(b, 8, "<%s>", clusterName)
+
+		// <secret>VALUE</secret>
+		// Peer replicas are authenticated by the secret of the cluster, which has to be shared by all regions
+		c.getRemoteServersClusterSecret(cluster, b)
+
+		cluster.WalkShards(func(index int, shard *api.ChiShard) error {
+			if c.ShardHostsNum(shard, options) < 1 {
+				// Skip empty shard
+				return nil
+			}
+
+			// <shard>
+			//		<internal_replication>true</internal_replication>
+			util.Iline(b, 12, "<shard>")
+			util.Iline(b, 16, "<internal_replication>true</internal_replication>")
+
+			// Replicas of this region
+			shard.WalkHosts(func(host *api.ChiHost) error {
+				if options.Include(host) {
+					c.getRemoteServersReplica(host, b)
+				}
+				return nil
+			})
+
+			// Replicas of peer regions
+			for i := range c.chi.Spec.CrossRegion.GetPeers() {
+				peer := &c.chi.Spec.CrossRegion.GetPeers()[i]
+				shard.WalkHosts(func(host *api.ChiHost) error {
+					if options.Include(host) {
+						c.getRemoteServersPeerReplica(host, peer, b)
+					}
+					return nil
+				})
+			}
+
+			// </shard>
+			util.Iline(b, 12, "</shard>")
+			return nil
+		})
+
+		// </my_cluster_name-cross-region>
+		util.Iline(b, 8, "</%s>", clusterName)
+		return nil
+	})
+}
+
+// getRemoteServersCustom writes custom clusters of arbitrary hosts specified in .spec.configuration.remoteClusters
+func (c *ClickHouseConfigGenerator) getRemoteServersCustom(b *bytes.Buffer) {
+	for i := range c.chi.Spec.Configuration.RemoteClusters {
+		cluster := &c.chi.Spec.Configuration.RemoteClusters[i]
+		if !xml.IsValidName(cluster.Name) {
+			// Cluster name is a tag, cluster can not be represented as XML
+			continue
+		}
+		// <my_cluster_name>
+		util.Iline(b, 8, "<%s>", cluster.Name)
+
+		// <secret>VALUE</secret>
+		if cluster.Secret != "" {
+			util.Iline(b, 12, "<secret>%s</secret>", xml.Escape(cluster.Secret))
+		}
+
+		for j := range cluster.Shards {
+			shard := &cluster.Shards[j]
+			// <shard>
+			//		<internal_replication>VALUE(true/false)</internal_replication>
+			util.Iline(b, 12, "<shard>")
+			util.Iline(b, 16, "<internal_replication>%t</internal_replication>", shard.InternalReplication.Value())
+
+			//		<weight>X</weight>
+			if shard.HasWeight() {
+				util.Iline(b, 16, "<weight>%d</weight>", shard.GetWeight())
+			}
+
+			for k := range shard.Replicas {
+				c.getRemoteServersCustomReplica(&shard.Replicas[k], b)
+			}
+
+			// </shard>
+			util.Iline(b, 12, "</shard>")
+		}
+
+		// </my_cluster_name>
+		util.Iline(b, 8, "</%s>", cluster.Name)
+	}
+}
+
+// getRemoteServersCustomReplica writes replica of the custom cluster
+func (c *ClickHouseConfigGenerator) getRemoteServersCustomReplica(replica *api.ChiRemoteReplica, b *bytes.Buffer) {
+	// <replica>
+	//		<host>XXX</host>
+	//		<port>XXX</port>
+	//		<secure>XXX</secure>
+	//		<user>XXX</user>
+	//		<password>XXX</password>
+	// </replica>
+	util.Iline(b, 16, "<replica>")
+	util.Iline(b, 16, "    <host>%s</host>", xml.Escape(replica.Host))
+	util.Iline(b, 16, "    <port>%d</port>", replica.Port)
+	util.Iline(b, 16, "    <secure>%d</secure>", c.getSecure(replica))
+	if replica.User != "" {
+		util.Iline(b, 16, "    <user>%s</user>", xml.Escape(replica.User))
+	}
+	if replica.Password != "" {
+		util.Iline(b, 16, "    <password>%s</password>", xml.Escape(replica.Password))
+	}
+	util.Iline(b, 16, "</replica>")
+}
+
+// getRemoteServersClusterSecret writes secret of the cluster, replicas of the cluster authenticate each other with
+func (c *ClickHouseConfigGenerator) getRemoteServersClusterSecret(cluster *api.Cluster, b *bytes.Buffer) {
+	switch cluster.Secret.Source() {
+	case api.ClusterSecretSourcePlaintext:
+		// Secret value is explicitly specified
+		util.Iline(b, 12, "<secret>%s</secret>", xml.Escape(cluster.Secret.Value))
+	case api.ClusterSecretSourceSecretRef, api.ClusterSecretSourceAuto:
+		// Use secret via ENV var from secret
+		util.Iline(b, 12, `<secret from_env="%s" />`, internodeClusterSecretEnvName)
+	}
+}
+
+// getRemoteServersPeerReplica writes replica of the peer region, mirroring specified host
+func (c *ClickHouseConfigGenerator) getRemoteServersPeerReplica(host *api.ChiHost, peer *api.ChiCrossRegionPeer, b *bytes.Buffer) {
+	port := peer.Port
+	if port == 0 {
+		if peer.IsSecure() {
+			port = host.TLSPort
+		} else {
+			port = host.TCPPort
+		}
+	}
+	secure := 0
+	if peer.IsSecure() {
+		secure = 1
+	}
+
+	// <replica>
+	//		<host>XXX</host>
+	//		<port>XXX</port>
+	//		<secure>XXX</secure>
+	//		<user>XXX</user>
+	//		<password>XXX</password>
+	// </replica>
+	util.Iline(b, 16, "<replica>")
+	util.Iline(b, 16, "    <host>%s</host>", xml.Escape(macro(host).Line(peer.HostPattern)))
+	util.Iline(b, 16, "    <port>%d</port>", port)
+	util.Iline(b, 16, "    <secure>%d</secure>", secure)
+	if peer.User != "" {
+		util.Iline(b, 16, "    <user>%s</user>", xml.Escape(peer.User))
+	}
+	if peer.Password != "" {
+		util.Iline(b, 16, "    <password>%s</password>", xml.Escape(peer.Password))
+	}
+	util.Iline(b, 16, "</replica>")
+}
+
 // GetHostMacros creates "macros.xml" content
 func (c *ClickHouseConfigGenerator) GetHostMacros(host *api.ChiHost) string {
 	b := &bytes.Buffer{}
@@ -458,7 +669,7 @@ func (c *ClickHouseConfigGenerator) GetHostMacros(host *api.ChiHost) string {
 	util.Iline(b, 0, "    <macros>")
 
 	// <installation>CHI-name-macros-value</installation>
-	util.Iline(b, 8, "<installation>%s</installation>", host.Address.CHIName)
+	util.Iline(b, 8, "<installation>%s</installation>", xml.Escape(host.Address.CHIName))
 
 	// <CLUSTER_NAME>cluster-name-macros-value</CLUSTER_NAME>
 	// util.Iline(b, 8, "<%s>%[2]s</%[1]s>", replica.Address.ClusterName, c.getMacrosCluster(replica.Address.ClusterName))
@@ -471,12 +682,29 @@ func (c *ClickHouseConfigGenerator) GetHostMacros(host *api.ChiHost) string {
 
 	// <cluster> and <shard> macros are applicable to main cluster only. All aux clusters do not have ambiguous macros
 	// <cluster></cluster> macro
-	util.Iline(b, 8, "<cluster>%s</cluster>", host.Address.ClusterName)
+	util.Iline(b, 8, "<cluster>%s</cluster>", xml.Escape(host.Address.ClusterName))
 	// <shard></shard> macro
-	util.Iline(b, 8, "<shard>%s</shard>", host.Address.ShardName)
+	util.Iline(b, 8, "<shard>%s</shard>", xml.Escape(host.Address.ShardName))
 	// <replica>replica id = full deployment id</replica>
 	// full deployment id is unique to identify replica within the cluster
-	util.Iline(b, 8, "<replica>%s</replica>", CreatePodHostname(host))
+	util.Iline(b, 8, "<replica>%s</replica>", xml.Escape(CreateMacroReplica(host)))
+
+	// Cross-region replication macros
+	// <cross_region_replica>region-replica</cross_region_replica> is unique across all regions
+	if crossRegion := host.GetCHI().Spec.CrossRegion; crossRegion.IsEnabled() {
+		util.Iline(b, 8, "<%s>%s</%[1]s>", macrosCrossRegionRegion, xml.Escape(crossRegion.GetRegion()))
+		util.Iline(b, 8, "<%s>%s</%[1]s>", macrosCrossRegionInstallation, xml.Escape(crossRegion.GetInstallation()))
+		util.Iline(b, 8, "<%s>%s</%[1]s>", macrosCrossRegionReplica, xml.Escape(crossRegion.GetRegion()+"-"+CreateMacroReplica(host)))
+	}
+
+	// Macros fetched from Kubernetes metadata of the host, such as zone or node name
+	// <zone>zone-a</zone>
+	for _, name := range util.MapSortedKeys(host.Macros) {
+		if !xml.IsValidName(name) || util.InArray(name, operatorMacroNames) {
+			continue
+		}
+		util.Iline(b, 8, "<%s>%s</%[1]s>", name, xml.Escape(host.Macros[name]))
+	}
 
 	// 		</macros>
 	// </yandex>
@@ -508,7 +736,7 @@ func (c *ClickHouseConfigGenerator) GetHostHostnameAndPorts(host *api.ChiHost) s
 	}
 
 	// Interserver host and port
-	util.Iline(b, 4, "<interserver_http_host>%s</interserver_http_host>", c.getRemoteServersReplicaHostname(host))
+	util.Iline(b, 4, "<interserver_http_host>%s</interserver_http_host>", xml.Escape(c.getRemoteServersReplicaHostname(host)))
 	if host.InterserverHTTPPort != chDefaultInterserverHTTPPortNumber {
 		util.Iline(b, 4, "<interserver_http_port>%d</interserver_http_port>", host.InterserverHTTPPort)
 	}
@@ -0,0 +1,195 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-sigs/yaml"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	"github.com/altinity/clickhouse-operator/pkg/harness"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// testConfigCHI has user-provided strings with XML special chars all over the config
+const testConfigCHI = `
+apiVersion: clickhouse.altinity.com/v1
+kind: ClickHouseInstallation
+metadata:
+  name: golden
+  namespace: test
+spec:
+  configuration:
+    zookeeper:
+      nodes:
+        - host: zookeeper.zoo1ns
+      root: "/clickhouse/a&b"
+      identity: "user:<pass>"
+    users:
+      alice/password: "p<a>ss\"&'word"
+      alice/networks/ip:
+        - "::1"
+        - "127.0.0.1"
+      alice/profile: default
+      alice/quota: "\"quoted\" 'quota'"
+      invalid<name/password: skipped
+    profiles:
+      default/log_comment: "tom & jerry"
+    settings:
+      logger/level: debug
+      display_name: "<golden>"
+      # Values are escaped as is, so values escaped in the manifest already are escaped twice and reported
+      http_server_default_response: "&lt;html&gt;"
+      invalid name/level: skipped
+    clusters:
+      - name: cluster
+        secret:
+          value: "s&cr<t"
+        layout:
+          shardsCount: 1
+          replicasCount: 2
+    remoteClusters:
+      - name: remote
+        secret: "r&mote"
+        shards:
+          - replicas:
+              - host: remote-0
+                user: "bob"
+                password: "<b&b>"
+      - name: "in valid"
+        shards:
+          - replicas:
+              - host: remote-1
+`
+
+func Test_ClickHouseConfigGenerator_Golden(t *testing.T) {
+	require.NoError(t, chop.NewOffline(""))
+
+	chi := &api.ClickHouseInstallation{}
+	require.NoError(t, yaml.Unmarshal([]byte(testConfigCHI), chi))
+	normalized, err := model.NewNormalizer(nil).CreateTemplatedCHI(chi, model.NewNormalizerOptions())
+	require.NoError(t, err)
+
+	generator := model.NewClickHouseConfigGenerator(normalized)
+	host := normalized.FirstHost()
+
+	harness.AssertGolden(t, "remote-servers", generator.GetRemoteServers(nil))
+	harness.AssertGolden(t, "users", generator.GetUsers())
+	harness.AssertGolden(t, "profiles", generator.GetProfiles())
+	harness.AssertGolden(t, "settings", generator.GetSettingsGlobal())
+	harness.AssertGolden(t, "macros", generator.GetHostMacros(host))
+	harness.AssertGolden(t, "zookeeper", generator.GetHostZookeeper(host))
+
+	// Entries which can not be represented as XML are not written, but are reported
+	require.Equal(t, []string{
+		`users "invalid<name/password": invalid name "invalid<name"`,
+		`settings "invalid name/level": invalid name "invalid name"`,
+		`remote cluster "in valid": invalid name`,
+	}, normalized.EnsureStatus().GetRejections())
+
+	// Values escaped in the manifest already are reported
+	require.Equal(t, []string{`settings "http_server_default_response"`}, model.FindEscapedValues(normalized))
+}
+
+func Test_ClickHouseConfigGenerator_HostMacros(t *testing.T) {
+	chi := newTestCHI(t, `
+metadata:
+  name: macros
+spec:
+  hostMacros:
+    nodeName: "node name"
+    nodeLabels:
+      zone: topology.kubernetes.io/zone
+      "<rack>": example.com/rack
+`)
+
+	require.Equal(t, "", chi.Spec.HostMacros.GetNodeName())
+	require.Equal(t, map[string]string{"zone": "topology.kubernetes.io/zone"}, chi.Spec.HostMacros.GetNodeLabels())
+	require.Equal(t, []string{
+		`host macro "node name": invalid name`,
+		`host macro "<rack>": invalid name`,
+	}, chi.EnsureStatus().GetRejections())
+}
+
+func Test_ClickHouseConfigGenerator_UserDefaults(t *testing.T) {
+	chi := newTestCHI(t, userDefaultsTestManifest)
+	users := model.NewClickHouseConfigGenerator(chi).GetUsers()
+
+	// Default roles are granted to the user, each by own query
+	require.Contains(t, users, `
+            <default_database>reports</default_database>
+            <grants>
+                <query>GRANT reader</query>
+                <query>GRANT writer</query>
+            </grants>
+            <networks>
+                <host>bi.example.com</host>
+                <host_regexp>^bi-.*$</host_regexp>`)
+	require.Contains(t, users, "<ip>10.0.0.0/8</ip>")
+	require.Contains(t, users, "<default_database>system</default_database>")
+	require.Equal(t, 2, strings.Count(users, "<query>"))
+}
+
+func Test_ClickHouseConfigGenerator_CrossRegionPeerCredentials(t *testing.T) {
+	chi := newTestCHI(t, `
+metadata:
+  name: events
+spec:
+  crossRegion:
+    region: eu-west
+    peers:
+      - region: us-east
+        hostPattern: chi-events-{cluster}-{host}.us-east
+      - region: ap-south
+        hostPattern: chi-events-{cluster}-{host}.ap-south
+        user: "rep&icator"
+        password: "<secret>"
+  configuration:
+    clusters:
+      - name: shared
+        secret:
+          value: "s&cr<t"
+      - name: plain
+`)
+	remoteServers := model.NewClickHouseConfigGenerator(chi).GetRemoteServers(nil)
+
+	// Peer replicas are authenticated by the secret of the cluster
+	require.Contains(t, remoteServers, `
+        <shared-cross-region>
+            <secret>s&amp;cr&lt;t</secret>
+            <shard>`)
+	// Cluster without secret connects to peer replicas as the user of the peer
+	require.Contains(t, remoteServers, `
+        <plain-cross-region>
+            <shard>`)
+	require.Contains(t, remoteServers, `
+                <replica>
+                    <host>chi-events-plain-0-0.ap-south</host>
+                    <port>9000</port>
+                    <secure>0</secure>
+                    <user>rep&amp;icator</user>
+                    <password>&lt;secret&gt;</password>
+                </replica>`)
+	require.Contains(t, remoteServers, `
+                <replica>
+                    <host>chi-events-plain-0-0.us-east</host>
+                    <port>9000</port>
+                    <secure>0</secure>
+                </replica>`)
+}
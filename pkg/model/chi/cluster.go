@@ -81,6 +81,17 @@ func (c *Cluster) QueryUnzip2Columns(ctx context.Context, endpoints []string, sq
 	return column1, column2, nil
 }
 
+// QueryUnzip3Columns unzips query result into three columns
+func (c *Cluster) QueryUnzip3Columns(ctx context.Context, endpoints []string, sql string) ([]string, []string, []string, error) {
+	var column1 []string
+	var column2 []string
+	var column3 []string
+	if err := c.queryUnzipColumns(ctx, endpoints, sql, &column1, &column2, &column3); err != nil {
+		return nil, nil, nil, err
+	}
+	return column1, column2, column3, nil
+}
+
 // QueryUnzipAndApplyUUIDs unzips query result into two columns and applis UUID substituation if present
 func (c *Cluster) QueryUnzipAndApplyUUIDs(ctx context.Context, endpoints []string, sql string) ([]string, []string, error) {
 	var column1 []string
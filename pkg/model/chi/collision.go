@@ -0,0 +1,230 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+)
+
+// Kinds of objects names of which are checked for collisions
+const (
+	ObjectKindService     = "Service"
+	ObjectKindStatefulSet = "StatefulSet"
+	ObjectKindConfigMap   = "ConfigMap"
+)
+
+// ObjectName is a name of an object generated for the CHI
+type ObjectName struct {
+	Kind string
+	Name string
+}
+
+// String returns human-readable representation of the object name
+func (n ObjectName) String() string {
+	return n.Kind + " " + n.Name
+}
+
+// ObjectLookup looks up existing object of the kind by name within namespace of the CHI
+type ObjectLookup func(kind, name string) (*meta.ObjectMeta, bool)
+
+// NewObjectLookup lists Services, StatefulSets and ConfigMaps of the namespace in order to look them up by kind and name
+func NewObjectLookup(ctx context.Context, kubeClient kube.Interface, namespace string) (ObjectLookup, error) {
+	objects := make(map[ObjectName]*meta.ObjectMeta)
+
+	services, err := kubeClient.CoreV1().Services(namespace).List(ctx, meta.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range services.Items {
+		objects[ObjectName{Kind: ObjectKindService, Name: services.Items[i].Name}] = &services.Items[i].ObjectMeta
+	}
+
+	statefulSets, err := kubeClient.AppsV1().StatefulSets(namespace).List(ctx, meta.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		objects[ObjectName{Kind: ObjectKindStatefulSet, Name: statefulSets.Items[i].Name}] = &statefulSets.Items[i].ObjectMeta
+	}
+
+	configMaps, err := kubeClient.CoreV1().ConfigMaps(namespace).List(ctx, meta.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range configMaps.Items {
+		objects[ObjectName{Kind: ObjectKindConfigMap, Name: configMaps.Items[i].Name}] = &configMaps.Items[i].ObjectMeta
+	}
+
+	return func(kind, name string) (*meta.ObjectMeta, bool) {
+		objMeta, ok := objects[ObjectName{Kind: kind, Name: name}]
+		return objMeta, ok
+	}, nil
+}
+
+// CreateObjectNames lists names of Services, StatefulSets and ConfigMaps generated for the normalized CHI
+func CreateObjectNames(chi *api.ClickHouseInstallation) (names []ObjectName) {
+	add := func(kind, name string) {
+		names = append(names, ObjectName{Kind: kind, Name: name})
+	}
+
+	add(ObjectKindService, CreateCHIServiceName(chi))
+	if chi.Spec.Defaults.IsHeadlessHostServices() {
+		add(ObjectKindService, CreateHeadlessServiceName(chi))
+	}
+	if chi.Spec.Defaults.GetRouting().IsNodeLocalService() {
+		add(ObjectKindService, CreateNodeLocalServiceName(chi))
+	}
+	add(ObjectKindConfigMap, CreateConfigMapCommonName(chi))
+	add(ObjectKindConfigMap, CreateConfigMapCommonUsersName(chi))
+
+	chi.WalkClusters(func(cluster *api.Cluster) error {
+		if _, ok := cluster.GetServiceTemplate(); ok {
+			add(ObjectKindService, CreateClusterServiceName(cluster))
+		}
+		return nil
+	})
+	chi.WalkShards(func(shard *api.ChiShard) error {
+		if _, ok := shard.GetServiceTemplate(); ok {
+			add(ObjectKindService, CreateShardServiceName(shard))
+		}
+		return nil
+	})
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		add(ObjectKindStatefulSet, CreateStatefulSetName(host))
+		add(ObjectKindConfigMap, CreateConfigMapHostName(host))
+		if !chi.Spec.Defaults.IsHeadlessHostServices() {
+			add(ObjectKindService, CreateStatefulSetServiceName(host))
+		}
+		return nil
+	})
+	chi.WalkClusters(func(cluster *api.Cluster) error {
+		for _, host := range CreateStandbyHosts(cluster) {
+			add(ObjectKindStatefulSet, CreateStatefulSetName(host))
+			add(ObjectKindConfigMap, CreateConfigMapHostName(host))
+			if !chi.Spec.Defaults.IsHeadlessHostServices() {
+				add(ObjectKindService, CreateStatefulSetServiceName(host))
+			}
+		}
+		return nil
+	})
+
+	return names
+}
+
+// FindNameCollisions finds names generated for the normalized CHI, which collide either with each other
+// or with existing objects not belonging to the CHI, be it objects of another CHI or objects created not by the operator.
+// Names of Services are DNS names as well, so collision of Services means collision of DNS names.
+// Returns list of collisions found
+func FindNameCollisions(chi *api.ClickHouseInstallation, lookup ObjectLookup) (collisions []string) {
+	seen := make(map[ObjectName]bool)
+	for _, name := range CreateObjectNames(chi) {
+		if seen[name] {
+			collisions = append(collisions, fmt.Sprintf("%s is generated more than once", name))
+			continue
+		}
+		seen[name] = true
+
+		if lookup == nil {
+			continue
+		}
+		objMeta, ok := lookup(name.Kind, name.Name)
+		if !ok {
+			continue
+		}
+		switch owner, labeled := objMeta.Labels[LabelCHIName]; {
+		case !labeled:
+			collisions = append(collisions, fmt.Sprintf("%s already exists and is not managed by the operator", name))
+		case owner != labelsNamer.getNamePartCHIName(chi):
+			collisions = append(collisions, fmt.Sprintf("%s already exists and belongs to CHI %s", name, owner))
+		}
+	}
+	return collisions
+}
+
+// UsersSourceFiles gets names of files the users source projects into users.d folder,
+// which are keys of the referenced ConfigMap or Secret
+type UsersSourceFiles func(source api.ChiUsersSource) ([]string, error)
+
+// NewUsersSourceFiles gets files of users sources out of ConfigMaps and Secrets of the namespace
+func NewUsersSourceFiles(ctx context.Context, kubeClient kube.Interface, namespace string) UsersSourceFiles {
+	return func(source api.ChiUsersSource) (files []string, err error) {
+		switch {
+		case source.ConfigMapRef != nil:
+			configMap, err := kubeClient.CoreV1().ConfigMaps(namespace).Get(ctx, source.GetName(), meta.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			for key := range configMap.Data {
+				files = append(files, key)
+			}
+			for key := range configMap.BinaryData {
+				files = append(files, key)
+			}
+		case source.SecretRef != nil:
+			secret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, source.GetName(), meta.GetOptions{})
+			if err != nil {
+				return nil, err
+			}
+			for key := range secret.Data {
+				files = append(files, key)
+			}
+			for key := range secret.StringData {
+				files = append(files, key)
+			}
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+}
+
+// FindUsersSourceCollisions finds files of users sources of the normalized CHI, which collide either with each other
+// or with users config files generated by the operator. Sources are projected into the same users.d folder,
+// so kubelet is not able to mount projected volume with colliding files and pods are not able to start.
+// Returns list of collisions found
+func FindUsersSourceCollisions(chi *api.ClickHouseInstallation, sourceFiles UsersSourceFiles) (collisions []string) {
+	sources := chi.Spec.Configuration.GetUsersFrom()
+	if len(sources) == 0 {
+		return nil
+	}
+
+	owners := make(map[string]string)
+	usersConfigMap := ObjectName{Kind: ObjectKindConfigMap, Name: CreateConfigMapCommonUsersName(chi)}.String()
+	for file := range NewConfigMapGenerator(chi).CreateConfigMapCHICommonUsers().Data {
+		owners[file] = usersConfigMap
+	}
+
+	for _, source := range sources {
+		files, err := sourceFiles(source)
+		if err != nil {
+			// Source not available is reported by maintenance of users sources
+			continue
+		}
+		for _, file := range files {
+			if owner, ok := owners[file]; ok {
+				collisions = append(collisions, fmt.Sprintf("file %s of %s collides with %s", file, source, owner))
+				continue
+			}
+			owners[file] = source.String()
+		}
+	}
+	return collisions
+}
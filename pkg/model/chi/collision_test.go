@@ -0,0 +1,82 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// usersFromTestManifest specifies CHI with users sources
+const usersFromTestManifest = `
+metadata:
+  namespace: test
+  name: users
+spec:
+  configuration:
+    usersFrom:
+      - configMapRef:
+          name: pipeline
+      - secretRef:
+          name: passwords
+      - configMapRef:
+          name: missing
+      - configMapRef:
+          name: generated
+`
+
+func Test_FindUsersSourceCollisions(t *testing.T) {
+	chi := newTestCHI(t, usersFromTestManifest)
+	kubeClient := fake.NewSimpleClientset(
+		&core.ConfigMap{
+			ObjectMeta: meta.ObjectMeta{Namespace: "test", Name: "pipeline"},
+			Data:       map[string]string{"analyst.xml": "", "shared.xml": ""},
+		},
+		&core.Secret{
+			ObjectMeta: meta.ObjectMeta{Namespace: "test", Name: "passwords"},
+			Data:       map[string][]byte{"passwords.xml": nil, "shared.xml": nil},
+		},
+		&core.ConfigMap{
+			ObjectMeta: meta.ObjectMeta{Namespace: "test", Name: "generated"},
+			BinaryData: map[string][]byte{"chop-generated-users.xml": nil},
+		},
+	)
+
+	collisions := model.FindUsersSourceCollisions(chi, model.NewUsersSourceFiles(context.Background(), kubeClient, "test"))
+	require.Equal(t, []string{
+		"file shared.xml of Secret/passwords collides with ConfigMap/pipeline",
+		"file chop-generated-users.xml of ConfigMap/generated collides with ConfigMap chi-users-common-usersd",
+	}, collisions)
+}
+
+func Test_FindUsersSourceCollisions_NoCollisions(t *testing.T) {
+	chi := newTestCHI(t, usersFromTestManifest)
+	files := func(source api.ChiUsersSource) ([]string, error) {
+		return []string{source.GetName() + ".xml"}, nil
+	}
+	require.Empty(t, model.FindUsersSourceCollisions(chi, files))
+
+	// CHI without users sources has nothing to collide
+	require.Empty(t, model.FindUsersSourceCollisions(newTestCHI(t, generatorsTestManifest), files))
+}
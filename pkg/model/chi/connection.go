@@ -0,0 +1,82 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+)
+
+// CreateConnectionStatus creates connection details of the CHI, as exposed by the CHI service
+func CreateConnectionStatus(chi *api.ClickHouseInstallation) *api.ChiConnectionStatus {
+	status := &api.ChiConnectionStatus{
+		Host:     CreateCHIServiceFQDN(chi),
+		CASecret: chi.Spec.Connection.GetCASecret(),
+	}
+
+	if template, ok := chi.GetCHIServiceTemplate(); ok {
+		// Ports are recognized by name, unnamed ports are recognized by well-known numbers
+		for _, port := range template.Spec.Ports {
+			switch {
+			case (port.Name == chDefaultHTTPPortName) || (port.Port == chDefaultHTTPPortNumber):
+				status.HTTPPort = port.Port
+			case (port.Name == chDefaultHTTPSPortName) || (port.Port == chDefaultHTTPSPortNumber):
+				status.HTTPSPort = port.Port
+			case (port.Name == chDefaultTCPPortName) || (port.Port == chDefaultTCPPortNumber):
+				status.NativePort = port.Port
+			case (port.Name == chDefaultTLSPortName) || (port.Port == chDefaultTLSPortNumber):
+				status.NativeSecurePort = port.Port
+			}
+		}
+	} else {
+		// Default service exposes insecure ports only
+		status.HTTPPort = chDefaultHTTPPortNumber
+		status.NativePort = chDefaultTCPPortNumber
+	}
+
+	status.Secure = (status.HTTPSPort > 0) || (status.NativeSecurePort > 0)
+	status.HTTPURL = status.BuildHTTPURL()
+	return status
+}
+
+// CreateConfigMapConnection creates ConfigMap connection details of the CHI are published into
+func (c *Creator) CreateConfigMapConnection() *core.ConfigMap {
+	return &core.ConfigMap{
+		ObjectMeta: c.createConnectionObjectMeta(),
+		Data:       CreateConnectionStatus(c.chi).GetData(),
+	}
+}
+
+// CreateSecretConnection creates Secret connection details of the CHI are published into
+func (c *Creator) CreateSecretConnection() *core.Secret {
+	return &core.Secret{
+		ObjectMeta: c.createConnectionObjectMeta(),
+		StringData: CreateConnectionStatus(c.chi).GetData(),
+		Type:       core.SecretTypeOpaque,
+	}
+}
+
+// createConnectionObjectMeta creates meta of the object connection details of the CHI are published into
+func (c *Creator) createConnectionObjectMeta() meta.ObjectMeta {
+	return meta.ObjectMeta{
+		Name:            CreateConnectionName(c.chi),
+		Namespace:       c.chi.Namespace,
+		Labels:          macro(c.chi).Map(c.labels.getConnection()),
+		Annotations:     macro(c.chi).Map(c.annotations.getCHIScope()),
+		OwnerReferences: getOwnerReferences(c.chi),
+	}
+}
@@ -14,11 +14,21 @@
 
 package chi
 
+import (
+	clickhouse_altinity_com "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com"
+)
+
 const (
 	// Default value for ClusterIP service
 	templateDefaultsServiceClusterIP = "None"
 )
 
+const (
+	// PodConditionReady is a type of pod condition, readiness gate of the pod waits for.
+	// Condition is set by the operator after deep health check of the host
+	PodConditionReady = clickhouse_altinity_com.APIGroupName + "/" + "ready"
+)
+
 const (
 	// .spec.useTemplate.useType
 	useTypeMerge = "merge"
@@ -0,0 +1,114 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+)
+
+// ConfigMapGenerator generates ConfigMaps with ClickHouse config files of the CHI
+type ConfigMapGenerator interface {
+	// CreateConfigMapCHICommon creates ConfigMap with config files common for all hosts of the CHI
+	CreateConfigMapCHICommon(options *ClickHouseConfigFilesGeneratorOptions) *core.ConfigMap
+	// CreateConfigMapCHICommonUsers creates ConfigMap with users config files common for all hosts of the CHI
+	CreateConfigMapCHICommonUsers() *core.ConfigMap
+	// CreateConfigMapHost creates ConfigMap with config files personal for the host
+	CreateConfigMapHost(host *api.ChiHost) *core.ConfigMap
+}
+
+// configMapGenerator is the default ConfigMapGenerator
+type configMapGenerator struct {
+	chi                    *api.ClickHouseInstallation
+	chConfigFilesGenerator *ClickHouseConfigFilesGenerator
+	labels                 *Labeler
+	annotations            *Annotator
+}
+
+// NewConfigMapGenerator creates new default ConfigMapGenerator
+func NewConfigMapGenerator(chi *api.ClickHouseInstallation) ConfigMapGenerator {
+	return &configMapGenerator{
+		chi:                    chi,
+		chConfigFilesGenerator: NewClickHouseConfigFilesGenerator(NewClickHouseConfigGenerator(chi), chop.Config()),
+		labels:                 NewLabeler(chi),
+		annotations:            NewAnnotator(chi),
+	}
+}
+
+// CreateConfigMapCHICommon creates new core.ConfigMap
+func (g *configMapGenerator) CreateConfigMapCHICommon(options *ClickHouseConfigFilesGeneratorOptions) *core.ConfigMap {
+	cm := &core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            CreateConfigMapCommonName(g.chi),
+			Namespace:       g.chi.Namespace,
+			Labels:          macro(g.chi).Map(g.labels.getConfigMapCHICommon()),
+			Annotations:     macro(g.chi).Map(g.annotations.getConfigMapCHICommon()),
+			OwnerReferences: getOwnerReferences(g.chi),
+		},
+		// Data contains several sections which are to be several xml chopConfig files
+		Data: g.chConfigFilesGenerator.CreateConfigFilesGroupCommon(options),
+	}
+	// And after the object is ready we can put version label
+	MakeObjectVersion(&cm.ObjectMeta, cm)
+	return cm
+}
+
+// CreateConfigMapCHICommonUsers creates new core.ConfigMap
+func (g *configMapGenerator) CreateConfigMapCHICommonUsers() *core.ConfigMap {
+	cm := &core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            CreateConfigMapCommonUsersName(g.chi),
+			Namespace:       g.chi.Namespace,
+			Labels:          macro(g.chi).Map(g.labels.getConfigMapCHICommonUsers()),
+			Annotations:     macro(g.chi).Map(g.annotations.getConfigMapCHICommonUsers()),
+			OwnerReferences: getOwnerReferences(g.chi),
+		},
+		// Data contains several sections which are to be several xml chopConfig files
+		Data: g.chConfigFilesGenerator.CreateConfigFilesGroupUsers(),
+	}
+	// And after the object is ready we can put version label
+	MakeObjectVersion(&cm.ObjectMeta, cm)
+	return cm
+}
+
+// createConfigMapHost creates new core.ConfigMap
+func (g *configMapGenerator) createConfigMapHost(host *api.ChiHost, name string, data map[string]string) *core.ConfigMap {
+	cm := &core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            name,
+			Namespace:       host.Address.Namespace,
+			Labels:          macro(host).Map(g.labels.getConfigMapHost(host)),
+			Annotations:     macro(host).Map(g.annotations.getConfigMapHost(host)),
+			OwnerReferences: getOwnerReferences(g.chi),
+		},
+		Data: data,
+	}
+	// And after the object is ready we can put version label
+	MakeObjectVersion(&cm.ObjectMeta, cm)
+	return cm
+}
+
+// CreateConfigMapHost creates new core.ConfigMap
+func (g *configMapGenerator) CreateConfigMapHost(host *api.ChiHost) *core.ConfigMap {
+	return g.createConfigMapHost(host, CreateConfigMapHostName(host), g.chConfigFilesGenerator.CreateConfigFilesGroupHost(host))
+}
+
+// CreateConfigMapHostMigration creates new core.ConfigMap
+//func (g *configMapGenerator) CreateConfigMapHostMigration(host *api.ChiHost, data map[string]string) *core.ConfigMap {
+//	return g.createConfigMapHost(host, CreateConfigMapHostMigrationName(host), data)
+//}
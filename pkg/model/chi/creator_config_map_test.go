@@ -0,0 +1,71 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// generatorsTestManifest specifies CHI of two shards with a user
+const generatorsTestManifest = `
+metadata:
+  namespace: test
+  name: gen
+  uid: uid-1
+spec:
+  configuration:
+    users:
+      reader/password: secret
+    clusters:
+      - name: main
+        layout:
+          shardsCount: 2
+`
+
+func Test_ConfigMapGenerator(t *testing.T) {
+	chi := newTestCHI(t, generatorsTestManifest)
+	generator := model.NewConfigMapGenerator(chi)
+
+	common := generator.CreateConfigMapCHICommon(model.NewClickHouseConfigFilesGeneratorOptions())
+	require.Equal(t, "chi-gen-common-configd", common.Name)
+	require.Equal(t, "test", common.Namespace)
+	require.Equal(t, "ChiCommon", common.Labels[model.LabelConfigMap])
+	require.NotEmpty(t, common.Labels[model.LabelObjectVersion])
+	require.Len(t, common.OwnerReferences, 1)
+	require.Contains(t, common.Data["chop-generated-remote_servers.xml"], "<main>")
+
+	users := generator.CreateConfigMapCHICommonUsers()
+	require.Equal(t, "chi-gen-common-usersd", users.Name)
+	require.Equal(t, "ChiCommonUsers", users.Labels[model.LabelConfigMap])
+	require.Contains(t, users.Data["chop-generated-users.xml"], "<reader>")
+
+	var hosts []string
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		cm := generator.CreateConfigMapHost(host)
+		require.Equal(t, "Host", cm.Labels[model.LabelConfigMap])
+		require.Contains(t, cm.Data["chop-generated-macros.xml"], "<shard>"+host.Address.ShardName+"</shard>")
+		hosts = append(hosts, cm.Name)
+		return nil
+	})
+	require.Equal(t, []string{"chi-gen-deploy-confd-main-0-0", "chi-gen-deploy-confd-main-1-0"}, hosts)
+
+	// Generated objects are deterministic
+	require.Equal(t, common, generator.CreateConfigMapCHICommon(model.NewClickHouseConfigFilesGeneratorOptions()))
+}
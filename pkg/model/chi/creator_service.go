@@ -0,0 +1,396 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// ServiceGenerator generates Services of the CHI
+type ServiceGenerator interface {
+	// CreateServiceCHI creates Service of the CHI, nil in case CHI has no Service
+	CreateServiceCHI() *core.Service
+	// CreateServiceBlueGreen creates Service pointing to the active color of the CHI, nil in case it is not needed
+	CreateServiceBlueGreen() *core.Service
+	// CreateServiceCluster creates Service of the cluster, nil in case cluster has no Service
+	CreateServiceCluster(cluster *api.Cluster) *core.Service
+	// CreateServiceShard creates Service of the shard, nil in case shard has no Service
+	CreateServiceShard(shard *api.ChiShard) *core.Service
+	// CreateServiceHeadless creates headless Service governing all hosts of the CHI
+	CreateServiceHeadless() *core.Service
+	// CreateServiceHost creates Service of the host
+	CreateServiceHost(host *api.ChiHost) *core.Service
+	// CreateServiceNodeLocal creates Service exposing hosts to node-local agents, nil in case it is not needed
+	CreateServiceNodeLocal() *core.Service
+}
+
+// serviceGenerator is the default ServiceGenerator
+type serviceGenerator struct {
+	chi         *api.ClickHouseInstallation
+	labels      *Labeler
+	annotations *Annotator
+	a           log.Announcer
+}
+
+// NewServiceGenerator creates new default ServiceGenerator
+func NewServiceGenerator(chi *api.ClickHouseInstallation) ServiceGenerator {
+	return &serviceGenerator{
+		chi:         chi,
+		labels:      NewLabeler(chi),
+		annotations: NewAnnotator(chi),
+		a:           log.M(chi),
+	}
+}
+
+// CreateServiceCHI creates new core.Service for specified CHI
+func (g *serviceGenerator) CreateServiceCHI() *core.Service {
+	serviceName := CreateCHIServiceName(g.chi)
+	ownerReferences := getOwnerReferences(g.chi)
+
+	g.a.V(1).F().Info("%s/%s", g.chi.Namespace, serviceName)
+	if template, ok := g.chi.GetCHIServiceTemplate(); ok {
+		// .templates.ServiceTemplate specified
+		return g.createServiceFromTemplate(
+			template,
+			g.chi.Namespace,
+			serviceName,
+			g.labels.getServiceCHI(g.chi),
+			g.annotations.getServiceCHI(g.chi),
+			g.labels.getSelectorCHIScopeReady(),
+			ownerReferences,
+			macro(g.chi),
+		)
+	}
+
+	// Create default Service
+	// We do not have .templates.ServiceTemplate specified or it is incorrect
+	svc := &core.Service{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            serviceName,
+			Namespace:       g.chi.Namespace,
+			Labels:          macro(g.chi).Map(g.labels.getServiceCHI(g.chi)),
+			Annotations:     macro(g.chi).Map(g.annotations.getServiceCHI(g.chi)),
+			OwnerReferences: ownerReferences,
+		},
+		Spec: core.ServiceSpec{
+			// ClusterIP: templateDefaultsServiceClusterIP,
+			Ports: []core.ServicePort{
+				{
+					Name:       chDefaultHTTPPortName,
+					Protocol:   core.ProtocolTCP,
+					Port:       chDefaultHTTPPortNumber,
+					TargetPort: intstr.FromString(chDefaultHTTPPortName),
+				},
+				{
+					Name:       chDefaultTCPPortName,
+					Protocol:   core.ProtocolTCP,
+					Port:       chDefaultTCPPortNumber,
+					TargetPort: intstr.FromString(chDefaultTCPPortName),
+				},
+			},
+			Selector:              g.labels.getSelectorCHIScopeReady(),
+			Type:                  core.ServiceTypeLoadBalancer,
+			ExternalTrafficPolicy: core.ServiceExternalTrafficPolicyTypeLocal,
+		},
+	}
+	MakeObjectVersion(&svc.ObjectMeta, svc)
+	return svc
+}
+
+// CreateServiceBlueGreen creates new core.Service for the common service shared by blue/green generations of the CHI.
+// The service selects pods of the CHI, so it has to be applied to the generation being switched to only.
+func (g *serviceGenerator) CreateServiceBlueGreen() *core.Service {
+	svc := g.CreateServiceCHI()
+	if svc == nil {
+		return nil
+	}
+
+	g.a.V(1).F().Info("%s/%s", g.chi.Namespace, g.chi.Spec.BlueGreen.GetService())
+	// Common service is not an object of the CHI, it is neither labelled as CHI-owned nor owned by the CHI.
+	// It has to survive reconcile cleanups and deletion of both generations.
+	svc.Name = g.chi.Spec.BlueGreen.GetService()
+	svc.Labels = macro(g.chi).Map(g.labels.getServiceBlueGreen())
+	svc.OwnerReferences = nil
+	MakeObjectVersion(&svc.ObjectMeta, svc)
+	return svc
+}
+
+// CreateServiceCluster creates new core.Service for specified Cluster
+func (g *serviceGenerator) CreateServiceCluster(cluster *api.Cluster) *core.Service {
+	serviceName := CreateClusterServiceName(cluster)
+	ownerReferences := getOwnerReferences(g.chi)
+
+	g.a.V(1).F().Info("%s/%s", cluster.Address.Namespace, serviceName)
+	if template, ok := cluster.GetServiceTemplate(); ok {
+		// .templates.ServiceTemplate specified
+		return g.createServiceFromTemplate(
+			template,
+			cluster.Address.Namespace,
+			serviceName,
+			g.labels.getServiceCluster(cluster),
+			g.annotations.getServiceCluster(cluster),
+			getSelectorClusterScopeReady(cluster),
+			ownerReferences,
+			macro(cluster),
+		)
+	}
+	// No template specified, no need to create service
+	return nil
+}
+
+// CreateServiceShard creates new core.Service for specified Shard
+func (g *serviceGenerator) CreateServiceShard(shard *api.ChiShard) *core.Service {
+	serviceName := CreateShardServiceName(shard)
+	ownerReferences := getOwnerReferences(g.chi)
+
+	g.a.V(1).F().Info("%s/%s", shard.Address.Namespace, serviceName)
+	if template, ok := shard.GetServiceTemplate(); ok {
+		// .templates.ServiceTemplate specified
+		return g.createServiceFromTemplate(
+			template,
+			shard.Address.Namespace,
+			serviceName,
+			g.labels.getServiceShard(shard),
+			g.annotations.getServiceShard(shard),
+			getSelectorShardScopeReady(shard),
+			ownerReferences,
+			macro(shard),
+		)
+	}
+	// No template specified, no need to create service
+	return nil
+}
+
+// CreateServiceHeadless creates new headless core.Service, governing StatefulSets of all hosts.
+// Returns nil in case hosts have own Services
+func (g *serviceGenerator) CreateServiceHeadless() *core.Service {
+	if !g.chi.Spec.Defaults.IsHeadlessHostServices() {
+		return nil
+	}
+
+	svc := &core.Service{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            CreateHeadlessServiceName(g.chi),
+			Namespace:       g.chi.Namespace,
+			Labels:          macro(g.chi).Map(g.labels.getServiceHeadless()),
+			Annotations:     macro(g.chi).Map(g.annotations.getCHIScope()),
+			OwnerReferences: getOwnerReferences(g.chi),
+		},
+		Spec: core.ServiceSpec{
+			Selector:                 g.labels.GetSelectorCHIScope(),
+			ClusterIP:                core.ClusterIPNone,
+			Type:                     core.ServiceTypeClusterIP,
+			PublishNotReadyAddresses: true,
+		},
+	}
+	MakeObjectVersion(&svc.ObjectMeta, svc)
+	return svc
+}
+
+// CreateServiceNodeLocal creates new core.Service with Local internal traffic policy, selecting all hosts of the CHI.
+// Node-local agents, such as log collectors, reach the host co-located on their node only via the Service.
+// Returns nil in case hosts are not exposed via the Service
+func (g *serviceGenerator) CreateServiceNodeLocal() *core.Service {
+	if !g.chi.Spec.Defaults.GetRouting().IsNodeLocalService() {
+		return nil
+	}
+	host := g.chi.FirstHost()
+	if host == nil {
+		return nil
+	}
+
+	local := core.ServiceInternalTrafficPolicyLocal
+	svc := &core.Service{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            CreateNodeLocalServiceName(g.chi),
+			Namespace:       g.chi.Namespace,
+			Labels:          macro(g.chi).Map(g.labels.getServiceNodeLocal()),
+			Annotations:     macro(g.chi).Map(g.annotations.getCHIScope()),
+			OwnerReferences: getOwnerReferences(g.chi),
+		},
+		Spec: core.ServiceSpec{
+			Selector:              g.labels.getSelectorCHIScopeReady(),
+			Type:                  core.ServiceTypeClusterIP,
+			InternalTrafficPolicy: &local,
+		},
+	}
+	// Hosts are expected to share ports, ports of the first host are exposed
+	appendServicePorts(svc, host)
+	MakeObjectVersion(&svc.ObjectMeta, svc)
+	return svc
+}
+
+// CreateServiceHost creates new core.Service for specified host.
+// Returns nil in case hosts are reachable via headless Service
+func (g *serviceGenerator) CreateServiceHost(host *api.ChiHost) *core.Service {
+	if g.chi.Spec.Defaults.IsHeadlessHostServices() {
+		return nil
+	}
+
+	serviceName := CreateStatefulSetServiceName(host)
+	statefulSetName := CreateStatefulSetName(host)
+	ownerReferences := getOwnerReferences(g.chi)
+
+	g.a.V(1).F().Info("%s/%s for Set %s", host.Address.Namespace, serviceName, statefulSetName)
+	if template, ok := host.GetServiceTemplate(); ok {
+		// .templates.ServiceTemplate specified
+		return g.createServiceFromTemplate(
+			template,
+			host.Address.Namespace,
+			serviceName,
+			g.labels.getServiceHost(host),
+			g.annotations.getServiceHost(host),
+			GetSelectorHostScope(host),
+			ownerReferences,
+			macro(host),
+		)
+	}
+
+	// Create default Service
+	// We do not have .templates.ServiceTemplate specified or it is incorrect
+	svc := &core.Service{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            serviceName,
+			Namespace:       host.Address.Namespace,
+			Labels:          macro(host).Map(g.labels.getServiceHost(host)),
+			Annotations:     macro(host).Map(g.annotations.getServiceHost(host)),
+			OwnerReferences: ownerReferences,
+		},
+		Spec: core.ServiceSpec{
+			Selector:                 GetSelectorHostScope(host),
+			ClusterIP:                templateDefaultsServiceClusterIP,
+			Type:                     "ClusterIP",
+			PublishNotReadyAddresses: true,
+		},
+	}
+	appendServicePorts(svc, host)
+	MakeObjectVersion(&svc.ObjectMeta, svc)
+	return svc
+}
+
+func appendServicePorts(service *core.Service, host *api.ChiHost) {
+	if api.IsPortAssigned(host.TCPPort) {
+		service.Spec.Ports = append(service.Spec.Ports,
+			core.ServicePort{
+				Name:       chDefaultTCPPortName,
+				Protocol:   core.ProtocolTCP,
+				Port:       host.TCPPort,
+				TargetPort: intstr.FromInt(int(host.TCPPort)),
+			},
+		)
+	}
+	if api.IsPortAssigned(host.TLSPort) {
+		service.Spec.Ports = append(service.Spec.Ports,
+			core.ServicePort{
+				Name:       chDefaultTLSPortName,
+				Protocol:   core.ProtocolTCP,
+				Port:       host.TLSPort,
+				TargetPort: intstr.FromInt(int(host.TLSPort)),
+			},
+		)
+	}
+	if api.IsPortAssigned(host.HTTPPort) {
+		service.Spec.Ports = append(service.Spec.Ports,
+			core.ServicePort{
+				Name:       chDefaultHTTPPortName,
+				Protocol:   core.ProtocolTCP,
+				Port:       host.HTTPPort,
+				TargetPort: intstr.FromInt(int(host.HTTPPort)),
+			},
+		)
+	}
+	if api.IsPortAssigned(host.HTTPSPort) {
+		service.Spec.Ports = append(service.Spec.Ports,
+			core.ServicePort{
+				Name:       chDefaultHTTPSPortName,
+				Protocol:   core.ProtocolTCP,
+				Port:       host.HTTPSPort,
+				TargetPort: intstr.FromInt(int(host.HTTPSPort)),
+			},
+		)
+	}
+	if api.IsPortAssigned(host.InterserverHTTPPort) {
+		service.Spec.Ports = append(service.Spec.Ports,
+			core.ServicePort{
+				Name:       chDefaultInterserverHTTPPortName,
+				Protocol:   core.ProtocolTCP,
+				Port:       host.InterserverHTTPPort,
+				TargetPort: intstr.FromInt(int(host.InterserverHTTPPort)),
+			},
+		)
+	}
+}
+
+// verifyServiceTemplatePorts verifies ChiServiceTemplate to have reasonable ports specified
+func (g *serviceGenerator) verifyServiceTemplatePorts(template *api.ChiServiceTemplate) error {
+	for i := range template.Spec.Ports {
+		servicePort := &template.Spec.Ports[i]
+		if api.IsPortInvalid(servicePort.Port) {
+			msg := fmt.Sprintf("template:%s INCORRECT PORT:%d", template.Name, servicePort.Port)
+			g.a.V(1).F().Warning(msg)
+			return fmt.Errorf(msg)
+		}
+	}
+	return nil
+}
+
+// createServiceFromTemplate create Service from ChiServiceTemplate and additional info
+func (g *serviceGenerator) createServiceFromTemplate(
+	template *api.ChiServiceTemplate,
+	namespace string,
+	name string,
+	labels map[string]string,
+	annotations map[string]string,
+	selector map[string]string,
+	ownerReferences []meta.OwnerReference,
+	macro *macrosEngine,
+) *core.Service {
+
+	// Verify Ports
+	if err := g.verifyServiceTemplatePorts(template); err != nil {
+		return nil
+	}
+
+	// Create Service
+	service := &core.Service{
+		ObjectMeta: *template.ObjectMeta.DeepCopy(),
+		Spec:       *template.Spec.DeepCopy(),
+	}
+
+	// Overwrite .name and .namespace - they are not allowed to be specified in template
+	service.Name = name
+	service.Namespace = namespace
+	service.OwnerReferences = ownerReferences
+
+	// Combine labels and annotations
+	service.Labels = macro.Map(util.MergeStringMapsOverwrite(service.Labels, labels))
+	service.Annotations = macro.Map(util.MergeStringMapsOverwrite(service.Annotations, annotations))
+
+	// Append provided Selector to already specified Selector in template
+	service.Spec.Selector = util.MergeStringMapsOverwrite(service.Spec.Selector, selector)
+
+	// And after the object is ready we can put version label
+	MakeObjectVersion(&service.ObjectMeta, service)
+
+	return service
+}
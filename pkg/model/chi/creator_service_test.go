@@ -0,0 +1,96 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+func Test_CreateServiceBlueGreen(t *testing.T) {
+	require.NoError(t, chop.NewOffline(""))
+
+	chi := mustNormalize(t, &api.ClickHouseInstallation{
+		ObjectMeta: meta.ObjectMeta{Namespace: "test", Name: "blue", UID: "uid-1"},
+		Spec: api.ChiSpec{
+			BlueGreen: &api.ChiBlueGreen{Service: "events", Color: api.BlueGreenColorBlue},
+		},
+	})
+	service := model.NewCreator(chi).CreateServiceBlueGreen()
+
+	require.Equal(t, "events", service.Name)
+	// Common service has to survive deletion of either generation
+	require.Empty(t, service.OwnerReferences)
+	require.NotContains(t, service.Labels, model.LabelCHIName)
+	require.True(t, model.IsServiceSelectingCHI(service, chi))
+}
+
+func Test_ServiceGenerator(t *testing.T) {
+	chi := newTestCHI(t, generatorsTestManifest)
+	generator := model.NewServiceGenerator(chi)
+
+	service := generator.CreateServiceCHI()
+	require.Equal(t, "clickhouse-gen", service.Name)
+	require.Equal(t, core.ServiceTypeLoadBalancer, service.Spec.Type)
+	require.Len(t, service.Spec.Ports, 2)
+	require.True(t, model.IsServiceSelectingCHI(service, chi))
+
+	// Cluster and shard Services are created out of service templates only
+	require.Nil(t, generator.CreateServiceCluster(chi.FindCluster("main")))
+	require.Nil(t, generator.CreateServiceShard(&chi.FindCluster("main").Layout.Shards[0]))
+	// Hosts have own Services by default
+	require.Nil(t, generator.CreateServiceHeadless())
+
+	var names []string
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		service := generator.CreateServiceHost(host)
+		require.Equal(t, model.GetSelectorHostScope(host), service.Spec.Selector)
+		require.NotEmpty(t, service.Spec.Ports)
+		names = append(names, service.Name)
+		return nil
+	})
+	require.Equal(t, []string{"chi-gen-main-0-0", "chi-gen-main-1-0"}, names)
+}
+
+func Test_ServiceGenerator_HeadlessHostServices(t *testing.T) {
+	chi := newTestCHI(t, fmt.Sprintf(`
+metadata:
+  name: headless
+spec:
+  defaults:
+    hostServices: %s
+  configuration:
+    clusters:
+      - name: main
+`, api.HostServicesHeadless))
+	generator := model.NewServiceGenerator(chi)
+
+	headless := generator.CreateServiceHeadless()
+	require.Equal(t, core.ClusterIPNone, headless.Spec.ClusterIP)
+	require.True(t, headless.Spec.PublishNotReadyAddresses)
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		require.Nil(t, generator.CreateServiceHost(host))
+		return nil
+	})
+}
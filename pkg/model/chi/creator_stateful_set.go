@@ -0,0 +1,1032 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"fmt"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// StatefulSetGenerator generates StatefulSets of hosts of the CHI
+type StatefulSetGenerator interface {
+	// CreateStatefulSet creates StatefulSet of the host, scaled down to zero in case host is to be shut down
+	CreateStatefulSet(host *api.ChiHost, shutdown bool) *apps.StatefulSet
+}
+
+// statefulSetGenerator is the default StatefulSetGenerator
+type statefulSetGenerator struct {
+	chi         *api.ClickHouseInstallation
+	labels      *Labeler
+	annotations *Annotator
+	a           log.Announcer
+}
+
+// NewStatefulSetGenerator creates new default StatefulSetGenerator
+func NewStatefulSetGenerator(chi *api.ClickHouseInstallation) StatefulSetGenerator {
+	return &statefulSetGenerator{
+		chi:         chi,
+		labels:      NewLabeler(chi),
+		annotations: NewAnnotator(chi),
+		a:           log.M(chi),
+	}
+}
+
+// CreateStatefulSet creates new apps.StatefulSet
+func (g *statefulSetGenerator) CreateStatefulSet(host *api.ChiHost, shutdown bool) *apps.StatefulSet {
+	statefulSet := &apps.StatefulSet{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            CreateStatefulSetName(host),
+			Namespace:       host.Address.Namespace,
+			Labels:          macro(host).Map(g.labels.getHostScope(host, true)),
+			Annotations:     macro(host).Map(g.annotations.getHostScope(host)),
+			OwnerReferences: getOwnerReferences(g.chi),
+		},
+		Spec: apps.StatefulSetSpec{
+			Replicas:    host.GetStatefulSetReplicasNum(shutdown),
+			ServiceName: createStatefulSetGoverningServiceName(host),
+			Selector: &meta.LabelSelector{
+				MatchLabels: GetSelectorHostScope(host),
+			},
+
+			// IMPORTANT
+			// Template is to be setup later
+			Template: core.PodTemplateSpec{},
+
+			// IMPORTANT
+			// VolumeClaimTemplates are to be setup later
+			VolumeClaimTemplates: nil,
+
+			PodManagementPolicy: apps.OrderedReadyPodManagement,
+			UpdateStrategy: apps.StatefulSetUpdateStrategy{
+				Type: apps.RollingUpdateStatefulSetStrategyType,
+			},
+			RevisionHistoryLimit: chop.Config().GetRevisionHistoryLimit(),
+		},
+	}
+
+	g.setupStatefulSetPodTemplate(statefulSet, host)
+	g.setupStatefulSetVolumeClaimTemplates(statefulSet, host)
+	MakeObjectVersion(&statefulSet.ObjectMeta, statefulSet)
+
+	return statefulSet
+}
+
+// setupStatefulSetPodTemplate performs PodTemplate setup of StatefulSet
+func (g *statefulSetGenerator) setupStatefulSetPodTemplate(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	// Process Pod Template
+	podTemplate := g.getPodTemplate(host)
+	g.statefulSetApplyPodTemplate(statefulSet, podTemplate, host)
+
+	// Post-process StatefulSet
+	ensureStatefulSetTemplateIntegrity(statefulSet, host)
+	setupScheduling(statefulSet, g.chi.Spec.Defaults.GetScheduling())
+	setupSystemTuning(statefulSet, g.chi.Spec.Defaults.GetSystem())
+	setupMemoryManagement(statefulSet, g.chi.Spec.Defaults.GetMemoryManagement())
+	setupEnvVars(statefulSet, host)
+	setupReadinessGate(statefulSet, g.chi.Spec.Defaults.IsReadinessGateEnabled())
+	setupNodeLocalHostPorts(statefulSet, g.chi.Spec.Defaults.GetRouting().IsNodeLocalHostPort())
+	g.personalizeStatefulSetTemplate(statefulSet, host)
+}
+
+// setupReadinessGate makes pod wait for the operator's deep health check of the host to be Ready
+func setupReadinessGate(statefulSet *apps.StatefulSet, enabled bool) {
+	if !enabled {
+		return
+	}
+	podSpec := &statefulSet.Spec.Template.Spec
+	for _, gate := range podSpec.ReadinessGates {
+		if gate.ConditionType == PodConditionReady {
+			return
+		}
+	}
+	podSpec.ReadinessGates = append(podSpec.ReadinessGates, core.PodReadinessGate{
+		ConditionType: PodConditionReady,
+	})
+}
+
+// setupNodeLocalHostPorts exposes native and HTTP ports of the host as host ports of its node,
+// so node-local agents reach the co-located host only
+func setupNodeLocalHostPorts(statefulSet *apps.StatefulSet, enabled bool) {
+	if !enabled {
+		return
+	}
+	container, ok := getClickHouseContainer(statefulSet)
+	if !ok {
+		return
+	}
+	for i := range container.Ports {
+		switch port := &container.Ports[i]; port.Name {
+		case chDefaultTCPPortName, chDefaultHTTPPortName:
+			port.HostPort = port.ContainerPort
+		}
+	}
+}
+
+// ensureStatefulSetTemplateIntegrity
+func ensureStatefulSetTemplateIntegrity(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	ensureClickHouseContainerSpecified(statefulSet, host)
+	ensureImageSpecified(statefulSet)
+	ensureProbesSpecified(statefulSet, host)
+	ensureNamedPortsSpecified(statefulSet, host)
+}
+
+// setupScheduling applies CHI-wide scheduling defaults to the pod.
+// Values specified explicitly by the pod template take precedence
+func setupScheduling(statefulSet *apps.StatefulSet, scheduling *api.ChiScheduling) {
+	if scheduling == nil {
+		return
+	}
+	podSpec := &statefulSet.Spec.Template.Spec
+
+	if runtimeClassName := scheduling.GetRuntimeClassName(); (runtimeClassName != "") && (podSpec.RuntimeClassName == nil) {
+		podSpec.RuntimeClassName = &runtimeClassName
+	}
+
+	if container, ok := getClickHouseContainer(statefulSet); ok {
+		for name, quantity := range scheduling.GetExtendedResources() {
+			if _, ok := container.Resources.Limits[name]; ok {
+				continue
+			}
+			// Extended resources can not be overcommitted, so requests are equal to limits
+			if container.Resources.Limits == nil {
+				container.Resources.Limits = make(core.ResourceList)
+			}
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = make(core.ResourceList)
+			}
+			container.Resources.Limits[name] = quantity.DeepCopy()
+			container.Resources.Requests[name] = quantity.DeepCopy()
+		}
+	}
+
+	if dedicated := scheduling.GetDedicatedNodes(); dedicated.IsValid() {
+		podSpec.Tolerations = append(podSpec.Tolerations, core.Toleration{
+			Key:      dedicated.Key,
+			Operator: core.TolerationOpEqual,
+			Value:    dedicated.Value,
+			Effect:   dedicated.GetEffect(),
+		})
+		appendRequiredNodeSelectorRequirement(podSpec, core.NodeSelectorRequirement{
+			Key:      dedicated.Key,
+			Operator: core.NodeSelectorOpIn,
+			Values:   []string{dedicated.Value},
+		})
+	}
+}
+
+// setupSystemTuning applies CHI-wide kernel parameters, memory tuning and process limits to the pod.
+// Values specified explicitly by the pod template take precedence
+func setupSystemTuning(statefulSet *apps.StatefulSet, tuning *api.ChiSystemTuning) {
+	if tuning == nil {
+		return
+	}
+	podSpec := &statefulSet.Spec.Template.Spec
+
+	if sysctls := tuning.GetSysctls(); len(sysctls) > 0 {
+		if podSpec.SecurityContext == nil {
+			podSpec.SecurityContext = &core.PodSecurityContext{}
+		}
+		specified := make(map[string]bool)
+		for _, sysctl := range podSpec.SecurityContext.Sysctls {
+			specified[sysctl.Name] = true
+		}
+		// Sorted order keeps StatefulSet stable between reconciles
+		for _, name := range util.MapSortedKeys(sysctls) {
+			if !specified[name] {
+				podSpec.SecurityContext.Sysctls = append(podSpec.SecurityContext.Sysctls, core.Sysctl{
+					Name:  name,
+					Value: sysctls[name],
+				})
+			}
+		}
+	}
+
+	container, ok := getClickHouseContainer(statefulSet)
+	if !ok {
+		return
+	}
+
+	memory := tuning.GetMemory()
+	for name, quantity := range memory.GetHugePages() {
+		if _, ok := container.Resources.Limits[name]; ok {
+			continue
+		}
+		// Huge pages can not be overcommitted, so requests are equal to limits
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = make(core.ResourceList)
+		}
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = make(core.ResourceList)
+		}
+		container.Resources.Limits[name] = quantity.DeepCopy()
+		container.Resources.Requests[name] = quantity.DeepCopy()
+	}
+	if memory.IsMLockExecutable() {
+		// Locking memory requires IPC_LOCK capability
+		addCapability(container, "IPC_LOCK")
+	}
+
+	ulimits := tuning.GetUlimits()
+	if ulimits.IsEmpty() || (len(container.Command) > 0) {
+		// Custom command is not wrapped
+		return
+	}
+	// Wrap entrypoint of the image with shell setting limits. Args of the container are passed to the entrypoint
+	script := ""
+	if ulimits.NoFile > 0 {
+		script += fmt.Sprintf("ulimit -n %d && ", ulimits.NoFile)
+	}
+	if ulimits.NProc > 0 {
+		script += fmt.Sprintf("ulimit -u %d && ", ulimits.NProc)
+	}
+	script += fmt.Sprintf("exec %s \"$@\"", ulimits.GetEntrypoint())
+	container.Command = []string{"/bin/sh", "-c", script, "--"}
+}
+
+// setupMemoryManagement sets memory limit and request of ClickHouse container.
+// Memory management provides defaults only, resources specified by pod templates are kept as is
+func setupMemoryManagement(statefulSet *apps.StatefulSet, memory *api.ChiMemoryManagement) {
+	container, ok := getClickHouseContainer(statefulSet)
+	if !ok || (memory == nil) {
+		return
+	}
+
+	request := memory.Request
+	if memory.Limit != nil {
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = make(core.ResourceList)
+		}
+		if _, ok := container.Resources.Limits[core.ResourceMemory]; !ok {
+			container.Resources.Limits[core.ResourceMemory] = memory.Limit.DeepCopy()
+		}
+		if request == nil {
+			// Request defaults to the limit container ends up with, which may be specified by pod template
+			limit := container.Resources.Limits[core.ResourceMemory]
+			request = &limit
+		}
+	}
+	if request != nil {
+		if container.Resources.Requests == nil {
+			container.Resources.Requests = make(core.ResourceList)
+		}
+		if _, ok := container.Resources.Requests[core.ResourceMemory]; !ok {
+			container.Resources.Requests[core.ResourceMemory] = request.DeepCopy()
+		}
+	}
+	if memory.HasNegativeOOMScoreAdj() {
+		// Lowering OOM score of the process requires SYS_RESOURCE capability
+		addCapability(container, "SYS_RESOURCE")
+	}
+}
+
+// addCapability adds capability to the container, unless it is added already
+func addCapability(container *core.Container, capability core.Capability) {
+	if container.SecurityContext == nil {
+		container.SecurityContext = &core.SecurityContext{}
+	}
+	if container.SecurityContext.Capabilities == nil {
+		container.SecurityContext.Capabilities = &core.Capabilities{}
+	}
+	capabilities := container.SecurityContext.Capabilities
+	if !hasCapability(capabilities.Add, capability) {
+		capabilities.Add = append(capabilities.Add, capability)
+	}
+}
+
+// hasCapability checks whether capability is listed
+func hasCapability(capabilities []core.Capability, capability core.Capability) bool {
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// setupEnvVars setup ENV vars for clickhouse container
+func setupEnvVars(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	container, ok := getClickHouseContainer(statefulSet)
+	if !ok {
+		return
+	}
+
+	container.Env = append(container.Env, host.GetCHI().Attributes.AdditionalEnvVars...)
+}
+
+// ensureClickHouseContainerSpecified
+func ensureClickHouseContainerSpecified(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	_, ok := getClickHouseContainer(statefulSet)
+	if ok {
+		return
+	}
+
+	// No ClickHouse container available, let's add one
+	addContainer(
+		&statefulSet.Spec.Template.Spec,
+		newDefaultClickHouseContainer(host),
+	)
+}
+
+// ensureImageSpecified selects default image for ClickHouse container with no image specified.
+// Image is selected by node architecture the pod is bound to via nodeSelector.
+// Pod not bound to any architecture is restricted by node affinity to architectures the selected image is listed for
+func ensureImageSpecified(statefulSet *apps.StatefulSet) {
+	container, ok := getClickHouseContainer(statefulSet)
+	if !ok || (container.Image != "") {
+		return
+	}
+
+	podSpec := &statefulSet.Spec.Template.Spec
+	image, architectures := chop.Config().ClickHouse.Image.Select(podSpec.NodeSelector[core.LabelArchStable])
+	if image == "" {
+		image = defaultClickHouseDockerImage
+	}
+	container.Image = image
+	if len(architectures) > 0 {
+		appendRequiredNodeSelectorRequirement(podSpec, core.NodeSelectorRequirement{
+			Key:      core.LabelArchStable,
+			Operator: core.NodeSelectorOpIn,
+			Values:   architectures,
+		})
+	}
+}
+
+// ensureClickHouseLogContainerSpecified
+func ensureClickHouseLogContainerSpecified(statefulSet *apps.StatefulSet) {
+	_, ok := getClickHouseLogContainer(statefulSet)
+	if ok {
+		return
+	}
+
+	// No ClickHouse Log container available, let's add one
+
+	addContainer(
+		&statefulSet.Spec.Template.Spec,
+		newDefaultLogContainer(),
+	)
+}
+
+// ensureProbesSpecified
+func ensureProbesSpecified(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	container, ok := getClickHouseContainer(statefulSet)
+	if !ok {
+		return
+	}
+	if container.LivenessProbe == nil {
+		container.LivenessProbe = newDefaultLivenessProbe(host)
+	}
+	if container.ReadinessProbe == nil {
+		container.ReadinessProbe = newDefaultReadinessProbe(host)
+	}
+}
+
+// personalizeStatefulSetTemplate
+func (g *statefulSetGenerator) personalizeStatefulSetTemplate(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	// Ensure pod created by this StatefulSet has alias 127.0.0.1
+	statefulSet.Spec.Template.Spec.HostAliases = []core.HostAlias{
+		{
+			IP:        "127.0.0.1",
+			Hostnames: []string{CreatePodHostname(host)},
+		},
+	}
+
+	// Setup volumes
+	g.statefulSetSetupVolumes(statefulSet, host)
+	// Setup statefulSet according to troubleshoot mode (if any)
+	g.setupTroubleshoot(statefulSet)
+	// Setup dedicated log container
+	g.setupLogContainer(statefulSet, host)
+}
+
+// setupTroubleshoot
+func (g *statefulSetGenerator) setupTroubleshoot(statefulSet *apps.StatefulSet) {
+	if !g.chi.IsTroubleshoot() {
+		// We are not troubleshooting
+		return
+	}
+
+	container, ok := getClickHouseContainer(statefulSet)
+	if !ok {
+		// Unable to locate ClickHouse container
+		return
+	}
+
+	// Let's setup troubleshooting in ClickHouse container
+
+	sleep := " || sleep 1800"
+	if len(container.Command) > 0 {
+		// In case we have user-specified command, let's
+		// append troubleshooting-capable tail and hope for the best
+		container.Command[len(container.Command)-1] += sleep
+	} else {
+		// Assume standard ClickHouse container is used
+		// Substitute entrypoint with troubleshooting-capable command
+		container.Command = []string{
+			"/bin/sh",
+			"-c",
+			"/entrypoint.sh" + sleep,
+		}
+	}
+	// Appended `sleep` command makes Pod unable to respond to probes, and probes would cause unexpected restart.
+	// Thus we need to disable all probes in troubleshooting mode.
+	container.LivenessProbe = nil
+	container.ReadinessProbe = nil
+}
+
+// setupLogContainer
+func (g *statefulSetGenerator) setupLogContainer(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	statefulSetName := CreateStatefulSetName(host)
+	// In case we have default LogVolumeClaimTemplate specified - need to append log container to Pod Template
+	if host.Templates.HasLogVolumeClaimTemplate() {
+		ensureClickHouseLogContainerSpecified(statefulSet)
+
+		g.a.V(1).F().Info("add log container for statefulSet %s", statefulSetName)
+	}
+}
+
+// getPodTemplate gets Pod Template to be used to create StatefulSet
+func (g *statefulSetGenerator) getPodTemplate(host *api.ChiHost) *api.ChiPodTemplate {
+	statefulSetName := CreateStatefulSetName(host)
+
+	// Which pod template would be used - either explicitly defined in or a default one
+	podTemplate, ok := host.GetPodTemplate()
+	if ok {
+		// Host references known PodTemplate
+		// Make local copy of this PodTemplate, in order not to spoil the original common-used template
+		podTemplate = podTemplate.DeepCopy()
+		g.a.V(3).F().Info("statefulSet %s use custom template: %s", statefulSetName, podTemplate.Name)
+	} else {
+		// Host references UNKNOWN PodTemplate, will use default one
+		podTemplate = newDefaultPodTemplate(statefulSetName, host)
+		if host.Templates.HasPodTemplate() {
+			g.a.V(1).F().Warning("statefulSet %s references unknown template: %s, use default generated template", statefulSetName, host.Templates.GetPodTemplate())
+		} else {
+			g.a.V(3).F().Info("statefulSet %s use default generated template", statefulSetName)
+		}
+	}
+
+	// Here we have local copy of Pod Template, to be used to create StatefulSet
+	// Now we can customize this Pod Template for particular host
+
+	prepareAffinity(podTemplate, host)
+
+	return podTemplate
+}
+
+// statefulSetSetupVolumes setup all volumes
+func (g *statefulSetGenerator) statefulSetSetupVolumes(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	g.statefulSetSetupVolumesForConfigMaps(statefulSet, host)
+	g.statefulSetSetupVolumesForSecrets(statefulSet, host)
+}
+
+// statefulSetSetupVolumesForConfigMaps adds to each container in the Pod VolumeMount objects
+func (g *statefulSetGenerator) statefulSetSetupVolumesForConfigMaps(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	configMapHostName := CreateConfigMapHostName(host)
+	configMapCommonName := CreateConfigMapCommonName(g.chi)
+	configMapCommonUsersName := CreateConfigMapCommonUsersName(g.chi)
+	configDirs := getConfigDirs(host)
+
+	// Add all ConfigMap objects as Volume objects of type ConfigMap
+	g.statefulSetAppendVolumes(
+		statefulSet,
+		newVolumeForConfigMap(configMapCommonName),
+		newVolumeForUsers(configMapCommonUsersName, g.chi.Spec.Configuration.GetUsersFrom()),
+		newVolumeForConfigMap(configMapHostName),
+		//newVolumeForConfigMap(configMapHostMigrationName),
+	)
+
+	// And reference these Volumes in each Container via VolumeMount
+	// So Pod will have ConfigMaps mounted as Volumes
+	for i := range statefulSet.Spec.Template.Spec.Containers {
+		// Convenience wrapper
+		container := &statefulSet.Spec.Template.Spec.Containers[i]
+		g.containerAppendVolumeMounts(
+			container,
+			newVolumeMount(configMapCommonName, configDirs.GetCommon(dirPathCommonConfig)),
+			newVolumeMount(configMapCommonUsersName, configDirs.GetUsers(dirPathUsersConfig)),
+			newVolumeMount(configMapHostName, configDirs.GetHost(dirPathHostConfig)),
+		)
+	}
+}
+
+// getConfigDirs gets config folders specified by pod template of the host, if any
+func getConfigDirs(host *api.ChiHost) *api.ChiConfigDirs {
+	if podTemplate, ok := host.GetPodTemplate(); ok {
+		return podTemplate.ConfigDirs
+	}
+	return nil
+}
+
+// statefulSetSetupVolumesForSecrets adds to each container in the Pod VolumeMount objects
+func (g *statefulSetGenerator) statefulSetSetupVolumesForSecrets(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+
+	// Add all ConfigMap objects as Volume objects of type ConfigMap
+	g.statefulSetAppendVolumes(
+		statefulSet,
+		host.GetCHI().Attributes.AdditionalVolumes...,
+	)
+
+	// And reference these Volumes in each Container via VolumeMount
+	// So Pod will have Secrets mounted as Volumes
+	for i := range statefulSet.Spec.Template.Spec.Containers {
+		// Convenience wrapper
+		container := &statefulSet.Spec.Template.Spec.Containers[i]
+		g.containerAppendVolumeMounts(
+			container,
+			host.GetCHI().Attributes.AdditionalVolumeMounts...,
+		)
+	}
+}
+
+// statefulSetAppendUsedPVCTemplates appends all PVC templates which are used (referenced by name) by containers
+// to the StatefulSet.Spec.VolumeClaimTemplates list
+func (g *statefulSetGenerator) statefulSetAppendUsedPVCTemplates(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	// VolumeClaimTemplates, that are directly referenced in containers' VolumeMount object(s)
+	// are appended to StatefulSet's Spec.VolumeClaimTemplates slice
+	//
+	// Deal with `volumeMounts` of a `container`, located by the path:
+	// .spec.templates.podTemplates.*.spec.containers.volumeMounts.*
+	for i := range statefulSet.Spec.Template.Spec.Containers {
+		// Convenience wrapper
+		container := &statefulSet.Spec.Template.Spec.Containers[i]
+		for j := range container.VolumeMounts {
+			if volumeClaimTemplate, ok := g.getVolumeClaimTemplate(&container.VolumeMounts[j]); ok {
+				g.statefulSetAppendPVCTemplate(statefulSet, host, volumeClaimTemplate)
+			}
+		}
+	}
+}
+
+// statefulSetAppendVolumeMountsForDataAndLogVolumeClaimTemplates
+// appends VolumeMounts for Data and Log VolumeClaimTemplates on all containers.
+// Creates VolumeMounts for Data and Log volumes in case these volume templates are specified in `templates`.
+func (g *statefulSetGenerator) statefulSetAppendVolumeMountsForDataAndLogVolumeClaimTemplates(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	// Mount all named (data and log so far) VolumeClaimTemplates into all containers
+	for i := range statefulSet.Spec.Template.Spec.Containers {
+		// Convenience wrapper
+		container := &statefulSet.Spec.Template.Spec.Containers[i]
+		g.containerAppendVolumeMounts(
+			container,
+			newVolumeMount(host.Templates.GetDataVolumeClaimTemplate(), dirPathClickHouseData),
+		)
+		g.containerAppendVolumeMounts(
+			container,
+			newVolumeMount(host.Templates.GetLogVolumeClaimTemplate(), dirPathClickHouseLog),
+		)
+	}
+}
+
+// setupStatefulSetVolumeClaimTemplates performs VolumeClaimTemplate setup for Containers in PodTemplate of a StatefulSet
+func (g *statefulSetGenerator) setupStatefulSetVolumeClaimTemplates(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	g.statefulSetAppendVolumeMountsForDataAndLogVolumeClaimTemplates(statefulSet, host)
+	g.statefulSetAppendUsedPVCTemplates(statefulSet, host)
+}
+
+// statefulSetApplyPodTemplate fills StatefulSet.Spec.Template with data from provided ChiPodTemplate
+func (g *statefulSetGenerator) statefulSetApplyPodTemplate(
+	statefulSet *apps.StatefulSet,
+	template *api.ChiPodTemplate,
+	host *api.ChiHost,
+) {
+	// StatefulSet's pod template is not directly compatible with ChiPodTemplate,
+	// we need to extract some fields from ChiPodTemplate and apply on StatefulSet
+	statefulSet.Spec.Template = core.PodTemplateSpec{
+		ObjectMeta: meta.ObjectMeta{
+			Name: template.Name,
+			Labels: macro(host).Map(util.MergeStringMapsOverwrite(
+				g.labels.getHostScopeReady(host, true),
+				template.ObjectMeta.Labels,
+			)),
+			Annotations: macro(host).Map(util.MergeStringMapsOverwrite(
+				g.annotations.getHostScope(host),
+				template.ObjectMeta.Annotations,
+			)),
+		},
+		Spec: *template.Spec.DeepCopy(),
+	}
+
+	if statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds == nil {
+		statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds = chop.Config().GetTerminationGracePeriod()
+	}
+}
+
+// getContainer gets container from the StatefulSet either by name or by index
+func getContainer(statefulSet *apps.StatefulSet, name string, index int) (*core.Container, bool) {
+	return getPodSpecContainer(&statefulSet.Spec.Template.Spec, name, index)
+}
+
+// getPodSpecContainer finds container of the pod spec by name and falls back to container by index
+func getPodSpecContainer(podSpec *core.PodSpec, name string, index int) (*core.Container, bool) {
+	if len(name) > 0 {
+		// Find by name
+		for i := range podSpec.Containers {
+			container := &podSpec.Containers[i]
+			if container.Name == name {
+				return container, true
+			}
+		}
+	}
+
+	if index >= 0 {
+		// Find by index
+		if len(podSpec.Containers) > index {
+			return &podSpec.Containers[index], true
+		}
+	}
+
+	return nil, false
+}
+
+// getClickHouseContainer
+func getClickHouseContainer(statefulSet *apps.StatefulSet) (*core.Container, bool) {
+	return getContainer(statefulSet, clickHouseContainerName, 0)
+}
+
+// GetPodClickHouseImage gets image of ClickHouse container of the pod, if any
+func GetPodClickHouseImage(pod *core.Pod) string {
+	if container, ok := getPodSpecContainer(&pod.Spec, clickHouseContainerName, 0); ok {
+		return container.Image
+	}
+	return ""
+}
+
+// getClickHouseLogContainer
+func getClickHouseLogContainer(statefulSet *apps.StatefulSet) (*core.Container, bool) {
+	return getContainer(statefulSet, clickHouseLogContainerName, -1)
+}
+
+// IsStatefulSetGeneration returns whether StatefulSet has requested generation or not
+func IsStatefulSetGeneration(statefulSet *apps.StatefulSet, generation int64) bool {
+	if statefulSet == nil {
+		return false
+	}
+
+	// StatefulSet has .spec generation we are looking for
+	return (statefulSet.Generation == generation) &&
+		// and this .spec generation is being applied to replicas - it is observed right now
+		(statefulSet.Status.ObservedGeneration == statefulSet.Generation) &&
+		// and all replicas are of expected generation
+		(statefulSet.Status.CurrentReplicas == *statefulSet.Spec.Replicas) &&
+		// and all replicas are updated - meaning rolling update completed over all replicas
+		(statefulSet.Status.UpdatedReplicas == *statefulSet.Spec.Replicas) &&
+		// and current revision is an updated one - meaning rolling update completed over all replicas
+		(statefulSet.Status.CurrentRevision == statefulSet.Status.UpdateRevision)
+}
+
+// IsStatefulSetReady returns whether StatefulSet is ready
+func IsStatefulSetReady(statefulSet *apps.StatefulSet) bool {
+	if statefulSet == nil {
+		return false
+	}
+
+	if statefulSet.Spec.Replicas == nil {
+		return false
+	}
+	// All replicas are in "Ready" status - meaning ready to be used - no failure inside
+	return statefulSet.Status.ReadyReplicas == *statefulSet.Spec.Replicas
+}
+
+// IsStatefulSetNotReady returns whether StatefulSet is not ready
+func IsStatefulSetNotReady(statefulSet *apps.StatefulSet) bool {
+	if statefulSet == nil {
+		return false
+	}
+
+	return !IsStatefulSetReady(statefulSet)
+}
+
+// StrStatefulSetStatus returns human-friendly string representation of StatefulSet status
+func StrStatefulSetStatus(status *apps.StatefulSetStatus) string {
+	return fmt.Sprintf(
+		"ObservedGeneration:%d Replicas:%d ReadyReplicas:%d CurrentReplicas:%d UpdatedReplicas:%d CurrentRevision:%s UpdateRevision:%s",
+		status.ObservedGeneration,
+		status.Replicas,
+		status.ReadyReplicas,
+		status.CurrentReplicas,
+		status.UpdatedReplicas,
+		status.CurrentRevision,
+		status.UpdateRevision,
+	)
+}
+
+// ensureNamedPortsSpecified
+func ensureNamedPortsSpecified(statefulSet *apps.StatefulSet, host *api.ChiHost) {
+	// Ensure ClickHouse container has all named ports specified
+	container, ok := getClickHouseContainer(statefulSet)
+	if !ok {
+		return
+	}
+	keepHostPort := host.GetCHI().Spec.Defaults.IsDistributeHostPorts()
+	ensurePortByName(container, chDefaultTCPPortName, host.TCPPort, keepHostPort)
+	ensurePortByName(container, chDefaultTLSPortName, host.TLSPort, keepHostPort)
+	ensurePortByName(container, chDefaultHTTPPortName, host.HTTPPort, keepHostPort)
+	ensurePortByName(container, chDefaultHTTPSPortName, host.HTTPSPort, keepHostPort)
+	ensurePortByName(container, chDefaultInterserverHTTPPortName, host.InterserverHTTPPort, keepHostPort)
+}
+
+// ensurePortByName
+func ensurePortByName(container *core.Container, name string, port int32, keepHostPort bool) {
+	if api.IsPortUnassigned(port) {
+		return
+	}
+
+	// Find port with specified name
+	for i := range container.Ports {
+		containerPort := &container.Ports[i]
+		if containerPort.Name == name {
+			// Assign value to existing port.
+			// In case host ports are distributed, port exposed on the node keeps being exposed,
+			// but on the port allocated to the host, since hosts sharing the node are distributed over different ports
+			if keepHostPort && (containerPort.HostPort != 0) {
+				containerPort.HostPort = port
+			} else {
+				containerPort.HostPort = 0
+			}
+			containerPort.ContainerPort = port
+			return
+		}
+	}
+
+	// Port with specified name not found. Need to append
+	container.Ports = append(container.Ports, core.ContainerPort{
+		Name:          name,
+		ContainerPort: port,
+	})
+}
+
+// setupStatefulSetApplyVolumeMount applies .templates.volumeClaimTemplates.* to a StatefulSet
+func (g *statefulSetGenerator) setupStatefulSetApplyVolumeMount(
+	host *api.ChiHost,
+	statefulSet *apps.StatefulSet,
+	containerName string,
+	volumeMount core.VolumeMount,
+) error {
+	//
+	// Sanity checks
+	//
+
+	// Specified (referenced from volumeMount) VolumeClaimTemplate has to be available as well
+	if _, ok := g.getVolumeClaimTemplate(&volumeMount); !ok {
+		// Incorrect/unknown .templates.VolumeClaimTemplate specified
+		g.a.V(1).F().Warning("Can not find VolumeClaimTemplate for VolumeMount: %s. Volume claim can not be mounted", volumeMount.Name)
+		return nil
+	}
+
+	// Specified container has to be available
+	container := getContainerByName(statefulSet, containerName)
+	if container == nil {
+		g.a.V(1).F().Warning("Can not find container: %s. Volume claim can not be mounted", containerName)
+		return nil
+	}
+
+	// Looks like all components are in place
+
+	// Mount specified (by volumeMount.Name) VolumeClaimTemplate into volumeMount.Path (say into '/var/lib/clickhouse')
+	//
+	// A container wants to have this VolumeClaimTemplate mounted into `mountPath` in case:
+	// 1. This VolumeClaimTemplate is NOT already mounted in the container with any VolumeMount (to avoid double-mount of a VolumeClaimTemplate)
+	// 2. And specified `mountPath` (say '/var/lib/clickhouse') is NOT already mounted with any VolumeMount (to avoid double-mount/rewrite into single `mountPath`)
+
+	for i := range container.VolumeMounts {
+		// Convenience wrapper
+		existingVolumeMount := &container.VolumeMounts[i]
+
+		// 1. Check whether this VolumeClaimTemplate is already listed in VolumeMount of this container
+		if volumeMount.Name == existingVolumeMount.Name {
+			// This .templates.VolumeClaimTemplate is already used in VolumeMount
+			g.a.V(1).F().Warning(
+				"StatefulSet:%s container:%s volumeClaimTemplateName:%s already used. Skip it and all the rest.",
+				statefulSet.Name,
+				container.Name,
+				volumeMount.Name,
+			)
+			return nil
+		}
+
+		// 2. Check whether `mountPath` (say '/var/lib/clickhouse') is already mounted
+		if volumeMount.MountPath == existingVolumeMount.MountPath {
+			// `mountPath` (say /var/lib/clickhouse) is already mounted
+			g.a.V(1).F().Warning(
+				"StatefulSet:%s container:%s mountPath:%s already used. Skip it and all the rest.",
+				statefulSet.Name,
+				container.Name,
+				volumeMount.MountPath,
+			)
+			return nil
+		}
+	}
+
+	// This VolumeClaimTemplate is not used explicitly by name and `mountPath` (say /var/lib/clickhouse) is not used also.
+	// Let's mount this VolumeClaimTemplate into `mountPath` (say '/var/lib/clickhouse') of a container
+	if volumeClaimTemplate, ok := g.getVolumeClaimTemplate(&volumeMount); ok {
+		// Add VolumeClaimTemplate to StatefulSet
+		g.statefulSetAppendPVCTemplate(statefulSet, host, volumeClaimTemplate)
+		// Add VolumeMount to ClickHouse container to `mountPath` point
+		g.containerAppendVolumeMounts(
+			container,
+			volumeMount,
+		)
+	}
+
+	g.a.V(1).F().Info(
+		"StatefulSet: %s container: %s mounted VolumeMount: %s onto path: %s",
+		statefulSet.Name,
+		container.Name,
+		volumeMount.Name,
+		volumeMount.MountPath,
+	)
+
+	return nil
+}
+
+// statefulSetAppendVolumes appends multiple Volume(s) to the specified StatefulSet
+func (g *statefulSetGenerator) statefulSetAppendVolumes(statefulSet *apps.StatefulSet, volumes ...core.Volume) {
+	statefulSet.Spec.Template.Spec.Volumes = append(
+		statefulSet.Spec.Template.Spec.Volumes,
+		volumes...,
+	)
+}
+
+// containerAppendVolumeMounts appends multiple VolumeMount(s) to the specified container
+func (g *statefulSetGenerator) containerAppendVolumeMounts(container *core.Container, volumeMounts ...core.VolumeMount) {
+	for _, volumeMount := range volumeMounts {
+		g.containerAppendVolumeMount(container, volumeMount)
+	}
+}
+
+// containerAppendVolumeMount appends one VolumeMount to the specified container
+func (g *statefulSetGenerator) containerAppendVolumeMount(container *core.Container, volumeMount core.VolumeMount) {
+	//
+	// Sanity checks
+	//
+
+	if container == nil {
+		return
+	}
+
+	// VolumeMount has to have reasonable data - Name and MountPath
+	if (volumeMount.Name == "") || (volumeMount.MountPath == "") {
+		return
+	}
+
+	// Check that:
+	// 1. Mountable item (VolumeClaimTemplate or Volume) specified in this VolumeMount is NOT already mounted
+	//    in this container by any other VolumeMount (to avoid double-mount of a mountable item)
+	// 2. And specified `mountPath` (say '/var/lib/clickhouse') is NOT already mounted in this container
+	//    by any VolumeMount (to avoid double-mount/rewrite into single `mountPath`)
+	for i := range container.VolumeMounts {
+		// Convenience wrapper
+		existingVolumeMount := &container.VolumeMounts[i]
+
+		// 1. Check whether this mountable item is already listed in VolumeMount of this container
+		if volumeMount.Name == existingVolumeMount.Name {
+			// This .templates.VolumeClaimTemplate is already used in VolumeMount
+			g.a.V(1).F().Warning(
+				"container.Name:%s volumeMount.Name:%s already used",
+				container.Name,
+				volumeMount.Name,
+			)
+			return
+		}
+
+		// 2. Check whether `mountPath` (say '/var/lib/clickhouse') is already mounted
+		if volumeMount.MountPath == existingVolumeMount.MountPath {
+			// `mountPath` (say /var/lib/clickhouse) is already mounted
+			g.a.V(1).F().Warning(
+				"container.Name:%s volumeMount.MountPath:%s already used",
+				container.Name,
+				volumeMount.MountPath,
+			)
+			return
+		}
+	}
+
+	// Add VolumeMount to ClickHouse container to `mountPath` point
+	container.VolumeMounts = append(
+		container.VolumeMounts,
+		volumeMount,
+	)
+
+	g.a.V(3).F().Info(
+		"container:%s volumeMount added: %s on %s",
+		container.Name,
+		volumeMount.Name,
+		volumeMount.MountPath,
+	)
+
+	return
+}
+
+// statefulSetAppendPVCTemplate appends to StatefulSet.Spec.VolumeClaimTemplates new entry with data from provided 'src' ChiVolumeClaimTemplate
+func (g *statefulSetGenerator) statefulSetAppendPVCTemplate(
+	statefulSet *apps.StatefulSet,
+	host *api.ChiHost,
+	volumeClaimTemplate *api.ChiVolumeClaimTemplate,
+) {
+	// Since we have the same names for PVs produced from both VolumeClaimTemplates and Volumes,
+	// we need to check naming for all of them
+
+	// Check whether provided VolumeClaimTemplate is already listed in statefulSet.Spec.VolumeClaimTemplates
+	for i := range statefulSet.Spec.VolumeClaimTemplates {
+		// Convenience wrapper
+		_volumeClaimTemplate := &statefulSet.Spec.VolumeClaimTemplates[i]
+		if _volumeClaimTemplate.Name == volumeClaimTemplate.Name {
+			// This VolumeClaimTemplate is already listed in statefulSet.Spec.VolumeClaimTemplates
+			// No need to add it second time
+			return
+		}
+	}
+
+	// Check whether provided VolumeClaimTemplate is already listed in statefulSet.Spec.Template.Spec.Volumes
+	for i := range statefulSet.Spec.Template.Spec.Volumes {
+		// Convenience wrapper
+		_volume := &statefulSet.Spec.Template.Spec.Volumes[i]
+		if _volume.Name == volumeClaimTemplate.Name {
+			// This VolumeClaimTemplate is already listed in statefulSet.Spec.Template.Spec.Volumes
+			// No need to add it second time
+			return
+		}
+	}
+
+	// Provided VolumeClaimTemplate is not listed neither in
+	// statefulSet.Spec.Template.Spec.Volumes
+	// nor in
+	// statefulSet.Spec.VolumeClaimTemplates
+	// so, let's add it
+
+	if operatorShouldCreatePVC(host, volumeClaimTemplate) {
+		claimName := CreatePVCNameByVolumeClaimTemplate(host, volumeClaimTemplate)
+		statefulSet.Spec.Template.Spec.Volumes = append(
+			statefulSet.Spec.Template.Spec.Volumes,
+			newVolumeForPVC(volumeClaimTemplate.Name, claimName),
+		)
+	} else {
+		statefulSet.Spec.VolumeClaimTemplates = append(
+			statefulSet.Spec.VolumeClaimTemplates,
+			// For templates we should not specify namespace where PVC would be located
+			createPVC(volumeClaimTemplate.Name, "", host, &volumeClaimTemplate.Spec, g.labels, g.annotations),
+		)
+	}
+}
+
+// createPVC creates PVC labeled and annotated as the host
+func createPVC(
+	name string,
+	namespace string,
+	host *api.ChiHost,
+	spec *core.PersistentVolumeClaimSpec,
+	labels *Labeler,
+	annotations *Annotator,
+) core.PersistentVolumeClaim {
+	persistentVolumeClaim := core.PersistentVolumeClaim{
+		TypeMeta: meta.TypeMeta{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: "v1",
+		},
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			// TODO
+			//  this has to wait until proper disk inheritance procedure will be available
+			// UPDATE
+			//  we are close to proper disk inheritance
+			// Right now we hit the following error:
+			// "Forbidden: updates to statefulset spec for fields other than 'replicas', 'template', and 'updateStrategy' are forbidden"
+			Labels:      macro(host).Map(labels.getHostScope(host, false)),
+			Annotations: macro(host).Map(annotations.getHostScope(host)),
+		},
+		// Append copy of PersistentVolumeClaimSpec
+		Spec: *spec.DeepCopy(),
+	}
+	// TODO introduce normalization
+	// Overwrite .Spec.VolumeMode
+	volumeMode := core.PersistentVolumeFilesystem
+	persistentVolumeClaim.Spec.VolumeMode = &volumeMode
+
+	return persistentVolumeClaim
+}
+
+// operatorShouldCreatePVC checks whether operator should create PVC for specified volumeClaimTemplate
+func operatorShouldCreatePVC(host *api.ChiHost, volumeClaimTemplate *api.ChiVolumeClaimTemplate) bool {
+	return getPVCProvisioner(host, volumeClaimTemplate) == api.PVCProvisionerOperator
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apps "k8s.io/api/apps/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+func Test_StatefulSetGenerator(t *testing.T) {
+	chi := newTestCHI(t, generatorsTestManifest)
+	generator := model.NewStatefulSetGenerator(chi)
+
+	host := chi.FindCluster("main").Layout.Shards[1].Hosts[0]
+	statefulSet := generator.CreateStatefulSet(host, false)
+	require.Equal(t, "chi-gen-main-1-0", statefulSet.Name)
+	require.Equal(t, "test", statefulSet.Namespace)
+	require.Equal(t, int32(1), *statefulSet.Spec.Replicas)
+	require.Equal(t, model.GetSelectorHostScope(host), statefulSet.Spec.Selector.MatchLabels)
+	require.Equal(t, "chi-gen-main-1-0", statefulSet.Spec.ServiceName)
+	require.NotEmpty(t, statefulSet.Labels[model.LabelObjectVersion])
+	require.Len(t, statefulSet.OwnerReferences, 1)
+
+	// Pods are labeled for the selector to match
+	for label, value := range statefulSet.Spec.Selector.MatchLabels {
+		require.Equal(t, value, statefulSet.Spec.Template.Labels[label], label)
+	}
+	require.NotEmpty(t, statefulSet.Spec.Template.Spec.Containers)
+
+	// Host to be shut down is scaled down to zero
+	require.Equal(t, int32(0), *generator.CreateStatefulSet(host, true).Spec.Replicas)
+}
+
+// testStatefulSetGenerator generates StatefulSets named after hosts
+type testStatefulSetGenerator struct{}
+
+// CreateStatefulSet creates StatefulSet named after the host
+func (testStatefulSetGenerator) CreateStatefulSet(host *api.ChiHost, _ bool) *apps.StatefulSet {
+	statefulSet := &apps.StatefulSet{}
+	statefulSet.Name = "custom-" + host.GetName()
+	return statefulSet
+}
+
+func Test_Creator_SetStatefulSetGenerator(t *testing.T) {
+	chi := newTestCHI(t, generatorsTestManifest)
+	host := chi.FindCluster("main").Layout.Shards[0].Hosts[0]
+
+	creator := model.NewCreator(chi).SetStatefulSetGenerator(testStatefulSetGenerator{})
+	require.Equal(t, "custom-"+host.GetName(), creator.CreateStatefulSet(host, false).Name)
+	// Other generators are kept
+	require.Equal(t, "chi-gen-common-configd", creator.CreateConfigMapCHICommon(model.NewClickHouseConfigFilesGeneratorOptions()).Name)
+}
@@ -45,6 +45,18 @@ func HostCanDeletePVC(host *api.ChiHost, pvcName string) bool {
 	return policy == api.PVCReclaimPolicyDelete
 }
 
+// LostHostCanDeletePVC checks whether PVC of the host, StatefulSet of which is gone along with the lost node, can be deleted.
+// PVCs of unclear origin are kept, since they can not be matched against volume claim templates without StatefulSet
+func LostHostCanDeletePVC(host *api.ChiHost, pvcName string) bool {
+	canDelete := false
+	host.CHI.WalkVolumeClaimTemplates(func(template *api.ChiVolumeClaimTemplate) {
+		if pvcName == CreatePVCNameByVolumeClaimTemplate(host, template) {
+			canDelete = getPVCReclaimPolicy(host, template) == api.PVCReclaimPolicyDelete
+		}
+	})
+	return canDelete
+}
+
 // HostCanDeleteAllPVCs checks whether all PVCs can be deleted
 func HostCanDeleteAllPVCs(host *api.ChiHost) bool {
 	canDeleteAllPVCs := true
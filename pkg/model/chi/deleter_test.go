@@ -0,0 +1,54 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+func Test_LostHostCanDeletePVC(t *testing.T) {
+	chi := newTestCHI(t, `
+metadata:
+  name: lost
+spec:
+  defaults:
+    storageManagement:
+      reclaimPolicy: Retain
+  templates:
+    volumeClaimTemplates:
+      - name: data
+        reclaimPolicy: Delete
+      - name: logs
+      - name: backups
+        reclaimPolicy: Retain
+`)
+	host := chi.FirstHost()
+
+	expected := map[string]bool{
+		"data":    true,
+		"logs":    false,
+		"backups": false,
+	}
+	chi.WalkVolumeClaimTemplates(func(template *api.ChiVolumeClaimTemplate) {
+		name := model.CreatePVCNameByVolumeClaimTemplate(host, template)
+		require.Equal(t, expected[template.Name], model.LostHostCanDeletePVC(host, name), template.Name)
+	})
+	require.False(t, model.LostHostCanDeletePVC(host, "unknown"), "PVC of unclear origin is kept")
+}
@@ -16,6 +16,8 @@ package chi
 
 import (
 	"fmt"
+	"strings"
+
 	core "k8s.io/api/core/v1"
 
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -53,7 +55,16 @@ const (
 	labelServiceValueCluster          = "cluster"
 	labelServiceValueShard            = "shard"
 	labelServiceValueHost             = "host"
+	labelServiceValueBlueGreen        = "blue-green"
+	labelServiceValueHeadless         = "headless"
+	labelServiceValueNodeLocal        = "node-local"
 	LabelPVCReclaimPolicyName         = clickhouse_altinity_com.APIGroupName + "/" + "reclaimPolicy"
+	LabelUpgradeSandboxOf             = clickhouse_altinity_com.APIGroupName + "/" + "upgrade-sandbox-of"
+	LabelRegionRole                   = clickhouse_altinity_com.APIGroupName + "/" + "region-role"
+
+	// Prefix of labels and annotations reserved for the operator, scope metadata is not allowed to use it
+
+	labelPrefixReserved = clickhouse_altinity_com.APIGroupName + "/"
 
 	// Supplementary service labels - used to cooperate with k8s
 
@@ -96,6 +107,11 @@ func (l *Labeler) getConfigMapCHICommon() map[string]string {
 		})
 }
 
+// getConnection
+func (l *Labeler) getConnection() map[string]string {
+	return l.getCHIScope()
+}
+
 // getConfigMapCHICommonUsers
 func (l *Labeler) getConfigMapCHICommonUsers() map[string]string {
 	return util.MergeStringMapsOverwrite(
@@ -116,13 +132,44 @@ func (l *Labeler) getConfigMapHost(host *api.ChiHost) map[string]string {
 
 // getServiceCHI
 func (l *Labeler) getServiceCHI(chi *api.ClickHouseInstallation) map[string]string {
+	labels := map[string]string{
+		LabelService: labelServiceValueCHI,
+	}
+	if chi.Spec.CrossRegion.IsEnabled() {
+		// Published endpoint of the primary region is selected by its role
+		labels[LabelRegionRole] = strings.ToLower(chi.Spec.CrossRegion.GetRole())
+	}
+	return util.MergeStringMapsOverwrite(l.getCHIScope(), labels)
+}
+
+// getServiceHeadless
+func (l *Labeler) getServiceHeadless() map[string]string {
 	return util.MergeStringMapsOverwrite(
 		l.getCHIScope(),
 		map[string]string{
-			LabelService: labelServiceValueCHI,
+			LabelService: labelServiceValueHeadless,
 		})
 }
 
+// getServiceNodeLocal
+func (l *Labeler) getServiceNodeLocal() map[string]string {
+	return util.MergeStringMapsOverwrite(
+		l.getCHIScope(),
+		map[string]string{
+			LabelService: labelServiceValueNodeLocal,
+		})
+}
+
+// getServiceBlueGreen
+func (l *Labeler) getServiceBlueGreen() map[string]string {
+	// Do not include CHI name, common service is shared by blue/green generations
+	return map[string]string{
+		LabelNamespace: labelsNamer.getNamePartNamespace(l.chi),
+		LabelAppName:   LabelAppValue,
+		LabelService:   labelServiceValueBlueGreen,
+	}
+}
+
 // getServiceCluster
 func (l *Labeler) getServiceCluster(cluster *api.Cluster) map[string]string {
 	return util.MergeStringMapsOverwrite(
@@ -173,10 +220,19 @@ func (l *Labeler) getSelectorCHIScopeReady() map[string]string {
 	return appendKeyReady(l.GetSelectorCHIScope())
 }
 
+// IsServiceSelectingCHI checks whether the service selects pods of the specified CHI
+func IsServiceSelectingCHI(service *core.Service, chi *api.ClickHouseInstallation) bool {
+	if service == nil {
+		return false
+	}
+	return service.Spec.Selector[LabelCHIName] == labelsNamer.getNamePartCHIName(chi)
+}
+
 // getClusterScope gets labels for Cluster-scoped object
 func (l *Labeler) getClusterScope(cluster *api.Cluster) map[string]string {
-	// Combine generated labels and CHI-provided labels
-	return l.filterOutPredefined(l.appendCHIProvidedTo(getSelectorClusterScope(cluster)))
+	// Combine generated labels, CHI-provided labels and cluster-provided labels
+	labels := l.appendCHIProvidedTo(getSelectorClusterScope(cluster))
+	return l.filterOutPredefined(util.MergeStringMapsOverwrite(labels, cluster.Metadata.GetLabels()))
 }
 
 // getSelectorClusterScope gets labels to select a Cluster-scoped object
@@ -197,8 +253,9 @@ func getSelectorClusterScopeReady(cluster *api.Cluster) map[string]string {
 
 // getShardScope gets labels for Shard-scoped object
 func (l *Labeler) getShardScope(shard *api.ChiShard) map[string]string {
-	// Combine generated labels and CHI-provided labels
-	return l.filterOutPredefined(l.appendCHIProvidedTo(getSelectorShardScope(shard)))
+	// Combine generated labels, CHI-provided labels and shard-provided labels
+	labels := l.appendCHIProvidedTo(getSelectorShardScope(shard))
+	return l.filterOutPredefined(util.MergeStringMapsOverwrite(labels, shard.Metadata.GetLabels()))
 }
 
 // getSelectorShardScope gets labels to select a Shard-scoped object
@@ -241,7 +298,9 @@ func (l *Labeler) getHostScope(host *api.ChiHost, applySupplementaryServiceLabel
 		// When we'll have ChkCluster Discovery functionality we can refactor this properly
 		labels = appendConfigLabels(host, labels)
 	}
-	return l.filterOutPredefined(l.appendCHIProvidedTo(labels))
+	// Host-provided labels are inherited from cluster, shard and replica
+	labels = util.MergeStringMapsOverwrite(l.appendCHIProvidedTo(labels), host.Metadata.GetLabels())
+	return l.filterOutPredefined(labels)
 }
 
 func appendConfigLabels(host *api.ChiHost, labels map[string]string) map[string]string {
@@ -0,0 +1,152 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"fmt"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/apis/deployment"
+)
+
+// MigrateDeprecatedFields converts deprecated fields of the CHI spec into the current layout in place.
+// Returns list of performed migrations, each described as "old path: old value -> new path: new value"
+func MigrateDeprecatedFields(chi *api.ClickHouseInstallation) (migrations []string) {
+	if chi == nil {
+		return nil
+	}
+
+	migrate := func(format string, args ...interface{}) {
+		migrations = append(migrations, fmt.Sprintf(format, args...))
+	}
+
+	if chi.Spec.Defaults != nil {
+		migrateTemplateNames(chi.Spec.Defaults.Templates, "spec.defaults.templates", migrate)
+	}
+
+	if chi.Spec.Configuration != nil {
+		for i, cluster := range chi.Spec.Configuration.Clusters {
+			if cluster == nil {
+				continue
+			}
+			path := fmt.Sprintf("spec.configuration.clusters[%d]", i)
+			migrateTemplateNames(cluster.Templates, path+".templates", migrate)
+			if cluster.Layout == nil {
+				continue
+			}
+			if cluster.Layout.Type != "" {
+				migrate("%s.layout.type: %s -> removed, layout is defined by shardsCount and replicasCount", path, cluster.Layout.Type)
+				cluster.Layout.Type = ""
+			}
+			for j := range cluster.Layout.Shards {
+				shard := &cluster.Layout.Shards[j]
+				shardPath := fmt.Sprintf("%s.layout.shards[%d]", path, j)
+				if shard.DefinitionType != "" {
+					migrate("%s.definitionType: %s -> removed, shard is defined by replicasCount and replicas", shardPath, shard.DefinitionType)
+					shard.DefinitionType = ""
+				}
+				migrateTemplateNames(shard.Templates, shardPath+".templates", migrate)
+				for k, host := range shard.Hosts {
+					migrateHost(host, fmt.Sprintf("%s.replicas[%d]", shardPath, k), migrate)
+				}
+			}
+			for j := range cluster.Layout.Replicas {
+				replica := &cluster.Layout.Replicas[j]
+				replicaPath := fmt.Sprintf("%s.layout.replicas[%d]", path, j)
+				migrateTemplateNames(replica.Templates, replicaPath+".templates", migrate)
+				for k, host := range replica.Hosts {
+					migrateHost(host, fmt.Sprintf("%s.shards[%d]", replicaPath, k), migrate)
+				}
+			}
+		}
+	}
+
+	if chi.Spec.Templates != nil {
+		for i := range chi.Spec.Templates.HostTemplates {
+			migrateHost(&chi.Spec.Templates.HostTemplates[i].Spec, fmt.Sprintf("spec.templates.hostTemplates[%d].spec", i), migrate)
+		}
+		for i := range chi.Spec.Templates.PodTemplates {
+			migratePodTemplate(&chi.Spec.Templates.PodTemplates[i], fmt.Sprintf("spec.templates.podTemplates[%d]", i), migrate)
+		}
+	}
+
+	return migrations
+}
+
+// migrateTemplateNames migrates deprecated volumeClaimTemplate into dataVolumeClaimTemplate
+func migrateTemplateNames(templateNames *api.ChiTemplateNames, path string, migrate func(string, ...interface{})) {
+	if (templateNames == nil) || (templateNames.VolumeClaimTemplate == "") {
+		return
+	}
+	if templateNames.DataVolumeClaimTemplate == "" {
+		templateNames.DataVolumeClaimTemplate = templateNames.VolumeClaimTemplate
+		migrate("%s.volumeClaimTemplate: %s -> %s.dataVolumeClaimTemplate: %s",
+			path, templateNames.VolumeClaimTemplate, path, templateNames.DataVolumeClaimTemplate)
+	} else {
+		migrate("%s.volumeClaimTemplate: %s -> removed, overridden by %s.dataVolumeClaimTemplate: %s",
+			path, templateNames.VolumeClaimTemplate, path, templateNames.DataVolumeClaimTemplate)
+	}
+	templateNames.VolumeClaimTemplate = ""
+}
+
+// migrateHost migrates deprecated port into tcpPort
+func migrateHost(host *api.ChiHost, path string, migrate func(string, ...interface{})) {
+	if host == nil {
+		return
+	}
+	migrateTemplateNames(host.Templates, path+".templates", migrate)
+	if api.IsPortUnassigned(host.Port) {
+		return
+	}
+	if api.IsPortUnassigned(host.TCPPort) {
+		host.TCPPort = host.Port
+		migrate("%s.port: %d -> %s.tcpPort: %d", path, host.Port, path, host.TCPPort)
+	} else {
+		migrate("%s.port: %d -> removed, overridden by %s.tcpPort: %d", path, host.Port, path, host.TCPPort)
+	}
+	host.Port = api.PortUnassigned()
+}
+
+// migratePodTemplate migrates deprecated distribution into podDistribution
+func migratePodTemplate(template *api.ChiPodTemplate, path string, migrate func(string, ...interface{})) {
+	switch template.Distribution {
+	case "":
+	case deployment.PodDistributionOnePerHost:
+		if len(template.PodDistribution) == 0 {
+			template.PodDistribution = []api.ChiPodDistribution{
+				{
+					Type: deployment.PodDistributionClickHouseAntiAffinity,
+				},
+			}
+			migrate("%s.distribution: %s -> %s.podDistribution[0].type: %s",
+				path, template.Distribution, path, deployment.PodDistributionClickHouseAntiAffinity)
+		} else {
+			migrate("%s.distribution: %s -> removed, overridden by %s.podDistribution", path, template.Distribution, path)
+		}
+		template.Distribution = ""
+	default:
+		migrate("%s.distribution: %s -> removed", path, template.Distribution)
+		template.Distribution = ""
+	}
+
+	for i := range template.PodDistribution {
+		podDistribution := &template.PodDistribution[i]
+		if podDistribution.Type == deployment.PodDistributionOnePerHost {
+			podDistribution.Type = deployment.PodDistributionClickHouseAntiAffinity
+			migrate("%s.podDistribution[%d].type: %s -> %s.podDistribution[%d].type: %s",
+				path, i, deployment.PodDistributionOnePerHost, path, i, podDistribution.Type)
+		}
+	}
+}
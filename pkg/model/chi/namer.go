@@ -59,12 +59,28 @@ const (
 	// statefulSetServiceNamePattern is a template of hosts's StatefulSet's Service name. "chi-{chi}-{cluster}-{shard}-{host}"
 	statefulSetServiceNamePattern = "chi-" + macrosChiName + "-" + macrosClusterName + "-" + macrosHostName
 
+	// headlessServiceNamePattern is a template of headless Service governing all StatefulSets of the CHI. "chi-{chi}-headless"
+	headlessServiceNamePattern = "chi-" + macrosChiName + "-headless"
+
+	// nodeLocalServiceNamePattern is a template of Service exposing hosts to node-local agents. "chi-{chi}-node-local"
+	nodeLocalServiceNamePattern = "chi-" + macrosChiName + "-node-local"
+
+	// podDNSHostRegexpTemplate is a template of hostname regexp of pods addressed by their DNS names within governing Service,
+	// be it own Service of the host or headless Service of the CHI
+	podDNSHostRegexpTemplate = "chi-{chi}-[^.]+\\d+-\\d+-0\\.chi-{chi}-[^.]+\\.{namespace}\\.svc\\.cluster\\.local$"
+
 	// configMapCommonNamePattern is a template of common settings for the CHI ConfigMap. "chi-{chi}-common-configd"
 	configMapCommonNamePattern = "chi-" + macrosChiName + "-common-configd"
 
 	// configMapCommonUsersNamePattern is a template of common users settings for the CHI ConfigMap. "chi-{chi}-common-usersd"
 	configMapCommonUsersNamePattern = "chi-" + macrosChiName + "-common-usersd"
 
+	// connectionNamePattern is a template of the object connection details are published into. "chi-{chi}-connection"
+	connectionNamePattern = "chi-" + macrosChiName + "-connection"
+
+	// bindingSecretNamePattern is a template of the Service Binding Secret of a user. "chi-{chi}-binding-{user}"
+	bindingSecretNamePattern = "chi-" + macrosChiName + "-binding-%s"
+
 	// configMapHostNamePattern is a template of macros ConfigMap. "chi-{chi}-deploy-confd-{cluster}-{shard}-{host}"
 	configMapHostNamePattern = "chi-" + macrosChiName + "-deploy-confd-" + macrosClusterName + "-" + macrosHostName
 
@@ -368,7 +384,7 @@ func getNamePartReplicaScopeIndex(host *api.ChiHost) string {
 
 // CreateConfigMapHostName returns a name for a ConfigMap for replica's personal config
 func CreateConfigMapHostName(host *api.ChiHost) string {
-	return macro(host).Line(configMapHostNamePattern)
+	return shortenSubdomainName(namingStrategy.ConfigMapHostName(host))
 }
 
 // CreateConfigMapHostMigrationName returns a name for a ConfigMap for replica's personal config
@@ -378,33 +394,34 @@ func CreateConfigMapHostName(host *api.ChiHost) string {
 
 // CreateConfigMapCommonName returns a name for a ConfigMap for replica's common config
 func CreateConfigMapCommonName(chi *api.ClickHouseInstallation) string {
-	return macro(chi).Line(configMapCommonNamePattern)
+	return shortenSubdomainName(namingStrategy.ConfigMapCommonName(chi))
 }
 
 // CreateConfigMapCommonUsersName returns a name for a ConfigMap for replica's common users config
 func CreateConfigMapCommonUsersName(chi *api.ClickHouseInstallation) string {
-	return macro(chi).Line(configMapCommonUsersNamePattern)
+	return shortenSubdomainName(namingStrategy.ConfigMapCommonUsersName(chi))
 }
 
-// CreateCHIServiceName creates a name of a root ClickHouseInstallation Service resource
-func CreateCHIServiceName(chi *api.ClickHouseInstallation) string {
-	// Name can be generated either from default name pattern,
-	// or from personal name pattern provided in ServiceTemplate
-
-	// Start with default name pattern
-	pattern := chiServiceNamePattern
-
-	// ServiceTemplate may have personal name pattern specified
-	if template, ok := chi.GetCHIServiceTemplate(); ok {
-		// ServiceTemplate available
-		if template.GenerateName != "" {
-			// ServiceTemplate has explicitly specified name pattern
-			pattern = template.GenerateName
-		}
+// CreateConnectionName returns a name of the object connection details of the CHI are published into
+func CreateConnectionName(chi *api.ClickHouseInstallation) string {
+	if name := chi.Spec.Connection.GetName(); name != "" {
+		return name
 	}
+	return macro(chi).Line(connectionNamePattern)
+}
 
-	// Create Service name based on name pattern available
-	return macro(chi).Line(pattern)
+// CreateBindingSecretName returns a name of the Service Binding Secret of the user of the CHI
+func CreateBindingSecretName(chi *api.ClickHouseInstallation, username string) string {
+	name := macro(chi).Line(fmt.Sprintf(bindingSecretNamePattern, strings.ReplaceAll(username, "_", "-")))
+	if label, ok := util.BuildRFC1035Label(name); ok {
+		return label
+	}
+	return name
+}
+
+// CreateCHIServiceName creates a name of a root ClickHouseInstallation Service resource
+func CreateCHIServiceName(chi *api.ClickHouseInstallation) string {
+	return shortenLabelName(namingStrategy.CHIServiceName(chi))
 }
 
 // CreateCHIServiceFQDN creates a FQD name of a root ClickHouseInstallation Service resource
@@ -430,44 +447,12 @@ func CreateCHIServiceFQDN(chi *api.ClickHouseInstallation) string {
 
 // CreateClusterServiceName returns a name of a cluster's Service
 func CreateClusterServiceName(cluster *api.Cluster) string {
-	// Name can be generated either from default name pattern,
-	// or from personal name pattern provided in ServiceTemplate
-
-	// Start with default name pattern
-	pattern := clusterServiceNamePattern
-
-	// ServiceTemplate may have personal name pattern specified
-	if template, ok := cluster.GetServiceTemplate(); ok {
-		// ServiceTemplate available
-		if template.GenerateName != "" {
-			// ServiceTemplate has explicitly specified name pattern
-			pattern = template.GenerateName
-		}
-	}
-
-	// Create Service name based on name pattern available
-	return macro(cluster).Line(pattern)
+	return shortenLabelName(namingStrategy.ClusterServiceName(cluster))
 }
 
 // CreateShardServiceName returns a name of a shard's Service
 func CreateShardServiceName(shard *api.ChiShard) string {
-	// Name can be generated either from default name pattern,
-	// or from personal name pattern provided in ServiceTemplate
-
-	// Start with default name pattern
-	pattern := shardServiceNamePattern
-
-	// ServiceTemplate may have personal name pattern specified
-	if template, ok := shard.GetServiceTemplate(); ok {
-		// ServiceTemplate available
-		if template.GenerateName != "" {
-			// ServiceTemplate has explicitly specified name pattern
-			pattern = template.GenerateName
-		}
-	}
-
-	// Create Service name based on name pattern available
-	return macro(shard).Line(pattern)
+	return shortenLabelName(namingStrategy.ShardServiceName(shard))
 }
 
 // CreateShardName returns a name of a shard
@@ -549,54 +534,56 @@ func IsAutoGeneratedHostName(
 
 // CreateStatefulSetName creates a name of a StatefulSet for ClickHouse instance
 func CreateStatefulSetName(host *api.ChiHost) string {
-	// Name can be generated either from default name pattern,
-	// or from personal name pattern provided in PodTemplate
-
-	// Start with default name pattern
-	pattern := statefulSetNamePattern
-
-	// PodTemplate may have personal name pattern specified
-	if template, ok := host.GetPodTemplate(); ok {
-		// PodTemplate available
-		if template.GenerateName != "" {
-			// PodTemplate has explicitly specified name pattern
-			pattern = template.GenerateName
-		}
+	if standby, ok := getPromotedStandbyHost(host); ok {
+		// Host is served by the promoted standby host, StatefulSet along with its volumes is kept
+		host = standby
 	}
+	return shortenStatefulSetName(namingStrategy.StatefulSetName(host))
+}
 
-	// Create StatefulSet name based on name pattern available
-	return macro(host).Line(pattern)
+// CreateHeadlessServiceName returns a name of headless Service, governing all StatefulSets of the CHI
+func CreateHeadlessServiceName(chi *api.ClickHouseInstallation) string {
+	return shortenLabelName(namingStrategy.HeadlessServiceName(chi))
 }
 
-// CreateStatefulSetServiceName returns a name of a StatefulSet-related Service for ClickHouse instance
-func CreateStatefulSetServiceName(host *api.ChiHost) string {
-	// Name can be generated either from default name pattern,
-	// or from personal name pattern provided in ServiceTemplate
-
-	// Start with default name pattern
-	pattern := statefulSetServiceNamePattern
-
-	// ServiceTemplate may have personal name pattern specified
-	if template, ok := host.GetServiceTemplate(); ok {
-		// ServiceTemplate available
-		if template.GenerateName != "" {
-			// ServiceTemplate has explicitly specified name pattern
-			pattern = template.GenerateName
-		}
+// CreateNodeLocalServiceName returns a name of Service exposing hosts of the CHI to node-local agents
+func CreateNodeLocalServiceName(chi *api.ClickHouseInstallation) string {
+	return shortenLabelName(macro(chi).Line(nodeLocalServiceNamePattern))
+}
+
+// createStatefulSetGoverningServiceName returns a name of the Service governing StatefulSet of the host,
+// which is either own Service of the host or headless Service of the CHI
+func createStatefulSetGoverningServiceName(host *api.ChiHost) string {
+	if host.GetCHI().Spec.Defaults.IsHeadlessHostServices() {
+		return CreateHeadlessServiceName(host.GetCHI())
 	}
+	return CreateStatefulSetServiceName(host)
+}
 
-	// Create Service name based on name pattern available
-	return macro(host).Line(pattern)
+// CreateStatefulSetServiceName returns a name of a StatefulSet-related Service for ClickHouse instance
+func CreateStatefulSetServiceName(host *api.ChiHost) string {
+	return shortenLabelName(namingStrategy.StatefulSetServiceName(host))
 }
 
 // CreatePodHostname returns a hostname of a Pod of a ClickHouse instance.
 // Is supposed to be used where network connection to a Pod is required.
 // NB: right now Pod's hostname points to a Service, through which Pod can be accessed.
+// In case of pod DNS discovery, Pod is accessed via its DNS record within governing Service of the StatefulSet
 func CreatePodHostname(host *api.ChiHost) string {
+	if host.GetCHI().Spec.Defaults.IsPodDNSHostDiscovery() {
+		// pod-name.governing-service-name
+		return CreatePodName(host) + "." + createStatefulSetGoverningServiceName(host)
+	}
 	// Do not use Pod own hostname - point to appropriate StatefulSet's Service
 	return CreateStatefulSetServiceName(host)
 }
 
+// CreateMacroReplica returns value of the {replica} macro of the host.
+// Replica name identifies replicas of replicated tables, so it does not depend on how the host is reachable
+func CreateMacroReplica(host *api.ChiHost) string {
+	return CreateStatefulSetServiceName(host)
+}
+
 // createPodFQDN creates a fully qualified domain name of a pod
 // ss-1eb454-2-0.my-dev-domain.svc.cluster.local
 func createPodFQDN(host *api.ChiHost) string {
@@ -767,21 +754,21 @@ func CreatePVCNameByVolumeMount(host *api.ChiHost, volumeMount *core.VolumeMount
 
 // createPVCName is an internal function
 func createPVCName(host *api.ChiHost, volumeMountName string) string {
-	return volumeMountName + "-" + CreatePodName(host)
+	return shortenSubdomainName(volumeMountName + "-" + CreatePodName(host))
 }
 
 // CreateClusterAutoSecretName creates Secret name where auto-generated secret is kept
 func CreateClusterAutoSecretName(cluster *api.Cluster) string {
 	if cluster.Name == "" {
-		return fmt.Sprintf(
+		return shortenSubdomainName(fmt.Sprintf(
 			"%s-auto-secret",
 			cluster.CHI.Name,
-		)
+		))
 	}
 
-	return fmt.Sprintf(
+	return shortenSubdomainName(fmt.Sprintf(
 		"%s-%s-auto-secret",
 		cluster.CHI.Name,
 		cluster.Name,
-	)
+	))
 }
@@ -0,0 +1,205 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+const (
+	// labelNameMaxLen specifies max length of names of objects, which are to be DNS labels, such as Services
+	labelNameMaxLen = 63
+	// subdomainNameMaxLen specifies max length of names of objects, which are to be DNS subdomains, such as ConfigMaps
+	subdomainNameMaxLen = 253
+	// statefulSetNameMaxLen specifies max length of StatefulSet name.
+	// Pods of StatefulSet are labeled with controller-revision-hash label, which is StatefulSet name suffixed
+	// with up to 11 chars of hash and has to fit into label value length
+	statefulSetNameMaxLen = 52
+)
+
+// NamingStrategy makes names of objects of the CHI.
+// Names made by a strategy are shortened to fit k8s length limits by deterministic hashing truncation anyway
+type NamingStrategy interface {
+	// ConfigMapHostName makes name of the ConfigMap with personal config files of the host
+	ConfigMapHostName(host *api.ChiHost) string
+	// ConfigMapCommonName makes name of the ConfigMap with config files common for all hosts of the CHI
+	ConfigMapCommonName(chi *api.ClickHouseInstallation) string
+	// ConfigMapCommonUsersName makes name of the ConfigMap with users config files common for all hosts of the CHI
+	ConfigMapCommonUsersName(chi *api.ClickHouseInstallation) string
+	// CHIServiceName makes name of the Service of the CHI
+	CHIServiceName(chi *api.ClickHouseInstallation) string
+	// ClusterServiceName makes name of the Service of the cluster
+	ClusterServiceName(cluster *api.Cluster) string
+	// ShardServiceName makes name of the Service of the shard
+	ShardServiceName(shard *api.ChiShard) string
+	// HeadlessServiceName makes name of the headless Service governing all StatefulSets of the CHI
+	HeadlessServiceName(chi *api.ClickHouseInstallation) string
+	// StatefulSetName makes name of the StatefulSet of the host
+	StatefulSetName(host *api.ChiHost) string
+	// StatefulSetServiceName makes name of the Service of the host
+	StatefulSetServiceName(host *api.ChiHost) string
+}
+
+// namingStrategy specifies how objects of CHIs are named
+var namingStrategy NamingStrategy = DefaultNamingStrategy{}
+
+// DefaultNamingStrategy makes names out of name patterns, which can be overridden by GenerateName of templates
+type DefaultNamingStrategy struct{}
+
+// ConfigMapHostName makes name of the ConfigMap with personal config files of the host
+func (DefaultNamingStrategy) ConfigMapHostName(host *api.ChiHost) string {
+	return macro(host).Line(configMapHostNamePattern)
+}
+
+// ConfigMapCommonName makes name of the ConfigMap with config files common for all hosts of the CHI
+func (DefaultNamingStrategy) ConfigMapCommonName(chi *api.ClickHouseInstallation) string {
+	return macro(chi).Line(configMapCommonNamePattern)
+}
+
+// ConfigMapCommonUsersName makes name of the ConfigMap with users config files common for all hosts of the CHI
+func (DefaultNamingStrategy) ConfigMapCommonUsersName(chi *api.ClickHouseInstallation) string {
+	return macro(chi).Line(configMapCommonUsersNamePattern)
+}
+
+// CHIServiceName makes name of the Service of the CHI
+func (DefaultNamingStrategy) CHIServiceName(chi *api.ClickHouseInstallation) string {
+	// Name can be generated either from default name pattern,
+	// or from personal name pattern provided in ServiceTemplate
+
+	// Start with default name pattern
+	pattern := chiServiceNamePattern
+
+	// ServiceTemplate may have personal name pattern specified
+	if template, ok := chi.GetCHIServiceTemplate(); ok {
+		// ServiceTemplate available
+		if template.GenerateName != "" {
+			// ServiceTemplate has explicitly specified name pattern
+			pattern = template.GenerateName
+		}
+	}
+
+	// Create Service name based on name pattern available
+	return macro(chi).Line(pattern)
+}
+
+// ClusterServiceName makes name of the Service of the cluster
+func (DefaultNamingStrategy) ClusterServiceName(cluster *api.Cluster) string {
+	// Name can be generated either from default name pattern,
+	// or from personal name pattern provided in ServiceTemplate
+
+	// Start with default name pattern
+	pattern := clusterServiceNamePattern
+
+	// ServiceTemplate may have personal name pattern specified
+	if template, ok := cluster.GetServiceTemplate(); ok {
+		// ServiceTemplate available
+		if template.GenerateName != "" {
+			// ServiceTemplate has explicitly specified name pattern
+			pattern = template.GenerateName
+		}
+	}
+
+	// Create Service name based on name pattern available
+	return macro(cluster).Line(pattern)
+}
+
+// ShardServiceName makes name of the Service of the shard
+func (DefaultNamingStrategy) ShardServiceName(shard *api.ChiShard) string {
+	// Name can be generated either from default name pattern,
+	// or from personal name pattern provided in ServiceTemplate
+
+	// Start with default name pattern
+	pattern := shardServiceNamePattern
+
+	// ServiceTemplate may have personal name pattern specified
+	if template, ok := shard.GetServiceTemplate(); ok {
+		// ServiceTemplate available
+		if template.GenerateName != "" {
+			// ServiceTemplate has explicitly specified name pattern
+			pattern = template.GenerateName
+		}
+	}
+
+	// Create Service name based on name pattern available
+	return macro(shard).Line(pattern)
+}
+
+// HeadlessServiceName makes name of the headless Service governing all StatefulSets of the CHI
+func (DefaultNamingStrategy) HeadlessServiceName(chi *api.ClickHouseInstallation) string {
+	return macro(chi).Line(headlessServiceNamePattern)
+}
+
+// StatefulSetName makes name of the StatefulSet of the host
+func (DefaultNamingStrategy) StatefulSetName(host *api.ChiHost) string {
+	// Name can be generated either from default name pattern,
+	// or from personal name pattern provided in PodTemplate
+
+	// Start with default name pattern
+	pattern := statefulSetNamePattern
+
+	// PodTemplate may have personal name pattern specified
+	if template, ok := host.GetPodTemplate(); ok {
+		// PodTemplate available
+		if template.GenerateName != "" {
+			// PodTemplate has explicitly specified name pattern
+			pattern = template.GenerateName
+		}
+	}
+
+	// Create StatefulSet name based on name pattern available
+	return macro(host).Line(pattern)
+}
+
+// StatefulSetServiceName makes name of the Service of the host
+func (DefaultNamingStrategy) StatefulSetServiceName(host *api.ChiHost) string {
+	// Name can be generated either from default name pattern,
+	// or from personal name pattern provided in ServiceTemplate
+
+	// Start with default name pattern
+	pattern := statefulSetServiceNamePattern
+
+	// ServiceTemplate may have personal name pattern specified
+	if template, ok := host.GetServiceTemplate(); ok {
+		// ServiceTemplate available
+		if template.GenerateName != "" {
+			// ServiceTemplate has explicitly specified name pattern
+			pattern = template.GenerateName
+		}
+	}
+
+	// Create Service name based on name pattern available
+	return macro(host).Line(pattern)
+}
+
+// shortenLabelName shortens name of an object, which is to be a DNS label
+func shortenLabelName(name string) string {
+	return util.ShortenString(name, labelNameMaxLen)
+}
+
+// shortenSubdomainName shortens name of an object, which is to be a DNS subdomain
+func shortenSubdomainName(name string) string {
+	return util.ShortenString(name, subdomainNameMaxLen)
+}
+
+// shortenStatefulSetName shortens name of a StatefulSet, which exceeds DNS label limit.
+// StatefulSets named within the limit are kept as is, so existing StatefulSets are not renamed,
+// while names which could never be created are shortened enough for pods to be labeled with revision hash
+func shortenStatefulSetName(name string) string {
+	if len(name) <= labelNameMaxLen {
+		return name
+	}
+	return util.ShortenString(name, statefulSetNameMaxLen)
+}
@@ -0,0 +1,69 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+func Test_CreateStatefulSetName(t *testing.T) {
+	tests := []struct {
+		name    string
+		chiName string
+		want    func(name string) string
+	}{
+		{
+			name:    "short name is kept",
+			chiName: "short",
+			want:    func(name string) string { return name },
+		},
+		{
+			// Such StatefulSets may exist already, they are not renamed
+			name:    "name within DNS label limit is kept",
+			chiName: strings.Repeat("a", 49),
+			want:    func(name string) string { return name },
+		},
+		{
+			name:    "name exceeding DNS label limit is shortened",
+			chiName: strings.Repeat("a", 60),
+			want: func(name string) string {
+				require.Greater(t, len(name), 63)
+				return name[:43] + "-" + util.CreateStringID(name, 8)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chi := newTestCHI(t, fmt.Sprintf(`
+metadata:
+  name: %s
+spec:
+  configuration:
+    clusters:
+      - name: main
+`, tt.chiName))
+			host := chi.FindCluster("main").Layout.Shards[0].Hosts[0]
+			name := fmt.Sprintf("chi-%s-main-0-0", tt.chiName)
+			require.Equal(t, tt.want(name), model.CreateStatefulSetName(host))
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 
 	core "k8s.io/api/core/v1"
@@ -35,6 +36,7 @@ import (
 	"github.com/altinity/clickhouse-operator/pkg/chop"
 	"github.com/altinity/clickhouse-operator/pkg/controller"
 	"github.com/altinity/clickhouse-operator/pkg/util"
+	"github.com/altinity/clickhouse-operator/pkg/xml"
 )
 
 // NormalizerContext specifies CHI-related normalization context
@@ -45,6 +47,8 @@ type NormalizerContext struct {
 	chi *api.ClickHouseInstallation
 	// options specifies normalization options
 	options *NormalizerOptions
+	// rejections specifies invalid entries of the spec, skipped by normalization
+	rejections []string
 }
 
 // NewNormalizerContext creates new NormalizerContext
@@ -157,7 +161,17 @@ func (n *Normalizer) prepareListOfCHITemplates(chi *api.ClickHouseInstallation)
 		}
 	}
 
-	// 2. Append templates, explicitly requested by the CHI
+	// 2. Append templates of the preset, requested by the CHI
+	if chi.Spec.Preset != "" {
+		if presetTemplates, ok := chop.Config().GetPresetTemplates(chi.Spec.Preset); ok {
+			log.V(1).M(chi).F().Info("Found preset %s templates num: %d", chi.Spec.Preset, len(presetTemplates))
+			useTemplates = append(useTemplates, presetTemplates...)
+		} else {
+			log.V(1).M(chi).F().Warning("UNABLE to find preset: %s", chi.Spec.Preset)
+		}
+	}
+
+	// 3. Append templates, explicitly requested by the CHI
 	if len(chi.Spec.UseTemplates) > 0 {
 		log.V(1).M(chi).F().Info("Found manual-templates num: %d", len(chi.Spec.UseTemplates))
 		useTemplates = append(useTemplates, chi.Spec.UseTemplates...)
@@ -244,6 +258,9 @@ func (n *Normalizer) mergeCHIFromTemplate(chi, template *api.ClickHouseInstallat
 // normalize normalizes whole CHI.
 // Returns normalized CHI
 func (n *Normalizer) normalize() (*api.ClickHouseInstallation, error) {
+	// Convert deprecated fields into the current layout, so the rest of normalization deals with current layout only
+	n.migrateDeprecatedFields()
+
 	// Walk over ChiSpec datatype fields
 	n.ctx.chi.Spec.TaskID = n.normalizeTaskID(n.ctx.chi.Spec.TaskID)
 	n.ctx.chi.Spec.UseTemplates = n.normalizeUseTemplates(n.ctx.chi.Spec.UseTemplates)
@@ -256,14 +273,33 @@ func (n *Normalizer) normalize() (*api.ClickHouseInstallation, error) {
 	n.ctx.chi.Spec.Defaults = n.normalizeDefaults(n.ctx.chi.Spec.Defaults)
 	n.ctx.chi.Spec.Configuration = n.normalizeConfiguration(n.ctx.chi.Spec.Configuration)
 	n.ctx.chi.Spec.Templates = n.normalizeTemplates(n.ctx.chi.Spec.Templates)
+	n.ctx.chi.Spec.CrossRegion = n.normalizeCrossRegion(n.ctx.chi.Spec.CrossRegion)
+	n.ctx.chi.Spec.HostMacros = n.normalizeHostMacros(n.ctx.chi.Spec.HostMacros)
 	// UseTemplates already done
 
 	n.finalizeCHI()
 	n.fillStatus()
+	n.ctx.chi.EnsureStatus().SetRejections(n.ctx.rejections)
 
 	return n.ctx.chi, nil
 }
 
+// migrateDeprecatedFields converts deprecated fields and records performed migrations in .status
+func (n *Normalizer) migrateDeprecatedFields() {
+	migrations := MigrateDeprecatedFields(n.ctx.chi)
+	for _, migration := range migrations {
+		log.V(1).M(n.ctx.chi).F().Warning("Deprecated field migrated: %s", migration)
+	}
+	n.ctx.chi.EnsureStatus().SetMigrations(migrations)
+}
+
+// reject records invalid entry of the spec, which is skipped by normalization and not applied
+func (n *Normalizer) reject(format string, args ...interface{}) {
+	rejection := fmt.Sprintf(format, args...)
+	log.V(1).M(n.ctx.chi).F().Warning("Invalid entry rejected: %s", rejection)
+	n.ctx.rejections = append(n.ctx.rejections, rejection)
+}
+
 // finalizeCHI performs some finalization tasks, which should be done after CHI is normalized
 func (n *Normalizer) finalizeCHI() {
 	n.ctx.chi.FillSelfCalculatedAddressInfo()
@@ -303,8 +339,8 @@ func (n *Normalizer) getHostTemplate(host *api.ChiHost) *api.ChiHostTemplate {
 	// Check hostNetwork case at first
 	podTemplate, ok := host.GetPodTemplate()
 	if ok {
-		if podTemplate.Spec.HostNetwork {
-			// HostNetwork
+		if podTemplate.Spec.HostNetwork || (host.GetCHI().Spec.Defaults.IsDistributeHostPorts() && podTemplateHasHostPorts(podTemplate)) {
+			// HostNetwork or ports exposed on the node with distribution requested, hosts sharing the node need ports of their own
 			hostTemplate = newDefaultHostTemplateForHostNetwork(statefulSetName)
 		}
 	}
@@ -319,6 +355,20 @@ func (n *Normalizer) getHostTemplate(host *api.ChiHost) *api.ChiHostTemplate {
 	return hostTemplate
 }
 
+// podTemplateHasHostPorts checks whether ClickHouse container of the pod template exposes any of its ports on the node
+func podTemplateHasHostPorts(podTemplate *api.ChiPodTemplate) bool {
+	container, ok := getPodSpecContainer(&podTemplate.Spec, clickHouseContainerName, 0)
+	if !ok {
+		return false
+	}
+	for _, port := range container.Ports {
+		if port.HostPort != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // hostApplyHostTemplate
 func hostApplyHostTemplate(host *api.ChiHost, template *api.ChiHostTemplate) {
 	if host.GetName() == "" {
@@ -347,41 +397,9 @@ func hostApplyHostTemplate(host *api.ChiHost, template *api.ChiHostTemplate) {
 				host.InterserverHTTPPort = template.Spec.InterserverHTTPPort
 			}
 		case deployment.PortDistributionClusterScopeIndex:
-			if api.IsPortUnassigned(host.TCPPort) {
-				base := chDefaultTCPPortNumber
-				if api.IsPortAssigned(template.Spec.TCPPort) {
-					base = template.Spec.TCPPort
-				}
-				host.TCPPort = base + int32(host.Address.ClusterScopeIndex)
-			}
-			if api.IsPortUnassigned(host.TLSPort) {
-				base := chDefaultTLSPortNumber
-				if api.IsPortAssigned(template.Spec.TLSPort) {
-					base = template.Spec.TLSPort
-				}
-				host.TLSPort = base + int32(host.Address.ClusterScopeIndex)
-			}
-			if api.IsPortUnassigned(host.HTTPPort) {
-				base := chDefaultHTTPPortNumber
-				if api.IsPortAssigned(template.Spec.HTTPPort) {
-					base = template.Spec.HTTPPort
-				}
-				host.HTTPPort = base + int32(host.Address.ClusterScopeIndex)
-			}
-			if api.IsPortUnassigned(host.HTTPSPort) {
-				base := chDefaultHTTPSPortNumber
-				if api.IsPortAssigned(template.Spec.HTTPSPort) {
-					base = template.Spec.HTTPSPort
-				}
-				host.HTTPSPort = base + int32(host.Address.ClusterScopeIndex)
-			}
-			if api.IsPortUnassigned(host.InterserverHTTPPort) {
-				base := chDefaultInterserverHTTPPortNumber
-				if api.IsPortAssigned(template.Spec.InterserverHTTPPort) {
-					base = template.Spec.InterserverHTTPPort
-				}
-				host.InterserverHTTPPort = base + int32(host.Address.ClusterScopeIndex)
-			}
+			hostApplyPortsWithOffset(host, template, int32(host.Address.ClusterScopeIndex))
+		case deployment.PortDistributionCHIScopeIndex:
+			hostApplyPortsWithOffset(host, template, int32(host.Address.CHIScopeIndex))
 		}
 	}
 
@@ -390,6 +408,26 @@ func hostApplyHostTemplate(host *api.ChiHost, template *api.ChiHostTemplate) {
 	host.InheritTemplatesFrom(nil, nil, template)
 }
 
+// hostApplyPortsWithOffset assigns unassigned ports of the host as base port offset by the host's index,
+// so hosts sharing network namespace of a node listen on ports of their own.
+// Base port is either specified by the host template or the default one
+func hostApplyPortsWithOffset(host *api.ChiHost, template *api.ChiHostTemplate, offset int32) {
+	distribute := func(port, base, _default int32) int32 {
+		if api.IsPortAssigned(port) {
+			return port
+		}
+		if api.IsPortUnassigned(base) {
+			base = _default
+		}
+		return base + offset
+	}
+	host.TCPPort = distribute(host.TCPPort, template.Spec.TCPPort, chDefaultTCPPortNumber)
+	host.TLSPort = distribute(host.TLSPort, template.Spec.TLSPort, chDefaultTLSPortNumber)
+	host.HTTPPort = distribute(host.HTTPPort, template.Spec.HTTPPort, chDefaultHTTPPortNumber)
+	host.HTTPSPort = distribute(host.HTTPSPort, template.Spec.HTTPSPort, chDefaultHTTPSPortNumber)
+	host.InterserverHTTPPort = distribute(host.InterserverHTTPPort, template.Spec.InterserverHTTPPort, chDefaultInterserverHTTPPortNumber)
+}
+
 // hostApplyPortsFromSettings
 func hostApplyPortsFromSettings(host *api.ChiHost) {
 	// Use host personal settings at first
@@ -439,7 +477,19 @@ func (n *Normalizer) fillStatus() {
 		return nil
 	})
 	ip, _ := chop.Get().ConfigManager.GetRuntimeParam(deployment.OPERATOR_POD_IP)
-	n.ctx.chi.FillStatus(endpoint, pods, fqdns, ip)
+	n.ctx.chi.FillStatus(endpoint, CreateConnectionStatus(n.ctx.chi), pods, fqdns, ip)
+	n.ctx.chi.EnsureStatus().SetBinding(n.createBindingStatus())
+}
+
+// createBindingStatus creates Provisioned Service binding of the CHI, referencing Secret of the first binding user
+func (n *Normalizer) createBindingStatus() *api.ChiBindingStatus {
+	users := n.ctx.chi.Spec.ServiceBinding.GetUsers()
+	if len(users) == 0 {
+		return nil
+	}
+	return &api.ChiBindingStatus{
+		Name: CreateBindingSecretName(n.ctx.chi, users[0]),
+	}
 }
 
 // normalizeTaskID normalizes .spec.taskID
@@ -503,6 +553,19 @@ func (n *Normalizer) normalizeDefaults(defaults *api.ChiDefaults) *api.ChiDefaul
 	}
 	// Set defaults for CHI object properties
 	defaults.ReplicasUseFQDN = defaults.ReplicasUseFQDN.Normalize(false)
+	if defaults.IsHeadlessHostServices() {
+		defaults.HostServices = api.HostServicesHeadless
+	} else {
+		defaults.HostServices = api.HostServicesPerHost
+	}
+	if defaults.IsPodDNSHostDiscovery() {
+		defaults.HostDiscovery = api.HostDiscoveryPodDNS
+	} else {
+		defaults.HostDiscovery = api.HostDiscoveryService
+	}
+	if defaults.ReplicasCount < 0 {
+		defaults.ReplicasCount = 0
+	}
 	// Ensure field
 	if defaults.DistributedDDL == nil {
 		//defaults.DistributedDDL = api.NewChiDistributedDDL()
@@ -516,9 +579,48 @@ func (n *Normalizer) normalizeDefaults(defaults *api.ChiDefaults) *api.ChiDefaul
 		//defaults.Templates = api.NewChiTemplateNames()
 	}
 	defaults.Templates.HandleDeprecatedFields()
+	defaults.Readiness = n.normalizeDefaultsReadiness(defaults.Readiness)
+	defaults.MemoryManagement = n.normalizeDefaultsMemoryManagement(defaults.MemoryManagement)
 	return defaults
 }
 
+// normalizeDefaultsMemoryManagement normalizes .spec.defaults.memoryManagement
+func (n *Normalizer) normalizeDefaultsMemoryManagement(memory *api.ChiMemoryManagement) *api.ChiMemoryManagement {
+	if (memory == nil) || (memory.MaxServerMemoryUsageToRAMRatio == "") {
+		return memory
+	}
+	ratio, err := strconv.ParseFloat(memory.MaxServerMemoryUsageToRAMRatio, 64)
+	if (err != nil) || (ratio <= 0) || (ratio > 1) {
+		n.reject("memory management ratio %q: has to be within (0, 1], %s is used instead",
+			memory.MaxServerMemoryUsageToRAMRatio, api.MemoryManagementRatioDefault)
+		memory.MaxServerMemoryUsageToRAMRatio = ""
+	}
+	return memory
+}
+
+const readinessPasswordEnvName = "CLICKHOUSE_READINESS_PASSWORD"
+
+// normalizeDefaultsReadiness normalizes .spec.defaults.readiness
+func (n *Normalizer) normalizeDefaultsReadiness(readiness *api.ChiReadiness) *api.ChiReadiness {
+	if !readiness.IsQuery() {
+		// Readiness is probed by /ping, nothing to do here
+		return readiness
+	}
+	readiness.Mode = api.ReadinessModeQuery
+	// Password of the user is passed to the probe via an ENV VAR
+	if ref := readiness.GetPasswordSecretKeyRef(); ref != nil {
+		n.appendAdditionalEnvVar(
+			core.EnvVar{
+				Name: readinessPasswordEnvName,
+				ValueFrom: &core.EnvVarSource{
+					SecretKeyRef: ref,
+				},
+			},
+		)
+	}
+	return readiness
+}
+
 // normalizeConfiguration normalizes .spec.configuration
 func (n *Normalizer) normalizeConfiguration(conf *api.Configuration) *api.Configuration {
 	if conf == nil {
@@ -527,16 +629,87 @@ func (n *Normalizer) normalizeConfiguration(conf *api.Configuration) *api.Config
 	conf.Zookeeper = n.normalizeConfigurationZookeeper(conf.Zookeeper)
 	n.normalizeConfigurationSettingsBased(conf)
 	conf.Clusters = n.normalizeClusters(conf.Clusters)
+	conf.RemoteClusters = n.normalizeConfigurationRemoteClusters(conf.RemoteClusters)
+	conf.Monitoring = n.normalizeConfigurationMonitoring(conf.Monitoring)
 	return conf
 }
 
+// normalizeConfigurationMonitoring normalizes .spec.configuration.monitoring
+func (n *Normalizer) normalizeConfigurationMonitoring(monitoring *api.ChiMonitoring) *api.ChiMonitoring {
+	if (monitoring == nil) || (monitoring.Database == "") {
+		return monitoring
+	}
+	if !IsValidDatabaseName(monitoring.Database) {
+		n.reject("monitoring database %q: invalid name, %s is used instead", monitoring.Database, api.MonitoringDatabaseDefault)
+		monitoring.Database = ""
+	}
+	return monitoring
+}
+
+// normalizeConfigurationRemoteClusters normalizes .spec.configuration.remoteClusters
+func (n *Normalizer) normalizeConfigurationRemoteClusters(clusters []api.ChiRemoteCluster) []api.ChiRemoteCluster {
+	var normalized []api.ChiRemoteCluster
+	for i := range clusters {
+		cluster := &clusters[i]
+		if cluster.Name == "" {
+			// Skip clusters which can not be addressed
+			continue
+		}
+		if !xml.IsValidName(cluster.Name) {
+			n.reject("remote cluster %q: invalid name", cluster.Name)
+			continue
+		}
+		var shards []api.ChiRemoteShard
+		for j := range cluster.Shards {
+			shard := &cluster.Shards[j]
+			if shard.InternalReplication == nil {
+				shard.InternalReplication = api.NewStringBool(true)
+			}
+			var replicas []api.ChiRemoteReplica
+			for k := range shard.Replicas {
+				replica := &shard.Replicas[k]
+				if replica.Host == "" {
+					// Skip replicas which can not be addressed
+					continue
+				}
+				if replica.Port == 0 {
+					if replica.IsSecure() {
+						replica.Port = chDefaultTLSPortNumber
+					} else {
+						replica.Port = chDefaultTCPPortNumber
+					}
+				}
+				replicas = append(replicas, *replica)
+			}
+			if len(replicas) > 0 {
+				shard.Replicas = replicas
+				shards = append(shards, *shard)
+			}
+		}
+		if len(shards) > 0 {
+			cluster.Shards = shards
+			normalized = append(normalized, *cluster)
+		}
+	}
+	return normalized
+}
+
 // normalizeConfigurationSettingsBased normalizes Settings-based configuration
 func (n *Normalizer) normalizeConfigurationSettingsBased(conf *api.Configuration) {
+	n.rejectInvalidSettingsNames("users", conf.Users)
+	n.rejectInvalidSettingsNames("profiles", conf.Profiles)
+	n.rejectInvalidSettingsNames("quotas", conf.Quotas)
+	n.normalizeConfigurationClientCertificates(conf)
+	n.normalizeConfigurationUserDefaults(conf)
 	conf.Users = n.normalizeConfigurationUsers(conf.Users)
 	conf.Profiles = n.normalizeConfigurationProfiles(conf.Profiles)
 	conf.Quotas = n.normalizeConfigurationQuotas(conf.Quotas)
 	conf.Settings = n.normalizeConfigurationSettings(conf.Settings)
 	conf.Files = n.normalizeConfigurationFiles(conf.Files)
+	n.normalizeConfigurationGuards(conf)
+	n.normalizeConfigurationMemory(conf)
+	n.normalizeConfigurationRouting(conf)
+	n.normalizeConfigurationAudit(conf)
 }
 
 // normalizeTemplates normalizes .spec.templates
@@ -588,6 +761,49 @@ func (n *Normalizer) normalizeTemplating(templating *api.ChiTemplating) *api.Chi
 	return templating
 }
 
+// normalizeHostMacros normalizes .spec.hostMacros
+func (n *Normalizer) normalizeHostMacros(macros *api.ChiHostMacros) *api.ChiHostMacros {
+	if macros == nil {
+		return nil
+	}
+	if macros.NodeName != "" {
+		if err := ValidateHostMacroName(macros.NodeName); err != nil {
+			n.reject("host macro %q: %v", macros.NodeName, err)
+			macros.NodeName = ""
+		}
+	}
+	for _, m := range []map[string]string{macros.NodeLabels, macros.NodeAnnotations} {
+		for _, name := range util.MapSortedKeys(m) {
+			if err := ValidateHostMacroName(name); err != nil {
+				n.reject("host macro %q: %v", name, err)
+				delete(m, name)
+			}
+		}
+	}
+	return macros
+}
+
+// normalizeCrossRegion normalizes .spec.crossRegion
+func (n *Normalizer) normalizeCrossRegion(crossRegion *api.ChiCrossRegion) *api.ChiCrossRegion {
+	if !crossRegion.IsEnabled() {
+		return crossRegion
+	}
+	if crossRegion.Installation == "" {
+		// Installation shared by all regions defaults to CHI name
+		crossRegion.Installation = n.ctx.chi.Name
+	}
+	var peers []api.ChiCrossRegionPeer
+	for _, peer := range crossRegion.Peers {
+		if (peer.Region == "") || (peer.Region == crossRegion.Region) || (peer.HostPattern == "") {
+			// Skip peers which can not be addressed
+			continue
+		}
+		peers = append(peers, peer)
+	}
+	crossRegion.Peers = peers
+	return crossRegion
+}
+
 // normalizeReconciling normalizes .spec.reconciling
 func (n *Normalizer) normalizeReconciling(reconciling *api.ChiReconciling) *api.ChiReconciling {
 	if reconciling == nil {
@@ -666,7 +882,8 @@ func (n *Normalizer) normalizeHostTemplate(template *api.ChiHostTemplate) {
 		switch portDistribution.Type {
 		case
 			deployment.PortDistributionUnspecified,
-			deployment.PortDistributionClusterScopeIndex:
+			deployment.PortDistributionClusterScopeIndex,
+			deployment.PortDistributionCHIScopeIndex:
 			// distribution is known
 		default:
 			// distribution is not known
@@ -1164,6 +1381,10 @@ var ErrSecretValueNotFound = fmt.Errorf("secret value not found")
 // fetchSecretFieldValue fetches the value of the specified field in the specified secret
 // TODO this is the only useage of k8s API in the normalizer. How to remove it?
 func (n *Normalizer) fetchSecretFieldValue(secretAddress api.ObjectAddress) (string, error) {
+	// Normalizer may have no Kubernetes access, say when CHI is validated offline
+	if n.kubeClient == nil {
+		return "", ErrSecretValueNotFound
+	}
 
 	// Fetch the secret
 	secret, err := n.kubeClient.CoreV1().Secrets(secretAddress.Namespace).Get(context.TODO(), secretAddress.Name, controller.NewGetOptions())
@@ -1203,6 +1424,9 @@ func (n *Normalizer) normalizeConfigurationUsers(users *api.Settings) *api.Setti
 	// Ensure and normalize user settings
 	users = users.Ensure().Normalize()
 
+	// Users with Service Binding Secrets get passwords from their binding Secrets
+	n.normalizeConfigurationUsersServiceBinding(users)
+
 	// Add special "default" user to the list of users, which is used/required for:
 	// 1. ClickHouse hosts to communicate with each other
 	// 2. Specify host_regexp for default user as "allowed hosts to visit from"
@@ -1228,6 +1452,31 @@ func (n *Normalizer) normalizeConfigurationUsers(users *api.Settings) *api.Setti
 	return users
 }
 
+// userPasswordFields lists user fields password may be specified by
+var userPasswordFields = []string{
+	"password",
+	"password_sha256_hex",
+	"password_double_sha1_hex",
+	"k8s_secret_password",
+	"k8s_secret_password_sha256_hex",
+	"k8s_secret_password_double_sha1_hex",
+	"k8s_secret_env_password",
+	"k8s_secret_env_password_sha256_hex",
+	"k8s_secret_env_password_double_sha1_hex",
+}
+
+// normalizeConfigurationUsersServiceBinding points passwords of users with Service Binding Secrets to their Secrets.
+// Users are created in case they are not specified explicitly
+func (n *Normalizer) normalizeConfigurationUsersServiceBinding(users *api.Settings) {
+	for _, username := range n.ctx.chi.Spec.ServiceBinding.GetUsers() {
+		for _, field := range userPasswordFields {
+			users.Delete(username + "/" + field)
+		}
+		secretKeyRef := CreateBindingSecretName(n.ctx.chi, username) + "/" + BindingSecretPasswordKey
+		users.Set(username+"/k8s_secret_env_password", api.NewSettingScalar(secretKeyRef))
+	}
+}
+
 func (n *Normalizer) removePlainPassword(user *api.SettingsUser) {
 	if user.Has("password_double_sha1_hex") || user.Has("password_sha256_hex") {
 		// If user has encrypted password specified, we need to delete existing plaintext password.
@@ -1290,6 +1539,12 @@ func (n *Normalizer) normalizeConfigurationUserEnsureMandatoryFields(user *api.S
 		hostRegexp = ""
 	}
 
+	if (hostRegexp != "") && n.ctx.chi.Spec.Defaults.IsPodDNSHostDiscovery() {
+		// Pods addressed by their DNS names have name of governing Service in their domain names
+		podDNSRegexp := CreatePodHostnameRegexp(n.ctx.chi, podDNSHostRegexpTemplate)
+		hostRegexp = fmt.Sprintf("(%s)|(%s)", hostRegexp, podDNSRegexp)
+	}
+
 	// Ensure required values are in place and apply non-empty values in case no own value(s) provided
 	n.setMandatoryUserFields(user, &userFields{
 		profile:    profile,
@@ -1325,6 +1580,15 @@ func (n *Normalizer) setMandatoryUserFields(user *api.SettingsUser, fields *user
 
 // normalizeConfigurationUserPassword deals with user passwords
 func (n *Normalizer) normalizeConfigurationUserPassword(user *api.SettingsUser) {
+	// Users authenticated by client certificates have no password at all,
+	// since ClickHouse accepts exactly one authentication method per user
+	if user.Has(userSSLCertificatesField) {
+		for _, field := range userPasswordFields {
+			user.Delete(field)
+		}
+		return
+	}
+
 	// Values from the secret have higher priority
 	n.substSettingsFieldWithSecretFieldValue(user, "password", "k8s_secret_password")
 	n.substSettingsFieldWithSecretFieldValue(user, "password_sha256_hex", "k8s_secret_password_sha256_hex")
@@ -1426,6 +1690,7 @@ func (n *Normalizer) normalizeConfigurationSettings(settings *api.Settings) *api
 		return nil
 	}
 	settings.Normalize()
+	n.rejectInvalidSettingsNames("settings", settings)
 
 	settings.WalkSafe(func(name string, setting *api.Setting) {
 		n.substSettingsFieldWithEnvRefToSecretField(settings, name, name, envVarNamePrefixConfigurationSettings, false)
@@ -1433,6 +1698,21 @@ func (n *Normalizer) normalizeConfigurationSettings(settings *api.Settings) *api
 	return settings
 }
 
+// rejectInvalidSettingsNames rejects settings, names of which can not be represented as XML tags
+func (n *Normalizer) rejectInvalidSettingsNames(section string, settings *api.Settings) {
+	names := settings.Names()
+	sort.Strings(names)
+	for _, name := range names {
+		for _, tag := range strings.Split(strings.Trim(name, "/"), "/") {
+			if !xml.IsValidName(tag) {
+				n.reject("%s %q: invalid name %q", section, name, tag)
+				settings.Delete(name)
+				break
+			}
+		}
+	}
+}
+
 // normalizeConfigurationFiles normalizes .spec.configuration.files
 func (n *Normalizer) normalizeConfigurationFiles(files *api.Settings) *api.Settings {
 	if files == nil {
@@ -1448,6 +1728,164 @@ func (n *Normalizer) normalizeConfigurationFiles(files *api.Settings) *api.Setti
 	return files
 }
 
+const (
+	// userSSLCertificatesField is a user field, listing common names of client certificates the user is authenticated by
+	userSSLCertificatesField = "ssl_certificates/common_name"
+	// clientCertificatesCAFile is a name of the file CA certificate of client certificates is mounted as
+	clientCertificatesCAFile = "client-ca.crt"
+)
+
+// normalizeConfigurationClientCertificates generates .spec.configuration.clientCertificates into
+// .spec.configuration.users, .settings and .files. CA certificate is mounted from the Secret and trusted by openSSL server
+func (n *Normalizer) normalizeConfigurationClientCertificates(conf *api.Configuration) {
+	if conf.ClientCertificates == nil {
+		return
+	}
+
+	for username, commonNames := range conf.ClientCertificates.GetUsers() {
+		if len(commonNames) == 0 {
+			continue
+		}
+		conf.Users = conf.Users.Ensure()
+		conf.Users.Set(username+"/"+userSSLCertificatesField, api.NewSettingVector(commonNames))
+	}
+
+	ca := conf.ClientCertificates.GetCA()
+	if !ca.HasSecretKeyRef() {
+		return
+	}
+	name, key := ca.GetNameKey()
+	conf.Files = conf.Files.Ensure()
+	conf.Files.Set(clientCertificatesCAFile, api.NewSettingSource(ca))
+	// CA certificate is mounted by files normalization as secrets.d/{file}/{secret}/{key}
+	caConfig := filepath.Join(dirPathSecretFilesConfig, clientCertificatesCAFile, name, key)
+	conf.Settings = conf.Settings.Ensure()
+	conf.Settings.SetIfNotExists("openSSL/server/caConfig", api.NewSettingScalar(caConfig))
+	conf.Settings.SetIfNotExists("openSSL/server/verificationMode", api.NewSettingScalar(conf.ClientCertificates.GetVerificationMode()))
+}
+
+// grantRoleQueryPattern is a template of a query granting role to a user, specified in user's grants. "GRANT {role}"
+const grantRoleQueryPattern = "GRANT %s"
+
+// normalizeConfigurationUserDefaults generates .spec.configuration.userDefaults into .spec.configuration.users.
+// Explicitly specified user fields have priority, lists are merged with explicitly specified ones
+func (n *Normalizer) normalizeConfigurationUserDefaults(conf *api.Configuration) {
+	for username, defaults := range conf.GetUserDefaults() {
+		if defaults == nil {
+			continue
+		}
+		conf.Users = conf.Users.Ensure()
+
+		if database := defaults.GetDefaultDatabase(); database != "" {
+			conf.Users.SetIfNotExists(username+"/default_database", api.NewSettingScalar(database))
+		}
+
+		// Roles granted to the user are default roles of the user
+		var grants []string
+		for _, role := range defaults.GetDefaultRoles() {
+			grants = append(grants, fmt.Sprintf(grantRoleQueryPattern, role))
+		}
+		n.mergeUserVectorField(conf.Users, username+"/grants/query", grants)
+
+		access := defaults.GetAccess()
+		n.mergeUserVectorField(conf.Users, username+"/networks/ip", access.GetIPs())
+		n.mergeUserVectorField(conf.Users, username+"/networks/host", access.GetHosts())
+		n.mergeUserVectorField(conf.Users, username+"/networks/host_regexp", access.GetHostRegexps())
+	}
+}
+
+// mergeUserVectorField merges values into the vector field of users
+func (n *Normalizer) mergeUserVectorField(users *api.Settings, name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	users.Set(name, api.NewSettingVector(values).MergeFrom(users.Get(name)))
+}
+
+// guardsProfileNamePattern is a template of a profile name, the per-user guards go into. "guards_{user}"
+const guardsProfileNamePattern = "guards_%s"
+
+// normalizeConfigurationGuards generates .spec.configuration.guards into .spec.configuration.settings and .profiles
+func (n *Normalizer) normalizeConfigurationGuards(conf *api.Configuration) {
+	if conf.Guards == nil {
+		return
+	}
+
+	// Server-wide guards go into server settings. Explicitly specified settings have priority
+	for name, value := range conf.Guards.GetServerSettings() {
+		if value > 0 {
+			conf.Settings = conf.Settings.Ensure()
+			conf.Settings.SetIfNotExists(name, api.NewSettingScalar(strconv.Itoa(value)))
+		}
+	}
+
+	// Per-user guards go into dedicated profile of the user, which inherits the profile the user had before
+	for username, guards := range conf.Guards.GetUsers() {
+		user := api.NewSettingsUser(conf.Users, username)
+		if !user.Has("profile") {
+			// Unknown user, reported by validation
+			continue
+		}
+
+		profile := fmt.Sprintf(guardsProfileNamePattern, username)
+		conf.Profiles = conf.Profiles.Ensure()
+		if parent := user.Get("profile").String(); parent != profile {
+			conf.Profiles.Set(profile+"/profile", api.NewSettingScalar(parent))
+		}
+		for name, value := range guards.GetProfileSettings() {
+			if value > 0 {
+				conf.Profiles.Set(profile+"/"+name, api.NewSettingScalar(strconv.Itoa(value)))
+			}
+		}
+		user.Set("profile", api.NewSettingScalar(profile))
+	}
+}
+
+// normalizeConfigurationMemory generates memory tuning of .spec.defaults.system and memory management of
+// .spec.defaults.memoryManagement into .spec.configuration.settings, so server settings are coherent with pod resources.
+// Explicitly specified settings have priority
+func (n *Normalizer) normalizeConfigurationMemory(conf *api.Configuration) {
+	memory := n.ctx.chi.Spec.Defaults.GetSystem().GetMemory()
+	for name, value := range memory.GetServerSettings() {
+		conf.Settings = conf.Settings.Ensure()
+		conf.Settings.SetIfNotExists(name, api.NewSettingScalar(value))
+	}
+	for name, value := range n.ctx.chi.Spec.Defaults.GetMemoryManagement().GetServerSettings() {
+		conf.Settings = conf.Settings.Ensure()
+		conf.Settings.SetIfNotExists(name, api.NewSettingScalar(value))
+	}
+}
+
+// normalizeConfigurationRouting generates routing of .spec.defaults.routing into the profile users have by default,
+// so Distributed queries follow services routing. Explicitly specified settings have priority
+func (n *Normalizer) normalizeConfigurationRouting(conf *api.Configuration) {
+	routing := n.ctx.chi.Spec.Defaults.GetRouting()
+	profile := chop.Config().ClickHouse.Config.User.Default.Profile
+	for name, value := range routing.GetProfileSettings() {
+		conf.Profiles = conf.Profiles.Ensure()
+		conf.Profiles.SetIfNotExists(profile+"/"+name, api.NewSettingScalar(value))
+	}
+	// Standby CHI of cross-region replication topology accepts reads only till it is promoted
+	for name, value := range n.ctx.chi.Spec.CrossRegion.GetProfileSettings() {
+		conf.Profiles = conf.Profiles.Ensure()
+		conf.Profiles.SetIfNotExists(profile+"/"+name, api.NewSettingScalar(value))
+	}
+}
+
+// normalizeConfigurationAudit generates .spec.configuration.audit into .spec.configuration.settings and .profiles,
+// so audit logs are configured alike on all hosts. Explicitly specified settings have priority
+func (n *Normalizer) normalizeConfigurationAudit(conf *api.Configuration) {
+	for name, value := range conf.Audit.GetServerSettings() {
+		conf.Settings = conf.Settings.Ensure()
+		conf.Settings.SetIfNotExists(name, api.NewSettingScalar(value))
+	}
+	profile := chop.Config().ClickHouse.Config.User.Default.Profile
+	for name, value := range conf.Audit.GetProfileSettings() {
+		conf.Profiles = conf.Profiles.Ensure()
+		conf.Profiles.SetIfNotExists(profile+"/"+name, api.NewSettingScalar(value))
+	}
+}
+
 // normalizeCluster normalizes cluster and returns deployments usage counters for this cluster
 func (n *Normalizer) normalizeCluster(cluster *api.Cluster) *api.Cluster {
 	if cluster == nil {
@@ -1464,10 +1902,13 @@ func (n *Normalizer) normalizeCluster(cluster *api.Cluster) *api.Cluster {
 	cluster.InheritTemplatesFrom(n.ctx.chi)
 
 	cluster.Zookeeper = n.normalizeConfigurationZookeeper(cluster.Zookeeper)
+	n.normalizeClusterReplicationThrottling(cluster)
 	cluster.Settings = n.normalizeConfigurationSettings(cluster.Settings)
 	cluster.Files = n.normalizeConfigurationFiles(cluster.Files)
+	cluster.Metadata = n.normalizeScopeMetadata(cluster.Metadata)
 
 	cluster.SchemaPolicy = n.normalizeClusterSchemaPolicy(cluster.SchemaPolicy)
+	cluster.ReplicatedDatabases = n.normalizeClusterReplicatedDatabases(cluster.Name, cluster.ReplicatedDatabases)
 
 	if cluster.Layout == nil {
 		cluster.Layout = api.NewChiClusterLayout()
@@ -1562,6 +2003,46 @@ func (n *Normalizer) normalizeClusterSchemaPolicy(policy *api.SchemaPolicy) *api
 	return policy
 }
 
+// ReplicatedDatabaseZookeeperPathPattern specifies default ZooKeeper path of a database with Replicated engine.
+// Macros are expanded by ClickHouse
+const ReplicatedDatabaseZookeeperPathPattern = "/clickhouse/{installation}/{cluster}/databases/%s"
+
+// normalizeClusterReplicationThrottling generates replication throttling of the cluster into cluster-level settings,
+// so all hosts of the cluster have replication traffic capped alike. Explicitly specified settings have priority
+func (n *Normalizer) normalizeClusterReplicationThrottling(cluster *api.Cluster) {
+	for name, value := range cluster.ReplicationThrottling.GetServerSettings() {
+		cluster.Settings = cluster.Settings.Ensure()
+		cluster.Settings.SetIfNotExists(name, api.NewSettingScalar(value))
+	}
+}
+
+// normalizeClusterReplicatedDatabases normalizes databases with Replicated engine of the cluster
+func (n *Normalizer) normalizeClusterReplicatedDatabases(cluster string, databases []api.ChiReplicatedDatabase) []api.ChiReplicatedDatabase {
+	var res []api.ChiReplicatedDatabase
+	var names []string
+	for i := range databases {
+		db := databases[i]
+		if (db.Name == "") || util.InArray(db.Name, names) {
+			// Skip unnamed and duplicated databases
+			continue
+		}
+		if !IsValidDatabaseName(db.Name) {
+			n.reject("cluster %s replicated database %q: invalid name", cluster, db.Name)
+			continue
+		}
+		if db.ZookeeperPath == "" {
+			db.ZookeeperPath = fmt.Sprintf(ReplicatedDatabaseZookeeperPathPattern, db.Name)
+		}
+		if !IsValidZookeeperPath(db.ZookeeperPath) {
+			n.reject("cluster %s replicated database %s: invalid zookeeper path %q", cluster, db.Name, db.ZookeeperPath)
+			continue
+		}
+		names = append(names, db.Name)
+		res = append(res, db)
+	}
+	return res
+}
+
 // normalizeClusterLayoutShardsCountAndReplicasCount ensures at least 1 shard and 1 replica counters
 func (n *Normalizer) normalizeClusterLayoutShardsCountAndReplicasCount(clusterLayout *api.ChiClusterLayout) *api.ChiClusterLayout {
 	if clusterLayout == nil {
@@ -1605,6 +2086,10 @@ func (n *Normalizer) normalizeClusterLayoutShardsCountAndReplicasCount(clusterLa
 
 	// Deal with unspecified ReplicasCount
 	if clusterLayout.ReplicasCount == 0 {
+		// Use CHI-wide default replicas count, if any
+		clusterLayout.ReplicasCount = n.ctx.chi.Spec.Defaults.GetReplicasCount()
+	}
+	if clusterLayout.ReplicasCount <= 0 {
 		// We need to have at least one Replica
 		clusterLayout.ReplicasCount = 1
 	}
@@ -1670,6 +2155,9 @@ func (n *Normalizer) normalizeShard(shard *api.ChiShard, cluster *api.Cluster, s
 	shard.InheritFilesFrom(cluster)
 	shard.Files = n.normalizeConfigurationFiles(shard.Files)
 	shard.InheritTemplatesFrom(cluster)
+	shard.Metadata = n.normalizeScopeMetadata(shard.Metadata)
+	shard.InheritMetadataFrom(cluster)
+	shard.Zookeeper = n.normalizeConfigurationZookeeper(shard.Zookeeper)
 	// Normalize Replicas
 	n.normalizeShardReplicasCount(shard, cluster.Layout.ReplicasCount)
 	n.normalizeShardHosts(shard, cluster, shardIndex)
@@ -1686,6 +2174,9 @@ func (n *Normalizer) normalizeReplica(replica *api.ChiReplica, cluster *api.Clus
 	replica.InheritFilesFrom(cluster)
 	replica.Files = n.normalizeConfigurationFiles(replica.Files)
 	replica.InheritTemplatesFrom(cluster)
+	replica.Metadata = n.normalizeScopeMetadata(replica.Metadata)
+	replica.InheritMetadataFrom(cluster)
+	replica.Zookeeper = n.normalizeConfigurationZookeeper(replica.Zookeeper)
 	// Normalize Shards
 	n.normalizeReplicaShardsCount(replica, cluster.Layout.ShardsCount)
 	n.normalizeReplicaHosts(replica, cluster, replicaIndex)
@@ -1808,10 +2299,36 @@ func (n *Normalizer) normalizeHost(
 		r = replica
 	}
 	host.InheritSettingsFrom(s, r)
+	n.normalizeHostThrottling(host)
 	host.Settings = n.normalizeConfigurationSettings(host.Settings)
 	host.InheritFilesFrom(s, r)
 	host.Files = n.normalizeConfigurationFiles(host.Files)
 	host.InheritTemplatesFrom(s, r, nil)
+	// Metadata is inherited from both shard and replica the host belongs to, shard takes precedence
+	host.Metadata = n.normalizeScopeMetadata(host.Metadata)
+	host.InheritMetadataFrom(shard, replica)
+}
+
+// normalizeScopeMetadata drops labels and annotations reserved for the operator from metadata of a cluster, shard, replica or host
+func (n *Normalizer) normalizeScopeMetadata(metadata *api.ChiScopeMetadata) *api.ChiScopeMetadata {
+	for _, key := range metadata.DropPrefixed(labelPrefixReserved) {
+		log.V(1).M(n.ctx.chi).F().Warning("Skip metadata key reserved for the operator: %s", key)
+	}
+	return metadata
+}
+
+// normalizeHostThrottling applies disk usage throttling settings to the host being under disk pressure
+func (n *Normalizer) normalizeHostThrottling(host *api.ChiHost) {
+	policy := n.ctx.chi.Spec.Maintenance.GetDiskUsage()
+	if !policy.IsEnabled() || policy.IsAlert() {
+		return
+	}
+	if !util.InArray(host.GetName(), n.ctx.chi.EnsureStatus().GetDiskPressureHosts()) {
+		return
+	}
+
+	// Throttling settings have priority over own settings of the host
+	host.Settings = policy.GetSettings().DeepCopy().MergeFrom(host.Settings)
 }
 
 // normalizeHostTemplateSpec is the same as normalizeHost but for a template
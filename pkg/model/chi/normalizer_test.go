@@ -0,0 +1,338 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+func Test_NormalizeReplicatedDatabases(t *testing.T) {
+	chi := newTestCHI(t, `
+metadata:
+  name: replicated
+spec:
+  configuration:
+    clusters:
+      - name: main
+        replicatedDatabases:
+          - name: events
+          - name: events
+          - name: "x\"; DROP DATABASE system; --"
+          - name: logs
+            zookeeperPath: "/logs') ENGINE = Memory; --"
+          - name: metrics
+            zookeeperPath: /clickhouse/{cluster}/metrics
+`)
+
+	require.Equal(t, []api.ChiReplicatedDatabase{
+		{Name: "events", ZookeeperPath: "/clickhouse/{installation}/{cluster}/databases/events"},
+		{Name: "metrics", ZookeeperPath: "/clickhouse/{cluster}/metrics"},
+	}, chi.FindCluster("main").ReplicatedDatabases)
+
+	require.Equal(t, []string{
+		`cluster main replicated database "x\"; DROP DATABASE system; --": invalid name`,
+		`cluster main replicated database logs: invalid zookeeper path "/logs') ENGINE = Memory; --"`,
+	}, chi.EnsureStatus().GetRejections())
+}
+
+func Test_NormalizeMonitoring(t *testing.T) {
+	chi := newTestCHI(t, `
+metadata:
+  name: monitoring
+spec:
+  configuration:
+    monitoring:
+      schema: "yes"
+      database: "mon\"; DROP DATABASE system"
+`)
+
+	require.Equal(t, api.MonitoringDatabaseDefault, chi.Spec.Configuration.GetMonitoring().GetDatabase())
+	require.Equal(t, []string{
+		`monitoring database "mon\"; DROP DATABASE system": invalid name, monitoring is used instead`,
+	}, chi.EnsureStatus().GetRejections())
+}
+
+// hostPortsTestManifest specifies CHI of two hosts with ClickHouse ports exposed on the node
+const hostPortsTestManifest = `
+metadata:
+  name: ports
+spec:
+  defaults:
+    distributeHostPorts: %q
+    templates:
+      podTemplate: pod
+  configuration:
+    clusters:
+      - name: main
+        layout:
+          replicasCount: 2
+  templates:
+    podTemplates:
+      - name: pod
+        spec:
+          hostNetwork: %t
+          containers:
+            - name: clickhouse
+              ports:
+                - name: tcp
+                  containerPort: 9000
+                  hostPort: 9000
+`
+
+func Test_NormalizeHostPorts(t *testing.T) {
+	tests := []struct {
+		name        string
+		distribute  string
+		hostNetwork bool
+		tcpPorts    []int32
+		hostPorts   []int32
+	}{
+		{
+			name:      "host ports are not distributed by default",
+			tcpPorts:  []int32{9000, 9000},
+			hostPorts: []int32{0, 0},
+		},
+		{
+			name:       "host ports are distributed on request",
+			distribute: "yes",
+			tcpPorts:   []int32{9000, 9001},
+			hostPorts:  []int32{9000, 9001},
+		},
+		{
+			name:        "host network ports are distributed always",
+			hostNetwork: true,
+			tcpPorts:    []int32{9000, 9001},
+			hostPorts:   []int32{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chi := newTestCHI(t, fmt.Sprintf(hostPortsTestManifest, tt.distribute, tt.hostNetwork))
+			var tcpPorts, hostPorts []int32
+			chi.WalkHosts(func(host *api.ChiHost) error {
+				tcpPorts = append(tcpPorts, host.TCPPort)
+				statefulSet := model.NewStatefulSetGenerator(chi).CreateStatefulSet(host, false)
+				for _, container := range statefulSet.Spec.Template.Spec.Containers {
+					for _, port := range container.Ports {
+						if port.Name == "tcp" {
+							hostPorts = append(hostPorts, port.HostPort)
+						}
+					}
+				}
+				return nil
+			})
+			require.Equal(t, tt.tcpPorts, tcpPorts)
+			require.Equal(t, tt.hostPorts, hostPorts)
+		})
+	}
+}
+
+// memoryManagementTestManifest specifies CHI of single host with memory management and pod template resources
+const memoryManagementTestManifest = `
+metadata:
+  name: memory
+spec:
+  defaults:
+    memoryManagement:
+      limit: 16Gi
+      maxServerMemoryUsageToRAMRatio: %q
+      oomScoreAdj: -500
+    templates:
+      podTemplate: pod
+  configuration:
+    clusters:
+      - name: main
+  templates:
+    podTemplates:
+      - name: pod
+        spec:
+          containers:
+            - name: clickhouse
+              resources: %s
+`
+
+func Test_NormalizeMemoryManagement(t *testing.T) {
+	tests := []struct {
+		name       string
+		ratio      string
+		resources  string
+		limit      string
+		request    string
+		settings   map[string]string
+		rejections []string
+	}{
+		{
+			name:      "memory management sets unset resources",
+			ratio:     "0.5",
+			resources: "{}",
+			limit:     "16Gi",
+			request:   "16Gi",
+			settings: map[string]string{
+				"max_server_memory_usage_to_ram_ratio": "0.5",
+				"max_server_memory_usage":              "8589934592",
+				"oom_score":                            "-500",
+			},
+		},
+		{
+			name:      "pod template resources are kept",
+			resources: "{limits: {memory: 8Gi}, requests: {memory: 4Gi}}",
+			limit:     "8Gi",
+			request:   "4Gi",
+		},
+		{
+			name:      "request defaults to pod template limit",
+			resources: "{limits: {memory: 8Gi}}",
+			limit:     "8Gi",
+			request:   "8Gi",
+		},
+		{
+			name:      "ratio above one is rejected",
+			ratio:     "1.5",
+			resources: "{}",
+			limit:     "16Gi",
+			request:   "16Gi",
+			settings: map[string]string{
+				"max_server_memory_usage_to_ram_ratio": api.MemoryManagementRatioDefault,
+			},
+			rejections: []string{`memory management ratio "1.5": has to be within (0, 1], 0.9 is used instead`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chi := newTestCHI(t, fmt.Sprintf(memoryManagementTestManifest, tt.ratio, tt.resources))
+			for name, value := range tt.settings {
+				require.Equal(t, value, chi.Spec.Configuration.Settings.Get(name).String(), name)
+			}
+			require.Equal(t, tt.rejections, chi.EnsureStatus().GetRejections())
+
+			host := chi.FindCluster("main").Layout.Shards[0].Hosts[0]
+			statefulSet := model.NewStatefulSetGenerator(chi).CreateStatefulSet(host, false)
+			resources := statefulSet.Spec.Template.Spec.Containers[0].Resources
+			require.Equal(t, tt.limit, resources.Limits.Memory().String())
+			require.Equal(t, tt.request, resources.Requests.Memory().String())
+		})
+	}
+}
+
+// userDefaultsTestManifest specifies CHI with defaults of users, some of which are specified explicitly
+const userDefaultsTestManifest = `
+metadata:
+  name: users
+spec:
+  configuration:
+    users:
+      analyst/password: secret
+      analyst/networks/ip: 192.168.0.1
+      admin/password: secret
+      admin/default_database: system
+    userDefaults:
+      analyst:
+        defaultDatabase: reports
+        defaultRoles:
+          - reader
+          - writer
+        access:
+          ips:
+            - 10.0.0.0/8
+          hosts:
+            - bi.example.com
+          hostRegexps:
+            - ^bi-.*$
+      admin:
+        defaultDatabase: reports
+`
+
+func Test_NormalizeUserDefaults(t *testing.T) {
+	chi := newTestCHI(t, userDefaultsTestManifest)
+	users := chi.Spec.Configuration.Users
+
+	require.Equal(t, "reports", users.Get("analyst/default_database").String())
+	require.Equal(t, []string{"GRANT reader", "GRANT writer"}, users.Get("analyst/grants/query").AsVectorOfStrings())
+	// Access is merged into explicitly specified networks
+	require.Subset(t, users.Get("analyst/networks/ip").AsVectorOfStrings(), []string{"192.168.0.1", "10.0.0.0/8"})
+	require.Equal(t, []string{"bi.example.com"}, users.Get("analyst/networks/host").AsVectorOfStrings())
+	require.Equal(t, []string{"^bi-.*$"}, users.Get("analyst/networks/host_regexp").AsVectorOfStrings())
+
+	// Explicitly specified settings have priority
+	require.Equal(t, "system", users.Get("admin/default_database").String())
+	require.False(t, users.Has("admin/grants/query"))
+}
+
+func Test_NormalizeHostMacros(t *testing.T) {
+	tests := []struct {
+		name       string
+		macros     string
+		nodeName   string
+		nodeLabels map[string]string
+		rejections []string
+	}{
+		{
+			name:       "valid names are kept",
+			macros:     "{nodeName: node, nodeLabels: {zone: topology.kubernetes.io/zone, rack.id: rack}}",
+			nodeName:   "node",
+			nodeLabels: map[string]string{"zone": "topology.kubernetes.io/zone", "rack.id": "rack"},
+		},
+		{
+			name:       "invalid names are rejected",
+			macros:     "{nodeName: 1node, nodeLabels: {zone: topology.kubernetes.io/zone, a/b: rack}}",
+			nodeLabels: map[string]string{"zone": "topology.kubernetes.io/zone"},
+			rejections: []string{
+				`host macro "1node": invalid name`,
+				`host macro "a/b": invalid name`,
+			},
+		},
+		{
+			name:       "names of operator macros are rejected",
+			macros:     "{nodeName: replica, nodeLabels: {shard: a, all-sharded-shard: b, region: c, zone: d}}",
+			nodeLabels: map[string]string{"zone": "d"},
+			rejections: []string{
+				`host macro "replica": name is reserved by the operator`,
+				`host macro "all-sharded-shard": name is reserved by the operator`,
+				`host macro "region": name is reserved by the operator`,
+				`host macro "shard": name is reserved by the operator`,
+			},
+		},
+		{
+			name:       "names of cross-region and fault domain macros are rejected",
+			macros:     "{nodeLabels: {cross_region_replica: a, fault_domain: b, installation: c, cluster: d}}",
+			nodeLabels: map[string]string{},
+			rejections: []string{
+				`host macro "cluster": name is reserved by the operator`,
+				`host macro "cross_region_replica": name is reserved by the operator`,
+				`host macro "fault_domain": name is reserved by the operator`,
+				`host macro "installation": name is reserved by the operator`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chi := newTestCHI(t, fmt.Sprintf(`
+metadata:
+  name: macros
+spec:
+  hostMacros: %s
+`, tt.macros))
+			require.Equal(t, tt.nodeName, chi.Spec.HostMacros.NodeName)
+			require.Equal(t, tt.nodeLabels, chi.Spec.HostMacros.NodeLabels)
+			require.Equal(t, tt.rejections, chi.EnsureStatus().GetRejections())
+		})
+	}
+}
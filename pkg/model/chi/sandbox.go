@@ -0,0 +1,197 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"fmt"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+const (
+	// upgradeSandboxCHINamePattern is a template of upgrade sandbox CHI name. "{chi}-sandbox-{id}"
+	upgradeSandboxCHINamePattern = "%s-sandbox-%s"
+	// upgradeSandboxIDLen specifies length of the ID of the CHI being verified, sandbox name is suffixed with
+	upgradeSandboxIDLen = 8
+)
+
+// CreateUpgradeSandboxCHIName creates a name of an upgrade sandbox CHI for the specified CHI.
+// Name is suffixed with ID of the CHI being verified, made of its namespace, name and UID, so sandbox name
+// does not collide with other CHIs, neither with user-created "{chi}-sandbox" ones nor with sandboxes of recreated CHIs
+func CreateUpgradeSandboxCHIName(chi *api.ClickHouseInstallation) string {
+	id := util.CreateStringID(chi.Namespace+"/"+chi.Name+"/"+string(chi.UID), upgradeSandboxIDLen)
+	return sanitize(fmt.Sprintf(upgradeSandboxCHINamePattern, chi.Name, id))
+}
+
+// IsUpgradeSandbox checks whether CHI is an upgrade sandbox of another CHI
+func IsUpgradeSandbox(chi *api.ClickHouseInstallation) bool {
+	_, ok := GetUpgradeSandboxParentName(chi)
+	return ok
+}
+
+// GetUpgradeSandboxParentName gets name of the CHI the specified upgrade sandbox CHI verifies upgrade of
+func GetUpgradeSandboxParentName(chi *api.ClickHouseInstallation) (string, bool) {
+	if chi == nil {
+		return "", false
+	}
+	name, ok := chi.Labels[LabelUpgradeSandboxOf]
+	return name, ok && (name != "")
+}
+
+// IsImageInUse checks whether ClickHouse container of any pod template of the CHI uses specified image
+func IsImageInUse(chi *api.ClickHouseInstallation, image string) bool {
+	found := false
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		if podTemplate, ok := host.GetPodTemplate(); ok {
+			if container, ok := getPodTemplateClickHouseContainer(podTemplate); ok && (container.Image == image) {
+				found = true
+			}
+		}
+		return nil
+	})
+	return found
+}
+
+// CreateUpgradeSandboxCHI creates a small ephemeral copy of the normalized CHI in order to verify upgrade to the image,
+// specified in upgrade verification section of the CHI.
+// Sandbox has the same clusters, each of one host, so schema objects referring clusters are able to be created.
+// Sandbox has no Zookeeper and no persistent storage, so it does not interfere with the CHI being verified.
+func CreateUpgradeSandboxCHI(chi *api.ClickHouseInstallation) *api.ClickHouseInstallation {
+	image := chi.Spec.UpgradeVerification.GetImage()
+	controller := true
+	block := true
+
+	sandbox := &api.ClickHouseInstallation{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      CreateUpgradeSandboxCHIName(chi),
+			Namespace: chi.Namespace,
+			Labels: map[string]string{
+				LabelUpgradeSandboxOf: chi.Name,
+			},
+			OwnerReferences: []meta.OwnerReference{
+				{
+					APIVersion:         api.SchemeGroupVersion.String(),
+					Kind:               api.ClickHouseInstallationCRDResourceKind,
+					Name:               chi.Name,
+					UID:                chi.UID,
+					Controller:         &controller,
+					BlockOwnerDeletion: &block,
+				},
+			},
+		},
+		Spec: api.ChiSpec{
+			NamespaceDomainPattern: chi.Spec.NamespaceDomainPattern,
+			Configuration:          api.NewConfiguration(),
+			Templates:              api.NewChiTemplates(),
+		},
+	}
+
+	if chi.Spec.Configuration != nil {
+		sandbox.Spec.Configuration.Users = api.NewSettings().MergeFromCB(
+			chi.Spec.Configuration.Users,
+			func(name string, _ *api.Setting) bool {
+				// Allowed hosts are specific for the CHI being verified
+				return !strings.HasSuffix(name, "/networks/host_regexp")
+			},
+		)
+		sandbox.Spec.Configuration.Profiles = chi.Spec.Configuration.Profiles
+		sandbox.Spec.Configuration.Quotas = chi.Spec.Configuration.Quotas
+		sandbox.Spec.Configuration.Settings = chi.Spec.Configuration.Settings
+		sandbox.Spec.Configuration.Files = chi.Spec.Configuration.Files
+	}
+
+	chi.WalkClusters(func(cluster *api.Cluster) error {
+		podTemplate := newUpgradeSandboxPodTemplate(cluster, image)
+		sandbox.Spec.Templates.PodTemplates = append(sandbox.Spec.Templates.PodTemplates, *podTemplate)
+		sandbox.Spec.Configuration.Clusters = append(sandbox.Spec.Configuration.Clusters, &api.Cluster{
+			Name:     cluster.Name,
+			Settings: cluster.Settings,
+			Files:    cluster.Files,
+			Templates: &api.ChiTemplateNames{
+				PodTemplate: podTemplate.Name,
+			},
+			Insecure: cluster.Insecure,
+			Secure:   cluster.Secure,
+			Secret:   cluster.Secret,
+			Layout: &api.ChiClusterLayout{
+				ShardsCount:   1,
+				ReplicasCount: 1,
+			},
+		})
+		return nil
+	})
+
+	return sandbox
+}
+
+// newUpgradeSandboxPodTemplate creates pod template for the sandbox cluster out of the pod template of the first host
+// of the cluster. ClickHouse container is switched to the target image and mounts of persistent volumes are dropped.
+func newUpgradeSandboxPodTemplate(cluster *api.Cluster, image string) *api.ChiPodTemplate {
+	podTemplate := &api.ChiPodTemplate{
+		Name: cluster.Name,
+	}
+	if host := cluster.FirstHost(); host != nil {
+		if template, ok := host.GetPodTemplate(); ok {
+			podTemplate.Spec = *template.Spec.DeepCopy()
+			podTemplate.ObjectMeta = *template.ObjectMeta.DeepCopy()
+		}
+	}
+
+	if len(podTemplate.Spec.Containers) == 0 {
+		podTemplate.Spec.Containers = append(podTemplate.Spec.Containers, core.Container{
+			Name: clickHouseContainerName,
+		})
+	}
+
+	// Keep mounts of pod's own volumes only, since sandbox has no volume claim templates
+	volumes := make(map[string]bool)
+	for _, volume := range podTemplate.Spec.Volumes {
+		volumes[volume.Name] = true
+	}
+	for i := range podTemplate.Spec.Containers {
+		container := &podTemplate.Spec.Containers[i]
+		var volumeMounts []core.VolumeMount
+		for _, volumeMount := range container.VolumeMounts {
+			if volumes[volumeMount.Name] {
+				volumeMounts = append(volumeMounts, volumeMount)
+			}
+		}
+		container.VolumeMounts = volumeMounts
+	}
+	if container, ok := getPodTemplateClickHouseContainer(podTemplate); ok {
+		container.Image = image
+	}
+
+	return podTemplate
+}
+
+// getPodTemplateClickHouseContainer gets ClickHouse container of the pod template
+func getPodTemplateClickHouseContainer(podTemplate *api.ChiPodTemplate) (*core.Container, bool) {
+	for i := range podTemplate.Spec.Containers {
+		if podTemplate.Spec.Containers[i].Name == clickHouseContainerName {
+			return &podTemplate.Spec.Containers[i], true
+		}
+	}
+	if len(podTemplate.Spec.Containers) > 0 {
+		// ClickHouse container is the first one by default
+		return &podTemplate.Spec.Containers[0], true
+	}
+	return nil, false
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+func newSandboxTestCHI(namespace, name string, uid types.UID) *api.ClickHouseInstallation {
+	return &api.ClickHouseInstallation{
+		ObjectMeta: meta.ObjectMeta{Namespace: namespace, Name: name, UID: uid},
+	}
+}
+
+func Test_CreateUpgradeSandboxCHIName(t *testing.T) {
+	chi := newSandboxTestCHI("ns1", "events", "uid-1")
+	name := model.CreateUpgradeSandboxCHIName(chi)
+
+	require.Regexp(t, `^events-sandbox-[0-9a-f]{8}$`, name)
+	require.Equal(t, name, model.CreateUpgradeSandboxCHIName(newSandboxTestCHI("ns1", "events", "uid-1")), "name has to be stable")
+	require.NotEqual(t, "events-sandbox", name, "name must not collide with user-created CHI")
+	require.NotEqual(t, name, model.CreateUpgradeSandboxCHIName(newSandboxTestCHI("ns2", "events", "uid-1")))
+	require.NotEqual(t, name, model.CreateUpgradeSandboxCHIName(newSandboxTestCHI("ns1", "events", "uid-2")))
+	require.NotEqual(t,
+		model.CreateUpgradeSandboxCHIName(newSandboxTestCHI("ns1", "a-sandbox", "")),
+		model.CreateUpgradeSandboxCHIName(newSandboxTestCHI("ns1", "a", "")),
+	)
+}
+
+func Test_CreateUpgradeSandboxCHI(t *testing.T) {
+	require.NoError(t, chop.NewOffline(""))
+
+	chi := newSandboxTestCHI("ns1", "events", "uid-1")
+	chi.Spec.UpgradeVerification = &api.ChiUpgradeVerification{Image: "clickhouse/clickhouse-server:24.3"}
+	chi.Spec.Configuration = &api.Configuration{
+		Clusters: []*api.Cluster{
+			{Name: "main", Layout: &api.ChiClusterLayout{ShardsCount: 2, ReplicasCount: 2}},
+		},
+	}
+	chi = mustNormalize(t, chi)
+	sandbox := model.CreateUpgradeSandboxCHI(chi)
+
+	require.Equal(t, "ns1", sandbox.Namespace)
+	require.True(t, model.IsUpgradeSandbox(sandbox))
+	require.False(t, model.IsUpgradeSandbox(chi))
+	parent, _ := model.GetUpgradeSandboxParentName(sandbox)
+	require.Equal(t, "events", parent)
+	require.Len(t, sandbox.OwnerReferences, 1)
+	require.Equal(t, chi.UID, sandbox.OwnerReferences[0].UID)
+
+	// Each cluster is a single host running the image being verified
+	require.Len(t, sandbox.Spec.Configuration.Clusters, 1)
+	require.Equal(t, 1, sandbox.Spec.Configuration.Clusters[0].Layout.ShardsCount)
+	require.Equal(t, 1, sandbox.Spec.Configuration.Clusters[0].Layout.ReplicasCount)
+	require.True(t, model.IsImageInUse(mustNormalize(t, sandbox), "clickhouse/clickhouse-server:24.3"))
+}
+
+func mustNormalize(t *testing.T, chi *api.ClickHouseInstallation) *api.ClickHouseInstallation {
+	normalized, err := model.NewNormalizer(nil).CreateTemplatedCHI(chi, model.NewNormalizerOptions())
+	require.NoError(t, err)
+	return normalized
+}
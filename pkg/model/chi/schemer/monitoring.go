@@ -0,0 +1,37 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemer
+
+import (
+	"context"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/model/clickhouse"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// HostCreateMonitoringSchema creates or upgrades monitoring schema on a host
+func (s *ClusterSchemer) HostCreateMonitoringSchema(ctx context.Context, host *api.ChiHost, database string) error {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("ctx is done")
+		return nil
+	}
+
+	names, sqls := s.sqlCreateMonitoringSchema(host.Address.ClusterName, database)
+	log.V(1).M(host).F().Info("Creating monitoring schema at %s: %v", host.Address.HostName, names)
+	log.V(2).M(host).F().Info("\n%v", sqls)
+	return s.ExecHost(ctx, host, sqls, clickhouse.NewQueryOptions().SetRetry(true))
+}
@@ -16,6 +16,7 @@ package schemer
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	log "github.com/altinity/clickhouse-operator/pkg/announcer"
@@ -53,7 +54,26 @@ func (s *ClusterSchemer) HostDropReplica(ctx context.Context, hostToRunOn, hostT
 	replica := chi.CreateInstanceHostname(hostToDrop)
 	shard := hostToRunOn.Address.ShardIndex
 	log.V(1).M(hostToRunOn).F().Info("Drop replica: %v at %v", replica, hostToRunOn.Address.HostName)
-	return s.ExecHost(ctx, hostToRunOn, s.sqlDropReplica(shard, replica), clickhouse.NewQueryOptions().SetRetry(false))
+	sqls := s.sqlDropReplica(shard, replica)
+	// Databases with Replicated engine, specified in the cluster, refer replica by macros
+	for _, db := range hostToDrop.GetCluster().ReplicatedDatabases {
+		sqls = append(sqls, s.sqlDropDatabaseReplica(db, hostToDrop.Address.ShardName, chi.CreateMacroReplica(hostToDrop)))
+	}
+	return s.ExecHost(ctx, hostToRunOn, sqls, clickhouse.NewQueryOptions().SetRetry(false))
+}
+
+// HostCreateReplicatedDatabases creates databases with Replicated engine, specified in the cluster, on a host
+func (s *ClusterSchemer) HostCreateReplicatedDatabases(ctx context.Context, host *api.ChiHost) error {
+	var names, sqls []string
+	for _, db := range host.GetCluster().ReplicatedDatabases {
+		names = append(names, db.Name)
+		sqls = append(sqls, s.sqlCreateDatabaseReplicatedEngine(db))
+	}
+	if len(sqls) == 0 {
+		return nil
+	}
+	log.V(1).M(host).F().Info("Creating replicated databases at %s: %v", host.Address.HostName, names)
+	return s.ExecHost(ctx, host, sqls, clickhouse.NewQueryOptions().SetRetry(true))
 }
 
 // createTablesSQLs makes all SQL for migrating tables
@@ -147,16 +167,100 @@ func (s *ClusterSchemer) CHIDropDnsCache(ctx context.Context, chi *api.ClickHous
 	return nil
 }
 
+// HostReloadConfig reloads config, including users config, on a host
+func (s *ClusterSchemer) HostReloadConfig(ctx context.Context, host *api.ChiHost) error {
+	log.V(1).M(host).F().Info("Reload config at %s", host.Address.HostName)
+	return s.ExecHost(ctx, host, []string{s.sqlReloadConfig()}, clickhouse.NewQueryOptions().SetRetry(false))
+}
+
+// HostRunOperation runs maintenance operation on a host
+func (s *ClusterSchemer) HostRunOperation(ctx context.Context, host *api.ChiHost, spec *api.OperationSpec) error {
+	sql := s.sqlOperation(spec)
+	log.V(1).M(host).F().Info("Run operation %s at %s: %s", spec.Type, host.Address.HostName, sql)
+	opts := clickhouse.NewQueryOptions().SetRetry(false)
+	opts.SetQueryTimeout(10 * time.Minute)
+	return s.ExecHost(ctx, host, []string{sql}, opts)
+}
+
 // HostActiveQueriesNum returns how many active queries are on the host
 func (s *ClusterSchemer) HostActiveQueriesNum(ctx context.Context, host *api.ChiHost) (int, error) {
 	return s.QueryHostInt(ctx, host, s.sqlActiveQueriesNum())
 }
 
+// HostDeepCheck checks the host is able to serve: it accepts connections, runs queries
+// and has all databases of the cluster attached
+func (s *ClusterSchemer) HostDeepCheck(ctx context.Context, host *api.ChiHost) error {
+	if res, err := s.QueryHostInt(ctx, host, s.sqlPing()); err != nil {
+		return err
+	} else if res != 1 {
+		return fmt.Errorf("unexpected result of ping query: %d", res)
+	}
+	missing, err := s.QueryHostInt(ctx, host, s.sqlMissingDatabasesNum(host.Address.ClusterName))
+	if err != nil {
+		return err
+	}
+	if missing > 0 {
+		return fmt.Errorf("databases not attached: %d", missing)
+	}
+	return nil
+}
+
+// HostLostTablesNum returns number of tables of the cluster lost by the host, in case the host has no tables at all,
+// as happens when the host is started over an empty data volume
+func (s *ClusterSchemer) HostLostTablesNum(ctx context.Context, host *api.ChiHost) (int, error) {
+	return s.QueryHostInt(ctx, host, s.sqlLostTablesNum(host.Address.ClusterName))
+}
+
 // HostClickHouseVersion returns ClickHouse version on the host
 func (s *ClusterSchemer) HostClickHouseVersion(ctx context.Context, host *api.ChiHost) (string, error) {
 	return s.QueryHostString(ctx, host, s.sqlVersion())
 }
 
+// HostMaxReplicationDelay returns max replication delay (in seconds) of replicated tables on the host
+func (s *ClusterSchemer) HostMaxReplicationDelay(ctx context.Context, host *api.ChiHost) (int, error) {
+	return s.QueryHostInt(ctx, host, s.sqlMaxReplicationDelay())
+}
+
+// HostReadonlyReplicasNum returns how many replicated tables are in read-only mode on the host
+func (s *ClusterSchemer) HostReadonlyReplicasNum(ctx context.Context, host *api.ChiHost) (int, error) {
+	return s.QueryHostInt(ctx, host, s.sqlReadonlyReplicasNum())
+}
+
+// HostDiskUsage returns max disk usage (in percent) over all disks of the host
+func (s *ClusterSchemer) HostDiskUsage(ctx context.Context, host *api.ChiHost) (int, error) {
+	return s.QueryHostInt(ctx, host, s.sqlDiskUsage())
+}
+
+// HostMemoryUsed returns resident memory (in bytes) of ClickHouse server on the host
+func (s *ClusterSchemer) HostMemoryUsed(ctx context.Context, host *api.ChiHost) (int, error) {
+	return s.QueryHostInt(ctx, host, s.sqlMemoryUsed())
+}
+
+// HostStorageUsed returns used space (in bytes) over all disks of the host
+func (s *ClusterSchemer) HostStorageUsed(ctx context.Context, host *api.ChiHost) (int, error) {
+	return s.QueryHostInt(ctx, host, s.sqlStorageUsed())
+}
+
+// HostStuckMutations returns names and 'KILL MUTATION ...' SQLs of mutations running on the host longer than max duration
+func (s *ClusterSchemer) HostStuckMutations(ctx context.Context, host *api.ChiHost, maxDuration int) ([]string, []string, error) {
+	databases, tables, ids, err := s.QueryUnzip3Columns(ctx, chi.CreateFQDNs(host, api.ChiHost{}, false), s.sqlStuckMutations(maxDuration))
+	if err != nil {
+		return nil, nil, err
+	}
+	var names, sqls []string
+	for i := range ids {
+		names = append(names, fmt.Sprintf("%s.%s:%s", databases[i], tables[i], ids[i]))
+		sqls = append(sqls, s.sqlKillMutation(databases[i], tables[i], ids[i]))
+	}
+	return names, sqls, nil
+}
+
+// HostKillMutations kills mutations on the host
+func (s *ClusterSchemer) HostKillMutations(ctx context.Context, host *api.ChiHost, sqls []string) error {
+	log.V(1).M(host).F().Info("Kill mutations at %s: %v", host.Address.HostName, sqls)
+	return s.ExecHost(ctx, host, sqls, clickhouse.NewQueryOptions().SetRetry(false))
+}
+
 func debugCreateSQLs(names, sqls []string, err error) ([]string, []string) {
 	if err != nil {
 		log.V(1).Warning("got error: %v", err)
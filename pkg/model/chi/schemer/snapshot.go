@@ -0,0 +1,61 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemer
+
+import (
+	"context"
+	"regexp"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/model/clickhouse"
+)
+
+// replicatedEngineRegexp matches Replicated*MergeTree engine along with its Zookeeper path and replica name arguments
+var replicatedEngineRegexp = regexp.MustCompile(`Replicated(\w*MergeTree)(\(\s*'[^']*'\s*,\s*'[^']*'\s*,?\s*)?`)
+
+// HostSchemaSnapshot fetches schema-only snapshot of the host as a set of 'CREATE ...' SQLs.
+// Replicated tables are converted into non-replicated ones, so snapshot can be restored without Zookeeper.
+func (s *ClusterSchemer) HostSchemaSnapshot(ctx context.Context, host *api.ChiHost) ([]string, []string, error) {
+	names, sqls, err := s.QueryUnzip2Columns(ctx, chi.CreateFQDNs(host, api.ChiHost{}, false), s.sqlSchemaSnapshot())
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range sqls {
+		sqls[i] = unreplicate(sqls[i])
+	}
+	log.V(1).M(host).F().Info("Schema snapshot of host %s: %v", host.GetName(), names)
+	return names, sqls, nil
+}
+
+// HostRestoreSchemaSnapshot restores schema-only snapshot on the host
+func (s *ClusterSchemer) HostRestoreSchemaSnapshot(ctx context.Context, host *api.ChiHost, sqls []string) error {
+	log.V(1).M(host).F().Info("Restore schema snapshot on host %s", host.GetName())
+	log.V(2).M(host).F().Info("\n%v", sqls)
+	// Retry traverses SQLs multiple times, so objects depending on each other are created eventually
+	return s.ExecHost(ctx, host, sqls, clickhouse.NewQueryOptions().SetRetry(true))
+}
+
+// unreplicate converts Replicated*MergeTree engine into plain *MergeTree engine
+func unreplicate(sql string) string {
+	return replicatedEngineRegexp.ReplaceAllStringFunc(sql, func(engine string) string {
+		match := replicatedEngineRegexp.FindStringSubmatch(engine)
+		if match[2] == "" {
+			return match[1]
+		}
+		return match[1] + "("
+	})
+}
@@ -17,6 +17,7 @@ package schemer
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/MakeNowJust/heredoc"
 
@@ -27,6 +28,22 @@ import (
 const ignoredDBs = `'system', 'information_schema', 'INFORMATION_SCHEMA'`
 const createTableDBEngines = `'Ordinary','Atomic','Memory','Lazy'`
 
+// identifierEscaper escapes special chars of identifiers quoted with backticks
+var identifierEscaper = strings.NewReplacer("\\", "\\\\", "`", "\\`")
+
+// stringEscaper escapes special chars of string literals quoted with single quotes
+var stringEscaper = strings.NewReplacer("\\", "\\\\", "'", "\\'")
+
+// quoteIdentifier quotes name of a database, table or other object to be used in SQL
+func quoteIdentifier(name string) string {
+	return "`" + identifierEscaper.Replace(name) + "`"
+}
+
+// quoteString quotes string to be used as string literal in SQL
+func quoteString(s string) string {
+	return "'" + stringEscaper.Replace(s) + "'"
+}
+
 // sqlDropTable returns set of 'DROP TABLE ...' SQLs
 func (s *ClusterSchemer) sqlDropTable(ctx context.Context, host *api.ChiHost) ([]string, []string, error) {
 	// There isn't a separate query for deleting views. To delete a view, use DROP TABLE
@@ -229,6 +246,58 @@ func (s *ClusterSchemer) sqlCreateFunction(cluster string) string {
 	)
 }
 
+func (s *ClusterSchemer) sqlCreateDatabaseStandby(cluster string) string {
+	var createDatabaseStmt string
+	switch {
+	case s.version.Matches(">= 22.12"):
+		createDatabaseStmt = `'CREATE DATABASE IF NOT EXISTS "' || name || '" Engine = ' || engine_full AS create_db_query`
+	default:
+		createDatabaseStmt = `'CREATE DATABASE IF NOT EXISTS "' || name || '" Engine = ' || engine      AS create_db_query`
+	}
+
+	// Databases with Replicated engine register replica in ZooKeeper, they are created on promotion only
+	return heredoc.Docf(`
+		SELECT
+			DISTINCT name,
+			%s
+		FROM
+			clusterAllReplicas('%s', system.databases) databases
+		WHERE
+			name NOT IN (%s) AND
+			engine != 'Replicated'
+		SETTINGS skip_unavailable_shards = 1
+		`,
+		createDatabaseStmt,
+		cluster,
+		ignoredDBs,
+	)
+}
+
+func (s *ClusterSchemer) sqlCreateTableStandby(cluster string) string {
+	// Tables mentioning Replicated engines register replica in ZooKeeper, they are created on promotion only
+	return heredoc.Docf(`
+		SELECT
+			DISTINCT concat(database, '.', name) AS name,
+			replaceRegexpOne(create_table_query, 'CREATE (TABLE|VIEW|MATERIALIZED VIEW|DICTIONARY|LIVE VIEW|WINDOW VIEW)', 'CREATE \\1 IF NOT EXISTS'),
+			extract(create_table_query, 'UUID \'([^\(\']*)') AS uuid,
+			extract(create_table_query, 'INNER UUID \'([^\(\']*)') AS inner_uuid
+		FROM
+			clusterAllReplicas('%s', system.tables) tables
+		WHERE
+			database NOT IN (%s) AND
+			has((select groupArray(name) from system.databases where engine in (%s)), database) AND
+			create_table_query != '' AND
+			create_table_query NOT LIKE '%%Replicated%%' AND
+			name NOT LIKE '.inner.%%' AND
+			name NOT LIKE '.inner_id.%%'
+		SETTINGS skip_unavailable_shards=1, show_table_uuid_in_table_create_query_if_not_nil=1
+		`,
+		cluster,
+		ignoredDBs,
+		createTableDBEngines,
+	)
+}
+
 func (s *ClusterSchemer) sqlDropReplica(shard int, replica string) []string {
 	return []string{
 		fmt.Sprintf("SYSTEM DROP REPLICA '%s'", replica),
@@ -236,18 +305,155 @@ func (s *ClusterSchemer) sqlDropReplica(shard int, replica string) []string {
 	}
 }
 
+func (s *ClusterSchemer) sqlCreateDatabaseReplicatedEngine(db api.ChiReplicatedDatabase) string {
+	// Shard and replica are expanded by ClickHouse from macros, maintained by the operator
+	return fmt.Sprintf(
+		`CREATE DATABASE IF NOT EXISTS %s ENGINE = Replicated(%s, '{shard}', '{replica}')`,
+		quoteIdentifier(db.Name),
+		quoteString(db.ZookeeperPath),
+	)
+}
+
+func (s *ClusterSchemer) sqlDropDatabaseReplica(db api.ChiReplicatedDatabase, shard, replica string) string {
+	return fmt.Sprintf(
+		`SYSTEM DROP DATABASE REPLICA %s FROM SHARD %s FROM DATABASE %s`,
+		quoteString(replica),
+		quoteString(shard),
+		quoteIdentifier(db.Name),
+	)
+}
+
+func (s *ClusterSchemer) sqlOperation(spec *api.OperationSpec) string {
+	// Partition is validated to consist of literals only, so it is used as is
+	table := quoteIdentifier(spec.Database) + "." + quoteIdentifier(spec.Table)
+	switch spec.Type {
+	case api.OperationTypeDetachPartition:
+		return fmt.Sprintf(`ALTER TABLE %s DETACH PARTITION %s`, table, spec.Partition)
+	case api.OperationTypeAttachPartition:
+		if spec.FromBackup != nil {
+			return fmt.Sprintf(
+				`RESTORE TABLE %s PARTITIONS %s FROM Disk(%s, %s) SETTINGS allow_non_empty_tables = 1`,
+				table,
+				spec.Partition,
+				quoteString(spec.FromBackup.Disk),
+				quoteString(spec.FromBackup.Path),
+			)
+		}
+		if spec.FromTable != "" {
+			from := quoteIdentifier(spec.Database) + "." + quoteIdentifier(spec.FromTable)
+			return fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FROM %s`, table, spec.Partition, from)
+		}
+		return fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s`, table, spec.Partition)
+	case api.OperationTypeMovePartition:
+		if spec.Disk != "" {
+			return fmt.Sprintf(`ALTER TABLE %s MOVE PARTITION %s TO DISK %s`, table, spec.Partition, quoteString(spec.Disk))
+		}
+		return fmt.Sprintf(`ALTER TABLE %s MOVE PARTITION %s TO VOLUME %s`, table, spec.Partition, quoteString(spec.Volume))
+	}
+	return ""
+}
+
 func (s *ClusterSchemer) sqlDropDNSCache() string {
 	return `SYSTEM DROP DNS CACHE`
 }
 
+func (s *ClusterSchemer) sqlReloadConfig() string {
+	return `SYSTEM RELOAD CONFIG`
+}
+
 func (s *ClusterSchemer) sqlActiveQueriesNum() string {
 	return `SELECT count() FROM system.processes`
 }
 
+func (s *ClusterSchemer) sqlPing() string {
+	return `SELECT 1`
+}
+
+// sqlMissingDatabasesNum returns number of databases, existing on other hosts of the cluster, but not attached on the host
+func (s *ClusterSchemer) sqlMissingDatabasesNum(cluster string) string {
+	return heredoc.Docf(`
+		SELECT
+			count()
+		FROM
+			(SELECT DISTINCT name FROM clusterAllReplicas('%s', system.databases) WHERE name NOT IN (%s))
+		WHERE
+			name NOT IN (SELECT name FROM system.databases)
+		SETTINGS skip_unavailable_shards = 1
+		`,
+		cluster,
+		ignoredDBs,
+	)
+}
+
+// sqlLostTablesNum returns number of tables, existing on other hosts of the cluster, in case the host has no tables at all,
+// which is the case of the host started over an empty data volume
+func (s *ClusterSchemer) sqlLostTablesNum(cluster string) string {
+	return heredoc.Docf(`
+		SELECT
+			count()
+		FROM
+			(SELECT DISTINCT database, name FROM clusterAllReplicas('%s', system.tables) WHERE database NOT IN (%s))
+		WHERE
+			(SELECT count() FROM system.tables WHERE database NOT IN (%s)) = 0
+		SETTINGS skip_unavailable_shards = 1
+		`,
+		cluster,
+		ignoredDBs,
+		ignoredDBs,
+	)
+}
+
 func (s *ClusterSchemer) sqlVersion() string {
 	return `SELECT version()`
 }
 
+func (s *ClusterSchemer) sqlMaxReplicationDelay() string {
+	return `SELECT max(absolute_delay) FROM system.replicas`
+}
+
+func (s *ClusterSchemer) sqlReadonlyReplicasNum() string {
+	return `SELECT count() FROM system.replicas WHERE is_readonly`
+}
+
+func (s *ClusterSchemer) sqlDiskUsage() string {
+	return `SELECT toUInt64(max((total_space - free_space) * 100 / total_space)) FROM system.disks WHERE total_space > 0`
+}
+
+func (s *ClusterSchemer) sqlMemoryUsed() string {
+	return `SELECT toUInt64(value) FROM system.asynchronous_metrics WHERE metric = 'MemoryResident'`
+}
+
+func (s *ClusterSchemer) sqlStorageUsed() string {
+	return `SELECT toUInt64(sum(total_space - free_space)) FROM system.disks WHERE total_space > 0`
+}
+
+// sqlStuckMutations returns databases, tables and ids of mutations running longer than specified duration
+func (s *ClusterSchemer) sqlStuckMutations(maxDuration int) string {
+	return heredoc.Docf(`
+		SELECT
+			database,
+			table,
+			mutation_id
+		FROM
+			system.mutations
+		WHERE
+			is_done = 0 AND
+			create_time < now() - INTERVAL %d SECOND
+		ORDER BY
+			create_time
+		`,
+		maxDuration,
+	)
+}
+
+// sqlKillMutation returns 'KILL MUTATION ...' SQL of the mutation
+func (s *ClusterSchemer) sqlKillMutation(database, table, mutationID string) string {
+	return fmt.Sprintf(
+		`KILL MUTATION WHERE database = %s AND table = %s AND mutation_id = %s`,
+		quoteString(database), quoteString(table), quoteString(mutationID),
+	)
+}
+
 func (s *ClusterSchemer) sqlHostInCluster() string {
 	// TODO: Change to select count() query to avoid exception in operator and ClickHouse logs
 	return heredoc.Docf(`
@@ -261,3 +467,121 @@ func (s *ClusterSchemer) sqlHostInCluster() string {
 		chi.AllShardsOneReplicaClusterName,
 	)
 }
+
+// sqlSchemaSnapshot returns set of 'CREATE ...' SQLs of all schema objects of the host.
+// Databases go first, views go last, so objects are able to be created in the order provided.
+func (s *ClusterSchemer) sqlSchemaSnapshot() string {
+	return heredoc.Docf(`
+		SELECT
+			name,
+			create_query
+		FROM
+		(
+			SELECT
+				name,
+				concat('CREATE DATABASE IF NOT EXISTS "', name, '"') AS create_query,
+				1 AS order
+			FROM
+				system.databases
+			WHERE
+				name NOT IN (%s)
+			UNION ALL
+			SELECT
+				concat(database, '.', name) AS name,
+				replaceRegexpOne(create_table_query, 'CREATE (TABLE|VIEW|MATERIALIZED VIEW|DICTIONARY|LIVE VIEW|WINDOW VIEW)', 'CREATE \\1 IF NOT EXISTS') AS create_query,
+				if(engine LIKE '%%View', 3, 2) AS order
+			FROM
+				system.tables
+			WHERE
+				database NOT IN (%s) AND
+				is_temporary = 0 AND
+				create_table_query != ''
+		)
+		ORDER BY order
+		`,
+		ignoredDBs,
+		ignoredDBs,
+	)
+}
+
+// sqlCreateMonitoringSchema returns names and 'CREATE ...' SQLs of monitoring schema objects.
+// Views are created or replaced, so monitoring schema is upgraded along with the operator.
+// Host views are aggregated over system tables of the host, cluster views combine host views of all replicas of the cluster
+func (s *ClusterSchemer) sqlCreateMonitoringSchema(cluster, database string) (names, sqls []string) {
+	// System log tables are created on the first flush, views are not able to refer missing tables
+	sqls = append(sqls, `SYSTEM FLUSH LOGS`)
+	names = append(names, database)
+	sqls = append(sqls, fmt.Sprintf(`CREATE DATABASE IF NOT EXISTS %s`, quoteIdentifier(database)))
+
+	views := []struct {
+		name  string
+		query string
+	}{
+		{
+			name: "query_stats_per_user",
+			query: heredoc.Doc(`
+				SELECT
+					hostName() AS host,
+					toStartOfHour(event_time) AS hour,
+					user,
+					count() AS queries,
+					countIf(type != 'QueryFinish') AS failed_queries,
+					sum(query_duration_ms) AS query_duration_ms,
+					sum(read_rows) AS read_rows,
+					sum(read_bytes) AS read_bytes,
+					sum(written_rows) AS written_rows,
+					sum(written_bytes) AS written_bytes,
+					max(memory_usage) AS max_memory_usage
+				FROM
+					system.query_log
+				WHERE
+					type != 'QueryStart' AND
+					event_date >= today() - 1
+				GROUP BY
+					hour,
+					user
+				`,
+			),
+		},
+		{
+			name: "parts_per_table",
+			query: heredoc.Doc(`
+				SELECT
+					hostName() AS host,
+					database,
+					table,
+					count() AS parts,
+					uniqExact(partition) AS partitions,
+					max(level) AS max_level,
+					sum(rows) AS rows,
+					sum(bytes_on_disk) AS bytes_on_disk,
+					max(modification_time) AS modification_time
+				FROM
+					system.parts
+				WHERE
+					active
+				GROUP BY
+					database,
+					table
+				`,
+			),
+		},
+	}
+
+	for _, view := range views {
+		names = append(names, database+"."+view.name)
+		sqls = append(sqls, fmt.Sprintf(
+			`CREATE OR REPLACE VIEW %s.%s AS %s`,
+			quoteIdentifier(database), quoteIdentifier(view.name), view.query,
+		))
+	}
+	for _, view := range views {
+		names = append(names, database+".cluster_"+view.name)
+		sqls = append(sqls, fmt.Sprintf(
+			`CREATE OR REPLACE VIEW %s.%s AS SELECT * FROM clusterAllReplicas(%s, %s.%s) SETTINGS skip_unavailable_shards = 1`,
+			quoteIdentifier(database), quoteIdentifier("cluster_"+view.name),
+			quoteString(cluster), quoteIdentifier(database), quoteIdentifier(view.name),
+		))
+	}
+	return names, sqls
+}
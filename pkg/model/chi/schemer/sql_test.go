@@ -0,0 +1,100 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+)
+
+func Test_quote(t *testing.T) {
+	require.Equal(t, "`events`", quoteIdentifier("events"))
+	require.Equal(t, "`a\\`b\\\\c`", quoteIdentifier("a`b\\c"))
+	require.Equal(t, "'/path'", quoteString("/path"))
+	require.Equal(t, "'a\\'b\\\\c'", quoteString("a'b\\c"))
+}
+
+func Test_sqlReplicatedDatabase(t *testing.T) {
+	s := &ClusterSchemer{}
+	db := api.ChiReplicatedDatabase{Name: "events", ZookeeperPath: "/clickhouse/{cluster}/events"}
+
+	require.Equal(t,
+		"CREATE DATABASE IF NOT EXISTS `events` ENGINE = Replicated('/clickhouse/{cluster}/events', '{shard}', '{replica}')",
+		s.sqlCreateDatabaseReplicatedEngine(db),
+	)
+	require.Equal(t,
+		"SYSTEM DROP DATABASE REPLICA 'chi-a-main-0-1' FROM SHARD '0' FROM DATABASE `events`",
+		s.sqlDropDatabaseReplica(db, "0", "chi-a-main-0-1"),
+	)
+}
+
+func Test_sqlOperation(t *testing.T) {
+	s := &ClusterSchemer{}
+	spec := &api.OperationSpec{
+		Type:      api.OperationTypeAttachPartition,
+		Database:  "default",
+		Table:     "events`x",
+		Partition: "202401",
+	}
+	require.Equal(t, "ALTER TABLE `default`.`events\\`x` ATTACH PARTITION 202401", s.sqlOperation(spec))
+
+	spec.FromTable = "events_old"
+	require.Equal(t, "ALTER TABLE `default`.`events\\`x` ATTACH PARTITION 202401 FROM `default`.`events_old`", s.sqlOperation(spec))
+
+	spec.FromTable = ""
+	spec.FromBackup = &api.OperationBackup{Disk: "backups", Path: "events'.zip"}
+	require.Equal(t,
+		"RESTORE TABLE `default`.`events\\`x` PARTITIONS 202401 FROM Disk('backups', 'events\\'.zip') SETTINGS allow_non_empty_tables = 1",
+		s.sqlOperation(spec),
+	)
+
+	spec.Type = api.OperationTypeMovePartition
+	spec.Disk = "cold'"
+	require.Equal(t, "ALTER TABLE `default`.`events\\`x` MOVE PARTITION 202401 TO DISK 'cold\\''", s.sqlOperation(spec))
+}
+
+func Test_sqlCreateMonitoringSchema(t *testing.T) {
+	s := &ClusterSchemer{}
+	names, sqls := s.sqlCreateMonitoringSchema("main", "mon")
+	require.Equal(t, []string{
+		"mon",
+		"mon.query_stats_per_user",
+		"mon.parts_per_table",
+		"mon.cluster_query_stats_per_user",
+		"mon.cluster_parts_per_table",
+	}, names)
+	require.Equal(t, "SYSTEM FLUSH LOGS", sqls[0])
+	require.Equal(t, "CREATE DATABASE IF NOT EXISTS `mon`", sqls[1])
+	require.Contains(t, sqls[2], "CREATE OR REPLACE VIEW `mon`.`query_stats_per_user` AS ")
+	require.Equal(t,
+		"CREATE OR REPLACE VIEW `mon`.`cluster_parts_per_table` AS SELECT * FROM clusterAllReplicas('main', `mon`.`parts_per_table`) SETTINGS skip_unavailable_shards = 1",
+		sqls[5],
+	)
+}
+
+func Test_sqlKillMutation(t *testing.T) {
+	s := &ClusterSchemer{}
+	require.Equal(t,
+		"KILL MUTATION WHERE database = 'default' AND table = 'events' AND mutation_id = 'mutation_3.txt'",
+		s.sqlKillMutation("default", "events", "mutation_3.txt"),
+	)
+	require.Equal(t,
+		"KILL MUTATION WHERE database = 'a\\'b' AND table = 'x\\' OR 1 = 1 --' AND mutation_id = '0000000001'",
+		s.sqlKillMutation("a'b", "x' OR 1 = 1 --", "0000000001"),
+	)
+}
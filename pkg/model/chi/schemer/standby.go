@@ -0,0 +1,70 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schemer
+
+import (
+	"context"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/model/chi"
+	"github.com/altinity/clickhouse-operator/pkg/model/clickhouse"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+func (s *ClusterSchemer) getStandbyObjectsSQLs(ctx context.Context, host *api.ChiHost) ([]string, []string, error) {
+	if util.IsContextDone(ctx) {
+		log.V(2).Info("ctx is done")
+		return nil, nil, nil
+	}
+
+	databaseNames, createDatabaseSQLs := debugCreateSQLs(
+		s.QueryUnzip2Columns(
+			ctx,
+			chi.CreateFQDNs(host, api.ClickHouseInstallation{}, false),
+			s.sqlCreateDatabaseStandby(host.Address.ClusterName),
+		),
+	)
+	tableNames, createTableSQLs := debugCreateSQLs(
+		s.QueryUnzipAndApplyUUIDs(
+			ctx,
+			chi.CreateFQDNs(host, api.ClickHouseInstallation{}, false),
+			s.sqlCreateTableStandby(host.Address.ClusterName),
+		),
+	)
+	functionNames, createFunctionSQLs := debugCreateSQLs(
+		s.QueryUnzip2Columns(
+			ctx,
+			chi.CreateFQDNs(host, api.ClickHouseInstallation{}, false),
+			s.sqlCreateFunction(host.Address.ClusterName),
+		),
+	)
+	return util.ConcatSlices([][]string{databaseNames, tableNames, functionNames}),
+		util.ConcatSlices([][]string{createDatabaseSQLs, createTableSQLs, createFunctionSQLs}),
+		nil
+}
+
+// HostCreateStandbyTables pre-creates schema on a standby host.
+// Objects registering replica in ZooKeeper are skipped, since standby host is not a replica of any shard yet,
+// they are created with macros of the host the standby host is promoted into
+func (s *ClusterSchemer) HostCreateStandbyTables(ctx context.Context, host *api.ChiHost) error {
+	names, sqls, _ := s.getStandbyObjectsSQLs(ctx, host)
+	if len(sqls) == 0 {
+		return nil
+	}
+	log.V(1).M(host).F().Info("Creating standby objects at %s: %v", host.Address.HostName, names)
+	log.V(2).M(host).F().Info("\n%v", sqls)
+	return s.ExecHost(ctx, host, sqls, clickhouse.NewQueryOptions().SetRetry(true))
+}
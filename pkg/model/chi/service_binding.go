@@ -0,0 +1,78 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"strconv"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// Entries of Service Binding Secret, as specified by servicebinding.io
+const (
+	BindingSecretTypeKey     = "type"
+	BindingSecretProviderKey = "provider"
+	BindingSecretHostKey     = "host"
+	BindingSecretPortKey     = "port"
+	BindingSecretHTTPPortKey = "http-port"
+	BindingSecretUsernameKey = "username"
+	BindingSecretPasswordKey = "password"
+	BindingSecretDatabaseKey = "database"
+)
+
+const (
+	bindingSecretPasswordMinLength = 20
+	bindingSecretPasswordMaxLength = 24
+	bindingSecretDatabase          = "default"
+)
+
+// CreateSecretBinding creates Service Binding Secret of the user.
+// Password is generated, thus it is expected to be kept from the existing Secret on reconcile
+func (c *Creator) CreateSecretBinding(username string) *core.Secret {
+	connection := CreateConnectionStatus(c.chi)
+	port := connection.NativePort
+	if port == 0 {
+		port = connection.NativeSecurePort
+	}
+	httpPort := connection.HTTPPort
+	if httpPort == 0 {
+		httpPort = connection.HTTPSPort
+	}
+
+	return &core.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Name:            CreateBindingSecretName(c.chi, username),
+			Namespace:       c.chi.Namespace,
+			Labels:          macro(c.chi).Map(c.labels.getConnection()),
+			Annotations:     macro(c.chi).Map(c.annotations.getCHIScope()),
+			OwnerReferences: getOwnerReferences(c.chi),
+		},
+		StringData: map[string]string{
+			BindingSecretTypeKey:     api.ServiceBindingType,
+			BindingSecretProviderKey: api.ServiceBindingProvider,
+			BindingSecretHostKey:     connection.Host,
+			BindingSecretPortKey:     strconv.Itoa(int(port)),
+			BindingSecretHTTPPortKey: strconv.Itoa(int(httpPort)),
+			BindingSecretUsernameKey: username,
+			BindingSecretPasswordKey: util.RandStringRange(bindingSecretPasswordMinLength, bindingSecretPasswordMaxLength),
+			BindingSecretDatabaseKey: bindingSecretDatabase,
+		},
+		Type: core.SecretType("servicebinding.io/" + api.ServiceBindingType),
+	}
+}
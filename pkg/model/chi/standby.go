@@ -0,0 +1,112 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"strconv"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+)
+
+const (
+	// StandbyShardName is a name of the pseudo-shard standby hosts of a cluster are addressed in.
+	// Standby hosts are not members of any shard of the cluster
+	StandbyShardName = "standby"
+	// standbyHostNamePrefix is a prefix of names of standby hosts
+	standbyHostNamePrefix = "standby-"
+)
+
+// CreateStandbyHosts creates standby hosts of the normalized cluster.
+// Indexes of standby hosts already promoted into hosts of the cluster are skipped,
+// so the pool is replenished with new standby hosts
+func CreateStandbyHosts(cluster *api.Cluster) (hosts []*api.ChiHost) {
+	promoted := make(map[int]bool)
+	for _, promotion := range cluster.CHI.GetStatus().GetStandbyPromotions() {
+		if promotion.Cluster == cluster.Name {
+			promoted[promotion.Standby] = true
+		}
+	}
+	for index := 0; len(hosts) < cluster.Standby.GetReplicas(); index++ {
+		if promoted[index] {
+			continue
+		}
+		if host := CreateStandbyHost(cluster, index); host != nil {
+			hosts = append(hosts, host)
+		} else {
+			break
+		}
+	}
+	return hosts
+}
+
+// CreateStandbyHost creates standby host of the normalized cluster by the index.
+// Standby host is made out of the first host of the cluster: it has the same templates, settings and files,
+// while being addressed in the standby pseudo-shard
+func CreateStandbyHost(cluster *api.Cluster, index int) *api.ChiHost {
+	if cluster == nil {
+		return nil
+	}
+	template := cluster.FirstHost()
+	if template == nil {
+		return nil
+	}
+
+	host := &api.ChiHost{
+		Name:                standbyHostNamePrefix + strconv.Itoa(index),
+		Insecure:            template.Insecure,
+		Secure:              template.Secure,
+		TCPPort:             template.TCPPort,
+		TLSPort:             template.TLSPort,
+		HTTPPort:            template.HTTPPort,
+		HTTPSPort:           template.HTTPSPort,
+		InterserverHTTPPort: template.InterserverHTTPPort,
+		Settings:            template.Settings.DeepCopy(),
+		Files:               template.Files.DeepCopy(),
+		Templates:           template.Templates.DeepCopy(),
+		Metadata:            template.Metadata.DeepCopy(),
+		Address:             template.Address,
+		Config:              template.Config,
+		CHI:                 template.CHI,
+	}
+	host.Address.ShardName = StandbyShardName
+	host.Address.ShardIndex = len(cluster.Layout.Shards)
+	host.Address.ShardScopeIndex = index
+	host.Address.ReplicaName = strconv.Itoa(index)
+	host.Address.ReplicaIndex = index
+	host.Address.HostName = host.Name
+	host.Address.StatefulSet = CreateStatefulSetName(host)
+	host.Address.FQDN = CreateFQDN(host)
+	return host
+}
+
+// IsStandbyHost checks whether the host is a standby host
+func IsStandbyHost(host *api.ChiHost) bool {
+	return host.Address.ShardName == StandbyShardName
+}
+
+// getPromotedStandbyHost gets standby host promoted into the host, if any.
+// Promoted standby host serves the host from then on: StatefulSet of the host is named after the standby host,
+// so pod and volumes of the standby host are reused, while labels, macros, Service and ConfigMap are of the host
+func getPromotedStandbyHost(host *api.ChiHost) (*api.ChiHost, bool) {
+	if IsStandbyHost(host) {
+		return nil, false
+	}
+	promotion, ok := host.GetCHI().GetStatus().FindStandbyPromotion(host.Address.ClusterName, host.GetName())
+	if !ok {
+		return nil, false
+	}
+	standby := CreateStandbyHost(host.GetCluster(), promotion.Standby)
+	return standby, standby != nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"fmt"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+)
+
+// Set of kubernetes annotations users can put on child objects in order to exclude them from management by the operator
+const (
+	// AnnotationUnmanaged specifies the whole object is not managed by the operator anymore
+	AnnotationUnmanaged = clickhouse_altinity_com.APIGroupName + "/" + "unmanaged"
+	// AnnotationUnmanagedFields specifies comma-separated list of object's fields not managed by the operator anymore
+	AnnotationUnmanagedFields = clickhouse_altinity_com.APIGroupName + "/" + "unmanaged-fields"
+)
+
+// Fields of child objects which can be excluded from management
+const (
+	UnmanagedFieldLabels                = "metadata.labels"
+	UnmanagedFieldAnnotations           = "metadata.annotations"
+	UnmanagedFieldData                  = "data"
+	UnmanagedFieldServiceType           = "spec.type"
+	UnmanagedFieldServicePorts          = "spec.ports"
+	UnmanagedFieldServiceSelector       = "spec.selector"
+	UnmanagedFieldServiceTrafficPolicy  = "spec.externalTrafficPolicy"
+	UnmanagedFieldServiceSourceRanges   = "spec.loadBalancerSourceRanges"
+	UnmanagedFieldServiceLoadBalancerIP = "spec.loadBalancerIP"
+)
+
+// IsUnmanaged checks whether the whole object is excluded from management by the operator
+func IsUnmanaged(objMeta *meta.ObjectMeta) bool {
+	if objMeta == nil {
+		return false
+	}
+	value, ok := objMeta.Annotations[AnnotationUnmanaged]
+	if !ok {
+		return false
+	}
+	return (*api.StringBool)(&value).IsTrue()
+}
+
+// GetUnmanagedFields gets list of object's fields excluded from management by the operator
+func GetUnmanagedFields(objMeta *meta.ObjectMeta) (fields []string) {
+	if objMeta == nil {
+		return nil
+	}
+	for _, field := range strings.Split(objMeta.Annotations[AnnotationUnmanagedFields], ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return util.Unique(fields)
+}
+
+// DescribeUnmanaged builds human-readable description of the object excluded from management, to be reported in status
+func DescribeUnmanaged(kind string, objMeta *meta.ObjectMeta, fields []string) string {
+	description := fmt.Sprintf("%s %s/%s", kind, objMeta.Namespace, objMeta.Name)
+	if len(fields) > 0 {
+		description += ": " + strings.Join(fields, ",")
+	}
+	return description
+}
+
+// preserveUnmanagedMeta preserves unmanaged meta fields of the current object in the target object
+func preserveUnmanagedMeta(target, cur *meta.ObjectMeta, field string) bool {
+	switch field {
+	case UnmanagedFieldLabels:
+		target.Labels = util.MergeStringMapsOverwrite(nil, cur.Labels)
+	case UnmanagedFieldAnnotations:
+		target.Annotations = util.MergeStringMapsOverwrite(nil, cur.Annotations)
+	default:
+		return false
+	}
+	return true
+}
+
+// preserveUnmanagedAnnotations ensures unmanaged annotations survive object update
+func preserveUnmanagedAnnotations(target, cur *meta.ObjectMeta) {
+	for _, annotation := range []string{AnnotationUnmanaged, AnnotationUnmanagedFields} {
+		if value, ok := cur.Annotations[annotation]; ok {
+			if target.Annotations == nil {
+				target.Annotations = make(map[string]string)
+			}
+			target.Annotations[annotation] = value
+		}
+	}
+}
+
+// PreserveUnmanagedConfigMapFields preserves fields of the current ConfigMap, excluded from management, in the target ConfigMap.
+// Returns list of preserved fields.
+func PreserveUnmanagedConfigMapFields(target, cur *core.ConfigMap) (preserved []string) {
+	if (target == nil) || (cur == nil) {
+		return nil
+	}
+	for _, field := range GetUnmanagedFields(&cur.ObjectMeta) {
+		switch {
+		case preserveUnmanagedMeta(&target.ObjectMeta, &cur.ObjectMeta, field):
+		case field == UnmanagedFieldData:
+			target.Data = util.MergeStringMapsOverwrite(nil, cur.Data)
+		default:
+			continue
+		}
+		preserved = append(preserved, field)
+	}
+	preserveUnmanagedAnnotations(&target.ObjectMeta, &cur.ObjectMeta)
+	return preserved
+}
+
+// PreserveUnmanagedServiceFields preserves fields of the current Service, excluded from management, in the target Service.
+// Returns list of preserved fields.
+func PreserveUnmanagedServiceFields(target, cur *core.Service) (preserved []string) {
+	if (target == nil) || (cur == nil) {
+		return nil
+	}
+	for _, field := range GetUnmanagedFields(&cur.ObjectMeta) {
+		switch {
+		case preserveUnmanagedMeta(&target.ObjectMeta, &cur.ObjectMeta, field):
+		case field == UnmanagedFieldServiceType:
+			target.Spec.Type = cur.Spec.Type
+		case field == UnmanagedFieldServicePorts:
+			target.Spec.Ports = append([]core.ServicePort(nil), cur.Spec.Ports...)
+		case field == UnmanagedFieldServiceSelector:
+			target.Spec.Selector = util.MergeStringMapsOverwrite(nil, cur.Spec.Selector)
+		case field == UnmanagedFieldServiceTrafficPolicy:
+			target.Spec.ExternalTrafficPolicy = cur.Spec.ExternalTrafficPolicy
+		case field == UnmanagedFieldServiceSourceRanges:
+			target.Spec.LoadBalancerSourceRanges = append([]string(nil), cur.Spec.LoadBalancerSourceRanges...)
+		case field == UnmanagedFieldServiceLoadBalancerIP:
+			target.Spec.LoadBalancerIP = cur.Spec.LoadBalancerIP
+		default:
+			continue
+		}
+		preserved = append(preserved, field)
+	}
+	preserveUnmanagedAnnotations(&target.ObjectMeta, &cur.ObjectMeta)
+	return preserved
+}
@@ -0,0 +1,380 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	k8sLabels "k8s.io/apimachinery/pkg/labels"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/apis/deployment"
+	"github.com/altinity/clickhouse-operator/pkg/util"
+	"github.com/altinity/clickhouse-operator/pkg/xml"
+)
+
+// minKeeperFaultDomains specifies number of fault domains keeper ensemble has to be spread over
+// in order to keep quorum on loss of any one of them
+const minKeeperFaultDomains = 3
+
+// databaseNameRegexp specifies names of databases the operator manages in ClickHouse
+var databaseNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// zookeeperPathRegexp specifies absolute paths in ZooKeeper, which may contain macros
+var zookeeperPathRegexp = regexp.MustCompile(`^/[a-zA-Z0-9_{}./-]*$`)
+
+// IsValidDatabaseName checks whether the name can be used as name of a database managed by the operator
+func IsValidDatabaseName(name string) bool {
+	return databaseNameRegexp.MatchString(name)
+}
+
+// IsValidZookeeperPath checks whether the path can be used as path in ZooKeeper of an object managed by the operator
+func IsValidZookeeperPath(path string) bool {
+	return zookeeperPathRegexp.MatchString(path)
+}
+
+// ValidateLayout checks layout of the normalized CHI against anti-patterns.
+// Nodes are candidates to schedule hosts on, used to check whether required anti-affinity is satisfiable.
+// Returns list of violations found
+func ValidateLayout(chi *api.ClickHouseInstallation, nodes []core.Node) (violations []string) {
+	chi.WalkClusters(func(cluster *api.Cluster) error {
+		if !cluster.Zookeeper.IsEmpty() && (len(cluster.Zookeeper.Nodes)%2 == 0) {
+			violations = append(violations, fmt.Sprintf(
+				"cluster %s uses keeper ensemble of %d nodes, odd number of nodes is required to tolerate failures",
+				cluster.Name, len(cluster.Zookeeper.Nodes)))
+		}
+
+		cluster.WalkShards(func(_ int, shard *api.ChiShard) error {
+			if (cluster.Standby.GetReplicas() > 0) && (shard.Name == StandbyShardName) {
+				violations = append(violations, fmt.Sprintf(
+					"shard %s of cluster %s is named after standby hosts, rename the shard in order to have standby hosts",
+					shard.Name, cluster.Name))
+			}
+			replicas := shard.HostsCount()
+			if chi.Spec.Validation.IsProduction() && (replicas < 2) {
+				violations = append(violations, fmt.Sprintf(
+					"shard %s of cluster %s has single replica, production profile requires replicated shards",
+					shard.Name, cluster.Name))
+			}
+			if nodes == nil {
+				return nil
+			}
+			shard.WalkHosts(func(host *api.ChiHost) error {
+				if violation := validateHostAntiAffinity(host, replicas, nodes); violation != "" {
+					violations = append(violations, violation)
+				}
+				return nil
+			})
+			return nil
+		})
+		return nil
+	})
+	return violations
+}
+
+// FindNodePortClashes finds ports listened by more than one host on the network of a node,
+// which happens with hostNetwork or hostPort in case ports of hosts are not distributed by index.
+// Hosts clashing over a port can not share a node, scheduler keeps them on different nodes,
+// so clash is an issue in case there are not enough nodes only.
+// Returns list of clashes found
+func FindNodePortClashes(chi *api.ClickHouseInstallation) (clashes []string) {
+	generator := NewStatefulSetGenerator(chi)
+	// Port -> hosts
+	ports := make(map[int32][]string)
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		seen := make(map[int32]bool)
+		for _, port := range getNodeNetworkPorts(generator, host) {
+			if api.IsPortUnassigned(port) || seen[port] {
+				continue
+			}
+			seen[port] = true
+			ports[port] = append(ports[port], host.Address.StatefulSet)
+		}
+		return nil
+	})
+
+	var sorted []int32
+	for port := range ports {
+		sorted = append(sorted, port)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, port := range sorted {
+		if hosts := ports[port]; len(hosts) > 1 {
+			clashes = append(clashes, fmt.Sprintf(
+				"port %d of node network is used by hosts %s, these hosts are not able to share a node",
+				port, strings.Join(hosts, ", ")))
+		}
+	}
+	return clashes
+}
+
+// getNodeNetworkPorts gets ports the host listens on the network of its node.
+// With hostNetwork ClickHouse listens on the node right away, otherwise ports exposed via hostPort are listened,
+// as they are in the StatefulSet of the host
+func getNodeNetworkPorts(generator StatefulSetGenerator, host *api.ChiHost) (ports []int32) {
+	template, ok := host.GetPodTemplate()
+	if !ok {
+		return nil
+	}
+	if template.Spec.HostNetwork {
+		return []int32{host.TCPPort, host.TLSPort, host.HTTPPort, host.HTTPSPort, host.InterserverHTTPPort}
+	}
+	if !podTemplateHasHostPorts(template) {
+		return nil
+	}
+	statefulSet := generator.CreateStatefulSet(host, false)
+	for _, container := range statefulSet.Spec.Template.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				ports = append(ports, port.HostPort)
+			}
+		}
+	}
+	return ports
+}
+
+// FindZookeeperMismatches finds shards of the normalized CHI, replicas of which use different ZooKeeper ensembles,
+// which happens in case replica-level zookeeper overrides do not agree with each other.
+// Replicas of a shard replicate via the same coordination domain only, so such a layout is broken.
+// Returns list of mismatches found
+func FindZookeeperMismatches(chi *api.ClickHouseInstallation) (mismatches []string) {
+	chi.WalkShards(func(shard *api.ChiShard) error {
+		var first *api.ChiHost
+		shard.WalkHosts(func(host *api.ChiHost) error {
+			if first == nil {
+				first = host
+				return nil
+			}
+			if !first.GetZookeeper().Equals(host.GetZookeeper()) {
+				mismatches = append(mismatches, fmt.Sprintf(
+					"replicas %s and %s of shard %s of cluster %s use different keeper ensembles",
+					first.Address.ReplicaName, host.Address.ReplicaName, shard.Address.ShardName, shard.Address.ClusterName))
+			}
+			return nil
+		})
+		return nil
+	})
+	return mismatches
+}
+
+// FindRemoteClusterClashes finds custom clusters of the normalized CHI, names of which clash with each other
+// or with clusters generated by the operator, since remote_servers can not have two clusters of the same name.
+// Returns list of clashes found
+func FindRemoteClusterClashes(chi *api.ClickHouseInstallation) (clashes []string) {
+	generated := map[string]bool{
+		OneShardAllReplicasClusterName: true,
+		AllShardsOneReplicaClusterName: true,
+	}
+	chi.WalkClusters(func(cluster *api.Cluster) error {
+		generated[cluster.Name] = true
+		if chi.Spec.CrossRegion.HasPeers() {
+			generated[cluster.Name+CrossRegionClusterNameSuffix] = true
+		}
+		return nil
+	})
+
+	seen := make(map[string]bool)
+	for _, cluster := range chi.Spec.Configuration.RemoteClusters {
+		switch {
+		case generated[cluster.Name]:
+			clashes = append(clashes, fmt.Sprintf("custom cluster %s clashes with generated cluster", cluster.Name))
+		case seen[cluster.Name]:
+			clashes = append(clashes, fmt.Sprintf("custom cluster %s is specified more than once", cluster.Name))
+		}
+		seen[cluster.Name] = true
+	}
+	return clashes
+}
+
+// FindFaultDomainRecommendations finds placement issues of hosts of the normalized CHI with respect to fault domains,
+// such as replicas of a shard sharing fault domain or too few fault domains for keeper ensemble to be spread over.
+// Fault domain is known for hosts having {fault_domain} macro fetched only, the rest hosts are skipped.
+// Returns list of recommendations
+func FindFaultDomainRecommendations(chi *api.ClickHouseInstallation) (recommendations []string) {
+	// Fault domain -> host
+	domains := make(map[string]string)
+	chi.WalkShards(func(shard *api.ChiShard) error {
+		// Fault domain -> replica
+		replicas := make(map[string]string)
+		shard.WalkHosts(func(host *api.ChiHost) error {
+			domain, ok := host.Macros[api.FaultDomainMacroName]
+			if !ok {
+				return nil
+			}
+			domains[domain] = host.GetName()
+			if replica, found := replicas[domain]; found {
+				recommendations = append(recommendations, fmt.Sprintf(
+					"replicas %s and %s of shard %s of cluster %s share fault domain %s, place them into different fault domains",
+					replica, host.Address.ReplicaName, shard.Address.ShardName, shard.Address.ClusterName, domain))
+			} else {
+				replicas[domain] = host.Address.ReplicaName
+			}
+			return nil
+		})
+		return nil
+	})
+
+	usesKeeper := false
+	chi.WalkClusters(func(cluster *api.Cluster) error {
+		usesKeeper = usesKeeper || !cluster.Zookeeper.IsEmpty()
+		return nil
+	})
+	if usesKeeper && (len(domains) > 0) && (len(domains) < minKeeperFaultDomains) {
+		recommendations = append(recommendations, fmt.Sprintf(
+			"hosts span %d fault domains (%s), keeper ensemble has to be spread over at least %d fault domains to keep quorum on loss of one",
+			len(domains), strings.Join(util.MapSortedKeys(domains), ", "), minKeeperFaultDomains))
+	}
+	return recommendations
+}
+
+// validateHostAntiAffinity checks whether required anti-affinity of the host is satisfiable with nodes available
+func validateHostAntiAffinity(host *api.ChiHost, replicas int, nodes []core.Node) string {
+	template, ok := host.GetPodTemplate()
+	if !ok || (host.Address.ReplicaIndex != 0) {
+		// Check first replica of the shard only, as the rest of replicas would repeat the violation
+		return ""
+	}
+
+	for _, distribution := range template.PodDistribution {
+		var required int
+		switch distribution.Type {
+		case deployment.PodDistributionShardAntiAffinity:
+			// Replicas of the shard have to be on different nodes
+			required = replicas
+		case deployment.PodDistributionClickHouseAntiAffinity:
+			// Each host has to be on its own node
+			required = host.GetCHI().HostsCount()
+		default:
+			continue
+		}
+		if available := countSchedulableNodes(nodes, template.Spec.NodeSelector); required > available {
+			return fmt.Sprintf(
+				"shard %s of cluster %s requires %s over %d nodes, but only %d nodes are available",
+				host.Address.ShardName, host.Address.ClusterName, distribution.Type, required, available)
+		}
+	}
+	return ""
+}
+
+// countSchedulableNodes counts nodes able to schedule pods with specified node selector on
+func countSchedulableNodes(nodes []core.Node, nodeSelector map[string]string) int {
+	selector := k8sLabels.SelectorFromSet(nodeSelector)
+	count := 0
+	for i := range nodes {
+		node := &nodes[i]
+		if !node.Spec.Unschedulable && selector.Matches(k8sLabels.Set(node.Labels)) {
+			count++
+		}
+	}
+	return count
+}
+
+// FindUnknownGuardUsers finds users of per-user guards of the normalized CHI, which are not specified in users.
+// Guards of unknown users are not generated into profiles.
+// Returns list of unknown users
+func FindUnknownGuardUsers(chi *api.ClickHouseInstallation) (unknown []string) {
+	conf := chi.Spec.Configuration
+	if conf == nil {
+		return nil
+	}
+	for username := range conf.Guards.GetUsers() {
+		if !api.NewSettingsUser(conf.Users, username).Has("profile") {
+			unknown = append(unknown, username)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// FindEscapedValues finds settings of the normalized CHI, values or attributes of which are XML-escaped already.
+// Values are escaped by the operator, so escaped values reach ClickHouse with entities, such as &amp;, literally.
+// Returns list of settings having escaped values
+func FindEscapedValues(chi *api.ClickHouseInstallation) (escaped []string) {
+	find := func(section string, settings *api.Settings) {
+		names := settings.Names()
+		sort.Strings(names)
+		for _, name := range names {
+			if isSettingEscaped(settings.Get(name)) {
+				escaped = append(escaped, fmt.Sprintf("%s %q", section, name))
+			}
+		}
+	}
+	if conf := chi.Spec.Configuration; conf != nil {
+		find("settings", conf.Settings)
+		find("users", conf.Users)
+		find("profiles", conf.Profiles)
+		find("quotas", conf.Quotas)
+	}
+	chi.WalkClusters(func(cluster *api.Cluster) error {
+		find(fmt.Sprintf("cluster %s settings", cluster.Name), cluster.Settings)
+		return nil
+	})
+	return escaped
+}
+
+// isSettingEscaped checks whether value or attributes of the setting are XML-escaped already
+func isSettingEscaped(setting *api.Setting) (escaped bool) {
+	var values []string
+	switch {
+	case setting.IsScalar():
+		values = append(values, setting.ScalarString())
+	case setting.IsVector():
+		values = append(values, setting.VectorOfStrings()...)
+	}
+	setting.WalkAttributes(func(_, value string) {
+		values = append(values, value)
+	})
+	for _, value := range values {
+		if xml.HasEscapedEntities(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindMissingTemplates finds templates referenced by hosts of the normalized CHI, but not specified in the CHI.
+// Hosts referencing unknown templates silently fall back to defaults.
+// Returns list of missing templates along with hosts referencing them
+func FindMissingTemplates(chi *api.ClickHouseInstallation) (missing []string) {
+	chi.WalkHosts(func(host *api.ChiHost) error {
+		report := func(kind, name string) {
+			missing = append(missing, fmt.Sprintf(
+				"%s %s referenced by replica %s of shard %s of cluster %s is not found",
+				kind, name, host.Address.ReplicaName, host.Address.ShardName, host.Address.ClusterName))
+		}
+		if _, ok := host.GetPodTemplate(); !ok && host.Templates.HasPodTemplate() {
+			report("podTemplate", host.Templates.GetPodTemplate())
+		}
+		if name := host.Templates.GetDataVolumeClaimTemplate(); host.Templates.HasDataVolumeClaimTemplate() {
+			if _, ok := chi.GetVolumeClaimTemplate(name); !ok {
+				report("dataVolumeClaimTemplate", name)
+			}
+		}
+		if name := host.Templates.GetLogVolumeClaimTemplate(); host.Templates.HasLogVolumeClaimTemplate() {
+			if _, ok := chi.GetVolumeClaimTemplate(name); !ok {
+				report("logVolumeClaimTemplate", name)
+			}
+		}
+		if _, ok := host.GetServiceTemplate(); !ok && host.Templates.HasReplicaServiceTemplate() {
+			report("replicaServiceTemplate", host.Templates.GetReplicaServiceTemplate())
+		}
+		return nil
+	})
+	return missing
+}
@@ -0,0 +1,246 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chi_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/kubernetes-sigs/yaml"
+	"github.com/stretchr/testify/require"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// newTestCHI unmarshals and normalizes the CHI
+func newTestCHI(t *testing.T, manifest string) *api.ClickHouseInstallation {
+	require.NoError(t, chop.NewOffline(""))
+	chi := &api.ClickHouseInstallation{}
+	require.NoError(t, yaml.Unmarshal([]byte(manifest), chi))
+	return mustNormalize(t, chi)
+}
+
+func Test_FindUnknownGuardUsers(t *testing.T) {
+	chi := newTestCHI(t, `
+metadata:
+  name: guards
+spec:
+  configuration:
+    users:
+      alice/password: secret
+    guards:
+      users:
+        alice:
+          maxSessionsForUser: 2
+        default:
+          maxSessionsForUser: 10
+        mallory:
+          maxSessionsForUser: 1
+        bob:
+          maxConcurrentQueriesForUser: 1
+`)
+
+	require.Equal(t, []string{"bob", "mallory"}, model.FindUnknownGuardUsers(chi))
+
+	// Guards of known users are applied via dedicated profiles
+	users := chi.Spec.Configuration.Users
+	require.Equal(t, "guards_alice", users.Get("alice/profile").String())
+	require.Equal(t, "guards_default", users.Get("default/profile").String())
+	require.Equal(t, "2", chi.Spec.Configuration.Profiles.Get("guards_alice/max_sessions_for_user").String())
+	require.Nil(t, chi.Spec.Configuration.Profiles.Get("guards_mallory/max_sessions_for_user"))
+}
+
+func Test_FindNodePortClashes(t *testing.T) {
+	// Ports of hosts with hostNetwork and not distributed ports clash
+	hostNetwork := newTestCHI(t, `
+metadata:
+  name: ports
+spec:
+  defaults:
+    templates:
+      podTemplate: pod
+      hostTemplate: fixed
+  configuration:
+    clusters:
+      - name: main
+        layout:
+          replicasCount: 2
+  templates:
+    hostTemplates:
+      - name: fixed
+    podTemplates:
+      - name: pod
+        spec:
+          hostNetwork: true
+`)
+	require.Equal(t, []string{
+		"port 8123 of node network is used by hosts chi-ports-main-0-0, chi-ports-main-0-1, these hosts are not able to share a node",
+		"port 9000 of node network is used by hosts chi-ports-main-0-0, chi-ports-main-0-1, these hosts are not able to share a node",
+		"port 9009 of node network is used by hosts chi-ports-main-0-0, chi-ports-main-0-1, these hosts are not able to share a node",
+	}, model.FindNodePortClashes(hostNetwork))
+	// Node port clash is reported, but it is not a layout violation, since scheduler keeps clashing hosts on different nodes
+	require.Empty(t, model.ValidateLayout(hostNetwork, nil))
+
+	// Ports of hosts are distributed with hostNetwork by default
+	require.Empty(t, model.FindNodePortClashes(newTestCHI(t, fmt.Sprintf(hostPortsTestManifest, "", true))))
+
+	// Named ports of ClickHouse are exposed on the node in case host ports are distributed only
+	require.Empty(t, model.FindNodePortClashes(newTestCHI(t, fmt.Sprintf(hostPortsTestManifest, "", false))))
+	require.Empty(t, model.FindNodePortClashes(newTestCHI(t, fmt.Sprintf(hostPortsTestManifest, "yes", false))))
+
+	// Ports unknown to the operator are exposed as specified
+	custom := newTestCHI(t, `
+metadata:
+  name: ports
+spec:
+  defaults:
+    distributeHostPorts: "yes"
+    templates:
+      podTemplate: pod
+  configuration:
+    clusters:
+      - name: main
+        layout:
+          replicasCount: 2
+  templates:
+    podTemplates:
+      - name: pod
+        spec:
+          containers:
+            - name: clickhouse
+              ports:
+                - name: metrics
+                  containerPort: 9363
+                  hostPort: 9363
+`)
+	require.Equal(t, []string{
+		"port 9363 of node network is used by hosts chi-ports-main-0-0, chi-ports-main-0-1, these hosts are not able to share a node",
+	}, model.FindNodePortClashes(custom))
+}
+
+const layoutTestManifest = `
+metadata:
+  name: layout
+spec:
+  validation:
+    profile: %s
+  defaults:
+    templates:
+      podTemplate: pod
+  configuration:
+    zookeeper:
+      nodes:
+%s
+    clusters:
+      - name: main
+        layout:
+          shardsCount: 2
+          replicasCount: %d
+  templates:
+    podTemplates:
+      - name: pod
+        podDistribution:
+          - type: ShardAntiAffinity
+        spec:
+          nodeSelector:
+            pool: clickhouse
+`
+
+// newLayoutTestNodes creates nodes, only schedulable nodes of the pool are able to run hosts
+func newLayoutTestNodes(pool, other, unschedulable int) (nodes []core.Node) {
+	add := func(count int, labels map[string]string, unschedulable bool) {
+		for i := 0; i < count; i++ {
+			nodes = append(nodes, core.Node{
+				ObjectMeta: meta.ObjectMeta{Name: fmt.Sprintf("node-%d", len(nodes)), Labels: labels},
+				Spec:       core.NodeSpec{Unschedulable: unschedulable},
+			})
+		}
+	}
+	add(pool, map[string]string{"pool": "clickhouse"}, false)
+	add(other, map[string]string{"pool": "other"}, false)
+	add(unschedulable, map[string]string{"pool": "clickhouse"}, true)
+	return nodes
+}
+
+func Test_ValidateLayout(t *testing.T) {
+	keeper3 := "        - host: keeper-0\n        - host: keeper-1\n        - host: keeper-2"
+	keeper2 := "        - host: keeper-0\n        - host: keeper-1"
+
+	tests := []struct {
+		name       string
+		profile    string
+		keeper     string
+		replicas   int
+		nodes      []core.Node
+		violations []string
+	}{
+		{
+			name:     "valid",
+			profile:  api.ValidationProfileProduction,
+			keeper:   keeper3,
+			replicas: 2,
+			nodes:    newLayoutTestNodes(2, 0, 0),
+		},
+		{
+			name:     "even keeper ensemble",
+			keeper:   keeper2,
+			replicas: 2,
+			violations: []string{
+				"cluster main uses keeper ensemble of 2 nodes, odd number of nodes is required to tolerate failures",
+			},
+		},
+		{
+			name:     "single replica in development",
+			keeper:   keeper3,
+			replicas: 1,
+		},
+		{
+			name:     "single replica in production",
+			profile:  api.ValidationProfileProduction,
+			keeper:   keeper3,
+			replicas: 1,
+			violations: []string{
+				"shard 0 of cluster main has single replica, production profile requires replicated shards",
+				"shard 1 of cluster main has single replica, production profile requires replicated shards",
+			},
+		},
+		{
+			name:     "anti-affinity over nodes not matching selector or unschedulable",
+			keeper:   keeper3,
+			replicas: 3,
+			nodes:    newLayoutTestNodes(2, 2, 1),
+			violations: []string{
+				"shard 0 of cluster main requires ShardAntiAffinity over 3 nodes, but only 2 nodes are available",
+				"shard 1 of cluster main requires ShardAntiAffinity over 3 nodes, but only 2 nodes are available",
+			},
+		},
+		{
+			name:     "anti-affinity is not checked without nodes",
+			keeper:   keeper3,
+			replicas: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chi := newTestCHI(t, fmt.Sprintf(layoutTestManifest, tt.profile, tt.keeper, tt.replicas))
+			require.Equal(t, tt.violations, model.ValidateLayout(chi, tt.nodes))
+		})
+	}
+}
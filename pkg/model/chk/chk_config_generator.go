@@ -59,7 +59,7 @@ func defaultKeeperSettings(path string) *apiChi.Settings {
 }
 
 // generateXMLConfig creates XML using map[string]string definitions
-func generateXMLConfig(settings *apiChi.Settings, chk *apiChk.ClickHouseKeeperInstallation) string {
+func generateXMLConfig(settings *apiChi.Settings, chk *apiChk.ClickHouseKeeperInstallation, replicas int) string {
 	if settings.Len() == 0 {
 		return ""
 	}
@@ -81,10 +81,10 @@ func generateXMLConfig(settings *apiChi.Settings, chk *apiChk.ClickHouseKeeperIn
 
 	raft := &bytes.Buffer{}
 	raftPort := chk.Spec.GetRaftPort()
-	for i := 0; i < getCluster(chk).GetLayout().GetReplicasCount(); i++ {
+	for i := 0; i < replicas; i++ {
 		util.Iline(raft, 12, "<server>")
 		util.Iline(raft, 12, "    <id>%d</id>", i)
-		util.Iline(raft, 12, "    <hostname>%s-%d.%s-headless.%s.svc.cluster.local</hostname>", chk.Name, i, chk.Name, chk.Namespace)
+		util.Iline(raft, 12, "    <hostname>%s</hostname>", GetPodFQDN(chk, i))
 		util.Iline(raft, 12, "    <port>%s</port>", fmt.Sprintf("%d", raftPort))
 		util.Iline(raft, 12, "</server>")
 	}
@@ -26,8 +26,9 @@ import (
 	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse-keeper.altinity.com/v1"
 )
 
-// CreateConfigMap returns a config map containing ClickHouse Keeper config XML
-func CreateConfigMap(chk *api.ClickHouseKeeperInstallation) *core.ConfigMap {
+// CreateConfigMap returns a config map containing ClickHouse Keeper config XML.
+// Raft configuration lists specified number of ensemble members.
+func CreateConfigMap(chk *api.ClickHouseKeeperInstallation, replicas int) *core.ConfigMap {
 	return &core.ConfigMap{
 		TypeMeta: meta.TypeMeta{
 			Kind:       "ConfigMap",
@@ -38,7 +39,7 @@ func CreateConfigMap(chk *api.ClickHouseKeeperInstallation) *core.ConfigMap {
 			Namespace: chk.Namespace,
 		},
 		Data: map[string]string{
-			"keeper_config.xml": generateXMLConfig(chk.Spec.GetConfiguration().GetSettings(), chk),
+			"keeper_config.xml": generateXMLConfig(chk.Spec.GetConfiguration().GetSettings(), chk, replicas),
 		},
 	}
 }
@@ -23,3 +23,8 @@ import (
 func getHeadlessServiceName(chk *api.ClickHouseKeeperInstallation) string {
 	return fmt.Sprintf("%s-headless", chk.GetName())
 }
+
+// GetPodFQDN returns FQDN of the ensemble member with specified ordinal
+func GetPodFQDN(chk *api.ClickHouseKeeperInstallation, i int) string {
+	return fmt.Sprintf("%s-%d.%s.%s.svc.cluster.local", chk.GetName(), i, getHeadlessServiceName(chk), chk.Namespace)
+}
@@ -0,0 +1,43 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chk
+
+import (
+	"testing"
+
+	"github.com/kubernetes-sigs/yaml"
+	"github.com/stretchr/testify/require"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse-keeper.altinity.com/v1"
+)
+
+func Test_ValidateLayout(t *testing.T) {
+	tests := map[string][]string{
+		`{}`: nil,
+		`{"spec": {"configuration": {"clusters": [{"name": "keeper", "layout": {"replicasCount": 1}}]}}}`: nil,
+		`{"spec": {"configuration": {"clusters": [{"name": "keeper", "layout": {"replicasCount": 3}}]}}}`: nil,
+		`{"spec": {"configuration": {"clusters": [{"name": "keeper", "layout": {"replicasCount": 2}}]}}}`: {
+			"ensemble of 2 members, odd number of members is required to tolerate failures",
+		},
+		`{"spec": {"configuration": {"clusters": [{"name": "keeper", "layout": {"replicasCount": 4}}]}}}`: {
+			"ensemble of 4 members, odd number of members is required to tolerate failures",
+		},
+	}
+	for manifest, violations := range tests {
+		chk := &api.ClickHouseKeeperInstallation{}
+		require.NoError(t, yaml.Unmarshal([]byte(manifest), chk))
+		require.Equal(t, violations, ValidateLayout(chk), manifest)
+	}
+}
@@ -24,6 +24,19 @@ type ClusterConnectionParams struct {
 	*Timeouts
 }
 
+// EndpointResolver maps hostname and port of a host to the address connection is established to
+type EndpointResolver func(hostname string, port int) (string, int)
+
+// endpointResolver specifies how hosts are resolved into addresses connections are established to.
+// Hosts are connected by their hostnames unless specified
+var endpointResolver EndpointResolver
+
+// SetEndpointResolver sets how hosts are resolved into addresses connections are established to.
+// Typically used by integration tests in order to direct connections to a mock ClickHouse
+func SetEndpointResolver(resolver EndpointResolver) {
+	endpointResolver = resolver
+}
+
 // NewClusterConnectionParams creates new ClusterConnectionParams
 func NewClusterConnectionParams(scheme, username, password, rootCA string, port int) *ClusterConnectionParams {
 	return &ClusterConnectionParams{
@@ -62,12 +75,16 @@ func (p *ClusterConnectionParams) NewEndpointConnectionParams(host string) *Endp
 	if p == nil {
 		return nil
 	}
+	port := p.Port
+	if endpointResolver != nil {
+		host, port = endpointResolver(host, port)
+	}
 	return NewEndpointConnectionParams(
 		p.Scheme,
 		host,
 		p.Username,
 		p.Password,
 		p.RootCA,
-		p.Port,
+		port,
 	).SetTimeouts(p.Timeouts)
 }
@@ -151,6 +151,19 @@ func Unique(slice []string) (unique []string) {
 	return unique
 }
 
+// ArraysEqual checks whether two arrays have the same elements in the same order
+func ArraysEqual(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func NonEmpty(slice []string) (nonEmpty []string) {
 	for _, str := range slice {
 		if str != "" {
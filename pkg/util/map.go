@@ -217,6 +217,16 @@ func MapHasKeys(m map[string]string, keys ...string) bool {
 	return true
 }
 
+// MapSortedKeys returns sorted list of keys of the map
+func MapSortedKeys(m map[string]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Map2String returns named map[string]string mas as a string
 func Map2String(name string, m map[string]string) string {
 	// Write map entries according to sorted keys
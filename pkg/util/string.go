@@ -21,9 +21,13 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"math/rand"
+	"strings"
 	"time"
 )
 
+// shortenStringHashLen specifies length of hash shortened strings are suffixed with
+const shortenStringHashLen = 8
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
@@ -82,6 +86,20 @@ func StringHead(str string, maxHeadLen int) string {
 	return str[:maxHeadLen]
 }
 
+// ShortenString shortens string to maxLen, replacing its tail with hash of the whole string.
+// Shortened strings are deterministic and remain distinct, as long as originals are
+func ShortenString(str string, maxLen int) string {
+	if len(str) <= maxLen {
+		return str
+	}
+	hash := CreateStringID(str, shortenStringHashLen)
+	if maxLen <= len(hash) {
+		return StringHead(hash, maxLen)
+	}
+	head := strings.TrimRight(StringHead(str, maxLen-len(hash)-1), "-_.")
+	return head + "-" + hash
+}
+
 // StringSliceContains implements contain method for string slice
 func StringSliceContains(haystack []string, needle string) bool {
 	for _, a := range haystack {
@@ -0,0 +1,75 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ShortenString(t *testing.T) {
+	long := strings.Repeat("a", 60) + "-" + strings.Repeat("b", 10)
+	tests := []struct {
+		name   string
+		str    string
+		maxLen int
+		want   string
+	}{
+		{
+			name:   "short string is kept",
+			str:    "chi-main-0-0",
+			maxLen: 63,
+			want:   "chi-main-0-0",
+		},
+		{
+			name:   "string of max length is kept",
+			str:    strings.Repeat("a", 63),
+			maxLen: 63,
+			want:   strings.Repeat("a", 63),
+		},
+		{
+			name:   "long string is suffixed with hash",
+			str:    long,
+			maxLen: 63,
+			want:   strings.Repeat("a", 54) + "-" + CreateStringID(long, shortenStringHashLen),
+		},
+		{
+			name:   "head is not ended with separator",
+			str:    strings.Repeat("a", 53) + "-" + strings.Repeat("b", 20),
+			maxLen: 63,
+			want:   strings.Repeat("a", 53) + "-" + CreateStringID(strings.Repeat("a", 53)+"-"+strings.Repeat("b", 20), shortenStringHashLen),
+		},
+		{
+			name:   "max length shorter than hash",
+			str:    long,
+			maxLen: 4,
+			want:   CreateStringID(long, shortenStringHashLen)[:4],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ShortenString(tt.str, tt.maxLen)
+			require.Equal(t, tt.want, got)
+			require.LessOrEqual(t, len(got), tt.maxLen)
+			// Shortening is deterministic
+			require.Equal(t, got, ShortenString(tt.str, tt.maxLen))
+		})
+	}
+
+	// Strings sharing the head remain distinct
+	require.NotEqual(t, ShortenString(long+"1", 63), ShortenString(long+"2", 63))
+}
@@ -0,0 +1,272 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kubernetes-sigs/yaml"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilYaml "k8s.io/apimachinery/pkg/util/yaml"
+	kube "k8s.io/client-go/kubernetes"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// Kinds of objects validated
+const (
+	KindCHI  = "ClickHouseInstallation"
+	KindCHIT = "ClickHouseInstallationTemplate"
+)
+
+// Result is a result of validation of an object
+type Result struct {
+	// Kind is a kind of the object validated
+	Kind string
+	// Namespace is a namespace of the object validated
+	Namespace string
+	// Name is a name of the object validated
+	Name string
+	// Errors lists issues the operator denies reconcile of the object because of
+	Errors []string
+	// Warnings lists issues the operator reconciles the object despite of
+	Warnings []string
+}
+
+// IsValid checks whether no errors are found
+func (r *Result) IsValid() bool {
+	return len(r.Errors) == 0
+}
+
+// String returns human-readable representation of the result
+func (r *Result) String() string {
+	b := &strings.Builder{}
+	status := "OK"
+	if !r.IsValid() {
+		status = "INVALID"
+	}
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + r.Name
+	}
+	fmt.Fprintf(b, "%s %s: %s\n", r.Kind, name, status)
+	for _, e := range r.Errors {
+		fmt.Fprintf(b, "  error: %s\n", e)
+	}
+	for _, w := range r.Warnings {
+		fmt.Fprintf(b, "  warning: %s\n", w)
+	}
+	return b.String()
+}
+
+// errorf appends an error to the result
+func (r *Result) errorf(format string, args ...interface{}) {
+	r.Errors = append(r.Errors, fmt.Sprintf(format, args...))
+}
+
+// warningf appends a warning to the result
+func (r *Result) warningf(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Init initializes operator config CHIs are normalized with, since there is no operator running.
+// Operator defaults are used unless config file is specified
+func Init(configFilePath string) error {
+	return chop.NewOffline(configFilePath)
+}
+
+// Validator validates CHIs with normalization and validation code of the operator,
+// so CHI manifests can be checked before they are applied to a cluster, say in CI pipelines.
+// Operator config has to be initialized before use, either by the operator itself or by Init
+type Validator struct {
+	normalizer *model.Normalizer
+	lookup     model.ObjectLookup
+	nodes      []core.Node
+}
+
+// NewValidator creates new validator.
+// Kubernetes client is used to fetch secrets referenced by CHIs, it can be nil in case there is no cluster at hand
+func NewValidator(kubeClient kube.Interface) *Validator {
+	return &Validator{
+		normalizer: model.NewNormalizer(kubeClient),
+	}
+}
+
+// SetLookup sets lookup of existing objects, used to find names of objects colliding with existing objects
+func (v *Validator) SetLookup(lookup model.ObjectLookup) *Validator {
+	v.lookup = lookup
+	return v
+}
+
+// SetNodes sets nodes, used to check whether required anti-affinity of hosts is satisfiable
+func (v *Validator) SetNodes(nodes []core.Node) *Validator {
+	v.nodes = nodes
+	return v
+}
+
+// ValidateManifest validates CHIs of multi-document YAML manifest.
+// CHITs of the manifest are made available to CHIs of the manifest, other objects are skipped.
+// Returns results of CHIs and CHITs found
+func (v *Validator) ValidateManifest(manifest io.Reader) ([]*Result, error) {
+	docs, err := splitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*Result
+	// CHIs are validated after all CHITs of the manifest are available
+	chis := make(map[*Result]*api.ClickHouseInstallation)
+	for _, doc := range docs {
+		typeMeta := meta.TypeMeta{}
+		if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+			return nil, err
+		}
+		switch typeMeta.Kind {
+		case KindCHI, KindCHIT:
+		default:
+			continue
+		}
+
+		result := &Result{
+			Kind: typeMeta.Kind,
+		}
+		results = append(results, result)
+
+		chi, err := decode(doc, result)
+		if err != nil {
+			result.errorf("unable to parse: %v", err)
+			continue
+		}
+		result.Namespace, result.Name = chi.Namespace, chi.Name
+
+		switch typeMeta.Kind {
+		case KindCHI:
+			chis[result] = chi
+		case KindCHIT:
+			chop.Config().AddCHITemplate(chi)
+		}
+	}
+
+	for _, result := range results {
+		if chi, ok := chis[result]; ok {
+			v.validate(chi, result)
+		}
+	}
+
+	return results, nil
+}
+
+// ValidateCHI validates the CHI
+func (v *Validator) ValidateCHI(chi *api.ClickHouseInstallation) *Result {
+	result := &Result{
+		Kind:      KindCHI,
+		Namespace: chi.Namespace,
+		Name:      chi.Name,
+	}
+	v.validate(chi, result)
+	return result
+}
+
+// validate runs the same checks the operator runs before reconcile of the CHI
+func (v *Validator) validate(chi *api.ClickHouseInstallation, result *Result) {
+	normalized, err := v.normalizer.CreateTemplatedCHI(chi, model.NewNormalizerOptions())
+	if err != nil {
+		result.errorf("unable to normalize: %v", err)
+		return
+	}
+
+	for _, migration := range normalized.EnsureStatus().GetMigrations() {
+		result.warningf("deprecated field is migrated: %s", migration)
+	}
+
+	for _, rejection := range normalized.EnsureStatus().GetRejections() {
+		result.errorf("invalid entry is rejected: %s", rejection)
+	}
+
+	if mismatches := model.FindZookeeperMismatches(normalized); len(mismatches) > 0 {
+		result.errorf("keeper ensembles mismatch: %s", strings.Join(mismatches, "; "))
+	}
+
+	if clashes := model.FindRemoteClusterClashes(normalized); len(clashes) > 0 {
+		result.errorf("custom clusters clash: %s", strings.Join(clashes, "; "))
+	}
+
+	if collisions := model.FindNameCollisions(normalized, v.lookup); len(collisions) > 0 {
+		result.errorf("names of objects collide: %s", strings.Join(collisions, "; "))
+	}
+
+	if missing := model.FindMissingTemplates(normalized); len(missing) > 0 {
+		if normalized.Spec.Validation.IsStrictTemplates() {
+			result.errorf("referenced templates not found: %s", strings.Join(missing, "; "))
+		} else {
+			result.warningf("referenced templates not found, defaults are used instead: %s", strings.Join(missing, "; "))
+		}
+	}
+
+	if unknown := model.FindUnknownGuardUsers(normalized); len(unknown) > 0 {
+		result.warningf("guards of unknown users are not applied: %s", strings.Join(unknown, ", "))
+	}
+
+	if clashes := model.FindNodePortClashes(normalized); len(clashes) > 0 {
+		result.warningf("ports of node network clash, each of these hosts requires node of its own: %s", strings.Join(clashes, "; "))
+	}
+
+	if violations := model.ValidateLayout(normalized, v.nodes); len(violations) > 0 {
+		if normalized.Spec.Validation.IsDeny() {
+			result.errorf("layout validation failed: %s", strings.Join(violations, "; "))
+		} else {
+			result.warningf("layout validation failed: %s", strings.Join(violations, "; "))
+		}
+	}
+}
+
+// decode decodes CHI or CHIT. Fields unknown to the operator are reported as errors,
+// since they are either misspelled or not supported by this version of the operator
+func decode(doc []byte, result *Result) (*api.ClickHouseInstallation, error) {
+	chi := &api.ClickHouseInstallation{}
+	if err := yaml.Unmarshal(doc, chi); err != nil {
+		return nil, err
+	}
+	if err := yaml.UnmarshalStrict(doc, &api.ClickHouseInstallation{}); err != nil {
+		result.errorf("schema: %v", err)
+	}
+	return chi, nil
+}
+
+// splitManifest splits multi-document YAML manifest into documents, empty documents are skipped
+func splitManifest(manifest io.Reader) (docs [][]byte, err error) {
+	reader := utilYaml.NewYAMLReader(bufio.NewReader(manifest))
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+}
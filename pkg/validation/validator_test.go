@@ -0,0 +1,138 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testManifest = `
+apiVersion: clickhouse.altinity.com/v1
+kind: ClickHouseInstallationTemplate
+metadata:
+  name: tpl
+spec:
+  templates:
+    podTemplates:
+      - name: pod
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: skipped
+---
+apiVersion: clickhouse.altinity.com/v1
+kind: ClickHouseInstallation
+metadata:
+  name: valid
+spec:
+  useTemplates:
+    - name: tpl
+  defaults:
+    templates:
+      podTemplate: pod
+---
+apiVersion: clickhouse.altinity.com/v1
+kind: ClickHouseInstallation
+metadata:
+  name: invalid
+spec:
+  configuration:
+    zookeeper:
+      nodes:
+        - host: zk-0
+        - host: zk-1
+    clusters:
+      - name: c
+        layoutt:
+          shardsCount: 1
+        templates:
+          podTemplate: missing
+`
+
+func Test_ValidateManifest(t *testing.T) {
+	require.NoError(t, Init(""))
+
+	results, err := NewValidator(nil).ValidateManifest(strings.NewReader(testManifest))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.Equal(t, KindCHIT, results[0].Kind)
+	require.True(t, results[0].IsValid())
+
+	// Template of the manifest is available to CHIs of the manifest
+	require.Equal(t, "valid", results[1].Name)
+	require.True(t, results[1].IsValid())
+	require.Empty(t, results[1].Warnings)
+
+	require.Equal(t, "invalid", results[2].Name)
+	require.False(t, results[2].IsValid())
+	require.Len(t, results[2].Errors, 1)
+	require.Contains(t, results[2].Errors[0], `unknown field "layoutt"`)
+	require.Len(t, results[2].Warnings, 2)
+	require.Contains(t, results[2].Warnings[0], "podTemplate missing")
+	require.Contains(t, results[2].Warnings[1], "keeper ensemble of 2 nodes")
+}
+
+const testGuardsManifest = `
+apiVersion: clickhouse.altinity.com/v1
+kind: ClickHouseInstallation
+metadata:
+  name: guards
+spec:
+  configuration:
+    guards:
+      users:
+        unknown:
+          maxSessionsForUser: 1
+`
+
+func Test_ValidateManifest_UnknownGuardUsers(t *testing.T) {
+	require.NoError(t, Init(""))
+
+	results, err := NewValidator(nil).ValidateManifest(strings.NewReader(testGuardsManifest))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.True(t, results[0].IsValid())
+	require.Len(t, results[0].Warnings, 1)
+	require.Contains(t, results[0].Warnings[0], "guards of unknown users are not applied: unknown")
+}
+
+const testRejectionsManifest = `
+apiVersion: clickhouse.altinity.com/v1
+kind: ClickHouseInstallation
+metadata:
+  name: rejections
+spec:
+  configuration:
+    clusters:
+      - name: main
+        replicatedDatabases:
+          - name: "bad name"
+`
+
+func Test_ValidateManifest_Rejections(t *testing.T) {
+	require.NoError(t, Init(""))
+
+	results, err := NewValidator(nil).ValidateManifest(strings.NewReader(testRejectionsManifest))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.False(t, results[0].IsValid())
+	require.Len(t, results[0].Errors, 1)
+	require.Contains(t, results[0].Errors[0], `invalid entry is rejected: cluster main replicated database "bad name": invalid name`)
+}
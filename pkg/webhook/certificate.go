@@ -0,0 +1,131 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+)
+
+// certificateValidity specifies how long self-signed certificate is valid
+const certificateValidity = 10 * 365 * 24 * time.Hour
+
+// certificateSecretName specifies name of the secret keeping certificate of the webhook service
+func (s *Server) certificateSecretName() string {
+	return s.service + "-certificate"
+}
+
+// getCertificate gets certificate of the webhook service from the secret, shared by all instances of the operator,
+// so all of them serve webhooks with the same certificate, which is the CA bundle CRDs and admission point to.
+// The secret is created by the instance which gets there first.
+// Returns TLS certificate to serve with along with PEM-encoded certificate to be used as CA bundle
+func (s *Server) getCertificate(ctx context.Context) (tls.Certificate, []byte, error) {
+	client := s.kubeClient.CoreV1().Secrets(s.namespace)
+	secret, err := client.Get(ctx, s.certificateSecretName(), controller.NewGetOptions())
+	if apiErrors.IsNotFound(err) {
+		secret, err = s.createCertificateSecret(ctx)
+		if apiErrors.IsAlreadyExists(err) {
+			// Another instance of the operator has created the secret concurrently
+			secret, err = client.Get(ctx, s.certificateSecretName(), controller.NewGetOptions())
+		}
+	}
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("unable to get certificate secret %s/%s err: %v", s.namespace, s.certificateSecretName(), err)
+	}
+
+	certPEM := secret.Data[core.TLSCertKey]
+	cert, err := tls.X509KeyPair(certPEM, secret.Data[core.TLSPrivateKeyKey])
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("unable to parse certificate secret %s/%s err: %v", s.namespace, s.certificateSecretName(), err)
+	}
+	return cert, certPEM, nil
+}
+
+// createCertificateSecret creates secret with new self-signed certificate of the webhook service
+func (s *Server) createCertificateSecret(ctx context.Context) (*core.Secret, error) {
+	certPEM, keyPEM, err := newSelfSignedCertificate(s.dnsNames())
+	if err != nil {
+		return nil, err
+	}
+	secret := &core.Secret{
+		ObjectMeta: meta.ObjectMeta{
+			Namespace: s.namespace,
+			Name:      s.certificateSecretName(),
+		},
+		Type: core.SecretTypeTLS,
+		Data: map[string][]byte{
+			core.TLSCertKey:       certPEM,
+			core.TLSPrivateKeyKey: keyPEM,
+		},
+	}
+	secret, err = s.kubeClient.CoreV1().Secrets(s.namespace).Create(ctx, secret, controller.NewCreateOptions())
+	if err == nil {
+		log.V(1).F().Info("certificate secret created %s/%s", s.namespace, s.certificateSecretName())
+	}
+	return secret, err
+}
+
+// newSelfSignedCertificate creates self-signed certificate for specified DNS names.
+// Returns PEM-encoded certificate and key
+func newSelfSignedCertificate(dnsNames []string) ([]byte, []byte, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName: dnsNames[0],
+		},
+		DNSNames:              dnsNames,
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certificateValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create certificate err: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return certPEM, keyPEM, nil
+}
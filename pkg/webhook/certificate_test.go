@@ -0,0 +1,66 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiExtensionsV1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extFake "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/fake"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubeFake "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_Servers_ShareCertificate(t *testing.T) {
+	ctx := context.Background()
+	crd := &apiExtensionsV1.CustomResourceDefinition{
+		ObjectMeta: meta.ObjectMeta{Name: crdNames[0]},
+		Spec: apiExtensionsV1.CustomResourceDefinitionSpec{
+			Versions: []apiExtensionsV1.CustomResourceDefinitionVersion{{Name: "v1"}, {Name: "v2"}},
+		},
+	}
+	kubeClient := kubeFake.NewSimpleClientset()
+	extClient := extFake.NewSimpleClientset(crd)
+	first := NewServer(":9443", "webhook", "test", kubeClient, extClient)
+	second := NewServer(":9443", "webhook", "test", kubeClient, extClient)
+
+	// Both instances serve the same certificate
+	firstCert, caBundle, err := first.getCertificate(ctx)
+	require.NoError(t, err)
+	secondCert, secondCABundle, err := second.getCertificate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, caBundle, secondCABundle)
+	require.Equal(t, firstCert.Certificate, secondCert.Certificate)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(caBundle))
+	leaf, err := x509.ParseCertificate(secondCert.Certificate[0])
+	require.NoError(t, err)
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "webhook.test.svc", Roots: roots})
+	require.NoError(t, err)
+
+	// The leader points configurations to the shared certificate
+	require.NoError(t, second.EnsureConfigurations(ctx))
+	config, err := kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(ctx, validatingWebhookName, meta.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, caBundle, config.Webhooks[0].ClientConfig.CABundle)
+	crd, err = extClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, crdNames[0], meta.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, caBundle, crd.Spec.Conversion.Webhook.ClientConfig.CABundle)
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	apiExtensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	clickhouse_altinity_com "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+const (
+	// APIVersionV1Beta1 specifies legacy API version, which may carry deprecated fields
+	APIVersionV1Beta1 = clickhouse_altinity_com.APIGroupName + "/v1beta1"
+	// APIVersionV1 specifies current API version
+	APIVersionV1 = clickhouse_altinity_com.APIGroupName + "/" + api.APIVersion
+)
+
+// handleConvert serves ConversionReview requests issued by kube-apiserver
+func handleConvert(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := &apiExtensions.ConversionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "conversion request is missing", http.StatusBadRequest)
+		return
+	}
+
+	review.Response = convertReview(review.Request)
+	review.Request = nil
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.V(1).F().Error("unable to write conversion response err: %v", err)
+	}
+}
+
+// convertReview converts all objects of the request into desired API version
+func convertReview(request *apiExtensions.ConversionRequest) *apiExtensions.ConversionResponse {
+	response := &apiExtensions.ConversionResponse{
+		UID: request.UID,
+	}
+
+	for _, object := range request.Objects {
+		converted, err := convertObject(object, request.DesiredAPIVersion)
+		if err != nil {
+			log.V(1).F().Error("unable to convert object to %s err: %v", request.DesiredAPIVersion, err)
+			response.ConvertedObjects = nil
+			response.Result = meta.Status{
+				Status:  meta.StatusFailure,
+				Message: err.Error(),
+			}
+			return response
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, converted)
+	}
+
+	response.Result = meta.Status{
+		Status: meta.StatusSuccess,
+	}
+	return response
+}
+
+// convertObject converts an object into desired API version.
+// Conversion into current version migrates deprecated fields of the spec into the current layout.
+// Conversion into legacy version changes API version only, since current layout is accepted by legacy version as well.
+func convertObject(object runtime.RawExtension, desiredAPIVersion string) (runtime.RawExtension, error) {
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(object.Raw); err != nil {
+		return runtime.RawExtension{}, err
+	}
+
+	fromAPIVersion := obj.GetAPIVersion()
+	switch {
+	case fromAPIVersion == desiredAPIVersion:
+		return object, nil
+	case (fromAPIVersion == APIVersionV1Beta1) && (desiredAPIVersion == APIVersionV1):
+		if err := migrateSpec(obj); err != nil {
+			return runtime.RawExtension{}, err
+		}
+	case (fromAPIVersion == APIVersionV1) && (desiredAPIVersion == APIVersionV1Beta1):
+	default:
+		return runtime.RawExtension{}, fmt.Errorf("unsupported conversion %s -> %s", fromAPIVersion, desiredAPIVersion)
+	}
+
+	obj.SetAPIVersion(desiredAPIVersion)
+	raw, err := obj.MarshalJSON()
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
+
+// migrateSpec migrates deprecated fields of the object's spec into the current layout
+func migrateSpec(obj *unstructured.Unstructured) error {
+	spec, ok := obj.Object["spec"]
+	if !ok {
+		return nil
+	}
+
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	chi := &api.ClickHouseInstallation{}
+	if err := json.Unmarshal(raw, &chi.Spec); err != nil {
+		return err
+	}
+
+	migrations := model.MigrateDeprecatedFields(chi)
+	if len(migrations) == 0 {
+		return nil
+	}
+	for _, migration := range migrations {
+		log.V(1).F().Info("%s/%s converted to %s: %s", obj.GetNamespace(), obj.GetName(), APIVersionV1, migration)
+	}
+
+	if raw, err = json.Marshal(chi.Spec); err != nil {
+		return err
+	}
+	spec = nil
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return err
+	}
+	obj.Object["spec"] = spec
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const legacyCHI = `{
+	"apiVersion": "clickhouse.altinity.com/v1beta1",
+	"kind": "ClickHouseInstallation",
+	"metadata": {"name": "legacy", "namespace": "test"},
+	"spec": {
+		"defaults": {"templates": {"volumeClaimTemplate": "data"}},
+		"templates": {"podTemplates": [{"name": "pod", "distribution": "OnePerHost"}]}
+	},
+	"status": {"status": "Completed"}
+}`
+
+func Test_ConvertObject(t *testing.T) {
+	converted, err := convertObject(runtime.RawExtension{Raw: []byte(legacyCHI)}, APIVersionV1)
+	require.NoError(t, err)
+
+	obj := &unstructured.Unstructured{}
+	require.NoError(t, obj.UnmarshalJSON(converted.Raw))
+	require.Equal(t, APIVersionV1, obj.GetAPIVersion())
+
+	template, _, _ := unstructured.NestedString(obj.Object, "spec", "defaults", "templates", "dataVolumeClaimTemplate")
+	require.Equal(t, "data", template)
+	_, found, _ := unstructured.NestedString(obj.Object, "spec", "defaults", "templates", "volumeClaimTemplate")
+	require.False(t, found)
+
+	podTemplates, _, _ := unstructured.NestedSlice(obj.Object, "spec", "templates", "podTemplates")
+	require.Len(t, podTemplates, 1)
+	podDistribution, _, _ := unstructured.NestedSlice(podTemplates[0].(map[string]interface{}), "podDistribution")
+	require.Equal(t, []interface{}{map[string]interface{}{"type": "ClickHouseAntiAffinity"}}, podDistribution)
+
+	status, _, _ := unstructured.NestedString(obj.Object, "status", "status")
+	require.Equal(t, "Completed", status)
+
+	back, err := convertObject(converted, APIVersionV1Beta1)
+	require.NoError(t, err)
+	require.NoError(t, obj.UnmarshalJSON(back.Raw))
+	require.Equal(t, APIVersionV1Beta1, obj.GetAPIVersion())
+
+	_, err = convertObject(converted, "clickhouse.altinity.com/v2")
+	require.Error(t, err)
+}
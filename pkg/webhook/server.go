@@ -0,0 +1,235 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	admissionRegistration "k8s.io/api/admissionregistration/v1"
+	apiExtensionsV1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiExtensions "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	clickhouse_altinity_com "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/controller"
+)
+
+const (
+	// ConvertPath specifies path conversion webhook is served at
+	ConvertPath = "/convert"
+	// ValidatePath specifies path validating admission webhook is served at
+	ValidatePath = "/validate"
+	// validatingWebhookName specifies name of the ValidatingWebhookConfiguration managed by the operator
+	validatingWebhookName = "clickhouse-operator-validation." + clickhouse_altinity_com.APIGroupName
+	// servicePort specifies port of the webhook service, kube-apiserver addresses
+	servicePort = int32(443)
+	// shutdownTimeout specifies how long to wait for in-flight requests on shutdown
+	shutdownTimeout = 5 * time.Second
+)
+
+// crdNames lists CRDs served at multiple API versions and converted by the webhook
+var crdNames = []string{
+	"clickhouseinstallations.clickhouse.altinity.com",
+	"clickhouseinstallationtemplates.clickhouse.altinity.com",
+}
+
+// Server serves CRD conversion and CHI validating admission webhooks
+type Server struct {
+	endpoint   string
+	service    string
+	namespace  string
+	kubeClient kube.Interface
+	extClient  apiExtensions.Interface
+}
+
+// NewServer creates new webhook server.
+// Service and namespace specify Service, kube-apiserver reaches the webhook by
+func NewServer(endpoint, service, namespace string, kubeClient kube.Interface, extClient apiExtensions.Interface) *Server {
+	return &Server{
+		endpoint:   endpoint,
+		service:    service,
+		namespace:  namespace,
+		kubeClient: kubeClient,
+		extClient:  extClient,
+	}
+}
+
+// Run serves webhooks till context is done.
+// CRDs and admission are pointed to the webhook by EnsureConfigurations
+func (s *Server) Run(ctx context.Context) error {
+	cert, _, err := s.getCertificate(ctx)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ConvertPath, handleConvert)
+	mux.HandleFunc(ValidatePath, newValidateHandler(s.kubeClient))
+	server := &http.Server{
+		Addr:    s.endpoint,
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.V(1).F().Info("serving webhooks at %s", s.endpoint)
+		errs <- server.ListenAndServeTLS("", "")
+	}()
+
+	select {
+	case err := <-errs:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+// EnsureConfigurations points CRDs conversion and CHI admission to the webhook.
+// Is expected to be run by the elected leader only, so instances of the operator do not override each other
+func (s *Server) EnsureConfigurations(ctx context.Context) error {
+	_, caBundle, err := s.getCertificate(ctx)
+	if err != nil {
+		return err
+	}
+	s.ensureConversion(ctx, caBundle)
+	s.ensureValidation(ctx, caBundle)
+	return nil
+}
+
+// dnsNames lists DNS names of the webhook service
+func (s *Server) dnsNames() []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", s.service, s.namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", s.service, s.namespace),
+		fmt.Sprintf("%s.%s", s.service, s.namespace),
+		s.service,
+	}
+}
+
+// ensureConversion switches CRDs served at multiple API versions to webhook conversion
+func (s *Server) ensureConversion(ctx context.Context, caBundle []byte) {
+	for _, name := range crdNames {
+		crd, err := s.extClient.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, name, controller.NewGetOptions())
+		if err != nil {
+			log.V(1).F().Warning("unable to get CRD %s err: %v", name, err)
+			continue
+		}
+		if len(crd.Spec.Versions) < 2 {
+			// Single version is served, nothing to convert
+			log.V(1).F().Info("CRD %s serves single version, skip conversion webhook", name)
+			continue
+		}
+
+		path := ConvertPath
+		port := servicePort
+		crd.Spec.Conversion = &apiExtensionsV1.CustomResourceConversion{
+			Strategy: apiExtensionsV1.WebhookConverter,
+			Webhook: &apiExtensionsV1.WebhookConversion{
+				ClientConfig: &apiExtensionsV1.WebhookClientConfig{
+					Service: &apiExtensionsV1.ServiceReference{
+						Namespace: s.namespace,
+						Name:      s.service,
+						Path:      &path,
+						Port:      &port,
+					},
+					CABundle: caBundle,
+				},
+				ConversionReviewVersions: []string{"v1"},
+			},
+		}
+		if _, err := s.extClient.ApiextensionsV1().CustomResourceDefinitions().Update(ctx, crd, controller.NewUpdateOptions()); err != nil {
+			log.V(1).F().Error("unable to set conversion webhook for CRD %s err: %v", name, err)
+			continue
+		}
+		log.V(1).F().Info("conversion webhook set for CRD %s", name)
+	}
+}
+
+// ensureValidation registers validating admission webhook for CHIs.
+// Failure policy is Ignore, so CHIs are admitted while the operator is not available
+func (s *Server) ensureValidation(ctx context.Context, caBundle []byte) {
+	path := ValidatePath
+	port := servicePort
+	failurePolicy := admissionRegistration.Ignore
+	sideEffects := admissionRegistration.SideEffectClassNone
+	config := &admissionRegistration.ValidatingWebhookConfiguration{
+		ObjectMeta: meta.ObjectMeta{
+			Name: validatingWebhookName,
+		},
+		Webhooks: []admissionRegistration.ValidatingWebhook{
+			{
+				Name: validatingWebhookName,
+				ClientConfig: admissionRegistration.WebhookClientConfig{
+					Service: &admissionRegistration.ServiceReference{
+						Namespace: s.namespace,
+						Name:      s.service,
+						Path:      &path,
+						Port:      &port,
+					},
+					CABundle: caBundle,
+				},
+				Rules: []admissionRegistration.RuleWithOperations{
+					{
+						Operations: []admissionRegistration.OperationType{
+							admissionRegistration.Create,
+							admissionRegistration.Update,
+						},
+						Rule: admissionRegistration.Rule{
+							APIGroups:   []string{clickhouse_altinity_com.APIGroupName},
+							APIVersions: []string{api.APIVersion},
+							Resources:   []string{"clickhouseinstallations"},
+						},
+					},
+				},
+				FailurePolicy:           &failurePolicy,
+				SideEffects:             &sideEffects,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+		},
+	}
+
+	client := s.kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations()
+	cur, err := client.Get(ctx, validatingWebhookName, controller.NewGetOptions())
+	switch {
+	case err == nil:
+		config.ResourceVersion = cur.ResourceVersion
+		_, err = client.Update(ctx, config, controller.NewUpdateOptions())
+	case apiErrors.IsNotFound(err):
+		_, err = client.Create(ctx, config, controller.NewCreateOptions())
+	}
+	if err != nil {
+		log.V(1).F().Error("unable to set validating webhook %s err: %v", validatingWebhookName, err)
+		return
+	}
+	log.V(1).F().Info("validating webhook set %s", validatingWebhookName)
+}
@@ -0,0 +1,146 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	admission "k8s.io/api/admission/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// newValidateHandler creates handler serving AdmissionReview requests issued by kube-apiserver
+func newValidateHandler(kubeClient kube.Interface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		review := &admission.AdmissionReview{}
+		if err := json.Unmarshal(body, review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if review.Request == nil {
+			http.Error(w, "admission request is missing", http.StatusBadRequest)
+			return
+		}
+
+		lookup, err := model.NewObjectLookup(r.Context(), kubeClient, review.Request.Namespace)
+		if err != nil {
+			log.V(1).F().Warning("unable to list objects of namespace %s err: %v", review.Request.Namespace, err)
+		}
+
+		review.Response = validateReview(review.Request, model.NewNormalizer(kubeClient), lookup)
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			log.V(1).F().Error("unable to write admission response err: %v", err)
+		}
+	}
+}
+
+// validateReview validates CHI of the request.
+// Objects which can not be judged are admitted, the operator reports issues during reconcile
+func validateReview(
+	request *admission.AdmissionRequest,
+	normalizer *model.Normalizer,
+	lookup model.ObjectLookup,
+) *admission.AdmissionResponse {
+	response := &admission.AdmissionResponse{
+		UID:     request.UID,
+		Allowed: true,
+	}
+
+	if request.Operation == admission.Delete {
+		return response
+	}
+
+	chi := &api.ClickHouseInstallation{}
+	if err := json.Unmarshal(request.Object.Raw, chi); err != nil {
+		log.V(1).F().Warning("unable to unmarshal CHI %s/%s err: %v", request.Namespace, request.Name, err)
+		return response
+	}
+
+	if err := validateCHI(chi, normalizer, lookup); err != nil {
+		response.Allowed = false
+		response.Result = &meta.Status{
+			Status:  meta.StatusFailure,
+			Message: err.Error(),
+			Reason:  meta.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+		}
+		return response
+	}
+
+	// Configuration not applied and risky changes are admitted, but reported back to the client as warnings
+	response.Warnings = configurationWarnings(chi, normalizer)
+	if request.Operation == admission.Update {
+		old := &api.ClickHouseInstallation{}
+		if err := json.Unmarshal(request.OldObject.Raw, old); err != nil {
+			log.V(1).F().Warning("unable to unmarshal old CHI %s/%s err: %v", request.Namespace, request.Name, err)
+			return response
+		}
+		response.Warnings = append(response.Warnings, riskyChangeWarnings(old, chi, normalizer)...)
+	}
+
+	return response
+}
+
+// validateCHI checks CHI against rules to be enforced at admission
+func validateCHI(chi *api.ClickHouseInstallation, normalizer *model.Normalizer, lookup model.ObjectLookup) error {
+	normalized, err := normalizer.CreateTemplatedCHI(chi, model.NewNormalizerOptions())
+	if err != nil {
+		log.V(1).F().Warning("unable to normalize CHI %s/%s err: %v", chi.Namespace, chi.Name, err)
+		return nil
+	}
+
+	if rejections := normalized.EnsureStatus().GetRejections(); len(rejections) > 0 {
+		return fmt.Errorf("invalid entries: %s", strings.Join(rejections, "; "))
+	}
+
+	if mismatches := model.FindZookeeperMismatches(normalized); len(mismatches) > 0 {
+		return fmt.Errorf("keeper ensembles mismatch: %s", strings.Join(mismatches, "; "))
+	}
+
+	if clashes := model.FindRemoteClusterClashes(normalized); len(clashes) > 0 {
+		return fmt.Errorf("custom clusters clash: %s", strings.Join(clashes, "; "))
+	}
+
+	if collisions := model.FindNameCollisions(normalized, lookup); len(collisions) > 0 {
+		return fmt.Errorf("names of objects collide: %s", strings.Join(collisions, "; "))
+	}
+
+	if !normalized.Spec.Validation.IsStrictTemplates() {
+		return nil
+	}
+	if missing := model.FindMissingTemplates(normalized); len(missing) > 0 {
+		return fmt.Errorf("referenced templates not found: %s", strings.Join(missing, "; "))
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/altinity/clickhouse-operator/pkg/announcer"
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+// riskyChangeWarnings lists warnings about changes of the CHI which are allowed, but may lead to data loss or outage
+func riskyChangeWarnings(old, cur *api.ClickHouseInstallation, normalizer *model.Normalizer) []string {
+	oldNormalized, err := normalizer.CreateTemplatedCHI(old, model.NewNormalizerOptions())
+	if err != nil {
+		log.V(1).F().Warning("unable to normalize old CHI %s/%s err: %v", old.Namespace, old.Name, err)
+		return nil
+	}
+	curNormalized, err := normalizer.CreateTemplatedCHI(cur, model.NewNormalizerOptions())
+	if err != nil {
+		log.V(1).F().Warning("unable to normalize CHI %s/%s err: %v", cur.Namespace, cur.Name, err)
+		return nil
+	}
+	return findRiskyChanges(oldNormalized, curNormalized)
+}
+
+// configurationWarnings lists parts of configuration of the CHI, which are admitted, but are not applied
+func configurationWarnings(chi *api.ClickHouseInstallation, normalizer *model.Normalizer) (warnings []string) {
+	normalized, err := normalizer.CreateTemplatedCHI(chi, model.NewNormalizerOptions())
+	if err != nil {
+		log.V(1).F().Warning("unable to normalize CHI %s/%s err: %v", chi.Namespace, chi.Name, err)
+		return nil
+	}
+	if unknown := model.FindUnknownGuardUsers(normalized); len(unknown) > 0 {
+		warnings = append(warnings, fmt.Sprintf("guards of unknown users are not applied: %s", strings.Join(unknown, ", ")))
+	}
+	if escaped := model.FindEscapedValues(normalized); len(escaped) > 0 {
+		warnings = append(warnings, fmt.Sprintf(
+			"values are XML-escaped already and would be escaped twice, replace entities with plain chars: %s",
+			strings.Join(escaped, ", ")))
+	}
+	return warnings
+}
+
+// findRiskyChanges compares normalized CHIs and lists risky changes
+func findRiskyChanges(old, cur *api.ClickHouseInstallation) (warnings []string) {
+	warnings = append(warnings, findLayoutReductions(old, cur)...)
+	warnings = append(warnings, findStorageClassChanges(old, cur)...)
+	warnings = append(warnings, findZookeeperChanges(old, cur)...)
+	return warnings
+}
+
+// findLayoutReductions lists clusters losing shards and shards losing replicas
+func findLayoutReductions(old, cur *api.ClickHouseInstallation) (warnings []string) {
+	old.WalkClusters(func(oldCluster *api.Cluster) error {
+		newCluster := cur.FindCluster(oldCluster.Name)
+		if newCluster == nil {
+			warnings = append(warnings, fmt.Sprintf("cluster %s is removed, its data will be deleted", oldCluster.Name))
+			return nil
+		}
+		if oldShards, newShards := len(oldCluster.Layout.Shards), len(newCluster.Layout.Shards); newShards < oldShards {
+			warnings = append(warnings, fmt.Sprintf(
+				"cluster %s shards count is reduced from %d to %d, data of removed shards will be deleted",
+				oldCluster.Name, oldShards, newShards,
+			))
+		}
+		oldCluster.WalkShards(func(_ int, oldShard *api.ChiShard) error {
+			newShard := cur.FindShard(oldCluster.Name, oldShard.Name)
+			if newShard == nil {
+				return nil
+			}
+			if oldReplicas, newReplicas := len(oldShard.Hosts), len(newShard.Hosts); newReplicas < oldReplicas {
+				warnings = append(warnings, fmt.Sprintf(
+					"cluster %s shard %s replicas count is reduced from %d to %d",
+					oldCluster.Name, oldShard.Name, oldReplicas, newReplicas,
+				))
+			}
+			return nil
+		})
+		return nil
+	})
+	return warnings
+}
+
+// findStorageClassChanges lists volume claim templates switching StorageClass.
+// Existing PVCs keep their StorageClass, so the change applies to new volumes only
+func findStorageClassChanges(old, cur *api.ClickHouseInstallation) (warnings []string) {
+	old.WalkVolumeClaimTemplates(func(oldTemplate *api.ChiVolumeClaimTemplate) {
+		newTemplate, ok := cur.GetVolumeClaimTemplate(oldTemplate.Name)
+		if !ok {
+			return
+		}
+		oldClass := storageClassName(oldTemplate)
+		newClass := storageClassName(newTemplate)
+		if oldClass != newClass {
+			warnings = append(warnings, fmt.Sprintf(
+				"volumeClaimTemplate %s storageClassName is changed from '%s' to '%s', existing volumes are not migrated",
+				oldTemplate.Name, oldClass, newClass,
+			))
+		}
+	})
+	return warnings
+}
+
+// storageClassName gets StorageClass name of the volume claim template
+func storageClassName(template *api.ChiVolumeClaimTemplate) string {
+	if template.Spec.StorageClassName == nil {
+		return ""
+	}
+	return *template.Spec.StorageClassName
+}
+
+// findZookeeperChanges lists clusters switching zookeeper endpoints.
+// Replicated tables lose their metadata in case new zookeeper does not have it
+func findZookeeperChanges(old, cur *api.ClickHouseInstallation) (warnings []string) {
+	old.WalkClusters(func(oldCluster *api.Cluster) error {
+		newCluster := cur.FindCluster(oldCluster.Name)
+		if newCluster == nil {
+			return nil
+		}
+		if !equalZookeeperNodes(oldCluster.Zookeeper, newCluster.Zookeeper) {
+			warnings = append(warnings, fmt.Sprintf(
+				"cluster %s zookeeper nodes are changed, replicated tables require their metadata to be present in the new zookeeper",
+				oldCluster.Name,
+			))
+		}
+		return nil
+	})
+	return warnings
+}
+
+// equalZookeeperNodes checks whether zookeeper configs point to the same nodes
+func equalZookeeperNodes(a, b *api.ChiZookeeperConfig) bool {
+	var aNodes, bNodes []api.ChiZookeeperNode
+	if a != nil {
+		aNodes = a.Nodes
+	}
+	if b != nil {
+		bNodes = b.Nodes
+	}
+	if len(aNodes) != len(bNodes) {
+		return false
+	}
+	for i := range aNodes {
+		if !aNodes[i].Equal(&bNodes[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+
+	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
+	"github.com/altinity/clickhouse-operator/pkg/chop"
+	model "github.com/altinity/clickhouse-operator/pkg/model/chi"
+)
+
+func newWarningsCHI(replicas int, storageClass string, zookeeper string) *api.ClickHouseInstallation {
+	shard := api.ChiShard{Name: "0"}
+	for i := 0; i < replicas; i++ {
+		shard.Hosts = append(shard.Hosts, &api.ChiHost{})
+	}
+	chi := &api.ClickHouseInstallation{
+		Spec: api.ChiSpec{
+			Configuration: &api.Configuration{
+				Clusters: []*api.Cluster{
+					{
+						Name:      "cluster",
+						Zookeeper: &api.ChiZookeeperConfig{Nodes: []api.ChiZookeeperNode{{Host: zookeeper, Port: 2181}}},
+						Layout:    &api.ChiClusterLayout{Shards: []api.ChiShard{shard}},
+					},
+				},
+			},
+			Templates: &api.ChiTemplates{
+				VolumeClaimTemplates: []api.ChiVolumeClaimTemplate{
+					{Name: "data", Spec: core.PersistentVolumeClaimSpec{StorageClassName: &storageClass}},
+				},
+			},
+		},
+	}
+	template := &chi.Spec.Templates.VolumeClaimTemplates[0]
+	chi.Spec.Templates.EnsureVolumeClaimTemplatesIndex().Set(template.Name, template)
+	return chi
+}
+
+func Test_FindRiskyChanges(t *testing.T) {
+	old := newWarningsCHI(3, "gp2", "zk-1")
+
+	require.Empty(t, findRiskyChanges(old, newWarningsCHI(3, "gp2", "zk-1")))
+	require.Empty(t, findRiskyChanges(old, newWarningsCHI(4, "gp2", "zk-1")))
+
+	warnings := findRiskyChanges(old, newWarningsCHI(2, "gp3", "zk-2"))
+	require.Len(t, warnings, 3)
+	require.Contains(t, warnings[0], "replicas count is reduced from 3 to 2")
+	require.Contains(t, warnings[1], "storageClassName is changed from 'gp2' to 'gp3'")
+	require.Contains(t, warnings[2], "zookeeper nodes are changed")
+}
+
+func Test_ConfigurationWarnings(t *testing.T) {
+	require.NoError(t, chop.NewOffline(""))
+
+	chi := newWarningsCHI(1, "gp2", "zk-1")
+	chi.Spec.Configuration.Guards = &api.ChiGuards{
+		Users: map[string]api.ChiUserGuards{
+			"default": {MaxSessionsForUser: 10},
+			"unknown": {MaxSessionsForUser: 1},
+		},
+	}
+	chi.Spec.Configuration.Settings = api.NewSettings().SetScalarsFromMap(map[string]string{
+		"display_name": "tom &amp; jerry",
+		"logger/level": "debug",
+	})
+	warnings := configurationWarnings(chi, model.NewNormalizer(nil))
+	require.Len(t, warnings, 2)
+	require.Contains(t, warnings[0], "guards of unknown users are not applied: unknown")
+	require.Contains(t, warnings[1], `values are XML-escaped already and would be escaped twice, replace entities with plain chars: settings "display_name"`)
+}
@@ -20,6 +20,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 
 	api "github.com/altinity/clickhouse-operator/pkg/apis/clickhouse.altinity.com/v1"
 )
@@ -35,6 +36,46 @@ const (
 	noEol = ""
 )
 
+// escaper escapes XML special chars, so user-provided strings can be used as values of tags and attributes
+var escaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&apos;",
+)
+
+// Escape escapes XML special chars of the text to be used as a value of a tag or an attribute
+func Escape(text string) string {
+	return escaper.Replace(text)
+}
+
+// escapedEntityRegexp matches XML entities, which are found in values escaped already
+var escapedEntityRegexp = regexp.MustCompile(`&(amp|lt|gt|quot|apos|#[0-9]+|#x[0-9a-fA-F]+);`)
+
+// HasEscapedEntities checks whether the text has XML entities, which means the text is escaped already
+// and would be escaped twice by Escape
+func HasEscapedEntities(text string) bool {
+	return escapedEntityRegexp.MatchString(text)
+}
+
+// IsValidName checks whether the name can be used as XML tag or attribute name.
+// Names can not be escaped, so a name having special chars would produce invalid XML
+func IsValidName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || (r == '_'):
+		case (i > 0) && (unicode.IsDigit(r) || (r == '-') || (r == '.')):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
 // GenerateFromSettings creates XML representation from the provided settings
 func GenerateFromSettings(w io.Writer, settings *api.Settings, prefix string) {
 	if settings.Len() == 0 {
@@ -72,6 +113,10 @@ func GenerateFromSettings(w io.Writer, settings *api.Settings, prefix string) {
 			// Empty path? Should not be, but double check
 			continue
 		}
+		if !isValidPath(tags) {
+			// Setting can not be represented as XML, skip it instead of breaking the whole file
+			continue
+		}
 		name := data[path]
 		xmlTreeRoot.addBranch(tags, settings.Get(name))
 	}
@@ -95,6 +140,16 @@ func normalizePath(prefix, path string) string {
 	return path
 }
 
+// isValidPath checks whether all tags of the path are valid XML names
+func isValidPath(tags []string) bool {
+	for _, tag := range tags {
+		if !IsValidName(tag) {
+			return false
+		}
+	}
+	return true
+}
+
 // addBranch ensures branch exists and assign value to the last tagged node
 func (n *xmlNode) addBranch(tags []string, setting *api.Setting) {
 	node := n
@@ -138,17 +193,29 @@ func (n *xmlNode) buildXML(w io.Writer, indent, tabsize uint8) {
 	switch {
 	case n.value.IsScalar():
 		// ScalarString node
-		n.writeTagWithValue(w, n.value.String(), n.value.Attributes(), indent, tabsize)
+		n.writeTagWithValue(w, n.value.String(), n.attributes(), indent, tabsize)
 		return
 	// VectorOfStrings node
 
 	case n.value.IsVector():
 		for _, value := range n.value.VectorOfStrings() {
-			n.writeTagWithValue(w, value, n.value.Attributes(), indent, tabsize)
+			n.writeTagWithValue(w, value, n.attributes(), indent, tabsize)
 		}
 	}
 }
 
+// attributes builds attributes of the tag out of attributes of the value.
+// Attributes are written in order of names, so the same settings always produce the same XML
+func (n *xmlNode) attributes() string {
+	a := ""
+	n.value.WalkAttributes(func(name, value string) {
+		if IsValidName(name) {
+			a += fmt.Sprintf(` %s="%s"`, name, Escape(value))
+		}
+	})
+	return a
+}
+
 // writeTagNoValue prints tag which has no value, But it may have nested tags
 // <a>
 //
@@ -218,7 +285,7 @@ func (n *xmlNode) writeTag(w io.Writer, indent uint8, attributes string, openTag
 	}
 }
 
-// writeValue prints XML value into io.Writer
+// writeValue prints escaped XML value into io.Writer
 func (n *xmlNode) writeValue(w io.Writer, value string) {
-	_, _ = fmt.Fprintf(w, "%s", value)
+	_, _ = fmt.Fprintf(w, "%s", Escape(value))
 }
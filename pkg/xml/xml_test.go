@@ -0,0 +1,32 @@
+// Copyright 2019 Altinity Ltd and/or its affiliates. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HasEscapedEntities(t *testing.T) {
+	for _, text := range []string{"&amp;", "a &lt;b&gt;", "&quot;q&quot;", "&apos;", "&#38;", "&#x26;", "x&#xaB;"} {
+		require.True(t, HasEscapedEntities(text), text)
+	}
+	for _, text := range []string{"", "tom & jerry", "a&b", "&amp", "&unknown;", "&#;", "&#xZ;", "<html>"} {
+		require.False(t, HasEscapedEntities(text), text)
+	}
+	// Escaped text is detected, so values escaped twice are reported
+	require.True(t, HasEscapedEntities(Escape("<html>")))
+}